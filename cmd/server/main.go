@@ -18,9 +18,14 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	swagger "github.com/swaggo/fiber-swagger"
 
+	"github.com/JustDoItBetter/FITS-backend/internal/common/lifecycle"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/pagination"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/reference"
 	"github.com/JustDoItBetter/FITS-backend/internal/common/response"
 	"github.com/JustDoItBetter/FITS-backend/internal/config"
 	"github.com/JustDoItBetter/FITS-backend/internal/domain/auth"
+	"github.com/JustDoItBetter/FITS-backend/internal/domain/me"
+	"github.com/JustDoItBetter/FITS-backend/internal/domain/role"
 	"github.com/JustDoItBetter/FITS-backend/internal/domain/signing"
 	"github.com/JustDoItBetter/FITS-backend/internal/domain/student"
 	"github.com/JustDoItBetter/FITS-backend/internal/domain/teacher"
@@ -28,6 +33,7 @@ import (
 	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
 	"github.com/JustDoItBetter/FITS-backend/pkg/database"
 	"github.com/JustDoItBetter/FITS-backend/pkg/logger"
+	"github.com/JustDoItBetter/FITS-backend/pkg/mailer"
 
 	_ "github.com/JustDoItBetter/FITS-backend/docs" // Swagger docs
 	"go.uber.org/zap"
@@ -72,6 +78,9 @@ func main() {
 		zap.String("log_format", cfg.Logging.Format),
 	)
 
+	// Configure password hashing before any password is hashed or verified
+	crypto.SetPasswordHasher(newPasswordHasher(&cfg.Password))
+
 	// Initialize database connection
 	db, err := database.New(&cfg.Database)
 	if err != nil {
@@ -85,6 +94,14 @@ func main() {
 		zap.String("database", cfg.Database.Database),
 	)
 
+	// Start the background email sender; services queue emails in the database outbox
+	mail, mailWorker, err := newMailer(db, &cfg.Mail)
+	if err != nil {
+		logger.Fatal("Failed to initialize mailer", zap.Error(err))
+	}
+	mailWorker.Start()
+	defer mailWorker.Stop()
+
 	// Initialize Fiber app
 	app := fiber.New(fiber.Config{
 		ReadTimeout:  cfg.GetReadTimeout(),
@@ -151,20 +168,25 @@ func main() {
 	app.Use(cors.New(cors.Config{
 		AllowOrigins:     cfg.Server.AllowedOrigins,
 		AllowMethods:     "GET,POST,PUT,DELETE,PATCH,OPTIONS",
-		AllowHeaders:     "Origin,Content-Type,Accept,Authorization",
+		AllowHeaders:     "Origin,Content-Type,Accept,Authorization,If-Match,If-None-Match",
 		AllowCredentials: false, // Must be false with wildcard origins
-		ExposeHeaders:    "Content-Length,Content-Type",
+		ExposeHeaders:    "Content-Length,Content-Type,Link,ETag",
 		MaxAge:           3600, // Cache preflight responses for 1 hour
 	}))
 
 	// Setup routes with per-user rate limiting
-	setupRoutes(app, db, cfg, userRateLimiter)
+	purger := setupRoutes(app, db, cfg, userRateLimiter, mail)
+
+	// Purge deleted students and teachers in the background once their retention time has passed
+	purger.Start()
+	defer purger.Stop()
 
 	// Start server with optional TLS support and graceful shutdown handling
 	startServer(app, cfg)
 }
 
-func setupRoutes(app *fiber.App, db *database.DB, cfg *config.Config, userRateLimiter *middleware.UserRateLimiter) {
+// setupRoutes registers all routes and returns the purger of deleted records, which the caller starts
+func setupRoutes(app *fiber.App, db *database.DB, cfg *config.Config, userRateLimiter *middleware.UserRateLimiter, mail *mailer.Mailer) *lifecycle.Purger {
 	// Serve static files from web directory
 	app.Static("/", "./web", fiber.Static{
 		Index:         "login.html",
@@ -196,12 +218,6 @@ func setupRoutes(app *fiber.App, db *database.DB, cfg *config.Config, userRateLi
 		})
 	})
 
-	// Prometheus metrics endpoint
-	app.Get("/metrics",
-		middleware.SecretAuth(cfg.Secrets.MetricsSecret),
-		adaptor.HTTPHandler(promhttp.Handler()),
-	)
-
 	// API Documentation - Auto-generated from code annotations with Swagger
 	app.Get("/swagger/*", swagger.WrapHandler)
 
@@ -216,18 +232,44 @@ func setupRoutes(app *fiber.App, db *database.DB, cfg *config.Config, userRateLi
 	// Initialize JWT Service
 	jwtService := crypto.NewJWTService(cfg.JWT.Secret)
 
-	// Initialize JWT Middleware
-	jwtMiddleware := middleware.NewJWTMiddleware(jwtService)
+	// Initialize auth repository - also needed to validate API keys
+	authRepo := auth.NewGormRepository(db.DB)
+	apiKeyService := auth.NewAPIKeyService(authRepo)
+
+	// Initialize JWT Middleware, service accounts authenticate with API keys
+	jwtMiddleware := middleware.NewJWTMiddleware(jwtService).WithAPIKeys(apiKeyService)
 
 	// Initialize Middleware Adapter for clean dependency injection
-	mwAdapter := middleware.NewMiddlewareAdapter(jwtMiddleware)
+	// Permissions of the roles are stored in the database and edited by admins
+	roleService := role.NewService(role.NewGormRepository(db.DB))
+	mwAdapter := middleware.NewMiddlewareAdapter(jwtMiddleware).WithPermissions(roleService)
+
+	// Prometheus metrics endpoint - scraped with an API key with the metrics:read scope
+	app.Get("/metrics",
+		jwtMiddleware.RequireAuth(),
+		middleware.RequireScopeOrAdmin(crypto.ScopeMetricsRead),
+		adaptor.HTTPHandler(promhttp.Handler()),
+	)
 
 	// Initialize Auth Domain
-	authRepo := auth.NewGormRepository(db.DB)
 	bootstrapService := auth.NewBootstrapService(authRepo, &cfg.JWT)
-	invitationService := auth.NewInvitationService(authRepo, jwtService, &cfg.JWT)
-	authService := auth.NewAuthService(authRepo, jwtService, &cfg.JWT)
-	authHandler := auth.NewHandler(bootstrapService, invitationService, authService)
+	notifier := auth.NewNotifier(cfg.Server.GetPublicBaseURL(), mail)
+	assignmentRules := reference.Rules{RequireDepartmentMatch: cfg.Assignment.RequireDepartmentMatch}
+	invitationService := auth.NewInvitationService(authRepo, jwtService, &cfg.JWT, notifier, assignmentRules)
+	authService := auth.NewAuthService(authRepo, jwtService, &cfg.JWT, &cfg.Security, notifier)
+	passkeyService, err := auth.NewPasskeyService(authRepo, authService, &cfg.WebAuthn)
+	if err != nil {
+		logger.Fatal("Failed to initialize passkey service", zap.Error(err))
+	}
+	var oidcService *auth.OIDCService
+	if cfg.OIDC.Enabled {
+		oidcService = auth.NewOIDCService(authRepo, authService, &cfg.OIDC, cfg.Server.GetPublicBaseURL())
+		logger.Info("OpenID Connect login enabled", zap.String("issuer", cfg.OIDC.IssuerURL))
+	}
+	// Disabling an account or changing its role invalidates its tokens right away
+	jwtMiddleware.WithAccountCheck(authService)
+
+	authHandler := auth.NewHandler(bootstrapService, invitationService, authService, passkeyService, oidcService, apiKeyService)
 
 	// Register auth routes (these don't require authentication)
 	authHandler.RegisterRoutes(app)
@@ -237,27 +279,206 @@ func setupRoutes(app *fiber.App, db *database.DB, cfg *config.Config, userRateLi
 		jwtMiddleware.RequireAuth(),
 		authHandler.Logout,
 	)
+	app.Post("/api/v1/auth/password",
+		jwtMiddleware.RequireAuth(),
+		authHandler.ChangePassword,
+	)
+	// 2FA enrollment also accepts the partial MFA token, so users of roles
+	// that require 2FA can enroll during their first login
+	app.Post("/api/v1/auth/mfa/enroll",
+		jwtMiddleware.RequireAuthOrMFA(),
+		authHandler.EnrollMFA,
+	)
+	app.Post("/api/v1/auth/mfa/confirm",
+		jwtMiddleware.RequireAuthOrMFA(),
+		authHandler.ConfirmMFA,
+	)
+	app.Post("/api/v1/auth/mfa/disable",
+		jwtMiddleware.RequireAuth(),
+		authHandler.DisableMFA,
+	)
+	app.Post("/api/v1/auth/passkey/register/begin",
+		jwtMiddleware.RequireAuth(),
+		authHandler.BeginPasskeyRegistration,
+	)
+	app.Post("/api/v1/auth/passkey/register/finish",
+		jwtMiddleware.RequireAuth(),
+		authHandler.FinishPasskeyRegistration,
+	)
+	app.Get("/api/v1/auth/passkeys",
+		jwtMiddleware.RequireAuth(),
+		authHandler.ListPasskeys,
+	)
+	app.Delete("/api/v1/auth/passkeys/:id",
+		jwtMiddleware.RequireAuth(),
+		authHandler.DeletePasskey,
+	)
+	app.Get("/api/v1/auth/sessions",
+		jwtMiddleware.RequireAuth(),
+		authHandler.ListSessions,
+	)
+	app.Delete("/api/v1/auth/sessions",
+		jwtMiddleware.RequireAuth(),
+		authHandler.RevokeOtherSessions,
+	)
+	app.Delete("/api/v1/auth/sessions/:id",
+		jwtMiddleware.RequireAuth(),
+		authHandler.RevokeSession,
+	)
 
 	// Protected admin endpoints
+	// Invitations can also be created by service accounts, e.g. a school information system
+	// Department admins manage invitations of their department, security admins manage accounts,
+	// deleting accounts and changing roles is left to full admins
 	app.Post("/api/v1/admin/invite",
 		jwtMiddleware.RequireAuth(),
-		middleware.RequireAdmin(),
+		middleware.RequireScopeOrAdmin(crypto.ScopeInvitationsWrite, crypto.AdminScopeDepartment),
 		authHandler.CreateInvitation,
 	)
+	app.Get("/api/v1/admin/invitations",
+		jwtMiddleware.RequireAuth(),
+		middleware.RequireAdmin(crypto.AdminScopeDepartment),
+		authHandler.ListInvitations,
+	)
+	app.Delete("/api/v1/admin/invitations/expired",
+		jwtMiddleware.RequireAuth(),
+		middleware.RequireAdmin(),
+		authHandler.PurgeExpiredInvitations,
+	)
+	app.Post("/api/v1/admin/invitations/bulk",
+		jwtMiddleware.RequireAuth(),
+		middleware.RequireScopeOrAdmin(crypto.ScopeInvitationsWrite, crypto.AdminScopeDepartment),
+		authHandler.BulkCreateInvitations,
+	)
+	app.Get("/api/v1/admin/invitations/:id",
+		jwtMiddleware.RequireAuth(),
+		middleware.RequireAdmin(crypto.AdminScopeDepartment),
+		authHandler.GetInvitation,
+	)
+	app.Post("/api/v1/admin/invitations/:id/revoke",
+		jwtMiddleware.RequireAuth(),
+		middleware.RequireAdmin(crypto.AdminScopeDepartment),
+		authHandler.RevokeInvitation,
+	)
+	app.Post("/api/v1/admin/invitations/:id/resend",
+		jwtMiddleware.RequireAuth(),
+		middleware.RequireAdmin(crypto.AdminScopeDepartment),
+		authHandler.ResendInvitation,
+	)
+	app.Get("/api/v1/admin/users",
+		jwtMiddleware.RequireAuth(),
+		middleware.RequireAdmin(crypto.AdminScopeSecurity),
+		authHandler.ListUsers,
+	)
+	app.Get("/api/v1/admin/users/:id",
+		jwtMiddleware.RequireAuth(),
+		middleware.RequireAdmin(crypto.AdminScopeSecurity),
+		authHandler.GetUser,
+	)
+	app.Delete("/api/v1/admin/users/:id",
+		jwtMiddleware.RequireAuth(),
+		middleware.RequireAdmin(),
+		authHandler.DeleteUser,
+	)
+	app.Post("/api/v1/admin/users/:id/disable",
+		jwtMiddleware.RequireAuth(),
+		middleware.RequireAdmin(crypto.AdminScopeSecurity),
+		authHandler.DisableUser,
+	)
+	app.Post("/api/v1/admin/users/:id/enable",
+		jwtMiddleware.RequireAuth(),
+		middleware.RequireAdmin(crypto.AdminScopeSecurity),
+		authHandler.EnableUser,
+	)
+	app.Put("/api/v1/admin/users/:id/role",
+		jwtMiddleware.RequireAuth(),
+		middleware.RequireAdmin(),
+		authHandler.ChangeUserRole,
+	)
+	app.Post("/api/v1/admin/users/:id/unlock",
+		jwtMiddleware.RequireAuth(),
+		middleware.RequireAdmin(crypto.AdminScopeSecurity),
+		authHandler.UnlockUser,
+	)
+	app.Post("/api/v1/admin/users/:id/password-reset",
+		jwtMiddleware.RequireAuth(),
+		middleware.RequireAdmin(crypto.AdminScopeSecurity),
+		authHandler.CreatePasswordReset,
+	)
+	app.Get("/api/v1/admin/users/:id/sessions",
+		jwtMiddleware.RequireAuth(),
+		middleware.RequireAdmin(crypto.AdminScopeSecurity),
+		authHandler.ListUserSessions,
+	)
+	app.Delete("/api/v1/admin/users/:id/sessions",
+		jwtMiddleware.RequireAuth(),
+		middleware.RequireAdmin(crypto.AdminScopeSecurity),
+		authHandler.RevokeUserSessions,
+	)
+	app.Delete("/api/v1/admin/users/:id/sessions/:sessionId",
+		jwtMiddleware.RequireAuth(),
+		middleware.RequireAdmin(crypto.AdminScopeSecurity),
+		authHandler.RevokeUserSession,
+	)
+	app.Post("/api/v1/admin/api-keys",
+		jwtMiddleware.RequireAuth(),
+		middleware.RequireAdmin(crypto.AdminScopeSecurity),
+		authHandler.CreateAPIKey,
+	)
+	app.Get("/api/v1/admin/api-keys",
+		jwtMiddleware.RequireAuth(),
+		middleware.RequireAdmin(crypto.AdminScopeSecurity),
+		authHandler.ListAPIKeys,
+	)
+	app.Post("/api/v1/admin/api-keys/:id/revoke",
+		jwtMiddleware.RequireAuth(),
+		middleware.RequireAdmin(crypto.AdminScopeSecurity),
+		authHandler.RevokeAPIKey,
+	)
+	if oidcService != nil {
+		app.Get("/api/v1/admin/oidc/identities",
+			jwtMiddleware.RequireAuth(),
+			middleware.RequireAdmin(crypto.AdminScopeSecurity),
+			authHandler.ListOIDCIdentities,
+		)
+		app.Post("/api/v1/admin/oidc/identities/:id/link",
+			jwtMiddleware.RequireAuth(),
+			middleware.RequireAdmin(crypto.AdminScopeSecurity),
+			authHandler.LinkOIDCIdentity,
+		)
+		app.Delete("/api/v1/admin/oidc/identities/:id",
+			jwtMiddleware.RequireAuth(),
+			middleware.RequireAdmin(crypto.AdminScopeSecurity),
+			authHandler.DeleteOIDCIdentity,
+		)
+	}
 
 	// Initialize repositories with GORM (PostgreSQL persistence)
 	studentRepo := student.NewGormRepository(db.DB)
 	teacherRepo := teacher.NewGormRepository(db.DB)
 
-	// Initialize services
-	studentService := student.NewService(studentRepo)
-	teacherService := teacher.NewService(teacherRepo)
-	signingService := signing.NewService()
+	// Initialize services with transaction support, deletions run their hooks in the same transaction
+	txMgr := database.NewTransactionManager(db.DB)
+	studentService := student.NewServiceWithTx(studentRepo, txMgr)
+	teacherService := teacher.NewServiceWithTx(teacherRepo, txMgr)
+
+	// Accounts of deleted students and teachers must not be able to log in anymore
+	studentService.OnDelete(auth.DisableLinkedAccount)
+	teacherService.OnDelete(auth.DisableLinkedAccount)
+
+	// Deleted records stay restorable for the retention time, students go first as they reference teachers
+	purger := lifecycle.NewPurger(cfg.Retention.GetDeletedRecords(), cfg.Retention.GetPurgeInterval())
+	purger.Register("students", studentService.PurgeDeleted)
+	purger.Register("teachers", teacherService.PurgeDeleted)
+	signingService := signing.NewService(signing.NewGormRepository(db.DB))
 
 	// Initialize handlers
-	studentHandler := student.NewHandler(studentService)
-	teacherHandler := teacher.NewHandler(teacherService)
+	cursorCodec := pagination.NewCursorCodec(cfg.JWT.Secret)
+	studentHandler := student.NewHandler(studentService, cursorCodec)
+	teacherHandler := teacher.NewHandler(teacherService, cursorCodec)
 	signingHandler := signing.NewHandler(signingService)
+	roleHandler := role.NewHandler(roleService)
+	meHandler := me.NewHandler(me.NewService(authService, studentService, teacherService, signingService))
 
 	// API v1 routes - Single source of truth for all routes and security
 	// Apply per-user rate limiting to all API routes (after JWT middleware extracts user info)
@@ -267,7 +488,7 @@ func setupRoutes(app *fiber.App, db *database.DB, cfg *config.Config, userRateLi
 	// Register signing routes (protected - requires authentication)
 	signingGroup := api.Group("/signing")
 	signingGroup.Use(jwtMiddleware.RequireAuth())
-	signingHandler.RegisterRoutes(signingGroup)
+	signingHandler.RegisterRoutes(signingGroup, mwAdapter)
 
 	// Register student routes with their security requirements
 	// Routes and middleware are now defined in one place within the handler
@@ -278,6 +499,68 @@ func setupRoutes(app *fiber.App, db *database.DB, cfg *config.Config, userRateLi
 	// Routes and middleware are now defined in one place within the handler
 	teacherGroup := api.Group("/teacher")
 	teacherHandler.RegisterRoutes(teacherGroup, mwAdapter, mwAdapter)
+
+	// Register role routes, admins edit the permissions of each role
+	roleGroup := api.Group("/admin/roles")
+	roleHandler.RegisterRoutes(roleGroup, mwAdapter, mwAdapter)
+
+	// Register the account of the caller, frontends read the linked record and relationships here
+	meGroup := api.Group("/me")
+	meHandler.RegisterRoutes(meGroup, mwAdapter, mwAdapter)
+
+	return purger
+}
+
+// newPasswordHasher creates the password hasher selected in the config
+func newPasswordHasher(cfg *config.PasswordConfig) crypto.PasswordHasher {
+	if cfg.GetAlgorithm() == config.PasswordAlgorithmBcrypt {
+		return crypto.NewBcryptHasher(cfg.GetBcryptCost())
+	}
+	return crypto.NewArgon2idHasher(crypto.Argon2Params{
+		Memory:      cfg.GetArgon2Memory(),
+		Iterations:  cfg.GetArgon2Iterations(),
+		Parallelism: cfg.GetArgon2Parallelism(),
+	})
+}
+
+// newMailer creates the email composer and the background sender for the configured backend
+func newMailer(db *database.DB, cfg *config.MailConfig) (*mailer.Mailer, *mailer.Worker, error) {
+	mail, err := mailer.New(cfg.GetFrom(), cfg.GetDefaultLocale())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var sender mailer.Sender
+	switch cfg.GetBackend() {
+	case config.MailBackendSMTP:
+		sender, err = mailer.NewSMTPSender(mailer.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.GetSMTPPort(),
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			TLSMode:  cfg.GetSMTPTLS(),
+		})
+	case config.MailBackendFile:
+		sender, err = mailer.NewFileSender(cfg.GetFileDir())
+	default:
+		sender = mailer.NewLogSender(cfg.LogBodies)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	logger.Info("Mailer initialized",
+		zap.String("backend", cfg.GetBackend()),
+		zap.String("default_locale", cfg.GetDefaultLocale()),
+	)
+
+	worker := mailer.NewWorker(mailer.NewGormOutbox(db.DB), sender, mailer.WorkerConfig{
+		PollInterval: cfg.GetPollInterval(),
+		BatchSize:    cfg.GetBatchSize(),
+		MaxAttempts:  cfg.GetMaxAttempts(),
+		Retention:    cfg.GetRetention(),
+	})
+	return mail, worker, nil
 }
 
 // startServer starts the HTTP/HTTPS server with optional TLS support
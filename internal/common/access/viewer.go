@@ -0,0 +1,71 @@
+package access
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/errors"
+	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
+)
+
+// Viewer is the authenticated caller that relationship policies decide for
+// Route middleware checks whether a role may read a kind of record at all (e.g. student:read),
+// the policies of the student and teacher services decide which records
+type Viewer struct {
+	UserID     string
+	Role       crypto.Role
+	UserUUID   string            // Student or teacher record linked to the account, empty for admins
+	AdminScope crypto.AdminScope // Empty for other roles
+	Department string            // Department of department admins, empty for other viewers
+}
+
+// FromContext returns the viewer set by the JWT middleware, the zero Viewer if the request is anonymous
+func FromContext(c *fiber.Ctx) Viewer {
+	userID, _ := c.Locals("user_id").(string)
+	role, _ := c.Locals("role").(crypto.Role)
+	userUUID, _ := c.Locals("user_uuid").(string)
+	scope, _ := c.Locals("admin_scope").(crypto.AdminScope)
+	department, _ := c.Locals("admin_department").(string)
+
+	return Viewer{
+		UserID:     userID,
+		Role:       role,
+		UserUUID:   userUUID,
+		AdminScope: scope,
+		Department: department,
+	}
+}
+
+// IsAdmin reports whether the viewer is an admin of any scope
+func (v Viewer) IsAdmin() bool {
+	return v.Role == crypto.RoleAdmin
+}
+
+// IsDepartmentAdmin reports whether the viewer is an admin limited to one department
+func (v Viewer) IsDepartmentAdmin() bool {
+	return v.IsAdmin() && v.AdminScope == crypto.AdminScopeDepartment
+}
+
+// InDepartment reports whether department is the department of a department admin
+// Departments compare case-insensitively like the list filter, an admin without department matches none
+func (v Viewer) InDepartment(department string) bool {
+	own := strings.TrimSpace(v.Department)
+	return own != "" && strings.EqualFold(own, strings.TrimSpace(department))
+}
+
+// Owns reports whether recordUUID is the student or teacher record linked to the viewer's account
+func (v Viewer) Owns(recordUUID string) bool {
+	return v.UserUUID != "" && v.UserUUID == recordUUID
+}
+
+// Forbidden is returned when a policy denies the viewer access to a record
+func Forbidden() error {
+	return errors.NewAppError(http.StatusForbidden, "Forbidden", "you don't have permission to access this resource")
+}
+
+// OutsideDepartment is returned when a department admin accesses a record of another department
+func OutsideDepartment() error {
+	return errors.NewAppError(http.StatusForbidden, "Forbidden", "department admins can only access records of their department")
+}
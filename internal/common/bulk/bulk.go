@@ -0,0 +1,293 @@
+// Package bulk reads import files and writes export files of the student and teacher rosters
+package bulk
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/errors"
+)
+
+// MaxImportRows limits the number of records per import file
+const MaxImportRows = 5000
+
+// Unzip limits of XLSX files. A sheet of MaxImportRows records unpacks to a few megabytes,
+// the limits keep a small, highly compressed upload within the body limit from unpacking into gigabytes
+const (
+	maxXLSXUnzipSize    = 64 << 20 // Whole workbook
+	maxXLSXUnzipXMLSize = 16 << 20 // Sheet or shared strings held in memory, larger ones fail with the workbook limit
+)
+
+// Format is the file format of an import or export
+type Format string
+
+// Supported formats
+const (
+	FormatCSV   Format = "csv"
+	FormatXLSX  Format = "xlsx"
+	FormatJSONL Format = "jsonl" // JSON Lines, one object per line
+)
+
+// ParseFormat parses the format query parameter of export endpoints, CSV if empty
+func ParseFormat(value string) (Format, error) {
+	switch format := Format(strings.ToLower(strings.TrimSpace(value))); format {
+	case "":
+		return FormatCSV, nil
+	case FormatCSV, FormatXLSX, FormatJSONL:
+		return format, nil
+	}
+	return "", errors.ValidationError("format must be csv, xlsx or jsonl")
+}
+
+// FormatOf detects the format of an uploaded file by its extension
+func FormatOf(filename string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".xlsx":
+		return FormatXLSX, nil
+	case ".jsonl", ".ndjson":
+		return FormatJSONL, nil
+	}
+	return "", errors.BadRequest("file must be a .csv, .xlsx or .jsonl file")
+}
+
+// ContentType returns the MIME type of files in the format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case FormatJSONL:
+		return "application/jsonl; charset=utf-8"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Row is one record of an import file with its values by field name
+type Row struct {
+	Line   int // Line in the file, the header of CSV and XLSX files is line 1
+	Fields map[string]string
+}
+
+// Get returns the trimmed value of a field, empty if the file has no such column
+func (r Row) Get(field string) string {
+	return strings.TrimSpace(r.Fields[field])
+}
+
+// Options describes the fields an import reads
+type Options struct {
+	Fields   []string          // Fields read from the file, other columns are ignored
+	Required []string          // Columns every CSV and XLSX file must have
+	Mapping  map[string]string // Column names of the file mapped to field names, e.g. "Vorname" to "first_name"
+}
+
+// ParseMapping parses a column mapping given as JSON object, e.g. {"Vorname": "first_name"}
+// Column names are matched case-insensitively, every target must be one of fields
+func ParseMapping(value string, fields []string) (map[string]string, error) {
+	mapping := make(map[string]string)
+	if strings.TrimSpace(value) == "" {
+		return mapping, nil
+	}
+
+	var raw map[string]string
+	if err := json.Unmarshal([]byte(value), &raw); err != nil {
+		return nil, errors.BadRequest("mapping must be a JSON object of column names to field names")
+	}
+	for column, field := range raw {
+		if !slices.Contains(fields, field) {
+			return nil, errors.BadRequest(fmt.Sprintf("can't map column '%s' to unknown field '%s', fields: %s", column, field, strings.Join(fields, ", ")))
+		}
+		mapping[normalizeColumn(column)] = field
+	}
+	return mapping, nil
+}
+
+// Read reads the records of an import file, detecting the format by the file extension
+// Completely empty rows are skipped
+func Read(filename string, data []byte, opts Options) ([]Row, error) {
+	format, err := FormatOf(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []Row
+	switch format {
+	case FormatJSONL:
+		rows, err = readJSONL(data, opts)
+	default:
+		var records [][]string
+		if format == FormatXLSX {
+			records, err = ReadXLSX(data)
+		} else {
+			records, err = ReadCSV(data)
+		}
+		if err != nil {
+			return nil, errors.BadRequest(fmt.Sprintf("failed to read file: %v", err))
+		}
+		rows, err = rowsFromRecords(records, opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, errors.BadRequest("file contains no records")
+	}
+	if len(rows) > MaxImportRows {
+		return nil, errors.BadRequest(fmt.Sprintf("file exceeds maximum of %d records", MaxImportRows))
+	}
+	return rows, nil
+}
+
+// ReadCSV reads all records of a CSV file
+func ReadCSV(data []byte) ([][]string, error) {
+	// Spreadsheet programs often prepend a UTF-8 byte order mark
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	// German spreadsheet exports use semicolons, detect them from the header row
+	header, _, _ := bytes.Cut(data, []byte("\n"))
+	if !bytes.Contains(header, []byte(",")) && bytes.Contains(header, []byte(";")) {
+		reader.Comma = ';'
+	}
+
+	return reader.ReadAll()
+}
+
+// ReadXLSX reads the rows of the first sheet in the workbook
+// Reading stops after the header and MaxImportRows+1 non-empty rows, enough for callers to reject the file as too long
+func ReadXLSX(data []byte) ([][]string, error) {
+	file, err := excelize.OpenReader(bytes.NewReader(data), excelize.Options{
+		UnzipSizeLimit:    maxXLSXUnzipSize,
+		UnzipXMLSizeLimit: maxXLSXUnzipXMLSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	sheets := file.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, fmt.Errorf("workbook has no sheets")
+	}
+
+	rows, err := file.Rows(sheets[0])
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records [][]string
+	nonEmpty := 0
+	for nonEmpty < MaxImportRows+2 && rows.Next() {
+		record, err := rows.Columns()
+		if err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(strings.Join(record, "")) != "" {
+			nonEmpty++
+		}
+		records = append(records, record)
+	}
+	return records, rows.Error()
+}
+
+// rowsFromRecords maps the records of a CSV or XLSX file to rows using the header row
+func rowsFromRecords(records [][]string, opts Options) ([]Row, error) {
+	if len(records) == 0 {
+		return nil, errors.BadRequest("file is empty")
+	}
+
+	columns := make(map[int]string)
+	present := make(map[string]bool)
+	for i, name := range records[0] {
+		if field, ok := opts.field(name); ok {
+			columns[i] = field
+			present[field] = true
+		}
+	}
+	var missing []string
+	for _, field := range opts.Required {
+		if !present[field] {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, errors.BadRequest(fmt.Sprintf("file is missing columns: %s", strings.Join(missing, ", ")))
+	}
+
+	var rows []Row
+	for i, record := range records[1:] {
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+		row := Row{Line: i + 2, Fields: make(map[string]string, len(columns))}
+		for j, value := range record {
+			if field, ok := columns[j]; ok {
+				row.Fields[field] = value
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// readJSONL reads a JSON Lines file, one object per line
+// Strings, numbers and booleans are read as text, null as empty value
+func readJSONL(data []byte, opts Options) ([]Row, error) {
+	var rows []Row
+	for i, line := range bytes.Split(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var object map[string]any
+		if err := json.Unmarshal(line, &object); err != nil {
+			return nil, errors.BadRequest(fmt.Sprintf("line %d is not a JSON object", i+1))
+		}
+		row := Row{Line: i + 1, Fields: make(map[string]string, len(object))}
+		for name, value := range object {
+			field, ok := opts.field(name)
+			if !ok {
+				continue
+			}
+			switch value := value.(type) {
+			case nil:
+			case string:
+				row.Fields[field] = value
+			case float64, bool:
+				row.Fields[field] = fmt.Sprint(value)
+			default:
+				return nil, errors.BadRequest(fmt.Sprintf("line %d: %s must be a string", i+1, name))
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// field returns the field a column of the file is read into
+func (o Options) field(column string) (string, bool) {
+	column = normalizeColumn(column)
+	if field, ok := o.Mapping[column]; ok {
+		return field, true
+	}
+	if slices.Contains(o.Fields, column) {
+		return column, true
+	}
+	return "", false
+}
+
+func normalizeColumn(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
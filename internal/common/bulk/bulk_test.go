@@ -0,0 +1,182 @@
+package bulk
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/pagination"
+)
+
+var testOptions = Options{
+	Fields:   []string{"first_name", "last_name", "email"},
+	Required: []string{"first_name", "email"},
+}
+
+func TestRead(t *testing.T) {
+	t.Run("csv with mapping and ignored columns", func(t *testing.T) {
+		opts := testOptions
+		opts.Mapping = map[string]string{"vorname": "first_name"}
+		data := "uuid;Vorname;Email\n1;Max;max@example.com\n;;\n2;Erika;erika@example.com\n"
+
+		rows, err := Read("roster.CSV", []byte(data), opts)
+		require.NoError(t, err)
+		require.Len(t, rows, 2)
+		assert.Equal(t, Row{Line: 2, Fields: map[string]string{"first_name": "Max", "email": "max@example.com"}}, rows[0])
+		assert.Equal(t, 4, rows[1].Line)
+		assert.Equal(t, "", rows[1].Get("last_name"))
+	})
+
+	t.Run("xlsx", func(t *testing.T) {
+		var buf bytes.Buffer
+		writer, err := NewWriter(FormatXLSX, &buf, []string{"first_name", "email"})
+		require.NoError(t, err)
+		require.NoError(t, writer.Write("Max", "max@example.com"))
+		require.NoError(t, writer.Close())
+
+		rows, err := Read("roster.xlsx", buf.Bytes(), testOptions)
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		assert.Equal(t, "Max", rows[0].Get("first_name"))
+	})
+
+	t.Run("xlsx stops reading after the maximum of records", func(t *testing.T) {
+		var buf bytes.Buffer
+		writer, err := NewWriter(FormatXLSX, &buf, []string{"first_name", "email"})
+		require.NoError(t, err)
+		for range MaxImportRows + 100 {
+			require.NoError(t, writer.Write("Max", "max@example.com"))
+		}
+		require.NoError(t, writer.Close())
+
+		records, err := ReadXLSX(buf.Bytes())
+		require.NoError(t, err)
+		assert.Len(t, records, MaxImportRows+2)
+
+		_, err = Read("roster.xlsx", buf.Bytes(), testOptions)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "exceeds maximum")
+	})
+
+	t.Run("jsonl", func(t *testing.T) {
+		data := `{"first_name": "Max", "email": "max@example.com", "teacher_id": null}` + "\n\n" + `{"FIRST_NAME": 42, "last_name": null}`
+
+		rows, err := Read("roster.jsonl", []byte(data), testOptions)
+		require.NoError(t, err)
+		require.Len(t, rows, 2)
+		assert.Equal(t, 1, rows[0].Line)
+		assert.Equal(t, "max@example.com", rows[0].Get("email"))
+		assert.Equal(t, 3, rows[1].Line)
+		assert.Equal(t, "42", rows[1].Get("first_name"))
+	})
+
+	for name, tt := range map[string]struct{ filename, data string }{
+		"unsupported extension": {"roster.xls", "data"},
+		"missing columns":       {"roster.csv", "first_name,last_name\nMax,Mustermann\n"},
+		"no records":            {"roster.csv", "first_name,email\n"},
+		"invalid json":          {"roster.jsonl", "[1, 2]"},
+		"nested json":           {"roster.jsonl", `{"first_name": {"a": 1}}`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Read(tt.filename, []byte(tt.data), testOptions)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseMapping(t *testing.T) {
+	mapping, err := ParseMapping(`{"Vorname ": "first_name"}`, testOptions.Fields)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"vorname": "first_name"}, mapping)
+
+	mapping, err = ParseMapping("", testOptions.Fields)
+	require.NoError(t, err)
+	assert.Empty(t, mapping)
+
+	_, err = ParseMapping(`{"Rolle": "role"}`, testOptions.Fields)
+	assert.Error(t, err)
+	_, err = ParseMapping(`not json`, testOptions.Fields)
+	assert.Error(t, err)
+}
+
+func TestFieldErrors(t *testing.T) {
+	req := struct {
+		FirstName string `validate:"required,max=3"`
+		Email     string `validate:"required,email"`
+		TeacherID string `validate:"omitempty,uuid"`
+	}{FirstName: "Maximilian", Email: "invalid", TeacherID: "1"}
+
+	err := validator.New().Struct(req)
+	assert.Equal(t, []string{
+		"first_name must be at most 3 characters",
+		"email must be a valid email",
+		"teacher_id must be a UUID",
+	}, FieldErrors(err))
+}
+
+func TestWriters(t *testing.T) {
+	created := time.Date(2025, 9, 30, 12, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	var noTeacher *string
+
+	t.Run("csv", func(t *testing.T) {
+		var buf strings.Builder
+		writer, err := NewWriter(FormatCSV, &buf, []string{"name", "teacher_id", "created_at"})
+		require.NoError(t, err)
+		require.NoError(t, writer.Write("=HYPERLINK(\"x\")", noTeacher, created))
+		require.NoError(t, writer.Close())
+
+		assert.Equal(t, "name,teacher_id,created_at\n\"'=HYPERLINK(\"\"x\"\")\",,2025-09-30T10:00:00Z\n", buf.String())
+	})
+
+	t.Run("jsonl", func(t *testing.T) {
+		var buf strings.Builder
+		writer, err := NewWriter(FormatJSONL, &buf, []string{"name", "teacher_id", "created_at"})
+		require.NoError(t, err)
+		require.NoError(t, writer.Write("Max", noTeacher, &created))
+		require.NoError(t, writer.Close())
+
+		assert.Equal(t, `{"name":"Max","teacher_id":null,"created_at":"2025-09-30T10:00:00Z"}`+"\n", buf.String())
+	})
+}
+
+func TestRecords(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, d) }
+	records := make([]pagination.Cursor, ExportBatchSize+10)
+	for i := range records {
+		records[i] = pagination.Cursor{CreatedAt: day(i), ID: string(rune('a' + i%26))}
+	}
+	key := func(c pagination.Cursor) pagination.Cursor { return c }
+
+	for name, sort := range map[string][]pagination.SortField{
+		"keyset": nil,
+		"offset": {{Field: "last_name"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var calls int
+			list := func(ctx context.Context, params pagination.Params) ([]pagination.Cursor, pagination.PageInfo, error) {
+				calls++
+				assert.Equal(t, name == "keyset", params.Keyset)
+				if params.Keyset {
+					page, info := pagination.KeysetSlice(records, params, true, key)
+					return page, info, nil
+				}
+				end := min(params.Offset()+params.Limit, len(records))
+				return records[params.Offset():end], pagination.PageInfo{}, nil
+			}
+
+			var count int
+			for _, err := range Records(context.Background(), sort, list) {
+				require.NoError(t, err)
+				count++
+			}
+			assert.Equal(t, len(records), count)
+			assert.Equal(t, 2, calls)
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package bulk
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/pagination"
+)
+
+// ExportBatchSize is the number of records an export reads per query
+const ExportBatchSize = 500
+
+// Writer writes the records of an export file
+type Writer interface {
+	// Write writes one record with a value per column
+	// Values are strings, *string, time.Time or *time.Time, nil pointers are written as empty or null
+	Write(values ...any) error
+	// Close completes the file and flushes it to the underlying writer
+	Close() error
+}
+
+// NewWriter creates a writer for an export file with the columns
+func NewWriter(format Format, w io.Writer, columns []string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		writer := &csvWriter{csv: csv.NewWriter(w)}
+		return writer, writer.csv.Write(columns)
+	case FormatXLSX:
+		return newXLSXWriter(w, columns)
+	case FormatJSONL:
+		return &jsonlWriter{w: bufio.NewWriter(w), columns: columns}, nil
+	}
+	return nil, fmt.Errorf("unsupported export format %q", format)
+}
+
+// csvWriter writes CSV files, flushing regularly so large exports stream
+type csvWriter struct {
+	csv  *csv.Writer
+	rows int
+}
+
+func (w *csvWriter) Write(values ...any) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		record[i] = escapeFormula(text(value))
+	}
+	if err := w.csv.Write(record); err != nil {
+		return err
+	}
+	if w.rows++; w.rows%ExportBatchSize == 0 {
+		w.csv.Flush()
+	}
+	return w.csv.Error()
+}
+
+func (w *csvWriter) Close() error {
+	w.csv.Flush()
+	return w.csv.Error()
+}
+
+// escapeFormula keeps spreadsheet programs from evaluating cells as formulas (CSV injection)
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// xlsxWriter writes the records into the first sheet of a workbook
+// The stream writer keeps memory bounded, the workbook is written to w on Close
+type xlsxWriter struct {
+	w      io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+}
+
+func newXLSXWriter(w io.Writer, columns []string) (*xlsxWriter, error) {
+	file := excelize.NewFile()
+	stream, err := file.NewStreamWriter(file.GetSheetName(0))
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	writer := &xlsxWriter{w: w, file: file, stream: stream}
+
+	header := make([]any, len(columns))
+	for i, column := range columns {
+		header[i] = column
+	}
+	return writer, writer.write(header)
+}
+
+func (w *xlsxWriter) Write(values ...any) error {
+	// Cells are written as strings, so values are never evaluated as formulas
+	cells := make([]any, len(values))
+	for i, value := range values {
+		cells[i] = text(value)
+	}
+	return w.write(cells)
+}
+
+func (w *xlsxWriter) write(cells []any) error {
+	w.row++
+	cell, err := excelize.CoordinatesToCellName(1, w.row)
+	if err != nil {
+		return err
+	}
+	return w.stream.SetRow(cell, cells)
+}
+
+func (w *xlsxWriter) Close() error {
+	defer w.file.Close()
+	if err := w.stream.Flush(); err != nil {
+		return err
+	}
+	return w.file.Write(w.w)
+}
+
+// jsonlWriter writes one JSON object per record, keys in column order
+type jsonlWriter struct {
+	w       *bufio.Writer
+	columns []string
+}
+
+func (w *jsonlWriter) Write(values ...any) error {
+	w.w.WriteByte('{')
+	for i, value := range values {
+		if i > 0 {
+			w.w.WriteByte(',')
+		}
+		key, _ := json.Marshal(w.columns[i])
+		w.w.Write(key)
+		w.w.WriteByte(':')
+
+		var encoded []byte
+		var err error
+		if s, ok := optionalText(value); ok {
+			encoded, err = json.Marshal(s)
+		} else {
+			encoded = []byte("null")
+		}
+		if err != nil {
+			return err
+		}
+		w.w.Write(encoded)
+	}
+	w.w.WriteByte('}')
+	return w.w.WriteByte('\n')
+}
+
+func (w *jsonlWriter) Close() error {
+	return w.w.Flush()
+}
+
+// text returns the text of a value, empty for nil pointers
+func text(value any) string {
+	s, _ := optionalText(value)
+	return s
+}
+
+// optionalText returns the text of a value and false for nil pointers
+func optionalText(value any) (string, bool) {
+	switch value := value.(type) {
+	case nil:
+		return "", false
+	case string:
+		return value, true
+	case *string:
+		if value == nil {
+			return "", false
+		}
+		return *value, true
+	case time.Time:
+		return value.UTC().Format(time.RFC3339), true
+	case *time.Time:
+		if value == nil {
+			return "", false
+		}
+		return value.UTC().Format(time.RFC3339), true
+	}
+	return fmt.Sprint(value), true
+}
+
+// Records iterates over all records of a list, reading them with list in batches of ExportBatchSize
+// Keyset pagination is used if the sort allows it, so records added meanwhile don't shift the batches
+func Records[T any](ctx context.Context, sort []pagination.SortField, list func(ctx context.Context, params pagination.Params) ([]T, pagination.PageInfo, error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		params := pagination.Params{Page: 1, Limit: ExportBatchSize}
+		if _, ok := pagination.KeysetSort(sort); ok {
+			params.Keyset = true
+		}
+
+		for {
+			batch, info, err := list(ctx, params)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, record := range batch {
+				if !yield(record, nil) {
+					return
+				}
+			}
+
+			if params.Keyset {
+				if info.Next == nil {
+					return
+				}
+				params.Cursor = info.Next
+			} else {
+				if len(batch) < params.Limit {
+					return
+				}
+				params.Page++
+			}
+		}
+	}
+}
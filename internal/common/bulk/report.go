@@ -0,0 +1,153 @@
+package bulk
+
+import (
+	stderrors "errors"
+	"fmt"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/errors"
+)
+
+// Import row statuses
+const (
+	RowStatusValid     = "valid"
+	RowStatusCreated   = "created"
+	RowStatusUpdated   = "updated"
+	RowStatusUnchanged = "unchanged"
+	RowStatusError     = "error"
+)
+
+// Import row actions, what a valid row does to the records
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionNone   = "none" // The record already matches the row
+)
+
+// Report reports the outcome of an import per row
+// @Description Result of a bulk import
+type Report struct {
+	DryRun    bool        `json:"dry_run" example:"false"`
+	Total     int         `json:"total" example:"120"`
+	Valid     int         `json:"valid" example:"118"`
+	Created   int         `json:"created" example:"0"`
+	Updated   int         `json:"updated" example:"0"`
+	Unchanged int         `json:"unchanged" example:"0"`
+	Failed    int         `json:"failed" example:"2"`
+	Rows      []RowResult `json:"rows"`
+}
+
+// RowResult is the validation or import result of a single row
+// @Description Result of a single import row
+type RowResult struct {
+	Row    int      `json:"row" example:"2"`
+	Email  string   `json:"email" example:"max@example.com"`
+	UUID   string   `json:"uuid,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"` // Record that is or would be updated, or was created
+	Action string   `json:"action,omitempty" example:"create" enums:"create,update,none"`
+	Status string   `json:"status" example:"valid" enums:"valid,created,updated,unchanged,error"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+// NewReport creates the report of an import with one result per row
+func NewReport(rows []Row, dryRun bool) *Report {
+	report := &Report{
+		DryRun: dryRun,
+		Total:  len(rows),
+		Rows:   make([]RowResult, len(rows)),
+	}
+	for i, row := range rows {
+		report.Rows[i].Row = row.Line
+	}
+	return report
+}
+
+// Fail marks a row as invalid
+func (r *Report) Fail(i int, rowErrors []string) {
+	r.Rows[i].Status = RowStatusError
+	r.Rows[i].Errors = rowErrors
+	r.Failed++
+}
+
+// Accept marks a row as valid with the action importing it will take
+func (r *Report) Accept(i int, action, uuid string) {
+	r.Rows[i].Status = RowStatusValid
+	r.Rows[i].Action = action
+	r.Rows[i].UUID = uuid
+	r.Valid++
+}
+
+// Imported marks the valid rows as imported after the records were written
+func (r *Report) Imported() {
+	for i := range r.Rows {
+		row := &r.Rows[i]
+		if row.Status != RowStatusValid {
+			continue
+		}
+		switch row.Action {
+		case ActionCreate:
+			row.Status = RowStatusCreated
+			r.Created++
+		case ActionUpdate:
+			row.Status = RowStatusUpdated
+			r.Updated++
+		default:
+			row.Status = RowStatusUnchanged
+			r.Unchanged++
+		}
+	}
+}
+
+// FieldErrors returns readable messages for the validation errors of a request, one per field
+// Fields are named like the import columns (FirstName as first_name)
+func FieldErrors(err error) []string {
+	var fieldErrors validator.ValidationErrors
+	if !stderrors.As(err, &fieldErrors) {
+		if appErr, ok := err.(*errors.AppError); ok {
+			return []string{appErr.Details}
+		}
+		return []string{err.Error()}
+	}
+
+	messages := make([]string, len(fieldErrors))
+	for i, fieldError := range fieldErrors {
+		field := snakeCase(fieldError.Field())
+		switch fieldError.Tag() {
+		case "required":
+			messages[i] = field + " is required"
+		case "email":
+			messages[i] = field + " must be a valid email"
+		case "uuid":
+			messages[i] = field + " must be a UUID"
+		case "min":
+			messages[i] = fmt.Sprintf("%s must be at least %s characters", field, fieldError.Param())
+		case "max":
+			messages[i] = fmt.Sprintf("%s must be at most %s characters", field, fieldError.Param())
+		default:
+			messages[i] = fmt.Sprintf("%s is invalid (%s)", field, fieldError.Tag())
+		}
+	}
+	return messages
+}
+
+// snakeCase turns a Go field name into a column name, e.g. TeacherID into teacher_id
+func snakeCase(name string) string {
+	runes := []rune(name)
+	result := make([]rune, 0, len(runes)+2)
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 && unicode.IsLower(runes[i-1]) {
+			result = append(result, '_')
+		}
+		result = append(result, unicode.ToLower(r))
+	}
+	return string(result)
+}
+
+// RowError prefixes an error writing the record of a row with its line, keeping the status of application errors
+func RowError(line int, err error) error {
+	if appErr, ok := err.(*errors.AppError); ok {
+		return errors.NewAppError(appErr.Code, appErr.Message, fmt.Sprintf("row %d: %s", line, appErr.Details))
+	}
+	return fmt.Errorf("row %d: %w", line, err)
+}
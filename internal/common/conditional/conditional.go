@@ -0,0 +1,121 @@
+package conditional
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/errors"
+)
+
+// Precondition is the If-Match header of a write, the versions of a record the client expects to change
+// The zero value matches no version, so a missing precondition fails closed
+type Precondition struct {
+	any      bool
+	versions []int
+}
+
+// Any matches every version, sent as If-Match: *
+var Any = Precondition{any: true}
+
+// Version matches only the given version
+func Version(version int) Precondition {
+	return Precondition{versions: []int{version}}
+}
+
+// Matches reports whether the current version of a record satisfies the precondition
+func (p Precondition) Matches(version int) bool {
+	if p.any {
+		return true
+	}
+	for _, v := range p.versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// Check returns a precondition failed error if the record of the resource was changed in the meantime
+func (p Precondition) Check(resource string, version int) error {
+	if p.Matches(version) {
+		return nil
+	}
+	return errors.PreconditionFailed(fmt.Sprintf("%s has been modified, current version is %d", resource, version))
+}
+
+// ETag returns the strong entity tag of a record version
+func ETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// SetETag sets the ETag header of the response to the record version
+func SetETag(c *fiber.Ctx, version int) {
+	c.Set(fiber.HeaderETag, ETag(version))
+}
+
+// IfMatch parses the If-Match header of a write
+// The header is required so that concurrent writes can't overwrite each other unnoticed.
+// Weak and malformed tags never match, If-Match uses the strong comparison (RFC 9110, 13.1.1)
+func IfMatch(c *fiber.Ctx) (Precondition, error) {
+	header := strings.TrimSpace(c.Get(fiber.HeaderIfMatch))
+	if header == "" {
+		return Precondition{}, errors.PreconditionRequired("If-Match header with the ETag of the record is required")
+	}
+	if header == "*" {
+		return Any, nil
+	}
+
+	var p Precondition
+	for _, tag := range strings.Split(header, ",") {
+		if version, ok := parseETag(strings.TrimSpace(tag)); ok {
+			p.versions = append(p.versions, version)
+		}
+	}
+	return p, nil
+}
+
+// NotModified reports whether the If-None-Match header of a read matches the record version,
+// in which case the handler answers 304 Not Modified instead of sending the record again.
+// If-None-Match uses the weak comparison, so weak tags match as well (RFC 9110, 13.1.2)
+func NotModified(c *fiber.Ctx, version int) bool {
+	header := strings.TrimSpace(c.Get(fiber.HeaderIfNoneMatch))
+	if header == "" {
+		return false
+	}
+	if header == "*" {
+		return true
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if v, ok := parseETag(tag); ok && v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// Respond sends the record with its ETag, or 304 Not Modified if the client's copy is current
+func Respond(c *fiber.Ctx, version int, send func() error) error {
+	SetETag(c, version)
+	if NotModified(c, version) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+	return send()
+}
+
+// parseETag returns the version of a strong entity tag
+func parseETag(tag string) (int, bool) {
+	unquoted, err := strconv.Unquote(tag)
+	if err != nil || !strings.HasPrefix(tag, `"`) {
+		return 0, false
+	}
+	version, err := strconv.Atoi(unquoted)
+	if err != nil || version < 1 {
+		return 0, false
+	}
+	return version, true
+}
@@ -0,0 +1,111 @@
+package conditional
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/errors"
+)
+
+func TestIfMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		wantCode int
+		matches  []int
+		rejects  []int
+	}{
+		{name: "missing header", header: "", wantCode: 428},
+		{name: "single version", header: `"3"`, matches: []int{3}, rejects: []int{2, 4}},
+		{name: "list of versions", header: `"2", "5"`, matches: []int{2, 5}, rejects: []int{3}},
+		{name: "any version", header: "*", matches: []int{1, 42}},
+		{name: "weak tag never matches", header: `W/"3"`, rejects: []int{3}},
+		{name: "malformed tags never match", header: `3, "abc", "0"`, rejects: []int{0, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			var got Precondition
+			var gotErr error
+			app.Put("/", func(c *fiber.Ctx) error {
+				got, gotErr = IfMatch(c)
+				return nil
+			})
+
+			req := httptest.NewRequest("PUT", "/", nil)
+			if tt.header != "" {
+				req.Header.Set("If-Match", tt.header)
+			}
+			_, err := app.Test(req)
+			require.NoError(t, err)
+
+			if tt.wantCode != 0 {
+				var appErr *errors.AppError
+				require.ErrorAs(t, gotErr, &appErr)
+				assert.Equal(t, tt.wantCode, appErr.Code)
+				return
+			}
+			require.NoError(t, gotErr)
+			for _, v := range tt.matches {
+				assert.True(t, got.Matches(v), "version %d", v)
+			}
+			for _, v := range tt.rejects {
+				assert.False(t, got.Matches(v), "version %d", v)
+			}
+		})
+	}
+}
+
+func TestPrecondition_Check(t *testing.T) {
+	assert.NoError(t, Version(2).Check("student", 2))
+	assert.NoError(t, Any.Check("student", 7))
+
+	err := Version(1).Check("student", 2)
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, 412, appErr.Code)
+
+	// The zero value fails closed
+	assert.Error(t, Precondition{}.Check("student", 1))
+}
+
+func TestRespond(t *testing.T) {
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		wantStatus  int
+	}{
+		{name: "no condition", wantStatus: 200},
+		{name: "current version", ifNoneMatch: `"4"`, wantStatus: 304},
+		{name: "weak tag of current version", ifNoneMatch: `W/"4"`, wantStatus: 304},
+		{name: "one of several tags", ifNoneMatch: `"2", "4"`, wantStatus: 304},
+		{name: "any version", ifNoneMatch: "*", wantStatus: 304},
+		{name: "stale version", ifNoneMatch: `"3"`, wantStatus: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/", func(c *fiber.Ctx) error {
+				return Respond(c, 4, func() error {
+					return c.SendString("record")
+				})
+			})
+
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+			assert.Equal(t, `"4"`, resp.Header.Get("ETag"))
+		})
+	}
+}
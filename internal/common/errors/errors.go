@@ -58,6 +58,22 @@ func Internal(message string) *AppError {
 	return InternalServerError(message)
 }
 
+func TooManyRequests(message string) *AppError {
+	return NewAppError(http.StatusTooManyRequests, "Too Many Requests", message)
+}
+
+func PreconditionFailed(message string) *AppError {
+	return NewAppError(http.StatusPreconditionFailed, "Precondition Failed", message)
+}
+
+func PreconditionRequired(message string) *AppError {
+	return NewAppError(http.StatusPreconditionRequired, "Precondition Required", message)
+}
+
+func UnsupportedMediaType(message string) *AppError {
+	return NewAppError(http.StatusUnsupportedMediaType, "Unsupported Media Type", message)
+}
+
 // IsUniqueViolation checks if the error is a unique constraint violation
 func IsUniqueViolation(err error) bool {
 	if err == nil {
@@ -101,6 +101,38 @@ func TestInternal(t *testing.T) {
 	assert.Equal(t, "unexpected error", err.Details)
 }
 
+func TestTooManyRequests(t *testing.T) {
+	err := TooManyRequests("slow down")
+
+	assert.Equal(t, 429, err.Code)
+	assert.Equal(t, "Too Many Requests", err.Message)
+	assert.Equal(t, "slow down", err.Details)
+}
+
+func TestPreconditionFailed(t *testing.T) {
+	err := PreconditionFailed("version mismatch")
+
+	assert.Equal(t, 412, err.Code)
+	assert.Equal(t, "Precondition Failed", err.Message)
+	assert.Equal(t, "version mismatch", err.Details)
+}
+
+func TestPreconditionRequired(t *testing.T) {
+	err := PreconditionRequired("If-Match header required")
+
+	assert.Equal(t, 428, err.Code)
+	assert.Equal(t, "Precondition Required", err.Message)
+	assert.Equal(t, "If-Match header required", err.Details)
+}
+
+func TestUnsupportedMediaType(t *testing.T) {
+	err := UnsupportedMediaType("expected JSON")
+
+	assert.Equal(t, 415, err.Code)
+	assert.Equal(t, "Unsupported Media Type", err.Message)
+	assert.Equal(t, "expected JSON", err.Details)
+}
+
 func TestIsUniqueViolation(t *testing.T) {
 	tests := []struct {
 		name string
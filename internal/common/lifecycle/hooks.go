@@ -0,0 +1,25 @@
+package lifecycle
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// DeleteHook runs inside the transaction that deletes a student or teacher record
+// uuid is the UUID of the deleted record. Returning an error rolls back the deletion
+type DeleteHook func(ctx context.Context, tx *gorm.DB, uuid string) error
+
+// DeleteHooks are the hooks registered by other domains for one kind of record
+// They let a domain react to deletions without the deleting domain importing it
+type DeleteHooks []DeleteHook
+
+// Run runs the hooks in the order they were registered and stops at the first error
+func (h DeleteHooks) Run(ctx context.Context, tx *gorm.DB, uuid string) error {
+	for _, hook := range h {
+		if err := hook(ctx, tx, uuid); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestDeleteHooks_Run(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("runs hooks in order", func(t *testing.T) {
+		var calls []string
+		hooks := DeleteHooks{
+			func(ctx context.Context, tx *gorm.DB, uuid string) error {
+				calls = append(calls, "first "+uuid)
+				return nil
+			},
+			func(ctx context.Context, tx *gorm.DB, uuid string) error {
+				calls = append(calls, "second "+uuid)
+				return nil
+			},
+		}
+
+		assert.NoError(t, hooks.Run(ctx, nil, "record-1"))
+		assert.Equal(t, []string{"first record-1", "second record-1"}, calls)
+	})
+
+	t.Run("stops at the first error", func(t *testing.T) {
+		hookErr := errors.New("hook failed")
+		called := false
+		hooks := DeleteHooks{
+			func(ctx context.Context, tx *gorm.DB, uuid string) error { return hookErr },
+			func(ctx context.Context, tx *gorm.DB, uuid string) error {
+				called = true
+				return nil
+			},
+		}
+
+		assert.ErrorIs(t, hooks.Run(ctx, nil, "record-1"), hookErr)
+		assert.False(t, called)
+	})
+
+	t.Run("no hooks", func(t *testing.T) {
+		assert.NoError(t, DeleteHooks(nil).Run(ctx, nil, "record-1"))
+	})
+}
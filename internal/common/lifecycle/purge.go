@@ -0,0 +1,100 @@
+package lifecycle
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/JustDoItBetter/FITS-backend/pkg/logger"
+)
+
+// PurgeFunc permanently removes the records deleted before the given time and returns their number
+type PurgeFunc func(ctx context.Context, before time.Time) (int64, error)
+
+// purgeTarget is one kind of record removed by the Purger
+type purgeTarget struct {
+	name  string
+	purge PurgeFunc
+}
+
+// Purger permanently removes soft-deleted records in the background once their retention time has passed
+// Until then they stay in the trash and can be restored
+type Purger struct {
+	retention time.Duration
+	interval  time.Duration
+	targets   []purgeTarget
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// NewPurger creates a background purger, register the records with Register and call Start to begin purging
+func NewPurger(retention, interval time.Duration) *Purger {
+	return &Purger{
+		retention: retention,
+		interval:  interval,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// Register adds a kind of record, name is used in the logs
+// Records are purged in the order they were registered
+func (p *Purger) Register(name string, purge PurgeFunc) {
+	p.targets = append(p.targets, purgeTarget{name: name, purge: purge})
+}
+
+// Start purges expired records until Stop is called
+func (p *Purger) Start() {
+	go func() {
+		defer close(p.done)
+
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		for {
+			p.purgeExpired(context.Background(), time.Now())
+
+			select {
+			case <-p.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop waits for the current purge to finish and stops purging
+func (p *Purger) Stop() {
+	p.once.Do(func() {
+		close(p.stop)
+	})
+	<-p.done
+}
+
+// purgeExpired removes the records deleted longer than the retention time ago and returns their number
+// A failing kind of record is logged and doesn't keep the others from being purged
+func (p *Purger) purgeExpired(ctx context.Context, now time.Time) int64 {
+	before := now.Add(-p.retention)
+
+	var total int64
+	for _, target := range p.targets {
+		purged, err := target.purge(ctx, before)
+		if err != nil {
+			logger.Error("Failed to purge deleted records", zap.String("records", target.name), zap.Error(err))
+			continue
+		}
+		if purged > 0 {
+			logger.Info("Purged deleted records",
+				zap.String("records", target.name),
+				zap.Int64("purged", purged),
+				zap.Time("deleted_before", before),
+			)
+		}
+		total += purged
+	}
+	return total
+}
@@ -0,0 +1,67 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPurger_PurgeExpired(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("purges records deleted before the retention time", func(t *testing.T) {
+		var calls []string
+		var cutoff time.Time
+		purger := NewPurger(30*24*time.Hour, time.Hour)
+		purger.Register("students", func(ctx context.Context, before time.Time) (int64, error) {
+			calls = append(calls, "students")
+			cutoff = before
+			return 2, nil
+		})
+		purger.Register("teachers", func(ctx context.Context, before time.Time) (int64, error) {
+			calls = append(calls, "teachers")
+			return 1, nil
+		})
+
+		assert.Equal(t, int64(3), purger.purgeExpired(ctx, now))
+		assert.Equal(t, []string{"students", "teachers"}, calls)
+		assert.Equal(t, now.Add(-30*24*time.Hour), cutoff)
+	})
+
+	t.Run("a failing purge doesn't stop the others", func(t *testing.T) {
+		purger := NewPurger(time.Hour, time.Hour)
+		purger.Register("students", func(ctx context.Context, before time.Time) (int64, error) {
+			return 0, errors.New("database unavailable")
+		})
+		purger.Register("teachers", func(ctx context.Context, before time.Time) (int64, error) {
+			return 4, nil
+		})
+
+		assert.Equal(t, int64(4), purger.purgeExpired(ctx, now))
+	})
+}
+
+func TestPurger_StartStop(t *testing.T) {
+	purged := make(chan struct{}, 1)
+	purger := NewPurger(time.Hour, time.Hour)
+	purger.Register("students", func(ctx context.Context, before time.Time) (int64, error) {
+		select {
+		case purged <- struct{}{}:
+		default:
+		}
+		return 0, nil
+	})
+
+	purger.Start()
+	select {
+	case <-purged:
+	case <-time.After(time.Second):
+		t.Fatal("purger didn't purge on start")
+	}
+	purger.Stop()
+	purger.Stop() // Stopping twice is safe
+}
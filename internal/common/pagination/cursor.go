@@ -0,0 +1,273 @@
+package pagination
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/errors"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Cursor is the position of a record in a list ordered by (created_at, id)
+// Keyset pagination continues from the cursor instead of skipping OFFSET rows, so deep pages stay
+// fast and rows inserted meanwhile don't shift the following pages
+type Cursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        string    `json:"i"`
+	Backward  bool      `json:"b,omitempty"` // Page before the record (prev link) instead of after it
+}
+
+// compare orders two cursors ascending by (created_at, id)
+func (c Cursor) compare(other Cursor) int {
+	if n := c.CreatedAt.Compare(other.CreatedAt); n != 0 {
+		return n
+	}
+	return strings.Compare(c.ID, other.ID)
+}
+
+// CursorCodec turns cursors into opaque tokens and back
+// Tokens are signed so clients can't forge positions, they have to follow the links they were given
+type CursorCodec struct {
+	key []byte
+}
+
+// NewCursorCodec creates a codec signing with a key derived from secret
+// Deriving the key keeps cursor signatures apart from other uses of the same secret (e.g. the JWT secret)
+func NewCursorCodec(secret string) *CursorCodec {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("pagination-cursor"))
+	return &CursorCodec{key: mac.Sum(nil)}
+}
+
+// Encode returns the opaque token for a cursor
+func (c *CursorCodec) Encode(cursor Cursor) string {
+	payload, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(c.sign(payload))
+}
+
+// Decode verifies a token and returns its cursor
+func (c *CursorCodec) Decode(token string) (*Cursor, error) {
+	invalid := errors.ValidationError("invalid cursor")
+
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, invalid
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, invalid
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, c.sign(payload)) {
+		return nil, invalid
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(payload, &cursor); err != nil || cursor.ID == "" {
+		return nil, invalid
+	}
+	return &cursor, nil
+}
+
+func (c *CursorCodec) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// PageInfo describes a page of a list
+type PageInfo struct {
+	TotalCount int64
+	Next       *Cursor // Keyset pagination, nil on the last page
+	Prev       *Cursor // Keyset pagination, nil on the first page
+}
+
+// ExtractCursorParams parses pagination parameters, switching to keyset pagination if the cursor
+// query parameter is given. An empty cursor starts at the first page, offset pagination stays the default
+func ExtractCursorParams(c *fiber.Ctx, codec *CursorCodec) (Params, error) {
+	params := ExtractParams(c)
+	if !c.Context().QueryArgs().Has("cursor") {
+		return params, nil
+	}
+
+	params.Keyset = true
+	params.Page = 0
+	if token := c.Query("cursor"); token != "" {
+		cursor, err := codec.Decode(token)
+		if err != nil {
+			return Params{}, err
+		}
+		params.Cursor = cursor
+	}
+	return params, nil
+}
+
+// KeysetSort reports the created_at direction for keyset pagination
+// Keyset pagination only supports sorting by created_at, ok is false for any other sort
+func KeysetSort(sort []SortField) (desc bool, ok bool) {
+	switch {
+	case len(sort) == 0:
+		return true, true
+	case len(sort) == 1 && sort[0].Field == "created_at":
+		return sort[0].Desc, true
+	default:
+		return false, false
+	}
+}
+
+// backward reports whether the page is read against the sort order (towards the prev link)
+func (p Params) backward() bool {
+	return p.Cursor != nil && p.Cursor.Backward
+}
+
+// KeysetCondition returns the WHERE condition selecting the records following the cursor in query order
+func KeysetCondition(cursor Cursor, desc bool) (string, []interface{}) {
+	op := ">"
+	if desc != cursor.Backward {
+		op = "<"
+	}
+	return "(created_at " + op + " ? OR (created_at = ? AND id " + op + " ?))",
+		[]interface{}{cursor.CreatedAt, cursor.CreatedAt, cursor.ID}
+}
+
+// KeysetOrder returns the ORDER BY clause of a keyset query
+// Backward pages are queried in reverse and flipped by KeysetPage
+func KeysetOrder(params Params, desc bool) string {
+	if desc != params.backward() {
+		return "created_at DESC, id DESC"
+	}
+	return "created_at ASC, id ASC"
+}
+
+// KeysetPage turns the records of a keyset query into a page
+// Queries fetch Limit+1 records in query order, the extra record only tells that another page follows.
+// key returns the position of a record
+func KeysetPage[T any](records []T, params Params, key func(T) Cursor) ([]T, PageInfo) {
+	more := len(records) > params.Limit
+	if more {
+		records = records[:params.Limit]
+	}
+	if params.backward() {
+		slices.Reverse(records)
+	}
+
+	var info PageInfo
+	if len(records) == 0 {
+		// Past either end, link back to the records on the other side of the cursor
+		if params.Cursor != nil {
+			back := *params.Cursor
+			back.Backward = !back.Backward
+			if back.Backward {
+				info.Prev = &back
+			} else {
+				info.Next = &back
+			}
+		}
+		return records, info
+	}
+
+	first, last := key(records[0]), key(records[len(records)-1])
+	first.Backward = true
+	if params.backward() {
+		info.Next = &last
+		if more {
+			info.Prev = &first
+		}
+	} else {
+		if more {
+			info.Next = &last
+		}
+		if params.Cursor != nil {
+			info.Prev = &first
+		}
+	}
+	return records, info
+}
+
+// KeysetSlice pages through records held in memory the way a keyset query does
+func KeysetSlice[T any](records []T, params Params, desc bool, key func(T) Cursor) ([]T, PageInfo) {
+	reverse := desc != params.backward()
+	ordered := slices.Clone(records)
+	slices.SortFunc(ordered, func(a, b T) int {
+		if reverse {
+			return key(b).compare(key(a))
+		}
+		return key(a).compare(key(b))
+	})
+
+	page := make([]T, 0, params.Limit+1)
+	for _, record := range ordered {
+		if len(page) > params.Limit {
+			break
+		}
+		if params.Cursor != nil {
+			c := key(record).compare(*params.Cursor)
+			if c == 0 || (c > 0) == reverse {
+				continue
+			}
+		}
+		page = append(page, record)
+	}
+	return KeysetPage(page, params, key)
+}
+
+// NewPageResponse creates the response for a page of a list supporting both offset and keyset pagination
+func NewPageResponse(data interface{}, params Params, info PageInfo, codec *CursorCodec) Response {
+	if !params.Keyset {
+		return NewResponse(data, params, info.TotalCount)
+	}
+
+	response := Response{
+		Data:       data,
+		Limit:      params.Limit,
+		TotalCount: info.TotalCount,
+		Success:    true,
+	}
+	if info.Next != nil {
+		response.NextCursor = codec.Encode(*info.Next)
+	}
+	if info.Prev != nil {
+		response.PrevCursor = codec.Encode(*info.Prev)
+	}
+	return response
+}
+
+// SetLinkHeader sets the RFC 8288 Link header pointing to the neighbouring pages of a response
+// Links are relative to the request and keep its other query parameters (filters, sort, limit)
+func SetLinkHeader(c *fiber.Ctx, response Response) {
+	query, _ := url.ParseQuery(string(c.Request().URI().QueryString()))
+	links := make([]string, 0, 4)
+	link := func(rel, param, value string) {
+		query.Set(param, value)
+		links = append(links, "<"+c.Path()+"?"+query.Encode()+`>; rel="`+rel+`"`)
+	}
+
+	if response.Page == 0 {
+		query.Del("page")
+		link("first", "cursor", "")
+		if response.PrevCursor != "" {
+			link("prev", "cursor", response.PrevCursor)
+		}
+		if response.NextCursor != "" {
+			link("next", "cursor", response.NextCursor)
+		}
+	} else {
+		query.Del("cursor")
+		link("first", "page", "1")
+		if response.Page > 1 {
+			link("prev", "page", strconv.Itoa(response.Page-1))
+		}
+		if response.Page < response.TotalPages {
+			link("next", "page", strconv.Itoa(response.Page+1))
+		}
+		link("last", "page", strconv.Itoa(response.TotalPages))
+	}
+	c.Set(fiber.HeaderLink, strings.Join(links, ", "))
+}
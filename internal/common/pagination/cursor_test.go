@@ -0,0 +1,154 @@
+package pagination
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestCursorCodec(t *testing.T) {
+	codec := NewCursorCodec("test-secret-at-least-32-characters-long")
+	cursor := Cursor{CreatedAt: time.Date(2025, 9, 30, 12, 0, 0, 123456000, time.UTC), ID: "550e8400-e29b-41d4-a716-446655440000", Backward: true}
+
+	t.Run("round trip", func(t *testing.T) {
+		decoded, err := codec.Decode(codec.Encode(cursor))
+		require.NoError(t, err)
+		assert.True(t, cursor.CreatedAt.Equal(decoded.CreatedAt))
+		assert.Equal(t, cursor.ID, decoded.ID)
+		assert.True(t, decoded.Backward)
+	})
+
+	t.Run("rejects invalid tokens", func(t *testing.T) {
+		token := codec.Encode(cursor)
+		other := NewCursorCodec("another-secret-at-least-32-characters")
+		forged := codec.Encode(Cursor{CreatedAt: cursor.CreatedAt, ID: "other"})
+
+		for name, token := range map[string]string{
+			"garbage":         "not-a-cursor",
+			"other key":       other.Encode(cursor),
+			"swapped payload": forged[:len(forged)-43] + token[len(token)-43:],
+			"bad signature":   token + "x",
+		} {
+			_, err := codec.Decode(token)
+			assert.Error(t, err, name)
+		}
+	})
+}
+
+func TestExtractCursorParams(t *testing.T) {
+	codec := NewCursorCodec("test-secret-at-least-32-characters-long")
+	extract := func(query string) (Params, error) {
+		app := fiber.New()
+		ctx := app.AcquireCtx(&fasthttp.RequestCtx{})
+		defer app.ReleaseCtx(ctx)
+		ctx.Request().URI().SetQueryString(query)
+		return ExtractCursorParams(ctx, codec)
+	}
+
+	params, err := extract("page=3&limit=10")
+	require.NoError(t, err)
+	assert.Equal(t, Params{Page: 3, Limit: 10}, params)
+
+	params, err = extract("cursor=&limit=10")
+	require.NoError(t, err)
+	assert.Equal(t, Params{Limit: 10, Keyset: true}, params)
+
+	params, err = extract("cursor=" + codec.Encode(Cursor{ID: "id-1"}))
+	require.NoError(t, err)
+	assert.True(t, params.Keyset)
+	require.NotNil(t, params.Cursor)
+	assert.Equal(t, "id-1", params.Cursor.ID)
+
+	_, err = extract("cursor=forged")
+	assert.Error(t, err)
+}
+
+func TestKeysetSlice(t *testing.T) {
+	type record struct {
+		id string
+		at time.Time
+	}
+	key := func(r record) Cursor { return Cursor{CreatedAt: r.at, ID: r.id} }
+	day := func(d int) time.Time { return time.Date(2025, 9, d, 0, 0, 0, 0, time.UTC) }
+	records := []record{{"a", day(1)}, {"b", day(2)}, {"c", day(2)}, {"d", day(3)}}
+	ids := func(page []record) []string {
+		result := make([]string, len(page))
+		for i, r := range page {
+			result[i] = r.id
+		}
+		return result
+	}
+
+	page, info := KeysetSlice(records, Params{Limit: 3, Keyset: true}, true, key)
+	assert.Equal(t, []string{"d", "c", "b"}, ids(page))
+	assert.Nil(t, info.Prev)
+	require.NotNil(t, info.Next)
+
+	page, info = KeysetSlice(records, Params{Limit: 3, Keyset: true, Cursor: info.Next}, true, key)
+	assert.Equal(t, []string{"a"}, ids(page))
+	assert.Nil(t, info.Next)
+	require.NotNil(t, info.Prev)
+
+	page, info = KeysetSlice(records, Params{Limit: 3, Keyset: true, Cursor: info.Prev}, true, key)
+	assert.Equal(t, []string{"d", "c", "b"}, ids(page))
+	assert.Nil(t, info.Prev)
+	assert.NotNil(t, info.Next)
+
+	t.Run("empty page links back", func(t *testing.T) {
+		past := &Cursor{CreatedAt: day(1), ID: "a"}
+		page, info := KeysetSlice(records, Params{Limit: 3, Keyset: true, Cursor: past}, true, key)
+		assert.Empty(t, page)
+		assert.Nil(t, info.Next)
+		require.NotNil(t, info.Prev)
+		assert.True(t, info.Prev.Backward)
+	})
+}
+
+func TestKeysetSort(t *testing.T) {
+	desc, ok := KeysetSort(nil)
+	assert.True(t, desc)
+	assert.True(t, ok)
+
+	desc, ok = KeysetSort([]SortField{{Field: "created_at"}})
+	assert.False(t, desc)
+	assert.True(t, ok)
+
+	_, ok = KeysetSort([]SortField{{Field: "last_name"}})
+	assert.False(t, ok)
+}
+
+func TestSetLinkHeader(t *testing.T) {
+	link := func(query string, response Response) string {
+		app := fiber.New()
+		ctx := app.AcquireCtx(&fasthttp.RequestCtx{})
+		defer app.ReleaseCtx(ctx)
+		ctx.Request().SetRequestURI("/api/v1/student?" + query)
+		ctx.Path("/api/v1/student")
+		SetLinkHeader(ctx, response)
+		return string(ctx.Response().Header.Peek(fiber.HeaderLink))
+	}
+
+	t.Run("offset pagination", func(t *testing.T) {
+		header := link("page=2&search=max", Response{Page: 2, TotalPages: 3})
+		assert.Equal(t, `</api/v1/student?page=1&search=max>; rel="first", `+
+			`</api/v1/student?page=1&search=max>; rel="prev", `+
+			`</api/v1/student?page=3&search=max>; rel="next", `+
+			`</api/v1/student?page=3&search=max>; rel="last"`, header)
+	})
+
+	t.Run("first of a single page", func(t *testing.T) {
+		header := link("", Response{Page: 1, TotalPages: 1})
+		assert.Equal(t, `</api/v1/student?page=1>; rel="first", </api/v1/student?page=1>; rel="last"`, header)
+	})
+
+	t.Run("keyset pagination", func(t *testing.T) {
+		header := link("cursor=old&limit=5", Response{NextCursor: "next", PrevCursor: "prev"})
+		assert.Equal(t, `</api/v1/student?cursor=&limit=5>; rel="first", `+
+			`</api/v1/student?cursor=prev&limit=5>; rel="prev", `+
+			`</api/v1/student?cursor=next&limit=5>; rel="next"`, header)
+	})
+}
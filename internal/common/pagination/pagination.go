@@ -20,6 +20,10 @@ const (
 type Params struct {
 	Page  int `json:"page" example:"1"`
 	Limit int `json:"limit" example:"20"`
+
+	// Keyset pagination, see ExtractCursorParams. Page is ignored when set
+	Keyset bool    `json:"-"`
+	Cursor *Cursor `json:"-"` // nil for the first page
 }
 
 // Offset calculates the database offset from page number and limit
@@ -31,10 +35,12 @@ func (p Params) Offset() int {
 // Response wraps paginated data with metadata for client navigation
 type Response struct {
 	Data       interface{} `json:"data"`
-	Page       int         `json:"page" example:"1"`
+	Page       int         `json:"page,omitempty" example:"1"` // Offset pagination only
 	Limit      int         `json:"limit" example:"20"`
 	TotalCount int64       `json:"total_count" example:"150"`
-	TotalPages int         `json:"total_pages" example:"8"`
+	TotalPages int         `json:"total_pages,omitempty" example:"8"` // Offset pagination only
+	NextCursor string      `json:"next_cursor,omitempty"`             // Keyset pagination, empty on the last page
+	PrevCursor string      `json:"prev_cursor,omitempty"`             // Keyset pagination, empty on the first page
 	Success    bool        `json:"success" example:"true"`
 }
 
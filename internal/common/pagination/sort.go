@@ -0,0 +1,111 @@
+package pagination
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/errors"
+)
+
+// MaxSortFields is the maximum number of fields a list can be sorted by
+const MaxSortFields = 3
+
+// SortField orders a list by one field
+type SortField struct {
+	Field string // Whitelisted field name, equal to the column name
+	Desc  bool
+}
+
+// DefaultSort lists the most recent records first
+var DefaultSort = []SortField{{Field: "created_at", Desc: true}}
+
+// ParseSort parses a sort query parameter such as "last_name,-created_at"
+// Fields are separated by commas, a leading "-" sorts descending. Only fields in allowed are accepted,
+// so the result can be used in ORDER BY clauses. An empty value returns DefaultSort
+func ParseSort(value string, allowed []string) ([]SortField, error) {
+	if strings.TrimSpace(value) == "" {
+		return DefaultSort, nil
+	}
+
+	parts := strings.Split(value, ",")
+	if len(parts) > MaxSortFields {
+		return nil, errors.ValidationError(fmt.Sprintf("sort accepts at most %d fields", MaxSortFields))
+	}
+
+	fields := make([]SortField, 0, len(parts))
+	seen := make(map[string]bool, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		field := SortField{Field: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+		if !slices.Contains(allowed, field.Field) {
+			return nil, errors.ValidationError(fmt.Sprintf("can't sort by '%s', allowed: %s", field.Field, strings.Join(allowed, ", ")))
+		}
+		if seen[field.Field] {
+			return nil, errors.ValidationError(fmt.Sprintf("sort field '%s' is given twice", field.Field))
+		}
+		seen[field.Field] = true
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// OrderBy returns the ORDER BY clause for sort, falling back to DefaultSort
+// tiebreak is appended ascending so pages are stable when sort values repeat
+func OrderBy(sort []SortField, tiebreak string) string {
+	if len(sort) == 0 {
+		sort = DefaultSort
+	}
+
+	clauses := make([]string, 0, len(sort)+1)
+	for _, field := range sort {
+		direction := "ASC"
+		if field.Desc {
+			direction = "DESC"
+		}
+		clauses = append(clauses, field.Field+" "+direction)
+	}
+	return strings.Join(append(clauses, tiebreak+" ASC"), ", ")
+}
+
+// Less reports whether a record sorts before another for in-memory sorting
+// compare returns the comparison of both records for a field (-1, 0 or 1), tiebreak the comparison of their IDs
+func Less(sort []SortField, compare func(field string) int, tiebreak int) bool {
+	if len(sort) == 0 {
+		sort = DefaultSort
+	}
+
+	for _, field := range sort {
+		c := compare(field.Field)
+		if c == 0 {
+			continue
+		}
+		if field.Desc {
+			return c > 0
+		}
+		return c < 0
+	}
+	return tiebreak < 0
+}
+
+// ParseDate parses a date filter given as date (2025-09-30) or RFC 3339 timestamp
+// A date as upper bound (end) includes the whole day. An empty value returns nil
+func ParseDate(name, value string, end bool) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return nil, errors.ValidationError(fmt.Sprintf("%s must be a date (2025-09-30) or RFC 3339 timestamp", name))
+	}
+	if end {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return &t, nil
+}
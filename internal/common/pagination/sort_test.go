@@ -0,0 +1,93 @@
+package pagination
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSort(t *testing.T) {
+	allowed := []string{"last_name", "first_name", "created_at"}
+
+	tests := []struct {
+		name    string
+		value   string
+		want    []SortField
+		wantErr bool
+	}{
+		{name: "empty uses default", value: "", want: DefaultSort},
+		{name: "ascending", value: "last_name", want: []SortField{{Field: "last_name"}}},
+		{
+			name:  "multiple fields",
+			value: "last_name, -created_at",
+			want:  []SortField{{Field: "last_name"}, {Field: "created_at", Desc: true}},
+		},
+		{name: "unknown field", value: "password_hash", wantErr: true},
+		{name: "injection", value: "last_name; DROP TABLE students", wantErr: true},
+		{name: "duplicate field", value: "last_name,-last_name", wantErr: true},
+		{name: "too many fields", value: "last_name,first_name,created_at,last_name", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSort(tt.value, allowed)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestOrderBy(t *testing.T) {
+	assert.Equal(t, "created_at DESC, id ASC", OrderBy(nil, "id"))
+	assert.Equal(t, "last_name ASC, created_at DESC, id ASC",
+		OrderBy([]SortField{{Field: "last_name"}, {Field: "created_at", Desc: true}}, "id"))
+}
+
+func TestLess(t *testing.T) {
+	sort := []SortField{{Field: "a"}, {Field: "b", Desc: true}}
+	compare := func(results map[string]int) func(string) int {
+		return func(field string) int { return results[field] }
+	}
+
+	assert.True(t, Less(sort, compare(map[string]int{"a": -1}), 1))
+	assert.True(t, Less(sort, compare(map[string]int{"b": 1}), 1), "second field is descending")
+	assert.True(t, Less(sort, compare(map[string]int{}), -1), "ties are broken by the IDs")
+	assert.False(t, Less(sort, compare(map[string]int{}), 1))
+}
+
+func TestParseDate(t *testing.T) {
+	t.Run("date as lower bound", func(t *testing.T) {
+		got, err := ParseDate("created_from", "2025-09-30", false)
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2025, 9, 30, 0, 0, 0, 0, time.UTC), *got)
+	})
+
+	t.Run("date as upper bound includes the day", func(t *testing.T) {
+		got, err := ParseDate("created_to", "2025-09-30", true)
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2025, 9, 30, 23, 59, 59, 999999999, time.UTC), *got)
+	})
+
+	t.Run("timestamp", func(t *testing.T) {
+		got, err := ParseDate("created_to", "2025-09-30T12:00:00+02:00", true)
+		require.NoError(t, err)
+		assert.True(t, got.Equal(time.Date(2025, 9, 30, 10, 0, 0, 0, time.UTC)))
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		got, err := ParseDate("created_from", "", false)
+		require.NoError(t, err)
+		assert.Nil(t, got)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := ParseDate("created_from", "30.09.2025", false)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,115 @@
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/errors"
+)
+
+// ContentType is the media type of JSON merge patches (RFC 7396)
+const ContentType = "application/merge-patch+json"
+
+// Field is a member of a JSON merge patch, which is either absent, null or set to a value
+// Absent members keep the current value, null clears it
+type Field[T any] struct {
+	present bool
+	null    bool
+	value   T
+}
+
+// Value returns a member set to value, used to build patches in code
+func Value[T any](value T) Field[T] {
+	return Field[T]{present: true, value: value}
+}
+
+// Null returns a member set to null
+func Null[T any]() Field[T] {
+	return Field[T]{present: true, null: true}
+}
+
+// Present reports whether the member is part of the patch
+func (f Field[T]) Present() bool {
+	return f.present
+}
+
+// IsNull reports whether the member is null
+func (f Field[T]) IsNull() bool {
+	return f.present && f.null
+}
+
+// Get returns the value of the member and whether it is set to a value
+func (f Field[T]) Get() (T, bool) {
+	return f.value, f.present && !f.null
+}
+
+// Required returns the value of a member of a field that can't be cleared,
+// a validation error if the member is null
+func (f Field[T]) Required(name string) (T, bool, error) {
+	if f.IsNull() {
+		var zero T
+		return zero, false, errors.ValidationError(name + " can't be null")
+	}
+	value, ok := f.Get()
+	return value, ok, nil
+}
+
+// Set applies the member of a field that can't be cleared to current, normalized with clean,
+// and reports whether the value changed
+func Set[T comparable](name string, f Field[T], current *T, clean func(T) T) (bool, error) {
+	value, ok, err := f.Required(name)
+	if err != nil || !ok {
+		return false, err
+	}
+	if clean != nil {
+		value = clean(value)
+	}
+	if value == *current {
+		return false, nil
+	}
+	*current = value
+	return true, nil
+}
+
+// UnmarshalJSON records that the member is present, encoding/json only calls it for members in the document
+func (f *Field[T]) UnmarshalJSON(data []byte) error {
+	f.present = true
+	if string(bytes.TrimSpace(data)) == "null" {
+		f.null = true
+		var zero T
+		f.value = zero
+		return nil
+	}
+	f.null = false
+	return json.Unmarshal(data, &f.value)
+}
+
+// Decode parses the body of a PATCH request, a merge patch object, into dst, a struct of Fields
+// application/json is accepted as well. Members dst doesn't know are rejected
+// instead of silently ignored, so typos and read-only fields don't go unnoticed
+func Decode(c *fiber.Ctx, dst any) error {
+	mediaType, _, err := mime.ParseMediaType(c.Get(fiber.HeaderContentType))
+	if err != nil || (mediaType != ContentType && mediaType != fiber.MIMEApplicationJSON) {
+		return errors.UnsupportedMediaType("PATCH requests take " + ContentType)
+	}
+
+	body := bytes.TrimSpace(c.Body())
+	if len(body) == 0 || body[0] != '{' {
+		return errors.BadRequest("merge patch must be a JSON object")
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(dst); err != nil {
+		if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			return errors.ValidationError(fmt.Sprintf("field %s can't be patched", field))
+		}
+		return errors.BadRequest("invalid merge patch: " + err.Error())
+	}
+	return nil
+}
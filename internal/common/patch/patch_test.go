@@ -0,0 +1,126 @@
+package patch
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/errors"
+)
+
+type testPatch struct {
+	Name    Field[string] `json:"name"`
+	Teacher Field[string] `json:"teacher"`
+	Age     Field[int]    `json:"age"`
+}
+
+func decode(t *testing.T, contentType, body string) (testPatch, error) {
+	t.Helper()
+	app := fiber.New()
+	var p testPatch
+	var decodeErr error
+	app.Patch("/", func(c *fiber.Ctx) error {
+		decodeErr = Decode(c, &p)
+		return nil
+	})
+
+	req := httptest.NewRequest("PATCH", "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	_, err := app.Test(req)
+	require.NoError(t, err)
+	return p, decodeErr
+}
+
+func TestDecode(t *testing.T) {
+	t.Run("absent, null and set members", func(t *testing.T) {
+		p, err := decode(t, ContentType, `{"name": "Max", "teacher": null}`)
+		require.NoError(t, err)
+
+		name, ok := p.Name.Get()
+		assert.True(t, ok)
+		assert.Equal(t, "Max", name)
+
+		assert.True(t, p.Teacher.Present())
+		assert.True(t, p.Teacher.IsNull())
+		_, ok = p.Teacher.Get()
+		assert.False(t, ok)
+
+		assert.False(t, p.Age.Present())
+		assert.False(t, p.Age.IsNull())
+	})
+
+	t.Run("application/json with charset", func(t *testing.T) {
+		p, err := decode(t, "application/json; charset=utf-8", `{"age": 17}`)
+		require.NoError(t, err)
+
+		age, ok := p.Age.Get()
+		assert.True(t, ok)
+		assert.Equal(t, 17, age)
+	})
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantCode    int
+	}{
+		{name: "other media type", contentType: "text/plain", body: `{}`, wantCode: 415},
+		{name: "missing media type", contentType: "", body: `{}`, wantCode: 415},
+		{name: "patch replacing the record", contentType: ContentType, body: `null`, wantCode: 400},
+		{name: "array", contentType: ContentType, body: `[{"name": "Max"}]`, wantCode: 400},
+		{name: "empty body", contentType: ContentType, body: ``, wantCode: 400},
+		{name: "wrong type", contentType: ContentType, body: `{"age": "old"}`, wantCode: 400},
+		{name: "unknown member", contentType: ContentType, body: `{"uuid": "123"}`, wantCode: 422},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decode(t, tt.contentType, tt.body)
+
+			var appErr *errors.AppError
+			require.ErrorAs(t, err, &appErr)
+			assert.Equal(t, tt.wantCode, appErr.Code)
+		})
+	}
+}
+
+func TestField_Required(t *testing.T) {
+	value, ok, err := Value("Max").Required("name")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "Max", value)
+
+	_, ok, err = Field[string]{}.Required("name")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, _, err = Null[string]().Required("name")
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, 422, appErr.Code)
+	assert.Equal(t, "name can't be null", appErr.Details)
+}
+
+func TestSet(t *testing.T) {
+	name := "Max"
+
+	changed, err := Set("name", Field[string]{}, &name, strings.TrimSpace)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	changed, err = Set("name", Value(" Max "), &name, strings.TrimSpace)
+	require.NoError(t, err)
+	assert.False(t, changed, "the normalized value is unchanged")
+
+	changed, err = Set("name", Value("Moritz"), &name, nil)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "Moritz", name)
+
+	_, err = Set("name", Null[string](), &name, nil)
+	assert.Error(t, err)
+	assert.Equal(t, "Moritz", name)
+}
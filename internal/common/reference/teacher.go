@@ -0,0 +1,77 @@
+package reference
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/errors"
+)
+
+// Teacher is the part of a teacher record that references are checked against
+type Teacher struct {
+	UUID       string `gorm:"column:id"`
+	Email      string `gorm:"column:email"`
+	Department string `gorm:"column:department"`
+}
+
+// TeacherLookup finds teachers that may be referenced by students and invitations
+type TeacherLookup interface {
+	// GetActiveTeacher returns the teacher if it exists and is not soft-deleted, nil without error otherwise
+	GetActiveTeacher(ctx context.Context, uuid string) (*Teacher, error)
+}
+
+// Rules are the optional rules for assigning students to teachers
+// The zero value only requires the teacher to exist
+type Rules struct {
+	// RequireDepartmentMatch rejects a department given for a student that differs from the teacher's department
+	RequireDepartmentMatch bool
+}
+
+// CheckTeacher validates an already loaded teacher against the rules
+// teacher is nil if no active teacher was found, department is the student's department if known
+func (r Rules) CheckTeacher(teacher *Teacher, department *string) error {
+	if teacher == nil {
+		return errors.ValidationError("teacher does not exist or has been deleted")
+	}
+
+	if r.RequireDepartmentMatch && department != nil && *department != "" &&
+		!strings.EqualFold(strings.TrimSpace(*department), strings.TrimSpace(teacher.Department)) {
+		return errors.ValidationError(fmt.Sprintf("department '%s' does not match the teacher's department '%s'", *department, teacher.Department))
+	}
+
+	return nil
+}
+
+// ValidateTeacher looks up a referenced teacher and checks it against the rules
+// Pass a lookup bound to the transaction that stores the reference to re-check right before the write
+func (r Rules) ValidateTeacher(ctx context.Context, lookup TeacherLookup, teacherUUID string, department *string) error {
+	teacher, err := lookup.GetActiveTeacher(ctx, teacherUUID)
+	if err != nil {
+		return err
+	}
+	return r.CheckTeacher(teacher, department)
+}
+
+// FindActiveTeacher implements GetActiveTeacher for GORM repositories
+// Inside a transaction the row is locked against concurrent deletion until the transaction ends
+func FindActiveTeacher(ctx context.Context, db *gorm.DB, uuid string) (*Teacher, error) {
+	var teachers []Teacher
+	if err := db.WithContext(ctx).
+		Table("teachers").
+		Select("id, email, department").
+		Where("id = ? AND deleted_at IS NULL", uuid).
+		Clauses(clause.Locking{Strength: "SHARE"}).
+		Limit(1).
+		Find(&teachers).Error; err != nil {
+		return nil, fmt.Errorf("failed to look up teacher: %w", err)
+	}
+
+	if len(teachers) == 0 {
+		return nil, nil
+	}
+	return &teachers[0], nil
+}
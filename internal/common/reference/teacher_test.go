@@ -0,0 +1,92 @@
+package reference
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/errors"
+)
+
+// testTeacherModel is a simplified teachers table for SQLite testing
+type testTeacherModel struct {
+	ID         string `gorm:"column:id;primaryKey"`
+	Email      string `gorm:"column:email"`
+	Department string `gorm:"column:department"`
+	DeletedAt  *time.Time
+}
+
+func (testTeacherModel) TableName() string {
+	return "teachers"
+}
+
+// stubLookup returns the teachers it was created with
+type stubLookup map[string]*Teacher
+
+func (s stubLookup) GetActiveTeacher(ctx context.Context, uuid string) (*Teacher, error) {
+	return s[uuid], nil
+}
+
+func TestRules_ValidateTeacher(t *testing.T) {
+	ctx := context.Background()
+	lookup := stubLookup{"teacher-1": {UUID: "teacher-1", Department: "IT"}}
+	it, math := "it ", "Math"
+
+	tests := []struct {
+		name       string
+		rules      Rules
+		teacher    string
+		department *string
+		wantErr    string
+	}{
+		{"existing teacher", Rules{}, "teacher-1", nil, ""},
+		{"unknown teacher", Rules{}, "teacher-2", nil, "teacher does not exist or has been deleted"},
+		{"department ignored without rule", Rules{}, "teacher-1", &math, ""},
+		{"matching department", Rules{RequireDepartmentMatch: true}, "teacher-1", &it, ""},
+		{"no department given", Rules{RequireDepartmentMatch: true}, "teacher-1", nil, ""},
+		{"other department", Rules{RequireDepartmentMatch: true}, "teacher-1", &math, "department 'Math' does not match the teacher's department 'IT'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rules.ValidateTeacher(ctx, lookup, tt.teacher, tt.department)
+
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.IsType(t, &errors.AppError{}, err)
+			assert.Equal(t, 422, err.(*errors.AppError).Code)
+			assert.Equal(t, tt.wantErr, err.(*errors.AppError).Details)
+		})
+	}
+}
+
+func TestFindActiveTeacher(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&testTeacherModel{}))
+
+	deletedAt := time.Now()
+	require.NoError(t, db.Create(&testTeacherModel{ID: "teacher-1", Email: "anna@example.com", Department: "IT"}).Error)
+	require.NoError(t, db.Create(&testTeacherModel{ID: "teacher-2", Email: "tom@example.com", Department: "Math", DeletedAt: &deletedAt}).Error)
+
+	ctx := context.Background()
+
+	teacher, err := FindActiveTeacher(ctx, db, "teacher-1")
+	require.NoError(t, err)
+	assert.Equal(t, &Teacher{UUID: "teacher-1", Email: "anna@example.com", Department: "IT"}, teacher)
+
+	teacher, err = FindActiveTeacher(ctx, db, "teacher-2")
+	require.NoError(t, err)
+	assert.Nil(t, teacher, "deleted teachers can't be referenced")
+
+	teacher, err = FindActiveTeacher(ctx, db, "teacher-3")
+	require.NoError(t, err)
+	assert.Nil(t, teacher)
+}
@@ -2,19 +2,27 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/BurntSushi/toml"
 )
 
 type Config struct {
-	Server   ServerConfig   `toml:"server"`
-	Secrets  SecretsConfig  `toml:"secrets"`
-	Storage  StorageConfig  `toml:"storage"`
-	Logging  LoggingConfig  `toml:"logging"`
-	Database DatabaseConfig `toml:"database"`
-	JWT      JWTConfig      `toml:"jwt"`
+	Server     ServerConfig     `toml:"server"`
+	Storage    StorageConfig    `toml:"storage"`
+	Logging    LoggingConfig    `toml:"logging"`
+	Database   DatabaseConfig   `toml:"database"`
+	JWT        JWTConfig        `toml:"jwt"`
+	Security   SecurityConfig   `toml:"security"`
+	WebAuthn   WebAuthnConfig   `toml:"webauthn"`
+	Password   PasswordConfig   `toml:"password"`
+	Mail       MailConfig       `toml:"mail"`
+	Assignment AssignmentConfig `toml:"assignment"`
+	OIDC       OIDCConfig       `toml:"oidc"`
+	Retention  RetentionConfig  `toml:"retention"`
 }
 
 type ServerConfig struct {
@@ -24,6 +32,7 @@ type ServerConfig struct {
 	WriteTimeout   string `toml:"write_timeout"`
 	AllowedOrigins string `toml:"allowed_origins"` // Comma-separated list of allowed CORS origins
 	RateLimit      int    `toml:"rate_limit"`      // Max requests per minute per IP (0 = unlimited)
+	PublicBaseURL  string `toml:"public_base_url"` // URL users reach the frontend at, used for links in emails
 
 	// TLS/HTTPS Configuration
 	TLSEnabled      bool   `toml:"tls_enabled"`       // Enable HTTPS/TLS
@@ -32,13 +41,6 @@ type ServerConfig struct {
 	TLSAutoRedirect bool   `toml:"tls_auto_redirect"` // Auto-redirect HTTP to HTTPS
 }
 
-type SecretsConfig struct {
-	MetricsSecret      string `toml:"metrics_secret"`
-	RegistrationSecret string `toml:"registration_secret"`
-	DeletionSecret     string `toml:"deletion_secret"`
-	UpdateSecret       string `toml:"update_secret"`
-}
-
 type StorageConfig struct {
 	UploadDir   string `toml:"upload_dir"`
 	MaxFileSize int64  `toml:"max_file_size"`
@@ -105,6 +107,411 @@ func (j *JWTConfig) GetInvitationExpiry() time.Duration {
 	return d
 }
 
+// SecurityConfig contains brute-force protection settings for authentication
+// All fields are optional - empty values fall back to the defaults below
+type SecurityConfig struct {
+	MaxFailedLogins      int    `toml:"max_failed_logins"`        // Failed attempts per username before lockout
+	MaxFailedLoginsPerIP int    `toml:"max_failed_logins_per_ip"` // Failed attempts per IP before the IP is blocked
+	FailedLoginWindow    string `toml:"failed_login_window"`      // Time window in which failed attempts are counted
+	LockoutDuration      string `toml:"lockout_duration"`         // How long a locked account or blocked IP stays locked
+	LoginDelayBase       string `toml:"login_delay_base"`         // Initial delay after the first failed attempt, doubled per failure
+	LoginDelayMax        string `toml:"login_delay_max"`          // Upper bound for the progressive delay
+
+	// Two-factor authentication
+	MFARequiredRoles []string `toml:"mfa_required_roles"` // Roles that must use TOTP two-factor authentication
+	MFAIssuer        string   `toml:"mfa_issuer"`         // Issuer name shown in authenticator apps
+
+	// Password reset
+	PasswordResetExpiry string `toml:"password_reset_expiry"` // How long an admin-issued reset link stays valid
+}
+
+// Default brute-force protection settings
+const (
+	DefaultMaxFailedLogins      = 5
+	DefaultMaxFailedLoginsPerIP = 20
+	DefaultFailedLoginWindow    = 15 * time.Minute
+	DefaultLockoutDuration      = 15 * time.Minute
+	DefaultLoginDelayBase       = time.Second
+	DefaultLoginDelayMax        = 30 * time.Second
+	DefaultMFAIssuer            = "FITS"
+	DefaultPasswordResetExpiry  = 24 * time.Hour
+)
+
+// GetMaxFailedLogins returns the per-username failure threshold
+func (s *SecurityConfig) GetMaxFailedLogins() int {
+	if s.MaxFailedLogins <= 0 {
+		return DefaultMaxFailedLogins
+	}
+	return s.MaxFailedLogins
+}
+
+// GetMaxFailedLoginsPerIP returns the per-IP failure threshold
+func (s *SecurityConfig) GetMaxFailedLoginsPerIP() int {
+	if s.MaxFailedLoginsPerIP <= 0 {
+		return DefaultMaxFailedLoginsPerIP
+	}
+	return s.MaxFailedLoginsPerIP
+}
+
+// GetFailedLoginWindow returns the window in which failed attempts are counted
+func (s *SecurityConfig) GetFailedLoginWindow() time.Duration {
+	return durationOrDefault(s.FailedLoginWindow, DefaultFailedLoginWindow)
+}
+
+// GetLockoutDuration returns how long an account or IP stays locked
+func (s *SecurityConfig) GetLockoutDuration() time.Duration {
+	return durationOrDefault(s.LockoutDuration, DefaultLockoutDuration)
+}
+
+// GetLoginDelayBase returns the initial progressive login delay
+func (s *SecurityConfig) GetLoginDelayBase() time.Duration {
+	return durationOrDefault(s.LoginDelayBase, DefaultLoginDelayBase)
+}
+
+// GetLoginDelayMax returns the maximum progressive login delay
+func (s *SecurityConfig) GetLoginDelayMax() time.Duration {
+	return durationOrDefault(s.LoginDelayMax, DefaultLoginDelayMax)
+}
+
+// RequiresMFA checks if two-factor authentication is mandatory for the given role
+func (s *SecurityConfig) RequiresMFA(role string) bool {
+	for _, r := range s.MFARequiredRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// GetMFAIssuer returns the issuer name for TOTP provisioning URIs
+func (s *SecurityConfig) GetMFAIssuer() string {
+	if s.MFAIssuer == "" {
+		return DefaultMFAIssuer
+	}
+	return s.MFAIssuer
+}
+
+// GetPasswordResetExpiry returns the lifetime of password reset tokens
+func (s *SecurityConfig) GetPasswordResetExpiry() time.Duration {
+	return durationOrDefault(s.PasswordResetExpiry, DefaultPasswordResetExpiry)
+}
+
+// WebAuthnConfig contains the relying party settings for passkey login
+type WebAuthnConfig struct {
+	RPID          string   `toml:"rp_id"`           // Domain the passkeys are bound to, e.g. "fits.example.com"
+	RPDisplayName string   `toml:"rp_display_name"` // Name shown by the browser during registration
+	RPOrigins     []string `toml:"rp_origins"`      // Frontend origins allowed to perform ceremonies
+}
+
+// Default values for WebAuthnConfig fields that are not set
+const (
+	DefaultWebAuthnRPID        = "localhost"
+	DefaultWebAuthnDisplayName = "FITS"
+	DefaultWebAuthnOrigin      = "http://localhost:8080"
+)
+
+// GetRPID returns the relying party ID
+func (w *WebAuthnConfig) GetRPID() string {
+	if w.RPID == "" {
+		return DefaultWebAuthnRPID
+	}
+	return w.RPID
+}
+
+// GetRPDisplayName returns the relying party display name
+func (w *WebAuthnConfig) GetRPDisplayName() string {
+	if w.RPDisplayName == "" {
+		return DefaultWebAuthnDisplayName
+	}
+	return w.RPDisplayName
+}
+
+// GetRPOrigins returns the allowed origins
+func (w *WebAuthnConfig) GetRPOrigins() []string {
+	if len(w.RPOrigins) == 0 {
+		return []string{DefaultWebAuthnOrigin}
+	}
+	return w.RPOrigins
+}
+
+// PasswordConfig selects the password hashing algorithm and its cost parameters
+// Existing hashes of another algorithm or with other parameters are rehashed on the next login
+type PasswordConfig struct {
+	Algorithm         string `toml:"algorithm"`          // "argon2id" (default) or "bcrypt"
+	Argon2Memory      uint32 `toml:"argon2_memory"`      // Memory in KiB
+	Argon2Iterations  uint32 `toml:"argon2_iterations"`  // Number of passes over the memory
+	Argon2Parallelism uint8  `toml:"argon2_parallelism"` // Number of threads
+	BcryptCost        int    `toml:"bcrypt_cost"`        // Only used with algorithm "bcrypt"
+}
+
+// Default password hashing settings (argon2id per RFC 9106, bcrypt cost as before)
+const (
+	PasswordAlgorithmArgon2id = "argon2id"
+	PasswordAlgorithmBcrypt   = "bcrypt"
+
+	DefaultArgon2Memory      = 64 * 1024
+	DefaultArgon2Iterations  = 3
+	DefaultArgon2Parallelism = 2
+	DefaultBcryptCost        = 12
+)
+
+// GetAlgorithm returns the password hashing algorithm
+func (p *PasswordConfig) GetAlgorithm() string {
+	if p.Algorithm == "" {
+		return PasswordAlgorithmArgon2id
+	}
+	return p.Algorithm
+}
+
+// GetArgon2Memory returns the argon2id memory cost in KiB
+func (p *PasswordConfig) GetArgon2Memory() uint32 {
+	if p.Argon2Memory == 0 {
+		return DefaultArgon2Memory
+	}
+	return p.Argon2Memory
+}
+
+// GetArgon2Iterations returns the argon2id time cost
+func (p *PasswordConfig) GetArgon2Iterations() uint32 {
+	if p.Argon2Iterations == 0 {
+		return DefaultArgon2Iterations
+	}
+	return p.Argon2Iterations
+}
+
+// GetArgon2Parallelism returns the argon2id parallelism
+func (p *PasswordConfig) GetArgon2Parallelism() uint8 {
+	if p.Argon2Parallelism == 0 {
+		return DefaultArgon2Parallelism
+	}
+	return p.Argon2Parallelism
+}
+
+// GetBcryptCost returns the bcrypt cost factor
+func (p *PasswordConfig) GetBcryptCost() int {
+	if p.BcryptCost == 0 {
+		return DefaultBcryptCost
+	}
+	return p.BcryptCost
+}
+
+// DefaultPublicBaseURL is used for links if server.public_base_url is not set
+const DefaultPublicBaseURL = "http://localhost:8080"
+
+// GetPublicBaseURL returns the public base URL without trailing slash
+func (s *ServerConfig) GetPublicBaseURL() string {
+	if s.PublicBaseURL == "" {
+		return DefaultPublicBaseURL
+	}
+	return strings.TrimRight(s.PublicBaseURL, "/")
+}
+
+// MailConfig contains the outbound email settings
+// Emails are queued in the database and delivered by a background sender with retries
+type MailConfig struct {
+	Backend       string `toml:"backend"`        // "log" (default), "file" or "smtp"
+	From          string `toml:"from"`           // Sender address, e.g. "FITS <noreply@fits.example.com>"
+	DefaultLocale string `toml:"default_locale"` // Template language if none is requested: "de" or "en"
+	FileDir       string `toml:"file_dir"`       // Directory for .eml files with backend "file"
+	LogBodies     bool   `toml:"log_bodies"`     // Log full bodies with backend "log"; they contain reset and invitation links
+	PollInterval  string `toml:"poll_interval"`  // How often the outbox is checked for due emails
+	MaxAttempts   int    `toml:"max_attempts"`   // Delivery attempts before an email is given up
+	BatchSize     int    `toml:"batch_size"`     // Emails sent per poll
+	Retention     string `toml:"retention"`      // How long sent emails stay in the outbox, e.g. "168h"
+
+	// SMTP backend
+	SMTPHost     string `toml:"smtp_host"`
+	SMTPPort     int    `toml:"smtp_port"`
+	SMTPUsername string `toml:"smtp_username"` // Empty disables authentication
+	SMTPPassword string `toml:"smtp_password"`
+	SMTPTLS      string `toml:"smtp_tls"` // "starttls" (default), "tls" or "none"
+}
+
+// Mail backends and default mail settings
+const (
+	MailBackendLog  = "log"
+	MailBackendFile = "file"
+	MailBackendSMTP = "smtp"
+
+	DefaultMailFrom         = "FITS <noreply@localhost>"
+	DefaultMailLocale       = "en"
+	DefaultMailFileDir      = "./data/mail"
+	DefaultMailPollInterval = 5 * time.Second
+	DefaultMailMaxAttempts  = 8
+	DefaultMailBatchSize    = 20
+	DefaultMailRetention    = 7 * 24 * time.Hour
+	DefaultSMTPPort         = 587
+	DefaultSMTPTLS          = "starttls"
+)
+
+// GetBackend returns the mail backend
+func (m *MailConfig) GetBackend() string {
+	if m.Backend == "" {
+		return MailBackendLog
+	}
+	return m.Backend
+}
+
+// GetFrom returns the sender address
+func (m *MailConfig) GetFrom() string {
+	if m.From == "" {
+		return DefaultMailFrom
+	}
+	return m.From
+}
+
+// GetDefaultLocale returns the template language used if none is requested
+func (m *MailConfig) GetDefaultLocale() string {
+	if m.DefaultLocale == "" {
+		return DefaultMailLocale
+	}
+	return m.DefaultLocale
+}
+
+// GetFileDir returns the directory for the file backend
+func (m *MailConfig) GetFileDir() string {
+	if m.FileDir == "" {
+		return DefaultMailFileDir
+	}
+	return m.FileDir
+}
+
+// GetPollInterval returns how often the outbox is checked
+func (m *MailConfig) GetPollInterval() time.Duration {
+	return durationOrDefault(m.PollInterval, DefaultMailPollInterval)
+}
+
+// GetMaxAttempts returns the number of delivery attempts per email
+func (m *MailConfig) GetMaxAttempts() int {
+	if m.MaxAttempts <= 0 {
+		return DefaultMailMaxAttempts
+	}
+	return m.MaxAttempts
+}
+
+// GetBatchSize returns the number of emails sent per poll
+func (m *MailConfig) GetBatchSize() int {
+	if m.BatchSize <= 0 {
+		return DefaultMailBatchSize
+	}
+	return m.BatchSize
+}
+
+// GetRetention returns how long sent emails are kept before they are deleted
+func (m *MailConfig) GetRetention() time.Duration {
+	return durationOrDefault(m.Retention, DefaultMailRetention)
+}
+
+// GetSMTPPort returns the SMTP server port
+func (m *MailConfig) GetSMTPPort() int {
+	if m.SMTPPort == 0 {
+		return DefaultSMTPPort
+	}
+	return m.SMTPPort
+}
+
+// GetSMTPTLS returns the SMTP transport security mode
+func (m *MailConfig) GetSMTPTLS() string {
+	if m.SMTPTLS == "" {
+		return DefaultSMTPTLS
+	}
+	return m.SMTPTLS
+}
+
+// AssignmentConfig contains the optional rules for assigning students to teachers
+// A referenced teacher must always exist and not be deleted
+type AssignmentConfig struct {
+	RequireDepartmentMatch bool `toml:"require_department_match"` // A department given for a student must equal the teacher's department
+}
+
+// OIDCConfig configures login through an external OpenID Connect identity provider (e.g. Keycloak)
+// The authorization code flow with PKCE is used, the client secret is optional for public clients
+type OIDCConfig struct {
+	Enabled              bool   `toml:"enabled"`
+	IssuerURL            string `toml:"issuer_url"`              // e.g. "https://keycloak.example.com/realms/school"
+	ClientID             string `toml:"client_id"`               // Client registered at the provider
+	ClientSecret         string `toml:"client_secret"`           // Can be overridden with OIDC_CLIENT_SECRET
+	RedirectURL          string `toml:"redirect_url"`            // Page the provider redirects back to, defaults to the login page
+	Scopes               string `toml:"scopes"`                  // Comma-separated, "openid" is always requested
+	DisplayName          string `toml:"display_name"`            // Label of the login button
+	AutoLinkByInvitation bool   `toml:"auto_link_by_invitation"` // Link verified emails to accounts registered with an invitation to that email
+}
+
+// Default OpenID Connect settings
+const (
+	DefaultOIDCRedirectPath = "/login.html"
+	DefaultOIDCScopes       = "openid,email,profile"
+	DefaultOIDCDisplayName  = "School login"
+)
+
+// GetRedirectURL returns the callback URL registered at the provider
+func (o *OIDCConfig) GetRedirectURL(publicBaseURL string) string {
+	if o.RedirectURL == "" {
+		return publicBaseURL + DefaultOIDCRedirectPath
+	}
+	return o.RedirectURL
+}
+
+// GetScopes returns the requested scopes, always including "openid"
+func (o *OIDCConfig) GetScopes() []string {
+	value := o.Scopes
+	if value == "" {
+		value = DefaultOIDCScopes
+	}
+
+	scopes := []string{"openid"}
+	for _, scope := range strings.Split(value, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" && scope != "openid" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+// GetDisplayName returns the label of the login button
+func (o *OIDCConfig) GetDisplayName() string {
+	if o.DisplayName == "" {
+		return DefaultOIDCDisplayName
+	}
+	return o.DisplayName
+}
+
+// RetentionConfig controls how long deleted students and teachers stay in the trash
+// Admins can restore them until they are purged for good
+type RetentionConfig struct {
+	DeletedRecords string `toml:"deleted_records"` // How long deleted records can be restored, e.g. "720h"
+	PurgeInterval  string `toml:"purge_interval"`  // How often records past the retention time are purged
+}
+
+// Default retention settings
+const (
+	DefaultDeletedRecordsRetention = 30 * 24 * time.Hour
+	DefaultPurgeInterval           = time.Hour
+)
+
+// GetDeletedRecords returns how long deleted records stay in the trash
+func (r *RetentionConfig) GetDeletedRecords() time.Duration {
+	return durationOrDefault(r.DeletedRecords, DefaultDeletedRecordsRetention)
+}
+
+// GetPurgeInterval returns how often expired records are purged
+func (r *RetentionConfig) GetPurgeInterval() time.Duration {
+	return durationOrDefault(r.PurgeInterval, DefaultPurgeInterval)
+}
+
+// durationOrDefault parses an optional duration setting
+// Invalid values are rejected by Validate(), so they only fall back here if validation was skipped
+func durationOrDefault(value string, def time.Duration) time.Duration {
+	if value == "" {
+		return def
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return def
+	}
+	return d
+}
+
 func Load(configPath string) (*Config, error) {
 	var cfg Config
 
@@ -116,8 +523,8 @@ func Load(configPath string) (*Config, error) {
 		fmt.Sscanf(port, "%d", &cfg.Server.Port)
 	}
 
-	if metricsSecret := os.Getenv("METRICS_SECRET"); metricsSecret != "" {
-		cfg.Secrets.MetricsSecret = metricsSecret
+	if clientSecret := os.Getenv("OIDC_CLIENT_SECRET"); clientSecret != "" {
+		cfg.OIDC.ClientSecret = clientSecret
 	}
 
 	if err := cfg.Validate(); err != nil {
@@ -141,6 +548,12 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("invalid server.write_timeout: %w", err)
 	}
 
+	if c.Server.PublicBaseURL != "" {
+		if u, err := url.Parse(c.Server.PublicBaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid server.public_base_url '%s': must be an http(s) URL", c.Server.PublicBaseURL)
+		}
+	}
+
 	// TLS validation (if enabled)
 	if c.Server.TLSEnabled {
 		if c.Server.TLSCertFile == "" {
@@ -151,11 +564,6 @@ func (c *Config) Validate() error {
 		}
 	}
 
-	// Secrets validation
-	if c.Secrets.MetricsSecret == "" {
-		return fmt.Errorf("secrets.metrics_secret must be set")
-	}
-
 	// Database validation
 	if c.Database.Host == "" {
 		return fmt.Errorf("database.host must be set")
@@ -191,6 +599,116 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("invalid jwt.invitation_expiry: %w", err)
 	}
 
+	// Security validation (optional durations must parse if set)
+	securityDurations := []struct {
+		key   string
+		value string
+	}{
+		{"security.failed_login_window", c.Security.FailedLoginWindow},
+		{"security.lockout_duration", c.Security.LockoutDuration},
+		{"security.login_delay_base", c.Security.LoginDelayBase},
+		{"security.login_delay_max", c.Security.LoginDelayMax},
+		{"security.password_reset_expiry", c.Security.PasswordResetExpiry},
+	}
+	for _, d := range securityDurations {
+		if d.value == "" {
+			continue
+		}
+		if _, err := time.ParseDuration(d.value); err != nil {
+			return fmt.Errorf("invalid %s: %w", d.key, err)
+		}
+	}
+
+	for _, role := range c.Security.MFARequiredRoles {
+		if role != "admin" && role != "teacher" && role != "student" {
+			return fmt.Errorf("invalid security.mfa_required_roles entry '%s': must be admin, teacher or student", role)
+		}
+	}
+
+	// WebAuthn validation (origins are compared exactly, so they must be scheme://host[:port])
+	for _, origin := range c.WebAuthn.RPOrigins {
+		if u, err := url.Parse(origin); err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") {
+			return fmt.Errorf("invalid webauthn.rp_origins entry '%s': must be scheme://host[:port]", origin)
+		}
+	}
+
+	// Password hashing validation
+	switch c.Password.GetAlgorithm() {
+	case PasswordAlgorithmArgon2id, PasswordAlgorithmBcrypt:
+	default:
+		return fmt.Errorf("invalid password.algorithm '%s': must be argon2id or bcrypt", c.Password.Algorithm)
+	}
+
+	// Below 8 MiB argon2id is weaker than the bcrypt hashes it replaces
+	if c.Password.Argon2Memory != 0 && c.Password.Argon2Memory < 8*1024 {
+		return fmt.Errorf("password.argon2_memory must be at least 8192 KiB")
+	}
+
+	if c.Password.BcryptCost != 0 && (c.Password.BcryptCost < 10 || c.Password.BcryptCost > 31) {
+		return fmt.Errorf("password.bcrypt_cost must be between 10 and 31")
+	}
+
+	// Mail validation
+	switch c.Mail.GetBackend() {
+	case MailBackendLog, MailBackendFile:
+	case MailBackendSMTP:
+		if c.Mail.SMTPHost == "" {
+			return fmt.Errorf("mail.smtp_host must be set when mail.backend is smtp")
+		}
+	default:
+		return fmt.Errorf("invalid mail.backend '%s': must be log, file or smtp", c.Mail.Backend)
+	}
+
+	switch c.Mail.GetSMTPTLS() {
+	case "starttls", "tls", "none":
+	default:
+		return fmt.Errorf("invalid mail.smtp_tls '%s': must be starttls, tls or none", c.Mail.SMTPTLS)
+	}
+
+	if c.Mail.PollInterval != "" {
+		if _, err := time.ParseDuration(c.Mail.PollInterval); err != nil {
+			return fmt.Errorf("invalid mail.poll_interval: %w", err)
+		}
+	}
+
+	if c.Mail.Retention != "" {
+		if parsed, err := time.ParseDuration(c.Mail.Retention); err != nil || parsed <= 0 {
+			return fmt.Errorf("invalid mail.retention '%s': must be a positive duration", c.Mail.Retention)
+		}
+	}
+
+	// Retention validation (optional durations must be positive if set)
+	retentionDurations := []struct {
+		key   string
+		value string
+	}{
+		{"retention.deleted_records", c.Retention.DeletedRecords},
+		{"retention.purge_interval", c.Retention.PurgeInterval},
+	}
+	for _, d := range retentionDurations {
+		if d.value == "" {
+			continue
+		}
+		if parsed, err := time.ParseDuration(d.value); err != nil || parsed <= 0 {
+			return fmt.Errorf("invalid %s '%s': must be a positive duration", d.key, d.value)
+		}
+	}
+
+	// OpenID Connect validation (if enabled)
+	if c.OIDC.Enabled {
+		if u, err := url.Parse(c.OIDC.IssuerURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid oidc.issuer_url '%s': must be an http(s) URL", c.OIDC.IssuerURL)
+		}
+		if c.OIDC.ClientID == "" {
+			return fmt.Errorf("oidc.client_id must be set when OIDC is enabled")
+		}
+		if c.OIDC.RedirectURL != "" {
+			if u, err := url.Parse(c.OIDC.RedirectURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("invalid oidc.redirect_url '%s': must be an http(s) URL", c.OIDC.RedirectURL)
+			}
+		}
+	}
+
 	return nil
 }
 
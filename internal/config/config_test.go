@@ -202,9 +202,6 @@ func TestConfig_Validate(t *testing.T) {
 					ReadTimeout:  "30s",
 					WriteTimeout: "30s",
 				},
-				Secrets: SecretsConfig{
-					MetricsSecret: "test-secret",
-				},
 				Database: DatabaseConfig{
 					Host:     "localhost",
 					Port:     5432,
@@ -227,9 +224,6 @@ func TestConfig_Validate(t *testing.T) {
 					ReadTimeout:  "30s",
 					WriteTimeout: "30s",
 				},
-				Secrets: SecretsConfig{
-					MetricsSecret: "test-secret",
-				},
 				Database: DatabaseConfig{
 					Host:     "localhost",
 					Port:     5432,
@@ -253,9 +247,6 @@ func TestConfig_Validate(t *testing.T) {
 					ReadTimeout:  "30s",
 					WriteTimeout: "30s",
 				},
-				Secrets: SecretsConfig{
-					MetricsSecret: "test-secret",
-				},
 				Database: DatabaseConfig{
 					Host:     "localhost",
 					Port:     5432,
@@ -279,9 +270,6 @@ func TestConfig_Validate(t *testing.T) {
 					ReadTimeout:  "invalid",
 					WriteTimeout: "30s",
 				},
-				Secrets: SecretsConfig{
-					MetricsSecret: "test-secret",
-				},
 				Database: DatabaseConfig{
 					Host:     "localhost",
 					Port:     5432,
@@ -305,9 +293,6 @@ func TestConfig_Validate(t *testing.T) {
 					ReadTimeout:  "30s",
 					WriteTimeout: "30s",
 				},
-				Secrets: SecretsConfig{
-					MetricsSecret: "test-secret",
-				},
 				Database: DatabaseConfig{
 					Host:     "localhost",
 					Port:     5432,
@@ -331,9 +316,6 @@ func TestConfig_Validate(t *testing.T) {
 					ReadTimeout:  "30s",
 					WriteTimeout: "30s",
 				},
-				Secrets: SecretsConfig{
-					MetricsSecret: "test-secret",
-				},
 				Database: DatabaseConfig{
 					Host:     "localhost",
 					Port:     5432,
@@ -367,6 +349,280 @@ func TestConfig_Validate(t *testing.T) {
 	}
 }
 
+func TestSecurityConfig_Defaults(t *testing.T) {
+	t.Run("empty config falls back to defaults", func(t *testing.T) {
+		cfg := &SecurityConfig{}
+
+		assert.Equal(t, DefaultMaxFailedLogins, cfg.GetMaxFailedLogins())
+		assert.Equal(t, DefaultMaxFailedLoginsPerIP, cfg.GetMaxFailedLoginsPerIP())
+		assert.Equal(t, DefaultFailedLoginWindow, cfg.GetFailedLoginWindow())
+		assert.Equal(t, DefaultLockoutDuration, cfg.GetLockoutDuration())
+		assert.Equal(t, DefaultLoginDelayBase, cfg.GetLoginDelayBase())
+		assert.Equal(t, DefaultLoginDelayMax, cfg.GetLoginDelayMax())
+		assert.Equal(t, DefaultPasswordResetExpiry, cfg.GetPasswordResetExpiry())
+	})
+
+	t.Run("configured values override defaults", func(t *testing.T) {
+		cfg := &SecurityConfig{
+			MaxFailedLogins:      3,
+			MaxFailedLoginsPerIP: 50,
+			FailedLoginWindow:    "1h",
+			LockoutDuration:      "30m",
+			LoginDelayBase:       "500ms",
+			LoginDelayMax:        "10s",
+		}
+
+		assert.Equal(t, 3, cfg.GetMaxFailedLogins())
+		assert.Equal(t, 50, cfg.GetMaxFailedLoginsPerIP())
+		assert.Equal(t, time.Hour, cfg.GetFailedLoginWindow())
+		assert.Equal(t, 30*time.Minute, cfg.GetLockoutDuration())
+		assert.Equal(t, 500*time.Millisecond, cfg.GetLoginDelayBase())
+		assert.Equal(t, 10*time.Second, cfg.GetLoginDelayMax())
+	})
+
+	t.Run("MFA requirement per role", func(t *testing.T) {
+		cfg := &SecurityConfig{MFARequiredRoles: []string{"admin", "teacher"}}
+
+		assert.True(t, cfg.RequiresMFA("admin"))
+		assert.True(t, cfg.RequiresMFA("teacher"))
+		assert.False(t, cfg.RequiresMFA("student"))
+		assert.Equal(t, DefaultMFAIssuer, cfg.GetMFAIssuer())
+	})
+
+	t.Run("invalid duration is rejected by Validate", func(t *testing.T) {
+		cfg := &Config{
+			Server:   ServerConfig{Port: 8080, ReadTimeout: "30s", WriteTimeout: "30s"},
+			Database: DatabaseConfig{Host: "localhost", Port: 5432, Database: "test_db"},
+			JWT: JWTConfig{
+				Secret:             "this-is-a-very-secure-secret-key-with-32-chars",
+				AccessTokenExpiry:  "1h",
+				RefreshTokenExpiry: "168h",
+				InvitationExpiry:   "168h",
+			},
+			Security: SecurityConfig{LockoutDuration: "forever"},
+		}
+
+		err := cfg.Validate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "security.lockout_duration")
+	})
+}
+
+func TestWebAuthnConfig(t *testing.T) {
+	t.Run("defaults when not set", func(t *testing.T) {
+		cfg := &WebAuthnConfig{}
+
+		assert.Equal(t, DefaultWebAuthnRPID, cfg.GetRPID())
+		assert.Equal(t, DefaultWebAuthnDisplayName, cfg.GetRPDisplayName())
+		assert.Equal(t, []string{DefaultWebAuthnOrigin}, cfg.GetRPOrigins())
+	})
+
+	t.Run("invalid origin is rejected by Validate", func(t *testing.T) {
+		cfg := &Config{
+			Server:   ServerConfig{Port: 8080, ReadTimeout: "30s", WriteTimeout: "30s"},
+			Database: DatabaseConfig{Host: "localhost", Port: 5432, Database: "test_db"},
+			JWT: JWTConfig{
+				Secret:             "this-is-a-very-secure-secret-key-with-32-chars",
+				AccessTokenExpiry:  "1h",
+				RefreshTokenExpiry: "168h",
+				InvitationExpiry:   "168h",
+			},
+			WebAuthn: WebAuthnConfig{RPOrigins: []string{"fits.example.com/login"}},
+		}
+
+		err := cfg.Validate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "webauthn.rp_origins")
+	})
+}
+
+func TestPasswordConfig(t *testing.T) {
+	t.Run("defaults when not set", func(t *testing.T) {
+		cfg := &PasswordConfig{}
+
+		assert.Equal(t, PasswordAlgorithmArgon2id, cfg.GetAlgorithm())
+		assert.Equal(t, uint32(DefaultArgon2Memory), cfg.GetArgon2Memory())
+		assert.Equal(t, uint32(DefaultArgon2Iterations), cfg.GetArgon2Iterations())
+		assert.Equal(t, uint8(DefaultArgon2Parallelism), cfg.GetArgon2Parallelism())
+		assert.Equal(t, DefaultBcryptCost, cfg.GetBcryptCost())
+	})
+
+	tests := []struct {
+		name     string
+		password PasswordConfig
+		errMsg   string
+	}{
+		{"unknown algorithm", PasswordConfig{Algorithm: "md5"}, "password.algorithm"},
+		{"too little argon2 memory", PasswordConfig{Argon2Memory: 1024}, "password.argon2_memory"},
+		{"bcrypt cost too low", PasswordConfig{Algorithm: "bcrypt", BcryptCost: 4}, "password.bcrypt_cost"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Server:   ServerConfig{Port: 8080, ReadTimeout: "30s", WriteTimeout: "30s"},
+				Database: DatabaseConfig{Host: "localhost", Port: 5432, Database: "test_db"},
+				JWT: JWTConfig{
+					Secret:             "this-is-a-very-secure-secret-key-with-32-chars",
+					AccessTokenExpiry:  "1h",
+					RefreshTokenExpiry: "168h",
+					InvitationExpiry:   "168h",
+				},
+				Password: tt.password,
+			}
+
+			err := cfg.Validate()
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestMailConfig(t *testing.T) {
+	t.Run("defaults when not set", func(t *testing.T) {
+		cfg := &Config{}
+
+		assert.Equal(t, DefaultPublicBaseURL, cfg.Server.GetPublicBaseURL())
+		assert.Equal(t, MailBackendLog, cfg.Mail.GetBackend())
+		assert.Equal(t, DefaultMailFrom, cfg.Mail.GetFrom())
+		assert.Equal(t, DefaultMailLocale, cfg.Mail.GetDefaultLocale())
+		assert.Equal(t, DefaultMailPollInterval, cfg.Mail.GetPollInterval())
+		assert.Equal(t, DefaultMailMaxAttempts, cfg.Mail.GetMaxAttempts())
+		assert.Equal(t, DefaultSMTPPort, cfg.Mail.GetSMTPPort())
+		assert.Equal(t, DefaultSMTPTLS, cfg.Mail.GetSMTPTLS())
+	})
+
+	t.Run("public base url without trailing slash", func(t *testing.T) {
+		cfg := &ServerConfig{PublicBaseURL: "https://fits.example.com/"}
+
+		assert.Equal(t, "https://fits.example.com", cfg.GetPublicBaseURL())
+	})
+
+	tests := []struct {
+		name   string
+		server ServerConfig
+		mail   MailConfig
+		errMsg string
+	}{
+		{"relative public base url", ServerConfig{PublicBaseURL: "/fits"}, MailConfig{}, "server.public_base_url"},
+		{"unknown backend", ServerConfig{}, MailConfig{Backend: "sendmail"}, "mail.backend"},
+		{"smtp without host", ServerConfig{}, MailConfig{Backend: "smtp"}, "mail.smtp_host"},
+		{"unknown smtp tls mode", ServerConfig{}, MailConfig{Backend: "smtp", SMTPHost: "localhost", SMTPTLS: "ssl"}, "mail.smtp_tls"},
+		{"invalid poll interval", ServerConfig{}, MailConfig{PollInterval: "often"}, "mail.poll_interval"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.server.Port = 8080
+			tt.server.ReadTimeout = "30s"
+			tt.server.WriteTimeout = "30s"
+			cfg := &Config{
+				Server:   tt.server,
+				Database: DatabaseConfig{Host: "localhost", Port: 5432, Database: "test_db"},
+				JWT: JWTConfig{
+					Secret:             "this-is-a-very-secure-secret-key-with-32-chars",
+					AccessTokenExpiry:  "1h",
+					RefreshTokenExpiry: "168h",
+					InvitationExpiry:   "168h",
+				},
+				Mail: tt.mail,
+			}
+
+			err := cfg.Validate()
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestRetentionConfig(t *testing.T) {
+	t.Run("defaults when not set", func(t *testing.T) {
+		cfg := &RetentionConfig{}
+
+		assert.Equal(t, DefaultDeletedRecordsRetention, cfg.GetDeletedRecords())
+		assert.Equal(t, DefaultPurgeInterval, cfg.GetPurgeInterval())
+	})
+
+	t.Run("configured values", func(t *testing.T) {
+		cfg := &RetentionConfig{DeletedRecords: "2160h", PurgeInterval: "15m"}
+
+		assert.Equal(t, 90*24*time.Hour, cfg.GetDeletedRecords())
+		assert.Equal(t, 15*time.Minute, cfg.GetPurgeInterval())
+	})
+
+	tests := []struct {
+		name      string
+		retention RetentionConfig
+		errMsg    string
+	}{
+		{"invalid retention", RetentionConfig{DeletedRecords: "a month"}, "retention.deleted_records"},
+		{"zero retention", RetentionConfig{DeletedRecords: "0s"}, "retention.deleted_records"},
+		{"negative purge interval", RetentionConfig{PurgeInterval: "-1h"}, "retention.purge_interval"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Server:   ServerConfig{Port: 8080, ReadTimeout: "30s", WriteTimeout: "30s"},
+				Database: DatabaseConfig{Host: "localhost", Port: 5432, Database: "test_db"},
+				JWT: JWTConfig{
+					Secret:             "this-is-a-very-secure-secret-key-with-32-chars",
+					AccessTokenExpiry:  "1h",
+					RefreshTokenExpiry: "168h",
+					InvitationExpiry:   "168h",
+				},
+				Retention: tt.retention,
+			}
+
+			err := cfg.Validate()
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestOIDCConfig(t *testing.T) {
+	t.Run("defaults when not set", func(t *testing.T) {
+		cfg := &OIDCConfig{}
+
+		assert.Equal(t, "https://fits.example.com/login.html", cfg.GetRedirectURL("https://fits.example.com"))
+		assert.Equal(t, []string{"openid", "email", "profile"}, cfg.GetScopes())
+		assert.Equal(t, DefaultOIDCDisplayName, cfg.GetDisplayName())
+	})
+
+	t.Run("always requests openid scope", func(t *testing.T) {
+		cfg := &OIDCConfig{Scopes: "email, openid ,groups"}
+
+		assert.Equal(t, []string{"openid", "email", "groups"}, cfg.GetScopes())
+	})
+
+	tests := []struct {
+		name   string
+		oidc   OIDCConfig
+		errMsg string
+	}{
+		{"missing issuer", OIDCConfig{Enabled: true, ClientID: "fits"}, "oidc.issuer_url"},
+		{"missing client id", OIDCConfig{Enabled: true, IssuerURL: "https://idp.example.com"}, "oidc.client_id"},
+		{"relative redirect url", OIDCConfig{Enabled: true, IssuerURL: "https://idp.example.com", ClientID: "fits", RedirectURL: "/login.html"}, "oidc.redirect_url"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Server:   ServerConfig{Port: 8080, ReadTimeout: "30s", WriteTimeout: "30s"},
+				Database: DatabaseConfig{Host: "localhost", Port: 5432, Database: "test_db"},
+				JWT: JWTConfig{
+					Secret:             "this-is-a-very-secure-secret-key-with-32-chars",
+					AccessTokenExpiry:  "1h",
+					RefreshTokenExpiry: "168h",
+					InvitationExpiry:   "168h",
+				},
+				OIDC: tt.oidc,
+			}
+
+			err := cfg.Validate()
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
 func TestConfig_Load_EnvironmentOverride(t *testing.T) {
 	// Create a temporary config file for testing
 	tmpFile, err := os.CreateTemp("", "config-test-*.toml")
@@ -400,12 +656,6 @@ invitation_expiry = "168h"
 admin_key_path = "./keys/admin.key"
 admin_pub_key_path = "./keys/admin.pub"
 
-[secrets]
-metrics_secret = "test-metrics-secret"
-registration_secret = "test-registration-secret"
-deletion_secret = "test-deletion-secret"
-update_secret = "test-update-secret"
-
 [storage]
 upload_dir = "./uploads"
 max_file_size = 10485760
@@ -428,21 +678,10 @@ format = "json"
 		assert.Equal(t, 9090, cfg.Server.Port)
 	})
 
-	// Test environment variable override for METRICS_SECRET
-	t.Run("METRICS_SECRET override", func(t *testing.T) {
-		os.Setenv("METRICS_SECRET", "env-metrics-secret")
-		defer os.Unsetenv("METRICS_SECRET")
-
-		cfg, err := Load(tmpFile.Name())
-		require.NoError(t, err)
-		assert.Equal(t, "env-metrics-secret", cfg.Secrets.MetricsSecret)
-	})
-
 	// Test loading without environment overrides
 	t.Run("no environment overrides", func(t *testing.T) {
 		cfg, err := Load(tmpFile.Name())
 		require.NoError(t, err)
 		assert.Equal(t, 8080, cfg.Server.Port)
-		assert.Equal(t, "test-metrics-secret", cfg.Secrets.MetricsSecret)
 	})
 }
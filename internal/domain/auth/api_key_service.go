@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/errors"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/pagination"
+	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
+	"github.com/JustDoItBetter/FITS-backend/pkg/logger"
+)
+
+// MaxAPIKeyNameLength is the maximum length of an API key name
+const MaxAPIKeyNameLength = 100
+
+// APIKeyService manages service account API keys and validates them for the auth middleware
+type APIKeyService struct {
+	repo Repository
+}
+
+// NewAPIKeyService creates a new API key service
+func NewAPIKeyService(repo Repository) *APIKeyService {
+	return &APIKeyService{repo: repo}
+}
+
+// CreateAPIKey creates a key with the requested scopes
+// actorID is the admin creating the key, recorded for the audit trail
+func (s *APIKeyService) CreateAPIKey(ctx context.Context, req *CreateAPIKeyRequest, actorID string) (*CreateAPIKeyResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.ValidationError("name is required")
+	}
+	if len(name) > MaxAPIKeyNameLength {
+		return nil, errors.ValidationError(fmt.Sprintf("name must be at most %d characters", MaxAPIKeyNameLength))
+	}
+
+	if len(req.Scopes) == 0 {
+		return nil, errors.ValidationError("at least one scope is required")
+	}
+	scopes := make([]crypto.Scope, 0, len(req.Scopes))
+	for _, value := range req.Scopes {
+		scope := crypto.Scope(strings.TrimSpace(value))
+		if !crypto.IsValidScope(scope) {
+			return nil, errors.ValidationError(fmt.Sprintf("unknown scope '%s'", value))
+		}
+		scopes = append(scopes, scope)
+	}
+
+	var expiresAt *time.Time
+	if req.ExpiresIn != "" {
+		d, err := time.ParseDuration(req.ExpiresIn)
+		if err != nil || d <= 0 {
+			return nil, errors.ValidationError("expires_in must be a positive duration, e.g. 8760h")
+		}
+		at := time.Now().Add(d)
+		expiresAt = &at
+	}
+
+	key, lookupID, err := crypto.GenerateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey := &APIKey{
+		Name:      name,
+		LookupID:  lookupID,
+		KeyHash:   crypto.HashString(key),
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	}
+	if actorID != "" {
+		apiKey.CreatedBy = &actorID
+	}
+
+	if err := s.repo.CreateAPIKey(ctx, apiKey); err != nil {
+		return nil, err
+	}
+
+	logger.Info("API key created",
+		zap.String("api_key_id", apiKey.ID),
+		zap.String("lookup_id", apiKey.LookupID),
+		zap.String("created_by", actorID),
+	)
+
+	return &CreateAPIKeyResponse{APIKey: *apiKey, Key: key}, nil
+}
+
+// ListAPIKeys returns all keys for the admin overview
+func (s *APIKeyService) ListAPIKeys(ctx context.Context, params pagination.Params) ([]APIKey, int64, error) {
+	return s.repo.ListAPIKeys(ctx, params)
+}
+
+// RevokeAPIKey revokes a key immediately, it is kept for the audit trail
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, id, actorID string) error {
+	key, err := s.repo.GetAPIKeyByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	var revokedBy *string
+	if actorID != "" {
+		revokedBy = &actorID
+	}
+	if err := s.repo.RevokeAPIKey(ctx, key.ID, revokedBy); err != nil {
+		return err
+	}
+
+	logger.Info("API key revoked",
+		zap.String("api_key_id", key.ID),
+		zap.String("revoked_by", actorID),
+	)
+	return nil
+}
+
+// ValidateAPIKey implements middleware.APIKeyValidator
+// The key is compared with the stored hash in constant time; unknown, revoked and expired keys get the same error
+func (s *APIKeyService) ValidateAPIKey(ctx context.Context, key, ipAddress string) (*crypto.APIKeyPrincipal, error) {
+	lookupID, ok := crypto.ParseAPIKey(key)
+	if !ok {
+		return nil, errors.Unauthorized("invalid API key")
+	}
+
+	apiKey, err := s.repo.GetAPIKeyByLookupID(ctx, lookupID)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok && appErr.Code == 404 {
+			return nil, errors.Unauthorized("invalid API key")
+		}
+		return nil, err
+	}
+
+	if !crypto.VerifyAPIKey(key, apiKey.KeyHash) || !apiKey.IsActive() {
+		return nil, errors.Unauthorized("invalid API key")
+	}
+
+	// Non-critical, but log errors for monitoring
+	if err := s.repo.TouchAPIKey(ctx, apiKey.ID, ipAddress, time.Now()); err != nil {
+		logger.Warn("Failed to record API key use",
+			zap.String("api_key_id", apiKey.ID),
+			zap.Error(err),
+		)
+	}
+
+	return &crypto.APIKeyPrincipal{
+		KeyID:  apiKey.ID,
+		Name:   apiKey.Name,
+		Scopes: apiKey.Scopes,
+	}, nil
+}
@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/errors"
+	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
+)
+
+func TestAPIKeyService_CreateAPIKey(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("stores only the hash", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewAPIKeyService(mockRepo)
+		mockRepo.On("CreateAPIKey", ctx, mock.AnythingOfType("*auth.APIKey")).Return(nil)
+
+		resp, err := service.CreateAPIKey(ctx, &CreateAPIKeyRequest{
+			Name:      " Prometheus ",
+			Scopes:    []string{"metrics:read"},
+			ExpiresIn: "720h",
+		}, "admin-1")
+
+		require.NoError(t, err)
+		stored := mockRepo.Calls[0].Arguments.Get(1).(*APIKey)
+		assert.Equal(t, "Prometheus", stored.Name)
+		assert.Equal(t, []crypto.Scope{crypto.ScopeMetricsRead}, stored.Scopes)
+		assert.Equal(t, crypto.HashString(resp.Key), stored.KeyHash)
+		assert.NotContains(t, stored.KeyHash, resp.Key)
+		assert.Equal(t, "admin-1", *stored.CreatedBy)
+		require.NotNil(t, stored.ExpiresAt)
+		assert.WithinDuration(t, time.Now().Add(720*time.Hour), *stored.ExpiresAt, time.Minute)
+
+		lookupID, ok := crypto.ParseAPIKey(resp.Key)
+		assert.True(t, ok)
+		assert.Equal(t, stored.LookupID, lookupID)
+	})
+
+	t.Run("validates request", func(t *testing.T) {
+		service := NewAPIKeyService(new(MockRepository))
+
+		for _, req := range []*CreateAPIKeyRequest{
+			{Name: "", Scopes: []string{"metrics:read"}},
+			{Name: "Prometheus"},
+			{Name: "Prometheus", Scopes: []string{"students:delete"}},
+			{Name: "Prometheus", Scopes: []string{"metrics:read"}, ExpiresIn: "-1h"},
+		} {
+			_, err := service.CreateAPIKey(ctx, req, "admin-1")
+			assertAppErrorCode(t, err, 422)
+		}
+	})
+}
+
+func TestAPIKeyService_ValidateAPIKey(t *testing.T) {
+	ctx := context.Background()
+	key, lookupID, err := crypto.GenerateAPIKey()
+	require.NoError(t, err)
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name    string
+		key     string
+		record  *APIKey
+		wantErr bool
+	}{
+		{"valid key", key, &APIKey{ID: "key-1", Name: "Prometheus", LookupID: lookupID, KeyHash: crypto.HashString(key), Scopes: []crypto.Scope{crypto.ScopeMetricsRead}}, false},
+		{"wrong secret", key + "x", &APIKey{ID: "key-1", LookupID: lookupID, KeyHash: crypto.HashString(key)}, true},
+		{"revoked key", key, &APIKey{ID: "key-1", LookupID: lookupID, KeyHash: crypto.HashString(key), RevokedAt: &past}, true},
+		{"expired key", key, &APIKey{ID: "key-1", LookupID: lookupID, KeyHash: crypto.HashString(key), ExpiresAt: &past}, true},
+		{"unknown key", key, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			service := NewAPIKeyService(mockRepo)
+			if tt.record != nil {
+				mockRepo.On("GetAPIKeyByLookupID", ctx, lookupID).Return(tt.record, nil)
+			} else {
+				mockRepo.On("GetAPIKeyByLookupID", ctx, lookupID).Return(nil, errors.NotFound("api key"))
+			}
+			mockRepo.On("TouchAPIKey", ctx, "key-1", "192.0.2.10", mock.AnythingOfType("time.Time")).Return(nil)
+
+			principal, err := service.ValidateAPIKey(ctx, tt.key, "192.0.2.10")
+
+			if tt.wantErr {
+				assertAppErrorCode(t, err, 401)
+				mockRepo.AssertNotCalled(t, "TouchAPIKey", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, &crypto.APIKeyPrincipal{KeyID: "key-1", Name: "Prometheus", Scopes: []crypto.Scope{crypto.ScopeMetricsRead}}, principal)
+			mockRepo.AssertCalled(t, "TouchAPIKey", ctx, "key-1", "192.0.2.10", mock.AnythingOfType("time.Time"))
+		})
+	}
+
+	t.Run("rejects values that aren't API keys", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+
+		_, err := NewAPIKeyService(mockRepo).ValidateAPIKey(ctx, "not-a-key", "192.0.2.10")
+
+		assertAppErrorCode(t, err, 401)
+		mockRepo.AssertNotCalled(t, "GetAPIKeyByLookupID", mock.Anything, mock.Anything)
+	})
+}
+
+func TestAPIKeyService_RevokeAPIKey(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewAPIKeyService(mockRepo)
+	actorID := "admin-1"
+	mockRepo.On("GetAPIKeyByID", ctx, "key-1").Return(&APIKey{ID: "key-1"}, nil)
+	mockRepo.On("RevokeAPIKey", ctx, "key-1", &actorID).Return(nil)
+	mockRepo.On("GetAPIKeyByID", ctx, "missing").Return(nil, errors.NotFound("api key"))
+
+	require.NoError(t, service.RevokeAPIKey(ctx, "key-1", actorID))
+	mockRepo.AssertCalled(t, "RevokeAPIKey", ctx, "key-1", &actorID)
+
+	assertAppErrorCode(t, service.RevokeAPIKey(ctx, "missing", actorID), 404)
+}
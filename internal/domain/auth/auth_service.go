@@ -9,43 +9,133 @@ import (
 	"github.com/JustDoItBetter/FITS-backend/internal/config"
 	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
 	"github.com/JustDoItBetter/FITS-backend/pkg/logger"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
 // AuthService handles authentication operations
 type AuthService struct {
-	repo       Repository
-	jwtService *crypto.JWTService
-	jwtConfig  *config.JWTConfig
+	repo           Repository
+	jwtService     *crypto.JWTService
+	jwtConfig      *config.JWTConfig
+	securityConfig *config.SecurityConfig
+	notifier       *Notifier
 }
 
 // NewAuthService creates a new auth service
-func NewAuthService(repo Repository, jwtService *crypto.JWTService, jwtConfig *config.JWTConfig) *AuthService {
+func NewAuthService(repo Repository, jwtService *crypto.JWTService, jwtConfig *config.JWTConfig, securityConfig *config.SecurityConfig, notifier *Notifier) *AuthService {
 	return &AuthService{
-		repo:       repo,
-		jwtService: jwtService,
-		jwtConfig:  jwtConfig,
+		repo:           repo,
+		jwtService:     jwtService,
+		jwtConfig:      jwtConfig,
+		securityConfig: securityConfig,
+		notifier:       notifier,
 	}
 }
 
 // Login authenticates a user and returns access and refresh tokens
-func (s *AuthService) Login(ctx context.Context, req *LoginRequest) (*LoginResponse, error) {
+// Failed attempts are tracked per username and per client IP for brute-force protection
+func (s *AuthService) Login(ctx context.Context, req *LoginRequest, client ClientInfo) (*LoginResponse, error) {
+	now := time.Now()
+	clientIP := client.IPAddress
+
+	// Reject early if the IP is blocked, the account is locked or the progressive delay is active
+	stats, err := s.repo.GetLoginFailureStats(ctx, req.Username, clientIP, now.Add(-s.securityConfig.GetFailedLoginWindow()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check login attempts: %w", err)
+	}
+	if err := s.checkLoginThrottle(stats, now); err != nil {
+		return nil, err
+	}
+
 	// Get user by username
 	user, err := s.repo.GetUserByUsername(ctx, req.Username)
 	if err != nil {
+		// Verify against a dummy hash so unknown usernames take as long as wrong passwords
+		_ = crypto.VerifyPassword(req.Password, dummyPasswordHash())
+		s.recordFailedLogin(ctx, nil, req.Username, clientIP, stats, now)
 		return nil, errors.Unauthorized("invalid credentials")
 	}
 
+	// Account may still be locked by an earlier lockout even if its attempts left the window
+	// The password is still verified so locked accounts answer as slowly as any other
+	if user.IsLocked() {
+		_ = crypto.VerifyPassword(req.Password, user.PasswordHash)
+		return nil, errors.TooManyRequests("account temporarily locked due to too many failed login attempts")
+	}
+
 	// Verify password
 	if err := crypto.VerifyPassword(req.Password, user.PasswordHash); err != nil {
+		s.recordFailedLogin(ctx, user, req.Username, clientIP, stats, now)
 		return nil, errors.Unauthorized("invalid credentials")
 	}
 
+	// Only revealed to someone who knows the password
+	if user.IsDisabled() {
+		return nil, errAccountDisabled()
+	}
+
+	// Migrate legacy hashes (e.g. bcrypt) to the configured algorithm while the plain password is known
+	s.rehashPassword(ctx, user, req.Password)
+
+	// Successful login resets the failure counter for this username
+	if stats.UsernameFailures > 0 {
+		if err := s.repo.ResetFailedLogins(ctx, user.Username); err != nil {
+			logger.Warn("Failed to reset failed login attempts",
+				zap.String("user_id", user.ID),
+				zap.Error(err),
+			)
+		}
+	}
+
+	// Second factor is required if the user enrolled or the role mandates it
+	if user.MFAEnabled || s.securityConfig.RequiresMFA(string(user.Role)) {
+		return s.mfaChallenge(user)
+	}
+
+	return s.issueTokens(ctx, user, client)
+}
+
+// rehashPassword replaces the stored hash if it uses another algorithm or outdated parameters
+// Failures are only logged, the old hash stays valid and the next login retries
+func (s *AuthService) rehashPassword(ctx context.Context, user *User, password string) {
+	if !crypto.PasswordNeedsRehash(user.PasswordHash) {
+		return
+	}
+
+	newHash, err := crypto.HashPassword(password)
+	if err == nil {
+		err = s.repo.RehashPassword(ctx, user.ID, user.PasswordHash, newHash)
+	}
+	if err != nil {
+		logger.Warn("Failed to rehash password",
+			zap.String("user_id", user.ID),
+			zap.Error(err),
+		)
+		return
+	}
+
+	user.PasswordHash = newHash
+	logger.Info("Password hash upgraded", zap.String("user_id", user.ID))
+}
+
+// issueTokens creates access and refresh tokens for a fully authenticated user
+// Every call starts a new session whose ID is embedded in both tokens
+// All login methods end here, so disabled accounts are rejected here as well
+func (s *AuthService) issueTokens(ctx context.Context, user *User, client ClientInfo) (*LoginResponse, error) {
+	if user.IsDisabled() {
+		return nil, errAccountDisabled()
+	}
+
+	sessionID := uuid.New().String()
+
 	// Generate access token
-	accessToken, err := s.jwtService.GenerateToken(
+	accessToken, err := s.jwtService.GenerateUserSessionToken(
 		user.ID,
 		user.Role,
+		user.AccountClaims(),
 		crypto.TokenTypeAccess,
+		sessionID,
 		s.jwtConfig.GetAccessTokenExpiry(),
 	)
 	if err != nil {
@@ -53,10 +143,12 @@ func (s *AuthService) Login(ctx context.Context, req *LoginRequest) (*LoginRespo
 	}
 
 	// Generate refresh token
-	refreshTokenString, err := s.jwtService.GenerateToken(
+	refreshTokenString, err := s.jwtService.GenerateUserSessionToken(
 		user.ID,
 		user.Role,
+		user.AccountClaims(),
 		crypto.TokenTypeRefresh,
+		sessionID,
 		s.jwtConfig.GetRefreshTokenExpiry(),
 	)
 	if err != nil {
@@ -64,10 +156,15 @@ func (s *AuthService) Login(ctx context.Context, req *LoginRequest) (*LoginRespo
 	}
 
 	// Save refresh token to DB
+	now := time.Now()
 	refreshToken := &RefreshToken{
-		UserID:    user.ID,
-		Token:     refreshTokenString,
-		ExpiresAt: time.Now().Add(s.jwtConfig.GetRefreshTokenExpiry()),
+		ID:         sessionID,
+		UserID:     user.ID,
+		Token:      refreshTokenString,
+		UserAgent:  truncateUserAgent(client.UserAgent),
+		IPAddress:  client.IPAddress,
+		LastUsedAt: &now,
+		ExpiresAt:  now.Add(s.jwtConfig.GetRefreshTokenExpiry()),
 	}
 
 	if err := s.repo.CreateRefreshToken(ctx, refreshToken); err != nil {
@@ -93,7 +190,8 @@ func (s *AuthService) Login(ctx context.Context, req *LoginRequest) (*LoginRespo
 }
 
 // RefreshAccessToken generates a new access token using a refresh token
-func (s *AuthService) RefreshAccessToken(ctx context.Context, refreshTokenString string) (*LoginResponse, error) {
+// The session's last-used time and IP address are updated
+func (s *AuthService) RefreshAccessToken(ctx context.Context, refreshTokenString string, client ClientInfo) (*LoginResponse, error) {
 	// Validate refresh token JWT
 	claims, err := s.jwtService.ValidateToken(refreshTokenString)
 	if err != nil {
@@ -130,11 +228,26 @@ func (s *AuthService) RefreshAccessToken(ctx context.Context, refreshTokenString
 		return nil, err
 	}
 
+	// Disabling revokes all refresh tokens, this covers a refresh racing with it
+	if user.IsDisabled() {
+		return nil, errAccountDisabled()
+	}
+
+	// Track session activity (non-critical, but log errors for monitoring)
+	if err := s.repo.TouchSession(ctx, refreshToken.ID, client.IPAddress, time.Now()); err != nil {
+		logger.Warn("Failed to update session activity",
+			zap.String("user_id", user.ID),
+			zap.Error(err),
+		)
+	}
+
 	// Generate new access token
-	accessToken, err := s.jwtService.GenerateToken(
+	accessToken, err := s.jwtService.GenerateUserSessionToken(
 		user.ID,
 		user.Role,
+		user.AccountClaims(),
 		crypto.TokenTypeAccess,
+		refreshToken.ID,
 		s.jwtConfig.GetAccessTokenExpiry(),
 	)
 	if err != nil {
@@ -151,10 +264,19 @@ func (s *AuthService) RefreshAccessToken(ctx context.Context, refreshTokenString
 	}, nil
 }
 
-// Logout logs out a user by deleting all their refresh tokens
-func (s *AuthService) Logout(ctx context.Context, userID string) error {
-	// Delete all refresh tokens for user
-	return s.repo.DeleteUserRefreshTokens(ctx, userID)
+// Logout ends the session the access token belongs to
+// Tokens without a session ID (issued before sessions were tracked) log out all sessions
+func (s *AuthService) Logout(ctx context.Context, userID, sessionID string) error {
+	if sessionID == "" {
+		return s.repo.DeleteUserRefreshTokens(ctx, userID)
+	}
+
+	err := s.repo.DeleteUserSession(ctx, userID, sessionID)
+	if appErr, ok := err.(*errors.AppError); ok && appErr.Code == 404 {
+		// Session was already revoked
+		return nil
+	}
+	return err
 }
 
 // ValidateToken validates an access token and returns the claims
@@ -2,11 +2,15 @@ package auth
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/JustDoItBetter/FITS-backend/internal/common/pagination"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/reference"
 	"github.com/JustDoItBetter/FITS-backend/internal/config"
 	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
+	"github.com/JustDoItBetter/FITS-backend/pkg/mailer"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -43,6 +47,185 @@ func (m *MockRepository) UpdateLastLogin(ctx context.Context, userID string) err
 	return args.Error(0)
 }
 
+func (m *MockRepository) LockUser(ctx context.Context, userID string, until time.Time) error {
+	args := m.Called(ctx, userID, until)
+	return args.Error(0)
+}
+
+func (m *MockRepository) UnlockUser(ctx context.Context, user *User) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+func (m *MockRepository) ListUsers(ctx context.Context, filter UserFilter, params pagination.Params) ([]UserSummary, int64, error) {
+	args := m.Called(ctx, filter, params)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]UserSummary), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockRepository) GetUserSummary(ctx context.Context, id string) (*UserSummary, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*UserSummary), args.Error(1)
+}
+
+func (m *MockRepository) GetUserByUserUUID(ctx context.Context, userUUID string) (*User, error) {
+	args := m.Called(ctx, userUUID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*User), args.Error(1)
+}
+
+func (m *MockRepository) HasActiveProfile(ctx context.Context, role crypto.Role, userUUID string) (bool, error) {
+	args := m.Called(ctx, role, userUUID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) GetActiveAdminIDs(ctx context.Context) ([]string, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockRepository) DisableUser(ctx context.Context, userID string, disabledBy *string) error {
+	args := m.Called(ctx, userID, disabledBy)
+	return args.Error(0)
+}
+
+func (m *MockRepository) EnableUser(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) UpdateUserRole(ctx context.Context, userID string, role crypto.Role, userUUID *string, admin crypto.AdminClaims) error {
+	args := m.Called(ctx, userID, role, userUUID, admin)
+	return args.Error(0)
+}
+
+func (m *MockRepository) UpdateUserProfile(ctx context.Context, userID string, displayName string, notifications NotificationPreferences) error {
+	args := m.Called(ctx, userID, displayName, notifications)
+	return args.Error(0)
+}
+
+func (m *MockRepository) DeleteUser(ctx context.Context, user *User) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+func (m *MockRepository) CreatePasswordResetToken(ctx context.Context, token *PasswordResetToken) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetPasswordResetToken(ctx context.Context, tokenHash string) (*PasswordResetToken, error) {
+	args := m.Called(ctx, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*PasswordResetToken), args.Error(1)
+}
+
+func (m *MockRepository) ResetPassword(ctx context.Context, token *PasswordResetToken, passwordHash string) error {
+	args := m.Called(ctx, token, passwordHash)
+	return args.Error(0)
+}
+
+func (m *MockRepository) ChangePassword(ctx context.Context, userID, passwordHash string) error {
+	args := m.Called(ctx, userID, passwordHash)
+	return args.Error(0)
+}
+
+func (m *MockRepository) RecordFailedLogin(ctx context.Context, attempt *LoginAttempt, pruneBefore time.Time) error {
+	args := m.Called(ctx, attempt, pruneBefore)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetLoginFailureStats(ctx context.Context, username, ipAddress string, since time.Time) (*LoginFailureStats, error) {
+	args := m.Called(ctx, username, ipAddress, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*LoginFailureStats), args.Error(1)
+}
+
+func (m *MockRepository) ResetFailedLogins(ctx context.Context, username string) error {
+	args := m.Called(ctx, username)
+	return args.Error(0)
+}
+
+func (m *MockRepository) CreateSecurityEvent(ctx context.Context, event *SecurityEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *MockRepository) SetTOTPSecret(ctx context.Context, userID, secret string) error {
+	args := m.Called(ctx, userID, secret)
+	return args.Error(0)
+}
+
+func (m *MockRepository) EnableMFA(ctx context.Context, userID string, recoveryCodeHashes []string) error {
+	args := m.Called(ctx, userID, recoveryCodeHashes)
+	return args.Error(0)
+}
+
+func (m *MockRepository) DisableMFA(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) UseTOTPStep(ctx context.Context, userID string, step int64) (bool, error) {
+	args := m.Called(ctx, userID, step)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) UseRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error) {
+	args := m.Called(ctx, userID, codeHash)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) CreatePasskeyCredential(ctx context.Context, credential *PasskeyCredential) error {
+	args := m.Called(ctx, credential)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetPasskeyCredentialsByUserID(ctx context.Context, userID string) ([]PasskeyCredential, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]PasskeyCredential), args.Error(1)
+}
+
+func (m *MockRepository) UpdatePasskeyCredential(ctx context.Context, credential *PasskeyCredential) error {
+	args := m.Called(ctx, credential)
+	return args.Error(0)
+}
+
+func (m *MockRepository) DeletePasskeyCredential(ctx context.Context, userID, id string) error {
+	args := m.Called(ctx, userID, id)
+	return args.Error(0)
+}
+
+func (m *MockRepository) CreateWebAuthnSession(ctx context.Context, session *WebAuthnSession) error {
+	args := m.Called(ctx, session)
+	return args.Error(0)
+}
+
+func (m *MockRepository) ConsumeWebAuthnSession(ctx context.Context, id string) (*WebAuthnSession, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*WebAuthnSession), args.Error(1)
+}
+
 func (m *MockRepository) CreateRefreshToken(ctx context.Context, token *RefreshToken) error {
 	args := m.Called(ctx, token)
 	return args.Error(0)
@@ -66,6 +249,34 @@ func (m *MockRepository) DeleteUserRefreshTokens(ctx context.Context, userID str
 	return args.Error(0)
 }
 
+func (m *MockRepository) RehashPassword(ctx context.Context, userID, oldHash, newHash string) error {
+	args := m.Called(ctx, userID, oldHash, newHash)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetUserSessions(ctx context.Context, userID string) ([]RefreshToken, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]RefreshToken), args.Error(1)
+}
+
+func (m *MockRepository) TouchSession(ctx context.Context, id, ipAddress string, usedAt time.Time) error {
+	args := m.Called(ctx, id, ipAddress, usedAt)
+	return args.Error(0)
+}
+
+func (m *MockRepository) DeleteUserSession(ctx context.Context, userID, id string) error {
+	args := m.Called(ctx, userID, id)
+	return args.Error(0)
+}
+
+func (m *MockRepository) DeleteOtherUserSessions(ctx context.Context, userID, keepID string) error {
+	args := m.Called(ctx, userID, keepID)
+	return args.Error(0)
+}
+
 func (m *MockRepository) UpdateUser(ctx context.Context, user *User) error {
 	args := m.Called(ctx, user)
 	return args.Error(0)
@@ -94,8 +305,39 @@ func (m *MockRepository) MarkInvitationAsUsed(ctx context.Context, token string)
 	return args.Error(0)
 }
 
-func (m *MockRepository) DeleteExpiredInvitations(ctx context.Context) error {
-	args := m.Called(ctx)
+func (m *MockRepository) DeleteExpiredInvitations(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRepository) GetInvitationByID(ctx context.Context, id string) (*Invitation, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Invitation), args.Error(1)
+}
+
+func (m *MockRepository) ListInvitations(ctx context.Context, filter InvitationFilter, params pagination.Params) ([]Invitation, int64, error) {
+	args := m.Called(ctx, filter, params)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]Invitation), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockRepository) HasOpenInvitation(ctx context.Context, email string) (bool, error) {
+	args := m.Called(ctx, email)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) RevokeInvitation(ctx context.Context, id, revokedBy string) error {
+	args := m.Called(ctx, id, revokedBy)
+	return args.Error(0)
+}
+
+func (m *MockRepository) ReissueInvitation(ctx context.Context, id, token string, expiresAt time.Time) error {
+	args := m.Called(ctx, id, token, expiresAt)
 	return args.Error(0)
 }
 
@@ -108,10 +350,175 @@ func (m *MockRepository) CreateTeacher(ctx context.Context, teacher *TeacherReco
 	args := m.Called(ctx, teacher)
 	return args.Error(0)
 }
+
+func (m *MockRepository) GetActiveTeacher(ctx context.Context, uuid string) (*reference.Teacher, error) {
+	args := m.Called(ctx, uuid)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*reference.Teacher), args.Error(1)
+}
+
+func (m *MockRepository) GetTeachersByEmail(ctx context.Context, emails []string) (map[string]*reference.Teacher, error) {
+	args := m.Called(ctx, emails)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]*reference.Teacher), args.Error(1)
+}
+
+func (m *MockRepository) GetOpenInvitationEmails(ctx context.Context, emails []string) (map[string]bool, error) {
+	args := m.Called(ctx, emails)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]bool), args.Error(1)
+}
+
+func (m *MockRepository) GetUserContact(ctx context.Context, userUUID string) (*UserContact, error) {
+	args := m.Called(ctx, userUUID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*UserContact), args.Error(1)
+}
+
+func (m *MockRepository) EnqueueEmail(ctx context.Context, msg *mailer.OutboxMessage) error {
+	args := m.Called(ctx, msg)
+	return args.Error(0)
+}
+
+func (m *MockRepository) CreateOIDCLoginState(ctx context.Context, state *OIDCLoginState) error {
+	args := m.Called(ctx, state)
+	return args.Error(0)
+}
+
+func (m *MockRepository) ConsumeOIDCLoginState(ctx context.Context, stateHash string) (*OIDCLoginState, error) {
+	args := m.Called(ctx, stateHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*OIDCLoginState), args.Error(1)
+}
+
+func (m *MockRepository) GetOIDCIdentity(ctx context.Context, issuer, subject string) (*OIDCIdentity, error) {
+	args := m.Called(ctx, issuer, subject)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*OIDCIdentity), args.Error(1)
+}
+
+func (m *MockRepository) GetOIDCIdentityByID(ctx context.Context, id string) (*OIDCIdentity, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*OIDCIdentity), args.Error(1)
+}
+
+func (m *MockRepository) CreateOIDCIdentity(ctx context.Context, identity *OIDCIdentity) error {
+	args := m.Called(ctx, identity)
+	return args.Error(0)
+}
+
+func (m *MockRepository) UpdateOIDCIdentityProfile(ctx context.Context, identity *OIDCIdentity) error {
+	args := m.Called(ctx, identity)
+	return args.Error(0)
+}
+
+func (m *MockRepository) LinkOIDCIdentity(ctx context.Context, id, userID string, linkedBy *string) error {
+	args := m.Called(ctx, id, userID, linkedBy)
+	return args.Error(0)
+}
+
+func (m *MockRepository) ListOIDCIdentities(ctx context.Context, filter OIDCIdentityFilter, params pagination.Params) ([]OIDCIdentity, int64, error) {
+	args := m.Called(ctx, filter, params)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]OIDCIdentity), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockRepository) DeleteOIDCIdentity(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetUserByInvitationEmail(ctx context.Context, email string) (*User, error) {
+	args := m.Called(ctx, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*User), args.Error(1)
+}
+
+func (m *MockRepository) CreateAPIKey(ctx context.Context, key *APIKey) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetAPIKeyByID(ctx context.Context, id string) (*APIKey, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*APIKey), args.Error(1)
+}
+
+func (m *MockRepository) GetAPIKeyByLookupID(ctx context.Context, lookupID string) (*APIKey, error) {
+	args := m.Called(ctx, lookupID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*APIKey), args.Error(1)
+}
+
+func (m *MockRepository) ListAPIKeys(ctx context.Context, params pagination.Params) ([]APIKey, int64, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]APIKey), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockRepository) RevokeAPIKey(ctx context.Context, id string, revokedBy *string) error {
+	args := m.Called(ctx, id, revokedBy)
+	return args.Error(0)
+}
+
+func (m *MockRepository) TouchAPIKey(ctx context.Context, id, ipAddress string, usedAt time.Time) error {
+	args := m.Called(ctx, id, ipAddress, usedAt)
+	return args.Error(0)
+}
+
 func (m *MockRepository) ExecuteInTransaction(ctx context.Context, fn func(repo Repository) error) error {
 	return fn(m)
 }
 
+// testClientIP is the client address used for login attempts in tests
+const testClientIP = "192.0.2.10"
+
+// testClient is the device used for logins in tests
+var testClient = ClientInfo{IPAddress: testClientIP, UserAgent: "Mozilla/5.0 (X11; Linux x86_64) Firefox/128.0"}
+
+// getTestNotifier returns a notifier that builds links but sends no emails
+func getTestNotifier() *Notifier {
+	return NewNotifier("https://fits.example.com", nil)
+}
+
+// Helper function to create test security config
+func getTestSecurityConfig() *config.SecurityConfig {
+	return &config.SecurityConfig{
+		MaxFailedLogins:      5,
+		MaxFailedLoginsPerIP: 20,
+		FailedLoginWindow:    "15m",
+		LockoutDuration:      "15m",
+		LoginDelayBase:       "1s",
+		LoginDelayMax:        "30s",
+	}
+}
+
 // Helper function to create test JWT config
 func getTestJWTConfig() *config.JWTConfig {
 	return &config.JWTConfig{
@@ -130,7 +537,7 @@ func TestNewAuthService(t *testing.T) {
 		jwtService := crypto.NewJWTService("test-secret")
 		jwtConfig := getTestJWTConfig()
 
-		service := NewAuthService(mockRepo, jwtService, jwtConfig)
+		service := NewAuthService(mockRepo, jwtService, jwtConfig, getTestSecurityConfig(), getTestNotifier())
 
 		assert.NotNil(t, service)
 		assert.Equal(t, mockRepo, service.repo)
@@ -146,7 +553,7 @@ func TestAuthService_Login(t *testing.T) {
 		mockRepo := new(MockRepository)
 		jwtService := crypto.NewJWTService("test-secret")
 		jwtConfig := getTestJWTConfig()
-		service := NewAuthService(mockRepo, jwtService, jwtConfig)
+		service := NewAuthService(mockRepo, jwtService, jwtConfig, getTestSecurityConfig(), getTestNotifier())
 
 		// Create test user with hashed password
 		password := "testPassword123"
@@ -159,6 +566,7 @@ func TestAuthService_Login(t *testing.T) {
 		}
 
 		// Mock expectations
+		mockRepo.On("GetLoginFailureStats", ctx, "testuser", testClientIP, mock.AnythingOfType("time.Time")).Return(&LoginFailureStats{}, nil)
 		mockRepo.On("GetUserByUsername", ctx, "testuser").Return(user, nil)
 		mockRepo.On("CreateRefreshToken", ctx, mock.AnythingOfType("*auth.RefreshToken")).Return(nil)
 		mockRepo.On("UpdateLastLogin", ctx, "user-123").Return(nil)
@@ -168,7 +576,7 @@ func TestAuthService_Login(t *testing.T) {
 			Username: "testuser",
 			Password: password,
 		}
-		response, err := service.Login(ctx, req)
+		response, err := service.Login(ctx, req, testClient)
 
 		// Assertions
 		require.NoError(t, err)
@@ -183,11 +591,37 @@ func TestAuthService_Login(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("login rehashes legacy bcrypt hash", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		jwtService := crypto.NewJWTService("test-secret")
+		service := NewAuthService(mockRepo, jwtService, getTestJWTConfig(), getTestSecurityConfig(), getTestNotifier())
+
+		password := "testPassword123"
+		legacyHash, err := crypto.NewBcryptHasher(4).Hash(password)
+		require.NoError(t, err)
+		user := &User{ID: "user-123", Username: "testuser", PasswordHash: legacyHash, Role: crypto.RoleStudent}
+
+		mockRepo.On("GetLoginFailureStats", ctx, "testuser", testClientIP, mock.AnythingOfType("time.Time")).Return(&LoginFailureStats{}, nil)
+		mockRepo.On("GetUserByUsername", ctx, "testuser").Return(user, nil)
+		mockRepo.On("RehashPassword", ctx, "user-123", legacyHash, mock.AnythingOfType("string")).Return(nil)
+		mockRepo.On("CreateRefreshToken", ctx, mock.AnythingOfType("*auth.RefreshToken")).Return(nil)
+		mockRepo.On("UpdateLastLogin", ctx, "user-123").Return(nil)
+
+		response, err := service.Login(ctx, &LoginRequest{Username: "testuser", Password: password}, testClient)
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, response.AccessToken)
+		newHash := mockRepo.Calls[2].Arguments.String(3)
+		assert.True(t, strings.HasPrefix(newHash, "$argon2id$"))
+		assert.NoError(t, crypto.VerifyPassword(password, newHash))
+		mockRepo.AssertExpectations(t)
+	})
+
 	t.Run("login with wrong password", func(t *testing.T) {
 		mockRepo := new(MockRepository)
 		jwtService := crypto.NewJWTService("test-secret")
 		jwtConfig := getTestJWTConfig()
-		service := NewAuthService(mockRepo, jwtService, jwtConfig)
+		service := NewAuthService(mockRepo, jwtService, jwtConfig, getTestSecurityConfig(), getTestNotifier())
 
 		hashedPassword, _ := crypto.HashPassword("correctPassword")
 		user := &User{
@@ -197,13 +631,15 @@ func TestAuthService_Login(t *testing.T) {
 			Role:         crypto.RoleStudent,
 		}
 
+		mockRepo.On("GetLoginFailureStats", ctx, "testuser", testClientIP, mock.AnythingOfType("time.Time")).Return(&LoginFailureStats{}, nil)
 		mockRepo.On("GetUserByUsername", ctx, "testuser").Return(user, nil)
+		mockRepo.On("RecordFailedLogin", ctx, mock.AnythingOfType("*auth.LoginAttempt"), mock.AnythingOfType("time.Time")).Return(nil)
 
 		req := &LoginRequest{
 			Username: "testuser",
 			Password: "wrongPassword",
 		}
-		response, err := service.Login(ctx, req)
+		response, err := service.Login(ctx, req, testClient)
 
 		assert.Error(t, err)
 		assert.Nil(t, response)
@@ -216,15 +652,17 @@ func TestAuthService_Login(t *testing.T) {
 		mockRepo := new(MockRepository)
 		jwtService := crypto.NewJWTService("test-secret")
 		jwtConfig := getTestJWTConfig()
-		service := NewAuthService(mockRepo, jwtService, jwtConfig)
+		service := NewAuthService(mockRepo, jwtService, jwtConfig, getTestSecurityConfig(), getTestNotifier())
 
+		mockRepo.On("GetLoginFailureStats", ctx, "nonexistent", testClientIP, mock.AnythingOfType("time.Time")).Return(&LoginFailureStats{}, nil)
 		mockRepo.On("GetUserByUsername", ctx, "nonexistent").Return(nil, assert.AnError)
+		mockRepo.On("RecordFailedLogin", ctx, mock.AnythingOfType("*auth.LoginAttempt"), mock.AnythingOfType("time.Time")).Return(nil)
 
 		req := &LoginRequest{
 			Username: "nonexistent",
 			Password: "anyPassword",
 		}
-		response, err := service.Login(ctx, req)
+		response, err := service.Login(ctx, req, testClient)
 
 		assert.Error(t, err)
 		assert.Nil(t, response)
@@ -240,7 +678,7 @@ func TestAuthService_Login(t *testing.T) {
 			mockRepo := new(MockRepository)
 			jwtService := crypto.NewJWTService("test-secret")
 			jwtConfig := getTestJWTConfig()
-			service := NewAuthService(mockRepo, jwtService, jwtConfig)
+			service := NewAuthService(mockRepo, jwtService, jwtConfig, getTestSecurityConfig(), getTestNotifier())
 
 			password := "testPassword123"
 			hashedPassword, _ := crypto.HashPassword(password)
@@ -251,6 +689,7 @@ func TestAuthService_Login(t *testing.T) {
 				Role:         role,
 			}
 
+			mockRepo.On("GetLoginFailureStats", ctx, "testuser", testClientIP, mock.AnythingOfType("time.Time")).Return(&LoginFailureStats{}, nil)
 			mockRepo.On("GetUserByUsername", ctx, "testuser").Return(user, nil)
 			mockRepo.On("CreateRefreshToken", ctx, mock.AnythingOfType("*auth.RefreshToken")).Return(nil)
 			mockRepo.On("UpdateLastLogin", ctx, "user-123").Return(nil)
@@ -259,7 +698,7 @@ func TestAuthService_Login(t *testing.T) {
 				Username: "testuser",
 				Password: password,
 			}
-			response, err := service.Login(ctx, req)
+			response, err := service.Login(ctx, req, testClient)
 
 			require.NoError(t, err)
 			assert.Equal(t, string(role), response.Role)
@@ -269,6 +708,216 @@ func TestAuthService_Login(t *testing.T) {
 	})
 }
 
+func TestAuthService_LoginProtection(t *testing.T) {
+	ctx := context.Background()
+	jwtService := crypto.NewJWTService("test-secret")
+	jwtConfig := getTestJWTConfig()
+
+	password := "testPassword123"
+	hashedPassword, _ := crypto.HashPassword(password)
+	newUser := func() *User {
+		return &User{
+			ID:           "user-123",
+			Username:     "testuser",
+			PasswordHash: hashedPassword,
+			Role:         crypto.RoleStudent,
+		}
+	}
+
+	t.Run("rejects locked username without checking password", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewAuthService(mockRepo, jwtService, jwtConfig, getTestSecurityConfig(), getTestNotifier())
+
+		lastFailure := time.Now().Add(-time.Minute)
+		mockRepo.On("GetLoginFailureStats", ctx, "testuser", testClientIP, mock.AnythingOfType("time.Time")).
+			Return(&LoginFailureStats{UsernameFailures: 5, UsernameLastFailure: &lastFailure}, nil)
+
+		response, err := service.Login(ctx, &LoginRequest{Username: "testuser", Password: password}, testClient)
+
+		assert.Nil(t, response)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "account temporarily locked")
+		mockRepo.AssertNotCalled(t, "GetUserByUsername", ctx, "testuser")
+	})
+
+	t.Run("rejects blocked IP address", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewAuthService(mockRepo, jwtService, jwtConfig, getTestSecurityConfig(), getTestNotifier())
+
+		lastFailure := time.Now().Add(-time.Minute)
+		mockRepo.On("GetLoginFailureStats", ctx, "testuser", testClientIP, mock.AnythingOfType("time.Time")).
+			Return(&LoginFailureStats{IPFailures: 20, IPLastFailure: &lastFailure}, nil)
+
+		response, err := service.Login(ctx, &LoginRequest{Username: "testuser", Password: password}, testClient)
+
+		assert.Nil(t, response)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "from this address")
+	})
+
+	t.Run("enforces progressive delay between attempts", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewAuthService(mockRepo, jwtService, jwtConfig, getTestSecurityConfig(), getTestNotifier())
+
+		// 3 failures require a 4s delay, last failure was just now
+		lastFailure := time.Now()
+		mockRepo.On("GetLoginFailureStats", ctx, "testuser", testClientIP, mock.AnythingOfType("time.Time")).
+			Return(&LoginFailureStats{UsernameFailures: 3, UsernameLastFailure: &lastFailure}, nil)
+
+		response, err := service.Login(ctx, &LoginRequest{Username: "testuser", Password: password}, testClient)
+
+		assert.Nil(t, response)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "please wait")
+	})
+
+	t.Run("locks account when threshold is reached", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewAuthService(mockRepo, jwtService, jwtConfig, getTestSecurityConfig(), getTestNotifier())
+
+		lastFailure := time.Now().Add(-time.Minute)
+		mockRepo.On("GetLoginFailureStats", ctx, "testuser", testClientIP, mock.AnythingOfType("time.Time")).
+			Return(&LoginFailureStats{UsernameFailures: 4, UsernameLastFailure: &lastFailure}, nil)
+		mockRepo.On("GetUserByUsername", ctx, "testuser").Return(newUser(), nil)
+		mockRepo.On("RecordFailedLogin", ctx, mock.AnythingOfType("*auth.LoginAttempt"), mock.AnythingOfType("time.Time")).Return(nil)
+		mockRepo.On("LockUser", ctx, "user-123", mock.AnythingOfType("time.Time")).Return(nil)
+		mockRepo.On("CreateSecurityEvent", ctx, mock.MatchedBy(func(e *SecurityEvent) bool {
+			return e.EventType == SecurityEventAccountLocked && e.UserID != nil && *e.UserID == "user-123"
+		})).Return(nil)
+
+		response, err := service.Login(ctx, &LoginRequest{Username: "testuser", Password: "wrongPassword"}, testClient)
+
+		assert.Nil(t, response)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid credentials")
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("records lockout for unknown username without locking a user", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewAuthService(mockRepo, jwtService, jwtConfig, getTestSecurityConfig(), getTestNotifier())
+
+		lastFailure := time.Now().Add(-time.Minute)
+		mockRepo.On("GetLoginFailureStats", ctx, "ghost", testClientIP, mock.AnythingOfType("time.Time")).
+			Return(&LoginFailureStats{UsernameFailures: 4, UsernameLastFailure: &lastFailure}, nil)
+		mockRepo.On("GetUserByUsername", ctx, "ghost").Return(nil, assert.AnError)
+		mockRepo.On("RecordFailedLogin", ctx, mock.AnythingOfType("*auth.LoginAttempt"), mock.AnythingOfType("time.Time")).Return(nil)
+		mockRepo.On("CreateSecurityEvent", ctx, mock.MatchedBy(func(e *SecurityEvent) bool {
+			return e.EventType == SecurityEventAccountLocked && e.UserID == nil && e.Username == "ghost"
+		})).Return(nil)
+
+		response, err := service.Login(ctx, &LoginRequest{Username: "ghost", Password: "wrongPassword"}, testClient)
+
+		assert.Nil(t, response)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid credentials")
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "LockUser", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("prunes attempts that no longer count", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		securityConfig := getTestSecurityConfig()
+		securityConfig.LockoutDuration = "1h"
+		service := NewAuthService(mockRepo, jwtService, jwtConfig, securityConfig, getTestNotifier())
+
+		mockRepo.On("GetLoginFailureStats", ctx, "testuser", testClientIP, mock.AnythingOfType("time.Time")).Return(&LoginFailureStats{}, nil)
+		mockRepo.On("GetUserByUsername", ctx, "testuser").Return(newUser(), nil)
+		// The lockout outlasts the counting window, so attempts are kept as long as the lockout
+		mockRepo.On("RecordFailedLogin", ctx, mock.AnythingOfType("*auth.LoginAttempt"), mock.MatchedBy(func(before time.Time) bool {
+			age := time.Since(before)
+			return age >= time.Hour && age < time.Hour+time.Minute
+		})).Return(nil)
+
+		_, err := service.Login(ctx, &LoginRequest{Username: "testuser", Password: "wrongPassword"}, testClient)
+
+		require.Error(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects account with active lock", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewAuthService(mockRepo, jwtService, jwtConfig, getTestSecurityConfig(), getTestNotifier())
+
+		user := newUser()
+		lockedUntil := time.Now().Add(10 * time.Minute)
+		user.LockedUntil = &lockedUntil
+
+		mockRepo.On("GetLoginFailureStats", ctx, "testuser", testClientIP, mock.AnythingOfType("time.Time")).Return(&LoginFailureStats{}, nil)
+		mockRepo.On("GetUserByUsername", ctx, "testuser").Return(user, nil)
+
+		response, err := service.Login(ctx, &LoginRequest{Username: "testuser", Password: password}, testClient)
+
+		assert.Nil(t, response)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "account temporarily locked")
+	})
+
+	t.Run("successful login resets failed attempts", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewAuthService(mockRepo, jwtService, jwtConfig, getTestSecurityConfig(), getTestNotifier())
+
+		lastFailure := time.Now().Add(-time.Minute)
+		mockRepo.On("GetLoginFailureStats", ctx, "testuser", testClientIP, mock.AnythingOfType("time.Time")).
+			Return(&LoginFailureStats{UsernameFailures: 2, UsernameLastFailure: &lastFailure}, nil)
+		mockRepo.On("GetUserByUsername", ctx, "testuser").Return(newUser(), nil)
+		mockRepo.On("ResetFailedLogins", ctx, "testuser").Return(nil)
+		mockRepo.On("CreateRefreshToken", ctx, mock.AnythingOfType("*auth.RefreshToken")).Return(nil)
+		mockRepo.On("UpdateLastLogin", ctx, "user-123").Return(nil)
+
+		response, err := service.Login(ctx, &LoginRequest{Username: "testuser", Password: password}, testClient)
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, response.AccessToken)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestAuthService_UnlockUser(t *testing.T) {
+	ctx := context.Background()
+	jwtService := crypto.NewJWTService("test-secret")
+
+	t.Run("unlocks user and records security event", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewAuthService(mockRepo, jwtService, getTestJWTConfig(), getTestSecurityConfig(), getTestNotifier())
+
+		user := &User{ID: "user-123", Username: "testuser", Role: crypto.RoleStudent}
+		mockRepo.On("GetUserByID", ctx, "user-123").Return(user, nil)
+		mockRepo.On("UnlockUser", ctx, user).Return(nil)
+		mockRepo.On("CreateSecurityEvent", ctx, mock.MatchedBy(func(e *SecurityEvent) bool {
+			return e.EventType == SecurityEventAccountUnlocked && e.ActorID != nil && *e.ActorID == "admin-001"
+		})).Return(nil)
+
+		err := service.UnlockUser(ctx, "user-123", "admin-001")
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns error for unknown user", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewAuthService(mockRepo, jwtService, getTestJWTConfig(), getTestSecurityConfig(), getTestNotifier())
+
+		mockRepo.On("GetUserByID", ctx, "missing").Return(nil, assert.AnError)
+
+		err := service.UnlockUser(ctx, "missing", "admin-001")
+
+		assert.Error(t, err)
+		mockRepo.AssertNotCalled(t, "UnlockUser", mock.Anything, mock.Anything)
+	})
+}
+
+func TestProgressiveDelay(t *testing.T) {
+	base := time.Second
+	maxDelay := 30 * time.Second
+
+	assert.Equal(t, time.Duration(0), progressiveDelay(0, base, maxDelay))
+	assert.Equal(t, time.Second, progressiveDelay(1, base, maxDelay))
+	assert.Equal(t, 2*time.Second, progressiveDelay(2, base, maxDelay))
+	assert.Equal(t, 8*time.Second, progressiveDelay(4, base, maxDelay))
+	assert.Equal(t, maxDelay, progressiveDelay(10, base, maxDelay))
+}
+
 func TestAuthService_RefreshAccessToken(t *testing.T) {
 	ctx := context.Background()
 
@@ -276,7 +925,7 @@ func TestAuthService_RefreshAccessToken(t *testing.T) {
 		mockRepo := new(MockRepository)
 		jwtService := crypto.NewJWTService("test-secret")
 		jwtConfig := getTestJWTConfig()
-		service := NewAuthService(mockRepo, jwtService, jwtConfig)
+		service := NewAuthService(mockRepo, jwtService, jwtConfig, getTestSecurityConfig(), getTestNotifier())
 
 		// Generate a valid refresh token
 		userID := "user-123"
@@ -284,6 +933,7 @@ func TestAuthService_RefreshAccessToken(t *testing.T) {
 
 		// Mock data
 		refreshTokenRecord := &RefreshToken{
+			ID:        "session-1",
 			UserID:    userID,
 			Token:     refreshToken,
 			ExpiresAt: time.Now().Add(24 * time.Hour),
@@ -296,9 +946,10 @@ func TestAuthService_RefreshAccessToken(t *testing.T) {
 
 		mockRepo.On("GetRefreshToken", ctx, refreshToken).Return(refreshTokenRecord, nil)
 		mockRepo.On("GetUserByID", ctx, userID).Return(user, nil)
+		mockRepo.On("TouchSession", ctx, "session-1", testClientIP, mock.AnythingOfType("time.Time")).Return(nil)
 
 		// Execute refresh
-		response, err := service.RefreshAccessToken(ctx, refreshToken)
+		response, err := service.RefreshAccessToken(ctx, refreshToken, testClient)
 
 		// Assertions
 		require.NoError(t, err)
@@ -308,6 +959,11 @@ func TestAuthService_RefreshAccessToken(t *testing.T) {
 		assert.Equal(t, "Bearer", response.TokenType)
 		assert.Equal(t, userID, response.UserID)
 
+		// New access token stays bound to the session
+		claims, err := jwtService.ValidateToken(response.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, "session-1", claims.SessionID)
+
 		mockRepo.AssertExpectations(t)
 	})
 
@@ -315,9 +971,9 @@ func TestAuthService_RefreshAccessToken(t *testing.T) {
 		mockRepo := new(MockRepository)
 		jwtService := crypto.NewJWTService("test-secret")
 		jwtConfig := getTestJWTConfig()
-		service := NewAuthService(mockRepo, jwtService, jwtConfig)
+		service := NewAuthService(mockRepo, jwtService, jwtConfig, getTestSecurityConfig(), getTestNotifier())
 
-		response, err := service.RefreshAccessToken(ctx, "invalid-token")
+		response, err := service.RefreshAccessToken(ctx, "invalid-token", testClient)
 
 		assert.Error(t, err)
 		assert.Nil(t, response)
@@ -330,12 +986,12 @@ func TestAuthService_RefreshAccessToken(t *testing.T) {
 		mockRepo := new(MockRepository)
 		jwtService := crypto.NewJWTService("test-secret")
 		jwtConfig := getTestJWTConfig()
-		service := NewAuthService(mockRepo, jwtService, jwtConfig)
+		service := NewAuthService(mockRepo, jwtService, jwtConfig, getTestSecurityConfig(), getTestNotifier())
 
 		// Generate an access token instead of refresh token
 		accessToken, _ := jwtService.GenerateToken("user-123", crypto.RoleStudent, crypto.TokenTypeAccess, time.Hour)
 
-		response, err := service.RefreshAccessToken(ctx, accessToken)
+		response, err := service.RefreshAccessToken(ctx, accessToken, testClient)
 
 		assert.Error(t, err)
 		assert.Nil(t, response)
@@ -346,7 +1002,7 @@ func TestAuthService_RefreshAccessToken(t *testing.T) {
 		mockRepo := new(MockRepository)
 		jwtService := crypto.NewJWTService("test-secret")
 		jwtConfig := getTestJWTConfig()
-		service := NewAuthService(mockRepo, jwtService, jwtConfig)
+		service := NewAuthService(mockRepo, jwtService, jwtConfig, getTestSecurityConfig(), getTestNotifier())
 
 		// Generate an expired refresh token
 		userID := "user-123"
@@ -362,7 +1018,7 @@ func TestAuthService_RefreshAccessToken(t *testing.T) {
 		mockRepo.On("GetRefreshToken", ctx, refreshToken).Return(refreshTokenRecord, nil)
 		mockRepo.On("DeleteRefreshToken", ctx, refreshToken).Return(nil)
 
-		response, err := service.RefreshAccessToken(ctx, refreshToken)
+		response, err := service.RefreshAccessToken(ctx, refreshToken, testClient)
 
 		assert.Error(t, err)
 		assert.Nil(t, response)
@@ -375,13 +1031,13 @@ func TestAuthService_RefreshAccessToken(t *testing.T) {
 		mockRepo := new(MockRepository)
 		jwtService := crypto.NewJWTService("test-secret")
 		jwtConfig := getTestJWTConfig()
-		service := NewAuthService(mockRepo, jwtService, jwtConfig)
+		service := NewAuthService(mockRepo, jwtService, jwtConfig, getTestSecurityConfig(), getTestNotifier())
 
 		refreshToken, _ := jwtService.GenerateToken("user-123", crypto.RoleStudent, crypto.TokenTypeRefresh, 24*time.Hour)
 
 		mockRepo.On("GetRefreshToken", ctx, refreshToken).Return(nil, assert.AnError)
 
-		response, err := service.RefreshAccessToken(ctx, refreshToken)
+		response, err := service.RefreshAccessToken(ctx, refreshToken, testClient)
 
 		assert.Error(t, err)
 		assert.Nil(t, response)
@@ -397,12 +1053,12 @@ func TestAuthService_Logout(t *testing.T) {
 		mockRepo := new(MockRepository)
 		jwtService := crypto.NewJWTService("test-secret")
 		jwtConfig := getTestJWTConfig()
-		service := NewAuthService(mockRepo, jwtService, jwtConfig)
+		service := NewAuthService(mockRepo, jwtService, jwtConfig, getTestSecurityConfig(), getTestNotifier())
 
 		userID := "user-123"
 		mockRepo.On("DeleteUserRefreshTokens", ctx, userID).Return(nil)
 
-		err := service.Logout(ctx, userID)
+		err := service.Logout(ctx, userID, "")
 
 		assert.NoError(t, err)
 		mockRepo.AssertExpectations(t)
@@ -412,12 +1068,12 @@ func TestAuthService_Logout(t *testing.T) {
 		mockRepo := new(MockRepository)
 		jwtService := crypto.NewJWTService("test-secret")
 		jwtConfig := getTestJWTConfig()
-		service := NewAuthService(mockRepo, jwtService, jwtConfig)
+		service := NewAuthService(mockRepo, jwtService, jwtConfig, getTestSecurityConfig(), getTestNotifier())
 
 		userID := "user-123"
 		mockRepo.On("DeleteUserRefreshTokens", ctx, userID).Return(assert.AnError)
 
-		err := service.Logout(ctx, userID)
+		err := service.Logout(ctx, userID, "")
 
 		assert.Error(t, err)
 		mockRepo.AssertExpectations(t)
@@ -428,7 +1084,7 @@ func TestAuthService_ValidateToken(t *testing.T) {
 	jwtService := crypto.NewJWTService("test-secret")
 	jwtConfig := getTestJWTConfig()
 	mockRepo := new(MockRepository)
-	service := NewAuthService(mockRepo, jwtService, jwtConfig)
+	service := NewAuthService(mockRepo, jwtService, jwtConfig, getTestSecurityConfig(), getTestNotifier())
 
 	t.Run("validates access token successfully", func(t *testing.T) {
 		token, _ := jwtService.GenerateToken("user-123", crypto.RoleStudent, crypto.TokenTypeAccess, time.Hour)
@@ -495,7 +1151,7 @@ func BenchmarkAuthService_Login(b *testing.B) {
 	mockRepo := new(MockRepository)
 	jwtService := crypto.NewJWTService("test-secret")
 	jwtConfig := getTestJWTConfig()
-	service := NewAuthService(mockRepo, jwtService, jwtConfig)
+	service := NewAuthService(mockRepo, jwtService, jwtConfig, getTestSecurityConfig(), getTestNotifier())
 
 	password := "testPassword123"
 	hashedPassword, _ := crypto.HashPassword(password)
@@ -506,6 +1162,7 @@ func BenchmarkAuthService_Login(b *testing.B) {
 		Role:         crypto.RoleStudent,
 	}
 
+	mockRepo.On("GetLoginFailureStats", mock.Anything, "testuser", testClientIP, mock.AnythingOfType("time.Time")).Return(&LoginFailureStats{}, nil)
 	mockRepo.On("GetUserByUsername", mock.Anything, "testuser").Return(user, nil)
 	mockRepo.On("CreateRefreshToken", mock.Anything, mock.Anything).Return(nil)
 	mockRepo.On("UpdateLastLogin", mock.Anything, "user-123").Return(nil)
@@ -517,6 +1174,6 @@ func BenchmarkAuthService_Login(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, _ = service.Login(ctx, req)
+		_, _ = service.Login(ctx, req, testClient)
 	}
 }
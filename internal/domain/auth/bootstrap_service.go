@@ -10,6 +10,10 @@ import (
 	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
 )
 
+// BootstrapAdminUsername is the admin account created at bootstrap
+// Its existence marks the system as initialized
+const BootstrapAdminUsername = "admin"
+
 // BootstrapService handles admin initialization
 type BootstrapService struct {
 	repo      Repository
@@ -28,7 +32,7 @@ func NewBootstrapService(repo Repository, jwtConfig *config.JWTConfig) *Bootstra
 // This should only be called once during installation
 func (s *BootstrapService) InitializeAdmin(ctx context.Context) (*BootstrapResponse, error) {
 	// 1. Check if admin already exists
-	existingAdmin, _ := s.repo.GetUserByUsername(ctx, "admin")
+	existingAdmin, _ := s.repo.GetUserByUsername(ctx, BootstrapAdminUsername)
 	if existingAdmin != nil {
 		return nil, fmt.Errorf("admin already initialized")
 	}
@@ -56,9 +60,10 @@ func (s *BootstrapService) InitializeAdmin(ctx context.Context) (*BootstrapRespo
 
 	// 5. Create admin user in database
 	adminUser := &User{
-		Username:     "admin",
+		Username:     BootstrapAdminUsername,
 		PasswordHash: "not-used",
 		Role:         crypto.RoleAdmin,
+		AdminScope:   crypto.AdminScopeFull,
 	}
 
 	if err := s.repo.CreateUser(ctx, adminUser); err != nil {
@@ -86,7 +91,7 @@ func (s *BootstrapService) InitializeAdmin(ctx context.Context) (*BootstrapRespo
 
 // IsBootstrapped checks if the system has been bootstrapped
 func (s *BootstrapService) IsBootstrapped(ctx context.Context) (bool, error) {
-	admin, err := s.repo.GetUserByUsername(ctx, "admin")
+	admin, err := s.repo.GetUserByUsername(ctx, BootstrapAdminUsername)
 	if err != nil {
 		return false, nil
 	}
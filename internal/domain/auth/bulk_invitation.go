@@ -0,0 +1,350 @@
+package auth
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/bulk"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/errors"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/reference"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/validation"
+	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
+	"github.com/JustDoItBetter/FITS-backend/pkg/logger"
+)
+
+// MaxBulkInvitationRows limits the number of invitations per roster upload
+const MaxBulkInvitationRows = 2000
+
+// Roster columns, matched case-insensitively against the header row
+const (
+	rosterColumnFirstName    = "first_name"
+	rosterColumnLastName     = "last_name"
+	rosterColumnEmail        = "email"
+	rosterColumnRole         = "role"
+	rosterColumnDepartment   = "department"
+	rosterColumnTeacherEmail = "teacher_email"
+)
+
+// requiredRosterColumns must be present in the header row, the others are optional
+var requiredRosterColumns = []string{rosterColumnFirstName, rosterColumnLastName, rosterColumnEmail, rosterColumnRole}
+
+// Bulk invitation row statuses
+const (
+	BulkRowStatusValid   = "valid"
+	BulkRowStatusCreated = "created"
+	BulkRowStatusError   = "error"
+)
+
+// RosterRow is one invitation entry read from a roster file
+type RosterRow struct {
+	Line         int // Line in the file, the header is line 1
+	FirstName    string
+	LastName     string
+	Email        string
+	Role         string
+	Department   string
+	TeacherEmail string
+}
+
+// ParseRoster reads roster rows from a CSV or XLSX file, detected by the file extension
+func ParseRoster(filename string, data []byte) ([]RosterRow, error) {
+	var records [][]string
+	var err error
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		records, err = bulk.ReadCSV(data)
+	case ".xlsx":
+		records, err = bulk.ReadXLSX(data)
+	default:
+		return nil, errors.BadRequest("roster must be a .csv or .xlsx file")
+	}
+	if err != nil {
+		return nil, errors.BadRequest(fmt.Sprintf("failed to read roster: %v", err))
+	}
+
+	return rosterRowsFromRecords(records)
+}
+
+// rosterRowsFromRecords maps records to roster rows using the header row
+// Rows that are completely empty are skipped
+func rosterRowsFromRecords(records [][]string) ([]RosterRow, error) {
+	if len(records) == 0 {
+		return nil, errors.BadRequest("roster is empty")
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	var missing []string
+	for _, name := range requiredRosterColumns {
+		if _, ok := columns[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, errors.BadRequest(fmt.Sprintf("roster is missing columns: %s", strings.Join(missing, ", ")))
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var rows []RosterRow
+	for i, record := range records[1:] {
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+		rows = append(rows, RosterRow{
+			Line:         i + 2,
+			FirstName:    field(record, rosterColumnFirstName),
+			LastName:     field(record, rosterColumnLastName),
+			Email:        field(record, rosterColumnEmail),
+			Role:         strings.ToLower(field(record, rosterColumnRole)),
+			Department:   field(record, rosterColumnDepartment),
+			TeacherEmail: field(record, rosterColumnTeacherEmail),
+		})
+	}
+
+	if len(rows) == 0 {
+		return nil, errors.BadRequest("roster contains no invitations")
+	}
+	if len(rows) > MaxBulkInvitationRows {
+		return nil, errors.BadRequest(fmt.Sprintf("roster exceeds maximum of %d invitations", MaxBulkInvitationRows))
+	}
+
+	return rows, nil
+}
+
+// BulkCreateInvitations validates every roster row and creates all invitations in one transaction
+// Each invitation email is queued in the same transaction
+// Nothing is created if any row is invalid or dryRun is set; the report lists the problems per row
+func (s *InvitationService) BulkCreateInvitations(ctx context.Context, rows []RosterRow, actorID string, dryRun bool) (*BulkInvitationReport, error) {
+	report := &BulkInvitationReport{
+		DryRun: dryRun,
+		Total:  len(rows),
+		Rows:   make([]BulkInvitationRowResult, len(rows)),
+	}
+
+	// Resolve teachers and existing invitations with one query each instead of one per row
+	var emails, teacherEmails []string
+	for _, row := range rows {
+		if email := validation.SanitizeEmail(row.Email); email != "" {
+			emails = append(emails, email)
+		}
+		if email := validation.SanitizeEmail(row.TeacherEmail); email != "" {
+			teacherEmails = append(teacherEmails, email)
+		}
+	}
+	teachers, err := s.repo.GetTeachersByEmail(ctx, teacherEmails)
+	if err != nil {
+		return nil, err
+	}
+	openInvitations, err := s.repo.GetOpenInvitationEmails(ctx, emails)
+	if err != nil {
+		return nil, err
+	}
+	inviter, err := s.getInviter(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	invitations := make([]*Invitation, len(rows))
+	seen := make(map[string]int)
+	for i, row := range rows {
+		result := &report.Rows[i]
+		result.Row = row.Line
+		result.Email = row.Email
+		result.Role = row.Role
+
+		invitation, rowErrors := newRosterInvitation(row, teachers, s.rules)
+		if invitation != nil && len(rowErrors) == 0 {
+			if err := inviter.check(invitation.Role, rosterDepartment(invitation, teachers[validation.SanitizeEmail(row.TeacherEmail)])); err != nil {
+				rowErrors = append(rowErrors, rowError(err))
+			}
+		}
+		if invitation != nil {
+			result.Email = invitation.Email
+			if line, ok := seen[invitation.Email]; ok {
+				rowErrors = append(rowErrors, fmt.Sprintf("email is already listed in row %d", line))
+			} else {
+				seen[invitation.Email] = row.Line
+			}
+			if openInvitations[invitation.Email] {
+				rowErrors = append(rowErrors, "an open invitation for this email already exists")
+			}
+		}
+
+		if len(rowErrors) > 0 {
+			result.Status = BulkRowStatusError
+			result.Errors = rowErrors
+			report.Failed++
+			continue
+		}
+
+		result.Status = BulkRowStatusValid
+		invitations[i] = invitation
+		report.Valid++
+	}
+
+	if dryRun || report.Failed > 0 {
+		return report, nil
+	}
+
+	expiresAt := time.Now().Add(s.jwtConfig.GetInvitationExpiry())
+	for _, invitation := range invitations {
+		token, err := s.generateToken(invitation.Email, invitation.Role)
+		if err != nil {
+			return nil, err
+		}
+		invitation.Token = token
+		invitation.ExpiresAt = expiresAt
+		if actorID != "" {
+			invitation.CreatedBy = &actorID
+		}
+	}
+
+	emailQueued := make([]bool, len(invitations))
+	err = s.repo.ExecuteInTransaction(ctx, func(txRepo Repository) error {
+		for i, invitation := range invitations {
+			if err := txRepo.CreateInvitation(ctx, invitation); err != nil {
+				// An invitation created concurrently for the same email
+				if _, ok := err.(*errors.AppError); ok {
+					return err
+				}
+				return fmt.Errorf("failed to create invitation for %s: %w", invitation.Email, err)
+			}
+			queued, err := s.notifier.queueInvitation(ctx, txRepo, invitation, "")
+			if err != nil {
+				return err
+			}
+			emailQueued[i] = queued
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, invitation := range invitations {
+		link := s.newCreateInvitationResponse(invitation, emailQueued[i])
+		report.Rows[i].Status = BulkRowStatusCreated
+		report.Rows[i].InvitationLink = link.InvitationLink
+		report.Rows[i].ExpiresAt = link.ExpiresAt
+		report.Rows[i].EmailQueued = link.EmailQueued
+	}
+	report.Created = len(invitations)
+
+	logger.Info("Bulk invitations created",
+		zap.Int("count", report.Created),
+		zap.String("created_by", actorID),
+	)
+	return report, nil
+}
+
+// newRosterInvitation validates a roster row and builds its invitation without token and expiry
+// Returns the invitation (nil if the email is unusable) and all problems found in the row
+func newRosterInvitation(row RosterRow, teachers map[string]*reference.Teacher, rules reference.Rules) (*Invitation, []string) {
+	var rowErrors []string
+
+	email := validation.SanitizeEmail(row.Email)
+	if email == "" {
+		rowErrors = append(rowErrors, "email is missing or invalid")
+	}
+
+	firstName := validation.SanitizeName(row.FirstName)
+	lastName := validation.SanitizeName(row.LastName)
+	if n := utf8.RuneCountInString(firstName); n < 1 || n > 100 {
+		rowErrors = append(rowErrors, "first_name must be 1 to 100 characters")
+	}
+	if n := utf8.RuneCountInString(lastName); n < 1 || n > 100 {
+		rowErrors = append(rowErrors, "last_name must be 1 to 100 characters")
+	}
+
+	invitation := &Invitation{
+		Email:     email,
+		FirstName: firstName,
+		LastName:  lastName,
+	}
+
+	switch row.Role {
+	case "student":
+		invitation.Role = crypto.RoleStudent
+		// The department is optional for students and only checked against the teacher's
+		if department := validation.SanitizeString(row.Department); department != "" {
+			invitation.Department = &department
+		}
+		teacherEmail := validation.SanitizeEmail(row.TeacherEmail)
+		if teacherEmail == "" {
+			rowErrors = append(rowErrors, "teacher_email is missing or invalid")
+		} else if teacher, ok := teachers[teacherEmail]; !ok {
+			rowErrors = append(rowErrors, fmt.Sprintf("no teacher with email %s", teacherEmail))
+		} else if err := rules.CheckTeacher(teacher, invitation.Department); err != nil {
+			rowErrors = append(rowErrors, rowError(err))
+		} else {
+			invitation.TeacherUUID = &teacher.UUID
+		}
+	case "teacher":
+		invitation.Role = crypto.RoleTeacher
+		department := validation.SanitizeString(row.Department)
+		if n := utf8.RuneCountInString(department); n < 1 || n > 100 {
+			rowErrors = append(rowErrors, "department must be 1 to 100 characters for teachers")
+		} else {
+			invitation.Department = &department
+		}
+	default:
+		rowErrors = append(rowErrors, "role must be 'student' or 'teacher'")
+	}
+
+	if email == "" {
+		return nil, rowErrors
+	}
+	return invitation, rowErrors
+}
+
+// rosterDepartment returns the department a roster invitation belongs to, students belong to their teacher's
+func rosterDepartment(invitation *Invitation, teacher *reference.Teacher) string {
+	if invitation.Role == crypto.RoleStudent && teacher != nil {
+		return teacher.Department
+	}
+	return stringValue(invitation.Department)
+}
+
+// rowError returns the message of a validation error without its title for the row report
+func rowError(err error) string {
+	if appErr, ok := err.(*errors.AppError); ok {
+		return appErr.Details
+	}
+	return err.Error()
+}
+
+// WriteInvitationLinksCSV writes the created invitations of a report as CSV for distribution
+func WriteInvitationLinksCSV(w io.Writer, report *BulkInvitationReport) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"row", "email", "role", "invitation_link", "expires_at"}); err != nil {
+		return err
+	}
+	for _, row := range report.Rows {
+		if row.Status != BulkRowStatusCreated {
+			continue
+		}
+		if err := writer.Write([]string{fmt.Sprint(row.Row), row.Email, row.Role, row.InvitationLink, row.ExpiresAt}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,203 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/reference"
+	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
+)
+
+func TestParseRoster(t *testing.T) {
+	t.Run("csv with header in any order", func(t *testing.T) {
+		data := "\xef\xbb\xbfEmail,Role,First_Name,Last_Name,Teacher_Email\n" +
+			"max@example.com,Student,Max,Mustermann,anna@example.com\n" +
+			",,,,\n" +
+			"erika@example.com,student,Erika,Musterfrau,anna@example.com\n"
+
+		rows, err := ParseRoster("roster.CSV", []byte(data))
+
+		require.NoError(t, err)
+		require.Len(t, rows, 2)
+		assert.Equal(t, RosterRow{Line: 2, FirstName: "Max", LastName: "Mustermann", Email: "max@example.com", Role: "student", TeacherEmail: "anna@example.com"}, rows[0])
+		assert.Equal(t, 4, rows[1].Line)
+	})
+
+	t.Run("csv with semicolons", func(t *testing.T) {
+		data := "first_name;last_name;email;role;department\nAnna;Schmidt;anna@example.com;teacher;IT\n"
+
+		rows, err := ParseRoster("roster.csv", []byte(data))
+
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		assert.Equal(t, "IT", rows[0].Department)
+	})
+
+	t.Run("xlsx", func(t *testing.T) {
+		file := excelize.NewFile()
+		sheet := file.GetSheetName(0)
+		require.NoError(t, file.SetSheetRow(sheet, "A1", &[]string{"first_name", "last_name", "email", "role", "department"}))
+		require.NoError(t, file.SetSheetRow(sheet, "A2", &[]string{"Anna", "Schmidt", "anna@example.com", "teacher", "IT"}))
+		var buf bytes.Buffer
+		require.NoError(t, file.Write(&buf))
+
+		rows, err := ParseRoster("roster.xlsx", buf.Bytes())
+
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		assert.Equal(t, RosterRow{Line: 2, FirstName: "Anna", LastName: "Schmidt", Email: "anna@example.com", Role: "teacher", Department: "IT"}, rows[0])
+	})
+
+	t.Run("rejects missing columns", func(t *testing.T) {
+		_, err := ParseRoster("roster.csv", []byte("first_name,email\nMax,max@example.com\n"))
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "last_name, role")
+	})
+
+	t.Run("rejects unsupported file type", func(t *testing.T) {
+		_, err := ParseRoster("roster.xls", []byte("data"))
+
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects roster without rows", func(t *testing.T) {
+		_, err := ParseRoster("roster.csv", []byte("first_name,last_name,email,role\n"))
+
+		assert.Error(t, err)
+	})
+}
+
+func TestInvitationService_BulkCreateInvitations(t *testing.T) {
+	ctx := context.Background()
+	rows := []RosterRow{
+		{Line: 2, FirstName: "Max", LastName: "Mustermann", Email: "Max@Example.com", Role: "student", TeacherEmail: "anna@example.com"},
+		{Line: 3, FirstName: "Anna", LastName: "Schmidt", Email: "anna.new@example.com", Role: "teacher", Department: "IT"},
+	}
+	emails := []string{"max@example.com", "anna.new@example.com"}
+	teacherEmails := []string{"anna@example.com"}
+	teachers := map[string]*reference.Teacher{
+		"anna@example.com": {UUID: "teacher-1", Email: "anna@example.com", Department: "IT"},
+	}
+
+	t.Run("creates all invitations in one transaction", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service, jwtService := newTestInvitationService(mockRepo)
+		mockRepo.On("GetTeachersByEmail", ctx, teacherEmails).Return(teachers, nil)
+		mockAdmin(mockRepo, ctx, "admin-1", crypto.AdminClaims{AdminScope: crypto.AdminScopeFull})
+		mockRepo.On("GetOpenInvitationEmails", ctx, emails).Return(map[string]bool{}, nil)
+		mockRepo.On("CreateInvitation", ctx, mock.AnythingOfType("*auth.Invitation")).Return(nil)
+
+		report, err := service.BulkCreateInvitations(ctx, rows, "admin-1", false)
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, report.Created)
+		assert.Equal(t, 0, report.Failed)
+		mockRepo.AssertNumberOfCalls(t, "CreateInvitation", 2)
+
+		student := mockRepo.Calls[3].Arguments.Get(1).(*Invitation)
+		assert.Equal(t, "max@example.com", student.Email)
+		assert.Equal(t, crypto.RoleStudent, student.Role)
+		require.NotNil(t, student.TeacherUUID)
+		assert.Equal(t, "teacher-1", *student.TeacherUUID)
+		require.NotNil(t, student.CreatedBy)
+		assert.Equal(t, "admin-1", *student.CreatedBy)
+		claims, err := jwtService.ValidateToken(student.Token)
+		require.NoError(t, err)
+		assert.Equal(t, "max@example.com", claims.UserID)
+
+		assert.Equal(t, BulkRowStatusCreated, report.Rows[0].Status)
+		assert.Contains(t, report.Rows[0].InvitationLink, student.Token)
+
+		var buf bytes.Buffer
+		require.NoError(t, WriteInvitationLinksCSV(&buf, report))
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		require.Len(t, lines, 3)
+		assert.True(t, strings.HasPrefix(lines[1], "2,max@example.com,student,"))
+	})
+
+	t.Run("dry run validates without creating", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service, _ := newTestInvitationService(mockRepo)
+		mockRepo.On("GetTeachersByEmail", ctx, teacherEmails).Return(teachers, nil)
+		mockAdmin(mockRepo, ctx, "admin-1", crypto.AdminClaims{AdminScope: crypto.AdminScopeFull})
+		mockRepo.On("GetOpenInvitationEmails", ctx, emails).Return(map[string]bool{}, nil)
+
+		report, err := service.BulkCreateInvitations(ctx, rows, "admin-1", true)
+
+		require.NoError(t, err)
+		assert.True(t, report.DryRun)
+		assert.Equal(t, 2, report.Valid)
+		assert.Equal(t, 0, report.Created)
+		assert.Equal(t, BulkRowStatusValid, report.Rows[1].Status)
+		mockRepo.AssertNotCalled(t, "CreateInvitation", mock.Anything, mock.Anything)
+	})
+
+	t.Run("reports every invalid row and creates nothing", func(t *testing.T) {
+		invalid := []RosterRow{
+			{Line: 2, FirstName: "Max", LastName: "Mustermann", Email: "max@example.com", Role: "student", TeacherEmail: "unknown@example.com"},
+			{Line: 3, FirstName: "Erika", LastName: "", Email: "not-an-email", Role: "student"},
+			{Line: 4, FirstName: "Anna", LastName: "Schmidt", Email: "anna.new@example.com", Role: "teacher"},
+			{Line: 5, FirstName: "Max", LastName: "Mustermann", Email: "MAX@example.com", Role: "admin"},
+			{Line: 6, FirstName: "Tom", LastName: "Taler", Email: "tom@example.com", Role: "teacher", Department: "Math"},
+		}
+		mockRepo := new(MockRepository)
+		service, _ := newTestInvitationService(mockRepo)
+		mockRepo.On("GetTeachersByEmail", ctx, []string{"unknown@example.com"}).Return(map[string]*reference.Teacher{}, nil)
+		mockAdmin(mockRepo, ctx, "admin-1", crypto.AdminClaims{AdminScope: crypto.AdminScopeFull})
+		mockRepo.On("GetOpenInvitationEmails", ctx, []string{"max@example.com", "anna.new@example.com", "max@example.com", "tom@example.com"}).
+			Return(map[string]bool{"tom@example.com": true}, nil)
+
+		report, err := service.BulkCreateInvitations(ctx, invalid, "admin-1", false)
+
+		require.NoError(t, err)
+		assert.Equal(t, 5, report.Failed)
+		assert.Equal(t, 0, report.Created)
+		assert.Contains(t, report.Rows[0].Errors, "no teacher with email unknown@example.com")
+		assert.ElementsMatch(t, []string{"email is missing or invalid", "last_name must be 1 to 100 characters", "teacher_email is missing or invalid"}, report.Rows[1].Errors)
+		assert.Contains(t, report.Rows[2].Errors, "department must be 1 to 100 characters for teachers")
+		assert.ElementsMatch(t, []string{"role must be 'student' or 'teacher'", "email is already listed in row 2"}, report.Rows[3].Errors)
+		assert.Contains(t, report.Rows[4].Errors, "an open invitation for this email already exists")
+		mockRepo.AssertNotCalled(t, "CreateInvitation", mock.Anything, mock.Anything)
+	})
+
+	t.Run("limits department admins to their department", func(t *testing.T) {
+		roster := append(rows, RosterRow{Line: 4, FirstName: "Tom", LastName: "Taler", Email: "tom@example.com", Role: "teacher", Department: "Math"})
+		mockRepo := new(MockRepository)
+		service, _ := newTestInvitationService(mockRepo)
+		mockRepo.On("GetTeachersByEmail", ctx, teacherEmails).Return(teachers, nil)
+		mockAdmin(mockRepo, ctx, "admin-2", crypto.AdminClaims{AdminScope: crypto.AdminScopeDepartment, Department: "IT"})
+		mockRepo.On("GetOpenInvitationEmails", ctx, append(emails, "tom@example.com")).Return(map[string]bool{}, nil)
+
+		report, err := service.BulkCreateInvitations(ctx, roster, "admin-2", true)
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, report.Valid)
+		assert.Equal(t, []string{"department admins can only invite to their department 'IT'"}, report.Rows[2].Errors)
+	})
+
+	t.Run("checks student department against the teacher", func(t *testing.T) {
+		roster := []RosterRow{
+			{Line: 2, FirstName: "Max", LastName: "Mustermann", Email: "max@example.com", Role: "student", Department: "Math", TeacherEmail: "anna@example.com"},
+			{Line: 3, FirstName: "Erika", LastName: "Muster", Email: "erika@example.com", Role: "student", Department: "it", TeacherEmail: "anna@example.com"},
+		}
+		mockRepo := new(MockRepository)
+		service := NewInvitationService(mockRepo, crypto.NewJWTService("test-secret"), getTestJWTConfig(), getTestNotifier(), reference.Rules{RequireDepartmentMatch: true})
+		mockRepo.On("GetTeachersByEmail", ctx, []string{"anna@example.com", "anna@example.com"}).Return(teachers, nil)
+		mockAdmin(mockRepo, ctx, "admin-1", crypto.AdminClaims{AdminScope: crypto.AdminScopeFull})
+		mockRepo.On("GetOpenInvitationEmails", ctx, []string{"max@example.com", "erika@example.com"}).Return(map[string]bool{}, nil)
+
+		report, err := service.BulkCreateInvitations(ctx, roster, "admin-1", true)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"department 'Math' does not match the teacher's department 'IT'"}, report.Rows[0].Errors)
+		assert.Equal(t, BulkRowStatusValid, report.Rows[1].Status)
+	})
+}
@@ -1,7 +1,14 @@
 package auth
 
 import (
+	"bytes"
+	"io"
+	"time"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/errors"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/pagination"
 	"github.com/JustDoItBetter/FITS-backend/internal/common/response"
+	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -10,14 +17,21 @@ type Handler struct {
 	bootstrapService  *BootstrapService
 	invitationService *InvitationService
 	authService       *AuthService
+	passkeyService    *PasskeyService
+	oidcService       *OIDCService // nil if OpenID Connect login is disabled
+	apiKeyService     *APIKeyService
 }
 
 // NewHandler creates a new auth handler
-func NewHandler(bootstrapService *BootstrapService, invitationService *InvitationService, authService *AuthService) *Handler {
+// oidcService may be nil, the OpenID Connect routes are only registered if it is set
+func NewHandler(bootstrapService *BootstrapService, invitationService *InvitationService, authService *AuthService, passkeyService *PasskeyService, oidcService *OIDCService, apiKeyService *APIKeyService) *Handler {
 	return &Handler{
 		bootstrapService:  bootstrapService,
 		invitationService: invitationService,
 		authService:       authService,
+		passkeyService:    passkeyService,
+		oidcService:       oidcService,
+		apiKeyService:     apiKeyService,
 	}
 }
 
@@ -31,7 +45,16 @@ func (h *Handler) RegisterRoutes(app *fiber.App) {
 	auth := app.Group("/api/v1/auth")
 	auth.Post("/login", h.Login)
 	auth.Post("/refresh", h.RefreshToken)
-	// Logout requires auth - will be added with middleware
+	auth.Post("/mfa/verify", h.VerifyMFA)
+	auth.Post("/passkey/login/begin", h.BeginPasskeyLogin)
+	auth.Post("/passkey/login/finish", h.FinishPasskeyLogin)
+	auth.Post("/password/reset", h.ResetPassword)
+	if h.oidcService != nil {
+		auth.Get("/oidc/config", h.GetOIDCConfig)
+		auth.Get("/oidc/authorize", h.BeginOIDCLogin)
+		auth.Post("/oidc/callback", h.FinishOIDCLogin)
+	}
+	// Logout, password change, MFA and passkey management require auth - registered in main.go with middleware
 
 	// Invitation routes (public for getting details and completing)
 	invite := app.Group("/api/v1/invite")
@@ -75,6 +98,7 @@ func (h *Handler) InitializeAdmin(c *fiber.Ctx) error {
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
 // @Failure 422 {object} response.ErrorResponse
+// @Failure 429 {object} response.ErrorResponse
 // @Router /api/v1/auth/login [post]
 func (h *Handler) Login(c *fiber.Ctx) error {
 	var req LoginRequest
@@ -82,7 +106,7 @@ func (h *Handler) Login(c *fiber.Ctx) error {
 		return response.Error(c, err)
 	}
 
-	result, err := h.authService.Login(c.Context(), &req)
+	result, err := h.authService.Login(c.Context(), &req, clientInfo(c))
 	if err != nil {
 		return response.Error(c, err)
 	}
@@ -107,7 +131,7 @@ func (h *Handler) RefreshToken(c *fiber.Ctx) error {
 		return response.Error(c, err)
 	}
 
-	result, err := h.authService.RefreshAccessToken(c.Context(), req.RefreshToken)
+	result, err := h.authService.RefreshAccessToken(c.Context(), req.RefreshToken, clientInfo(c))
 	if err != nil {
 		return response.Error(c, err)
 	}
@@ -117,7 +141,7 @@ func (h *Handler) RefreshToken(c *fiber.Ctx) error {
 
 // Logout handles user logout
 // @Summary User logout
-// @Description Logout user by invalidating all refresh tokens
+// @Description End the current session by invalidating its refresh token
 // @Tags auth
 // @Produce json
 // @Success 200 {object} response.SuccessResponse
@@ -131,57 +155,150 @@ func (h *Handler) Logout(c *fiber.Ctx) error {
 		return response.Error(c, fiber.NewError(fiber.StatusUnauthorized, "user not authenticated"))
 	}
 
-	if err := h.authService.Logout(c.Context(), userID.(string)); err != nil {
+	sessionID, _ := c.Locals("session_id").(string)
+
+	if err := h.authService.Logout(c.Context(), userID.(string), sessionID); err != nil {
 		return response.Error(c, err)
 	}
 
 	return response.SuccessWithMessage(c, "logged out successfully", nil)
 }
 
-// Invitation Endpoints
+// ListSessions lists the sessions of the current user
+// @Summary List own sessions
+// @Description List active sessions (devices) of the current user. The session of the calling token is marked as current.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} response.SuccessResponse{data=[]SessionResponse}
+// @Failure 401 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/auth/sessions [get]
+func (h *Handler) ListSessions(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	sessionID, _ := c.Locals("session_id").(string)
 
-// CreateInvitation creates a new user invitation
-// @Summary Create invitation
-// @Description Create invitation link for student or teacher (Admin only)
-// @Tags invitations
+	result, err := h.authService.ListSessions(c.Context(), userID, sessionID)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.Success(c, result)
+}
+
+// RevokeSession revokes one session of the current user
+// @Summary Revoke own session
+// @Description Sign out a single device by invalidating its refresh token. Issued access tokens stay valid until they expire.
+// @Tags auth
+// @Produce json
+// @Param id path string true "Session ID" format(uuid)
+// @Success 200 {object} response.SuccessResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/auth/sessions/{id} [delete]
+func (h *Handler) RevokeSession(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+
+	if err := h.authService.RevokeSession(c.Context(), userID, c.Params("id")); err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.SuccessWithMessage(c, "session revoked successfully", nil)
+}
+
+// RevokeOtherSessions revokes all sessions of the current user except the current one
+// @Summary Revoke other sessions
+// @Description Sign out all other devices of the current user
+// @Tags auth
+// @Produce json
+// @Success 200 {object} response.SuccessResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/auth/sessions [delete]
+func (h *Handler) RevokeOtherSessions(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	sessionID, _ := c.Locals("session_id").(string)
+
+	if err := h.authService.RevokeOtherSessions(c.Context(), userID, sessionID); err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.SuccessWithMessage(c, "other sessions revoked successfully", nil)
+}
+
+// ChangePassword changes the password of the current user
+// @Summary Change password
+// @Description Change the own password. Requires the current password; all refresh tokens are revoked.
+// @Description Wrong current passwords count as failed logins and lock the account like failed logins do.
+// @Tags auth
 // @Accept json
 // @Produce json
-// @Param invitation body CreateInvitationRequest true "Invitation data"
-// @Success 201 {object} response.SuccessResponse{data=CreateInvitationResponse}
+// @Param passwords body ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} response.SuccessResponse
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
 // @Failure 422 {object} response.ErrorResponse
+// @Failure 429 {object} response.ErrorResponse "Too many failed attempts"
 // @Security BearerAuth
-// @Router /api/v1/admin/invite [post]
-func (h *Handler) CreateInvitation(c *fiber.Ctx) error {
-	var req CreateInvitationRequest
+// @Router /api/v1/auth/password [post]
+func (h *Handler) ChangePassword(c *fiber.Ctx) error {
+	var req ChangePasswordRequest
 	if err := c.BodyParser(&req); err != nil {
 		return response.Error(c, err)
 	}
 
-	result, err := h.invitationService.CreateInvitation(c.Context(), &req)
-	if err != nil {
+	userID, _ := c.Locals("user_id").(string)
+
+	if err := h.authService.ChangePassword(c.Context(), userID, &req, clientInfo(c)); err != nil {
 		return response.Error(c, err)
 	}
 
-	return response.Created(c, result)
+	return response.SuccessWithMessage(c, "password changed successfully", nil)
 }
 
-// GetInvitationDetails retrieves invitation details
-// @Summary Get invitation details
-// @Description Get invitation information by token
-// @Tags invitations
+// ResetPassword sets a new password with a reset token
+// @Summary Reset password
+// @Description Set a new password with a reset token issued by an admin. All refresh tokens are revoked.
+// @Tags auth
+// @Accept json
 // @Produce json
-// @Param token path string true "Invitation token"
-// @Success 200 {object} response.SuccessResponse{data=InvitationResponse}
+// @Param reset body ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Router /api/v1/auth/password/reset [post]
+func (h *Handler) ResetPassword(c *fiber.Ctx) error {
+	var req ResetPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, err)
+	}
+
+	if err := h.authService.ResetPassword(c.Context(), &req); err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.SuccessWithMessage(c, "password reset successfully", nil)
+}
+
+// VerifyMFA completes a login that requires two-factor authentication
+// @Summary Verify second factor
+// @Description Exchange the MFA token returned by login and a TOTP or recovery code for access and refresh tokens
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param verification body MFAVerifyRequest true "MFA token and code"
+// @Success 200 {object} response.SuccessResponse{data=LoginResponse}
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
-// @Failure 404 {object} response.ErrorResponse
-// @Router /api/v1/invite/{token} [get]
-func (h *Handler) GetInvitationDetails(c *fiber.Ctx) error {
-	token := c.Params("token")
+// @Failure 429 {object} response.ErrorResponse
+// @Router /api/v1/auth/mfa/verify [post]
+func (h *Handler) VerifyMFA(c *fiber.Ctx) error {
+	var req MFAVerifyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, err)
+	}
 
-	result, err := h.invitationService.GetInvitationDetails(c.Context(), token)
+	result, err := h.authService.VerifyMFA(c.Context(), &req, clientInfo(c))
 	if err != nil {
 		return response.Error(c, err)
 	}
@@ -189,30 +306,998 @@ func (h *Handler) GetInvitationDetails(c *fiber.Ctx) error {
 	return response.Success(c, result)
 }
 
-// CompleteInvitation completes user registration
-// @Summary Complete invitation
-// @Description Complete user registration with username and password
-// @Tags invitations
+// EnrollMFA starts two-factor enrollment
+// @Summary Enroll in two-factor authentication
+// @Description Generate a TOTP secret and provisioning URI for an authenticator app. Accepts an access token or the MFA token from login.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} response.SuccessResponse{data=MFAEnrollResponse}
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/auth/mfa/enroll [post]
+func (h *Handler) EnrollMFA(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+
+	result, err := h.authService.EnrollMFA(c.Context(), userID)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.Success(c, result)
+}
+
+// ConfirmMFA activates two-factor authentication
+// @Summary Confirm two-factor enrollment
+// @Description Verify the first TOTP code and activate 2FA. Returns one-time recovery codes, and tokens when called with the MFA token from login.
+// @Tags auth
 // @Accept json
 // @Produce json
-// @Param token path string true "Invitation token"
-// @Param credentials body CompleteInvitationRequest true "User credentials"
-// @Success 200 {object} response.SuccessResponse
+// @Param confirmation body MFAConfirmRequest true "TOTP code"
+// @Success 200 {object} response.SuccessResponse{data=MFAConfirmResponse}
 // @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
 // @Failure 409 {object} response.ErrorResponse
 // @Failure 422 {object} response.ErrorResponse
-// @Router /api/v1/invite/{token}/complete [post]
-func (h *Handler) CompleteInvitation(c *fiber.Ctx) error {
-	token := c.Params("token")
+// @Security BearerAuth
+// @Router /api/v1/auth/mfa/confirm [post]
+func (h *Handler) ConfirmMFA(c *fiber.Ctx) error {
+	var req MFAConfirmRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, err)
+	}
 
-	var req CompleteInvitationRequest
+	userID, _ := c.Locals("user_id").(string)
+
+	// Enrollment during login with an MFA token completes the login
+	var client *ClientInfo
+	if c.Locals("token_type") == crypto.TokenTypeMFA {
+		info := clientInfo(c)
+		client = &info
+	}
+
+	result, err := h.authService.ConfirmMFA(c.Context(), userID, &req, client)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.Success(c, result)
+}
+
+// DisableMFA turns off two-factor authentication
+// @Summary Disable two-factor authentication
+// @Description Disable 2FA after re-entering password and a TOTP or recovery code. Not allowed for roles that require 2FA.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param credentials body MFADisableRequest true "Password and code"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/auth/mfa/disable [post]
+func (h *Handler) DisableMFA(c *fiber.Ctx) error {
+	var req MFADisableRequest
 	if err := c.BodyParser(&req); err != nil {
 		return response.Error(c, err)
 	}
 
-	if err := h.invitationService.CompleteInvitation(c.Context(), token, &req); err != nil {
+	userID, _ := c.Locals("user_id").(string)
+
+	if err := h.authService.DisableMFA(c.Context(), userID, &req); err != nil {
 		return response.Error(c, err)
 	}
 
-	return response.SuccessWithMessage(c, "registration completed successfully", nil)
+	return response.SuccessWithMessage(c, "two-factor authentication disabled", nil)
+}
+
+// BeginPasskeyLogin starts a passkey login
+// @Summary Begin passkey login
+// @Description Start a usernameless WebAuthn login. Pass the options to navigator.credentials.get()
+// @Tags passkeys
+// @Produce json
+// @Success 200 {object} response.SuccessResponse{data=PasskeyBeginResponse}
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/auth/passkey/login/begin [post]
+func (h *Handler) BeginPasskeyLogin(c *fiber.Ctx) error {
+	result, err := h.passkeyService.BeginLogin(c.Context())
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.Success(c, result)
+}
+
+// FinishPasskeyLogin completes a passkey login
+// @Summary Finish passkey login
+// @Description Verify the authenticator assertion and return access and refresh tokens
+// @Tags passkeys
+// @Accept json
+// @Produce json
+// @Param assertion body PasskeyFinishRequest true "Session ID and assertion"
+// @Success 200 {object} response.SuccessResponse{data=LoginResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 429 {object} response.ErrorResponse
+// @Router /api/v1/auth/passkey/login/finish [post]
+func (h *Handler) FinishPasskeyLogin(c *fiber.Ctx) error {
+	var req PasskeyFinishRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, err)
+	}
+
+	result, err := h.passkeyService.FinishLogin(c.Context(), &req, clientInfo(c))
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.Success(c, result)
+}
+
+// BeginPasskeyRegistration starts registering a passkey
+// @Summary Begin passkey registration
+// @Description Start registering a passkey for the current user. Pass the options to navigator.credentials.create()
+// @Tags passkeys
+// @Produce json
+// @Success 200 {object} response.SuccessResponse{data=PasskeyBeginResponse}
+// @Failure 401 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/auth/passkey/register/begin [post]
+func (h *Handler) BeginPasskeyRegistration(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+
+	result, err := h.passkeyService.BeginRegistration(c.Context(), userID)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.Success(c, result)
+}
+
+// FinishPasskeyRegistration stores a new passkey
+// @Summary Finish passkey registration
+// @Description Verify the authenticator attestation and store the passkey
+// @Tags passkeys
+// @Accept json
+// @Produce json
+// @Param attestation body PasskeyFinishRequest true "Session ID, name and attestation"
+// @Success 201 {object} response.SuccessResponse{data=PasskeyCredential}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/auth/passkey/register/finish [post]
+func (h *Handler) FinishPasskeyRegistration(c *fiber.Ctx) error {
+	var req PasskeyFinishRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, err)
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+
+	result, err := h.passkeyService.FinishRegistration(c.Context(), userID, &req)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.Created(c, result)
+}
+
+// ListPasskeys lists the passkeys of the current user
+// @Summary List passkeys
+// @Description Get all passkeys registered by the current user
+// @Tags passkeys
+// @Produce json
+// @Success 200 {object} response.SuccessResponse{data=[]PasskeyCredential}
+// @Failure 401 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/auth/passkeys [get]
+func (h *Handler) ListPasskeys(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+
+	result, err := h.passkeyService.ListCredentials(c.Context(), userID)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.Success(c, result)
+}
+
+// DeletePasskey removes a passkey of the current user
+// @Summary Delete passkey
+// @Description Remove a passkey of the current user
+// @Tags passkeys
+// @Produce json
+// @Param id path string true "Passkey ID" format(uuid)
+// @Success 200 {object} response.SuccessResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/auth/passkeys/{id} [delete]
+func (h *Handler) DeletePasskey(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+
+	if err := h.passkeyService.DeleteCredential(c.Context(), userID, c.Params("id")); err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.SuccessWithMessage(c, "passkey deleted successfully", nil)
+}
+
+// UnlockUser lifts a brute-force lockout
+// @Summary Unlock user account
+// @Description Clear a temporary lockout and the failed login attempts of an account (Admin only)
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID" format(uuid)
+// @Success 200 {object} response.SuccessResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/users/{id}/unlock [post]
+func (h *Handler) UnlockUser(c *fiber.Ctx) error {
+	actorID, _ := c.Locals("user_id").(string)
+
+	if err := h.authService.UnlockUser(c.Context(), c.Params("id"), actorID); err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.SuccessWithMessage(c, "user unlocked successfully", nil)
+}
+
+// CreatePasswordReset issues a password reset link for a user
+// @Summary Issue password reset
+// @Description Create a single-use password reset link for a user who forgot their password. The link is emailed to students and teachers and also returned (Admin only)
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID" format(uuid)
+// @Success 201 {object} response.SuccessResponse{data=PasswordResetResponse}
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/users/{id}/password-reset [post]
+func (h *Handler) CreatePasswordReset(c *fiber.Ctx) error {
+	actorID, _ := c.Locals("user_id").(string)
+
+	result, err := h.authService.CreatePasswordReset(c.Context(), c.Params("id"), actorID)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.Created(c, result)
+}
+
+// ListUserSessions lists the sessions of a user
+// @Summary List user sessions
+// @Description List active sessions (devices) of a user (Admin only)
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID" format(uuid)
+// @Success 200 {object} response.SuccessResponse{data=[]SessionResponse}
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/users/{id}/sessions [get]
+func (h *Handler) ListUserSessions(c *fiber.Ctx) error {
+	actorID, _ := c.Locals("user_id").(string)
+
+	result, err := h.authService.ListUserSessions(c.Context(), c.Params("id"), actorID)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.Success(c, result)
+}
+
+// RevokeUserSession revokes one session of a user
+// @Summary Revoke user session
+// @Description Sign out a single device of a user (Admin only)
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID" format(uuid)
+// @Param sessionId path string true "Session ID" format(uuid)
+// @Success 200 {object} response.SuccessResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/users/{id}/sessions/{sessionId} [delete]
+func (h *Handler) RevokeUserSession(c *fiber.Ctx) error {
+	actorID, _ := c.Locals("user_id").(string)
+
+	if err := h.authService.RevokeUserSession(c.Context(), c.Params("id"), c.Params("sessionId"), actorID); err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.SuccessWithMessage(c, "session revoked successfully", nil)
+}
+
+// RevokeUserSessions revokes all sessions of a user
+// @Summary Revoke all user sessions
+// @Description Sign out all devices of a user (Admin only)
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID" format(uuid)
+// @Success 200 {object} response.SuccessResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/users/{id}/sessions [delete]
+func (h *Handler) RevokeUserSessions(c *fiber.Ctx) error {
+	actorID, _ := c.Locals("user_id").(string)
+
+	if err := h.authService.RevokeUserSessions(c.Context(), c.Params("id"), actorID); err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.SuccessWithMessage(c, "sessions revoked successfully", nil)
+}
+
+// ListUsers lists user accounts
+// @Summary List users
+// @Description Paginated list of user accounts with the name and email of their student or teacher record, newest first (Admin only)
+// @Tags admin
+// @Produce json
+// @Param role query string false "Filter by role" Enums(admin, teacher, student)
+// @Param status query string false "Filter by status" Enums(active, disabled, locked)
+// @Param search query string false "Part of the username, name or email"
+// @Param page query int false "Page number (default: 1)" minimum(1)
+// @Param limit query int false "Items per page (default: 20, max: 100)" minimum(1) maximum(100)
+// @Success 200 {object} pagination.Response{data=[]UserSummary}
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/users [get]
+func (h *Handler) ListUsers(c *fiber.Ctx) error {
+	params := pagination.ExtractParams(c)
+	filter := UserFilter{
+		Role:   c.Query("role"),
+		Status: c.Query("status"),
+		Search: c.Query("search"),
+	}
+
+	users, totalCount, err := h.authService.ListUsers(c.Context(), filter, params)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return c.JSON(pagination.NewResponse(users, params, totalCount))
+}
+
+// GetUser returns a user account
+// @Summary Get user
+// @Description Get a user account with the name and email of its student or teacher record (Admin only)
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID" format(uuid)
+// @Success 200 {object} response.SuccessResponse{data=UserSummary}
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/users/{id} [get]
+func (h *Handler) GetUser(c *fiber.Ctx) error {
+	result, err := h.authService.GetUser(c.Context(), c.Params("id"))
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.Success(c, result)
+}
+
+// DisableUser disables a user account
+// @Summary Disable user
+// @Description Block all logins of an account and sign out all its devices. The last active admin can't be disabled (Admin only)
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID" format(uuid)
+// @Success 200 {object} response.SuccessResponse{data=UserSummary}
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/users/{id}/disable [post]
+func (h *Handler) DisableUser(c *fiber.Ctx) error {
+	actorID, _ := c.Locals("user_id").(string)
+
+	result, err := h.authService.DisableUser(c.Context(), c.Params("id"), actorID)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.Success(c, result)
+}
+
+// EnableUser re-enables a disabled user account
+// @Description Allow a disabled account to log in again (Admin only). Accounts of deleted student or teacher records can't be enabled
+// @Description Allow a disabled account to log in again (Admin only)
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID" format(uuid)
+// @Success 200 {object} response.SuccessResponse{data=UserSummary}
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/users/{id}/enable [post]
+func (h *Handler) EnableUser(c *fiber.Ctx) error {
+	actorID, _ := c.Locals("user_id").(string)
+
+	result, err := h.authService.EnableUser(c.Context(), c.Params("id"), actorID)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.Success(c, result)
+}
+
+// ChangeUserRole changes the role of a user account
+// @Summary Change user role
+// @Description Change the role or admin scope of an account. Teachers and students must be linked to an existing record of their role that belongs to no other account; admins have none but need an admin_scope. All devices of the account are signed out (Full admins only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID" format(uuid)
+// @Param role body ChangeUserRoleRequest true "New role"
+// @Success 200 {object} response.SuccessResponse{data=UserSummary}
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/users/{id}/role [put]
+func (h *Handler) ChangeUserRole(c *fiber.Ctx) error {
+	var req ChangeUserRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, err)
+	}
+
+	actorID, _ := c.Locals("user_id").(string)
+
+	result, err := h.authService.ChangeUserRole(c.Context(), c.Params("id"), &req, actorID)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.Success(c, result)
+}
+
+// DeleteUser permanently deletes a user account
+// @Summary Delete user
+// @Description Permanently delete an account with its credentials, sessions and login history for a data protection request. The student or teacher record is kept (Full admins only)
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID" format(uuid)
+// @Success 200 {object} response.SuccessResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/users/{id} [delete]
+func (h *Handler) DeleteUser(c *fiber.Ctx) error {
+	actorID, _ := c.Locals("user_id").(string)
+
+	if err := h.authService.DeleteUser(c.Context(), c.Params("id"), actorID); err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.SuccessWithMessage(c, "user deleted successfully", nil)
+}
+
+// OpenID Connect Endpoints
+
+// GetOIDCConfig describes the external identity provider
+// @Summary Get identity provider
+// @Description Returns the identity provider for the login page. Not available if OpenID Connect login is disabled
+// @Tags oidc
+// @Produce json
+// @Success 200 {object} response.SuccessResponse{data=OIDCProviderInfo}
+// @Failure 404 {object} response.ErrorResponse
+// @Router /api/v1/auth/oidc/config [get]
+func (h *Handler) GetOIDCConfig(c *fiber.Ctx) error {
+	return response.Success(c, h.oidcService.ProviderInfo())
+}
+
+// BeginOIDCLogin starts a login at the external identity provider
+// @Summary Begin OpenID Connect login
+// @Description Start an authorization code flow with PKCE. Redirect the browser to the returned URL; the provider redirects back with code and state
+// @Tags oidc
+// @Produce json
+// @Success 200 {object} response.SuccessResponse{data=OIDCBeginResponse}
+// @Failure 503 {object} response.ErrorResponse
+// @Router /api/v1/auth/oidc/authorize [get]
+func (h *Handler) BeginOIDCLogin(c *fiber.Ctx) error {
+	result, err := h.oidcService.BeginLogin(c.Context())
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.Success(c, result)
+}
+
+// FinishOIDCLogin completes a login at the external identity provider
+// @Summary Finish OpenID Connect login
+// @Description Exchange the authorization code and return access and refresh tokens. Returns 403 while the account at the provider awaits linking by an administrator
+// @Tags oidc
+// @Accept json
+// @Produce json
+// @Param callback body OIDCCallbackRequest true "Code and state from the redirect"
+// @Success 200 {object} response.SuccessResponse{data=LoginResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 429 {object} response.ErrorResponse
+// @Failure 503 {object} response.ErrorResponse
+// @Router /api/v1/auth/oidc/callback [post]
+func (h *Handler) FinishOIDCLogin(c *fiber.Ctx) error {
+	var req OIDCCallbackRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, err)
+	}
+
+	result, err := h.oidcService.FinishLogin(c.Context(), &req, clientInfo(c))
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.Success(c, result)
+}
+
+// ListOIDCIdentities lists accounts at the identity provider
+// @Summary List OpenID Connect identities
+// @Description Paginated list of accounts at the identity provider, newest first. Pending identities await linking (Admin only)
+// @Tags oidc
+// @Produce json
+// @Param status query string false "Filter by status" Enums(pending, linked)
+// @Param user_id query string false "Filter by linked user" format(uuid)
+// @Param page query int false "Page number (default: 1)" minimum(1)
+// @Param limit query int false "Items per page (default: 20, max: 100)" minimum(1) maximum(100)
+// @Success 200 {object} pagination.Response{data=[]OIDCIdentity}
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/oidc/identities [get]
+func (h *Handler) ListOIDCIdentities(c *fiber.Ctx) error {
+	params := pagination.ExtractParams(c)
+	filter := OIDCIdentityFilter{
+		Status: c.Query("status"),
+		UserID: c.Query("user_id"),
+	}
+
+	identities, totalCount, err := h.oidcService.ListIdentities(c.Context(), filter, params)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return c.JSON(pagination.NewResponse(identities, params, totalCount))
+}
+
+// LinkOIDCIdentity links an account at the identity provider to a user
+// @Summary Link OpenID Connect identity
+// @Description Approve a pending identity or move a linked one to another user. The user can log in through the identity provider afterwards (Admin only)
+// @Tags oidc
+// @Accept json
+// @Produce json
+// @Param id path string true "Identity ID" format(uuid)
+// @Param link body LinkOIDCIdentityRequest true "User to link"
+// @Success 200 {object} response.SuccessResponse{data=OIDCIdentity}
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/oidc/identities/{id}/link [post]
+func (h *Handler) LinkOIDCIdentity(c *fiber.Ctx) error {
+	var req LinkOIDCIdentityRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, err)
+	}
+
+	actorID, _ := c.Locals("user_id").(string)
+
+	result, err := h.oidcService.LinkIdentity(c.Context(), c.Params("id"), &req, actorID)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.Success(c, result)
+}
+
+// DeleteOIDCIdentity removes an account at the identity provider
+// @Summary Delete OpenID Connect identity
+// @Description Reject a pending identity or unlink a user. The account is recorded as pending again at its next login (Admin only)
+// @Tags oidc
+// @Produce json
+// @Param id path string true "Identity ID" format(uuid)
+// @Success 200 {object} response.SuccessResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/oidc/identities/{id} [delete]
+func (h *Handler) DeleteOIDCIdentity(c *fiber.Ctx) error {
+	if err := h.oidcService.DeleteIdentity(c.Context(), c.Params("id")); err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.SuccessWithMessage(c, "identity deleted successfully", nil)
+}
+
+// API Key Endpoints
+
+// CreateAPIKey creates a service account API key
+// @Summary Create API key
+// @Description Create an API key for a service account. The key is only returned in this response (Admin only)
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param key body CreateAPIKeyRequest true "Name, scopes and lifetime"
+// @Success 201 {object} response.SuccessResponse{data=CreateAPIKeyResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/api-keys [post]
+func (h *Handler) CreateAPIKey(c *fiber.Ctx) error {
+	var req CreateAPIKeyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, err)
+	}
+
+	actorID, _ := c.Locals("user_id").(string)
+
+	result, err := h.apiKeyService.CreateAPIKey(c.Context(), &req, actorID)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.Created(c, result)
+}
+
+// ListAPIKeys lists service account API keys
+// @Summary List API keys
+// @Description Paginated list of API keys including revoked and expired ones, newest first (Admin only)
+// @Tags api-keys
+// @Produce json
+// @Param page query int false "Page number (default: 1)" minimum(1)
+// @Param limit query int false "Items per page (default: 20, max: 100)" minimum(1) maximum(100)
+// @Success 200 {object} pagination.Response{data=[]APIKey}
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/api-keys [get]
+func (h *Handler) ListAPIKeys(c *fiber.Ctx) error {
+	params := pagination.ExtractParams(c)
+
+	keys, totalCount, err := h.apiKeyService.ListAPIKeys(c.Context(), params)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return c.JSON(pagination.NewResponse(keys, params, totalCount))
+}
+
+// RevokeAPIKey revokes a service account API key
+// @Summary Revoke API key
+// @Description Reject the key immediately. The key is kept for the audit trail (Admin only)
+// @Tags api-keys
+// @Produce json
+// @Param id path string true "API key ID" format(uuid)
+// @Success 200 {object} response.SuccessResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/api-keys/{id}/revoke [post]
+func (h *Handler) RevokeAPIKey(c *fiber.Ctx) error {
+	actorID, _ := c.Locals("user_id").(string)
+
+	if err := h.apiKeyService.RevokeAPIKey(c.Context(), c.Params("id"), actorID); err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.SuccessWithMessage(c, "api key revoked successfully", nil)
+}
+
+// Invitation Endpoints
+
+// CreateInvitation creates a new user invitation
+// @Summary Create invitation
+// @Description Create invitation link for a student, teacher or admin. The link is emailed to the invitee and also returned. Department admins invite to their department, only full admins invite admins (Admin only)
+// @Tags invitations
+// @Accept json
+// @Produce json
+// @Param invitation body CreateInvitationRequest true "Invitation data"
+// @Success 201 {object} response.SuccessResponse{data=CreateInvitationResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/invite [post]
+func (h *Handler) CreateInvitation(c *fiber.Ctx) error {
+	var req CreateInvitationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, err)
+	}
+
+	actorID, _ := c.Locals("user_id").(string)
+
+	result, err := h.invitationService.CreateInvitation(c.Context(), &req, actorID)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.Created(c, result)
+}
+
+// ListInvitations lists invitations with their status
+// @Summary List invitations
+// @Description Paginated list of invitations, newest first, optionally filtered by status or email. Department admins see the invitations of their department (Admin only)
+// @Tags invitations
+// @Produce json
+// @Param status query string false "Filter by status" Enums(pending, used, expired, revoked)
+// @Param email query string false "Filter by email (case-insensitive)"
+// @Param page query int false "Page number (default: 1)" minimum(1)
+// @Param limit query int false "Items per page (default: 20, max: 100)" minimum(1) maximum(100)
+// @Success 200 {object} pagination.Response{data=[]InvitationSummary}
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/invitations [get]
+func (h *Handler) ListInvitations(c *fiber.Ctx) error {
+	params := pagination.ExtractParams(c)
+	filter := InvitationFilter{
+		Status: c.Query("status"),
+		Email:  c.Query("email"),
+	}
+
+	actorID, _ := c.Locals("user_id").(string)
+
+	invitations, totalCount, err := h.invitationService.ListInvitations(c.Context(), filter, params, actorID)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return c.JSON(pagination.NewResponse(invitations, params, totalCount))
+}
+
+// GetInvitation returns a single invitation
+// @Summary Get invitation
+// @Description Get an invitation with status and audit trail. Department admins only get the invitations of their department (Admin only)
+// @Tags invitations
+// @Produce json
+// @Param id path string true "Invitation ID" format(uuid)
+// @Success 200 {object} response.SuccessResponse{data=InvitationSummary}
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/invitations/{id} [get]
+func (h *Handler) GetInvitation(c *fiber.Ctx) error {
+	actorID, _ := c.Locals("user_id").(string)
+
+	result, err := h.invitationService.GetInvitation(c.Context(), c.Params("id"), actorID)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.Success(c, result)
+}
+
+// BulkCreateInvitations creates invitations from an uploaded roster
+// @Summary Bulk create invitations
+// @Description Upload a CSV or XLSX roster with the columns first_name, last_name, email, role, department (required for teachers, checked against the teacher for students) and teacher_email (students).
+// @Description Every row is validated; invitations are only created if all rows are valid, in a single transaction.
+// @Description With format=csv the invitation links are returned as a downloadable file once created (Admin only)
+// @Tags invitations
+// @Accept multipart/form-data
+// @Produce json
+// @Produce text/csv
+// @Param file formData file true "Roster file (.csv or .xlsx)"
+// @Param dry_run query bool false "Only validate the roster, create nothing"
+// @Param format query string false "Response format for created invitations" Enums(json, csv)
+// @Success 200 {object} response.SuccessResponse{data=BulkInvitationReport} "Dry run or invalid rows, nothing created"
+// @Success 201 {object} response.SuccessResponse{data=BulkInvitationReport}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/invitations/bulk [post]
+func (h *Handler) BulkCreateInvitations(c *fiber.Ctx) error {
+	file, err := c.FormFile("file")
+	if err != nil {
+		return response.Error(c, errors.BadRequest("roster file is required"))
+	}
+
+	// Read file data
+	fileData, err := file.Open()
+	if err != nil {
+		return response.Error(c, err)
+	}
+	defer fileData.Close()
+
+	data, err := io.ReadAll(fileData)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	rows, err := ParseRoster(file.Filename, data)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	actorID, _ := c.Locals("user_id").(string)
+	report, err := h.invitationService.BulkCreateInvitations(c.Context(), rows, actorID, c.QueryBool("dry_run"))
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	if report.Created == 0 {
+		return response.Success(c, report)
+	}
+
+	if c.Query("format") == "csv" {
+		var buf bytes.Buffer
+		if err := WriteInvitationLinksCSV(&buf, report); err != nil {
+			return response.Error(c, err)
+		}
+		c.Set("Content-Type", "text/csv; charset=utf-8")
+		c.Set("Content-Disposition", "attachment; filename=invitation_links.csv")
+		c.Status(fiber.StatusCreated)
+		return c.Send(buf.Bytes())
+	}
+
+	return response.Created(c, report)
+}
+
+// RevokeInvitation revokes an unused invitation
+// @Summary Revoke invitation
+// @Description Invalidate an unused invitation immediately. The invitation is kept for the audit trail (Admin only)
+// @Tags invitations
+// @Produce json
+// @Param id path string true "Invitation ID" format(uuid)
+// @Success 200 {object} response.SuccessResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/invitations/{id}/revoke [post]
+func (h *Handler) RevokeInvitation(c *fiber.Ctx) error {
+	actorID, _ := c.Locals("user_id").(string)
+
+	if err := h.invitationService.RevokeInvitation(c.Context(), c.Params("id"), actorID); err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.SuccessWithMessage(c, "invitation revoked successfully", nil)
+}
+
+// ResendInvitation issues a new link for an invitation
+// @Summary Resend invitation
+// @Description Issue a new token and expiry for a pending or expired invitation and email the new link. The previous link stops working (Admin only)
+// @Tags invitations
+// @Produce json
+// @Param id path string true "Invitation ID" format(uuid)
+// @Success 200 {object} response.SuccessResponse{data=CreateInvitationResponse}
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/invitations/{id}/resend [post]
+func (h *Handler) ResendInvitation(c *fiber.Ctx) error {
+	actorID, _ := c.Locals("user_id").(string)
+
+	result, err := h.invitationService.ResendInvitation(c.Context(), c.Params("id"), actorID)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.Success(c, result)
+}
+
+// PurgeExpiredInvitations deletes expired invitations
+// @Summary Purge expired invitations
+// @Description Delete unused invitations that expired more than older_than ago (default: all expired). Used invitations are kept (Full admins only)
+// @Tags invitations
+// @Produce json
+// @Param older_than query string false "Minimum time since expiry, e.g. 720h"
+// @Success 200 {object} response.SuccessResponse{data=PurgeInvitationsResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/invitations/expired [delete]
+func (h *Handler) PurgeExpiredInvitations(c *fiber.Ctx) error {
+	var olderThan time.Duration
+	if value := c.Query("older_than"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return response.Error(c, errors.BadRequest("older_than must be a positive duration, e.g. 720h"))
+		}
+		olderThan = d
+	}
+
+	result, err := h.invitationService.PurgeExpiredInvitations(c.Context(), olderThan)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.Success(c, result)
+}
+
+// GetInvitationDetails retrieves invitation details
+// @Summary Get invitation details
+// @Description Get invitation information by token
+// @Tags invitations
+// @Produce json
+// @Param token path string true "Invitation token"
+// @Success 200 {object} response.SuccessResponse{data=InvitationResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /api/v1/invite/{token} [get]
+func (h *Handler) GetInvitationDetails(c *fiber.Ctx) error {
+	token := c.Params("token")
+
+	result, err := h.invitationService.GetInvitationDetails(c.Context(), token)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.Success(c, result)
+}
+
+// CompleteInvitation completes user registration
+// @Summary Complete invitation
+// @Description Complete user registration with username and password
+// @Tags invitations
+// @Accept json
+// @Produce json
+// @Param token path string true "Invitation token"
+// @Param credentials body CompleteInvitationRequest true "User credentials"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Router /api/v1/invite/{token}/complete [post]
+func (h *Handler) CompleteInvitation(c *fiber.Ctx) error {
+	token := c.Params("token")
+
+	var req CompleteInvitationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, err)
+	}
+
+	if err := h.invitationService.CompleteInvitation(c.Context(), token, &req); err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.SuccessWithMessage(c, "registration completed successfully", nil)
+}
+
+// clientInfo describes the device of the current request for session tracking
+func clientInfo(c *fiber.Ctx) ClientInfo {
+	return ClientInfo{
+		IPAddress: c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+	}
 }
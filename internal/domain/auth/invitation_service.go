@@ -3,14 +3,20 @@ package auth
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/JustDoItBetter/FITS-backend/internal/common/errors"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/pagination"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/reference"
 	"github.com/JustDoItBetter/FITS-backend/internal/common/validation"
 	"github.com/JustDoItBetter/FITS-backend/internal/config"
 	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
+	"github.com/JustDoItBetter/FITS-backend/pkg/logger"
+	"go.uber.org/zap"
 )
 
 // InvitationService handles user invitation operations
@@ -18,78 +24,319 @@ type InvitationService struct {
 	repo       Repository
 	jwtService *crypto.JWTService
 	jwtConfig  *config.JWTConfig
+	notifier   *Notifier
+	rules      reference.Rules
 }
 
 // NewInvitationService creates a new invitation service
-func NewInvitationService(repo Repository, jwtService *crypto.JWTService, jwtConfig *config.JWTConfig) *InvitationService {
+// rules are checked whenever a student invitation references a teacher
+func NewInvitationService(repo Repository, jwtService *crypto.JWTService, jwtConfig *config.JWTConfig, notifier *Notifier, rules reference.Rules) *InvitationService {
 	return &InvitationService{
 		repo:       repo,
 		jwtService: jwtService,
 		jwtConfig:  jwtConfig,
+		notifier:   notifier,
+		rules:      rules,
 	}
 }
 
 // CreateInvitation creates a new invitation for a user
-func (s *InvitationService) CreateInvitation(ctx context.Context, req *CreateInvitationRequest) (*CreateInvitationResponse, error) {
+// actorID is the admin creating the invitation, recorded for the audit trail
+func (s *InvitationService) CreateInvitation(ctx context.Context, req *CreateInvitationRequest, actorID string) (*CreateInvitationResponse, error) {
+	// Normalize the email so the open invitation check and the unique index see the same address
+	email := validation.SanitizeEmail(req.Email)
+	if email == "" {
+		return nil, errors.ValidationError("email is invalid")
+	}
+
 	// Validate role
 	var role crypto.Role
+	var admin crypto.AdminClaims
+	var department string // Department the invitation belongs to, checked against department admins
 	if req.Role == "student" {
 		role = crypto.RoleStudent
 		// Validate that student has teacher_uuid
 		if req.TeacherUUID == nil || *req.TeacherUUID == "" {
 			return nil, errors.ValidationError("teacher_uuid is required for students")
 		}
-		// TODO: Validate that teacher exists in database
+		teacher, err := s.repo.GetActiveTeacher(ctx, *req.TeacherUUID)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.rules.CheckTeacher(teacher, req.Department); err != nil {
+			return nil, err
+		}
+		department = teacher.Department
 	} else if req.Role == "teacher" {
 		role = crypto.RoleTeacher
 		// Validate that teacher has department
 		if req.Department == nil || *req.Department == "" {
 			return nil, errors.ValidationError("department is required for teachers")
 		}
+		department = *req.Department
+	} else if req.Role == "admin" {
+		role = crypto.RoleAdmin
+		if req.TeacherUUID != nil && *req.TeacherUUID != "" {
+			return nil, errors.ValidationError("teacher_uuid must be empty for admins")
+		}
+		var err error
+		if admin, err = newAdminClaims(role, req.AdminScope, stringValue(req.Department)); err != nil {
+			return nil, err
+		}
 	} else {
-		return nil, errors.ValidationError("role must be 'student' or 'teacher'")
+		return nil, errors.ValidationError("role must be 'student', 'teacher' or 'admin'")
+	}
+	if role != crypto.RoleAdmin && req.AdminScope != "" {
+		return nil, errors.ValidationError("admin_scope is only allowed for admins")
 	}
 
-	// Check if email already used in another invitation
-	// (Optional: prevent duplicate invitations)
+	inviter, err := s.getInviter(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if err := inviter.check(role, department); err != nil {
+		return nil, err
+	}
 
-	// Generate invitation token
-	invitationToken, err := s.jwtService.GenerateToken(
-		req.Email, // Use email as subject
-		role,
-		crypto.TokenTypeInvitation,
-		s.jwtConfig.GetInvitationExpiry(),
-	)
+	// Only one open invitation per email, revoke or resend the existing one instead
+	hasOpen, err := s.repo.HasOpenInvitation(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if hasOpen {
+		return nil, errors.Conflict("an open invitation for this email already exists")
+	}
+
+	invitationToken, err := s.generateToken(email, role)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate invitation token: %w", err)
+		return nil, err
 	}
 
 	// Create invitation record with user data
 	invitation := &Invitation{
 		Token:       invitationToken,
-		Email:       req.Email,
+		Email:       email,
 		FirstName:   req.FirstName,
 		LastName:    req.LastName,
 		Role:        role,
 		Department:  req.Department,
 		TeacherUUID: req.TeacherUUID,
+		AdminScope:  admin.AdminScope,
 		Used:        false,
 		ExpiresAt:   time.Now().Add(s.jwtConfig.GetInvitationExpiry()),
 	}
+	if role == crypto.RoleAdmin {
+		invitation.Department = nil
+		if admin.Department != "" {
+			invitation.Department = &admin.Department
+		}
+	}
+	if actorID != "" {
+		invitation.CreatedBy = &actorID
+	}
 
-	if err := s.repo.CreateInvitation(ctx, invitation); err != nil {
-		return nil, fmt.Errorf("failed to create invitation: %w", err)
+	// The invitation email is queued in the same transaction, so it is only sent for committed invitations
+	var emailQueued bool
+	err = s.repo.ExecuteInTransaction(ctx, func(txRepo Repository) error {
+		if err := txRepo.CreateInvitation(ctx, invitation); err != nil {
+			return err
+		}
+		emailQueued, err = s.notifier.queueInvitation(ctx, txRepo, invitation, req.Locale)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Generate invitation link
-	// TODO: Get base URL from config
-	invitationLink := fmt.Sprintf("https://fits.example.com/invite/%s", invitationToken)
+	return s.newCreateInvitationResponse(invitation, emailQueued), nil
+}
+
+// generateToken creates a signed invitation token with the email as subject
+func (s *InvitationService) generateToken(email string, role crypto.Role) (string, error) {
+	token, err := s.jwtService.GenerateToken(
+		email, // Use email as subject
+		role,
+		crypto.TokenTypeInvitation,
+		s.jwtConfig.GetInvitationExpiry(),
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate invitation token: %w", err)
+	}
+	return token, nil
+}
 
+// newCreateInvitationResponse builds the response containing the invitation link
+func (s *InvitationService) newCreateInvitationResponse(invitation *Invitation, emailQueued bool) *CreateInvitationResponse {
 	return &CreateInvitationResponse{
-		InvitationToken: invitationToken,
-		InvitationLink:  invitationLink,
+		InvitationToken: invitation.Token,
+		InvitationLink:  s.notifier.invitationLink(invitation.Token),
 		ExpiresAt:       invitation.ExpiresAt.Format(time.RFC3339),
-	}, nil
+		EmailQueued:     emailQueued,
+	}
+}
+
+// ListInvitations returns invitations matching the filter for the admin overview
+// Department admins only see the invitations of their department
+func (s *InvitationService) ListInvitations(ctx context.Context, filter InvitationFilter, params pagination.Params, actorID string) ([]InvitationSummary, int64, error) {
+	switch filter.Status {
+	case "", InvitationStatusPending, InvitationStatusUsed, InvitationStatusExpired, InvitationStatusRevoked:
+	default:
+		return nil, 0, errors.ValidationError("status must be one of pending, used, expired, revoked")
+	}
+
+	inviter, err := s.getInviter(ctx, actorID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if inviter.admin.AdminScope == crypto.AdminScopeDepartment {
+		// A department admin without department sees no invitations
+		filter.Department = strings.TrimSpace(inviter.admin.Department)
+		if filter.Department == "" {
+			return []InvitationSummary{}, 0, nil
+		}
+	}
+
+	invitations, totalCount, err := s.repo.ListInvitations(ctx, filter, params)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := make([]InvitationSummary, len(invitations))
+	for i := range invitations {
+		result[i] = newInvitationSummary(&invitations[i])
+	}
+	return result, totalCount, nil
+}
+
+// GetInvitation returns a single invitation for the admin overview
+func (s *InvitationService) GetInvitation(ctx context.Context, id, actorID string) (*InvitationSummary, error) {
+	invitation, err := s.repo.GetInvitationByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkInviter(ctx, invitation, actorID); err != nil {
+		return nil, err
+	}
+
+	summary := newInvitationSummary(invitation)
+	return &summary, nil
+}
+
+// RevokeInvitation invalidates an unused invitation immediately
+func (s *InvitationService) RevokeInvitation(ctx context.Context, id, actorID string) error {
+	invitation, err := s.repo.GetInvitationByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.checkInviter(ctx, invitation, actorID); err != nil {
+		return err
+	}
+
+	switch invitation.Status() {
+	case InvitationStatusUsed:
+		return errors.Conflict("invitation already used")
+	case InvitationStatusRevoked:
+		return errors.Conflict("invitation already revoked")
+	}
+
+	if err := s.repo.RevokeInvitation(ctx, invitation.ID, actorID); err != nil {
+		return err
+	}
+
+	logger.Info("Invitation revoked",
+		zap.String("invitation_id", invitation.ID),
+		zap.String("revoked_by", actorID),
+	)
+	return nil
+}
+
+// ResendInvitation issues a new token and expiry for a pending or expired invitation and emails the new link
+// The previous link stops working
+func (s *InvitationService) ResendInvitation(ctx context.Context, id, actorID string) (*CreateInvitationResponse, error) {
+	invitation, err := s.repo.GetInvitationByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch invitation.Status() {
+	case InvitationStatusUsed:
+		return nil, errors.Conflict("invitation already used")
+	case InvitationStatusRevoked:
+		return nil, errors.Conflict("invitation was revoked, create a new one instead")
+	}
+
+	// Admin invitations grant admin rights, so only full admins may extend them,
+	// department admins only extend the invitations of their department
+	if err := s.checkInviter(ctx, invitation, actorID); err != nil {
+		return nil, err
+	}
+
+	// Don't send out an invitation that can no longer be completed
+	if invitation.Role == crypto.RoleStudent && invitation.TeacherUUID != nil {
+		if err := s.rules.ValidateTeacher(ctx, s.repo, *invitation.TeacherUUID, invitation.Department); err != nil {
+			return nil, err
+		}
+	}
+
+	token, err := s.generateToken(invitation.Email, invitation.Role)
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().Add(s.jwtConfig.GetInvitationExpiry())
+
+	invitation.Token = token
+	invitation.ExpiresAt = expiresAt
+
+	var emailQueued bool
+	err = s.repo.ExecuteInTransaction(ctx, func(txRepo Repository) error {
+		if err := txRepo.ReissueInvitation(ctx, invitation.ID, token, expiresAt); err != nil {
+			return err
+		}
+		emailQueued, err = s.notifier.queueInvitation(ctx, txRepo, invitation, "")
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Invitation resent",
+		zap.String("invitation_id", invitation.ID),
+		zap.String("resent_by", actorID),
+	)
+	return s.newCreateInvitationResponse(invitation, emailQueued), nil
+}
+
+// PurgeExpiredInvitations deletes unused invitations that expired more than olderThan ago
+func (s *InvitationService) PurgeExpiredInvitations(ctx context.Context, olderThan time.Duration) (*PurgeInvitationsResponse, error) {
+	deleted, err := s.repo.DeleteExpiredInvitations(ctx, time.Now().Add(-olderThan))
+	if err != nil {
+		return nil, err
+	}
+
+	return &PurgeInvitationsResponse{Deleted: deleted}, nil
+}
+
+// newInvitationSummary converts an invitation to its admin listing representation
+func newInvitationSummary(invitation *Invitation) InvitationSummary {
+	return InvitationSummary{
+		ID:          invitation.ID,
+		Email:       invitation.Email,
+		FirstName:   invitation.FirstName,
+		LastName:    invitation.LastName,
+		Role:        string(invitation.Role),
+		Department:  invitation.Department,
+		TeacherUUID: invitation.TeacherUUID,
+		AdminScope:  string(invitation.AdminScope),
+		Status:      invitation.Status(),
+		CreatedBy:   invitation.CreatedBy,
+		CreatedAt:   invitation.CreatedAt,
+		ExpiresAt:   invitation.ExpiresAt,
+		UsedAt:      invitation.UsedAt,
+		RevokedAt:   invitation.RevokedAt,
+		RevokedBy:   invitation.RevokedBy,
+		ResentAt:    invitation.ResentAt,
+		ResendCount: invitation.ResendCount,
+	}
 }
 
 // GetInvitationDetails retrieves invitation details by token
@@ -107,11 +354,8 @@ func (s *InvitationService) GetInvitationDetails(ctx context.Context, token stri
 	}
 
 	// Check if invitation is valid
-	if !invitation.IsValid() {
-		if invitation.Used {
-			return nil, errors.BadRequest("invitation already used")
-		}
-		return nil, errors.BadRequest("invitation expired")
+	if err := checkInvitationUsable(invitation); err != nil {
+		return nil, err
 	}
 
 	// Return invitation data
@@ -122,6 +366,7 @@ func (s *InvitationService) GetInvitationDetails(ctx context.Context, token stri
 		Role:        string(invitation.Role),
 		Department:  invitation.Department,
 		TeacherUUID: invitation.TeacherUUID,
+		AdminScope:  string(invitation.AdminScope),
 		ExpiresAt:   invitation.ExpiresAt.Format(time.RFC3339),
 	}, nil
 }
@@ -136,11 +381,8 @@ func (s *InvitationService) CompleteInvitation(ctx context.Context, token string
 	}
 
 	// Validate invitation
-	if !invitation.IsValid() {
-		if invitation.Used {
-			return errors.BadRequest("invitation already used")
-		}
-		return errors.BadRequest("invitation expired")
+	if err := checkInvitationUsable(invitation); err != nil {
+		return err
 	}
 
 	// Check if username already exists
@@ -149,16 +391,11 @@ func (s *InvitationService) CompleteInvitation(ctx context.Context, token string
 		return errors.Conflict("username already exists")
 	}
 
-	// Validate password strength before hashing
-	if err := validation.ValidatePasswordStrength(req.Password); err != nil {
+	// Validate password strength and check against common passwords before hashing
+	if err := validateNewPassword(req.Password); err != nil {
 		return err
 	}
 
-	// Check against common passwords
-	if validation.IsCommonPassword(req.Password) {
-		return errors.ValidationError("password is too common and easily guessable, please choose a stronger password")
-	}
-
 	// Hash password
 	passwordHash, err := crypto.HashPassword(req.Password)
 	if err != nil {
@@ -173,6 +410,20 @@ func (s *InvitationService) CompleteInvitation(ctx context.Context, token string
 	return s.repo.ExecuteInTransaction(ctx, func(txRepo Repository) error {
 		// Create Student or Teacher record
 		if invitation.Role == crypto.RoleStudent {
+			// The teacher may have been deleted or moved since the invitation was created
+			if invitation.TeacherUUID != nil {
+				teacher, err := txRepo.GetActiveTeacher(ctx, *invitation.TeacherUUID)
+				if err != nil {
+					return err
+				}
+				if err := s.rules.CheckTeacher(teacher, invitation.Department); err != nil {
+					logger.Warn("Invitation references an invalid teacher",
+						zap.String("invitation_id", invitation.ID),
+						zap.Error(err),
+					)
+					return errors.Conflict("the teacher assigned in this invitation is no longer available, please ask an administrator for a new invitation")
+				}
+			}
 			student := &StudentRecord{
 				ID:        entityUUID,
 				FirstName: invitation.FirstName,
@@ -199,21 +450,26 @@ func (s *InvitationService) CompleteInvitation(ctx context.Context, token string
 			}
 		}
 
-		// Create user with reference to Student/Teacher UUID
+		// Create user with reference to Student/Teacher UUID, admins have no record but a scope
 		user := &User{
 			Username:     req.Username,
 			PasswordHash: passwordHash,
 			Role:         invitation.Role,
 			UserUUID:     &entityUUID,
 		}
+		if invitation.Role == crypto.RoleAdmin {
+			user.UserUUID = nil
+			user.AdminScope = invitation.AdminScope
+			user.AdminDepartment = stringValue(invitation.Department)
+		}
 
 		if err := txRepo.CreateUser(ctx, user); err != nil {
 			return fmt.Errorf("failed to create user: %w", err)
 		}
 
-		// Mark invitation as used
+		// Mark invitation as used (fails if it was used or revoked concurrently)
 		if err := txRepo.MarkInvitationAsUsed(ctx, token); err != nil {
-			return fmt.Errorf("failed to mark invitation as used: %w", err)
+			return err
 		}
 
 		// TODO: If teacher, generate RSA keypair (implement in keypair domain first)
@@ -223,3 +479,84 @@ func (s *InvitationService) CompleteInvitation(ctx context.Context, token string
 		return nil
 	})
 }
+
+// checkInvitationUsable explains why an invitation cannot be used anymore
+func checkInvitationUsable(invitation *Invitation) error {
+	switch invitation.Status() {
+	case InvitationStatusUsed:
+		return errors.BadRequest("invitation already used")
+	case InvitationStatusRevoked:
+		return errors.BadRequest("invitation revoked")
+	case InvitationStatusExpired:
+		return errors.BadRequest("invitation expired")
+	}
+	return nil
+}
+
+// inviter is who creates an invitation, it limits whom they may invite
+type inviter struct {
+	service bool               // Service account (API key) without user account
+	admin   crypto.AdminClaims // Scope of the inviting admin
+}
+
+// getInviter looks up the scope of the admin creating an invitation
+// An empty actorID is a service account authenticated with an API key
+func (s *InvitationService) getInviter(ctx context.Context, actorID string) (inviter, error) {
+	if actorID == "" {
+		return inviter{service: true}, nil
+	}
+
+	actor, err := s.repo.GetUserByID(ctx, actorID)
+	if err != nil {
+		return inviter{}, err
+	}
+	return inviter{admin: actor.AdminClaims()}, nil
+}
+
+// checkInviter checks that the actor may manage an existing invitation like creating it
+// The department of a student invitation is the department of its teacher
+func (s *InvitationService) checkInviter(ctx context.Context, invitation *Invitation, actorID string) error {
+	inviter, err := s.getInviter(ctx, actorID)
+	if err != nil {
+		return err
+	}
+
+	department := stringValue(invitation.Department)
+	if invitation.Role == crypto.RoleStudent && inviter.admin.AdminScope == crypto.AdminScopeDepartment {
+		// Only active teachers count, the invitation of a deleted teacher's student belongs to no department
+		department = ""
+		if invitation.TeacherUUID != nil {
+			teacher, err := s.repo.GetActiveTeacher(ctx, *invitation.TeacherUUID)
+			if err != nil {
+				return err
+			}
+			if teacher != nil {
+				department = teacher.Department
+			}
+		}
+	}
+	return inviter.check(invitation.Role, department)
+}
+
+// check reports whether the inviter may invite an account of the role to the department
+// Only full admins invite admins, department admins only invite teachers and students of their department
+func (i inviter) check(role crypto.Role, department string) error {
+	if role == crypto.RoleAdmin {
+		if i.admin.AdminScope != crypto.AdminScopeFull {
+			return errors.NewAppError(http.StatusForbidden, "Forbidden", "only full admins can invite admins")
+		}
+		return nil
+	}
+
+	switch {
+	case i.service, i.admin.AdminScope == crypto.AdminScopeFull:
+		return nil
+	case i.admin.AdminScope == crypto.AdminScopeDepartment:
+		if !strings.EqualFold(strings.TrimSpace(department), strings.TrimSpace(i.admin.Department)) {
+			return errors.NewAppError(http.StatusForbidden, "Forbidden",
+				fmt.Sprintf("department admins can only invite to their department '%s'", i.admin.Department))
+		}
+		return nil
+	}
+	return errors.NewAppError(http.StatusForbidden, "Forbidden", "your admin scope doesn't allow creating invitations")
+}
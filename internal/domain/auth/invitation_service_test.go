@@ -0,0 +1,538 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/errors"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/pagination"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/reference"
+	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestInvitationService(repo Repository) (*InvitationService, *crypto.JWTService) {
+	jwtService := crypto.NewJWTService("test-secret")
+	return NewInvitationService(repo, jwtService, getTestJWTConfig(), getTestNotifier(), reference.Rules{}), jwtService
+}
+
+// mockAdmin makes actorID an admin account with the given scope
+func mockAdmin(mockRepo *MockRepository, ctx context.Context, actorID string, admin crypto.AdminClaims) {
+	mockRepo.On("GetUserByID", ctx, actorID).Return(&User{
+		ID:              actorID,
+		Role:            crypto.RoleAdmin,
+		AdminScope:      admin.AdminScope,
+		AdminDepartment: admin.Department,
+	}, nil)
+}
+
+func TestInvitation_Status(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name       string
+		invitation Invitation
+		want       string
+	}{
+		{"pending", Invitation{ExpiresAt: now.Add(time.Hour)}, InvitationStatusPending},
+		{"expired", Invitation{ExpiresAt: now.Add(-time.Hour)}, InvitationStatusExpired},
+		{"revoked", Invitation{ExpiresAt: now.Add(time.Hour), RevokedAt: &now}, InvitationStatusRevoked},
+		{"revoked and expired", Invitation{ExpiresAt: now.Add(-time.Hour), RevokedAt: &now}, InvitationStatusRevoked},
+		{"used", Invitation{Used: true, ExpiresAt: now.Add(-time.Hour)}, InvitationStatusUsed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.invitation.Status())
+			assert.Equal(t, tt.want == InvitationStatusPending, tt.invitation.IsValid())
+		})
+	}
+}
+
+func TestInvitationService_CreateInvitation(t *testing.T) {
+	ctx := context.Background()
+	req := &CreateInvitationRequest{
+		Email:      "max@example.com",
+		FirstName:  "Max",
+		LastName:   "Mustermann",
+		Role:       "teacher",
+		Department: stringPtr("IT"),
+	}
+
+	t.Run("records the creating admin", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service, _ := newTestInvitationService(mockRepo)
+		mockAdmin(mockRepo, ctx, "admin-1", crypto.AdminClaims{AdminScope: crypto.AdminScopeFull})
+		mockRepo.On("HasOpenInvitation", ctx, req.Email).Return(false, nil)
+		mockRepo.On("CreateInvitation", ctx, mock.AnythingOfType("*auth.Invitation")).Return(nil)
+
+		resp, err := service.CreateInvitation(ctx, req, "admin-1")
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, resp.InvitationToken)
+		created := mockRepo.Calls[2].Arguments.Get(1).(*Invitation)
+		require.NotNil(t, created.CreatedBy)
+		assert.Equal(t, "admin-1", *created.CreatedBy)
+	})
+
+	t.Run("rejects a second open invitation for the same email", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service, _ := newTestInvitationService(mockRepo)
+		mockAdmin(mockRepo, ctx, "admin-1", crypto.AdminClaims{AdminScope: crypto.AdminScopeFull})
+		mockRepo.On("HasOpenInvitation", ctx, req.Email).Return(true, nil)
+
+		resp, err := service.CreateInvitation(ctx, req, "admin-1")
+
+		assert.Nil(t, resp)
+		appErr, ok := err.(*errors.AppError)
+		require.True(t, ok)
+		assert.Equal(t, 409, appErr.Code)
+		mockRepo.AssertNotCalled(t, "CreateInvitation", mock.Anything, mock.Anything)
+	})
+
+	t.Run("normalizes the email before checking for open invitations", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service, _ := newTestInvitationService(mockRepo)
+		mockAdmin(mockRepo, ctx, "admin-1", crypto.AdminClaims{AdminScope: crypto.AdminScopeFull})
+		mockRepo.On("HasOpenInvitation", ctx, "max@example.com").Return(false, nil)
+		mockRepo.On("CreateInvitation", ctx, mock.AnythingOfType("*auth.Invitation")).Return(nil)
+
+		mixedCase := *req
+		mixedCase.Email = " Max@Example.COM "
+		_, err := service.CreateInvitation(ctx, &mixedCase, "admin-1")
+
+		require.NoError(t, err)
+		created := mockRepo.Calls[2].Arguments.Get(1).(*Invitation)
+		assert.Equal(t, "max@example.com", created.Email)
+	})
+
+	t.Run("rejects an invitation created concurrently for the same email", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service, _ := newTestInvitationService(mockRepo)
+		mockAdmin(mockRepo, ctx, "admin-1", crypto.AdminClaims{AdminScope: crypto.AdminScopeFull})
+		mockRepo.On("HasOpenInvitation", ctx, req.Email).Return(false, nil)
+		mockRepo.On("CreateInvitation", ctx, mock.AnythingOfType("*auth.Invitation")).
+			Return(errors.Conflict("an open invitation for max@example.com already exists"))
+
+		resp, err := service.CreateInvitation(ctx, req, "admin-1")
+
+		assert.Nil(t, resp)
+		appErr, ok := err.(*errors.AppError)
+		require.True(t, ok)
+		assert.Equal(t, 409, appErr.Code)
+	})
+
+	t.Run("rejects unknown or deleted teacher for students", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service, _ := newTestInvitationService(mockRepo)
+		mockRepo.On("GetActiveTeacher", ctx, "teacher-1").Return(nil, nil)
+
+		resp, err := service.CreateInvitation(ctx, &CreateInvitationRequest{
+			Email:       "erika@example.com",
+			FirstName:   "Erika",
+			LastName:    "Muster",
+			Role:        "student",
+			TeacherUUID: stringPtr("teacher-1"),
+		}, "admin-1")
+
+		assert.Nil(t, resp)
+		appErr, ok := err.(*errors.AppError)
+		require.True(t, ok)
+		assert.Equal(t, 422, appErr.Code)
+		mockRepo.AssertNotCalled(t, "CreateInvitation", mock.Anything, mock.Anything)
+	})
+}
+
+func TestGormRepository_CreateInvitation(t *testing.T) {
+	ctx := context.Background()
+	db := setupLifecycleTestDB(t)
+	repo := NewGormRepository(db)
+	for _, stmt := range []string{
+		`CREATE TABLE invitations (id TEXT PRIMARY KEY, token TEXT NOT NULL, email TEXT NOT NULL, first_name TEXT NOT NULL,
+			last_name TEXT NOT NULL, role TEXT NOT NULL, department TEXT, teacher_uuid TEXT, admin_scope TEXT NOT NULL DEFAULT '',
+			used BOOLEAN DEFAULT false, created_at DATETIME, expires_at DATETIME NOT NULL, created_by TEXT, used_at DATETIME,
+			revoked_at DATETIME, revoked_by TEXT, resent_at DATETIME, resend_count INTEGER NOT NULL DEFAULT 0)`,
+		`CREATE UNIQUE INDEX idx_invitations_open_email ON invitations(LOWER(email)) WHERE used = false AND revoked_at IS NULL`,
+	} {
+		require.NoError(t, db.Exec(stmt).Error)
+	}
+
+	newInvitation := func(id, email string, expiresAt time.Time) *Invitation {
+		return &Invitation{ID: id, Token: "token-" + id, Email: email, FirstName: "Max", LastName: "Mustermann",
+			Role: crypto.RoleTeacher, CreatedAt: time.Now(), ExpiresAt: expiresAt}
+	}
+
+	require.NoError(t, repo.CreateInvitation(ctx, newInvitation("expired", "max@example.com", time.Now().Add(-time.Hour))))
+	require.NoError(t, repo.CreateInvitation(ctx, newInvitation("open", "max@example.com", time.Now().Add(time.Hour))),
+		"an expired invitation does not block a new one")
+
+	err := repo.CreateInvitation(ctx, newInvitation("duplicate", "MAX@example.com", time.Now().Add(time.Hour)))
+	appErr, ok := err.(*errors.AppError)
+	require.True(t, ok, "unexpected error: %v", err)
+	assert.Equal(t, 409, appErr.Code)
+
+	var ids []string
+	require.NoError(t, db.Model(&Invitation{}).Pluck("id", &ids).Error)
+	assert.Equal(t, []string{"open"}, ids)
+}
+
+func TestInvitationService_CreateInvitation_AdminScopes(t *testing.T) {
+	ctx := context.Background()
+	full := crypto.AdminClaims{AdminScope: crypto.AdminScopeFull}
+	itAdmin := crypto.AdminClaims{AdminScope: crypto.AdminScopeDepartment, Department: "IT"}
+	adminReq := &CreateInvitationRequest{
+		Email:      "sekretariat@example.com",
+		FirstName:  "Sabine",
+		LastName:   "Sekretariat",
+		Role:       "admin",
+		AdminScope: "department",
+		Department: stringPtr("IT"),
+	}
+	teacherReq := func(department string) *CreateInvitationRequest {
+		return &CreateInvitationRequest{
+			Email:      "anna@example.com",
+			FirstName:  "Anna",
+			LastName:   "Schmidt",
+			Role:       "teacher",
+			Department: stringPtr(department),
+		}
+	}
+
+	t.Run("full admin invites a department admin", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service, _ := newTestInvitationService(mockRepo)
+		mockAdmin(mockRepo, ctx, "admin-1", full)
+		mockRepo.On("HasOpenInvitation", ctx, adminReq.Email).Return(false, nil)
+		mockRepo.On("CreateInvitation", ctx, mock.AnythingOfType("*auth.Invitation")).Return(nil)
+
+		_, err := service.CreateInvitation(ctx, adminReq, "admin-1")
+
+		require.NoError(t, err)
+		created := mockRepo.Calls[2].Arguments.Get(1).(*Invitation)
+		assert.Equal(t, crypto.RoleAdmin, created.Role)
+		assert.Equal(t, crypto.AdminScopeDepartment, created.AdminScope)
+		assert.Equal(t, "IT", *created.Department)
+	})
+
+	t.Run("department admin invites teachers of their department", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service, _ := newTestInvitationService(mockRepo)
+		mockAdmin(mockRepo, ctx, "admin-2", itAdmin)
+		mockRepo.On("HasOpenInvitation", ctx, "anna@example.com").Return(false, nil)
+		mockRepo.On("CreateInvitation", ctx, mock.AnythingOfType("*auth.Invitation")).Return(nil)
+
+		_, err := service.CreateInvitation(ctx, teacherReq("it"), "admin-2")
+
+		require.NoError(t, err)
+	})
+
+	tests := []struct {
+		name    string
+		req     *CreateInvitationRequest
+		actorID string
+		actor   crypto.AdminClaims
+	}{
+		{"department admin invites another department", teacherReq("Math"), "admin-2", itAdmin},
+		{"department admin invites an admin", adminReq, "admin-2", itAdmin},
+		{"security admin invites a teacher", teacherReq("IT"), "admin-3", crypto.AdminClaims{AdminScope: crypto.AdminScopeSecurity}},
+		{"service account invites an admin", adminReq, "", crypto.AdminClaims{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			service, _ := newTestInvitationService(mockRepo)
+			mockAdmin(mockRepo, ctx, tt.actorID, tt.actor)
+
+			_, err := service.CreateInvitation(ctx, tt.req, tt.actorID)
+
+			assertAppErrorCode(t, err, 403)
+			mockRepo.AssertNotCalled(t, "CreateInvitation", mock.Anything, mock.Anything)
+		})
+	}
+
+	t.Run("validates admin scope", func(t *testing.T) {
+		service, _ := newTestInvitationService(new(MockRepository))
+
+		for _, req := range []*CreateInvitationRequest{
+			{Email: "a@example.com", FirstName: "A", LastName: "B", Role: "admin"},
+			{Email: "a@example.com", FirstName: "A", LastName: "B", Role: "admin", AdminScope: "department"},
+			{Email: "a@example.com", FirstName: "A", LastName: "B", Role: "admin", AdminScope: "full", TeacherUUID: stringPtr("teacher-1")},
+			{Email: "a@example.com", FirstName: "A", LastName: "B", Role: "teacher", Department: stringPtr("IT"), AdminScope: "full"},
+		} {
+			_, err := service.CreateInvitation(ctx, req, "admin-1")
+			assertAppErrorCode(t, err, 422)
+		}
+	})
+}
+
+func TestInvitationService_CompleteInvitation(t *testing.T) {
+	ctx := context.Background()
+	invitation := &Invitation{
+		ID:          "inv-1",
+		Token:       "invitation-token",
+		Email:       "erika@example.com",
+		FirstName:   "Erika",
+		LastName:    "Muster",
+		Role:        crypto.RoleStudent,
+		TeacherUUID: stringPtr("teacher-1"),
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+	req := &CompleteInvitationRequest{Username: "erika.muster", Password: "SecurePassword123!"}
+
+	t.Run("creates the student assigned to the teacher", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service, _ := newTestInvitationService(mockRepo)
+		mockRepo.On("GetInvitationByToken", ctx, invitation.Token).Return(invitation, nil)
+		mockRepo.On("GetUserByUsername", ctx, req.Username).Return(nil, errors.NotFound("user"))
+		mockRepo.On("GetActiveTeacher", ctx, "teacher-1").Return(&reference.Teacher{UUID: "teacher-1"}, nil)
+		mockRepo.On("CreateStudent", ctx, mock.AnythingOfType("*auth.StudentRecord")).Return(nil)
+		mockRepo.On("CreateUser", ctx, mock.AnythingOfType("*auth.User")).Return(nil)
+		mockRepo.On("MarkInvitationAsUsed", ctx, invitation.Token).Return(nil)
+
+		err := service.CompleteInvitation(ctx, invitation.Token, req)
+
+		require.NoError(t, err)
+		student := mockRepo.Calls[3].Arguments.Get(1).(*StudentRecord)
+		assert.Equal(t, "teacher-1", *student.TeacherID)
+	})
+
+	t.Run("creates an admin without record", func(t *testing.T) {
+		adminInvitation := &Invitation{
+			ID:         "inv-2",
+			Token:      "admin-invitation-token",
+			Email:      "it@example.com",
+			Role:       crypto.RoleAdmin,
+			AdminScope: crypto.AdminScopeSecurity,
+			ExpiresAt:  time.Now().Add(time.Hour),
+		}
+		mockRepo := new(MockRepository)
+		service, _ := newTestInvitationService(mockRepo)
+		mockRepo.On("GetInvitationByToken", ctx, adminInvitation.Token).Return(adminInvitation, nil)
+		mockRepo.On("GetUserByUsername", ctx, req.Username).Return(nil, errors.NotFound("user"))
+		mockRepo.On("CreateUser", ctx, mock.AnythingOfType("*auth.User")).Return(nil)
+		mockRepo.On("MarkInvitationAsUsed", ctx, adminInvitation.Token).Return(nil)
+
+		err := service.CompleteInvitation(ctx, adminInvitation.Token, req)
+
+		require.NoError(t, err)
+		user := mockRepo.Calls[2].Arguments.Get(1).(*User)
+		assert.Equal(t, crypto.RoleAdmin, user.Role)
+		assert.Equal(t, crypto.AdminScopeSecurity, user.AdminScope)
+		assert.Nil(t, user.UserUUID)
+		mockRepo.AssertNotCalled(t, "CreateStudent", mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "CreateTeacher", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects a teacher deleted since the invitation was created", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service, _ := newTestInvitationService(mockRepo)
+		mockRepo.On("GetInvitationByToken", ctx, invitation.Token).Return(invitation, nil)
+		mockRepo.On("GetUserByUsername", ctx, req.Username).Return(nil, errors.NotFound("user"))
+		mockRepo.On("GetActiveTeacher", ctx, "teacher-1").Return(nil, nil)
+
+		err := service.CompleteInvitation(ctx, invitation.Token, req)
+
+		appErr, ok := err.(*errors.AppError)
+		require.True(t, ok)
+		assert.Equal(t, 409, appErr.Code)
+		mockRepo.AssertNotCalled(t, "CreateStudent", mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "MarkInvitationAsUsed", mock.Anything, mock.Anything)
+	})
+}
+
+func TestInvitationService_ListInvitations(t *testing.T) {
+	ctx := context.Background()
+	params := pagination.Params{Page: 1, Limit: 20}
+
+	t.Run("maps invitations to summaries", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service, _ := newTestInvitationService(mockRepo)
+		filter := InvitationFilter{Status: InvitationStatusPending}
+		invitations := []Invitation{{ID: "inv-1", Token: "secret", Email: "max@example.com", Role: crypto.RoleStudent, ExpiresAt: time.Now().Add(time.Hour)}}
+		mockAdmin(mockRepo, ctx, "admin-1", crypto.AdminClaims{AdminScope: crypto.AdminScopeFull})
+		mockRepo.On("ListInvitations", ctx, filter, params).Return(invitations, int64(1), nil)
+
+		result, total, err := service.ListInvitations(ctx, filter, params, "admin-1")
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		require.Len(t, result, 1)
+		assert.Equal(t, "inv-1", result[0].ID)
+		assert.Equal(t, InvitationStatusPending, result[0].Status)
+	})
+
+	t.Run("rejects unknown status", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service, _ := newTestInvitationService(mockRepo)
+
+		_, _, err := service.ListInvitations(ctx, InvitationFilter{Status: "open"}, params, "admin-1")
+
+		assert.Error(t, err)
+		mockRepo.AssertNotCalled(t, "ListInvitations", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestInvitationService_RevokeInvitation(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("revokes a pending invitation", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service, _ := newTestInvitationService(mockRepo)
+		mockAdmin(mockRepo, ctx, "admin-1", crypto.AdminClaims{AdminScope: crypto.AdminScopeFull})
+		mockRepo.On("GetInvitationByID", ctx, "inv-1").Return(&Invitation{ID: "inv-1", ExpiresAt: time.Now().Add(time.Hour)}, nil)
+		mockRepo.On("RevokeInvitation", ctx, "inv-1", "admin-1").Return(nil)
+
+		require.NoError(t, service.RevokeInvitation(ctx, "inv-1", "admin-1"))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("used invitation cannot be revoked", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service, _ := newTestInvitationService(mockRepo)
+		mockAdmin(mockRepo, ctx, "admin-1", crypto.AdminClaims{AdminScope: crypto.AdminScopeFull})
+		mockRepo.On("GetInvitationByID", ctx, "inv-1").Return(&Invitation{ID: "inv-1", Used: true}, nil)
+
+		err := service.RevokeInvitation(ctx, "inv-1", "admin-1")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "already used")
+		mockRepo.AssertNotCalled(t, "RevokeInvitation", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("revoked invitation can no longer be viewed", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service, jwtService := newTestInvitationService(mockRepo)
+		token, _ := jwtService.GenerateToken("max@example.com", crypto.RoleStudent, crypto.TokenTypeInvitation, time.Hour)
+		revokedAt := time.Now()
+		mockRepo.On("GetInvitationByToken", ctx, token).Return(&Invitation{Token: token, ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revokedAt}, nil)
+
+		_, err := service.GetInvitationDetails(ctx, token)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invitation revoked")
+	})
+}
+
+func TestInvitationService_ResendInvitation(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("issues a new token for an expired invitation", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service, jwtService := newTestInvitationService(mockRepo)
+		invitation := &Invitation{ID: "inv-1", Token: "old-token", Email: "max@example.com", Role: crypto.RoleStudent, ExpiresAt: time.Now().Add(-time.Hour)}
+		mockAdmin(mockRepo, ctx, "admin-1", crypto.AdminClaims{AdminScope: crypto.AdminScopeFull})
+		mockRepo.On("GetInvitationByID", ctx, "inv-1").Return(invitation, nil)
+		mockRepo.On("ReissueInvitation", ctx, "inv-1", mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(nil)
+
+		resp, err := service.ResendInvitation(ctx, "inv-1", "admin-1")
+
+		require.NoError(t, err)
+		assert.NotEqual(t, "old-token", resp.InvitationToken)
+		assert.Equal(t, resp.InvitationToken, mockRepo.Calls[2].Arguments.String(2))
+		claims, err := jwtService.ValidateToken(resp.InvitationToken)
+		require.NoError(t, err)
+		assert.Equal(t, "max@example.com", claims.UserID)
+		assert.Equal(t, crypto.TokenTypeInvitation, claims.TokenType)
+	})
+
+	t.Run("revoked invitation cannot be resent", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service, _ := newTestInvitationService(mockRepo)
+		revokedAt := time.Now()
+		mockAdmin(mockRepo, ctx, "admin-1", crypto.AdminClaims{AdminScope: crypto.AdminScopeFull})
+		mockRepo.On("GetInvitationByID", ctx, "inv-1").Return(&Invitation{ID: "inv-1", ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revokedAt}, nil)
+
+		_, err := service.ResendInvitation(ctx, "inv-1", "admin-1")
+
+		require.Error(t, err)
+		mockRepo.AssertNotCalled(t, "ReissueInvitation", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestInvitationService_DepartmentAdmin(t *testing.T) {
+	ctx := context.Background()
+	department := crypto.AdminClaims{AdminScope: crypto.AdminScopeDepartment, Department: "IT"}
+	expiresAt := time.Now().Add(time.Hour)
+	invitations := map[string]*Invitation{
+		"it-teacher": {ID: "it-teacher", Email: "anna@example.com", Role: crypto.RoleTeacher, Department: stringPtr("it"), ExpiresAt: expiresAt},
+		"hr-teacher": {ID: "hr-teacher", Email: "tom@example.com", Role: crypto.RoleTeacher, Department: stringPtr("HR"), ExpiresAt: expiresAt},
+		"it-student": {ID: "it-student", Email: "max@example.com", Role: crypto.RoleStudent, TeacherUUID: stringPtr("teacher-it"), ExpiresAt: expiresAt},
+		"hr-student": {ID: "hr-student", Email: "erika@example.com", Role: crypto.RoleStudent, TeacherUUID: stringPtr("teacher-hr"), ExpiresAt: expiresAt},
+		"admin":      {ID: "admin", Email: "lena@example.com", Role: crypto.RoleAdmin, AdminScope: crypto.AdminScopeDepartment, Department: stringPtr("IT"), ExpiresAt: expiresAt},
+	}
+	setup := func() (*InvitationService, *MockRepository) {
+		mockRepo := new(MockRepository)
+		service, _ := newTestInvitationService(mockRepo)
+		mockAdmin(mockRepo, ctx, "admin-2", department)
+		for id, invitation := range invitations {
+			mockRepo.On("GetInvitationByID", ctx, id).Return(invitation, nil).Maybe()
+		}
+		mockRepo.On("GetActiveTeacher", ctx, "teacher-it").Return(&reference.Teacher{UUID: "teacher-it", Department: "IT"}, nil).Maybe()
+		mockRepo.On("GetActiveTeacher", ctx, "teacher-hr").Return(&reference.Teacher{UUID: "teacher-hr", Department: "HR"}, nil).Maybe()
+		return service, mockRepo
+	}
+	assertForbidden := func(t *testing.T, err error) {
+		t.Helper()
+		appErr, ok := err.(*errors.AppError)
+		require.True(t, ok, "got %v", err)
+		assert.Equal(t, 403, appErr.Code)
+	}
+
+	t.Run("gets invitations of the department", func(t *testing.T) {
+		service, _ := setup()
+
+		for _, id := range []string{"it-teacher", "it-student"} {
+			result, err := service.GetInvitation(ctx, id, "admin-2")
+			require.NoError(t, err, id)
+			assert.Equal(t, id, result.ID)
+		}
+	})
+
+	t.Run("can't access invitations of other departments", func(t *testing.T) {
+		service, mockRepo := setup()
+
+		for _, id := range []string{"hr-teacher", "hr-student", "admin"} {
+			_, err := service.GetInvitation(ctx, id, "admin-2")
+			assertForbidden(t, err)
+			assertForbidden(t, service.RevokeInvitation(ctx, id, "admin-2"))
+			_, err = service.ResendInvitation(ctx, id, "admin-2")
+			assertForbidden(t, err)
+		}
+		mockRepo.AssertNotCalled(t, "RevokeInvitation", mock.Anything, mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "ReissueInvitation", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("lists invitations of the department", func(t *testing.T) {
+		service, mockRepo := setup()
+		params := pagination.Params{Page: 1, Limit: 20}
+		mockRepo.On("ListInvitations", ctx, InvitationFilter{Status: InvitationStatusPending, Department: "IT"}, params).
+			Return([]Invitation{*invitations["it-teacher"]}, int64(1), nil)
+
+		result, total, err := service.ListInvitations(ctx, InvitationFilter{Status: InvitationStatusPending}, params, "admin-2")
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		require.Len(t, result, 1)
+		assert.Equal(t, "it-teacher", result[0].ID)
+	})
+}
+
+func TestInvitationService_PurgeExpiredInvitations(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service, _ := newTestInvitationService(mockRepo)
+	mockRepo.On("DeleteExpiredInvitations", ctx, mock.MatchedBy(func(before time.Time) bool {
+		return time.Until(before) < -29*24*time.Hour
+	})).Return(int64(3), nil)
+
+	result, err := service.PurgeExpiredInvitations(ctx, 30*24*time.Hour)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), result.Deleted)
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/errors"
+	"github.com/JustDoItBetter/FITS-backend/pkg/logger"
+)
+
+// DisableLinkedAccount disables the account of a deleted student or teacher record and revokes its sessions
+// It is registered as a lifecycle.DeleteHook and runs in the transaction deleting the record,
+// so the record is only deleted if the account was disabled as well
+// Records without account and accounts that are already disabled are left alone
+func DisableLinkedAccount(ctx context.Context, tx *gorm.DB, userUUID string) error {
+	repo := NewGormRepository(tx)
+
+	user, err := repo.GetUserByUserUUID(ctx, userUUID)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok && appErr.Code == 404 {
+			return nil
+		}
+		return err
+	}
+	if user.IsDisabled() {
+		return nil
+	}
+
+	if err := repo.DisableUser(ctx, user.ID, nil); err != nil {
+		return err
+	}
+
+	if err := repo.CreateSecurityEvent(ctx, &SecurityEvent{
+		EventType: SecurityEventAccountDisabled,
+		UserID:    &user.ID,
+		Username:  user.Username,
+		Details:   "disabled because the " + string(user.Role) + " record was deleted",
+	}); err != nil {
+		return err
+	}
+
+	logger.Info("Account of deleted record disabled",
+		zap.String("user_id", user.ID),
+		zap.String("user_uuid", userUUID),
+	)
+	return nil
+}
@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// setupLifecycleTestDB creates the tables DisableLinkedAccount touches in an in-memory SQLite database
+func setupLifecycleTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err, "failed to create test database")
+
+	for _, stmt := range []string{
+		`CREATE TABLE users (id TEXT PRIMARY KEY, username TEXT NOT NULL, password_hash TEXT NOT NULL, role TEXT NOT NULL,
+			user_uuid TEXT, created_at DATETIME, last_login DATETIME, locked_until DATETIME, mfa_enabled BOOLEAN DEFAULT false,
+			totp_secret TEXT, totp_last_step INTEGER, disabled_at DATETIME, disabled_by TEXT)`,
+		`CREATE TABLE refresh_tokens (id TEXT PRIMARY KEY, user_id TEXT NOT NULL, token TEXT NOT NULL)`,
+		`CREATE TABLE security_events (id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))), event_type TEXT NOT NULL,
+			user_id TEXT, username TEXT, ip_address TEXT, actor_id TEXT, details TEXT, created_at DATETIME DEFAULT CURRENT_TIMESTAMP)`,
+	} {
+		require.NoError(t, db.Exec(stmt).Error)
+	}
+	return db
+}
+
+func TestDisableLinkedAccount(t *testing.T) {
+	ctx := context.Background()
+	db := setupLifecycleTestDB(t)
+	studentUUID := "550e8400-e29b-41d4-a716-446655440000"
+
+	require.NoError(t, db.Exec(`INSERT INTO users (id, username, password_hash, role, user_uuid) VALUES ('user-1', 'max', 'hash', 'student', ?)`, studentUUID).Error)
+	require.NoError(t, db.Exec(`INSERT INTO refresh_tokens (id, user_id, token) VALUES ('token-1', 'user-1', 'secret')`).Error)
+
+	require.NoError(t, db.Transaction(func(tx *gorm.DB) error {
+		return DisableLinkedAccount(ctx, tx, studentUUID)
+	}))
+
+	user, err := NewGormRepository(db).GetUserByID(ctx, "user-1")
+	require.NoError(t, err)
+	assert.True(t, user.IsDisabled())
+	assert.Nil(t, user.DisabledBy)
+
+	var tokens int64
+	require.NoError(t, db.Table("refresh_tokens").Where("user_id = ?", "user-1").Count(&tokens).Error)
+	assert.Zero(t, tokens)
+
+	var events []SecurityEvent
+	require.NoError(t, db.Find(&events).Error)
+	require.Len(t, events, 1)
+	assert.Equal(t, SecurityEventAccountDisabled, events[0].EventType)
+	assert.Equal(t, "max", events[0].Username)
+
+	t.Run("already disabled account is left alone", func(t *testing.T) {
+		assert.NoError(t, DisableLinkedAccount(ctx, db, studentUUID))
+
+		var count int64
+		require.NoError(t, db.Model(&SecurityEvent{}).Count(&count).Error)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("record without account", func(t *testing.T) {
+		assert.NoError(t, DisableLinkedAccount(ctx, db, "550e8400-e29b-41d4-a716-446655440001"))
+	})
+}
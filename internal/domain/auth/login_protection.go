@@ -0,0 +1,162 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/errors"
+	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
+	"github.com/JustDoItBetter/FITS-backend/pkg/logger"
+	"go.uber.org/zap"
+)
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     string
+)
+
+// dummyPasswordHash returns a password hash that is verified when the username does not exist
+// This makes unknown usernames take as long as wrong passwords, preventing user enumeration via timing
+func dummyPasswordHash() string {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = crypto.HashPassword("fits-timing-equalization-dummy-password")
+	})
+	return dummyHash
+}
+
+// progressiveDelay returns the wait time required after the given number of consecutive failures
+// The delay doubles with every failure, starting at base and capped at maxDelay
+func progressiveDelay(failures int64, base, maxDelay time.Duration) time.Duration {
+	if failures <= 0 {
+		return 0
+	}
+	delay := float64(base) * math.Pow(2, float64(failures-1))
+	if delay > float64(maxDelay) {
+		return maxDelay
+	}
+	return time.Duration(delay)
+}
+
+// checkLoginThrottle rejects a login attempt before the password is checked
+// if the IP is blocked, the username is locked or the progressive delay has not elapsed yet
+// The same checks apply to unknown usernames so lockout responses don't reveal which accounts exist
+func (s *AuthService) checkLoginThrottle(stats *LoginFailureStats, now time.Time) error {
+	lockout := s.securityConfig.GetLockoutDuration()
+
+	if stats.IPFailures >= int64(s.securityConfig.GetMaxFailedLoginsPerIP()) &&
+		stats.IPLastFailure != nil && now.Before(stats.IPLastFailure.Add(lockout)) {
+		return errors.TooManyRequests("too many failed login attempts from this address, please try again later")
+	}
+
+	if stats.UsernameFailures >= int64(s.securityConfig.GetMaxFailedLogins()) &&
+		stats.UsernameLastFailure != nil && now.Before(stats.UsernameLastFailure.Add(lockout)) {
+		return errors.TooManyRequests("account temporarily locked due to too many failed login attempts")
+	}
+
+	if stats.UsernameLastFailure != nil {
+		delay := progressiveDelay(stats.UsernameFailures, s.securityConfig.GetLoginDelayBase(), s.securityConfig.GetLoginDelayMax())
+		if wait := stats.UsernameLastFailure.Add(delay).Sub(now); wait > 0 {
+			return errors.TooManyRequests(fmt.Sprintf("please wait %d seconds before trying again", int(math.Ceil(wait.Seconds()))))
+		}
+	}
+
+	return nil
+}
+
+// recordFailedLogin stores a failed attempt and locks the account or blocks the IP once a threshold is reached
+// Errors are logged but not returned - the caller always answers with "invalid credentials"
+func (s *AuthService) recordFailedLogin(ctx context.Context, user *User, username, ipAddress string, stats *LoginFailureStats, now time.Time) {
+	attempt := &LoginAttempt{
+		Username:    username,
+		IPAddress:   ipAddress,
+		AttemptedAt: now,
+	}
+	lockout := s.securityConfig.GetLockoutDuration()
+
+	// Attempts older than both the counting window and the lockout can't affect any decision anymore
+	retention := max(s.securityConfig.GetFailedLoginWindow(), lockout)
+	if err := s.repo.RecordFailedLogin(ctx, attempt, now.Add(-retention)); err != nil {
+		logger.Error("Failed to record failed login attempt",
+			zap.String("username", username),
+			zap.String("ip_address", ipAddress),
+			zap.Error(err),
+		)
+	}
+
+	if stats.UsernameFailures+1 >= int64(s.securityConfig.GetMaxFailedLogins()) {
+		event := &SecurityEvent{
+			EventType: SecurityEventAccountLocked,
+			Username:  username,
+			IPAddress: ipAddress,
+			Details:   fmt.Sprintf("locked for %s after %d failed login attempts", lockout, stats.UsernameFailures+1),
+		}
+		if user != nil {
+			event.UserID = &user.ID
+			if err := s.repo.LockUser(ctx, user.ID, now.Add(lockout)); err != nil {
+				logger.Error("Failed to lock user account",
+					zap.String("user_id", user.ID),
+					zap.Error(err),
+				)
+			}
+		}
+		s.logSecurityEvent(ctx, event)
+	}
+
+	if stats.IPFailures+1 >= int64(s.securityConfig.GetMaxFailedLoginsPerIP()) {
+		s.logSecurityEvent(ctx, &SecurityEvent{
+			EventType: SecurityEventIPBlocked,
+			Username:  username,
+			IPAddress: ipAddress,
+			Details:   fmt.Sprintf("blocked for %s after %d failed login attempts", lockout, stats.IPFailures+1),
+		})
+	}
+}
+
+// logSecurityEvent writes an event to the security log table and the application log
+func (s *AuthService) logSecurityEvent(ctx context.Context, event *SecurityEvent) {
+	logger.Warn("Security event",
+		zap.String("event_type", string(event.EventType)),
+		zap.String("username", event.Username),
+		zap.String("ip_address", event.IPAddress),
+		zap.String("details", event.Details),
+	)
+
+	if err := s.repo.CreateSecurityEvent(ctx, event); err != nil {
+		logger.Error("Failed to write security event",
+			zap.String("event_type", string(event.EventType)),
+			zap.Error(err),
+		)
+	}
+}
+
+// UnlockUser lifts a lockout and clears the failed attempts of an account
+// actorID is the admin performing the unlock and is recorded in the security log
+func (s *AuthService) UnlockUser(ctx context.Context, userID, actorID string) error {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := requireAdminManager(ctx, s.repo, actorID, user); err != nil {
+		return err
+	}
+
+	if err := s.repo.UnlockUser(ctx, user); err != nil {
+		return err
+	}
+
+	event := &SecurityEvent{
+		EventType: SecurityEventAccountUnlocked,
+		UserID:    &user.ID,
+		Username:  user.Username,
+		Details:   "unlocked by administrator",
+	}
+	if actorID != "" {
+		event.ActorID = &actorID
+	}
+	s.logSecurityEvent(ctx, event)
+
+	return nil
+}
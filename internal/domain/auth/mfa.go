@@ -0,0 +1,211 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/errors"
+	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
+	"github.com/JustDoItBetter/FITS-backend/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	// MFATokenExpiry is how long a partial MFA token can be exchanged for real tokens
+	MFATokenExpiry = 5 * time.Minute
+	// RecoveryCodeCount is the number of recovery codes generated on enrollment
+	RecoveryCodeCount = 10
+)
+
+// mfaChallenge returns a partial login response that only contains an MFA token
+func (s *AuthService) mfaChallenge(user *User) (*LoginResponse, error) {
+	mfaToken, err := s.jwtService.GenerateToken(user.ID, user.Role, crypto.TokenTypeMFA, MFATokenExpiry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate MFA token: %w", err)
+	}
+
+	return &LoginResponse{
+		ExpiresIn:             int64(MFATokenExpiry.Seconds()),
+		TokenType:             "Bearer",
+		Role:                  string(user.Role),
+		UserID:                user.ID,
+		MFARequired:           true,
+		MFAToken:              mfaToken,
+		MFAEnrollmentRequired: !user.MFAEnabled,
+	}, nil
+}
+
+// VerifyMFA exchanges a partial MFA token and a TOTP or recovery code for access and refresh tokens
+// Wrong codes count as failed logins, so the same lockout rules apply as for passwords
+func (s *AuthService) VerifyMFA(ctx context.Context, req *MFAVerifyRequest, client ClientInfo) (*LoginResponse, error) {
+	claims, err := s.jwtService.ValidateToken(req.MFAToken)
+	if err != nil || claims.TokenType != crypto.TokenTypeMFA {
+		return nil, errors.Unauthorized("invalid or expired MFA token")
+	}
+
+	user, err := s.repo.GetUserByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, errors.Unauthorized("invalid or expired MFA token")
+	}
+
+	if !user.MFAEnabled {
+		return nil, errors.BadRequest("two-factor authentication is not enrolled, enroll with the MFA token first")
+	}
+
+	now := time.Now()
+	stats, err := s.repo.GetLoginFailureStats(ctx, user.Username, client.IPAddress, now.Add(-s.securityConfig.GetFailedLoginWindow()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check login attempts: %w", err)
+	}
+	if err := s.checkLoginThrottle(stats, now); err != nil {
+		return nil, err
+	}
+
+	ok, err := s.verifySecondFactor(ctx, user, req.Code, now)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		s.recordFailedLogin(ctx, user, user.Username, client.IPAddress, stats, now)
+		return nil, errors.Unauthorized("invalid verification code")
+	}
+
+	return s.issueTokens(ctx, user, client)
+}
+
+// verifySecondFactor accepts a TOTP code (each time step only once) or an unused recovery code
+func (s *AuthService) verifySecondFactor(ctx context.Context, user *User, code string, now time.Time) (bool, error) {
+	if user.TOTPSecret == nil {
+		return false, nil
+	}
+
+	if step, ok := crypto.ValidateTOTPCode(*user.TOTPSecret, code, now); ok {
+		// Reject replay of a code that was already used in this or an earlier step,
+		// the repository re-checks the step so concurrent requests can't both use the code
+		if user.TOTPLastStep != nil && step <= *user.TOTPLastStep {
+			return false, nil
+		}
+		return s.repo.UseTOTPStep(ctx, user.ID, step)
+	}
+
+	used, err := s.repo.UseRecoveryCode(ctx, user.ID, crypto.HashString(crypto.NormalizeRecoveryCode(code)))
+	if err != nil {
+		return false, err
+	}
+	if used {
+		logger.Info("Recovery code used for login", zap.String("user_id", user.ID))
+	}
+	return used, nil
+}
+
+// EnrollMFA generates a new TOTP secret for the user
+// The secret only becomes active after ConfirmMFA verified a code from the authenticator app
+func (s *AuthService) EnrollMFA(ctx context.Context, userID string) (*MFAEnrollResponse, error) {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.MFAEnabled {
+		return nil, errors.Conflict("two-factor authentication is already enabled")
+	}
+
+	secret, err := crypto.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SetTOTPSecret(ctx, user.ID, secret); err != nil {
+		return nil, err
+	}
+
+	return &MFAEnrollResponse{
+		Secret:          secret,
+		ProvisioningURI: crypto.TOTPProvisioningURI(s.securityConfig.GetMFAIssuer(), user.Username, secret),
+	}, nil
+}
+
+// ConfirmMFA activates two-factor authentication after verifying the first TOTP code
+// Returns the recovery codes in plain text - they are only stored hashed and cannot be shown again
+// If client is set (enrollment during login with an MFA token), tokens are issued as well
+func (s *AuthService) ConfirmMFA(ctx context.Context, userID string, req *MFAConfirmRequest, client *ClientInfo) (*MFAConfirmResponse, error) {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.MFAEnabled {
+		return nil, errors.Conflict("two-factor authentication is already enabled")
+	}
+	if user.TOTPSecret == nil {
+		return nil, errors.BadRequest("no pending enrollment, call enroll first")
+	}
+
+	step, ok := crypto.ValidateTOTPCode(*user.TOTPSecret, req.Code, time.Now())
+	if !ok {
+		return nil, errors.ValidationError("invalid verification code")
+	}
+	// A concurrent confirmation may have used the code already
+	used, err := s.repo.UseTOTPStep(ctx, user.ID, step)
+	if err != nil {
+		return nil, err
+	}
+	if !used {
+		return nil, errors.ValidationError("invalid verification code")
+	}
+
+	codes, err := crypto.GenerateRecoveryCodes(RecoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = crypto.HashString(crypto.NormalizeRecoveryCode(code))
+	}
+
+	if err := s.repo.EnableMFA(ctx, user.ID, hashes); err != nil {
+		return nil, err
+	}
+
+	result := &MFAConfirmResponse{RecoveryCodes: codes}
+	if client != nil {
+		tokens, err := s.issueTokens(ctx, user, *client)
+		if err != nil {
+			return nil, err
+		}
+		result.Tokens = tokens
+	}
+
+	return result, nil
+}
+
+// DisableMFA turns off two-factor authentication after re-verifying password and code
+// Not allowed for roles that require 2FA
+func (s *AuthService) DisableMFA(ctx context.Context, userID string, req *MFADisableRequest) error {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !user.MFAEnabled {
+		return errors.BadRequest("two-factor authentication is not enabled")
+	}
+	if s.securityConfig.RequiresMFA(string(user.Role)) {
+		return errors.NewAppError(403, "Forbidden", "two-factor authentication is mandatory for role "+string(user.Role))
+	}
+
+	if err := crypto.VerifyPassword(req.Password, user.PasswordHash); err != nil {
+		return errors.Unauthorized("invalid credentials")
+	}
+
+	ok, err := s.verifySecondFactor(ctx, user, req.Code, time.Now())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.Unauthorized("invalid verification code")
+	}
+
+	return s.repo.DisableMFA(ctx, user.ID)
+}
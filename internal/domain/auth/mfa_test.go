@@ -0,0 +1,300 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newMFATestUser creates a user with 2FA enabled and returns it with its TOTP secret
+func newMFATestUser(t *testing.T, password string) (*User, string) {
+	t.Helper()
+
+	secret, err := crypto.GenerateTOTPSecret()
+	require.NoError(t, err)
+	hashedPassword, err := crypto.HashPassword(password)
+	require.NoError(t, err)
+
+	return &User{
+		ID:           "user-123",
+		Username:     "teacher1",
+		PasswordHash: hashedPassword,
+		Role:         crypto.RoleTeacher,
+		MFAEnabled:   true,
+		TOTPSecret:   &secret,
+	}, secret
+}
+
+func TestAuthService_LoginMFAChallenge(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("enrolled user gets MFA token instead of tokens", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		jwtService := crypto.NewJWTService("test-secret")
+		service := NewAuthService(mockRepo, jwtService, getTestJWTConfig(), getTestSecurityConfig(), getTestNotifier())
+
+		user, _ := newMFATestUser(t, "testPassword123")
+		mockRepo.On("GetLoginFailureStats", ctx, "teacher1", testClientIP, mock.AnythingOfType("time.Time")).Return(&LoginFailureStats{}, nil)
+		mockRepo.On("GetUserByUsername", ctx, "teacher1").Return(user, nil)
+
+		resp, err := service.Login(ctx, &LoginRequest{Username: "teacher1", Password: "testPassword123"}, testClient)
+
+		require.NoError(t, err)
+		assert.True(t, resp.MFARequired)
+		assert.False(t, resp.MFAEnrollmentRequired)
+		assert.Empty(t, resp.AccessToken)
+		assert.Empty(t, resp.RefreshToken)
+
+		claims, err := jwtService.ValidateToken(resp.MFAToken)
+		require.NoError(t, err)
+		assert.Equal(t, crypto.TokenTypeMFA, claims.TokenType)
+		mockRepo.AssertNotCalled(t, "CreateRefreshToken", mock.Anything, mock.Anything)
+	})
+
+	t.Run("role requiring MFA without enrollment must enroll", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		cfg := getTestSecurityConfig()
+		cfg.MFARequiredRoles = []string{"teacher"}
+		service := NewAuthService(mockRepo, crypto.NewJWTService("test-secret"), getTestJWTConfig(), cfg, getTestNotifier())
+
+		hashedPassword, _ := crypto.HashPassword("testPassword123")
+		user := &User{ID: "user-123", Username: "teacher1", PasswordHash: hashedPassword, Role: crypto.RoleTeacher}
+		mockRepo.On("GetLoginFailureStats", ctx, "teacher1", testClientIP, mock.AnythingOfType("time.Time")).Return(&LoginFailureStats{}, nil)
+		mockRepo.On("GetUserByUsername", ctx, "teacher1").Return(user, nil)
+
+		resp, err := service.Login(ctx, &LoginRequest{Username: "teacher1", Password: "testPassword123"}, testClient)
+
+		require.NoError(t, err)
+		assert.True(t, resp.MFARequired)
+		assert.True(t, resp.MFAEnrollmentRequired)
+		assert.NotEmpty(t, resp.MFAToken)
+	})
+}
+
+func TestAuthService_VerifyMFA(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T) (*MockRepository, *AuthService, *User, string, string) {
+		mockRepo := new(MockRepository)
+		jwtService := crypto.NewJWTService("test-secret")
+		service := NewAuthService(mockRepo, jwtService, getTestJWTConfig(), getTestSecurityConfig(), getTestNotifier())
+		user, secret := newMFATestUser(t, "testPassword123")
+		mfaToken, err := jwtService.GenerateToken(user.ID, user.Role, crypto.TokenTypeMFA, MFATokenExpiry)
+		require.NoError(t, err)
+
+		mockRepo.On("GetUserByID", ctx, user.ID).Return(user, nil)
+		mockRepo.On("GetLoginFailureStats", ctx, user.Username, testClientIP, mock.AnythingOfType("time.Time")).Return(&LoginFailureStats{}, nil)
+		return mockRepo, service, user, secret, mfaToken
+	}
+
+	t.Run("valid TOTP code issues tokens", func(t *testing.T) {
+		mockRepo, service, user, secret, mfaToken := setup(t)
+		code, _ := crypto.GenerateTOTPCode(secret, time.Now())
+		mockRepo.On("UseTOTPStep", ctx, user.ID, mock.AnythingOfType("int64")).Return(true, nil)
+		mockRepo.On("CreateRefreshToken", ctx, mock.AnythingOfType("*auth.RefreshToken")).Return(nil)
+		mockRepo.On("UpdateLastLogin", ctx, user.ID).Return(nil)
+
+		resp, err := service.VerifyMFA(ctx, &MFAVerifyRequest{MFAToken: mfaToken, Code: code}, testClient)
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, resp.AccessToken)
+		assert.NotEmpty(t, resp.RefreshToken)
+		assert.False(t, resp.MFARequired)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("replayed TOTP code is rejected", func(t *testing.T) {
+		mockRepo, service, user, secret, mfaToken := setup(t)
+		now := time.Now()
+		code, _ := crypto.GenerateTOTPCode(secret, now)
+		lastStep := crypto.TOTPStep(now)
+		user.TOTPLastStep = &lastStep
+		mockRepo.On("UseRecoveryCode", ctx, user.ID, mock.AnythingOfType("string")).Return(false, nil)
+		mockRepo.On("RecordFailedLogin", ctx, mock.AnythingOfType("*auth.LoginAttempt"), mock.AnythingOfType("time.Time")).Return(nil)
+
+		resp, err := service.VerifyMFA(ctx, &MFAVerifyRequest{MFAToken: mfaToken, Code: code}, testClient)
+
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+		mockRepo.AssertNotCalled(t, "UseTOTPStep", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("TOTP code used by a concurrent request is rejected", func(t *testing.T) {
+		mockRepo, service, user, secret, mfaToken := setup(t)
+		code, _ := crypto.GenerateTOTPCode(secret, time.Now())
+		mockRepo.On("UseTOTPStep", ctx, user.ID, mock.AnythingOfType("int64")).Return(false, nil)
+		mockRepo.On("RecordFailedLogin", ctx, mock.AnythingOfType("*auth.LoginAttempt"), mock.AnythingOfType("time.Time")).Return(nil)
+
+		resp, err := service.VerifyMFA(ctx, &MFAVerifyRequest{MFAToken: mfaToken, Code: code}, testClient)
+
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+		mockRepo.AssertNotCalled(t, "CreateRefreshToken", mock.Anything, mock.Anything)
+	})
+
+	t.Run("unused recovery code issues tokens", func(t *testing.T) {
+		mockRepo, service, user, _, mfaToken := setup(t)
+		codeHash := crypto.HashString(crypto.NormalizeRecoveryCode("abcde-12345"))
+		mockRepo.On("UseRecoveryCode", ctx, user.ID, codeHash).Return(true, nil)
+		mockRepo.On("CreateRefreshToken", ctx, mock.AnythingOfType("*auth.RefreshToken")).Return(nil)
+		mockRepo.On("UpdateLastLogin", ctx, user.ID).Return(nil)
+
+		resp, err := service.VerifyMFA(ctx, &MFAVerifyRequest{MFAToken: mfaToken, Code: "ABCDE-12345"}, testClient)
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, resp.AccessToken)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("wrong code counts as failed login", func(t *testing.T) {
+		mockRepo, service, user, _, mfaToken := setup(t)
+		mockRepo.On("UseRecoveryCode", ctx, user.ID, mock.AnythingOfType("string")).Return(false, nil)
+		mockRepo.On("RecordFailedLogin", ctx, mock.AnythingOfType("*auth.LoginAttempt"), mock.AnythingOfType("time.Time")).Return(nil)
+
+		_, err := service.VerifyMFA(ctx, &MFAVerifyRequest{MFAToken: mfaToken, Code: "000000"}, testClient)
+
+		assert.Error(t, err)
+		mockRepo.AssertCalled(t, "RecordFailedLogin", ctx, mock.AnythingOfType("*auth.LoginAttempt"), mock.AnythingOfType("time.Time"))
+	})
+
+	t.Run("access token is not accepted as MFA token", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		jwtService := crypto.NewJWTService("test-secret")
+		service := NewAuthService(mockRepo, jwtService, getTestJWTConfig(), getTestSecurityConfig(), getTestNotifier())
+		accessToken, _ := jwtService.GenerateToken("user-123", crypto.RoleTeacher, crypto.TokenTypeAccess, time.Hour)
+
+		_, err := service.VerifyMFA(ctx, &MFAVerifyRequest{MFAToken: accessToken, Code: "123456"}, testClient)
+
+		assert.Error(t, err)
+		mockRepo.AssertNotCalled(t, "GetUserByID", mock.Anything, mock.Anything)
+	})
+}
+
+func TestGormRepository_UseTOTPStep(t *testing.T) {
+	ctx := context.Background()
+	db := setupLifecycleTestDB(t)
+	repo := NewGormRepository(db)
+	require.NoError(t, db.Exec(`INSERT INTO users (id, username, password_hash, role) VALUES ('user-1', 'max', 'hash', 'student')`).Error)
+
+	used, err := repo.UseTOTPStep(ctx, "user-1", 100)
+	require.NoError(t, err)
+	assert.True(t, used)
+
+	for _, step := range []int64{100, 99} {
+		used, err = repo.UseTOTPStep(ctx, "user-1", step)
+		require.NoError(t, err)
+		assert.False(t, used, "step %d is not after the last used step", step)
+	}
+
+	used, err = repo.UseTOTPStep(ctx, "user-1", 101)
+	require.NoError(t, err)
+	assert.True(t, used)
+}
+
+func TestAuthService_EnrollAndConfirmMFA(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("enroll stores pending secret", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewAuthService(mockRepo, crypto.NewJWTService("test-secret"), getTestJWTConfig(), getTestSecurityConfig(), getTestNotifier())
+		user := &User{ID: "user-123", Username: "teacher1", Role: crypto.RoleTeacher}
+		mockRepo.On("GetUserByID", ctx, user.ID).Return(user, nil)
+		mockRepo.On("SetTOTPSecret", ctx, user.ID, mock.AnythingOfType("string")).Return(nil)
+
+		resp, err := service.EnrollMFA(ctx, user.ID)
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, resp.Secret)
+		assert.Contains(t, resp.ProvisioningURI, "otpauth://totp/")
+		mockRepo.AssertCalled(t, "SetTOTPSecret", ctx, user.ID, resp.Secret)
+	})
+
+	t.Run("enroll fails when already enabled", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewAuthService(mockRepo, crypto.NewJWTService("test-secret"), getTestJWTConfig(), getTestSecurityConfig(), getTestNotifier())
+		user, _ := newMFATestUser(t, "testPassword123")
+		mockRepo.On("GetUserByID", ctx, user.ID).Return(user, nil)
+
+		_, err := service.EnrollMFA(ctx, user.ID)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("confirm enables MFA and returns recovery codes", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewAuthService(mockRepo, crypto.NewJWTService("test-secret"), getTestJWTConfig(), getTestSecurityConfig(), getTestNotifier())
+		secret, _ := crypto.GenerateTOTPSecret()
+		user := &User{ID: "user-123", Username: "teacher1", Role: crypto.RoleTeacher, TOTPSecret: &secret}
+		code, _ := crypto.GenerateTOTPCode(secret, time.Now())
+
+		mockRepo.On("GetUserByID", ctx, user.ID).Return(user, nil)
+		mockRepo.On("EnableMFA", ctx, user.ID, mock.AnythingOfType("[]string")).Return(nil)
+		mockRepo.On("UseTOTPStep", ctx, user.ID, mock.AnythingOfType("int64")).Return(true, nil)
+		mockRepo.On("CreateRefreshToken", ctx, mock.AnythingOfType("*auth.RefreshToken")).Return(nil)
+		mockRepo.On("UpdateLastLogin", ctx, user.ID).Return(nil)
+
+		resp, err := service.ConfirmMFA(ctx, user.ID, &MFAConfirmRequest{Code: code}, &testClient)
+
+		require.NoError(t, err)
+		assert.Len(t, resp.RecoveryCodes, RecoveryCodeCount)
+		require.NotNil(t, resp.Tokens)
+		assert.NotEmpty(t, resp.Tokens.AccessToken)
+
+		// Only hashes of the recovery codes may be stored
+		hashes := mockRepo.Calls[2].Arguments.Get(2).([]string)
+		assert.Equal(t, crypto.HashString(crypto.NormalizeRecoveryCode(resp.RecoveryCodes[0])), hashes[0])
+		assert.NotContains(t, hashes, resp.RecoveryCodes[0])
+	})
+
+	t.Run("confirm rejects wrong code", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewAuthService(mockRepo, crypto.NewJWTService("test-secret"), getTestJWTConfig(), getTestSecurityConfig(), getTestNotifier())
+		secret, _ := crypto.GenerateTOTPSecret()
+		user := &User{ID: "user-123", Username: "teacher1", Role: crypto.RoleTeacher, TOTPSecret: &secret}
+		mockRepo.On("GetUserByID", ctx, user.ID).Return(user, nil)
+
+		_, err := service.ConfirmMFA(ctx, user.ID, &MFAConfirmRequest{Code: "000000"}, nil)
+
+		assert.Error(t, err)
+		mockRepo.AssertNotCalled(t, "EnableMFA", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestAuthService_DisableMFA(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("refused for role that requires MFA", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		cfg := getTestSecurityConfig()
+		cfg.MFARequiredRoles = []string{"teacher"}
+		service := NewAuthService(mockRepo, crypto.NewJWTService("test-secret"), getTestJWTConfig(), cfg, getTestNotifier())
+		user, secret := newMFATestUser(t, "testPassword123")
+		code, _ := crypto.GenerateTOTPCode(secret, time.Now())
+		mockRepo.On("GetUserByID", ctx, user.ID).Return(user, nil)
+
+		err := service.DisableMFA(ctx, user.ID, &MFADisableRequest{Password: "testPassword123", Code: code})
+
+		assert.Error(t, err)
+		mockRepo.AssertNotCalled(t, "DisableMFA", mock.Anything, mock.Anything)
+	})
+
+	t.Run("disables with password and code", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewAuthService(mockRepo, crypto.NewJWTService("test-secret"), getTestJWTConfig(), getTestSecurityConfig(), getTestNotifier())
+		user, secret := newMFATestUser(t, "testPassword123")
+		code, _ := crypto.GenerateTOTPCode(secret, time.Now())
+		mockRepo.On("GetUserByID", ctx, user.ID).Return(user, nil)
+		mockRepo.On("UseTOTPStep", ctx, user.ID, mock.AnythingOfType("int64")).Return(true, nil)
+		mockRepo.On("DisableMFA", ctx, user.ID).Return(nil)
+
+		err := service.DisableMFA(ctx, user.ID, &MFADisableRequest{Password: "testPassword123", Code: code})
+
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
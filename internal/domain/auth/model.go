@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
@@ -16,6 +17,18 @@ type User struct {
 	UserUUID     *string     `json:"user_uuid,omitempty" gorm:"type:uuid;index" example:"550e8400-e29b-41d4-a716-446655440000"` // References student or teacher (NULL for admin)
 	CreatedAt    time.Time   `json:"created_at" gorm:"default:CURRENT_TIMESTAMP"`
 	LastLogin    *time.Time  `json:"last_login,omitempty"`
+	LockedUntil  *time.Time  `json:"locked_until,omitempty"` // Set after too many failed login attempts
+	MFAEnabled   bool        `json:"mfa_enabled" gorm:"default:false"`
+	TOTPSecret   *string     `json:"-"`                     // Base32 TOTP secret, set during enrollment - never expose in JSON
+	TOTPLastStep *int64      `json:"-"`                     // Last accepted TOTP time step, prevents code replay
+	DisabledAt   *time.Time  `json:"disabled_at,omitempty"` // Set while an admin has disabled the account
+	DisabledBy   *string     `json:"disabled_by,omitempty" gorm:"type:uuid"`
+	// Profile fields users edit themselves, see UpdateProfileRequest
+	DisplayName   string                  `json:"display_name,omitempty" gorm:"not null;default:''" example:"Max M."`
+	Notifications NotificationPreferences `json:"notifications" gorm:"embedded"`
+	// Admins only, see crypto.AdminScope
+	AdminScope      crypto.AdminScope `json:"admin_scope,omitempty" gorm:"not null;default:''" example:"department"`
+	AdminDepartment string            `json:"admin_department,omitempty" gorm:"not null;default:''" example:"IT"` // Department admins only
 }
 
 // TableName specifies the table name for GORM
@@ -23,14 +36,265 @@ func (User) TableName() string {
 	return "users"
 }
 
+// IsLocked checks if the account is temporarily locked after failed login attempts
+func (u *User) IsLocked() bool {
+	return u.LockedUntil != nil && time.Now().Before(*u.LockedUntil)
+}
+
+// IsDisabled checks if an admin has disabled the account
+func (u *User) IsDisabled() bool {
+	return u.DisabledAt != nil
+}
+
+// AdminClaims returns the admin scope the account's tokens carry, empty for other roles
+func (u *User) AdminClaims() crypto.AdminClaims {
+	if u.Role != crypto.RoleAdmin {
+		return crypto.AdminClaims{}
+	}
+	return crypto.AdminClaims{
+		AdminScope: crypto.EffectiveAdminScope(u.Role, u.AdminScope),
+		Department: u.AdminDepartment,
+	}
+}
+
+// AccountClaims returns what the account's tokens carry besides ID and role: the linked record and the admin scope
+func (u *User) AccountClaims() crypto.AccountClaims {
+	account := crypto.AccountClaims{AdminClaims: u.AdminClaims()}
+	if u.UserUUID != nil {
+		account.UserUUID = *u.UserUUID
+	}
+	return account
+}
+
+// NotificationPreferences are the optional emails a user receives
+// Security emails such as password resets are always sent
+type NotificationPreferences struct {
+	SignRequests bool `json:"sign_requests" gorm:"column:notify_sign_requests;not null;default:true" example:"true"` // Reports to sign (teachers) or signed reports (students)
+	Reminders    bool `json:"reminders" gorm:"column:notify_reminders;not null;default:true" example:"true"`         // Reminders of reports due this week
+}
+
+// RecoveryCode is a hashed one-time code that replaces a TOTP code if the authenticator is lost
+type RecoveryCode struct {
+	ID        string     `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	UserID    string     `json:"user_id" gorm:"type:uuid;not null;index"`
+	CodeHash  string     `json:"-" gorm:"not null"` // SHA-256 of the normalized code
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at" gorm:"default:CURRENT_TIMESTAMP"`
+}
+
+// TableName specifies the table name for GORM
+func (RecoveryCode) TableName() string {
+	return "mfa_recovery_codes"
+}
+
+// PasswordResetToken is a single-use token for setting a new password without knowing the old one
+// Only the SHA-256 hash of the token is stored
+type PasswordResetToken struct {
+	ID        string    `gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	UserID    string    `gorm:"type:uuid;not null;index"`
+	TokenHash string    `gorm:"uniqueIndex;not null"`
+	CreatedBy *string   `gorm:"type:uuid"` // Admin who issued the reset
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+}
+
+// TableName specifies the table name for GORM
+func (PasswordResetToken) TableName() string {
+	return "password_reset_tokens"
+}
+
+// IsValid checks if the reset token is unused and not expired
+func (t *PasswordResetToken) IsValid() bool {
+	return t.UsedAt == nil && time.Now().Before(t.ExpiresAt)
+}
+
+// PasskeyCredential is a WebAuthn credential (passkey) registered by a user
+// @Description Registered passkey
+type PasskeyCredential struct {
+	ID           string     `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()" example:"550e8400-e29b-41d4-a716-446655440000"`
+	UserID       string     `json:"-" gorm:"type:uuid;not null;index"`
+	CredentialID string     `json:"credential_id" gorm:"uniqueIndex;not null" example:"q83vEjRWeJA"` // Base64url raw credential ID
+	Name         string     `json:"name" gorm:"not null" example:"MacBook Touch ID"`
+	Data         string     `json:"-" gorm:"type:text;not null"` // JSON-encoded webauthn.Credential (public key, flags, authenticator)
+	SignCount    int64      `json:"sign_count" gorm:"not null;default:0" example:"12"`
+	CreatedAt    time.Time  `json:"created_at" gorm:"default:CURRENT_TIMESTAMP"`
+	LastUsedAt   *time.Time `json:"last_used_at,omitempty"`
+}
+
+// TableName specifies the table name for GORM
+func (PasskeyCredential) TableName() string {
+	return "webauthn_credentials"
+}
+
+// WebAuthn ceremonies stored in WebAuthnSession
+const (
+	WebAuthnCeremonyRegistration = "registration"
+	WebAuthnCeremonyLogin        = "login"
+)
+
+// WebAuthnSession holds the challenge of a WebAuthn ceremony between begin and finish
+type WebAuthnSession struct {
+	ID        string    `gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	UserID    *string   `gorm:"type:uuid"` // NULL for discoverable (usernameless) login
+	Ceremony  string    `gorm:"not null"`
+	Data      string    `gorm:"type:text;not null"` // JSON-encoded webauthn.SessionData
+	ExpiresAt time.Time `gorm:"not null;index"`
+	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+}
+
+// TableName specifies the table name for GORM
+func (WebAuthnSession) TableName() string {
+	return "webauthn_sessions"
+}
+
+// IsExpired checks if the ceremony has timed out
+func (s *WebAuthnSession) IsExpired() bool {
+	return time.Now().After(s.ExpiresAt)
+}
+
+// OIDC identity statuses
+const (
+	OIDCIdentityStatusPending = "pending" // Waiting for an admin to link it to a user
+	OIDCIdentityStatusLinked  = "linked"
+)
+
+// OIDCIdentity links a subject at the external identity provider to a user
+// Identities that could not be linked automatically stay pending until an admin links them
+// @Description Account at the external identity provider
+type OIDCIdentity struct {
+	ID          string     `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Issuer      string     `json:"issuer" gorm:"not null" example:"https://keycloak.example.com/realms/school"`
+	Subject     string     `json:"subject" gorm:"not null" example:"f3a1c2d4-5b6e-4f70-8a91-b2c3d4e5f607"`
+	UserID      *string    `json:"user_id,omitempty" gorm:"type:uuid;index" example:"550e8400-e29b-41d4-a716-446655440000"` // NULL while pending
+	Status      string     `json:"status" gorm:"not null" example:"pending" enums:"pending,linked"`
+	Email       string     `json:"email,omitempty" example:"max@example.com"` // As reported by the provider at the last login
+	Name        string     `json:"name,omitempty" example:"Max Mustermann"`
+	LinkedBy    *string    `json:"linked_by,omitempty" gorm:"type:uuid"` // Admin who linked it, NULL if linked automatically
+	LinkedAt    *time.Time `json:"linked_at,omitempty"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"default:CURRENT_TIMESTAMP"`
+}
+
+// TableName specifies the table name for GORM
+func (OIDCIdentity) TableName() string {
+	return "oidc_identities"
+}
+
+// OIDCLoginState holds the nonce and PKCE verifier of a login between the redirect to the provider and the callback
+type OIDCLoginState struct {
+	ID           string    `gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	StateHash    string    `gorm:"uniqueIndex;not null"` // SHA-256 of the state parameter
+	Nonce        string    `gorm:"not null"`
+	CodeVerifier string    `gorm:"not null"`
+	ExpiresAt    time.Time `gorm:"not null;index"`
+	CreatedAt    time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+}
+
+// TableName specifies the table name for GORM
+func (OIDCLoginState) TableName() string {
+	return "oidc_login_states"
+}
+
+// IsExpired checks if the login has timed out
+func (s *OIDCLoginState) IsExpired() bool {
+	return time.Now().After(s.ExpiresAt)
+}
+
+// APIKey is the credential of a service account, e.g. a monitoring system or a school information system
+// The key itself is only shown once at creation, the hash is stored
+// @Description Service account API key
+type APIKey struct {
+	ID         string         `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name       string         `json:"name" gorm:"not null" example:"Prometheus"`
+	LookupID   string         `json:"lookup_id" gorm:"uniqueIndex;not null" example:"3f9a1c0b7d2e"` // Part of the key after "fits_", identifies it in logs
+	KeyHash    string         `json:"-" gorm:"not null"`                                            // SHA-256 of the whole key
+	Scopes     []crypto.Scope `json:"scopes" gorm:"serializer:json;not null" example:"metrics:read"`
+	CreatedBy  *string        `json:"created_by,omitempty" gorm:"type:uuid"`
+	ExpiresAt  *time.Time     `json:"expires_at,omitempty"` // Never expires if nil
+	LastUsedAt *time.Time     `json:"last_used_at,omitempty"`
+	LastUsedIP string         `json:"last_used_ip,omitempty" example:"192.0.2.10"`
+	RevokedAt  *time.Time     `json:"revoked_at,omitempty"`
+	RevokedBy  *string        `json:"revoked_by,omitempty" gorm:"type:uuid"`
+	CreatedAt  time.Time      `json:"created_at" gorm:"default:CURRENT_TIMESTAMP"`
+}
+
+// TableName specifies the table name for GORM
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// IsActive reports whether the key is neither revoked nor expired
+func (k *APIKey) IsActive() bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || time.Now().Before(*k.ExpiresAt))
+}
+
+// LoginAttempt records a failed login attempt for brute-force protection
+// Attempts are tracked per username and per IP address
+type LoginAttempt struct {
+	ID          string    `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	Username    string    `json:"username" gorm:"not null;index"`
+	IPAddress   string    `json:"ip_address" gorm:"not null;index"`
+	AttemptedAt time.Time `json:"attempted_at" gorm:"not null;index"`
+}
+
+// TableName specifies the table name for GORM
+func (LoginAttempt) TableName() string {
+	return "login_attempts"
+}
+
+// LoginFailureStats summarizes recent failed login attempts for a username and an IP address
+type LoginFailureStats struct {
+	UsernameFailures    int64
+	UsernameLastFailure *time.Time
+	IPFailures          int64
+	IPLastFailure       *time.Time
+}
+
+// SecurityEventType identifies the kind of security-relevant event
+type SecurityEventType string
+
+const (
+	SecurityEventAccountLocked   SecurityEventType = "account_locked"
+	SecurityEventAccountUnlocked SecurityEventType = "account_unlocked"
+	SecurityEventIPBlocked       SecurityEventType = "ip_blocked"
+	SecurityEventPasskeyCloned   SecurityEventType = "passkey_clone_warning"
+	SecurityEventAccountDisabled SecurityEventType = "account_disabled"
+	SecurityEventAccountEnabled  SecurityEventType = "account_enabled"
+	SecurityEventRoleChanged     SecurityEventType = "role_changed"
+	SecurityEventAccountDeleted  SecurityEventType = "account_deleted"
+)
+
+// SecurityEvent is an entry in the security log
+// @Description Security log entry
+type SecurityEvent struct {
+	ID        string            `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	EventType SecurityEventType `json:"event_type" gorm:"not null;index" example:"account_locked"`
+	UserID    *string           `json:"user_id,omitempty" gorm:"type:uuid;index"`
+	Username  string            `json:"username,omitempty" example:"max.mustermann"`
+	IPAddress string            `json:"ip_address,omitempty" example:"192.168.1.10"`
+	ActorID   *string           `json:"actor_id,omitempty" gorm:"type:uuid"` // Admin who triggered the event, if any
+	Details   string            `json:"details,omitempty"`
+	CreatedAt time.Time         `json:"created_at" gorm:"default:CURRENT_TIMESTAMP"`
+}
+
+// TableName specifies the table name for GORM
+func (SecurityEvent) TableName() string {
+	return "security_events"
+}
+
 // RefreshToken represents a refresh token for extending sessions
+// Each refresh token is one login session (device), identified by its ID
 // @Description Refresh token for session management
 type RefreshToken struct {
-	ID        string    `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
-	UserID    string    `json:"user_id" gorm:"type:uuid;not null;index"`
-	Token     string    `json:"token" gorm:"uniqueIndex;not null"`
-	ExpiresAt time.Time `json:"expires_at" gorm:"not null;index"`
-	CreatedAt time.Time `json:"created_at" gorm:"default:CURRENT_TIMESTAMP"`
+	ID         string     `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	UserID     string     `json:"user_id" gorm:"type:uuid;not null;index"`
+	Token      string     `json:"token" gorm:"uniqueIndex;not null"`
+	UserAgent  string     `json:"user_agent"`
+	IPAddress  string     `json:"ip_address"`             // Client IP of the most recent use
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"` // Last login or token refresh
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"not null;index"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"default:CURRENT_TIMESTAMP"`
 }
 
 // TableName specifies the table name for GORM
@@ -43,20 +307,35 @@ func (rt *RefreshToken) IsExpired() bool {
 	return time.Now().After(rt.ExpiresAt)
 }
 
+// ClientInfo describes the device a login or token refresh comes from
+type ClientInfo struct {
+	IPAddress string
+	UserAgent string
+}
+
 // Invitation represents an invitation for a user to register
 // @Description Invitation token for user registration
 type Invitation struct {
-	ID          string      `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
-	Token       string      `json:"token" gorm:"uniqueIndex;not null"`
-	Email       string      `json:"email" gorm:"not null"`
-	FirstName   string      `json:"first_name" gorm:"not null"`
-	LastName    string      `json:"last_name" gorm:"not null"`
-	Role        crypto.Role `json:"role" gorm:"not null"`
-	Department  *string     `json:"department,omitempty"`                          // Only for teachers
-	TeacherUUID *string     `json:"teacher_uuid,omitempty" gorm:"type:uuid;index"` // Required for students, NULL for teachers
-	Used        bool        `json:"used" gorm:"default:false;index"`
-	CreatedAt   time.Time   `json:"created_at" gorm:"default:CURRENT_TIMESTAMP"`
-	ExpiresAt   time.Time   `json:"expires_at" gorm:"not null"`
+	ID          string            `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	Token       string            `json:"token" gorm:"uniqueIndex;not null"`
+	Email       string            `json:"email" gorm:"not null"`
+	FirstName   string            `json:"first_name" gorm:"not null"`
+	LastName    string            `json:"last_name" gorm:"not null"`
+	Role        crypto.Role       `json:"role" gorm:"not null"`
+	Department  *string           `json:"department,omitempty"`                             // Only for teachers and department admins
+	TeacherUUID *string           `json:"teacher_uuid,omitempty" gorm:"type:uuid;index"`    // Required for students, NULL for teachers
+	AdminScope  crypto.AdminScope `json:"admin_scope,omitempty" gorm:"not null;default:''"` // Only for admins
+	Used        bool              `json:"used" gorm:"default:false;index"`
+	CreatedAt   time.Time         `json:"created_at" gorm:"default:CURRENT_TIMESTAMP"`
+	ExpiresAt   time.Time         `json:"expires_at" gorm:"not null"`
+
+	// Audit trail
+	CreatedBy   *string    `json:"created_by,omitempty" gorm:"type:uuid"` // Admin who created the invitation
+	UsedAt      *time.Time `json:"used_at,omitempty"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	RevokedBy   *string    `json:"revoked_by,omitempty" gorm:"type:uuid"`
+	ResentAt    *time.Time `json:"resent_at,omitempty"` // Last time a new token was issued
+	ResendCount int        `json:"resend_count" gorm:"not null;default:0"`
 }
 
 // TableName specifies the table name for GORM
@@ -69,9 +348,76 @@ func (i *Invitation) IsExpired() bool {
 	return time.Now().After(i.ExpiresAt)
 }
 
-// IsValid checks if the invitation is valid (not used and not expired)
+// IsRevoked checks if an admin revoked the invitation
+func (i *Invitation) IsRevoked() bool {
+	return i.RevokedAt != nil
+}
+
+// IsValid checks if the invitation is valid (not used, not revoked and not expired)
 func (i *Invitation) IsValid() bool {
-	return !i.Used && !i.IsExpired()
+	return !i.Used && !i.IsRevoked() && !i.IsExpired()
+}
+
+// Invitation statuses, derived from the used, revoked and expiry fields
+const (
+	InvitationStatusPending = "pending"
+	InvitationStatusUsed    = "used"
+	InvitationStatusExpired = "expired"
+	InvitationStatusRevoked = "revoked"
+)
+
+// Status returns the current status of the invitation
+func (i *Invitation) Status() string {
+	switch {
+	case i.Used:
+		return InvitationStatusUsed
+	case i.IsRevoked():
+		return InvitationStatusRevoked
+	case i.IsExpired():
+		return InvitationStatusExpired
+	default:
+		return InvitationStatusPending
+	}
+}
+
+// User statuses for filtering the user list
+const (
+	UserStatusActive   = "active"
+	UserStatusDisabled = "disabled"
+	UserStatusLocked   = "locked"
+)
+
+// UserFilter restricts user listings
+type UserFilter struct {
+	Role   string // One of the roles, empty for all
+	Status string // One of the UserStatus constants, empty for all
+	Search string // Case-insensitive part of the username, name or email
+}
+
+// UserSummary is a user account together with its student or teacher record
+// @Description User account for the admin overview
+type UserSummary struct {
+	User
+	FirstName   string  `json:"first_name,omitempty" example:"Max"`
+	LastName    string  `json:"last_name,omitempty" example:"Mustermann"`
+	Email       string  `json:"email,omitempty" example:"max@example.com"`
+	Department  string  `json:"department,omitempty" example:"IT"`                                     // Teachers only
+	TeacherUUID *string `json:"teacher_uuid,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"` // Students only
+}
+
+// OIDCIdentityFilter restricts OIDC identity listings
+type OIDCIdentityFilter struct {
+	Status string // One of the OIDCIdentityStatus constants, empty for all
+	UserID string // Identities linked to this user, empty for all
+}
+
+// InvitationFilter restricts invitation listings
+type InvitationFilter struct {
+	Status string // One of the InvitationStatus constants, empty for all
+	Email  string // Exact match, case-insensitive
+
+	// Department limits the invitations to teachers of the department and students of their teachers, for department admins
+	Department string
 }
 
 // DTO Models for API requests/responses
@@ -84,14 +430,159 @@ type LoginRequest struct {
 }
 
 // LoginResponse represents a successful login response
-// @Description Login response with tokens
+// @Description Login response with tokens. If mfa_required is set, only mfa_token is returned
+// @Description and must be exchanged for tokens via /api/v1/auth/mfa/verify
 type LoginResponse struct {
-	AccessToken  string `json:"access_token" example:"eyJhbGc..."`
-	RefreshToken string `json:"refresh_token" example:"eyJhbGc..."`
-	ExpiresIn    int64  `json:"expires_in" example:"3600"` // Seconds
-	TokenType    string `json:"token_type" example:"Bearer"`
-	Role         string `json:"role" example:"student"`
-	UserID       string `json:"user_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	AccessToken           string `json:"access_token,omitempty" example:"eyJhbGc..."`
+	RefreshToken          string `json:"refresh_token,omitempty" example:"eyJhbGc..."`
+	ExpiresIn             int64  `json:"expires_in" example:"3600"` // Seconds
+	TokenType             string `json:"token_type" example:"Bearer"`
+	Role                  string `json:"role" example:"student"`
+	UserID                string `json:"user_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	MFARequired           bool   `json:"mfa_required,omitempty" example:"false"`
+	MFAToken              string `json:"mfa_token,omitempty" example:"eyJhbGc..."`
+	MFAEnrollmentRequired bool   `json:"mfa_enrollment_required,omitempty" example:"false"` // Role requires 2FA but user has not enrolled yet
+}
+
+// MFAVerifyRequest exchanges a partial MFA token for access and refresh tokens
+// @Description Second factor verification
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfa_token" example:"eyJhbGc..." validate:"required"`
+	Code     string `json:"code" example:"123456" validate:"required"` // TOTP code or recovery code
+}
+
+// MFAEnrollResponse contains the TOTP secret for a new enrollment
+// @Description TOTP enrollment data for authenticator apps
+type MFAEnrollResponse struct {
+	Secret          string `json:"secret" example:"JBSWY3DPEHPK3PXP"`
+	ProvisioningURI string `json:"provisioning_uri" example:"otpauth://totp/FITS:anna.schmidt?secret=JBSWY3DPEHPK3PXP&issuer=FITS"`
+}
+
+// MFAConfirmRequest confirms a TOTP enrollment with a code from the authenticator app
+// @Description TOTP enrollment confirmation
+type MFAConfirmRequest struct {
+	Code string `json:"code" example:"123456" validate:"required,len=6,numeric"`
+}
+
+// MFAConfirmResponse returns the recovery codes after successful enrollment
+// @Description Recovery codes (shown only once) and tokens if enrollment completed a login
+type MFAConfirmResponse struct {
+	RecoveryCodes []string       `json:"recovery_codes" example:"abcde-12345,fghij-67890"`
+	Tokens        *LoginResponse `json:"tokens,omitempty"`
+}
+
+// MFADisableRequest disables two-factor authentication
+// @Description Password and current TOTP code are required to disable 2FA
+type MFADisableRequest struct {
+	Password string `json:"password" example:"SecurePassword123!" validate:"required"`
+	Code     string `json:"code" example:"123456" validate:"required"`
+}
+
+// ChangePasswordRequest changes the password of the current user
+// @Description Current and new password
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" example:"OldPassword123!" validate:"required"`
+	NewPassword     string `json:"new_password" example:"NewSecurePassword456!" validate:"required,min=8"`
+}
+
+// PasswordResetResponse contains a reset link issued by an admin
+// @Description Password reset link to hand over to the user
+type PasswordResetResponse struct {
+	ResetToken  string `json:"reset_token" example:"q83vEjRWeJBkR3Xy..."`
+	ResetLink   string `json:"reset_link" example:"https://fits.example.com/reset-password.html?token=q83vEjRWeJBkR3Xy..."`
+	ExpiresAt   string `json:"expires_at" example:"2025-10-25T12:00:00Z"`
+	EmailQueued bool   `json:"email_queued" example:"true"` // False if the user has no email address (e.g. admins)
+}
+
+// ResetPasswordRequest sets a new password with a reset token
+// @Description Reset token and new password
+type ResetPasswordRequest struct {
+	Token       string `json:"token" example:"q83vEjRWeJBkR3Xy..." validate:"required"`
+	NewPassword string `json:"new_password" example:"NewSecurePassword456!" validate:"required,min=8"`
+}
+
+// PasskeyBeginResponse starts a WebAuthn ceremony
+// @Description Options to pass to navigator.credentials.create() or navigator.credentials.get()
+type PasskeyBeginResponse struct {
+	SessionID string      `json:"session_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Options   interface{} `json:"options" swaggertype:"object"`
+}
+
+// PasskeyFinishRequest completes a WebAuthn ceremony
+// @Description Authenticator response for a started ceremony
+type PasskeyFinishRequest struct {
+	SessionID  string          `json:"session_id" example:"550e8400-e29b-41d4-a716-446655440000" validate:"required,uuid"`
+	Name       string          `json:"name,omitempty" example:"MacBook Touch ID" validate:"omitempty,max=100"` // Registration only
+	Credential json.RawMessage `json:"credential" swaggertype:"object" validate:"required"`                    // PublicKeyCredential serialized by the browser
+}
+
+// CreateAPIKeyRequest creates a service account API key
+// @Description Name, scopes and optional lifetime of the key
+type CreateAPIKeyRequest struct {
+	Name      string   `json:"name" example:"Prometheus" validate:"required,max=100"`
+	Scopes    []string `json:"scopes" example:"metrics:read" validate:"required,min=1"`
+	ExpiresIn string   `json:"expires_in,omitempty" example:"8760h"` // Go duration, the key never expires if empty
+}
+
+// CreateAPIKeyResponse contains a new API key
+// @Description The key is only returned once, store it in the service account's secret store
+type CreateAPIKeyResponse struct {
+	APIKey
+	Key string `json:"key" example:"fits_3f9a1c0b7d2e_Yk3n..."`
+}
+
+// OIDCProviderInfo describes the configured identity provider for the login page
+// @Description External identity provider
+type OIDCProviderInfo struct {
+	DisplayName string `json:"display_name" example:"School login"`
+}
+
+// OIDCBeginResponse starts a login at the external identity provider
+// @Description Redirect the browser to the authorization URL
+type OIDCBeginResponse struct {
+	AuthorizationURL string `json:"authorization_url" example:"https://keycloak.example.com/realms/school/protocol/openid-connect/auth?client_id=fits&code_challenge=..."`
+	ExpiresAt        string `json:"expires_at" example:"2025-10-18T12:10:00Z"` // The callback must arrive before this time
+}
+
+// OIDCCallbackRequest completes a login with the parameters the provider redirected back with
+// @Description Authorization response from the identity provider
+type OIDCCallbackRequest struct {
+	Code  string `json:"code" example:"SplxlOBeZQQYbYS6WxSbIA" validate:"required"`
+	State string `json:"state" example:"af0ifjsldkj" validate:"required"`
+}
+
+// LinkOIDCIdentityRequest links an identity to a user
+// @Description User to link the identity to
+type LinkOIDCIdentityRequest struct {
+	UserID string `json:"user_id" example:"550e8400-e29b-41d4-a716-446655440000" validate:"required,uuid"`
+}
+
+// ChangeUserRoleRequest changes the role of a user account
+// @Description New role and the student or teacher record the account belongs to
+type ChangeUserRoleRequest struct {
+	Role       string `json:"role" example:"teacher" validate:"required,oneof=admin teacher student"`
+	UserUUID   string `json:"user_uuid,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`               // Required for teachers and students, must be empty for admins
+	AdminScope string `json:"admin_scope,omitempty" example:"auditor" enums:"full,department,auditor,security"` // Required for admins
+	Department string `json:"department,omitempty" example:"IT"`                                                // Required for department admins
+}
+
+// UpdateProfileRequest changes the profile fields users may edit themselves
+// @Description Profile fields to change, omitted fields are left unchanged
+type UpdateProfileRequest struct {
+	DisplayName   *string                  `json:"display_name,omitempty" example:"Max M."` // Empty to use the name of the student or teacher record
+	Notifications *NotificationPreferences `json:"notifications,omitempty"`
+}
+
+// SessionResponse represents a login session without its refresh token
+// @Description Active login session (device)
+type SessionResponse struct {
+	ID         string     `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	UserAgent  string     `json:"user_agent" example:"Mozilla/5.0 (Windows NT 10.0; Win64; x64)"`
+	IPAddress  string     `json:"ip_address" example:"192.0.2.10"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	Current    bool       `json:"current" example:"true"` // Session of the token used for this request
 }
 
 // RefreshTokenRequest represents a token refresh request
@@ -116,6 +607,7 @@ type InvitationResponse struct {
 	Role        string  `json:"role" example:"student"`
 	Department  *string `json:"department,omitempty" example:"IT"`
 	TeacherUUID *string `json:"teacher_uuid,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	AdminScope  string  `json:"admin_scope,omitempty" example:"department"`
 	ExpiresAt   string  `json:"expires_at" example:"2025-10-25T12:00:00Z"`
 }
 
@@ -125,17 +617,49 @@ type CreateInvitationRequest struct {
 	Email       string  `json:"email" example:"max@example.com" validate:"required,email"`
 	FirstName   string  `json:"first_name" example:"Max" validate:"required,min=1,max=100"`
 	LastName    string  `json:"last_name" example:"Mustermann" validate:"required,min=1,max=100"`
-	Role        string  `json:"role" example:"student" validate:"required,oneof=student teacher"`
-	Department  *string `json:"department,omitempty" example:"IT" validate:"omitempty,min=1,max=100"`                            // Required for teachers
-	TeacherUUID *string `json:"teacher_uuid,omitempty" example:"550e8400-e29b-41d4-a716-446655440000" validate:"omitempty,uuid"` // Required for students
+	Role        string  `json:"role" example:"student" validate:"required,oneof=student teacher admin"`
+	Department  *string `json:"department,omitempty" example:"IT" validate:"omitempty,min=1,max=100"`                            // Required for teachers and department admins, optional for students to check against the teacher's department
+	TeacherUUID *string `json:"teacher_uuid,omitempty" example:"550e8400-e29b-41d4-a716-446655440000" validate:"omitempty,uuid"` // Required for students, must be an existing teacher
+	AdminScope  string  `json:"admin_scope,omitempty" example:"department" enums:"full,department,auditor,security"`             // Required for admins
+	Locale      string  `json:"locale,omitempty" example:"de"`                                                                   // Language of the invitation email, defaults to mail.default_locale
 }
 
 // CreateInvitationResponse represents the created invitation
 // @Description Created invitation with link
 type CreateInvitationResponse struct {
 	InvitationToken string `json:"invitation_token" example:"eyJhbGc..."`
-	InvitationLink  string `json:"invitation_link" example:"https://fits.example.com/invite/eyJhbGc..."`
+	InvitationLink  string `json:"invitation_link" example:"https://fits.example.com/invite.html?token=eyJhbGc..."`
 	ExpiresAt       string `json:"expires_at" example:"2025-10-25T12:00:00Z"`
+	EmailQueued     bool   `json:"email_queued" example:"true"`
+}
+
+// InvitationSummary represents an invitation in admin listings
+// The token is never listed; resending issues a new link
+// @Description Invitation with status and audit trail
+type InvitationSummary struct {
+	ID          string     `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Email       string     `json:"email" example:"max@example.com"`
+	FirstName   string     `json:"first_name" example:"Max"`
+	LastName    string     `json:"last_name" example:"Mustermann"`
+	Role        string     `json:"role" example:"student"`
+	Department  *string    `json:"department,omitempty" example:"IT"`
+	TeacherUUID *string    `json:"teacher_uuid,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	AdminScope  string     `json:"admin_scope,omitempty" example:"department"`
+	Status      string     `json:"status" example:"pending" enums:"pending,used,expired,revoked"`
+	CreatedBy   *string    `json:"created_by,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	UsedAt      *time.Time `json:"used_at,omitempty"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	RevokedBy   *string    `json:"revoked_by,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	ResentAt    *time.Time `json:"resent_at,omitempty"`
+	ResendCount int        `json:"resend_count" example:"0"`
+}
+
+// PurgeInvitationsResponse reports how many expired invitations were deleted
+// @Description Result of purging expired invitations
+type PurgeInvitationsResponse struct {
+	Deleted int64 `json:"deleted" example:"12"`
 }
 
 // BootstrapResponse represents the bootstrap initialization response
@@ -145,3 +669,27 @@ type BootstrapResponse struct {
 	Message       string `json:"message" example:"Admin certificate generated successfully"`
 	PublicKeyPath string `json:"public_key_path" example:"./configs/keys/admin.pub"`
 }
+
+// BulkInvitationReport reports the outcome of a roster upload per row
+// @Description Result of a bulk invitation upload
+type BulkInvitationReport struct {
+	DryRun  bool                      `json:"dry_run" example:"false"`
+	Total   int                       `json:"total" example:"120"`
+	Valid   int                       `json:"valid" example:"118"`
+	Created int                       `json:"created" example:"0"`
+	Failed  int                       `json:"failed" example:"2"`
+	Rows    []BulkInvitationRowResult `json:"rows"`
+}
+
+// BulkInvitationRowResult is the validation or creation result of a single roster row
+// @Description Result of a single roster row
+type BulkInvitationRowResult struct {
+	Row            int      `json:"row" example:"2"`
+	Email          string   `json:"email" example:"max@example.com"`
+	Role           string   `json:"role" example:"student"`
+	Status         string   `json:"status" example:"created" enums:"valid,created,error"`
+	Errors         []string `json:"errors,omitempty"`
+	InvitationLink string   `json:"invitation_link,omitempty" example:"https://fits.example.com/invite.html?token=eyJhbGc..."`
+	ExpiresAt      string   `json:"expires_at,omitempty" example:"2025-10-25T12:00:00Z"`
+	EmailQueued    bool     `json:"email_queued,omitempty" example:"true"`
+}
@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/JustDoItBetter/FITS-backend/pkg/mailer"
+)
+
+// Notifier builds the links handed out to users and queues the emails carrying them
+type Notifier struct {
+	baseURL string
+	mail    *mailer.Mailer // nil disables emails, the links are only returned to the admin
+}
+
+// NewNotifier creates a notifier for the frontend at baseURL (without trailing slash)
+func NewNotifier(baseURL string, mail *mailer.Mailer) *Notifier {
+	return &Notifier{
+		baseURL: baseURL,
+		mail:    mail,
+	}
+}
+
+// enabled reports whether emails are sent
+func (n *Notifier) enabled() bool {
+	return n.mail != nil
+}
+
+// invitationLink returns the registration page link for an invitation token
+func (n *Notifier) invitationLink(token string) string {
+	return fmt.Sprintf("%s/invite.html?token=%s", n.baseURL, url.QueryEscape(token))
+}
+
+// passwordResetLink returns the reset page link for a password reset token
+func (n *Notifier) passwordResetLink(token string) string {
+	return fmt.Sprintf("%s/reset-password.html?token=%s", n.baseURL, url.QueryEscape(token))
+}
+
+// queueInvitation stores the invitation email in the outbox
+// repo should be the transaction that creates or reissues the invitation, so the email
+// is only sent if the invitation was committed. Returns whether an email was queued
+func (n *Notifier) queueInvitation(ctx context.Context, repo Repository, invitation *Invitation, locale string) (bool, error) {
+	if !n.enabled() {
+		return false, nil
+	}
+
+	msg, err := n.mail.Compose(invitation.Email, mailer.TemplateInvitation, locale, mailer.InvitationData{
+		FirstName: invitation.FirstName,
+		LastName:  invitation.LastName,
+		Role:      string(invitation.Role),
+		Link:      n.invitationLink(invitation.Token),
+		ExpiresAt: invitation.ExpiresAt,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to compose invitation email: %w", err)
+	}
+
+	if err := repo.EnqueueEmail(ctx, msg); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// queuePasswordReset stores the password reset email in the outbox
+// Returns whether an email was queued
+func (n *Notifier) queuePasswordReset(ctx context.Context, repo Repository, user *User, contact *UserContact, token string, expiresAt time.Time) (bool, error) {
+	if !n.enabled() || contact == nil {
+		return false, nil
+	}
+
+	msg, err := n.mail.Compose(contact.Email, mailer.TemplatePasswordReset, "", mailer.PasswordResetData{
+		FirstName: contact.FirstName,
+		LastName:  contact.LastName,
+		Username:  user.Username,
+		Link:      n.passwordResetLink(token),
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to compose password reset email: %w", err)
+	}
+
+	if err := repo.EnqueueEmail(ctx, msg); err != nil {
+		return false, err
+	}
+	return true, nil
+}
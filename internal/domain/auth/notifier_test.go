@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/reference"
+	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
+	"github.com/JustDoItBetter/FITS-backend/pkg/mailer"
+)
+
+// newMailingNotifier returns a notifier that queues emails
+func newMailingNotifier(t *testing.T) *Notifier {
+	mail, err := mailer.New("FITS <noreply@fits.example.com>", "en")
+	require.NoError(t, err)
+	return NewNotifier("https://fits.example.com", mail)
+}
+
+func TestNotifier_Links(t *testing.T) {
+	notifier := getTestNotifier()
+
+	assert.Equal(t, "https://fits.example.com/invite.html?token=a%2Bb", notifier.invitationLink("a+b"))
+	assert.Equal(t, "https://fits.example.com/reset-password.html?token=abc", notifier.passwordResetLink("abc"))
+}
+
+func TestInvitationService_CreateInvitation_QueuesEmail(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewInvitationService(mockRepo, crypto.NewJWTService("test-secret"), getTestJWTConfig(), newMailingNotifier(t), reference.Rules{})
+	mockAdmin(mockRepo, ctx, "admin-1", crypto.AdminClaims{AdminScope: crypto.AdminScopeFull})
+	mockRepo.On("HasOpenInvitation", ctx, "max@example.com").Return(false, nil)
+	mockRepo.On("CreateInvitation", ctx, mock.AnythingOfType("*auth.Invitation")).Return(nil)
+	mockRepo.On("EnqueueEmail", ctx, mock.AnythingOfType("*mailer.OutboxMessage")).Return(nil)
+
+	resp, err := service.CreateInvitation(ctx, &CreateInvitationRequest{
+		Email:      "max@example.com",
+		FirstName:  "Max",
+		LastName:   "Mustermann",
+		Role:       "teacher",
+		Department: stringPtr("IT"),
+		Locale:     "de",
+	}, "admin-1")
+
+	require.NoError(t, err)
+	assert.True(t, resp.EmailQueued)
+	assert.Equal(t, "https://fits.example.com/invite.html?token="+resp.InvitationToken, resp.InvitationLink)
+
+	msg := mockRepo.Calls[3].Arguments.Get(1).(*mailer.OutboxMessage)
+	assert.Equal(t, mailer.TemplateInvitation, msg.Template)
+	assert.Equal(t, "max@example.com", msg.Recipient)
+	assert.Equal(t, "Ihre Einladung zu FITS", msg.Subject)
+	assert.Contains(t, msg.TextBody, resp.InvitationLink)
+}
+
+func TestAuthService_CreatePasswordReset_QueuesEmail(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("emails the linked student or teacher", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewAuthService(mockRepo, crypto.NewJWTService("test-secret"), getTestJWTConfig(), getTestSecurityConfig(), newMailingNotifier(t))
+		user := &User{ID: "user-123", Username: "max.mustermann", Role: crypto.RoleStudent, UserUUID: stringPtr("student-1")}
+		mockRepo.On("GetUserByID", ctx, user.ID).Return(user, nil)
+		mockRepo.On("GetUserContact", ctx, "student-1").Return(&UserContact{FirstName: "Max", LastName: "Mustermann", Email: "max@example.com"}, nil)
+		mockRepo.On("CreatePasswordResetToken", ctx, mock.AnythingOfType("*auth.PasswordResetToken")).Return(nil)
+		mockRepo.On("EnqueueEmail", ctx, mock.AnythingOfType("*mailer.OutboxMessage")).Return(nil)
+
+		resp, err := service.CreatePasswordReset(ctx, user.ID, "admin-1")
+
+		require.NoError(t, err)
+		assert.True(t, resp.EmailQueued)
+
+		msg := mockRepo.Calls[3].Arguments.Get(1).(*mailer.OutboxMessage)
+		assert.Equal(t, mailer.TemplatePasswordReset, msg.Template)
+		assert.Equal(t, "max@example.com", msg.Recipient)
+		assert.Contains(t, msg.TextBody, resp.ResetLink)
+		assert.Contains(t, msg.TextBody, "max.mustermann")
+	})
+
+	t.Run("returns only the link for accounts without contact", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewAuthService(mockRepo, crypto.NewJWTService("test-secret"), getTestJWTConfig(), getTestSecurityConfig(), newMailingNotifier(t))
+		admin := &User{ID: "admin-1", Username: "admin", Role: crypto.RoleAdmin}
+		mockRepo.On("GetUserByID", ctx, admin.ID).Return(admin, nil)
+		mockAdmin(mockRepo, ctx, "admin-2", crypto.AdminClaims{AdminScope: crypto.AdminScopeFull})
+		mockRepo.On("CreatePasswordResetToken", ctx, mock.AnythingOfType("*auth.PasswordResetToken")).Return(nil)
+
+		resp, err := service.CreatePasswordReset(ctx, admin.ID, "admin-2")
+
+		require.NoError(t, err)
+		assert.False(t, resp.EmailQueued)
+		assert.NotEmpty(t, resp.ResetLink)
+		mockRepo.AssertNotCalled(t, "GetUserContact", mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "EnqueueEmail", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,306 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/errors"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/pagination"
+	"github.com/JustDoItBetter/FITS-backend/internal/config"
+	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
+	"github.com/JustDoItBetter/FITS-backend/pkg/logger"
+)
+
+const (
+	// OIDCLoginTimeout is how long the callback of a started login is accepted
+	OIDCLoginTimeout = 10 * time.Minute
+	// oidcDiscoveryTimeout bounds fetching the provider's discovery document
+	oidcDiscoveryTimeout = 10 * time.Second
+)
+
+// oidcClaims are the ID token claims used to identify and link the user
+type oidcClaims struct {
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Name          string `json:"name"`
+}
+
+// OIDCService handles login through an external OpenID Connect identity provider
+// using the authorization code flow with PKCE. Subjects at the provider are linked
+// to existing users, either automatically through the email of the invitation the
+// user registered with or by an admin, and a linked login issues regular FITS tokens
+type OIDCService struct {
+	repo        Repository
+	authService *AuthService
+	cfg         *config.OIDCConfig
+	redirectURL string
+
+	// The provider is discovered on first use so the server starts while the provider is unreachable
+	mu       sync.Mutex
+	oauth2   *oauth2.Config
+	verifier *oidc.IDTokenVerifier
+}
+
+// NewOIDCService creates a new OpenID Connect login service
+func NewOIDCService(repo Repository, authService *AuthService, cfg *config.OIDCConfig, publicBaseURL string) *OIDCService {
+	return &OIDCService{
+		repo:        repo,
+		authService: authService,
+		cfg:         cfg,
+		redirectURL: cfg.GetRedirectURL(publicBaseURL),
+	}
+}
+
+// client returns the OAuth2 client and ID token verifier, discovering the provider if necessary
+func (s *OIDCService) client(ctx context.Context) (*oauth2.Config, *oidc.IDTokenVerifier, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.oauth2 != nil {
+		return s.oauth2, s.verifier, nil
+	}
+
+	discoveryCtx, cancel := context.WithTimeout(ctx, oidcDiscoveryTimeout)
+	defer cancel()
+
+	provider, err := oidc.NewProvider(discoveryCtx, s.cfg.IssuerURL)
+	if err != nil {
+		logger.Error("OIDC provider discovery failed",
+			zap.String("issuer", s.cfg.IssuerURL),
+			zap.Error(err),
+		)
+		return nil, nil, errors.NewAppError(http.StatusServiceUnavailable, "Service Unavailable", "identity provider is not reachable")
+	}
+
+	s.oauth2 = &oauth2.Config{
+		ClientID:     s.cfg.ClientID,
+		ClientSecret: s.cfg.ClientSecret,
+		RedirectURL:  s.redirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       s.cfg.GetScopes(),
+	}
+	s.verifier = provider.Verifier(&oidc.Config{ClientID: s.cfg.ClientID})
+
+	return s.oauth2, s.verifier, nil
+}
+
+// ProviderInfo describes the provider for the login page
+func (s *OIDCService) ProviderInfo() *OIDCProviderInfo {
+	return &OIDCProviderInfo{DisplayName: s.cfg.GetDisplayName()}
+}
+
+// BeginLogin stores a new login state and returns the URL to redirect the browser to
+func (s *OIDCService) BeginLogin(ctx context.Context) (*OIDCBeginResponse, error) {
+	oauth2Config, _, err := s.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := crypto.GenerateSecureToken(32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate login state: %w", err)
+	}
+	nonce, err := crypto.GenerateSecureToken(32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	// Only the hash of the state is stored, it travels through the browser like a token
+	loginState := &OIDCLoginState{
+		StateHash:    crypto.HashString(state),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(OIDCLoginTimeout),
+	}
+	if err := s.repo.CreateOIDCLoginState(ctx, loginState); err != nil {
+		return nil, err
+	}
+
+	return &OIDCBeginResponse{
+		AuthorizationURL: oauth2Config.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier)),
+		ExpiresAt:        loginState.ExpiresAt.UTC().Format(time.RFC3339),
+	}, nil
+}
+
+// FinishLogin exchanges the authorization code, verifies the ID token and issues tokens
+// for the linked user. Subjects that can't be linked are recorded for an admin to approve.
+// Users with MFA receive the same challenge as after a password login
+func (s *OIDCService) FinishLogin(ctx context.Context, req *OIDCCallbackRequest, client ClientInfo) (*LoginResponse, error) {
+	if req.Code == "" || req.State == "" {
+		return nil, errors.BadRequest("code and state are required")
+	}
+
+	state, err := s.repo.ConsumeOIDCLoginState(ctx, crypto.HashString(req.State))
+	if err != nil || state.IsExpired() {
+		return nil, errors.BadRequest("invalid or expired login state")
+	}
+
+	oauth2Config, verifier, err := s.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := oauth2Config.Exchange(ctx, req.Code, oauth2.VerifierOption(state.CodeVerifier))
+	if err != nil {
+		logger.Debug("OIDC code exchange failed", zap.Error(err))
+		return nil, errors.Unauthorized("login at identity provider failed")
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, errors.Unauthorized("identity provider returned no ID token")
+	}
+
+	idToken, err := verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		logger.Debug("OIDC ID token verification failed", zap.Error(err))
+		return nil, errors.Unauthorized("login at identity provider failed")
+	}
+	if subtle.ConstantTimeCompare([]byte(idToken.Nonce), []byte(state.Nonce)) != 1 {
+		return nil, errors.Unauthorized("login at identity provider failed")
+	}
+
+	var claims oidcClaims
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, errors.Unauthorized("invalid ID token claims")
+	}
+
+	identity, err := s.repo.GetOIDCIdentity(ctx, idToken.Issuer, idToken.Subject)
+	if appErr, ok := err.(*errors.AppError); ok && appErr.Code == 404 {
+		identity, err = s.createIdentity(ctx, idToken, &claims)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	identity.Email = claims.Email
+	identity.Name = claims.Name
+	identity.LastLoginAt = &now
+	if err := s.repo.UpdateOIDCIdentityProfile(ctx, identity); err != nil {
+		return nil, err
+	}
+
+	if identity.Status != OIDCIdentityStatusLinked || identity.UserID == nil {
+		logger.Info("OIDC login awaiting admin approval",
+			zap.String("identity_id", identity.ID),
+			zap.String("subject", identity.Subject),
+		)
+		return nil, errors.NewAppError(http.StatusForbidden, "Forbidden",
+			"your account at the identity provider is not linked yet, please ask an administrator to approve it")
+	}
+
+	user, err := s.repo.GetUserByID(ctx, *identity.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.IsLocked() {
+		return nil, errors.TooManyRequests("account temporarily locked due to too many failed login attempts")
+	}
+	if user.IsDisabled() {
+		return nil, errAccountDisabled()
+	}
+
+	// The identity provider replaces the password, not the second factor
+	if user.MFAEnabled || s.authService.securityConfig.RequiresMFA(string(user.Role)) {
+		return s.authService.mfaChallenge(user)
+	}
+
+	return s.authService.issueTokens(ctx, user, client)
+}
+
+// createIdentity records a subject seen for the first time
+// It is linked right away if the verified email belongs to exactly one account registered with an invitation
+func (s *OIDCService) createIdentity(ctx context.Context, idToken *oidc.IDToken, claims *oidcClaims) (*OIDCIdentity, error) {
+	identity := &OIDCIdentity{
+		Issuer:  idToken.Issuer,
+		Subject: idToken.Subject,
+		Status:  OIDCIdentityStatusPending,
+		Email:   claims.Email,
+		Name:    claims.Name,
+	}
+
+	if s.cfg.AutoLinkByInvitation && claims.EmailVerified && claims.Email != "" {
+		user, err := s.repo.GetUserByInvitationEmail(ctx, claims.Email)
+		if err != nil {
+			return nil, err
+		}
+		if user != nil {
+			now := time.Now()
+			identity.UserID = &user.ID
+			identity.Status = OIDCIdentityStatusLinked
+			identity.LinkedAt = &now
+		}
+	}
+
+	if err := s.repo.CreateOIDCIdentity(ctx, identity); err != nil {
+		return nil, err
+	}
+
+	logger.Info("OIDC identity created",
+		zap.String("identity_id", identity.ID),
+		zap.String("issuer", identity.Issuer),
+		zap.String("status", identity.Status),
+	)
+
+	return identity, nil
+}
+
+// ListIdentities returns the identities for the admin overview
+func (s *OIDCService) ListIdentities(ctx context.Context, filter OIDCIdentityFilter, params pagination.Params) ([]OIDCIdentity, int64, error) {
+	switch filter.Status {
+	case "", OIDCIdentityStatusPending, OIDCIdentityStatusLinked:
+	default:
+		return nil, 0, errors.ValidationError("status must be one of pending, linked")
+	}
+
+	return s.repo.ListOIDCIdentities(ctx, filter, params)
+}
+
+// LinkIdentity approves a pending identity or moves a linked one to another user
+// actorID is the admin linking the identity, recorded for the audit trail
+func (s *OIDCService) LinkIdentity(ctx context.Context, id string, req *LinkOIDCIdentityRequest, actorID string) (*OIDCIdentity, error) {
+	if req.UserID == "" {
+		return nil, errors.ValidationError("user_id is required")
+	}
+
+	user, err := s.repo.GetUserByID(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	// Linking an identity to an admin lets whoever controls the identity log in as that admin
+	if err := requireAdminManager(ctx, s.repo, actorID, user); err != nil {
+		return nil, err
+	}
+
+	var linkedBy *string
+	if actorID != "" {
+		linkedBy = &actorID
+	}
+	if err := s.repo.LinkOIDCIdentity(ctx, id, user.ID, linkedBy); err != nil {
+		return nil, err
+	}
+
+	logger.Info("OIDC identity linked",
+		zap.String("identity_id", id),
+		zap.String("user_id", user.ID),
+		zap.String("linked_by", actorID),
+	)
+
+	return s.repo.GetOIDCIdentityByID(ctx, id)
+}
+
+// DeleteIdentity removes an identity - the subject is recorded as pending again at its next login
+func (s *OIDCService) DeleteIdentity(ctx context.Context, id string) error {
+	return s.repo.DeleteOIDCIdentity(ctx, id)
+}
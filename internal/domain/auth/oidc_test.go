@@ -0,0 +1,351 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/coreos/go-oidc/v3/oidc/oidctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/errors"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/pagination"
+	"github.com/JustDoItBetter/FITS-backend/internal/config"
+	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
+)
+
+const testOIDCClientID = "fits"
+
+// fakeOIDCProvider is an in-process identity provider
+// It serves discovery and keys through oidctest and implements a token endpoint that checks PKCE
+type fakeOIDCProvider struct {
+	*httptest.Server
+	key *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]fakeAuthorization
+}
+
+// fakeAuthorization is a code issued by the provider, waiting to be exchanged
+type fakeAuthorization struct {
+	challenge string
+	claims    map[string]interface{}
+}
+
+func newFakeOIDCProvider(t *testing.T) *fakeOIDCProvider {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	p := &fakeOIDCProvider{key: key, codes: make(map[string]fakeAuthorization)}
+	discovery := &oidctest.Server{
+		PublicKeys: []oidctest.PublicKey{{PublicKey: key.Public(), KeyID: "test-key", Algorithm: oidc.RS256}},
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/", discovery)
+	mux.HandleFunc("/token", p.serveToken)
+	p.Server = httptest.NewServer(mux)
+	discovery.SetIssuer(p.URL)
+	t.Cleanup(p.Close)
+
+	return p
+}
+
+// authorize plays the browser and the provider's login page: it checks the authorization
+// request and returns the code and state the provider redirects back with
+func (p *fakeOIDCProvider) authorize(t *testing.T, authorizationURL, subject string, claims map[string]interface{}) *OIDCCallbackRequest {
+	t.Helper()
+
+	u, err := url.Parse(authorizationURL)
+	require.NoError(t, err)
+	query := u.Query()
+	require.Equal(t, p.URL+"/auth", u.Scheme+"://"+u.Host+u.Path)
+	require.Equal(t, "code", query.Get("response_type"))
+	require.Equal(t, testOIDCClientID, query.Get("client_id"))
+	require.Equal(t, "S256", query.Get("code_challenge_method"))
+	require.Contains(t, query.Get("scope"), "openid")
+
+	idClaims := map[string]interface{}{
+		"iss":   p.URL,
+		"aud":   testOIDCClientID,
+		"sub":   subject,
+		"nonce": query.Get("nonce"),
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}
+	for k, v := range claims {
+		idClaims[k] = v
+	}
+
+	code, err := crypto.GenerateSecureToken(16)
+	require.NoError(t, err)
+
+	p.mu.Lock()
+	p.codes[code] = fakeAuthorization{challenge: query.Get("code_challenge"), claims: idClaims}
+	p.mu.Unlock()
+
+	return &OIDCCallbackRequest{Code: code, State: query.Get("state")}
+}
+
+func (p *fakeOIDCProvider) serveToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		http.Error(w, `{"error":"invalid_request"}`, http.StatusBadRequest)
+		return
+	}
+
+	p.mu.Lock()
+	authorization, ok := p.codes[r.PostForm.Get("code")]
+	delete(p.codes, r.PostForm.Get("code"))
+	p.mu.Unlock()
+
+	verifierHash := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(verifierHash[:]) != authorization.challenge {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+		return
+	}
+
+	claims, _ := json.Marshal(authorization.claims)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token": "provider-access-token",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     oidctest.SignIDToken(p.key, "test-key", oidc.RS256, string(claims)),
+	})
+}
+
+type oidcTestEnv struct {
+	ctx      context.Context
+	repo     *MockRepository
+	provider *fakeOIDCProvider
+	service  *OIDCService
+	user     *User
+	states   map[string]*OIDCLoginState
+}
+
+func newOIDCTestEnv(t *testing.T, autoLink bool) *oidcTestEnv {
+	t.Helper()
+
+	env := &oidcTestEnv{
+		ctx:      context.Background(),
+		repo:     new(MockRepository),
+		provider: newFakeOIDCProvider(t),
+		user:     &User{ID: "550e8400-e29b-41d4-a716-446655440000", Username: "max.mustermann", Role: crypto.RoleStudent},
+		states:   make(map[string]*OIDCLoginState),
+	}
+
+	authService := NewAuthService(env.repo, crypto.NewJWTService("test-secret"), getTestJWTConfig(), getTestSecurityConfig(), getTestNotifier())
+	env.service = NewOIDCService(env.repo, authService, &config.OIDCConfig{
+		Enabled:              true,
+		IssuerURL:            env.provider.URL,
+		ClientID:             testOIDCClientID,
+		AutoLinkByInvitation: autoLink,
+	}, "https://fits.example.com")
+
+	env.repo.On("CreateOIDCLoginState", env.ctx, mock.AnythingOfType("*auth.OIDCLoginState")).
+		Run(func(args mock.Arguments) {
+			state := args.Get(1).(*OIDCLoginState)
+			env.states[state.StateHash] = state
+		}).Return(nil)
+	env.repo.On("GetUserByID", env.ctx, env.user.ID).Return(env.user, nil)
+	env.repo.On("UpdateOIDCIdentityProfile", env.ctx, mock.AnythingOfType("*auth.OIDCIdentity")).Return(nil)
+	env.repo.On("CreateRefreshToken", env.ctx, mock.AnythingOfType("*auth.RefreshToken")).Return(nil)
+	env.repo.On("UpdateLastLogin", env.ctx, env.user.ID).Return(nil)
+
+	return env
+}
+
+// login runs a complete login of the subject with the given ID token claims
+func (env *oidcTestEnv) login(t *testing.T, subject string, claims map[string]interface{}) (*LoginResponse, error) {
+	t.Helper()
+
+	begin, err := env.service.BeginLogin(env.ctx)
+	require.NoError(t, err)
+
+	callback := env.provider.authorize(t, begin.AuthorizationURL, subject, claims)
+	stateHash := crypto.HashString(callback.State)
+	require.Contains(t, env.states, stateHash, "only the hash of the state is stored")
+	env.repo.On("ConsumeOIDCLoginState", env.ctx, stateHash).Return(env.states[stateHash], nil).Once()
+
+	return env.service.FinishLogin(env.ctx, callback, ClientInfo{IPAddress: "192.0.2.1"})
+}
+
+func assertAppErrorCode(t *testing.T, err error, code int) {
+	t.Helper()
+
+	require.Error(t, err)
+	require.IsType(t, &errors.AppError{}, err)
+	assert.Equal(t, code, err.(*errors.AppError).Code)
+}
+
+func TestOIDCService_FinishLogin(t *testing.T) {
+	t.Run("linked identity receives tokens", func(t *testing.T) {
+		env := newOIDCTestEnv(t, false)
+		identity := &OIDCIdentity{ID: "identity-1", Issuer: env.provider.URL, Subject: "sub-1", Status: OIDCIdentityStatusLinked, UserID: &env.user.ID}
+		env.repo.On("GetOIDCIdentity", env.ctx, env.provider.URL, "sub-1").Return(identity, nil)
+
+		resp, err := env.login(t, "sub-1", map[string]interface{}{"email": "max@example.com", "name": "Max Mustermann"})
+
+		require.NoError(t, err)
+		assert.Equal(t, env.user.ID, resp.UserID)
+		assert.NotEmpty(t, resp.AccessToken)
+		assert.Equal(t, "max@example.com", identity.Email)
+		assert.NotNil(t, identity.LastLoginAt)
+	})
+
+	t.Run("user with MFA receives a challenge", func(t *testing.T) {
+		env := newOIDCTestEnv(t, false)
+		env.user.MFAEnabled = true
+		identity := &OIDCIdentity{ID: "identity-1", Issuer: env.provider.URL, Subject: "sub-1", Status: OIDCIdentityStatusLinked, UserID: &env.user.ID}
+		env.repo.On("GetOIDCIdentity", env.ctx, env.provider.URL, "sub-1").Return(identity, nil)
+
+		resp, err := env.login(t, "sub-1", nil)
+
+		require.NoError(t, err)
+		assert.True(t, resp.MFARequired)
+		assert.NotEmpty(t, resp.MFAToken)
+		assert.False(t, resp.MFAEnrollmentRequired)
+		assert.Empty(t, resp.AccessToken)
+		env.repo.AssertNotCalled(t, "CreateRefreshToken", mock.Anything, mock.Anything)
+	})
+
+	t.Run("role requiring MFA must enroll", func(t *testing.T) {
+		env := newOIDCTestEnv(t, false)
+		env.service.authService.securityConfig.MFARequiredRoles = []string{string(crypto.RoleStudent)}
+		identity := &OIDCIdentity{ID: "identity-1", Issuer: env.provider.URL, Subject: "sub-1", Status: OIDCIdentityStatusLinked, UserID: &env.user.ID}
+		env.repo.On("GetOIDCIdentity", env.ctx, env.provider.URL, "sub-1").Return(identity, nil)
+
+		resp, err := env.login(t, "sub-1", nil)
+
+		require.NoError(t, err)
+		assert.True(t, resp.MFARequired)
+		assert.True(t, resp.MFAEnrollmentRequired)
+		assert.Empty(t, resp.AccessToken)
+		env.repo.AssertNotCalled(t, "CreateRefreshToken", mock.Anything, mock.Anything)
+	})
+
+	t.Run("verified invitation email links automatically", func(t *testing.T) {
+		env := newOIDCTestEnv(t, true)
+		env.repo.On("GetOIDCIdentity", env.ctx, env.provider.URL, "sub-2").Return(nil, errors.NotFound("oidc identity"))
+		env.repo.On("GetUserByInvitationEmail", env.ctx, "max@example.com").Return(env.user, nil)
+		env.repo.On("CreateOIDCIdentity", env.ctx, mock.AnythingOfType("*auth.OIDCIdentity")).Return(nil)
+
+		resp, err := env.login(t, "sub-2", map[string]interface{}{"email": "max@example.com", "email_verified": true})
+
+		require.NoError(t, err)
+		assert.Equal(t, env.user.ID, resp.UserID)
+		created := env.repo.Calls[4].Arguments.Get(1).(*OIDCIdentity)
+		assert.Equal(t, OIDCIdentityStatusLinked, created.Status)
+		assert.Equal(t, &env.user.ID, created.UserID)
+		assert.Nil(t, created.LinkedBy)
+	})
+
+	t.Run("unverified email awaits admin approval", func(t *testing.T) {
+		env := newOIDCTestEnv(t, true)
+		env.repo.On("GetOIDCIdentity", env.ctx, env.provider.URL, "sub-3").Return(nil, errors.NotFound("oidc identity"))
+		env.repo.On("CreateOIDCIdentity", env.ctx, mock.AnythingOfType("*auth.OIDCIdentity")).Return(nil)
+
+		_, err := env.login(t, "sub-3", map[string]interface{}{"email": "max@example.com", "email_verified": false})
+
+		assertAppErrorCode(t, err, 403)
+		env.repo.AssertNotCalled(t, "GetUserByInvitationEmail", mock.Anything, mock.Anything)
+		env.repo.AssertNotCalled(t, "CreateRefreshToken", mock.Anything, mock.Anything)
+	})
+
+	t.Run("pending identity can't log in", func(t *testing.T) {
+		env := newOIDCTestEnv(t, true)
+		identity := &OIDCIdentity{ID: "identity-4", Issuer: env.provider.URL, Subject: "sub-4", Status: OIDCIdentityStatusPending}
+		env.repo.On("GetOIDCIdentity", env.ctx, env.provider.URL, "sub-4").Return(identity, nil)
+
+		_, err := env.login(t, "sub-4", nil)
+
+		assertAppErrorCode(t, err, 403)
+		env.repo.AssertNotCalled(t, "CreateOIDCIdentity", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects unknown state", func(t *testing.T) {
+		env := newOIDCTestEnv(t, false)
+		env.repo.On("ConsumeOIDCLoginState", env.ctx, crypto.HashString("forged")).Return(nil, errors.NotFound("oidc login state"))
+
+		_, err := env.service.FinishLogin(env.ctx, &OIDCCallbackRequest{Code: "code", State: "forged"}, ClientInfo{})
+
+		assertAppErrorCode(t, err, 400)
+	})
+
+	t.Run("rejects wrong PKCE verifier", func(t *testing.T) {
+		env := newOIDCTestEnv(t, false)
+		begin, err := env.service.BeginLogin(env.ctx)
+		require.NoError(t, err)
+		callback := env.provider.authorize(t, begin.AuthorizationURL, "sub-1", nil)
+		state := *env.states[crypto.HashString(callback.State)]
+		state.CodeVerifier = "a-verifier-the-attacker-does-not-know-abcdefghijklmnop"
+		env.repo.On("ConsumeOIDCLoginState", env.ctx, state.StateHash).Return(&state, nil)
+
+		_, err = env.service.FinishLogin(env.ctx, callback, ClientInfo{})
+
+		assertAppErrorCode(t, err, 401)
+	})
+
+	t.Run("rejects replayed nonce", func(t *testing.T) {
+		env := newOIDCTestEnv(t, false)
+		begin, err := env.service.BeginLogin(env.ctx)
+		require.NoError(t, err)
+		callback := env.provider.authorize(t, begin.AuthorizationURL, "sub-1", map[string]interface{}{"nonce": "from-another-login"})
+		stateHash := crypto.HashString(callback.State)
+		env.repo.On("ConsumeOIDCLoginState", env.ctx, stateHash).Return(env.states[stateHash], nil)
+
+		_, err = env.service.FinishLogin(env.ctx, callback, ClientInfo{})
+
+		assertAppErrorCode(t, err, 401)
+		env.repo.AssertNotCalled(t, "GetOIDCIdentity", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestOIDCService_Unreachable(t *testing.T) {
+	service := NewOIDCService(new(MockRepository), nil, &config.OIDCConfig{
+		IssuerURL: "http://127.0.0.1:1",
+		ClientID:  testOIDCClientID,
+	}, "https://fits.example.com")
+
+	_, err := service.BeginLogin(context.Background())
+
+	assertAppErrorCode(t, err, 503)
+}
+
+func TestOIDCService_LinkIdentity(t *testing.T) {
+	env := newOIDCTestEnv(t, false)
+	linked := &OIDCIdentity{ID: "identity-1", Status: OIDCIdentityStatusLinked, UserID: &env.user.ID}
+	adminID := "admin-1"
+	env.repo.On("LinkOIDCIdentity", env.ctx, "identity-1", env.user.ID, &adminID).Return(nil)
+	env.repo.On("GetOIDCIdentityByID", env.ctx, "identity-1").Return(linked, nil)
+	env.repo.On("GetUserByID", env.ctx, "missing").Return(nil, errors.NotFound("user"))
+
+	identity, err := env.service.LinkIdentity(env.ctx, "identity-1", &LinkOIDCIdentityRequest{UserID: env.user.ID}, adminID)
+	require.NoError(t, err)
+	assert.Equal(t, linked, identity)
+
+	_, err = env.service.LinkIdentity(env.ctx, "identity-1", &LinkOIDCIdentityRequest{UserID: "missing"}, adminID)
+	assertAppErrorCode(t, err, 404)
+
+	_, err = env.service.LinkIdentity(env.ctx, "identity-1", &LinkOIDCIdentityRequest{}, adminID)
+	assertAppErrorCode(t, err, 422)
+
+	_, _, err = env.service.ListIdentities(env.ctx, OIDCIdentityFilter{Status: "approved"}, pagination.Params{})
+	assertAppErrorCode(t, err, 422)
+}
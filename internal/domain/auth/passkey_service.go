@@ -0,0 +1,326 @@
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/errors"
+	"github.com/JustDoItBetter/FITS-backend/internal/config"
+	"github.com/JustDoItBetter/FITS-backend/pkg/logger"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"go.uber.org/zap"
+)
+
+const (
+	// PasskeyCeremonyTimeout is how long a started registration or login can be completed
+	PasskeyCeremonyTimeout = 5 * time.Minute
+	// DefaultPasskeyName is used when the user does not name a new passkey
+	DefaultPasskeyName = "Passkey"
+)
+
+// PasskeyService handles passwordless login with WebAuthn credentials (passkeys)
+// Passkeys require user verification on the authenticator, so a passkey login
+// replaces both password and TOTP and directly issues tokens
+type PasskeyService struct {
+	repo        Repository
+	authService *AuthService
+	webAuthn    *webauthn.WebAuthn
+}
+
+// NewPasskeyService creates a new passkey service
+func NewPasskeyService(repo Repository, authService *AuthService, cfg *config.WebAuthnConfig) (*PasskeyService, error) {
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:          cfg.GetRPID(),
+		RPDisplayName: cfg.GetRPDisplayName(),
+		RPOrigins:     cfg.GetRPOrigins(),
+		AuthenticatorSelection: protocol.AuthenticatorSelection{
+			ResidentKey:      protocol.ResidentKeyRequirementRequired,
+			UserVerification: protocol.VerificationRequired,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize WebAuthn: %w", err)
+	}
+
+	return &PasskeyService{
+		repo:        repo,
+		authService: authService,
+		webAuthn:    w,
+	}, nil
+}
+
+// passkeyUser adapts a User and its stored credentials to the webauthn.User interface
+type passkeyUser struct {
+	user        *User
+	records     []PasskeyCredential
+	credentials []webauthn.Credential
+}
+
+// WebAuthnID returns the user handle - the user ID, so discoverable logins can look up the user directly
+func (u *passkeyUser) WebAuthnID() []byte {
+	return []byte(u.user.ID)
+}
+
+func (u *passkeyUser) WebAuthnName() string {
+	return u.user.Username
+}
+
+func (u *passkeyUser) WebAuthnDisplayName() string {
+	return u.user.Username
+}
+
+func (u *passkeyUser) WebAuthnCredentials() []webauthn.Credential {
+	return u.credentials
+}
+
+// record returns the stored row for a WebAuthn credential ID
+func (u *passkeyUser) record(credentialID []byte) *PasskeyCredential {
+	encoded := base64.RawURLEncoding.EncodeToString(credentialID)
+	for i := range u.records {
+		if u.records[i].CredentialID == encoded {
+			return &u.records[i]
+		}
+	}
+	return nil
+}
+
+// loadPasskeyUser loads a user with all registered passkeys
+func (s *PasskeyService) loadPasskeyUser(ctx context.Context, userID string) (*passkeyUser, error) {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := s.repo.GetPasskeyCredentialsByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials := make([]webauthn.Credential, 0, len(records))
+	for _, record := range records {
+		var credential webauthn.Credential
+		if err := json.Unmarshal([]byte(record.Data), &credential); err != nil {
+			return nil, fmt.Errorf("failed to decode passkey %s: %w", record.ID, err)
+		}
+		credentials = append(credentials, credential)
+	}
+
+	return &passkeyUser{user: user, records: records, credentials: credentials}, nil
+}
+
+// saveSession stores the ceremony state and returns its ID
+func (s *PasskeyService) saveSession(ctx context.Context, userID *string, ceremony string, data *webauthn.SessionData) (string, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode webauthn session: %w", err)
+	}
+
+	session := &WebAuthnSession{
+		UserID:    userID,
+		Ceremony:  ceremony,
+		Data:      string(encoded),
+		ExpiresAt: time.Now().Add(PasskeyCeremonyTimeout),
+	}
+	if err := s.repo.CreateWebAuthnSession(ctx, session); err != nil {
+		return "", err
+	}
+
+	return session.ID, nil
+}
+
+// loadSession consumes a ceremony session and checks that it belongs to the expected ceremony and user
+func (s *PasskeyService) loadSession(ctx context.Context, sessionID, ceremony string, userID *string) (*webauthn.SessionData, error) {
+	session, err := s.repo.ConsumeWebAuthnSession(ctx, sessionID)
+	if err != nil {
+		return nil, errors.BadRequest("invalid or expired passkey session")
+	}
+
+	if session.Ceremony != ceremony || session.IsExpired() {
+		return nil, errors.BadRequest("invalid or expired passkey session")
+	}
+	if userID != nil && (session.UserID == nil || *session.UserID != *userID) {
+		return nil, errors.BadRequest("invalid or expired passkey session")
+	}
+
+	var data webauthn.SessionData
+	if err := json.Unmarshal([]byte(session.Data), &data); err != nil {
+		return nil, fmt.Errorf("failed to decode webauthn session: %w", err)
+	}
+
+	return &data, nil
+}
+
+// BeginRegistration starts registering a new passkey for an authenticated user
+func (s *PasskeyService) BeginRegistration(ctx context.Context, userID string) (*PasskeyBeginResponse, error) {
+	user, err := s.loadPasskeyUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Exclude existing credentials so the same authenticator isn't registered twice
+	options, sessionData, err := s.webAuthn.BeginRegistration(user,
+		webauthn.WithExclusions(webauthn.Credentials(user.credentials).CredentialDescriptors()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin passkey registration: %w", err)
+	}
+
+	sessionID, err := s.saveSession(ctx, &user.user.ID, WebAuthnCeremonyRegistration, sessionData)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PasskeyBeginResponse{SessionID: sessionID, Options: options}, nil
+}
+
+// FinishRegistration verifies the attestation from the authenticator and stores the new passkey
+func (s *PasskeyService) FinishRegistration(ctx context.Context, userID string, req *PasskeyFinishRequest) (*PasskeyCredential, error) {
+	sessionData, err := s.loadSession(ctx, req.SessionID, WebAuthnCeremonyRegistration, &userID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.loadPasskeyUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(req.Credential)
+	if err != nil {
+		return nil, errors.BadRequest("invalid passkey response: " + protocolErrorDetails(err))
+	}
+
+	credential, err := s.webAuthn.CreateCredential(user, *sessionData, parsed)
+	if err != nil {
+		return nil, errors.BadRequest("passkey registration failed: " + protocolErrorDetails(err))
+	}
+
+	data, err := json.Marshal(credential)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode passkey: %w", err)
+	}
+
+	name := req.Name
+	if name == "" {
+		name = DefaultPasskeyName
+	}
+
+	record := &PasskeyCredential{
+		UserID:       user.user.ID,
+		CredentialID: base64.RawURLEncoding.EncodeToString(credential.ID),
+		Name:         name,
+		Data:         string(data),
+		SignCount:    int64(credential.Authenticator.SignCount),
+	}
+	if err := s.repo.CreatePasskeyCredential(ctx, record); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Passkey registered",
+		zap.String("user_id", user.user.ID),
+		zap.String("passkey_id", record.ID),
+	)
+
+	return record, nil
+}
+
+// BeginLogin starts a discoverable (usernameless) passkey login
+func (s *PasskeyService) BeginLogin(ctx context.Context) (*PasskeyBeginResponse, error) {
+	options, sessionData, err := s.webAuthn.BeginDiscoverableLogin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin passkey login: %w", err)
+	}
+
+	sessionID, err := s.saveSession(ctx, nil, WebAuthnCeremonyLogin, sessionData)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PasskeyBeginResponse{SessionID: sessionID, Options: options}, nil
+}
+
+// FinishLogin verifies the assertion signature and sign counter and issues tokens
+func (s *PasskeyService) FinishLogin(ctx context.Context, req *PasskeyFinishRequest, client ClientInfo) (*LoginResponse, error) {
+	sessionData, err := s.loadSession(ctx, req.SessionID, WebAuthnCeremonyLogin, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(req.Credential)
+	if err != nil {
+		return nil, errors.Unauthorized("invalid passkey response")
+	}
+
+	var user *passkeyUser
+	// The user handle returned by the authenticator is the user ID (see WebAuthnID)
+	handler := func(rawID, userHandle []byte) (webauthn.User, error) {
+		user, err = s.loadPasskeyUser(ctx, string(userHandle))
+		if err != nil {
+			return nil, err
+		}
+		return user, nil
+	}
+
+	_, credential, err := s.webAuthn.ValidatePasskeyLogin(handler, *sessionData, parsed)
+	if err != nil {
+		logger.Debug("Passkey login failed", zap.String("details", protocolErrorDetails(err)))
+		return nil, errors.Unauthorized("passkey login failed")
+	}
+
+	record := user.record(credential.ID)
+	if record == nil {
+		return nil, errors.Unauthorized("passkey login failed")
+	}
+
+	// A sign counter that did not increase means the private key may have been cloned
+	if credential.Authenticator.CloneWarning {
+		s.authService.logSecurityEvent(ctx, &SecurityEvent{
+			EventType: SecurityEventPasskeyCloned,
+			UserID:    &user.user.ID,
+			Username:  user.user.Username,
+			Details: fmt.Sprintf("passkey %s presented sign count %d, stored sign count %d",
+				record.ID, parsed.Response.AuthenticatorData.Counter, record.SignCount),
+		})
+		return nil, errors.Unauthorized("passkey login failed")
+	}
+
+	if user.user.IsLocked() {
+		return nil, errors.TooManyRequests("account temporarily locked due to too many failed login attempts")
+	}
+
+	data, err := json.Marshal(credential)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode passkey: %w", err)
+	}
+	now := time.Now()
+	record.Data = string(data)
+	record.SignCount = int64(credential.Authenticator.SignCount)
+	record.LastUsedAt = &now
+	if err := s.repo.UpdatePasskeyCredential(ctx, record); err != nil {
+		return nil, err
+	}
+
+	return s.authService.issueTokens(ctx, user.user, client)
+}
+
+// ListCredentials returns the passkeys of a user
+func (s *PasskeyService) ListCredentials(ctx context.Context, userID string) ([]PasskeyCredential, error) {
+	return s.repo.GetPasskeyCredentialsByUserID(ctx, userID)
+}
+
+// DeleteCredential removes a passkey of a user
+func (s *PasskeyService) DeleteCredential(ctx context.Context, userID, id string) error {
+	return s.repo.DeletePasskeyCredential(ctx, userID, id)
+}
+
+// protocolErrorDetails extracts the human-readable details of a WebAuthn protocol error
+func protocolErrorDetails(err error) string {
+	if perr, ok := err.(*protocol.Error); ok && perr.Details != "" {
+		return perr.Details
+	}
+	return err.Error()
+}
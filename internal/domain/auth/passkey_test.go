@@ -0,0 +1,383 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/config"
+	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testPasskeyOrigin = "https://fits.example.com"
+
+// softAuthenticator is a software WebAuthn authenticator with a P-256 key
+// It produces "none" attestations and ES256 assertions like a platform authenticator
+type softAuthenticator struct {
+	key        *ecdsa.PrivateKey
+	credID     []byte
+	userHandle []byte
+	counter    uint32
+}
+
+func newSoftAuthenticator(t *testing.T) *softAuthenticator {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	credID := make([]byte, 16)
+	_, err = rand.Read(credID)
+	require.NoError(t, err)
+
+	return &softAuthenticator{key: key, credID: credID}
+}
+
+// browserOptions is the subset of the ceremony options a browser passes to the authenticator
+type browserOptions struct {
+	PublicKey struct {
+		Challenge string `json:"challenge"`
+		RPID      string `json:"rpId"`
+		RP        struct {
+			ID string `json:"id"`
+		} `json:"rp"`
+		User struct {
+			ID string `json:"id"`
+		} `json:"user"`
+	} `json:"publicKey"`
+}
+
+func decodeBrowserOptions(t *testing.T, options interface{}) browserOptions {
+	t.Helper()
+
+	raw, err := json.Marshal(options)
+	require.NoError(t, err)
+	var opts browserOptions
+	require.NoError(t, json.Unmarshal(raw, &opts))
+	return opts
+}
+
+func (a *softAuthenticator) clientData(t *testing.T, ceremonyType, challenge string) []byte {
+	t.Helper()
+
+	data, err := json.Marshal(map[string]string{
+		"type":      ceremonyType,
+		"challenge": challenge,
+		"origin":    testPasskeyOrigin,
+	})
+	require.NoError(t, err)
+	return data
+}
+
+// authData builds authenticator data with UP and UV set, optionally with attested credential data
+func (a *softAuthenticator) authData(t *testing.T, rpID string, attested bool) []byte {
+	t.Helper()
+
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	flags := byte(0x01 | 0x04) // UP | UV
+	if attested {
+		flags |= 0x40 // AT
+	}
+
+	data := append([]byte{}, rpIDHash[:]...)
+	data = append(data, flags)
+	data = binary.BigEndian.AppendUint32(data, a.counter)
+
+	if attested {
+		coseKey, err := webauthncbor.Marshal(webauthncose.EC2PublicKeyData{
+			PublicKeyData: webauthncose.PublicKeyData{
+				KeyType:   int64(webauthncose.EllipticKey),
+				Algorithm: int64(webauthncose.AlgES256),
+			},
+			Curve:  int64(webauthncose.P256),
+			XCoord: a.key.X.FillBytes(make([]byte, 32)),
+			YCoord: a.key.Y.FillBytes(make([]byte, 32)),
+		})
+		require.NoError(t, err)
+
+		data = append(data, make([]byte, 16)...) // AAGUID
+		data = binary.BigEndian.AppendUint16(data, uint16(len(a.credID)))
+		data = append(data, a.credID...)
+		data = append(data, coseKey...)
+	}
+
+	return data
+}
+
+// register answers navigator.credentials.create()
+func (a *softAuthenticator) register(t *testing.T, options interface{}) json.RawMessage {
+	t.Helper()
+
+	opts := decodeBrowserOptions(t, options)
+	userHandle, err := base64.RawURLEncoding.DecodeString(opts.PublicKey.User.ID)
+	require.NoError(t, err)
+	a.userHandle = userHandle
+
+	attestation, err := webauthncbor.Marshal(map[string]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[string]interface{}{},
+		"authData": a.authData(t, opts.PublicKey.RP.ID, true),
+	})
+	require.NoError(t, err)
+
+	return a.encode(t, map[string]string{
+		"clientDataJSON":    b64(a.clientData(t, "webauthn.create", opts.PublicKey.Challenge)),
+		"attestationObject": b64(attestation),
+	})
+}
+
+// assert answers navigator.credentials.get() and increments the sign counter
+func (a *softAuthenticator) assert(t *testing.T, options interface{}) json.RawMessage {
+	t.Helper()
+
+	opts := decodeBrowserOptions(t, options)
+	a.counter++
+
+	authData := a.authData(t, opts.PublicKey.RPID, false)
+	clientData := a.clientData(t, "webauthn.get", opts.PublicKey.Challenge)
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	require.NoError(t, err)
+
+	return a.encode(t, map[string]string{
+		"clientDataJSON":    b64(clientData),
+		"authenticatorData": b64(authData),
+		"signature":         b64(signature),
+		"userHandle":        b64(a.userHandle),
+	})
+}
+
+func (a *softAuthenticator) encode(t *testing.T, response map[string]string) json.RawMessage {
+	t.Helper()
+
+	raw, err := json.Marshal(map[string]interface{}{
+		"id":       b64(a.credID),
+		"rawId":    b64(a.credID),
+		"type":     "public-key",
+		"response": response,
+	})
+	require.NoError(t, err)
+	return raw
+}
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// passkeyTestEnv wires a PasskeyService to a mock repository that keeps sessions and credentials in memory
+type passkeyTestEnv struct {
+	ctx         context.Context
+	repo        *MockRepository
+	service     *PasskeyService
+	user        *User
+	sessions    map[string]*WebAuthnSession
+	credentials []PasskeyCredential
+}
+
+func newPasskeyTestEnv(t *testing.T) *passkeyTestEnv {
+	t.Helper()
+
+	env := &passkeyTestEnv{
+		ctx:      context.Background(),
+		repo:     new(MockRepository),
+		user:     &User{ID: "550e8400-e29b-41d4-a716-446655440000", Username: "teacher1", Role: crypto.RoleTeacher},
+		sessions: make(map[string]*WebAuthnSession),
+	}
+
+	authService := NewAuthService(env.repo, crypto.NewJWTService("test-secret"), getTestJWTConfig(), getTestSecurityConfig(), getTestNotifier())
+	service, err := NewPasskeyService(env.repo, authService, &config.WebAuthnConfig{
+		RPID:      "fits.example.com",
+		RPOrigins: []string{testPasskeyOrigin},
+	})
+	require.NoError(t, err)
+	env.service = service
+
+	env.repo.On("GetUserByID", env.ctx, env.user.ID).Return(env.user, nil)
+	env.repo.On("CreateWebAuthnSession", env.ctx, mock.AnythingOfType("*auth.WebAuthnSession")).
+		Run(func(args mock.Arguments) {
+			session := args.Get(1).(*WebAuthnSession)
+			session.ID = "session-" + string(rune('a'+len(env.sessions)))
+			env.sessions[session.ID] = session
+		}).Return(nil)
+	env.repo.On("CreatePasskeyCredential", env.ctx, mock.AnythingOfType("*auth.PasskeyCredential")).
+		Run(func(args mock.Arguments) {
+			credential := args.Get(1).(*PasskeyCredential)
+			credential.ID = "passkey-1"
+			env.credentials = append(env.credentials, *credential)
+		}).Return(nil)
+	env.repo.On("UpdatePasskeyCredential", env.ctx, mock.AnythingOfType("*auth.PasskeyCredential")).
+		Run(func(args mock.Arguments) {
+			env.credentials[0] = *args.Get(1).(*PasskeyCredential)
+		}).Return(nil)
+	env.repo.On("CreateRefreshToken", env.ctx, mock.AnythingOfType("*auth.RefreshToken")).Return(nil)
+	env.repo.On("UpdateLastLogin", env.ctx, env.user.ID).Return(nil)
+	env.repo.On("CreateSecurityEvent", env.ctx, mock.AnythingOfType("*auth.SecurityEvent")).Return(nil)
+
+	return env
+}
+
+// expectCeremony makes the next call to GetPasskeyCredentialsByUserID and ConsumeWebAuthnSession
+// return the current in-memory state
+func (env *passkeyTestEnv) expectCeremony(sessionID string) {
+	env.repo.On("ConsumeWebAuthnSession", env.ctx, sessionID).Return(env.sessions[sessionID], nil).Once()
+	env.repo.On("GetPasskeyCredentialsByUserID", env.ctx, env.user.ID).Return(append([]PasskeyCredential{}, env.credentials...), nil).Once()
+}
+
+func (env *passkeyTestEnv) registerPasskey(t *testing.T, authenticator *softAuthenticator) *PasskeyCredential {
+	t.Helper()
+
+	env.repo.On("GetPasskeyCredentialsByUserID", env.ctx, env.user.ID).Return(append([]PasskeyCredential{}, env.credentials...), nil).Once()
+	begin, err := env.service.BeginRegistration(env.ctx, env.user.ID)
+	require.NoError(t, err)
+
+	env.expectCeremony(begin.SessionID)
+	credential, err := env.service.FinishRegistration(env.ctx, env.user.ID, &PasskeyFinishRequest{
+		SessionID:  begin.SessionID,
+		Name:       "Laptop",
+		Credential: authenticator.register(t, begin.Options),
+	})
+	require.NoError(t, err)
+	return credential
+}
+
+func (env *passkeyTestEnv) login(t *testing.T, authenticator *softAuthenticator) (*LoginResponse, error) {
+	t.Helper()
+
+	begin, err := env.service.BeginLogin(env.ctx)
+	require.NoError(t, err)
+	assertion := authenticator.assert(t, begin.Options)
+
+	env.expectCeremony(begin.SessionID)
+	return env.service.FinishLogin(env.ctx, &PasskeyFinishRequest{SessionID: begin.SessionID, Credential: assertion}, testClient)
+}
+
+func TestPasskeyService_Registration(t *testing.T) {
+	t.Run("registers passkey from software authenticator", func(t *testing.T) {
+		env := newPasskeyTestEnv(t)
+		authenticator := newSoftAuthenticator(t)
+
+		credential := env.registerPasskey(t, authenticator)
+
+		assert.Equal(t, env.user.ID, credential.UserID)
+		assert.Equal(t, "Laptop", credential.Name)
+		assert.Equal(t, b64(authenticator.credID), credential.CredentialID)
+		assert.NotEmpty(t, credential.Data)
+		assert.Equal(t, []byte(env.user.ID), authenticator.userHandle)
+	})
+
+	t.Run("rejects response to a challenge of another session", func(t *testing.T) {
+		env := newPasskeyTestEnv(t)
+		authenticator := newSoftAuthenticator(t)
+
+		env.repo.On("GetPasskeyCredentialsByUserID", env.ctx, env.user.ID).Return([]PasskeyCredential{}, nil)
+		first, err := env.service.BeginRegistration(env.ctx, env.user.ID)
+		require.NoError(t, err)
+		second, err := env.service.BeginRegistration(env.ctx, env.user.ID)
+		require.NoError(t, err)
+
+		env.repo.On("ConsumeWebAuthnSession", env.ctx, second.SessionID).Return(env.sessions[second.SessionID], nil).Once()
+		_, err = env.service.FinishRegistration(env.ctx, env.user.ID, &PasskeyFinishRequest{
+			SessionID:  second.SessionID,
+			Credential: authenticator.register(t, first.Options),
+		})
+
+		assert.Error(t, err)
+		env.repo.AssertNotCalled(t, "CreatePasskeyCredential", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects session of another user", func(t *testing.T) {
+		env := newPasskeyTestEnv(t)
+		otherUser := "other-user"
+		env.sessions["session-x"] = &WebAuthnSession{ID: "session-x", UserID: &otherUser, Ceremony: WebAuthnCeremonyRegistration, Data: "{}"}
+		env.repo.On("ConsumeWebAuthnSession", env.ctx, "session-x").Return(env.sessions["session-x"], nil).Once()
+
+		_, err := env.service.FinishRegistration(env.ctx, env.user.ID, &PasskeyFinishRequest{SessionID: "session-x", Credential: json.RawMessage(`{}`)})
+
+		assert.Error(t, err)
+	})
+}
+
+func TestPasskeyService_Login(t *testing.T) {
+	t.Run("logs in with registered passkey", func(t *testing.T) {
+		env := newPasskeyTestEnv(t)
+		authenticator := newSoftAuthenticator(t)
+		env.registerPasskey(t, authenticator)
+
+		resp, err := env.login(t, authenticator)
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, resp.AccessToken)
+		assert.NotEmpty(t, resp.RefreshToken)
+		assert.Equal(t, env.user.ID, resp.UserID)
+		assert.Equal(t, int64(1), env.credentials[0].SignCount)
+		assert.NotNil(t, env.credentials[0].LastUsedAt)
+	})
+
+	t.Run("sign counter must increase", func(t *testing.T) {
+		env := newPasskeyTestEnv(t)
+		authenticator := newSoftAuthenticator(t)
+		env.registerPasskey(t, authenticator)
+
+		_, err := env.login(t, authenticator)
+		require.NoError(t, err)
+
+		// A cloned authenticator reuses an old counter value
+		authenticator.counter = 0
+		resp, err := env.login(t, authenticator)
+
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+		env.repo.AssertCalled(t, "CreateSecurityEvent", env.ctx, mock.MatchedBy(func(e *SecurityEvent) bool {
+			return e.EventType == SecurityEventPasskeyCloned
+		}))
+	})
+
+	t.Run("rejects unknown credential", func(t *testing.T) {
+		env := newPasskeyTestEnv(t)
+		env.registerPasskey(t, newSoftAuthenticator(t))
+
+		stranger := newSoftAuthenticator(t)
+		stranger.userHandle = []byte(env.user.ID)
+		_, err := env.login(t, stranger)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects assertion signed with another key", func(t *testing.T) {
+		env := newPasskeyTestEnv(t)
+		authenticator := newSoftAuthenticator(t)
+		env.registerPasskey(t, authenticator)
+
+		other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		authenticator.key = other
+		_, err = env.login(t, authenticator)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects locked account", func(t *testing.T) {
+		env := newPasskeyTestEnv(t)
+		authenticator := newSoftAuthenticator(t)
+		env.registerPasskey(t, authenticator)
+
+		future := time.Now().Add(time.Hour)
+		env.user.LockedUntil = &future
+		_, err := env.login(t, authenticator)
+
+		assert.Error(t, err)
+		env.repo.AssertNotCalled(t, "CreateRefreshToken", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,163 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/errors"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/validation"
+	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
+	"github.com/JustDoItBetter/FITS-backend/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// passwordResetTokenSize is the number of random bytes in a reset token (256 bits)
+const passwordResetTokenSize = 32
+
+// validateNewPassword applies the password policy to a password chosen by a user
+// Passwords longer than the hasher accepts are rejected here instead of failing to hash
+func validateNewPassword(password string) error {
+	if len(password) > crypto.MaxPasswordBytes {
+		return errors.ValidationError(fmt.Sprintf("password must be at most %d bytes long", crypto.MaxPasswordBytes))
+	}
+
+	if err := validation.ValidatePasswordStrength(password); err != nil {
+		return err
+	}
+
+	if validation.IsCommonPassword(password) {
+		return errors.ValidationError("password is too common and easily guessable, please choose a stronger password")
+	}
+
+	return nil
+}
+
+// ChangePassword changes the password of a user after re-verifying the current one
+// All refresh tokens are revoked, so other sessions have to log in again.
+// Wrong current passwords count as failed logins, so a stolen access token can't be used to guess the password
+func (s *AuthService) ChangePassword(ctx context.Context, userID string, req *ChangePasswordRequest, client ClientInfo) error {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	stats, err := s.repo.GetLoginFailureStats(ctx, user.Username, client.IPAddress, now.Add(-s.securityConfig.GetFailedLoginWindow()))
+	if err != nil {
+		return fmt.Errorf("failed to check login attempts: %w", err)
+	}
+	if err := s.checkLoginThrottle(stats, now); err != nil {
+		return err
+	}
+
+	if err := crypto.VerifyPassword(req.CurrentPassword, user.PasswordHash); err != nil {
+		s.recordFailedLogin(ctx, user, user.Username, client.IPAddress, stats, now)
+		return errors.Unauthorized("current password is incorrect")
+	}
+
+	if req.NewPassword == req.CurrentPassword {
+		return errors.ValidationError("new password must be different from the current password")
+	}
+
+	if err := validateNewPassword(req.NewPassword); err != nil {
+		return err
+	}
+
+	passwordHash, err := crypto.HashPassword(req.NewPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	if err := s.repo.ChangePassword(ctx, user.ID, passwordHash); err != nil {
+		return err
+	}
+
+	logger.Info("Password changed", zap.String("user_id", user.ID))
+	return nil
+}
+
+// CreatePasswordReset issues a single-use reset link for a user (admin only)
+// The link is emailed to the student or teacher behind the account and also returned,
+// because admins have no email address. The database only stores the hash of the token
+func (s *AuthService) CreatePasswordReset(ctx context.Context, userID, actorID string) (*PasswordResetResponse, error) {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireAdminManager(ctx, s.repo, actorID, user); err != nil {
+		return nil, err
+	}
+
+	var contact *UserContact
+	if s.notifier.enabled() && user.UserUUID != nil {
+		contact, err = s.repo.GetUserContact(ctx, *user.UserUUID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	token, err := crypto.GenerateSecureToken(passwordResetTokenSize)
+	if err != nil {
+		return nil, err
+	}
+
+	resetToken := &PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: crypto.HashString(token),
+		ExpiresAt: time.Now().Add(s.securityConfig.GetPasswordResetExpiry()),
+	}
+	if actorID != "" {
+		resetToken.CreatedBy = &actorID
+	}
+
+	var emailQueued bool
+	err = s.repo.ExecuteInTransaction(ctx, func(txRepo Repository) error {
+		if err := txRepo.CreatePasswordResetToken(ctx, resetToken); err != nil {
+			return err
+		}
+		emailQueued, err = s.notifier.queuePasswordReset(ctx, txRepo, user, contact, token, resetToken.ExpiresAt)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Password reset issued",
+		zap.String("user_id", user.ID),
+		zap.String("actor_id", actorID),
+		zap.Bool("email_queued", emailQueued),
+	)
+
+	return &PasswordResetResponse{
+		ResetToken:  token,
+		ResetLink:   s.notifier.passwordResetLink(token),
+		ExpiresAt:   resetToken.ExpiresAt.Format(time.RFC3339),
+		EmailQueued: emailQueued,
+	}, nil
+}
+
+// ResetPassword sets a new password with a reset token
+// On success the token is consumed, a lockout is lifted and all refresh tokens are revoked
+func (s *AuthService) ResetPassword(ctx context.Context, req *ResetPasswordRequest) error {
+	resetToken, err := s.repo.GetPasswordResetToken(ctx, crypto.HashString(req.Token))
+	if err != nil || !resetToken.IsValid() {
+		return errors.BadRequest("password reset token is invalid or expired")
+	}
+
+	if err := validateNewPassword(req.NewPassword); err != nil {
+		return err
+	}
+
+	passwordHash, err := crypto.HashPassword(req.NewPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	if err := s.repo.ResetPassword(ctx, resetToken, passwordHash); err != nil {
+		return err
+	}
+
+	logger.Info("Password reset completed", zap.String("user_id", resetToken.UserID))
+	return nil
+}
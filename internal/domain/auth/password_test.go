@@ -0,0 +1,160 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/errors"
+	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthService_ChangePassword(t *testing.T) {
+	ctx := context.Background()
+	hashedPassword, _ := crypto.HashPassword("OldPassword123!")
+	user := &User{ID: "user-123", Username: "testuser", PasswordHash: hashedPassword, Role: crypto.RoleStudent}
+
+	t.Run("changes password and revokes sessions", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewAuthService(mockRepo, crypto.NewJWTService("test-secret"), getTestJWTConfig(), getTestSecurityConfig(), getTestNotifier())
+		mockRepo.On("GetUserByID", ctx, user.ID).Return(user, nil)
+		mockRepo.On("GetLoginFailureStats", ctx, user.Username, testClientIP, mock.AnythingOfType("time.Time")).Return(&LoginFailureStats{}, nil)
+		mockRepo.On("ChangePassword", ctx, user.ID, mock.AnythingOfType("string")).Return(nil)
+
+		err := service.ChangePassword(ctx, user.ID, &ChangePasswordRequest{
+			CurrentPassword: "OldPassword123!",
+			NewPassword:     "NewSecurePassword456!",
+		}, testClient)
+
+		require.NoError(t, err)
+		newHash := mockRepo.Calls[2].Arguments.String(2)
+		assert.NoError(t, crypto.VerifyPassword("NewSecurePassword456!", newHash))
+	})
+
+	t.Run("rejects wrong current password", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewAuthService(mockRepo, crypto.NewJWTService("test-secret"), getTestJWTConfig(), getTestSecurityConfig(), getTestNotifier())
+		mockRepo.On("GetUserByID", ctx, user.ID).Return(user, nil)
+		mockRepo.On("GetLoginFailureStats", ctx, user.Username, testClientIP, mock.AnythingOfType("time.Time")).Return(&LoginFailureStats{}, nil)
+		mockRepo.On("RecordFailedLogin", ctx, mock.AnythingOfType("*auth.LoginAttempt"), mock.AnythingOfType("time.Time")).Return(nil)
+
+		err := service.ChangePassword(ctx, user.ID, &ChangePasswordRequest{
+			CurrentPassword: "WrongPassword123!",
+			NewPassword:     "NewSecurePassword456!",
+		}, testClient)
+
+		assert.Error(t, err)
+		mockRepo.AssertNotCalled(t, "ChangePassword", mock.Anything, mock.Anything, mock.Anything)
+		mockRepo.AssertCalled(t, "RecordFailedLogin", ctx, mock.MatchedBy(func(attempt *LoginAttempt) bool {
+			return attempt.Username == user.Username && attempt.IPAddress == testClientIP
+		}), mock.AnythingOfType("time.Time"))
+	})
+
+	t.Run("is throttled like logins", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewAuthService(mockRepo, crypto.NewJWTService("test-secret"), getTestJWTConfig(), getTestSecurityConfig(), getTestNotifier())
+		lastFailure := time.Now()
+		mockRepo.On("GetUserByID", ctx, user.ID).Return(user, nil)
+		mockRepo.On("GetLoginFailureStats", ctx, user.Username, testClientIP, mock.AnythingOfType("time.Time")).
+			Return(&LoginFailureStats{UsernameFailures: 1, UsernameLastFailure: &lastFailure}, nil)
+
+		err := service.ChangePassword(ctx, user.ID, &ChangePasswordRequest{
+			CurrentPassword: "OldPassword123!",
+			NewPassword:     "NewSecurePassword456!",
+		}, testClient)
+
+		appErr, ok := err.(*errors.AppError)
+		require.True(t, ok)
+		assert.Equal(t, 429, appErr.Code)
+		mockRepo.AssertNotCalled(t, "ChangePassword", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects weak and common passwords", func(t *testing.T) {
+		for _, password := range []string{"short", "password123", "OldPassword123!", "Aa1!" + strings.Repeat("x", crypto.MaxPasswordBytes)} {
+			mockRepo := new(MockRepository)
+			service := NewAuthService(mockRepo, crypto.NewJWTService("test-secret"), getTestJWTConfig(), getTestSecurityConfig(), getTestNotifier())
+			mockRepo.On("GetUserByID", ctx, user.ID).Return(user, nil)
+			mockRepo.On("GetLoginFailureStats", ctx, user.Username, testClientIP, mock.AnythingOfType("time.Time")).Return(&LoginFailureStats{}, nil)
+
+			err := service.ChangePassword(ctx, user.ID, &ChangePasswordRequest{
+				CurrentPassword: "OldPassword123!",
+				NewPassword:     password,
+			}, testClient)
+
+			var appErr *errors.AppError
+			require.ErrorAs(t, err, &appErr, "password %q should be rejected", password)
+			assert.Equal(t, 422, appErr.Code)
+			mockRepo.AssertNotCalled(t, "ChangePassword", mock.Anything, mock.Anything, mock.Anything)
+		}
+	})
+}
+
+func TestAuthService_PasswordReset(t *testing.T) {
+	ctx := context.Background()
+	user := &User{ID: "user-123", Username: "testuser", Role: crypto.RoleStudent}
+
+	t.Run("issues reset token stored as hash", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewAuthService(mockRepo, crypto.NewJWTService("test-secret"), getTestJWTConfig(), getTestSecurityConfig(), getTestNotifier())
+		mockRepo.On("GetUserByID", ctx, user.ID).Return(user, nil)
+		mockRepo.On("CreatePasswordResetToken", ctx, mock.AnythingOfType("*auth.PasswordResetToken")).Return(nil)
+
+		resp, err := service.CreatePasswordReset(ctx, user.ID, "admin-1")
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, resp.ResetToken)
+		assert.Contains(t, resp.ResetLink, resp.ResetToken)
+
+		stored := mockRepo.Calls[1].Arguments.Get(1).(*PasswordResetToken)
+		assert.Equal(t, crypto.HashString(resp.ResetToken), stored.TokenHash)
+		assert.NotEqual(t, resp.ResetToken, stored.TokenHash)
+		assert.Equal(t, "admin-1", *stored.CreatedBy)
+		assert.WithinDuration(t, time.Now().Add(24*time.Hour), stored.ExpiresAt, time.Minute)
+	})
+
+	t.Run("resets password with valid token", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewAuthService(mockRepo, crypto.NewJWTService("test-secret"), getTestJWTConfig(), getTestSecurityConfig(), getTestNotifier())
+		resetToken := &PasswordResetToken{ID: "reset-1", UserID: user.ID, ExpiresAt: time.Now().Add(time.Hour)}
+		mockRepo.On("GetPasswordResetToken", ctx, crypto.HashString("plain-token")).Return(resetToken, nil)
+		mockRepo.On("ResetPassword", ctx, resetToken, mock.AnythingOfType("string")).Return(nil)
+
+		err := service.ResetPassword(ctx, &ResetPasswordRequest{Token: "plain-token", NewPassword: "NewSecurePassword456!"})
+
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects used or expired token", func(t *testing.T) {
+		usedAt := time.Now()
+		tokens := []*PasswordResetToken{
+			{ID: "reset-1", UserID: user.ID, ExpiresAt: time.Now().Add(time.Hour), UsedAt: &usedAt},
+			{ID: "reset-2", UserID: user.ID, ExpiresAt: time.Now().Add(-time.Minute)},
+		}
+
+		for _, resetToken := range tokens {
+			mockRepo := new(MockRepository)
+			service := NewAuthService(mockRepo, crypto.NewJWTService("test-secret"), getTestJWTConfig(), getTestSecurityConfig(), getTestNotifier())
+			mockRepo.On("GetPasswordResetToken", ctx, mock.AnythingOfType("string")).Return(resetToken, nil)
+
+			err := service.ResetPassword(ctx, &ResetPasswordRequest{Token: "plain-token", NewPassword: "NewSecurePassword456!"})
+
+			assert.Error(t, err)
+			mockRepo.AssertNotCalled(t, "ResetPassword", mock.Anything, mock.Anything, mock.Anything)
+		}
+	})
+
+	t.Run("rejects unknown token", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewAuthService(mockRepo, crypto.NewJWTService("test-secret"), getTestJWTConfig(), getTestSecurityConfig(), getTestNotifier())
+		mockRepo.On("GetPasswordResetToken", ctx, mock.AnythingOfType("string")).Return(nil, assert.AnError)
+
+		err := service.ResetPassword(ctx, &ResetPasswordRequest{Token: "unknown", NewPassword: "NewSecurePassword456!"})
+
+		assert.Error(t, err)
+	})
+}
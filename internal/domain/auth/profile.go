@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"context"
+	"unicode/utf8"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/errors"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/pagination"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/validation"
+)
+
+// maxDisplayNameLength is the longest display name in characters, the size of the display_name column
+const maxDisplayNameLength = 100
+
+// UpdateProfile changes the profile fields users may edit on their own account
+// Returns the updated account with its student or teacher record
+func (s *AuthService) UpdateProfile(ctx context.Context, userID string, req *UpdateProfileRequest) (*UserSummary, error) {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	displayName := user.DisplayName
+	if req.DisplayName != nil {
+		displayName = validation.SanitizeName(*req.DisplayName)
+		if utf8.RuneCountInString(displayName) > maxDisplayNameLength {
+			return nil, errors.ValidationError("display_name must be at most 100 characters")
+		}
+	}
+	notifications := user.Notifications
+	if req.Notifications != nil {
+		notifications = *req.Notifications
+	}
+
+	if err := s.repo.UpdateUserProfile(ctx, user.ID, displayName, notifications); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetUserSummary(ctx, user.ID)
+}
+
+// CountOpenInvitations returns the number of invitations that can still be accepted
+func (s *AuthService) CountOpenInvitations(ctx context.Context) (int64, error) {
+	_, count, err := s.repo.ListInvitations(ctx, InvitationFilter{Status: InvitationStatusPending}, pagination.Params{Page: 1, Limit: 1})
+	return count, err
+}
@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/pagination"
+	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
+)
+
+func TestAuthService_UpdateProfile(t *testing.T) {
+	ctx := context.Background()
+	newUser := func() *User {
+		return &User{
+			ID:            "user-1",
+			Role:          crypto.RoleStudent,
+			DisplayName:   "Max",
+			Notifications: NotificationPreferences{SignRequests: true, Reminders: true},
+		}
+	}
+
+	t.Run("changes the display name and keeps the preferences", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		user := newUser()
+		mockRepo.On("GetUserByID", ctx, user.ID).Return(user, nil)
+		mockRepo.On("UpdateUserProfile", ctx, user.ID, "Max M.", user.Notifications).Return(nil)
+		mockRepo.On("GetUserSummary", ctx, user.ID).Return(&UserSummary{User: *user}, nil)
+
+		name := "  Max   M. "
+		_, err := newUserAdminTestService(mockRepo).UpdateProfile(ctx, user.ID, &UpdateProfileRequest{DisplayName: &name})
+
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("changes the preferences and keeps the display name", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		user := newUser()
+		notifications := NotificationPreferences{SignRequests: true, Reminders: false}
+		mockRepo.On("GetUserByID", ctx, user.ID).Return(user, nil)
+		mockRepo.On("UpdateUserProfile", ctx, user.ID, "Max", notifications).Return(nil)
+		mockRepo.On("GetUserSummary", ctx, user.ID).Return(&UserSummary{User: *user}, nil)
+
+		_, err := newUserAdminTestService(mockRepo).UpdateProfile(ctx, user.ID, &UpdateProfileRequest{Notifications: &notifications})
+
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects long display names", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		user := newUser()
+		mockRepo.On("GetUserByID", ctx, user.ID).Return(user, nil)
+
+		name := strings.Repeat("a", 101)
+		_, err := newUserAdminTestService(mockRepo).UpdateProfile(ctx, user.ID, &UpdateProfileRequest{DisplayName: &name})
+
+		assertAppErrorCode(t, err, 422)
+		mockRepo.AssertNotCalled(t, "UpdateUserProfile", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestAuthService_CountOpenInvitations(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	mockRepo.On("ListInvitations", ctx, InvitationFilter{Status: InvitationStatusPending}, pagination.Params{Page: 1, Limit: 1}).
+		Return([]Invitation{}, int64(7), nil)
+
+	count, err := newUserAdminTestService(mockRepo).CountOpenInvitations(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), count)
+}
@@ -6,7 +6,13 @@ import (
 	"time"
 
 	"github.com/JustDoItBetter/FITS-backend/internal/common/errors"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/pagination"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/reference"
+	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
+	"github.com/JustDoItBetter/FITS-backend/pkg/database"
+	"github.com/JustDoItBetter/FITS-backend/pkg/mailer"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Repository defines the interface for auth data access
@@ -17,6 +23,48 @@ type Repository interface {
 	GetUserByID(ctx context.Context, id string) (*User, error)
 	UpdateUser(ctx context.Context, user *User) error
 	UpdateLastLogin(ctx context.Context, userID string) error
+	LockUser(ctx context.Context, userID string, until time.Time) error
+	UnlockUser(ctx context.Context, user *User) error
+
+	// User administration
+	ListUsers(ctx context.Context, filter UserFilter, params pagination.Params) ([]UserSummary, int64, error)
+	GetUserSummary(ctx context.Context, id string) (*UserSummary, error)
+	GetUserByUserUUID(ctx context.Context, userUUID string) (*User, error)
+	HasActiveProfile(ctx context.Context, role crypto.Role, userUUID string) (bool, error)
+	GetActiveAdminIDs(ctx context.Context) ([]string, error)
+	DisableUser(ctx context.Context, userID string, disabledBy *string) error
+	EnableUser(ctx context.Context, userID string) error
+	UpdateUserRole(ctx context.Context, userID string, role crypto.Role, userUUID *string, admin crypto.AdminClaims) error
+	UpdateUserProfile(ctx context.Context, userID string, displayName string, notifications NotificationPreferences) error
+	DeleteUser(ctx context.Context, user *User) error
+
+	// Password management
+	CreatePasswordResetToken(ctx context.Context, token *PasswordResetToken) error
+	GetPasswordResetToken(ctx context.Context, tokenHash string) (*PasswordResetToken, error)
+	ResetPassword(ctx context.Context, token *PasswordResetToken, passwordHash string) error
+	ChangePassword(ctx context.Context, userID, passwordHash string) error
+	RehashPassword(ctx context.Context, userID, oldHash, newHash string) error
+
+	// Brute-force protection
+	RecordFailedLogin(ctx context.Context, attempt *LoginAttempt, pruneBefore time.Time) error
+	GetLoginFailureStats(ctx context.Context, username, ipAddress string, since time.Time) (*LoginFailureStats, error)
+	ResetFailedLogins(ctx context.Context, username string) error
+	CreateSecurityEvent(ctx context.Context, event *SecurityEvent) error
+
+	// Two-factor authentication
+	SetTOTPSecret(ctx context.Context, userID, secret string) error
+	EnableMFA(ctx context.Context, userID string, recoveryCodeHashes []string) error
+	DisableMFA(ctx context.Context, userID string) error
+	UseTOTPStep(ctx context.Context, userID string, step int64) (bool, error)
+	UseRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error)
+
+	// Passkeys (WebAuthn)
+	CreatePasskeyCredential(ctx context.Context, credential *PasskeyCredential) error
+	GetPasskeyCredentialsByUserID(ctx context.Context, userID string) ([]PasskeyCredential, error)
+	UpdatePasskeyCredential(ctx context.Context, credential *PasskeyCredential) error
+	DeletePasskeyCredential(ctx context.Context, userID, id string) error
+	CreateWebAuthnSession(ctx context.Context, session *WebAuthnSession) error
+	ConsumeWebAuthnSession(ctx context.Context, id string) (*WebAuthnSession, error)
 
 	// Refresh token operations
 	CreateRefreshToken(ctx context.Context, token *RefreshToken) error
@@ -25,15 +73,53 @@ type Repository interface {
 	DeleteExpiredRefreshTokens(ctx context.Context) error
 	DeleteUserRefreshTokens(ctx context.Context, userID string) error
 
+	// Session management (refresh tokens per device)
+	GetUserSessions(ctx context.Context, userID string) ([]RefreshToken, error)
+	TouchSession(ctx context.Context, id, ipAddress string, usedAt time.Time) error
+	DeleteUserSession(ctx context.Context, userID, id string) error
+	DeleteOtherUserSessions(ctx context.Context, userID, keepID string) error
+
 	// Invitation operations
 	CreateInvitation(ctx context.Context, invitation *Invitation) error
 	GetInvitationByToken(ctx context.Context, token string) (*Invitation, error)
 	MarkInvitationAsUsed(ctx context.Context, token string) error
-	DeleteExpiredInvitations(ctx context.Context) error
+	DeleteExpiredInvitations(ctx context.Context, before time.Time) (int64, error)
+	GetInvitationByID(ctx context.Context, id string) (*Invitation, error)
+	ListInvitations(ctx context.Context, filter InvitationFilter, params pagination.Params) ([]Invitation, int64, error)
+	HasOpenInvitation(ctx context.Context, email string) (bool, error)
+	RevokeInvitation(ctx context.Context, id, revokedBy string) error
+	ReissueInvitation(ctx context.Context, id, token string, expiresAt time.Time) error
+	GetOpenInvitationEmails(ctx context.Context, emails []string) (map[string]bool, error)
 
 	// Student/Teacher operations (for invitation completion)
 	CreateStudent(ctx context.Context, student *StudentRecord) error
 	CreateTeacher(ctx context.Context, teacher *TeacherRecord) error
+	GetActiveTeacher(ctx context.Context, uuid string) (*reference.Teacher, error)
+	GetTeachersByEmail(ctx context.Context, emails []string) (map[string]*reference.Teacher, error)
+	GetUserContact(ctx context.Context, userUUID string) (*UserContact, error)
+
+	// Outbound email
+	EnqueueEmail(ctx context.Context, msg *mailer.OutboxMessage) error
+
+	// OpenID Connect login
+	CreateOIDCLoginState(ctx context.Context, state *OIDCLoginState) error
+	ConsumeOIDCLoginState(ctx context.Context, stateHash string) (*OIDCLoginState, error)
+	GetOIDCIdentity(ctx context.Context, issuer, subject string) (*OIDCIdentity, error)
+	GetOIDCIdentityByID(ctx context.Context, id string) (*OIDCIdentity, error)
+	CreateOIDCIdentity(ctx context.Context, identity *OIDCIdentity) error
+	UpdateOIDCIdentityProfile(ctx context.Context, identity *OIDCIdentity) error
+	LinkOIDCIdentity(ctx context.Context, id, userID string, linkedBy *string) error
+	ListOIDCIdentities(ctx context.Context, filter OIDCIdentityFilter, params pagination.Params) ([]OIDCIdentity, int64, error)
+	DeleteOIDCIdentity(ctx context.Context, id string) error
+	GetUserByInvitationEmail(ctx context.Context, email string) (*User, error)
+
+	// Service account API keys
+	CreateAPIKey(ctx context.Context, key *APIKey) error
+	GetAPIKeyByID(ctx context.Context, id string) (*APIKey, error)
+	GetAPIKeyByLookupID(ctx context.Context, lookupID string) (*APIKey, error)
+	ListAPIKeys(ctx context.Context, params pagination.Params) ([]APIKey, int64, error)
+	RevokeAPIKey(ctx context.Context, id string, revokedBy *string) error
+	TouchAPIKey(ctx context.Context, id, ipAddress string, usedAt time.Time) error
 
 	// Transaction support
 	// ExecuteInTransaction runs the given function within a database transaction
@@ -60,6 +146,13 @@ type TeacherRecord struct {
 	Department string
 }
 
+// UserContact is the name and email of the student or teacher behind a user account
+type UserContact struct {
+	FirstName string
+	LastName  string
+	Email     string
+}
+
 // GormRepository implements Repository using GORM
 type GormRepository struct {
 	db *gorm.DB
@@ -116,6 +209,545 @@ func (r *GormRepository) UpdateLastLogin(ctx context.Context, userID string) err
 	return nil
 }
 
+func (r *GormRepository) LockUser(ctx context.Context, userID string, until time.Time) error {
+	if err := r.db.WithContext(ctx).Model(&User{}).Where("id = ?", userID).Update("locked_until", until).Error; err != nil {
+		return fmt.Errorf("failed to lock user: %w", err)
+	}
+	return nil
+}
+
+// UnlockUser clears the account lock and the failed attempts that caused it
+func (r *GormRepository) UnlockUser(ctx context.Context, user *User) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&User{}).Where("id = ?", user.ID).Update("locked_until", nil).Error; err != nil {
+			return fmt.Errorf("failed to unlock user: %w", err)
+		}
+		if err := tx.Where("username = ?", user.Username).Delete(&LoginAttempt{}).Error; err != nil {
+			return fmt.Errorf("failed to reset failed logins: %w", err)
+		}
+		return nil
+	})
+}
+
+// User administration
+
+// userSummaryQuery joins users with the student or teacher record they belong to
+func (r *GormRepository) userSummaryQuery(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Table("users").
+		Joins("LEFT JOIN students ON students.id = users.user_uuid AND users.role = ?", crypto.RoleStudent).
+		Joins("LEFT JOIN teachers ON teachers.id = users.user_uuid AND users.role = ?", crypto.RoleTeacher)
+}
+
+// userSummaryColumns are the columns scanned into UserSummary
+const userSummaryColumns = `users.*,
+	COALESCE(students.first_name, teachers.first_name, '') AS first_name,
+	COALESCE(students.last_name, teachers.last_name, '') AS last_name,
+	COALESCE(students.email, teachers.email, '') AS email,
+	COALESCE(teachers.department, '') AS department,
+	students.teacher_id AS teacher_uuid`
+
+// ListUsers returns user accounts with their student or teacher record, newest first
+func (r *GormRepository) ListUsers(ctx context.Context, filter UserFilter, params pagination.Params) ([]UserSummary, int64, error) {
+	query := r.userSummaryQuery(ctx)
+
+	if filter.Role != "" {
+		query = query.Where("users.role = ?", filter.Role)
+	}
+	switch filter.Status {
+	case UserStatusActive:
+		query = query.Where("users.disabled_at IS NULL")
+	case UserStatusDisabled:
+		query = query.Where("users.disabled_at IS NOT NULL")
+	case UserStatusLocked:
+		query = query.Where("users.locked_until > ?", time.Now())
+	}
+	if filter.Search != "" {
+		pattern := database.ContainsPattern(filter.Search)
+		query = query.Where(`LOWER(users.username) LIKE ? ESCAPE '\'
+			OR LOWER(COALESCE(students.email, teachers.email, '')) LIKE ? ESCAPE '\'
+			OR LOWER(COALESCE(students.first_name, teachers.first_name, '') || ' ' || COALESCE(students.last_name, teachers.last_name, '')) LIKE ? ESCAPE '\'`,
+			pattern, pattern, pattern)
+	}
+
+	var totalCount int64
+	if err := query.Count(&totalCount).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	var users []UserSummary
+	if err := query.
+		Select(userSummaryColumns).
+		Offset(params.Offset()).
+		Limit(params.Limit).
+		Order("users.created_at DESC").
+		Scan(&users).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	return users, totalCount, nil
+}
+
+// GetUserSummary retrieves a user account with its student or teacher record
+func (r *GormRepository) GetUserSummary(ctx context.Context, id string) (*UserSummary, error) {
+	var users []UserSummary
+	if err := r.userSummaryQuery(ctx).
+		Select(userSummaryColumns).
+		Where("users.id = ?", id).
+		Limit(1).
+		Scan(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if len(users) == 0 {
+		return nil, errors.NotFound("user")
+	}
+	return &users[0], nil
+}
+
+// GetUserByUserUUID finds the account that belongs to a student or teacher record
+func (r *GormRepository) GetUserByUserUUID(ctx context.Context, userUUID string) (*User, error) {
+	var user User
+	if err := r.db.WithContext(ctx).Where("user_uuid = ?", userUUID).First(&user).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFound("user")
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return &user, nil
+}
+
+// HasActiveProfile checks if a student or teacher record exists for the role and is not deleted
+func (r *GormRepository) HasActiveProfile(ctx context.Context, role crypto.Role, userUUID string) (bool, error) {
+	var table string
+	switch role {
+	case crypto.RoleStudent:
+		table = "students"
+	case crypto.RoleTeacher:
+		table = "teachers"
+	default:
+		return false, nil
+	}
+
+	var count int64
+	if err := r.db.WithContext(ctx).Table(table).
+		Where("id = ? AND deleted_at IS NULL", userUUID).
+		Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to look up %s: %w", table, err)
+	}
+	return count > 0, nil
+}
+
+// GetActiveAdminIDs returns the full admins that are not disabled
+// Inside a transaction the rows are locked, so concurrent requests can't remove the last admin together
+func (r *GormRepository) GetActiveAdminIDs(ctx context.Context) ([]string, error) {
+	var ids []string
+	if err := r.db.WithContext(ctx).Model(&User{}).
+		Where("role = ? AND admin_scope IN ? AND disabled_at IS NULL", crypto.RoleAdmin, []crypto.AdminScope{crypto.AdminScopeFull, ""}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Pluck("id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to get admins: %w", err)
+	}
+	return ids, nil
+}
+
+// DisableUser disables an account and revokes all its refresh tokens
+func (r *GormRepository) DisableUser(ctx context.Context, userID string, disabledBy *string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&User{}).
+			Where("id = ? AND disabled_at IS NULL", userID).
+			Updates(map[string]interface{}{
+				"disabled_at": time.Now(),
+				"disabled_by": disabledBy,
+			})
+		if result.Error != nil {
+			return fmt.Errorf("failed to disable user: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return errors.Conflict("user is already disabled")
+		}
+
+		if err := tx.Where("user_id = ?", userID).Delete(&RefreshToken{}).Error; err != nil {
+			return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+		}
+		return nil
+	})
+}
+
+// EnableUser re-enables a disabled account
+func (r *GormRepository) EnableUser(ctx context.Context, userID string) error {
+	result := r.db.WithContext(ctx).Model(&User{}).
+		Where("id = ? AND disabled_at IS NOT NULL", userID).
+		Updates(map[string]interface{}{
+			"disabled_at": nil,
+			"disabled_by": nil,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to enable user: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.Conflict("user is not disabled")
+	}
+	return nil
+}
+
+// UpdateUserProfile changes the display name and notification preferences of an account
+func (r *GormRepository) UpdateUserProfile(ctx context.Context, userID string, displayName string, notifications NotificationPreferences) error {
+	result := r.db.WithContext(ctx).Model(&User{}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{
+			"display_name":         displayName,
+			"notify_sign_requests": notifications.SignRequests,
+			"notify_reminders":     notifications.Reminders,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update user profile: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.NotFound("user")
+	}
+	return nil
+}
+
+// UpdateUserRole changes the role, admin scope and the linked student or teacher record of an account
+// All refresh tokens are revoked because they carry the old role
+func (r *GormRepository) UpdateUserRole(ctx context.Context, userID string, role crypto.Role, userUUID *string, admin crypto.AdminClaims) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"role":             role,
+			"user_uuid":        userUUID,
+			"admin_scope":      admin.AdminScope,
+			"admin_department": admin.Department,
+		}).Error; err != nil {
+			if errors.IsUniqueViolation(err) {
+				return errors.Conflict("the record is already linked to another account")
+			}
+			return fmt.Errorf("failed to update role: %w", err)
+		}
+
+		if err := tx.Where("user_id = ?", userID).Delete(&RefreshToken{}).Error; err != nil {
+			return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+		}
+		return nil
+	})
+}
+
+// DeleteUser permanently deletes an account for a data protection request
+// Credentials, sessions and identities are deleted with the account by the foreign keys,
+// failed login attempts are deleted and the username is removed from the security log
+func (r *GormRepository) DeleteUser(ctx context.Context, user *User) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("username = ?", user.Username).Delete(&LoginAttempt{}).Error; err != nil {
+			return fmt.Errorf("failed to delete login attempts: %w", err)
+		}
+
+		if err := tx.Model(&SecurityEvent{}).
+			Where("user_id = ? OR username = ?", user.ID, user.Username).
+			Update("username", "").Error; err != nil {
+			return fmt.Errorf("failed to anonymize security events: %w", err)
+		}
+
+		result := tx.Where("id = ?", user.ID).Delete(&User{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete user: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return errors.NotFound("user")
+		}
+		return nil
+	})
+}
+
+// Password management
+
+func (r *GormRepository) CreatePasswordResetToken(ctx context.Context, token *PasswordResetToken) error {
+	if err := r.db.WithContext(ctx).Create(token).Error; err != nil {
+		return fmt.Errorf("failed to create password reset token: %w", err)
+	}
+	return nil
+}
+
+func (r *GormRepository) GetPasswordResetToken(ctx context.Context, tokenHash string) (*PasswordResetToken, error) {
+	var token PasswordResetToken
+	if err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFound("password reset token")
+		}
+		return nil, fmt.Errorf("failed to get password reset token: %w", err)
+	}
+	return &token, nil
+}
+
+// ResetPassword consumes a reset token, sets the new password, lifts a lockout
+// and revokes all refresh tokens and other pending reset tokens of the user
+// The conditional update on used_at makes the token single-use even under concurrent requests
+func (r *GormRepository) ResetPassword(ctx context.Context, token *PasswordResetToken, passwordHash string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&PasswordResetToken{}).
+			Where("id = ? AND used_at IS NULL AND expires_at > ?", token.ID, now).
+			Update("used_at", now)
+		if result.Error != nil {
+			return fmt.Errorf("failed to use password reset token: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return errors.BadRequest("password reset token is invalid or expired")
+		}
+
+		if err := tx.Model(&User{}).Where("id = ?", token.UserID).Updates(map[string]interface{}{
+			"password_hash": passwordHash,
+			"locked_until":  nil,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update password: %w", err)
+		}
+
+		if err := tx.Where("user_id = ? AND used_at IS NULL", token.UserID).Delete(&PasswordResetToken{}).Error; err != nil {
+			return fmt.Errorf("failed to delete pending reset tokens: %w", err)
+		}
+
+		if err := tx.Where("user_id = ?", token.UserID).Delete(&RefreshToken{}).Error; err != nil {
+			return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// ChangePassword sets a new password and revokes all refresh tokens of the user
+func (r *GormRepository) ChangePassword(ctx context.Context, userID, passwordHash string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&User{}).Where("id = ?", userID).Update("password_hash", passwordHash).Error; err != nil {
+			return fmt.Errorf("failed to update password: %w", err)
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&RefreshToken{}).Error; err != nil {
+			return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+		}
+		return nil
+	})
+}
+
+// RehashPassword replaces a password hash with the same password in a newer format
+// Only applies if the hash was not changed in the meantime; sessions are kept
+func (r *GormRepository) RehashPassword(ctx context.Context, userID, oldHash, newHash string) error {
+	if err := r.db.WithContext(ctx).Model(&User{}).
+		Where("id = ? AND password_hash = ?", userID, oldHash).
+		Update("password_hash", newHash).Error; err != nil {
+		return fmt.Errorf("failed to rehash password: %w", err)
+	}
+	return nil
+}
+
+// Brute-force protection
+
+// RecordFailedLogin stores a failed attempt and deletes the attempts made before pruneBefore,
+// which no longer count towards any lockout, so the table doesn't grow without bound
+func (r *GormRepository) RecordFailedLogin(ctx context.Context, attempt *LoginAttempt, pruneBefore time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("attempted_at < ?", pruneBefore).Delete(&LoginAttempt{}).Error; err != nil {
+			return fmt.Errorf("failed to prune login attempts: %w", err)
+		}
+		if err := tx.Create(attempt).Error; err != nil {
+			return fmt.Errorf("failed to record login attempt: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetLoginFailureStats counts failed attempts since the given time for both the username and the IP address
+func (r *GormRepository) GetLoginFailureStats(ctx context.Context, username, ipAddress string, since time.Time) (*LoginFailureStats, error) {
+	type aggregate struct {
+		Count int64
+		Last  *time.Time
+	}
+
+	var byUsername, byIP aggregate
+	if err := r.db.WithContext(ctx).Model(&LoginAttempt{}).
+		Select("COUNT(*) AS count, MAX(attempted_at) AS last").
+		Where("username = ? AND attempted_at >= ?", username, since).
+		Scan(&byUsername).Error; err != nil {
+		return nil, fmt.Errorf("failed to count failed logins by username: %w", err)
+	}
+
+	if err := r.db.WithContext(ctx).Model(&LoginAttempt{}).
+		Select("COUNT(*) AS count, MAX(attempted_at) AS last").
+		Where("ip_address = ? AND attempted_at >= ?", ipAddress, since).
+		Scan(&byIP).Error; err != nil {
+		return nil, fmt.Errorf("failed to count failed logins by IP: %w", err)
+	}
+
+	return &LoginFailureStats{
+		UsernameFailures:    byUsername.Count,
+		UsernameLastFailure: byUsername.Last,
+		IPFailures:          byIP.Count,
+		IPLastFailure:       byIP.Last,
+	}, nil
+}
+
+func (r *GormRepository) ResetFailedLogins(ctx context.Context, username string) error {
+	if err := r.db.WithContext(ctx).Where("username = ?", username).Delete(&LoginAttempt{}).Error; err != nil {
+		return fmt.Errorf("failed to reset failed logins: %w", err)
+	}
+	return nil
+}
+
+func (r *GormRepository) CreateSecurityEvent(ctx context.Context, event *SecurityEvent) error {
+	if err := r.db.WithContext(ctx).Create(event).Error; err != nil {
+		return fmt.Errorf("failed to create security event: %w", err)
+	}
+	return nil
+}
+
+// Two-factor authentication
+
+// SetTOTPSecret stores a pending (not yet confirmed) TOTP secret
+func (r *GormRepository) SetTOTPSecret(ctx context.Context, userID, secret string) error {
+	if err := r.db.WithContext(ctx).Model(&User{}).Where("id = ?", userID).
+		Updates(map[string]interface{}{"totp_secret": secret, "totp_last_step": nil}).Error; err != nil {
+		return fmt.Errorf("failed to set TOTP secret: %w", err)
+	}
+	return nil
+}
+
+// EnableMFA activates two-factor authentication and replaces all recovery codes
+func (r *GormRepository) EnableMFA(ctx context.Context, userID string, recoveryCodeHashes []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&User{}).Where("id = ?", userID).Update("mfa_enabled", true).Error; err != nil {
+			return fmt.Errorf("failed to enable MFA: %w", err)
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&RecoveryCode{}).Error; err != nil {
+			return fmt.Errorf("failed to delete old recovery codes: %w", err)
+		}
+		codes := make([]RecoveryCode, len(recoveryCodeHashes))
+		for i, hash := range recoveryCodeHashes {
+			codes[i] = RecoveryCode{UserID: userID, CodeHash: hash}
+		}
+		if len(codes) > 0 {
+			if err := tx.Create(&codes).Error; err != nil {
+				return fmt.Errorf("failed to create recovery codes: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// DisableMFA removes the TOTP secret and all recovery codes
+func (r *GormRepository) DisableMFA(ctx context.Context, userID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"mfa_enabled":    false,
+			"totp_secret":    nil,
+			"totp_last_step": nil,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to disable MFA: %w", err)
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&RecoveryCode{}).Error; err != nil {
+			return fmt.Errorf("failed to delete recovery codes: %w", err)
+		}
+		return nil
+	})
+}
+
+// UseTOTPStep records the time step of an accepted TOTP code
+// Returns false if the step or a later one was already used - the conditional update makes this safe against concurrent use
+func (r *GormRepository) UseTOTPStep(ctx context.Context, userID string, step int64) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&User{}).
+		Where("id = ? AND (totp_last_step IS NULL OR totp_last_step < ?)", userID, step).
+		Update("totp_last_step", step)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to update TOTP step: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// UseRecoveryCode marks an unused recovery code as used
+// Returns false if no matching unused code exists - the conditional update makes this safe against concurrent use
+func (r *GormRepository) UseRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Passkeys (WebAuthn)
+
+func (r *GormRepository) CreatePasskeyCredential(ctx context.Context, credential *PasskeyCredential) error {
+	if err := r.db.WithContext(ctx).Create(credential).Error; err != nil {
+		if errors.IsUniqueViolation(err) {
+			return errors.Conflict("passkey is already registered")
+		}
+		return fmt.Errorf("failed to create passkey credential: %w", err)
+	}
+	return nil
+}
+
+func (r *GormRepository) GetPasskeyCredentialsByUserID(ctx context.Context, userID string) ([]PasskeyCredential, error) {
+	var credentials []PasskeyCredential
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at").Find(&credentials).Error; err != nil {
+		return nil, fmt.Errorf("failed to get passkey credentials: %w", err)
+	}
+	return credentials, nil
+}
+
+// UpdatePasskeyCredential stores the sign counter and flags after a successful login
+func (r *GormRepository) UpdatePasskeyCredential(ctx context.Context, credential *PasskeyCredential) error {
+	if err := r.db.WithContext(ctx).Model(&PasskeyCredential{}).Where("id = ?", credential.ID).Updates(map[string]interface{}{
+		"data":         credential.Data,
+		"sign_count":   credential.SignCount,
+		"last_used_at": credential.LastUsedAt,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to update passkey credential: %w", err)
+	}
+	return nil
+}
+
+// DeletePasskeyCredential removes a passkey, scoped to its owner
+func (r *GormRepository) DeletePasskeyCredential(ctx context.Context, userID, id string) error {
+	result := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&PasskeyCredential{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete passkey credential: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.NotFound("passkey")
+	}
+	return nil
+}
+
+func (r *GormRepository) CreateWebAuthnSession(ctx context.Context, session *WebAuthnSession) error {
+	if err := r.db.WithContext(ctx).Create(session).Error; err != nil {
+		return fmt.Errorf("failed to create webauthn session: %w", err)
+	}
+	return nil
+}
+
+// ConsumeWebAuthnSession loads and deletes a ceremony session so each challenge can only be answered once
+// Expired sessions of other ceremonies are cleaned up on the way
+func (r *GormRepository) ConsumeWebAuthnSession(ctx context.Context, id string) (*WebAuthnSession, error) {
+	var session WebAuthnSession
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ?", id).First(&session).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errors.NotFound("webauthn session")
+			}
+			return fmt.Errorf("failed to get webauthn session: %w", err)
+		}
+		result := tx.Where("id = ?", id).Delete(&WebAuthnSession{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete webauthn session: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			// Consumed concurrently by another request
+			return errors.NotFound("webauthn session")
+		}
+		return tx.Where("expires_at < ?", time.Now()).Delete(&WebAuthnSession{}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
 // Refresh token operations
 
 func (r *GormRepository) CreateRefreshToken(ctx context.Context, token *RefreshToken) error {
@@ -157,10 +789,64 @@ func (r *GormRepository) DeleteUserRefreshTokens(ctx context.Context, userID str
 	return nil
 }
 
+// Session management
+
+func (r *GormRepository) GetUserSessions(ctx context.Context, userID string) ([]RefreshToken, error) {
+	var sessions []RefreshToken
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND expires_at > ?", userID, time.Now()).
+		Order("COALESCE(last_used_at, created_at) DESC").
+		Find(&sessions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get user sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+func (r *GormRepository) TouchSession(ctx context.Context, id, ipAddress string, usedAt time.Time) error {
+	if err := r.db.WithContext(ctx).Model(&RefreshToken{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"ip_address":   ipAddress,
+			"last_used_at": usedAt,
+		}).Error; err != nil {
+		return fmt.Errorf("failed to update session: %w", err)
+	}
+	return nil
+}
+
+func (r *GormRepository) DeleteUserSession(ctx context.Context, userID, id string) error {
+	result := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&RefreshToken{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete session: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.NotFound("session")
+	}
+	return nil
+}
+
+func (r *GormRepository) DeleteOtherUserSessions(ctx context.Context, userID, keepID string) error {
+	if err := r.db.WithContext(ctx).Where("user_id = ? AND id <> ?", userID, keepID).Delete(&RefreshToken{}).Error; err != nil {
+		return fmt.Errorf("failed to delete other sessions: %w", err)
+	}
+	return nil
+}
+
 // Invitation operations
 
+// CreateInvitation stores a new invitation, a unique index allows only one open invitation per email
+// Expired open invitations for the email are deleted first, they would otherwise hold the index
 func (r *GormRepository) CreateInvitation(ctx context.Context, invitation *Invitation) error {
+	if err := r.db.WithContext(ctx).
+		Where("LOWER(email) = LOWER(?) AND used = ? AND revoked_at IS NULL AND expires_at <= ?", invitation.Email, false, time.Now()).
+		Delete(&Invitation{}).Error; err != nil {
+		return fmt.Errorf("failed to delete expired invitations: %w", err)
+	}
+
 	if err := r.db.WithContext(ctx).Create(invitation).Error; err != nil {
+		if errors.IsUniqueViolation(err) {
+			return errors.Conflict(fmt.Sprintf("an open invitation for %s already exists", invitation.Email))
+		}
 		return fmt.Errorf("failed to create invitation: %w", err)
 	}
 	return nil
@@ -177,16 +863,151 @@ func (r *GormRepository) GetInvitationByToken(ctx context.Context, token string)
 	return &invitation, nil
 }
 
+// MarkInvitationAsUsed consumes a pending invitation
+// Only succeeds once, so concurrent registrations with the same token cannot both complete
 func (r *GormRepository) MarkInvitationAsUsed(ctx context.Context, token string) error {
-	if err := r.db.WithContext(ctx).Model(&Invitation{}).Where("token = ?", token).Update("used", true).Error; err != nil {
-		return fmt.Errorf("failed to mark invitation as used: %w", err)
+	result := r.db.WithContext(ctx).Model(&Invitation{}).
+		Where("token = ? AND used = ? AND revoked_at IS NULL", token, false).
+		Updates(map[string]interface{}{
+			"used":    true,
+			"used_at": time.Now(),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to mark invitation as used: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.Conflict("invitation is no longer valid")
 	}
 	return nil
 }
 
-func (r *GormRepository) DeleteExpiredInvitations(ctx context.Context) error {
-	if err := r.db.WithContext(ctx).Where("expires_at < ?", time.Now()).Delete(&Invitation{}).Error; err != nil {
-		return fmt.Errorf("failed to delete expired invitations: %w", err)
+// DeleteExpiredInvitations deletes unused invitations that expired before the given time
+// Used invitations are kept as record of how an account was created
+func (r *GormRepository) DeleteExpiredInvitations(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("used = ? AND expires_at < ?", false, before).Delete(&Invitation{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete expired invitations: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+func (r *GormRepository) GetInvitationByID(ctx context.Context, id string) (*Invitation, error) {
+	var invitation Invitation
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&invitation).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFound("invitation")
+		}
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+	return &invitation, nil
+}
+
+// ListInvitations retrieves invitations matching the filter, newest first
+func (r *GormRepository) ListInvitations(ctx context.Context, filter InvitationFilter, params pagination.Params) ([]Invitation, int64, error) {
+	query := r.db.WithContext(ctx).Model(&Invitation{})
+
+	now := time.Now()
+	switch filter.Status {
+	case InvitationStatusPending:
+		query = query.Where("used = ? AND revoked_at IS NULL AND expires_at > ?", false, now)
+	case InvitationStatusUsed:
+		query = query.Where("used = ?", true)
+	case InvitationStatusRevoked:
+		query = query.Where("used = ? AND revoked_at IS NOT NULL", false)
+	case InvitationStatusExpired:
+		query = query.Where("used = ? AND revoked_at IS NULL AND expires_at <= ?", false, now)
+	}
+	if filter.Email != "" {
+		query = query.Where("LOWER(email) = LOWER(?)", filter.Email)
+	}
+	if filter.Department != "" {
+		query = query.Where("((role = ? AND LOWER(department) = LOWER(?)) OR "+
+			"(role = ? AND teacher_uuid IN (SELECT id FROM teachers WHERE LOWER(department) = LOWER(?) AND deleted_at IS NULL)))",
+			crypto.RoleTeacher, filter.Department, crypto.RoleStudent, filter.Department)
+	}
+
+	var totalCount int64
+	if err := query.Count(&totalCount).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count invitations: %w", err)
+	}
+
+	var invitations []Invitation
+	if err := query.
+		Offset(params.Offset()).
+		Limit(params.Limit).
+		Order("created_at DESC").
+		Find(&invitations).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list invitations: %w", err)
+	}
+
+	return invitations, totalCount, nil
+}
+
+// HasOpenInvitation checks if a pending (unused, not revoked, not expired) invitation exists for the email
+func (r *GormRepository) HasOpenInvitation(ctx context.Context, email string) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&Invitation{}).
+		Where("LOWER(email) = LOWER(?) AND used = ? AND revoked_at IS NULL AND expires_at > ?", email, false, time.Now()).
+		Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check open invitations: %w", err)
+	}
+	return count > 0, nil
+}
+
+// RevokeInvitation invalidates an unused invitation, keeping it for the audit trail
+func (r *GormRepository) RevokeInvitation(ctx context.Context, id, revokedBy string) error {
+	result := r.db.WithContext(ctx).Model(&Invitation{}).
+		Where("id = ? AND used = ? AND revoked_at IS NULL", id, false).
+		Updates(map[string]interface{}{
+			"revoked_at": time.Now(),
+			"revoked_by": revokedBy,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke invitation: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.Conflict("invitation is already used or revoked")
+	}
+	return nil
+}
+
+// GetOpenInvitationEmails returns which of the given emails (lowercased) have a pending invitation
+func (r *GormRepository) GetOpenInvitationEmails(ctx context.Context, emails []string) (map[string]bool, error) {
+	result := make(map[string]bool)
+	if len(emails) == 0 {
+		return result, nil
+	}
+
+	var found []string
+	if err := r.db.WithContext(ctx).Model(&Invitation{}).
+		Where("LOWER(email) IN ? AND used = ? AND revoked_at IS NULL AND expires_at > ?", emails, false, time.Now()).
+		Distinct().
+		Pluck("LOWER(email)", &found).Error; err != nil {
+		return nil, fmt.Errorf("failed to check open invitations: %w", err)
+	}
+
+	for _, email := range found {
+		result[email] = true
+	}
+	return result, nil
+}
+
+// ReissueInvitation replaces the token and expiry of an unused invitation
+// The old token stops working because invitations are looked up by token
+func (r *GormRepository) ReissueInvitation(ctx context.Context, id, token string, expiresAt time.Time) error {
+	result := r.db.WithContext(ctx).Model(&Invitation{}).
+		Where("id = ? AND used = ? AND revoked_at IS NULL", id, false).
+		Updates(map[string]interface{}{
+			"token":        token,
+			"expires_at":   expiresAt,
+			"resent_at":    time.Now(),
+			"resend_count": gorm.Expr("resend_count + 1"),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to reissue invitation: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.Conflict("invitation is already used or revoked")
 	}
 	return nil
 }
@@ -217,6 +1038,318 @@ func (r *GormRepository) CreateTeacher(ctx context.Context, teacher *TeacherReco
 	return nil
 }
 
+// GetActiveTeacher looks up a teacher that students and invitations may reference
+// Returns nil without error if the teacher does not exist or was deleted
+func (r *GormRepository) GetActiveTeacher(ctx context.Context, uuid string) (*reference.Teacher, error) {
+	return reference.FindActiveTeacher(ctx, r.db, uuid)
+}
+
+// GetTeachersByEmail resolves teacher emails (lowercased) to teachers, ignoring deleted teachers
+func (r *GormRepository) GetTeachersByEmail(ctx context.Context, emails []string) (map[string]*reference.Teacher, error) {
+	result := make(map[string]*reference.Teacher)
+	if len(emails) == 0 {
+		return result, nil
+	}
+
+	var teachers []reference.Teacher
+	if err := r.db.WithContext(ctx).Raw(`
+		SELECT id, LOWER(email) AS email, department FROM teachers
+		WHERE LOWER(email) IN ? AND deleted_at IS NULL
+	`, emails).Scan(&teachers).Error; err != nil {
+		return nil, fmt.Errorf("failed to look up teachers: %w", err)
+	}
+
+	for i := range teachers {
+		result[teachers[i].Email] = &teachers[i]
+	}
+	return result, nil
+}
+
+// GetUserContact looks up the student or teacher record of a user account
+// Returns nil without error if there is none, e.g. for admins
+func (r *GormRepository) GetUserContact(ctx context.Context, userUUID string) (*UserContact, error) {
+	var contacts []UserContact
+	if err := r.db.WithContext(ctx).Raw(`
+		SELECT first_name, last_name, email FROM students WHERE id = ? AND deleted_at IS NULL
+		UNION ALL
+		SELECT first_name, last_name, email FROM teachers WHERE id = ? AND deleted_at IS NULL
+	`, userUUID, userUUID).Scan(&contacts).Error; err != nil {
+		return nil, fmt.Errorf("failed to get user contact: %w", err)
+	}
+
+	if len(contacts) == 0 {
+		return nil, nil
+	}
+	return &contacts[0], nil
+}
+
+// EnqueueEmail stores an email in the outbox for the background sender
+func (r *GormRepository) EnqueueEmail(ctx context.Context, msg *mailer.OutboxMessage) error {
+	if err := r.db.WithContext(ctx).Create(msg).Error; err != nil {
+		return fmt.Errorf("failed to queue email: %w", err)
+	}
+	return nil
+}
+
+// OpenID Connect login
+
+// CreateOIDCLoginState stores the nonce and PKCE verifier of a started login
+func (r *GormRepository) CreateOIDCLoginState(ctx context.Context, state *OIDCLoginState) error {
+	if err := r.db.WithContext(ctx).Create(state).Error; err != nil {
+		return fmt.Errorf("failed to create oidc login state: %w", err)
+	}
+	return nil
+}
+
+// ConsumeOIDCLoginState loads and deletes a login state so each callback can only be used once
+// Expired states of abandoned logins are cleaned up on the way
+func (r *GormRepository) ConsumeOIDCLoginState(ctx context.Context, stateHash string) (*OIDCLoginState, error) {
+	var state OIDCLoginState
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("state_hash = ?", stateHash).First(&state).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errors.NotFound("oidc login state")
+			}
+			return fmt.Errorf("failed to get oidc login state: %w", err)
+		}
+		result := tx.Where("id = ?", state.ID).Delete(&OIDCLoginState{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete oidc login state: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			// Consumed concurrently by another request
+			return errors.NotFound("oidc login state")
+		}
+		return tx.Where("expires_at < ?", time.Now()).Delete(&OIDCLoginState{}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// GetOIDCIdentity retrieves the identity of a subject at an issuer
+func (r *GormRepository) GetOIDCIdentity(ctx context.Context, issuer, subject string) (*OIDCIdentity, error) {
+	var identity OIDCIdentity
+	if err := r.db.WithContext(ctx).Where("issuer = ? AND subject = ?", issuer, subject).First(&identity).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFound("oidc identity")
+		}
+		return nil, fmt.Errorf("failed to get oidc identity: %w", err)
+	}
+	return &identity, nil
+}
+
+// GetOIDCIdentityByID retrieves an identity by ID
+func (r *GormRepository) GetOIDCIdentityByID(ctx context.Context, id string) (*OIDCIdentity, error) {
+	var identity OIDCIdentity
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&identity).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFound("oidc identity")
+		}
+		return nil, fmt.Errorf("failed to get oidc identity: %w", err)
+	}
+	return &identity, nil
+}
+
+// CreateOIDCIdentity stores the identity of a subject seen for the first time
+func (r *GormRepository) CreateOIDCIdentity(ctx context.Context, identity *OIDCIdentity) error {
+	if err := r.db.WithContext(ctx).Create(identity).Error; err != nil {
+		if errors.IsUniqueViolation(err) {
+			return errors.Conflict("identity is already registered, please try again")
+		}
+		return fmt.Errorf("failed to create oidc identity: %w", err)
+	}
+	return nil
+}
+
+// UpdateOIDCIdentityProfile stores the email, name and last login reported at the latest login
+func (r *GormRepository) UpdateOIDCIdentityProfile(ctx context.Context, identity *OIDCIdentity) error {
+	if err := r.db.WithContext(ctx).
+		Model(&OIDCIdentity{}).
+		Where("id = ?", identity.ID).
+		Updates(map[string]interface{}{
+			"email":         identity.Email,
+			"name":          identity.Name,
+			"last_login_at": identity.LastLoginAt,
+		}).Error; err != nil {
+		return fmt.Errorf("failed to update oidc identity: %w", err)
+	}
+	return nil
+}
+
+// LinkOIDCIdentity links an identity to a user, linkedBy is nil for automatic links
+func (r *GormRepository) LinkOIDCIdentity(ctx context.Context, id, userID string, linkedBy *string) error {
+	result := r.db.WithContext(ctx).
+		Model(&OIDCIdentity{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"user_id":   userID,
+			"status":    OIDCIdentityStatusLinked,
+			"linked_by": linkedBy,
+			"linked_at": time.Now(),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to link oidc identity: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.NotFound("oidc identity")
+	}
+	return nil
+}
+
+// ListOIDCIdentities returns identities, newest first
+func (r *GormRepository) ListOIDCIdentities(ctx context.Context, filter OIDCIdentityFilter, params pagination.Params) ([]OIDCIdentity, int64, error) {
+	query := r.db.WithContext(ctx).Model(&OIDCIdentity{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.UserID != "" {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+
+	var totalCount int64
+	if err := query.Count(&totalCount).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count oidc identities: %w", err)
+	}
+
+	var identities []OIDCIdentity
+	if err := query.
+		Offset(params.Offset()).
+		Limit(params.Limit).
+		Order("created_at DESC").
+		Find(&identities).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list oidc identities: %w", err)
+	}
+
+	return identities, totalCount, nil
+}
+
+// DeleteOIDCIdentity removes an identity, rejecting a pending link or unlinking a user
+func (r *GormRepository) DeleteOIDCIdentity(ctx context.Context, id string) error {
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&OIDCIdentity{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete oidc identity: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.NotFound("oidc identity")
+	}
+	return nil
+}
+
+// GetUserByInvitationEmail finds the account that was registered with an invitation to the email
+// Returns nil without error if there is none or the email is ambiguous
+func (r *GormRepository) GetUserByInvitationEmail(ctx context.Context, email string) (*User, error) {
+	var users []User
+	if err := r.db.WithContext(ctx).Raw(`
+		SELECT users.* FROM users
+		JOIN (
+			SELECT id, email FROM students WHERE deleted_at IS NULL
+			UNION ALL
+			SELECT id, email FROM teachers WHERE deleted_at IS NULL
+		) AS profiles ON profiles.id = users.user_uuid
+		WHERE LOWER(profiles.email) = LOWER(?)
+		AND EXISTS (
+			SELECT 1 FROM invitations
+			WHERE LOWER(invitations.email) = LOWER(profiles.email) AND invitations.used = true
+		)
+		LIMIT 2
+	`, email).Scan(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to look up user by invitation email: %w", err)
+	}
+
+	if len(users) != 1 {
+		return nil, nil
+	}
+	return &users[0], nil
+}
+
+// Service account API keys
+
+// CreateAPIKey stores a new API key
+func (r *GormRepository) CreateAPIKey(ctx context.Context, key *APIKey) error {
+	if err := r.db.WithContext(ctx).Create(key).Error; err != nil {
+		return fmt.Errorf("failed to create api key: %w", err)
+	}
+	return nil
+}
+
+// GetAPIKeyByID retrieves an API key by ID
+func (r *GormRepository) GetAPIKeyByID(ctx context.Context, id string) (*APIKey, error) {
+	var key APIKey
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&key).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFound("api key")
+		}
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+	return &key, nil
+}
+
+// GetAPIKeyByLookupID retrieves the API key a presented key claims to be
+func (r *GormRepository) GetAPIKeyByLookupID(ctx context.Context, lookupID string) (*APIKey, error) {
+	var key APIKey
+	if err := r.db.WithContext(ctx).Where("lookup_id = ?", lookupID).First(&key).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFound("api key")
+		}
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+	return &key, nil
+}
+
+// ListAPIKeys returns all API keys including revoked ones, newest first
+func (r *GormRepository) ListAPIKeys(ctx context.Context, params pagination.Params) ([]APIKey, int64, error) {
+	query := r.db.WithContext(ctx).Model(&APIKey{})
+
+	var totalCount int64
+	if err := query.Count(&totalCount).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count api keys: %w", err)
+	}
+
+	var keys []APIKey
+	if err := query.
+		Offset(params.Offset()).
+		Limit(params.Limit).
+		Order("created_at DESC").
+		Find(&keys).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list api keys: %w", err)
+	}
+
+	return keys, totalCount, nil
+}
+
+// RevokeAPIKey revokes an API key, revokedBy is nil if the admin has no user account
+func (r *GormRepository) RevokeAPIKey(ctx context.Context, id string, revokedBy *string) error {
+	result := r.db.WithContext(ctx).Model(&APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"revoked_at": time.Now(),
+			"revoked_by": revokedBy,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke api key: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.Conflict("api key is already revoked")
+	}
+	return nil
+}
+
+// TouchAPIKey records the use of an API key
+// Keys used by frequent callers are only updated once per minute to avoid a write per request
+func (r *GormRepository) TouchAPIKey(ctx context.Context, id, ipAddress string, usedAt time.Time) error {
+	if err := r.db.WithContext(ctx).Model(&APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ? OR last_used_ip <> ?)", id, usedAt.Add(-time.Minute), ipAddress).
+		Updates(map[string]interface{}{
+			"last_used_at": usedAt,
+			"last_used_ip": ipAddress,
+		}).Error; err != nil {
+		return fmt.Errorf("failed to record api key use: %w", err)
+	}
+	return nil
+}
+
 // ExecuteInTransaction runs the given function within a database transaction
 // If the function returns an error, the transaction is automatically rolled back
 // Otherwise, the transaction is committed
@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"context"
+	"unicode/utf8"
+
+	"github.com/JustDoItBetter/FITS-backend/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// MaxUserAgentLength matches the size of refresh_tokens.user_agent
+const MaxUserAgentLength = 512
+
+// ListSessions returns the active sessions of a user, most recently used first
+// currentSessionID marks the session of the calling token, pass "" if the caller is someone else
+func (s *AuthService) ListSessions(ctx context.Context, userID, currentSessionID string) ([]SessionResponse, error) {
+	if _, err := s.repo.GetUserByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.repo.GetUserSessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, SessionResponse{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    currentSessionID != "" && session.ID == currentSessionID,
+		})
+	}
+
+	return result, nil
+}
+
+// RevokeSession ends a single session of a user
+// Access tokens already issued for the session stay valid until they expire
+func (s *AuthService) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	if err := s.repo.DeleteUserSession(ctx, userID, sessionID); err != nil {
+		return err
+	}
+
+	logger.Info("Session revoked",
+		zap.String("user_id", userID),
+		zap.String("session_id", sessionID),
+	)
+	return nil
+}
+
+// RevokeOtherSessions ends all sessions of a user except the current one
+// Without a current session (e.g. admin action) all sessions are ended
+func (s *AuthService) RevokeOtherSessions(ctx context.Context, userID, currentSessionID string) error {
+	if _, err := s.repo.GetUserByID(ctx, userID); err != nil {
+		return err
+	}
+
+	if currentSessionID == "" {
+		if err := s.repo.DeleteUserRefreshTokens(ctx, userID); err != nil {
+			return err
+		}
+	} else if err := s.repo.DeleteOtherUserSessions(ctx, userID, currentSessionID); err != nil {
+		return err
+	}
+
+	logger.Info("Sessions revoked",
+		zap.String("user_id", userID),
+		zap.Bool("kept_current", currentSessionID != ""),
+	)
+	return nil
+}
+
+// ListUserSessions returns the sessions of another user to an admin
+// Only full admins may see the sessions of admin accounts
+func (s *AuthService) ListUserSessions(ctx context.Context, userID, actorID string) ([]SessionResponse, error) {
+	if err := s.requireSessionManager(ctx, userID, actorID); err != nil {
+		return nil, err
+	}
+	return s.ListSessions(ctx, userID, "")
+}
+
+// RevokeUserSession ends a single session of another user on behalf of an admin
+func (s *AuthService) RevokeUserSession(ctx context.Context, userID, sessionID, actorID string) error {
+	if err := s.requireSessionManager(ctx, userID, actorID); err != nil {
+		return err
+	}
+	return s.RevokeSession(ctx, userID, sessionID)
+}
+
+// RevokeUserSessions ends all sessions of another user on behalf of an admin
+func (s *AuthService) RevokeUserSessions(ctx context.Context, userID, actorID string) error {
+	if err := s.requireSessionManager(ctx, userID, actorID); err != nil {
+		return err
+	}
+	return s.RevokeOtherSessions(ctx, userID, "")
+}
+
+// requireSessionManager checks that the actor may manage the sessions of the user
+func (s *AuthService) requireSessionManager(ctx context.Context, userID, actorID string) error {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return requireAdminManager(ctx, s.repo, actorID, user)
+}
+
+// truncateUserAgent limits the user agent to the column size without splitting a UTF-8 character
+func truncateUserAgent(userAgent string) string {
+	if len(userAgent) <= MaxUserAgentLength {
+		return userAgent
+	}
+	cut := MaxUserAgentLength
+	for cut > 0 && !utf8.RuneStart(userAgent[cut]) {
+		cut--
+	}
+	return userAgent[:cut]
+}
@@ -0,0 +1,200 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/errors"
+	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthService_IssueTokensRecordsSession(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	jwtService := crypto.NewJWTService("test-secret")
+	service := NewAuthService(mockRepo, jwtService, getTestJWTConfig(), getTestSecurityConfig(), getTestNotifier())
+	user := &User{ID: "user-123", Username: "testuser", Role: crypto.RoleStudent}
+
+	var stored *RefreshToken
+	mockRepo.On("CreateRefreshToken", ctx, mock.AnythingOfType("*auth.RefreshToken")).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(*RefreshToken) }).
+		Return(nil)
+	mockRepo.On("UpdateLastLogin", ctx, user.ID).Return(nil)
+
+	resp, err := service.issueTokens(ctx, user, testClient)
+	require.NoError(t, err)
+
+	require.NotNil(t, stored)
+	assert.NotEmpty(t, stored.ID)
+	assert.Equal(t, testClient.UserAgent, stored.UserAgent)
+	assert.Equal(t, testClientIP, stored.IPAddress)
+	assert.NotNil(t, stored.LastUsedAt)
+
+	// Both tokens carry the session ID so the session can be identified later
+	accessClaims, err := jwtService.ValidateToken(resp.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, stored.ID, accessClaims.SessionID)
+	refreshClaims, err := jwtService.ValidateToken(resp.RefreshToken)
+	require.NoError(t, err)
+	assert.Equal(t, stored.ID, refreshClaims.SessionID)
+}
+
+func TestAuthService_ListSessions(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	user := &User{ID: "user-123", Username: "testuser", Role: crypto.RoleStudent}
+	sessions := []RefreshToken{
+		{ID: "session-1", UserID: user.ID, Token: "secret-1", UserAgent: "Firefox", IPAddress: "192.0.2.10", LastUsedAt: &now, ExpiresAt: now.Add(time.Hour)},
+		{ID: "session-2", UserID: user.ID, Token: "secret-2", UserAgent: "Safari", IPAddress: "192.0.2.20", ExpiresAt: now.Add(time.Hour)},
+	}
+
+	t.Run("marks the current session", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewAuthService(mockRepo, crypto.NewJWTService("test-secret"), getTestJWTConfig(), getTestSecurityConfig(), getTestNotifier())
+		mockRepo.On("GetUserByID", ctx, user.ID).Return(user, nil)
+		mockRepo.On("GetUserSessions", ctx, user.ID).Return(sessions, nil)
+
+		result, err := service.ListSessions(ctx, user.ID, "session-2")
+
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+		assert.False(t, result[0].Current)
+		assert.True(t, result[1].Current)
+		assert.Equal(t, "Firefox", result[0].UserAgent)
+		assert.Equal(t, "192.0.2.20", result[1].IPAddress)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewAuthService(mockRepo, crypto.NewJWTService("test-secret"), getTestJWTConfig(), getTestSecurityConfig(), getTestNotifier())
+		mockRepo.On("GetUserByID", ctx, "missing").Return(nil, errors.NotFound("user"))
+
+		_, err := service.ListSessions(ctx, "missing", "")
+
+		assert.Error(t, err)
+		mockRepo.AssertNotCalled(t, "GetUserSessions", mock.Anything, mock.Anything)
+	})
+}
+
+func TestAuthService_RevokeSessions(t *testing.T) {
+	ctx := context.Background()
+	user := &User{ID: "user-123", Username: "testuser", Role: crypto.RoleStudent}
+
+	t.Run("revokes a single session", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewAuthService(mockRepo, crypto.NewJWTService("test-secret"), getTestJWTConfig(), getTestSecurityConfig(), getTestNotifier())
+		mockRepo.On("DeleteUserSession", ctx, user.ID, "session-1").Return(nil)
+
+		require.NoError(t, service.RevokeSession(ctx, user.ID, "session-1"))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("session of another user is not found", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewAuthService(mockRepo, crypto.NewJWTService("test-secret"), getTestJWTConfig(), getTestSecurityConfig(), getTestNotifier())
+		mockRepo.On("DeleteUserSession", ctx, user.ID, "foreign").Return(errors.NotFound("session"))
+
+		err := service.RevokeSession(ctx, user.ID, "foreign")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "session not found")
+	})
+
+	t.Run("revokes all other sessions", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewAuthService(mockRepo, crypto.NewJWTService("test-secret"), getTestJWTConfig(), getTestSecurityConfig(), getTestNotifier())
+		mockRepo.On("GetUserByID", ctx, user.ID).Return(user, nil)
+		mockRepo.On("DeleteOtherUserSessions", ctx, user.ID, "session-1").Return(nil)
+
+		require.NoError(t, service.RevokeOtherSessions(ctx, user.ID, "session-1"))
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "DeleteUserRefreshTokens", mock.Anything, mock.Anything)
+	})
+
+	t.Run("without current session revokes everything", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewAuthService(mockRepo, crypto.NewJWTService("test-secret"), getTestJWTConfig(), getTestSecurityConfig(), getTestNotifier())
+		mockRepo.On("GetUserByID", ctx, user.ID).Return(user, nil)
+		mockRepo.On("DeleteUserRefreshTokens", ctx, user.ID).Return(nil)
+
+		require.NoError(t, service.RevokeOtherSessions(ctx, user.ID, ""))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("logout only ends the current session", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewAuthService(mockRepo, crypto.NewJWTService("test-secret"), getTestJWTConfig(), getTestSecurityConfig(), getTestNotifier())
+		mockRepo.On("DeleteUserSession", ctx, user.ID, "session-1").Return(nil)
+
+		require.NoError(t, service.Logout(ctx, user.ID, "session-1"))
+		mockRepo.AssertNotCalled(t, "DeleteUserRefreshTokens", mock.Anything, mock.Anything)
+	})
+
+	t.Run("logout of an already revoked session succeeds", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewAuthService(mockRepo, crypto.NewJWTService("test-secret"), getTestJWTConfig(), getTestSecurityConfig(), getTestNotifier())
+		mockRepo.On("DeleteUserSession", ctx, user.ID, "session-1").Return(errors.NotFound("session"))
+
+		assert.NoError(t, service.Logout(ctx, user.ID, "session-1"))
+	})
+}
+
+func TestAuthService_AdminSessions(t *testing.T) {
+	ctx := context.Background()
+	actorID := "admin-1"
+	admin := &User{ID: "admin-2", Username: "anna.admin", Role: crypto.RoleAdmin, AdminScope: crypto.AdminScopeAuditor}
+	security := crypto.AdminClaims{AdminScope: crypto.AdminScopeSecurity}
+
+	t.Run("scoped admin can't touch sessions of an admin", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewAuthService(mockRepo, crypto.NewJWTService("test-secret"), getTestJWTConfig(), getTestSecurityConfig(), getTestNotifier())
+		mockRepo.On("GetUserByID", ctx, admin.ID).Return(admin, nil)
+		mockAdmin(mockRepo, ctx, actorID, security)
+
+		_, err := service.ListUserSessions(ctx, admin.ID, actorID)
+		assertAppErrorCode(t, err, 403)
+		assertAppErrorCode(t, service.RevokeUserSession(ctx, admin.ID, "session-1", actorID), 403)
+		assertAppErrorCode(t, service.RevokeUserSessions(ctx, admin.ID, actorID), 403)
+
+		mockRepo.AssertNotCalled(t, "GetUserSessions", mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "DeleteUserSession", mock.Anything, mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "DeleteUserRefreshTokens", mock.Anything, mock.Anything)
+	})
+
+	t.Run("full admin revokes sessions of an admin", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewAuthService(mockRepo, crypto.NewJWTService("test-secret"), getTestJWTConfig(), getTestSecurityConfig(), getTestNotifier())
+		mockRepo.On("GetUserByID", ctx, admin.ID).Return(admin, nil)
+		mockAdmin(mockRepo, ctx, actorID, crypto.AdminClaims{AdminScope: crypto.AdminScopeFull})
+		mockRepo.On("DeleteUserRefreshTokens", ctx, admin.ID).Return(nil)
+
+		require.NoError(t, service.RevokeUserSessions(ctx, admin.ID, actorID))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("scoped admin revokes sessions of a teacher", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewAuthService(mockRepo, crypto.NewJWTService("test-secret"), getTestJWTConfig(), getTestSecurityConfig(), getTestNotifier())
+		teacher := &User{ID: "user-1", Username: "t.meyer", Role: crypto.RoleTeacher}
+		mockRepo.On("GetUserByID", ctx, teacher.ID).Return(teacher, nil)
+		mockRepo.On("DeleteUserSession", ctx, teacher.ID, "session-1").Return(nil)
+
+		require.NoError(t, service.RevokeUserSession(ctx, teacher.ID, "session-1", actorID))
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestTruncateUserAgent(t *testing.T) {
+	assert.Equal(t, "curl/8.0", truncateUserAgent("curl/8.0"))
+
+	long := strings.Repeat("a", MaxUserAgentLength-1) + "ü"
+	truncated := truncateUserAgent(long)
+	assert.LessOrEqual(t, len(truncated), MaxUserAgentLength)
+	assert.True(t, utf8.ValidString(truncated))
+}
@@ -0,0 +1,388 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/errors"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/pagination"
+	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
+	"github.com/JustDoItBetter/FITS-backend/pkg/logger"
+)
+
+// errAccountDisabled is returned when a disabled account tries to log in
+func errAccountDisabled() error {
+	return errors.NewAppError(http.StatusForbidden, "Forbidden", "account is disabled, please contact an administrator")
+}
+
+// ListUsers returns user accounts with their student or teacher record for the admin overview
+func (s *AuthService) ListUsers(ctx context.Context, filter UserFilter, params pagination.Params) ([]UserSummary, int64, error) {
+	switch crypto.Role(filter.Role) {
+	case "", crypto.RoleAdmin, crypto.RoleTeacher, crypto.RoleStudent:
+	default:
+		return nil, 0, errors.ValidationError("role must be one of admin, teacher, student")
+	}
+
+	switch filter.Status {
+	case "", UserStatusActive, UserStatusDisabled, UserStatusLocked:
+	default:
+		return nil, 0, errors.ValidationError("status must be one of active, disabled, locked")
+	}
+
+	filter.Search = strings.TrimSpace(filter.Search)
+	return s.repo.ListUsers(ctx, filter, params)
+}
+
+// GetUser returns a user account with its student or teacher record
+func (s *AuthService) GetUser(ctx context.Context, userID string) (*UserSummary, error) {
+	return s.repo.GetUserSummary(ctx, userID)
+}
+
+// DisableUser blocks all logins of an account and revokes its sessions
+// actorID is the admin disabling the account and is recorded in the security log
+func (s *AuthService) DisableUser(ctx context.Context, userID, actorID string) (*UserSummary, error) {
+	if userID == actorID {
+		return nil, errors.ValidationError("you can't disable your own account")
+	}
+
+	var disabledBy *string
+	if actorID != "" {
+		disabledBy = &actorID
+	}
+
+	var user *User
+	err := s.repo.ExecuteInTransaction(ctx, func(repo Repository) error {
+		var err error
+		user, err = repo.GetUserByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if user.IsDisabled() {
+			return errors.Conflict("user is already disabled")
+		}
+		if err := requireAdminManager(ctx, repo, actorID, user); err != nil {
+			return err
+		}
+		if err := requireOtherAdmin(ctx, repo, user); err != nil {
+			return err
+		}
+		return repo.DisableUser(ctx, user.ID, disabledBy)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logSecurityEvent(ctx, &SecurityEvent{
+		EventType: SecurityEventAccountDisabled,
+		UserID:    &user.ID,
+		Username:  user.Username,
+		ActorID:   disabledBy,
+		Details:   "disabled by administrator",
+	})
+
+	return s.repo.GetUserSummary(ctx, user.ID)
+}
+
+// EnableUser allows a disabled account to log in again
+// Accounts of deleted student or teacher records stay disabled until the record is restored
+func (s *AuthService) EnableUser(ctx context.Context, userID, actorID string) (*UserSummary, error) {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireAdminManager(ctx, s.repo, actorID, user); err != nil {
+		return nil, err
+	}
+	if user.Role != crypto.RoleAdmin && user.UserUUID != nil {
+		exists, err := s.repo.HasActiveProfile(ctx, user.Role, *user.UserUUID)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, errors.Conflict(fmt.Sprintf("the %s record of this account is deleted", user.Role))
+		}
+	}
+
+	if err := s.repo.EnableUser(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
+	event := &SecurityEvent{
+		EventType: SecurityEventAccountEnabled,
+		UserID:    &user.ID,
+		Username:  user.Username,
+		Details:   "re-enabled by administrator",
+	}
+	if actorID != "" {
+		event.ActorID = &actorID
+	}
+	s.logSecurityEvent(ctx, event)
+
+	return s.repo.GetUserSummary(ctx, user.ID)
+}
+
+// ChangeUserRole changes the role of an account together with the record it belongs to
+// Teachers and students must be linked to an existing record of their role that has no other
+// account, admins have none but an admin scope. The account's sessions are revoked because tokens
+// carry the role and scope
+func (s *AuthService) ChangeUserRole(ctx context.Context, userID string, req *ChangeUserRoleRequest, actorID string) (*UserSummary, error) {
+	role := crypto.Role(strings.TrimSpace(req.Role))
+	userUUID := strings.TrimSpace(req.UserUUID)
+
+	switch role {
+	case crypto.RoleAdmin:
+		if userUUID != "" {
+			return nil, errors.ValidationError("user_uuid must be empty for admins")
+		}
+	case crypto.RoleTeacher, crypto.RoleStudent:
+		if userUUID == "" {
+			return nil, errors.ValidationError("user_uuid is required for teachers and students")
+		}
+		if _, err := uuid.Parse(userUUID); err != nil {
+			return nil, errors.ValidationError("user_uuid must be a valid UUID")
+		}
+	default:
+		return nil, errors.ValidationError("role must be one of admin, teacher, student")
+	}
+
+	admin, err := newAdminClaims(role, req.AdminScope, req.Department)
+	if err != nil {
+		return nil, err
+	}
+
+	if userID == actorID {
+		return nil, errors.ValidationError("you can't change your own role")
+	}
+
+	var link *string
+	if userUUID != "" {
+		link = &userUUID
+	}
+
+	var user *User
+	changed := false
+	err = s.repo.ExecuteInTransaction(ctx, func(repo Repository) error {
+		var err error
+		user, err = repo.GetUserByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if user.Role == role && stringValue(user.UserUUID) == userUUID && user.AdminClaims() == admin {
+			return nil
+		}
+		if user.Username == BootstrapAdminUsername {
+			return errors.Conflict("the role of the bootstrap admin account can't be changed")
+		}
+		if admin.AdminScope != crypto.AdminScopeFull {
+			if err := requireOtherAdmin(ctx, repo, user); err != nil {
+				return err
+			}
+		}
+		if role != crypto.RoleAdmin {
+			if err := checkProfileLink(ctx, repo, user, role, userUUID); err != nil {
+				return err
+			}
+		}
+
+		changed = true
+		return repo.UpdateUserRole(ctx, user.ID, role, link, admin)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if changed {
+		event := &SecurityEvent{
+			EventType: SecurityEventRoleChanged,
+			UserID:    &user.ID,
+			Username:  user.Username,
+			Details:   fmt.Sprintf("role changed from %s to %s by administrator", describeRole(user.Role, user.AdminClaims()), describeRole(role, admin)),
+		}
+		if actorID != "" {
+			event.ActorID = &actorID
+		}
+		s.logSecurityEvent(ctx, event)
+	}
+
+	return s.repo.GetUserSummary(ctx, user.ID)
+}
+
+// DeleteUser permanently deletes an account for a data protection request
+// The student or teacher record is kept, it is deleted through its own endpoint
+func (s *AuthService) DeleteUser(ctx context.Context, userID, actorID string) error {
+	if userID == actorID {
+		return errors.ValidationError("you can't delete your own account")
+	}
+
+	err := s.repo.ExecuteInTransaction(ctx, func(repo Repository) error {
+		user, err := repo.GetUserByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if user.Username == BootstrapAdminUsername {
+			return errors.Conflict("the bootstrap admin account can't be deleted")
+		}
+		if err := requireOtherAdmin(ctx, repo, user); err != nil {
+			return err
+		}
+		return repo.DeleteUser(ctx, user)
+	})
+	if err != nil {
+		return err
+	}
+
+	// The security log must not keep the username of a deleted account
+	event := &SecurityEvent{
+		EventType: SecurityEventAccountDeleted,
+		Details:   "account deleted by administrator",
+	}
+	if actorID != "" {
+		event.ActorID = &actorID
+	}
+	s.logSecurityEvent(ctx, event)
+
+	logger.Info("User deleted",
+		zap.String("user_id", userID),
+		zap.String("deleted_by", actorID),
+	)
+	return nil
+}
+
+// ValidateAccount implements middleware.AccountValidator
+// Tokens of deleted and disabled accounts and tokens with a role or admin scope the account no longer has are rejected
+func (s *AuthService) ValidateAccount(ctx context.Context, claims *crypto.Claims) error {
+	user, err := s.repo.GetUserByID(ctx, claims.UserID)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok && appErr.Code == 404 {
+			return errors.Unauthorized("account no longer exists")
+		}
+		return err
+	}
+
+	if user.IsDisabled() {
+		return errors.Unauthorized("account is disabled")
+	}
+	if user.Role != claims.Role {
+		return errors.Unauthorized("role has changed, please log in again")
+	}
+	tokenAdmin := crypto.AdminClaims{AdminScope: claims.EffectiveAdminScope(), Department: claims.Department}
+	if user.AdminClaims() != tokenAdmin {
+		return errors.Unauthorized("admin scope has changed, please log in again")
+	}
+	if stringValue(user.UserUUID) != claims.UserUUID {
+		return errors.Unauthorized("linked record has changed, please log in again")
+	}
+	return nil
+}
+
+// requireOtherAdmin prevents disabling, demoting or deleting the last active full admin
+// Nobody could manage admins anymore, as admins can only be created by full admins
+func requireOtherAdmin(ctx context.Context, repo Repository, user *User) error {
+	if user.AdminClaims().AdminScope != crypto.AdminScopeFull || user.IsDisabled() {
+		return nil
+	}
+
+	adminIDs, err := repo.GetActiveAdminIDs(ctx)
+	if err != nil {
+		return err
+	}
+	for _, id := range adminIDs {
+		if id != user.ID {
+			return nil
+		}
+	}
+	return errors.Conflict("the last active full admin can't be disabled, demoted or deleted")
+}
+
+// requireAdminManager lets only full admins act on admin accounts
+// A scoped admin could otherwise take over an account with more rights than its own
+func requireAdminManager(ctx context.Context, repo Repository, actorID string, target *User) error {
+	if target.Role != crypto.RoleAdmin {
+		return nil
+	}
+
+	if actorID != "" {
+		actor, err := repo.GetUserByID(ctx, actorID)
+		if err != nil {
+			if appErr, ok := err.(*errors.AppError); !ok || appErr.Code != 404 {
+				return err
+			}
+		} else if actor.AdminClaims().AdminScope == crypto.AdminScopeFull {
+			return nil
+		}
+	}
+	return errors.NewAppError(http.StatusForbidden, "Forbidden", "only full admins can manage admin accounts")
+}
+
+// newAdminClaims validates the admin scope and department requested for an account of the given role
+func newAdminClaims(role crypto.Role, scope, department string) (crypto.AdminClaims, error) {
+	adminScope := crypto.AdminScope(strings.TrimSpace(scope))
+	department = strings.TrimSpace(department)
+
+	if role != crypto.RoleAdmin {
+		if adminScope != "" || department != "" {
+			return crypto.AdminClaims{}, errors.ValidationError("admin_scope and department are only allowed for admins")
+		}
+		return crypto.AdminClaims{}, nil
+	}
+
+	if !crypto.IsValidAdminScope(adminScope) {
+		return crypto.AdminClaims{}, errors.ValidationError("admin_scope must be one of full, department, auditor, security")
+	}
+	if adminScope == crypto.AdminScopeDepartment {
+		if department == "" || len(department) > 100 {
+			return crypto.AdminClaims{}, errors.ValidationError("department is required for department admins and must be at most 100 characters")
+		}
+	} else if department != "" {
+		return crypto.AdminClaims{}, errors.ValidationError("department is only allowed for department admins")
+	}
+
+	return crypto.AdminClaims{AdminScope: adminScope, Department: department}, nil
+}
+
+// describeRole describes a role for the security log, including the scope of admins
+func describeRole(role crypto.Role, admin crypto.AdminClaims) string {
+	switch {
+	case admin.Department != "":
+		return fmt.Sprintf("%s (%s %s)", role, admin.AdminScope, admin.Department)
+	case admin.AdminScope != "":
+		return fmt.Sprintf("%s (%s)", role, admin.AdminScope)
+	}
+	return string(role)
+}
+
+// checkProfileLink checks that a teacher or student record exists and belongs to no other account
+func checkProfileLink(ctx context.Context, repo Repository, user *User, role crypto.Role, userUUID string) error {
+	exists, err := repo.HasActiveProfile(ctx, role, userUUID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errors.ValidationError(fmt.Sprintf("user_uuid must reference an existing %s", role))
+	}
+
+	owner, err := repo.GetUserByUserUUID(ctx, userUUID)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok && appErr.Code == 404 {
+			return nil
+		}
+		return err
+	}
+	if owner.ID != user.ID {
+		return errors.Conflict(fmt.Sprintf("the %s is already linked to another account", role))
+	}
+	return nil
+}
+
+// stringValue returns the value of an optional string, "" if it is nil
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,428 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/errors"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/pagination"
+	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
+)
+
+func newUserAdminTestService(repo *MockRepository) *AuthService {
+	return NewAuthService(repo, crypto.NewJWTService("test-secret"), getTestJWTConfig(), getTestSecurityConfig(), getTestNotifier())
+}
+
+func TestAuthService_ListUsers(t *testing.T) {
+	ctx := context.Background()
+	params := pagination.Params{Page: 1, Limit: 20}
+
+	t.Run("trims the search", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("ListUsers", ctx, UserFilter{Role: "teacher", Status: "disabled", Search: "anna"}, params).
+			Return([]UserSummary{}, int64(0), nil)
+
+		_, _, err := newUserAdminTestService(mockRepo).ListUsers(ctx, UserFilter{Role: "teacher", Status: "disabled", Search: " anna "}, params)
+
+		require.NoError(t, err)
+	})
+
+	t.Run("validates filters", func(t *testing.T) {
+		service := newUserAdminTestService(new(MockRepository))
+
+		_, _, err := service.ListUsers(ctx, UserFilter{Role: "service"}, params)
+		assertAppErrorCode(t, err, 422)
+
+		_, _, err = service.ListUsers(ctx, UserFilter{Status: "deleted"}, params)
+		assertAppErrorCode(t, err, 422)
+	})
+}
+
+func TestAuthService_DisableUser(t *testing.T) {
+	ctx := context.Background()
+	actorID := "admin-1"
+
+	t.Run("disables the account and logs the event", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		user := &User{ID: "user-1", Username: "max.mustermann", Role: crypto.RoleStudent}
+		mockRepo.On("GetUserByID", ctx, user.ID).Return(user, nil)
+		mockRepo.On("DisableUser", ctx, user.ID, &actorID).Return(nil)
+		mockRepo.On("CreateSecurityEvent", ctx, mock.AnythingOfType("*auth.SecurityEvent")).Return(nil)
+		mockRepo.On("GetUserSummary", ctx, user.ID).Return(&UserSummary{User: *user}, nil)
+
+		_, err := newUserAdminTestService(mockRepo).DisableUser(ctx, user.ID, actorID)
+
+		require.NoError(t, err)
+		mockRepo.AssertCalled(t, "DisableUser", ctx, user.ID, &actorID)
+		mockRepo.AssertCalled(t, "CreateSecurityEvent", ctx, mock.MatchedBy(func(e *SecurityEvent) bool {
+			return e.EventType == SecurityEventAccountDisabled && *e.UserID == user.ID && *e.ActorID == actorID
+		}))
+	})
+
+	t.Run("keeps the last active admin", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		admin := &User{ID: "admin-2", Username: "anna.admin", Role: crypto.RoleAdmin}
+		mockRepo.On("GetUserByID", ctx, admin.ID).Return(admin, nil)
+		mockAdmin(mockRepo, ctx, actorID, crypto.AdminClaims{AdminScope: crypto.AdminScopeFull})
+		mockRepo.On("GetActiveAdminIDs", ctx).Return([]string{admin.ID}, nil)
+
+		_, err := newUserAdminTestService(mockRepo).DisableUser(ctx, admin.ID, actorID)
+
+		assertAppErrorCode(t, err, 409)
+		mockRepo.AssertNotCalled(t, "DisableUser", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("admin with another active admin", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		admin := &User{ID: "admin-2", Username: "anna.admin", Role: crypto.RoleAdmin}
+		mockRepo.On("GetUserByID", ctx, admin.ID).Return(admin, nil)
+		mockAdmin(mockRepo, ctx, actorID, crypto.AdminClaims{AdminScope: crypto.AdminScopeFull})
+		mockRepo.On("GetActiveAdminIDs", ctx).Return([]string{actorID, admin.ID}, nil)
+		mockRepo.On("DisableUser", ctx, admin.ID, &actorID).Return(nil)
+		mockRepo.On("CreateSecurityEvent", ctx, mock.AnythingOfType("*auth.SecurityEvent")).Return(nil)
+		mockRepo.On("GetUserSummary", ctx, admin.ID).Return(&UserSummary{User: *admin}, nil)
+
+		_, err := newUserAdminTestService(mockRepo).DisableUser(ctx, admin.ID, actorID)
+
+		require.NoError(t, err)
+	})
+
+	t.Run("rejects own account", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+
+		_, err := newUserAdminTestService(mockRepo).DisableUser(ctx, actorID, actorID)
+
+		assertAppErrorCode(t, err, 422)
+		mockRepo.AssertNotCalled(t, "GetUserByID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("already disabled", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		now := time.Now()
+		mockRepo.On("GetUserByID", ctx, "user-1").Return(&User{ID: "user-1", Role: crypto.RoleStudent, DisabledAt: &now}, nil)
+
+		_, err := newUserAdminTestService(mockRepo).DisableUser(ctx, "user-1", actorID)
+
+		assertAppErrorCode(t, err, 409)
+	})
+}
+
+func TestAuthService_EnableUser(t *testing.T) {
+	ctx := context.Background()
+	actorID := "admin-1"
+	studentUUID := "550e8400-e29b-41d4-a716-446655440000"
+	now := time.Now()
+
+	t.Run("enables the account and logs the event", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		user := &User{ID: "user-1", Username: "max.mustermann", Role: crypto.RoleStudent, UserUUID: &studentUUID, DisabledAt: &now}
+		mockRepo.On("GetUserByID", ctx, user.ID).Return(user, nil)
+		mockRepo.On("HasActiveProfile", ctx, crypto.RoleStudent, studentUUID).Return(true, nil)
+		mockRepo.On("EnableUser", ctx, user.ID).Return(nil)
+		mockRepo.On("CreateSecurityEvent", ctx, mock.AnythingOfType("*auth.SecurityEvent")).Return(nil)
+		mockRepo.On("GetUserSummary", ctx, user.ID).Return(&UserSummary{User: *user}, nil)
+
+		_, err := newUserAdminTestService(mockRepo).EnableUser(ctx, user.ID, actorID)
+
+		require.NoError(t, err)
+		mockRepo.AssertCalled(t, "CreateSecurityEvent", ctx, mock.MatchedBy(func(e *SecurityEvent) bool {
+			return e.EventType == SecurityEventAccountEnabled && *e.UserID == user.ID && *e.ActorID == actorID
+		}))
+	})
+
+	t.Run("keeps the account of a deleted record disabled", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		user := &User{ID: "user-1", Username: "max.mustermann", Role: crypto.RoleStudent, UserUUID: &studentUUID, DisabledAt: &now}
+		mockRepo.On("GetUserByID", ctx, user.ID).Return(user, nil)
+		mockRepo.On("HasActiveProfile", ctx, crypto.RoleStudent, studentUUID).Return(false, nil)
+
+		_, err := newUserAdminTestService(mockRepo).EnableUser(ctx, user.ID, actorID)
+
+		assertAppErrorCode(t, err, 409)
+		mockRepo.AssertNotCalled(t, "EnableUser", mock.Anything, mock.Anything)
+	})
+}
+
+func TestAuthService_ChangeUserRole(t *testing.T) {
+	ctx := context.Background()
+	actorID := "admin-1"
+	teacherUUID := "550e8400-e29b-41d4-a716-446655440000"
+
+	t.Run("links the new record and revokes sessions", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		user := &User{ID: "user-1", Username: "max.mustermann", Role: crypto.RoleStudent, UserUUID: stringPtr("student-1")}
+		mockRepo.On("GetUserByID", ctx, user.ID).Return(user, nil)
+		mockRepo.On("HasActiveProfile", ctx, crypto.RoleTeacher, teacherUUID).Return(true, nil)
+		mockRepo.On("GetUserByUserUUID", ctx, teacherUUID).Return(nil, errors.NotFound("user"))
+		mockRepo.On("UpdateUserRole", ctx, user.ID, crypto.RoleTeacher, &teacherUUID, crypto.AdminClaims{}).Return(nil)
+		mockRepo.On("CreateSecurityEvent", ctx, mock.AnythingOfType("*auth.SecurityEvent")).Return(nil)
+		mockRepo.On("GetUserSummary", ctx, user.ID).Return(&UserSummary{User: *user}, nil)
+
+		_, err := newUserAdminTestService(mockRepo).ChangeUserRole(ctx, user.ID, &ChangeUserRoleRequest{Role: "teacher", UserUUID: teacherUUID}, actorID)
+
+		require.NoError(t, err)
+		mockRepo.AssertCalled(t, "UpdateUserRole", ctx, user.ID, crypto.RoleTeacher, &teacherUUID, crypto.AdminClaims{})
+		mockRepo.AssertCalled(t, "CreateSecurityEvent", ctx, mock.MatchedBy(func(e *SecurityEvent) bool {
+			return e.EventType == SecurityEventRoleChanged && e.Details == "role changed from student to teacher by administrator"
+		}))
+	})
+
+	t.Run("record linked to another account", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetUserByID", ctx, "user-1").Return(&User{ID: "user-1", Role: crypto.RoleStudent}, nil)
+		mockRepo.On("HasActiveProfile", ctx, crypto.RoleTeacher, teacherUUID).Return(true, nil)
+		mockRepo.On("GetUserByUserUUID", ctx, teacherUUID).Return(&User{ID: "user-2"}, nil)
+
+		_, err := newUserAdminTestService(mockRepo).ChangeUserRole(ctx, "user-1", &ChangeUserRoleRequest{Role: "teacher", UserUUID: teacherUUID}, actorID)
+
+		assertAppErrorCode(t, err, 409)
+		mockRepo.AssertNotCalled(t, "UpdateUserRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown record", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetUserByID", ctx, "user-1").Return(&User{ID: "user-1", Role: crypto.RoleStudent}, nil)
+		mockRepo.On("HasActiveProfile", ctx, crypto.RoleTeacher, teacherUUID).Return(false, nil)
+
+		_, err := newUserAdminTestService(mockRepo).ChangeUserRole(ctx, "user-1", &ChangeUserRoleRequest{Role: "teacher", UserUUID: teacherUUID}, actorID)
+
+		assertAppErrorCode(t, err, 422)
+	})
+
+	t.Run("promotion to admin unlinks the record", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		user := &User{ID: "user-1", Username: "anna.schmidt", Role: crypto.RoleTeacher, UserUUID: &teacherUUID}
+		mockRepo.On("GetUserByID", ctx, user.ID).Return(user, nil)
+		mockRepo.On("UpdateUserRole", ctx, user.ID, crypto.RoleAdmin, (*string)(nil), crypto.AdminClaims{AdminScope: crypto.AdminScopeFull}).Return(nil)
+		mockRepo.On("CreateSecurityEvent", ctx, mock.AnythingOfType("*auth.SecurityEvent")).Return(nil)
+		mockRepo.On("GetUserSummary", ctx, user.ID).Return(&UserSummary{User: *user}, nil)
+
+		_, err := newUserAdminTestService(mockRepo).ChangeUserRole(ctx, user.ID, &ChangeUserRoleRequest{Role: "admin", AdminScope: "full"}, actorID)
+
+		require.NoError(t, err)
+		mockRepo.AssertCalled(t, "UpdateUserRole", ctx, user.ID, crypto.RoleAdmin, (*string)(nil), crypto.AdminClaims{AdminScope: crypto.AdminScopeFull})
+	})
+
+	t.Run("keeps the last active admin", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetUserByID", ctx, "admin-2").Return(&User{ID: "admin-2", Username: "anna.admin", Role: crypto.RoleAdmin}, nil)
+		mockRepo.On("GetActiveAdminIDs", ctx).Return([]string{"admin-2"}, nil)
+
+		_, err := newUserAdminTestService(mockRepo).ChangeUserRole(ctx, "admin-2", &ChangeUserRoleRequest{Role: "teacher", UserUUID: teacherUUID}, actorID)
+
+		assertAppErrorCode(t, err, 409)
+	})
+
+	t.Run("bootstrap admin", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetUserByID", ctx, "admin-0").Return(&User{ID: "admin-0", Username: BootstrapAdminUsername, Role: crypto.RoleAdmin}, nil)
+
+		_, err := newUserAdminTestService(mockRepo).ChangeUserRole(ctx, "admin-0", &ChangeUserRoleRequest{Role: "teacher", UserUUID: teacherUUID}, actorID)
+
+		assertAppErrorCode(t, err, 409)
+	})
+
+	t.Run("unchanged role is a no-op", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		user := &User{ID: "user-1", Role: crypto.RoleTeacher, UserUUID: &teacherUUID}
+		mockRepo.On("GetUserByID", ctx, user.ID).Return(user, nil)
+		mockRepo.On("GetUserSummary", ctx, user.ID).Return(&UserSummary{User: *user}, nil)
+
+		_, err := newUserAdminTestService(mockRepo).ChangeUserRole(ctx, user.ID, &ChangeUserRoleRequest{Role: "teacher", UserUUID: teacherUUID}, actorID)
+
+		require.NoError(t, err)
+		mockRepo.AssertNotCalled(t, "UpdateUserRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("narrows a full admin to a department", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		admin := &User{ID: "admin-2", Username: "anna.admin", Role: crypto.RoleAdmin, AdminScope: crypto.AdminScopeFull}
+		department := crypto.AdminClaims{AdminScope: crypto.AdminScopeDepartment, Department: "IT"}
+		mockRepo.On("GetUserByID", ctx, admin.ID).Return(admin, nil)
+		mockRepo.On("GetActiveAdminIDs", ctx).Return([]string{actorID, admin.ID}, nil)
+		mockRepo.On("UpdateUserRole", ctx, admin.ID, crypto.RoleAdmin, (*string)(nil), department).Return(nil)
+		mockRepo.On("CreateSecurityEvent", ctx, mock.AnythingOfType("*auth.SecurityEvent")).Return(nil)
+		mockRepo.On("GetUserSummary", ctx, admin.ID).Return(&UserSummary{User: *admin}, nil)
+
+		_, err := newUserAdminTestService(mockRepo).ChangeUserRole(ctx, admin.ID,
+			&ChangeUserRoleRequest{Role: "admin", AdminScope: "department", Department: " IT "}, actorID)
+
+		require.NoError(t, err)
+		mockRepo.AssertCalled(t, "UpdateUserRole", ctx, admin.ID, crypto.RoleAdmin, (*string)(nil), department)
+		mockRepo.AssertCalled(t, "CreateSecurityEvent", ctx, mock.MatchedBy(func(e *SecurityEvent) bool {
+			return e.Details == "role changed from admin (full) to admin (department IT) by administrator"
+		}))
+	})
+
+	t.Run("keeps the last full admin", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetUserByID", ctx, "admin-2").Return(&User{ID: "admin-2", Username: "anna.admin", Role: crypto.RoleAdmin}, nil)
+		mockRepo.On("GetActiveAdminIDs", ctx).Return([]string{"admin-2"}, nil)
+
+		_, err := newUserAdminTestService(mockRepo).ChangeUserRole(ctx, "admin-2", &ChangeUserRoleRequest{Role: "admin", AdminScope: "auditor"}, actorID)
+
+		assertAppErrorCode(t, err, 409)
+		mockRepo.AssertNotCalled(t, "UpdateUserRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("validates request", func(t *testing.T) {
+		service := newUserAdminTestService(new(MockRepository))
+
+		for _, req := range []*ChangeUserRoleRequest{
+			{Role: "service"},
+			{Role: "admin", UserUUID: teacherUUID},
+			{Role: "admin"},
+			{Role: "admin", AdminScope: "superuser"},
+			{Role: "admin", AdminScope: "department"},
+			{Role: "admin", AdminScope: "auditor", Department: "IT"},
+			{Role: "teacher", UserUUID: teacherUUID, AdminScope: "full"},
+			{Role: "teacher"},
+			{Role: "student", UserUUID: "not-a-uuid"},
+		} {
+			_, err := service.ChangeUserRole(ctx, "user-1", req, actorID)
+			assertAppErrorCode(t, err, 422)
+		}
+
+		_, err := service.ChangeUserRole(ctx, actorID, &ChangeUserRoleRequest{Role: "teacher", UserUUID: teacherUUID}, actorID)
+		assertAppErrorCode(t, err, 422)
+	})
+}
+
+func TestAuthService_DeleteUser(t *testing.T) {
+	ctx := context.Background()
+	actorID := "admin-1"
+
+	t.Run("deletes the account without keeping its username", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		user := &User{ID: "user-1", Username: "max.mustermann", Role: crypto.RoleStudent}
+		mockRepo.On("GetUserByID", ctx, user.ID).Return(user, nil)
+		mockRepo.On("DeleteUser", ctx, user).Return(nil)
+		mockRepo.On("CreateSecurityEvent", ctx, mock.AnythingOfType("*auth.SecurityEvent")).Return(nil)
+
+		require.NoError(t, newUserAdminTestService(mockRepo).DeleteUser(ctx, user.ID, actorID))
+
+		mockRepo.AssertCalled(t, "DeleteUser", ctx, user)
+		mockRepo.AssertCalled(t, "CreateSecurityEvent", ctx, mock.MatchedBy(func(e *SecurityEvent) bool {
+			return e.EventType == SecurityEventAccountDeleted && e.UserID == nil && e.Username == ""
+		}))
+	})
+
+	t.Run("bootstrap admin", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetUserByID", ctx, "admin-0").Return(&User{ID: "admin-0", Username: BootstrapAdminUsername, Role: crypto.RoleAdmin}, nil)
+
+		assertAppErrorCode(t, newUserAdminTestService(mockRepo).DeleteUser(ctx, "admin-0", actorID), 409)
+		mockRepo.AssertNotCalled(t, "DeleteUser", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects own account", func(t *testing.T) {
+		assertAppErrorCode(t, newUserAdminTestService(new(MockRepository)).DeleteUser(ctx, actorID, actorID), 422)
+	})
+}
+
+func TestRequireAdminManager(t *testing.T) {
+	ctx := context.Background()
+	admin := &User{ID: "admin-2", Role: crypto.RoleAdmin, AdminScope: crypto.AdminScopeAuditor}
+
+	tests := []struct {
+		name     string
+		target   *User
+		actor    crypto.AdminClaims
+		wantCode int
+	}{
+		{"full admin manages admins", admin, crypto.AdminClaims{AdminScope: crypto.AdminScopeFull}, 0},
+		{"security admin manages users", &User{ID: "user-1", Role: crypto.RoleTeacher}, crypto.AdminClaims{AdminScope: crypto.AdminScopeSecurity}, 0},
+		{"security admin can't manage admins", admin, crypto.AdminClaims{AdminScope: crypto.AdminScopeSecurity}, 403},
+		{"department admin can't manage admins", admin, crypto.AdminClaims{AdminScope: crypto.AdminScopeDepartment, Department: "IT"}, 403},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			mockAdmin(mockRepo, ctx, "admin-1", tt.actor)
+
+			err := requireAdminManager(ctx, mockRepo, "admin-1", tt.target)
+
+			if tt.wantCode == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			assertAppErrorCode(t, err, tt.wantCode)
+		})
+	}
+}
+
+func TestAuthService_ValidateAccount(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	full := crypto.AdminClaims{AdminScope: crypto.AdminScopeFull}
+	auditor := crypto.AdminClaims{AdminScope: crypto.AdminScopeAuditor}
+
+	tests := []struct {
+		name     string
+		user     *User
+		role     crypto.Role
+		userUUID string
+		admin    crypto.AdminClaims
+		wantCode int
+	}{
+		{"active account", &User{ID: "user-1", Role: crypto.RoleTeacher}, crypto.RoleTeacher, "", crypto.AdminClaims{}, 0},
+		{"disabled account", &User{ID: "user-1", Role: crypto.RoleTeacher, DisabledAt: &now}, crypto.RoleTeacher, "", crypto.AdminClaims{}, 401},
+		{"changed role", &User{ID: "user-1", Role: crypto.RoleStudent}, crypto.RoleAdmin, "", full, 401},
+		{"deleted account", nil, crypto.RoleTeacher, "", crypto.AdminClaims{}, 401},
+		{"admin", &User{ID: "user-1", Role: crypto.RoleAdmin, AdminScope: crypto.AdminScopeAuditor}, crypto.RoleAdmin, "", auditor, 0},
+		{"admin token without scope", &User{ID: "user-1", Role: crypto.RoleAdmin}, crypto.RoleAdmin, "", crypto.AdminClaims{}, 0},
+		{"changed admin scope", &User{ID: "user-1", Role: crypto.RoleAdmin, AdminScope: crypto.AdminScopeAuditor}, crypto.RoleAdmin, "", full, 401},
+		{"changed department", &User{ID: "user-1", Role: crypto.RoleAdmin, AdminScope: crypto.AdminScopeDepartment, AdminDepartment: "IT"},
+			crypto.RoleAdmin, "", crypto.AdminClaims{AdminScope: crypto.AdminScopeDepartment, Department: "Wirtschaft"}, 401},
+		{"linked record", &User{ID: "user-1", Role: crypto.RoleStudent, UserUUID: stringPtr("student-1")}, crypto.RoleStudent, "student-1", crypto.AdminClaims{}, 0},
+		{"changed linked record", &User{ID: "user-1", Role: crypto.RoleStudent, UserUUID: stringPtr("student-2")}, crypto.RoleStudent, "student-1", crypto.AdminClaims{}, 401},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			if tt.user != nil {
+				mockRepo.On("GetUserByID", ctx, "user-1").Return(tt.user, nil)
+			} else {
+				mockRepo.On("GetUserByID", ctx, "user-1").Return(nil, errors.NotFound("user"))
+			}
+
+			claims := &crypto.Claims{UserID: "user-1", Role: tt.role, AccountClaims: crypto.AccountClaims{UserUUID: tt.userUUID, AdminClaims: tt.admin}}
+			err := newUserAdminTestService(mockRepo).ValidateAccount(ctx, claims)
+
+			if tt.wantCode == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			assertAppErrorCode(t, err, tt.wantCode)
+		})
+	}
+}
+
+func TestAuthService_LoginDisabledAccount(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := newUserAdminTestService(mockRepo)
+
+	passwordHash, err := crypto.HashPassword("SecurePassword123!")
+	require.NoError(t, err)
+	now := time.Now()
+	user := &User{ID: "user-1", Username: "max.mustermann", PasswordHash: passwordHash, Role: crypto.RoleStudent, DisabledAt: &now}
+
+	mockRepo.On("GetLoginFailureStats", ctx, user.Username, testClientIP, mock.AnythingOfType("time.Time")).Return(&LoginFailureStats{}, nil)
+	mockRepo.On("GetUserByUsername", ctx, user.Username).Return(user, nil)
+
+	_, err = service.Login(ctx, &LoginRequest{Username: user.Username, Password: "SecurePassword123!"}, testClient)
+
+	assertAppErrorCode(t, err, 403)
+	mockRepo.AssertNotCalled(t, "CreateRefreshToken", mock.Anything, mock.Anything)
+
+	_, err = service.issueTokens(ctx, user, testClient)
+	assertAppErrorCode(t, err, 403)
+}
@@ -0,0 +1,98 @@
+package me
+
+import (
+	"github.com/JustDoItBetter/FITS-backend/internal/common/access"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/response"
+	"github.com/JustDoItBetter/FITS-backend/internal/domain/auth"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Handler handles HTTP requests for the account of the caller
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new me handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registers all me endpoints with their required middleware
+// This provides a single source of truth for routes and their security requirements
+func (h *Handler) RegisterRoutes(router fiber.Router, jwtMW JWTMiddleware, rbacMW RBACMiddleware) {
+	// GET /api/v1/me - Account of the caller (users only, service accounts have no profile)
+	router.Get("/",
+		jwtMW.RequireAuth(),
+		rbacMW.RequireRole("admin", "teacher", "student"),
+		h.Get,
+	)
+
+	// PUT /api/v1/me - Edit the profile of the caller
+	router.Put("/",
+		jwtMW.RequireAuth(),
+		rbacMW.RequireRole("admin", "teacher", "student"),
+		h.Update,
+	)
+}
+
+// JWTMiddleware interface defines JWT authentication middleware requirements
+type JWTMiddleware interface {
+	RequireAuth() fiber.Handler
+}
+
+// RBACMiddleware interface defines role-based access control middleware
+type RBACMiddleware interface {
+	RequireRole(roles ...string) fiber.Handler
+}
+
+// Get godoc
+// @Summary Get own account
+// @Description Returns the account of the caller with its student or teacher record, the assigned teacher of a student
+// @Description or the students of a teacher (at most 100, see student_count) and the work waiting for the caller.
+// @Tags Me
+// @Produce json
+// @Success 200 {object} response.SuccessResponse{data=Me} "Account of the caller"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - missing or invalid token"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - service accounts have no profile"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/v1/me [get]
+func (h *Handler) Get(c *fiber.Ctx) error {
+	me, err := h.service.Get(c.Context(), access.FromContext(c))
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.Success(c, me)
+}
+
+// Update godoc
+// @Summary Edit own profile
+// @Description Changes the display name and notification preferences of the caller. Omitted fields are left unchanged.
+// @Tags Me
+// @Accept json
+// @Produce json
+// @Param request body auth.UpdateProfileRequest true "Profile fields to change"
+// @Success 200 {object} response.SuccessResponse{data=Me} "Updated account of the caller"
+// @Failure 400 {object} response.ErrorResponse "Invalid request body"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - missing or invalid token"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - service accounts have no profile"
+// @Failure 422 {object} response.ErrorResponse "Validation error - display name too long"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/v1/me [put]
+func (h *Handler) Update(c *fiber.Ctx) error {
+	var req auth.UpdateProfileRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, err)
+	}
+
+	me, err := h.service.Update(c.Context(), access.FromContext(c), &req)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.Success(c, me)
+}
@@ -0,0 +1,28 @@
+package me
+
+import (
+	"github.com/JustDoItBetter/FITS-backend/internal/domain/auth"
+	"github.com/JustDoItBetter/FITS-backend/internal/domain/student"
+	"github.com/JustDoItBetter/FITS-backend/internal/domain/teacher"
+)
+
+// Me is the authenticated user together with the records related to the account
+// @Description Account of the caller with its student or teacher record and relationships
+type Me struct {
+	User *auth.UserSummary `json:"user"`
+	// Linked record, set for students and teachers respectively
+	Student *student.Student `json:"student,omitempty"`
+	Teacher *teacher.Teacher `json:"teacher,omitempty"`
+	// Relationships: the teacher of a student, the students of a teacher
+	AssignedTeacher *teacher.Teacher   `json:"assigned_teacher,omitempty"`
+	Students        []*student.Student `json:"students,omitempty"`
+	StudentCount    int64              `json:"student_count,omitempty" example:"24"` // All students of a teacher, Students holds at most pagination.MaxLimit
+	Pending         PendingWork        `json:"pending"`
+}
+
+// PendingWork counts what is waiting for the user
+// @Description Work waiting for the caller
+type PendingWork struct {
+	SignRequests    int64  `json:"sign_requests" example:"3"`              // Reports to sign (teachers) or waiting for a signature (students)
+	OpenInvitations *int64 `json:"open_invitations,omitempty" example:"5"` // Admins who may list invitations only
+}
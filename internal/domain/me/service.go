@@ -0,0 +1,163 @@
+package me
+
+import (
+	"context"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/access"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/errors"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/pagination"
+	"github.com/JustDoItBetter/FITS-backend/internal/domain/auth"
+	"github.com/JustDoItBetter/FITS-backend/internal/domain/student"
+	"github.com/JustDoItBetter/FITS-backend/internal/domain/teacher"
+	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
+)
+
+// Accounts reads and edits user accounts, implemented by auth.AuthService
+type Accounts interface {
+	GetUser(ctx context.Context, userID string) (*auth.UserSummary, error)
+	UpdateProfile(ctx context.Context, userID string, req *auth.UpdateProfileRequest) (*auth.UserSummary, error)
+	CountOpenInvitations(ctx context.Context) (int64, error)
+}
+
+// Students reads the students visible to a viewer, implemented by student.Service
+type Students interface {
+	GetForViewer(ctx context.Context, uuid string, viewer access.Viewer) (*student.Student, error)
+	ListPaginated(ctx context.Context, params pagination.Params, filter student.ListFilter, viewer access.Viewer) ([]*student.Student, pagination.PageInfo, error)
+}
+
+// Teachers reads the teachers visible to a viewer, implemented by teacher.Service
+type Teachers interface {
+	GetForViewer(ctx context.Context, uuid string, viewer access.Viewer) (*teacher.Teacher, error)
+}
+
+// SignRequests counts the sign requests waiting for a viewer, implemented by signing.Service
+type SignRequests interface {
+	CountPending(ctx context.Context, viewer access.Viewer) (int64, error)
+}
+
+// byName sorts the students of a teacher alphabetically
+var byName = []pagination.SortField{{Field: "last_name"}, {Field: "first_name"}}
+
+// Service assembles the account of the caller from the auth, student, teacher and signing domains
+// Records are read through the read policies of their domain, so /me shows nothing the caller couldn't read anyway
+type Service struct {
+	accounts     Accounts
+	students     Students
+	teachers     Teachers
+	signRequests SignRequests
+}
+
+// NewService creates a new me service
+func NewService(accounts Accounts, students Students, teachers Teachers, signRequests SignRequests) *Service {
+	return &Service{
+		accounts:     accounts,
+		students:     students,
+		teachers:     teachers,
+		signRequests: signRequests,
+	}
+}
+
+// Get returns the account of the viewer with its linked record, relationships and pending work
+func (s *Service) Get(ctx context.Context, viewer access.Viewer) (*Me, error) {
+	if viewer.UserID == "" {
+		return nil, errors.Unauthorized("authentication required")
+	}
+
+	user, err := s.accounts.GetUser(ctx, viewer.UserID)
+	if err != nil {
+		return nil, err
+	}
+	me := &Me{User: user}
+
+	switch {
+	case viewer.Role == crypto.RoleStudent && viewer.UserUUID != "":
+		if err := s.addStudent(ctx, me, viewer); err != nil {
+			return nil, err
+		}
+	case viewer.Role == crypto.RoleTeacher && viewer.UserUUID != "":
+		if err := s.addTeacher(ctx, me, viewer); err != nil {
+			return nil, err
+		}
+	}
+
+	if me.Pending, err = s.pendingWork(ctx, viewer); err != nil {
+		return nil, err
+	}
+	return me, nil
+}
+
+// Update changes the profile fields of the viewer's account and returns the updated account
+func (s *Service) Update(ctx context.Context, viewer access.Viewer, req *auth.UpdateProfileRequest) (*Me, error) {
+	if viewer.UserID == "" {
+		return nil, errors.Unauthorized("authentication required")
+	}
+
+	if _, err := s.accounts.UpdateProfile(ctx, viewer.UserID, req); err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, viewer)
+}
+
+// addStudent adds the student record of the viewer and the assigned teacher
+// A teacher that was deleted in the meantime is left out
+func (s *Service) addStudent(ctx context.Context, me *Me, viewer access.Viewer) error {
+	record, err := s.students.GetForViewer(ctx, viewer.UserUUID, viewer)
+	if err != nil {
+		return err
+	}
+	me.Student = record
+
+	if record.TeacherID == nil || *record.TeacherID == "" {
+		return nil
+	}
+	assigned, err := s.teachers.GetForViewer(ctx, *record.TeacherID, viewer)
+	if err != nil && !isNotFound(err) {
+		return err
+	}
+	me.AssignedTeacher = assigned
+	return nil
+}
+
+// addTeacher adds the teacher record of the viewer and the first page of their students
+func (s *Service) addTeacher(ctx context.Context, me *Me, viewer access.Viewer) error {
+	record, err := s.teachers.GetForViewer(ctx, viewer.UserUUID, viewer)
+	if err != nil {
+		return err
+	}
+	me.Teacher = record
+
+	students, info, err := s.students.ListPaginated(ctx, pagination.Params{Page: 1, Limit: pagination.MaxLimit}, student.ListFilter{Sort: byName}, viewer)
+	if err != nil {
+		return err
+	}
+	me.Students = students
+	me.StudentCount = info.TotalCount
+	return nil
+}
+
+// pendingWork counts the work waiting for the viewer
+// Open invitations are only counted for admins who may list invitations, i.e. all but security admins
+func (s *Service) pendingWork(ctx context.Context, viewer access.Viewer) (PendingWork, error) {
+	var pending PendingWork
+
+	signRequests, err := s.signRequests.CountPending(ctx, viewer)
+	if err != nil {
+		return pending, err
+	}
+	pending.SignRequests = signRequests
+
+	if viewer.IsAdmin() && viewer.AdminScope != crypto.AdminScopeSecurity {
+		invitations, err := s.accounts.CountOpenInvitations(ctx)
+		if err != nil {
+			return pending, err
+		}
+		pending.OpenInvitations = &invitations
+	}
+	return pending, nil
+}
+
+// isNotFound reports whether err is a 404 application error
+func isNotFound(err error) bool {
+	appErr, ok := err.(*errors.AppError)
+	return ok && appErr.Code == 404
+}
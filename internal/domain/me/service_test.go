@@ -0,0 +1,204 @@
+package me
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/access"
+	apperrors "github.com/JustDoItBetter/FITS-backend/internal/common/errors"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/pagination"
+	"github.com/JustDoItBetter/FITS-backend/internal/domain/auth"
+	"github.com/JustDoItBetter/FITS-backend/internal/domain/student"
+	"github.com/JustDoItBetter/FITS-backend/internal/domain/teacher"
+	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
+)
+
+// MockAccounts is a mock implementation of the Accounts interface
+type MockAccounts struct {
+	mock.Mock
+}
+
+func (m *MockAccounts) GetUser(ctx context.Context, userID string) (*auth.UserSummary, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*auth.UserSummary), args.Error(1)
+}
+
+func (m *MockAccounts) UpdateProfile(ctx context.Context, userID string, req *auth.UpdateProfileRequest) (*auth.UserSummary, error) {
+	args := m.Called(ctx, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*auth.UserSummary), args.Error(1)
+}
+
+func (m *MockAccounts) CountOpenInvitations(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// MockStudents is a mock implementation of the Students interface
+type MockStudents struct {
+	mock.Mock
+}
+
+func (m *MockStudents) GetForViewer(ctx context.Context, uuid string, viewer access.Viewer) (*student.Student, error) {
+	args := m.Called(ctx, uuid, viewer)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*student.Student), args.Error(1)
+}
+
+func (m *MockStudents) ListPaginated(ctx context.Context, params pagination.Params, filter student.ListFilter, viewer access.Viewer) ([]*student.Student, pagination.PageInfo, error) {
+	args := m.Called(ctx, params, filter, viewer)
+	if args.Get(0) == nil {
+		return nil, pagination.PageInfo{}, args.Error(2)
+	}
+	return args.Get(0).([]*student.Student), args.Get(1).(pagination.PageInfo), args.Error(2)
+}
+
+// MockTeachers is a mock implementation of the Teachers interface
+type MockTeachers struct {
+	mock.Mock
+}
+
+func (m *MockTeachers) GetForViewer(ctx context.Context, uuid string, viewer access.Viewer) (*teacher.Teacher, error) {
+	args := m.Called(ctx, uuid, viewer)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*teacher.Teacher), args.Error(1)
+}
+
+// stubSignRequests counts no pending sign requests
+type stubSignRequests struct{}
+
+func (stubSignRequests) CountPending(ctx context.Context, viewer access.Viewer) (int64, error) {
+	return 0, nil
+}
+
+const (
+	studentUUID = "550e8400-e29b-41d4-a716-446655440000"
+	teacherUUID = "550e8400-e29b-41d4-a716-446655440010"
+)
+
+type mocks struct {
+	accounts *MockAccounts
+	students *MockStudents
+	teachers *MockTeachers
+}
+
+func newTestService() (*Service, mocks) {
+	m := mocks{accounts: new(MockAccounts), students: new(MockStudents), teachers: new(MockTeachers)}
+	return NewService(m.accounts, m.students, m.teachers, stubSignRequests{}), m
+}
+
+func TestService_Get(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("student with assigned teacher", func(t *testing.T) {
+		service, m := newTestService()
+		viewer := access.Viewer{UserID: "user-1", Role: crypto.RoleStudent, UserUUID: studentUUID}
+		assignedTeacher := teacherUUID
+		m.accounts.On("GetUser", ctx, "user-1").Return(&auth.UserSummary{}, nil)
+		m.students.On("GetForViewer", ctx, studentUUID, viewer).Return(&student.Student{UUID: studentUUID, TeacherID: &assignedTeacher}, nil)
+		m.teachers.On("GetForViewer", ctx, teacherUUID, viewer).Return(&teacher.Teacher{UUID: teacherUUID}, nil)
+
+		me, err := service.Get(ctx, viewer)
+
+		require.NoError(t, err)
+		assert.Equal(t, studentUUID, me.Student.UUID)
+		assert.Equal(t, teacherUUID, me.AssignedTeacher.UUID)
+		assert.Nil(t, me.Teacher)
+		assert.Nil(t, me.Pending.OpenInvitations)
+	})
+
+	t.Run("student whose teacher was deleted", func(t *testing.T) {
+		service, m := newTestService()
+		viewer := access.Viewer{UserID: "user-1", Role: crypto.RoleStudent, UserUUID: studentUUID}
+		assignedTeacher := teacherUUID
+		m.accounts.On("GetUser", ctx, "user-1").Return(&auth.UserSummary{}, nil)
+		m.students.On("GetForViewer", ctx, studentUUID, viewer).Return(&student.Student{UUID: studentUUID, TeacherID: &assignedTeacher}, nil)
+		m.teachers.On("GetForViewer", ctx, teacherUUID, viewer).Return(nil, apperrors.NotFound("teacher"))
+
+		me, err := service.Get(ctx, viewer)
+
+		require.NoError(t, err)
+		assert.Nil(t, me.AssignedTeacher)
+	})
+
+	t.Run("teacher with students", func(t *testing.T) {
+		service, m := newTestService()
+		viewer := access.Viewer{UserID: "user-2", Role: crypto.RoleTeacher, UserUUID: teacherUUID}
+		m.accounts.On("GetUser", ctx, "user-2").Return(&auth.UserSummary{}, nil)
+		m.teachers.On("GetForViewer", ctx, teacherUUID, viewer).Return(&teacher.Teacher{UUID: teacherUUID}, nil)
+		m.students.On("ListPaginated", ctx, pagination.Params{Page: 1, Limit: pagination.MaxLimit}, student.ListFilter{Sort: byName}, viewer).
+			Return([]*student.Student{{UUID: studentUUID}}, pagination.PageInfo{TotalCount: 1}, nil)
+
+		me, err := service.Get(ctx, viewer)
+
+		require.NoError(t, err)
+		assert.Equal(t, teacherUUID, me.Teacher.UUID)
+		assert.Len(t, me.Students, 1)
+		assert.Equal(t, int64(1), me.StudentCount)
+	})
+
+	t.Run("admin counts open invitations", func(t *testing.T) {
+		service, m := newTestService()
+		viewer := access.Viewer{UserID: "admin-1", Role: crypto.RoleAdmin, AdminScope: crypto.AdminScopeDepartment}
+		m.accounts.On("GetUser", ctx, "admin-1").Return(&auth.UserSummary{}, nil)
+		m.accounts.On("CountOpenInvitations", ctx).Return(int64(4), nil)
+
+		me, err := service.Get(ctx, viewer)
+
+		require.NoError(t, err)
+		require.NotNil(t, me.Pending.OpenInvitations)
+		assert.Equal(t, int64(4), *me.Pending.OpenInvitations)
+	})
+
+	t.Run("security admin doesn't count invitations", func(t *testing.T) {
+		service, m := newTestService()
+		viewer := access.Viewer{UserID: "admin-2", Role: crypto.RoleAdmin, AdminScope: crypto.AdminScopeSecurity}
+		m.accounts.On("GetUser", ctx, "admin-2").Return(&auth.UserSummary{}, nil)
+
+		me, err := service.Get(ctx, viewer)
+
+		require.NoError(t, err)
+		assert.Nil(t, me.Pending.OpenInvitations)
+		m.accounts.AssertNotCalled(t, "CountOpenInvitations", mock.Anything)
+	})
+
+	t.Run("anonymous caller", func(t *testing.T) {
+		service, _ := newTestService()
+
+		_, err := service.Get(ctx, access.Viewer{})
+
+		var appErr *apperrors.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, 401, appErr.Code)
+	})
+}
+
+func TestService_Update(t *testing.T) {
+	ctx := context.Background()
+	service, m := newTestService()
+	viewer := access.Viewer{UserID: "admin-1", Role: crypto.RoleAdmin, AdminScope: crypto.AdminScopeSecurity}
+	name := "Anna"
+	req := &auth.UpdateProfileRequest{DisplayName: &name}
+	updated := &auth.UserSummary{User: auth.User{ID: "admin-1", DisplayName: name}}
+	m.accounts.On("UpdateProfile", ctx, "admin-1", req).Return(updated, nil)
+	m.accounts.On("GetUser", ctx, "admin-1").Return(updated, nil)
+
+	me, err := service.Update(ctx, viewer, req)
+
+	require.NoError(t, err)
+	assert.Equal(t, "Anna", me.User.DisplayName)
+	m.accounts.AssertExpectations(t)
+}
@@ -0,0 +1,129 @@
+package role
+
+import (
+	"github.com/JustDoItBetter/FITS-backend/internal/common/response"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Handler handles HTTP requests for role operations
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new role handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registers all role endpoints with their required middleware
+// This provides a single source of truth for routes and their security requirements
+func (h *Handler) RegisterRoutes(router fiber.Router, jwtMW JWTMiddleware, rbacMW RBACMiddleware) {
+	// GET /api/v1/admin/roles - List roles (Admin only, auditors may read)
+	router.Get("/",
+		jwtMW.RequireAuth(),
+		rbacMW.RequireAdmin(),
+		h.List,
+	)
+
+	// GET /api/v1/admin/roles/:name - Get role (Admin only, auditors may read)
+	router.Get("/:name",
+		jwtMW.RequireAuth(),
+		rbacMW.RequireAdmin(),
+		h.Get,
+	)
+
+	// PUT /api/v1/admin/roles/:name - Replace the permissions of a role (Full admins only)
+	router.Put("/:name",
+		jwtMW.RequireAuth(),
+		rbacMW.RequireAdmin(),
+		h.Update,
+	)
+}
+
+// JWTMiddleware interface defines JWT authentication middleware requirements
+type JWTMiddleware interface {
+	RequireAuth() fiber.Handler
+}
+
+// RBACMiddleware interface defines role-based access control middleware
+type RBACMiddleware interface {
+	RequireAdmin() fiber.Handler
+}
+
+// List godoc
+// @Summary List roles
+// @Description Lists the roles with the permissions granted to them. The admin role has all permissions. Requires admin role.
+// @Tags Roles
+// @Produce json
+// @Success 200 {object} response.SuccessResponse{data=[]Role} "Roles with their permissions"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - missing or invalid token"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - requires admin role"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/v1/admin/roles [get]
+func (h *Handler) List(c *fiber.Ctx) error {
+	roles, err := h.service.List(c.Context())
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.Success(c, roles)
+}
+
+// Get godoc
+// @Summary Get role
+// @Description Retrieves a role with the permissions granted to it. Requires admin role.
+// @Tags Roles
+// @Produce json
+// @Param name path string true "Role name" example(teacher)
+// @Success 200 {object} response.SuccessResponse{data=Role} "Role found"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - missing or invalid token"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - requires admin role"
+// @Failure 404 {object} response.ErrorResponse "Role not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/v1/admin/roles/{name} [get]
+func (h *Handler) Get(c *fiber.Ctx) error {
+	role, err := h.service.Get(c.Context(), c.Params("name"))
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.Success(c, role)
+}
+
+// Update godoc
+// @Summary Edit role permissions
+// @Description Replaces the permissions of the teacher or student role; accounts of the role are affected immediately.
+// @Description The admin role always has all permissions, admins are limited by their admin scope instead. Requires full admin.
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Param name path string true "Role name" example(teacher)
+// @Param request body UpdateRoleRequest true "New permissions of the role"
+// @Success 200 {object} response.SuccessResponse{data=Role} "Role updated"
+// @Failure 400 {object} response.ErrorResponse "Invalid request body"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - missing or invalid token"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - requires full admin"
+// @Failure 404 {object} response.ErrorResponse "Role not found"
+// @Failure 409 {object} response.ErrorResponse "The admin role can't be edited"
+// @Failure 422 {object} response.ErrorResponse "Validation error - unknown permission"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/v1/admin/roles/{name} [put]
+func (h *Handler) Update(c *fiber.Ctx) error {
+	var req UpdateRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, err)
+	}
+
+	actorID, _ := c.Locals("user_id").(string)
+	role, err := h.service.Update(c.Context(), c.Params("name"), &req, actorID)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.Success(c, role)
+}
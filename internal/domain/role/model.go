@@ -0,0 +1,34 @@
+package role
+
+import (
+	"time"
+
+	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
+)
+
+// Role is an account role with the permissions granted to it
+// The roles themselves are fixed (admin, teacher, student), their permissions can be edited
+// @Description Role with its permissions
+type Role struct {
+	Name        crypto.Role         `json:"name" example:"teacher"`
+	Description string              `json:"description" example:"Supervises students and signs their reports"`
+	Permissions []crypto.Permission `json:"permissions" example:"student:read,report:sign"`
+	UpdatedAt   time.Time           `json:"updated_at" example:"2025-09-30T12:00:00Z"`
+}
+
+// HasPermission reports whether the role was granted permission
+func (r *Role) HasPermission(permission crypto.Permission) bool {
+	for _, p := range r.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateRoleRequest replaces the permissions of a role
+// @Description Request body for editing a role. The permissions replace the current ones.
+type UpdateRoleRequest struct {
+	Description *string  `json:"description,omitempty" example:"Supervises students and signs their reports" validate:"omitempty,max=255"`
+	Permissions []string `json:"permissions" example:"student:read,report:sign" validate:"required"`
+}
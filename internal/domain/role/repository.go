@@ -0,0 +1,17 @@
+package role
+
+import (
+	"context"
+
+	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
+)
+
+// Repository defines the interface for role data access
+type Repository interface {
+	// List returns all roles with their permissions, ordered by name
+	List(ctx context.Context) ([]*Role, error)
+	// Get returns a role with its permissions
+	Get(ctx context.Context, name crypto.Role) (*Role, error)
+	// Update replaces the description and permissions of a role
+	Update(ctx context.Context, role *Role) error
+}
@@ -0,0 +1,138 @@
+package role
+
+import (
+	"context"
+	"time"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/errors"
+	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
+	"gorm.io/gorm"
+)
+
+// RoleModel represents the GORM model for roles table
+type RoleModel struct {
+	Name        string    `gorm:"column:name;type:varchar(20);primaryKey"`
+	Description string    `gorm:"column:description;type:varchar(255);not null"`
+	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+// TableName specifies the table name for GORM
+func (RoleModel) TableName() string {
+	return "roles"
+}
+
+// RolePermissionModel represents the GORM model for role_permissions table
+// Each row grants one permission to one role
+type RolePermissionModel struct {
+	Role       string `gorm:"column:role;type:varchar(20);primaryKey"`
+	Permission string `gorm:"column:permission;type:varchar(50);primaryKey"`
+}
+
+// TableName specifies the table name for GORM
+func (RolePermissionModel) TableName() string {
+	return "role_permissions"
+}
+
+// GormRepository implements Repository interface using GORM
+type GormRepository struct {
+	db *gorm.DB
+}
+
+// NewGormRepository creates a new GORM-based role repository
+func NewGormRepository(db *gorm.DB) Repository {
+	return &GormRepository{db: db}
+}
+
+// List returns all roles with their permissions, ordered by name
+func (r *GormRepository) List(ctx context.Context) ([]*Role, error) {
+	var models []RoleModel
+	if err := r.db.WithContext(ctx).Order("name ASC").Find(&models).Error; err != nil {
+		return nil, errors.Internal("failed to list roles: " + err.Error())
+	}
+
+	var grants []RolePermissionModel
+	if err := r.db.WithContext(ctx).Order("permission ASC").Find(&grants).Error; err != nil {
+		return nil, errors.Internal("failed to list role permissions: " + err.Error())
+	}
+	permissions := make(map[string][]crypto.Permission)
+	for _, grant := range grants {
+		permissions[grant.Role] = append(permissions[grant.Role], crypto.Permission(grant.Permission))
+	}
+
+	roles := make([]*Role, len(models))
+	for i, model := range models {
+		roles[i] = toRole(&model, permissions[model.Name])
+	}
+	return roles, nil
+}
+
+// Get returns a role with its permissions
+func (r *GormRepository) Get(ctx context.Context, name crypto.Role) (*Role, error) {
+	var model RoleModel
+	if err := r.db.WithContext(ctx).Where("name = ?", string(name)).First(&model).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFound("role")
+		}
+		return nil, errors.Internal("failed to get role: " + err.Error())
+	}
+
+	var grants []RolePermissionModel
+	if err := r.db.WithContext(ctx).Where("role = ?", model.Name).Order("permission ASC").Find(&grants).Error; err != nil {
+		return nil, errors.Internal("failed to get role permissions: " + err.Error())
+	}
+	permissions := make([]crypto.Permission, len(grants))
+	for i, grant := range grants {
+		permissions[i] = crypto.Permission(grant.Permission)
+	}
+
+	return toRole(&model, permissions), nil
+}
+
+// Update replaces the description and permissions of a role in one transaction
+func (r *GormRepository) Update(ctx context.Context, role *Role) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&RoleModel{}).
+			Where("name = ?", string(role.Name)).
+			Updates(map[string]interface{}{
+				"description": role.Description,
+				"updated_at":  now,
+			})
+		if result.Error != nil {
+			return errors.Internal("failed to update role: " + result.Error.Error())
+		}
+		if result.RowsAffected == 0 {
+			return errors.NotFound("role")
+		}
+
+		if err := tx.Where("role = ?", string(role.Name)).Delete(&RolePermissionModel{}).Error; err != nil {
+			return errors.Internal("failed to update role permissions: " + err.Error())
+		}
+		if len(role.Permissions) > 0 {
+			grants := make([]RolePermissionModel, len(role.Permissions))
+			for i, permission := range role.Permissions {
+				grants[i] = RolePermissionModel{Role: string(role.Name), Permission: string(permission)}
+			}
+			if err := tx.Create(&grants).Error; err != nil {
+				return errors.Internal("failed to update role permissions: " + err.Error())
+			}
+		}
+
+		role.UpdatedAt = now
+		return nil
+	})
+}
+
+// toRole converts a RoleModel and its permissions to a Role
+func toRole(model *RoleModel, permissions []crypto.Permission) *Role {
+	if permissions == nil {
+		permissions = []crypto.Permission{}
+	}
+	return &Role{
+		Name:        crypto.Role(model.Name),
+		Description: model.Description,
+		Permissions: permissions,
+		UpdatedAt:   model.UpdatedAt,
+	}
+}
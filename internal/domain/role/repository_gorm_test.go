@@ -0,0 +1,101 @@
+package role
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	apperrors "github.com/JustDoItBetter/FITS-backend/internal/common/errors"
+	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
+)
+
+// setupTestDB creates an in-memory SQLite database seeded like migration 017
+func setupTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err, "failed to create test database")
+
+	require.NoError(t, db.AutoMigrate(&RoleModel{}, &RolePermissionModel{}), "failed to migrate test database")
+	require.NoError(t, db.Create(&[]RoleModel{
+		{Name: "admin", Description: "Manages the system"},
+		{Name: "teacher", Description: "Supervises students"},
+		{Name: "student", Description: "Uploads reports"},
+	}).Error)
+	require.NoError(t, db.Create(&[]RolePermissionModel{
+		{Role: "teacher", Permission: "student:read"},
+		{Role: "teacher", Permission: "report:sign"},
+		{Role: "student", Permission: "report:upload"},
+	}).Error)
+
+	return db
+}
+
+func TestGormRepository_List(t *testing.T) {
+	repo := NewGormRepository(setupTestDB(t))
+
+	roles, err := repo.List(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, roles, 3)
+	assert.Equal(t, crypto.RoleAdmin, roles[0].Name)
+	assert.Empty(t, roles[0].Permissions)
+	assert.Equal(t, crypto.RoleStudent, roles[1].Name)
+	assert.Equal(t, []crypto.Permission{crypto.PermissionReportUpload}, roles[1].Permissions)
+	assert.Equal(t, []crypto.Permission{crypto.PermissionReportSign, crypto.PermissionStudentRead}, roles[2].Permissions)
+}
+
+func TestGormRepository_Get(t *testing.T) {
+	repo := NewGormRepository(setupTestDB(t))
+	ctx := context.Background()
+
+	t.Run("existing role", func(t *testing.T) {
+		role, err := repo.Get(ctx, crypto.RoleTeacher)
+
+		require.NoError(t, err)
+		assert.Equal(t, "Supervises students", role.Description)
+		assert.True(t, role.HasPermission(crypto.PermissionReportSign))
+		assert.False(t, role.HasPermission(crypto.PermissionStudentWrite))
+	})
+
+	t.Run("unknown role", func(t *testing.T) {
+		_, err := repo.Get(ctx, "service")
+
+		appErr, ok := err.(*apperrors.AppError)
+		require.True(t, ok)
+		assert.Equal(t, 404, appErr.Code)
+	})
+}
+
+func TestGormRepository_Update(t *testing.T) {
+	repo := NewGormRepository(setupTestDB(t))
+	ctx := context.Background()
+
+	t.Run("replaces the permissions", func(t *testing.T) {
+		role := &Role{Name: crypto.RoleTeacher, Description: "Signs reports", Permissions: []crypto.Permission{crypto.PermissionReportRead}}
+		require.NoError(t, repo.Update(ctx, role))
+
+		updated, err := repo.Get(ctx, crypto.RoleTeacher)
+		require.NoError(t, err)
+		assert.Equal(t, "Signs reports", updated.Description)
+		assert.Equal(t, []crypto.Permission{crypto.PermissionReportRead}, updated.Permissions)
+	})
+
+	t.Run("removes all permissions", func(t *testing.T) {
+		require.NoError(t, repo.Update(ctx, &Role{Name: crypto.RoleStudent}))
+
+		updated, err := repo.Get(ctx, crypto.RoleStudent)
+		require.NoError(t, err)
+		assert.Empty(t, updated.Permissions)
+	})
+
+	t.Run("unknown role", func(t *testing.T) {
+		err := repo.Update(ctx, &Role{Name: "service", Permissions: []crypto.Permission{crypto.PermissionReportRead}})
+
+		appErr, ok := err.(*apperrors.AppError)
+		require.True(t, ok)
+		assert.Equal(t, 404, appErr.Code)
+	})
+}
@@ -0,0 +1,174 @@
+package role
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/errors"
+	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
+	"github.com/JustDoItBetter/FITS-backend/pkg/logger"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+// permissionCacheTTL is how long permissions are served from memory before they are reloaded
+// Edits on this instance apply at once, other instances pick them up after the TTL
+const permissionCacheTTL = 30 * time.Second
+
+// Service handles business logic for roles and answers permission checks of the middleware
+type Service struct {
+	repo     Repository
+	validate *validator.Validate
+
+	mu          sync.RWMutex
+	permissions map[crypto.Role]map[crypto.Permission]bool
+	loadedAt    time.Time
+}
+
+// NewService creates a new role service
+func NewService(repo Repository) *Service {
+	return &Service{
+		repo:     repo,
+		validate: validator.New(),
+	}
+}
+
+// List returns all roles with their permissions
+func (s *Service) List(ctx context.Context) ([]*Role, error) {
+	roles, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, role := range roles {
+		withAdminPermissions(role)
+	}
+	return roles, nil
+}
+
+// Get returns a role with its permissions
+func (s *Service) Get(ctx context.Context, name string) (*Role, error) {
+	role, err := s.repo.Get(ctx, crypto.Role(name))
+	if err != nil {
+		return nil, err
+	}
+	return withAdminPermissions(role), nil
+}
+
+// Update replaces the permissions of a role
+// The admin role always has all permissions and can't be edited, its admins are limited by their admin scope instead
+// actorID is the admin editing the role, logged for the audit trail
+func (s *Service) Update(ctx context.Context, name string, req *UpdateRoleRequest, actorID string) (*Role, error) {
+	if err := s.validate.Struct(req); err != nil {
+		return nil, errors.ValidationError(err.Error())
+	}
+	if crypto.Role(name) == crypto.RoleAdmin {
+		return nil, errors.Conflict("the admin role always has all permissions, limit admins with their admin scope instead")
+	}
+
+	permissions, err := parsePermissions(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := s.repo.Get(ctx, crypto.Role(name))
+	if err != nil {
+		return nil, err
+	}
+	if req.Description != nil {
+		role.Description = strings.TrimSpace(*req.Description)
+	}
+	role.Permissions = permissions
+
+	if err := s.repo.Update(ctx, role); err != nil {
+		return nil, err
+	}
+	s.invalidate()
+
+	logger.Info("Role permissions changed",
+		zap.String("role", name),
+		zap.Strings("permissions", req.Permissions),
+		zap.String("changed_by", actorID),
+	)
+	return role, nil
+}
+
+// HasPermission reports whether role was granted permission
+// Implements middleware.PermissionResolver; roles are cached for permissionCacheTTL
+func (s *Service) HasPermission(ctx context.Context, role crypto.Role, permission crypto.Permission) (bool, error) {
+	if role == crypto.RoleAdmin {
+		return true, nil
+	}
+
+	s.mu.RLock()
+	permissions, loadedAt := s.permissions, s.loadedAt
+	s.mu.RUnlock()
+
+	if permissions == nil || time.Since(loadedAt) > permissionCacheTTL {
+		var err error
+		if permissions, err = s.load(ctx); err != nil {
+			return false, err
+		}
+	}
+	return permissions[role][permission], nil
+}
+
+// load reads all roles from the repository into the cache
+func (s *Service) load(ctx context.Context) (map[crypto.Role]map[crypto.Permission]bool, error) {
+	roles, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := make(map[crypto.Role]map[crypto.Permission]bool, len(roles))
+	for _, role := range roles {
+		granted := make(map[crypto.Permission]bool, len(role.Permissions))
+		for _, permission := range role.Permissions {
+			granted[permission] = true
+		}
+		permissions[role.Name] = granted
+	}
+
+	s.mu.Lock()
+	s.permissions = permissions
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+	return permissions, nil
+}
+
+// invalidate makes the next permission check reload the roles
+func (s *Service) invalidate() {
+	s.mu.Lock()
+	s.permissions = nil
+	s.mu.Unlock()
+}
+
+// parsePermissions validates the requested permissions and returns them sorted without duplicates
+func parsePermissions(values []string) ([]crypto.Permission, error) {
+	seen := make(map[crypto.Permission]bool, len(values))
+	permissions := make([]crypto.Permission, 0, len(values))
+	for _, value := range values {
+		permission := crypto.Permission(strings.TrimSpace(value))
+		if !crypto.IsValidPermission(permission) {
+			return nil, errors.ValidationError(fmt.Sprintf("unknown permission '%s'", value))
+		}
+		if !seen[permission] {
+			seen[permission] = true
+			permissions = append(permissions, permission)
+		}
+	}
+
+	sort.Slice(permissions, func(i, j int) bool { return permissions[i] < permissions[j] })
+	return permissions, nil
+}
+
+// withAdminPermissions shows all permissions for the admin role, which is granted everything
+func withAdminPermissions(role *Role) *Role {
+	if role.Name == crypto.RoleAdmin {
+		role.Permissions = append([]crypto.Permission(nil), crypto.Permissions...)
+	}
+	return role
+}
@@ -0,0 +1,105 @@
+package role
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apperrors "github.com/JustDoItBetter/FITS-backend/internal/common/errors"
+	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
+)
+
+func assertAppErrorCode(t *testing.T, err error, code int) {
+	t.Helper()
+	appErr, ok := err.(*apperrors.AppError)
+	require.True(t, ok, "expected AppError, got %v", err)
+	assert.Equal(t, code, appErr.Code)
+}
+
+func TestService_HasPermission(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(NewGormRepository(setupTestDB(t)))
+
+	granted, err := service.HasPermission(ctx, crypto.RoleTeacher, crypto.PermissionReportSign)
+	require.NoError(t, err)
+	assert.True(t, granted)
+
+	granted, err = service.HasPermission(ctx, crypto.RoleStudent, crypto.PermissionReportSign)
+	require.NoError(t, err)
+	assert.False(t, granted)
+
+	granted, err = service.HasPermission(ctx, crypto.RoleService, crypto.PermissionReportUpload)
+	require.NoError(t, err)
+	assert.False(t, granted, "roles without row have no permissions")
+
+	granted, err = service.HasPermission(ctx, crypto.RoleAdmin, crypto.PermissionStudentWrite)
+	require.NoError(t, err)
+	assert.True(t, granted, "admins have all permissions")
+}
+
+func TestService_Update(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("applies to permission checks at once", func(t *testing.T) {
+		service := NewService(NewGormRepository(setupTestDB(t)))
+		granted, err := service.HasPermission(ctx, crypto.RoleStudent, crypto.PermissionTeacherRead)
+		require.NoError(t, err)
+		require.False(t, granted)
+
+		role, err := service.Update(ctx, "student", &UpdateRoleRequest{
+			Permissions: []string{"teacher:read", " report:upload", "teacher:read"},
+		}, "admin-1")
+
+		require.NoError(t, err)
+		assert.Equal(t, []crypto.Permission{crypto.PermissionReportUpload, crypto.PermissionTeacherRead}, role.Permissions)
+		assert.Equal(t, "Uploads reports", role.Description)
+
+		granted, err = service.HasPermission(ctx, crypto.RoleStudent, crypto.PermissionTeacherRead)
+		require.NoError(t, err)
+		assert.True(t, granted)
+	})
+
+	t.Run("rejects unknown permissions", func(t *testing.T) {
+		service := NewService(NewGormRepository(setupTestDB(t)))
+
+		_, err := service.Update(ctx, "student", &UpdateRoleRequest{Permissions: []string{"report:delete"}}, "admin-1")
+
+		assertAppErrorCode(t, err, 422)
+	})
+
+	t.Run("requires the permissions", func(t *testing.T) {
+		service := NewService(NewGormRepository(setupTestDB(t)))
+
+		_, err := service.Update(ctx, "student", &UpdateRoleRequest{}, "admin-1")
+
+		assertAppErrorCode(t, err, 422)
+	})
+
+	t.Run("admin role can't be edited", func(t *testing.T) {
+		service := NewService(NewGormRepository(setupTestDB(t)))
+
+		_, err := service.Update(ctx, "admin", &UpdateRoleRequest{Permissions: []string{}}, "admin-1")
+
+		assertAppErrorCode(t, err, 409)
+	})
+
+	t.Run("unknown role", func(t *testing.T) {
+		service := NewService(NewGormRepository(setupTestDB(t)))
+
+		_, err := service.Update(ctx, "service", &UpdateRoleRequest{Permissions: []string{}}, "admin-1")
+
+		assertAppErrorCode(t, err, 404)
+	})
+}
+
+func TestService_List(t *testing.T) {
+	service := NewService(NewGormRepository(setupTestDB(t)))
+
+	roles, err := service.List(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, roles, 3)
+	assert.Equal(t, crypto.Permissions, roles[0].Permissions, "the admin role shows all permissions")
+}
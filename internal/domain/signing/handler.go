@@ -18,10 +18,16 @@ func NewHandler(service *Service) *Handler {
 }
 
 // RegisterRoutes registers signing routes
-func (h *Handler) RegisterRoutes(router fiber.Router) {
-	router.Post("/upload", h.Upload)
-	router.Get("/sign_requests", h.GetSignRequests)
-	router.Post("/sign_uploads", h.SignUploads)
+// The router must already require authentication, each route requires a report permission
+func (h *Handler) RegisterRoutes(router fiber.Router, rbacMW RBACMiddleware) {
+	router.Post("/upload", rbacMW.RequirePermission("report:upload"), h.Upload)
+	router.Get("/sign_requests", rbacMW.RequirePermission("report:read"), h.GetSignRequests)
+	router.Post("/sign_uploads", rbacMW.RequirePermission("report:sign"), h.SignUploads)
+}
+
+// RBACMiddleware interface defines the permission middleware signing routes need
+type RBACMiddleware interface {
+	RequirePermission(permission string) fiber.Handler
 }
 
 // Upload handles parquet file uploads
@@ -34,6 +40,7 @@ func (h *Handler) RegisterRoutes(router fiber.Router) {
 // @Success 201 {object} response.SuccessResponse{data=UploadRecord}
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse "Forbidden - requires the report:upload permission"
 // @Failure 501 {object} response.ErrorResponse
 // @Security BearerAuth
 // @Router /api/v1/signing/upload [post]
@@ -70,6 +77,7 @@ func (h *Handler) Upload(c *fiber.Ctx) error {
 // @Produce application/octet-stream
 // @Success 200 {file} binary "Parquet file with pending sign requests"
 // @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse "Forbidden - requires the report:read permission"
 // @Failure 501 {object} response.ErrorResponse
 // @Security BearerAuth
 // @Router /api/v1/signing/sign_requests [get]
@@ -95,6 +103,7 @@ func (h *Handler) GetSignRequests(c *fiber.Ctx) error {
 // @Success 200 {object} response.SuccessResponse
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse "Forbidden - requires the report:sign permission"
 // @Failure 501 {object} response.ErrorResponse
 // @Security BearerAuth
 // @Router /api/v1/signing/sign_uploads [post]
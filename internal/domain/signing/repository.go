@@ -0,0 +1,11 @@
+package signing
+
+import "context"
+
+// Repository defines the interface for report data access
+type Repository interface {
+	// CountPendingByTeacher counts the reports waiting for the teacher's signature
+	CountPendingByTeacher(ctx context.Context, teacherUUID string) (int64, error)
+	// CountPendingByStudent counts the reports of the student that aren't signed or rejected yet
+	CountPendingByStudent(ctx context.Context, studentUUID string) (int64, error)
+}
@@ -0,0 +1,42 @@
+package signing
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// ReportStatusPending is the status of a report waiting for its signature
+const ReportStatusPending = "pending"
+
+// GormRepository implements Repository interface using GORM
+type GormRepository struct {
+	db *gorm.DB
+}
+
+// NewGormRepository creates a new GORM-based report repository
+func NewGormRepository(db *gorm.DB) Repository {
+	return &GormRepository{db: db}
+}
+
+// CountPendingByTeacher counts the pending reports assigned to the teacher
+func (r *GormRepository) CountPendingByTeacher(ctx context.Context, teacherUUID string) (int64, error) {
+	return r.countPending(ctx, "teacher_uuid", teacherUUID)
+}
+
+// CountPendingByStudent counts the pending reports uploaded by the student
+func (r *GormRepository) CountPendingByStudent(ctx context.Context, studentUUID string) (int64, error) {
+	return r.countPending(ctx, "student_uuid", studentUUID)
+}
+
+// countPending counts the pending reports whose column references the record
+func (r *GormRepository) countPending(ctx context.Context, column, uuid string) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Table("reports").
+		Where(column+" = ? AND status = ?", uuid, ReportStatusPending).
+		Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count pending reports: %w", err)
+	}
+	return count, nil
+}
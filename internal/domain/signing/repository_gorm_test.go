@@ -0,0 +1,88 @@
+package signing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/access"
+	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
+)
+
+const (
+	teacherUUID      = "550e8400-e29b-41d4-a716-446655440010"
+	otherTeacherUUID = "550e8400-e29b-41d4-a716-446655440011"
+	studentUUID      = "550e8400-e29b-41d4-a716-446655440000"
+	otherStudentUUID = "550e8400-e29b-41d4-a716-446655440001"
+)
+
+// setupTestDB creates the reports table in an in-memory SQLite database and seeds reports in every status
+func setupTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err, "failed to create test database")
+
+	require.NoError(t, db.Exec(`CREATE TABLE reports (id INTEGER PRIMARY KEY AUTOINCREMENT, student_uuid TEXT NOT NULL,
+		teacher_uuid TEXT NOT NULL, week_number INTEGER NOT NULL, year INTEGER NOT NULL, status TEXT NOT NULL)`).Error)
+
+	reports := []struct {
+		student, teacher, status string
+		week                     int
+	}{
+		{studentUUID, teacherUUID, "pending", 40},
+		{studentUUID, teacherUUID, "pending", 41},
+		{studentUUID, teacherUUID, "signed", 39},
+		{otherStudentUUID, teacherUUID, "pending", 40},
+		{otherStudentUUID, teacherUUID, "rejected", 39},
+		{otherStudentUUID, otherTeacherUUID, "pending", 41},
+	}
+	for _, r := range reports {
+		require.NoError(t, db.Exec(`INSERT INTO reports (student_uuid, teacher_uuid, week_number, year, status) VALUES (?, ?, ?, 2025, ?)`,
+			r.student, r.teacher, r.week, r.status).Error)
+	}
+	return db
+}
+
+func TestGormRepository_CountPending(t *testing.T) {
+	ctx := context.Background()
+	repo := NewGormRepository(setupTestDB(t))
+
+	count, err := repo.CountPendingByTeacher(ctx, teacherUUID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+
+	count, err = repo.CountPendingByStudent(ctx, otherStudentUUID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	count, err = repo.CountPendingByTeacher(ctx, "550e8400-e29b-41d4-a716-446655440099")
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestService_CountPending(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(NewGormRepository(setupTestDB(t)))
+
+	tests := []struct {
+		name   string
+		viewer access.Viewer
+		want   int64
+	}{
+		{name: "teacher counts reports to sign", viewer: access.Viewer{UserID: "user-1", Role: crypto.RoleTeacher, UserUUID: otherTeacherUUID}, want: 1},
+		{name: "student counts unsigned reports", viewer: access.Viewer{UserID: "user-2", Role: crypto.RoleStudent, UserUUID: studentUUID}, want: 2},
+		{name: "admin has none", viewer: access.Viewer{UserID: "user-3", Role: crypto.RoleAdmin, AdminScope: crypto.AdminScopeFull}, want: 0},
+		{name: "account without record has none", viewer: access.Viewer{UserID: "user-4", Role: crypto.RoleTeacher}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count, err := service.CountPending(ctx, tt.viewer)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, count)
+		})
+	}
+}
@@ -3,7 +3,9 @@ package signing
 import (
 	"context"
 
+	"github.com/JustDoItBetter/FITS-backend/internal/common/access"
 	"github.com/JustDoItBetter/FITS-backend/internal/common/errors"
+	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
 )
 
 // WARNING: EXPERIMENTAL - Signing domain is not yet implemented
@@ -16,14 +18,14 @@ import (
 
 // Service handles business logic for signing operations
 type Service struct {
+	repo Repository
 	// TODO: Add parquet file handling when ready
 	// TODO: Implement RSA signature verification
-	// TODO: Add database persistence for signatures
 }
 
 // NewService creates a new signing service
-func NewService() *Service {
-	return &Service{}
+func NewService(repo Repository) *Service {
+	return &Service{repo: repo}
 }
 
 // HandleUpload processes a parquet file upload
@@ -43,3 +45,21 @@ func (s *Service) HandleSignedUploads(ctx context.Context, filename string, data
 	// TODO: Implement signed request processing
 	return errors.NewAppError(501, "Not Implemented", "signed uploads handling not yet implemented")
 }
+
+// CountPending returns the number of sign requests waiting for the viewer
+// Teachers count the requests of their students to sign, students their requests that aren't signed yet
+// Admins and viewers without a linked record have no sign requests of their own
+func (s *Service) CountPending(ctx context.Context, viewer access.Viewer) (int64, error) {
+	if viewer.UserUUID == "" {
+		return 0, nil
+	}
+
+	switch viewer.Role {
+	case crypto.RoleTeacher:
+		return s.repo.CountPendingByTeacher(ctx, viewer.UserUUID)
+	case crypto.RoleStudent:
+		return s.repo.CountPendingByStudent(ctx, viewer.UserUUID)
+	default:
+		return 0, nil
+	}
+}
@@ -0,0 +1,235 @@
+package student
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"iter"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/access"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/bulk"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/pagination"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/validation"
+	"github.com/JustDoItBetter/FITS-backend/pkg/logger"
+)
+
+// ExportColumns are the columns of student exports
+var ExportColumns = []string{"uuid", "first_name", "last_name", "email", "teacher_id", "teacher_removed_at", "created_at", "updated_at"}
+
+// ImportFields are the columns read by student imports, other columns (e.g. of an export) are ignored
+var ImportFields = []string{"first_name", "last_name", "email", "teacher_id"}
+
+// requiredImportFields must be columns of CSV and XLSX imports
+var requiredImportFields = []string{"first_name", "last_name", "email"}
+
+// ReadImport reads the rows of a student import file, renaming columns with mapping
+func ReadImport(filename string, data []byte, mapping map[string]string) ([]bulk.Row, error) {
+	return bulk.Read(filename, data, bulk.Options{Fields: ImportFields, Required: requiredImportFields, Mapping: mapping})
+}
+
+// Import validates every row and upserts the students by email
+// Rows with a new email create a student, the others update the student, empty cells keep its values.
+// Nothing is written if any row is invalid or dryRun is set; otherwise all rows are written in one transaction.
+// Department admins may only import students of their department's teachers
+func (s *Service) Import(ctx context.Context, rows []bulk.Row, dryRun bool, viewer access.Viewer) (*bulk.Report, error) {
+	report := bulk.NewReport(rows, dryRun)
+
+	// Resolve existing students with one query instead of one per row
+	emails := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if email := validation.SanitizeEmail(row.Get("email")); email != "" {
+			emails = append(emails, email)
+		}
+	}
+	existing, err := s.repo.GetByEmails(ctx, emails)
+	if err != nil {
+		return nil, err
+	}
+
+	students := make([]*Student, len(rows))
+	seen := make(map[string]int)
+	teachers := make(map[string]error)
+	departments := make(map[string]error)
+	inDepartment := func(teacherID *string) error {
+		if teacherID == nil {
+			return checkDepartment(ctx, s.repo, teacherID, viewer)
+		}
+		err, checked := departments[*teacherID]
+		if !checked {
+			err = checkDepartment(ctx, s.repo, teacherID, viewer)
+			departments[*teacherID] = err
+		}
+		return err
+	}
+	for i, row := range rows {
+		report.Rows[i].Email = row.Get("email")
+
+		student, action, rowErrors := s.importRow(ctx, row, existing, teachers, inDepartment)
+		if email := validation.SanitizeEmail(row.Get("email")); email != "" {
+			report.Rows[i].Email = email
+			if line, ok := seen[email]; ok {
+				rowErrors = append(rowErrors, fmt.Sprintf("email is already listed in row %d", line))
+			} else {
+				seen[email] = row.Line
+			}
+		}
+
+		if len(rowErrors) > 0 {
+			report.Fail(i, rowErrors)
+			continue
+		}
+		students[i] = student
+		report.Accept(i, action, student.UUID)
+	}
+
+	if dryRun || report.Failed > 0 {
+		return report, nil
+	}
+
+	write := func(repo Repository) error {
+		for i, student := range students {
+			var err error
+			switch report.Rows[i].Action {
+			case bulk.ActionCreate:
+				err = repo.Create(ctx, student)
+			case bulk.ActionUpdate:
+				err = repo.Update(ctx, student)
+			}
+			if err != nil {
+				return bulk.RowError(rows[i].Line, err)
+			}
+		}
+		return nil
+	}
+	if s.txMgr != nil {
+		err = s.txMgr.WithTransaction(ctx, func(tx *gorm.DB) error {
+			return write(s.repo.WithDB(tx))
+		})
+	} else {
+		err = write(s.repo)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	report.Imported()
+	logger.Info("Students imported",
+		zap.Int("created", report.Created),
+		zap.Int("updated", report.Updated),
+		zap.Int("unchanged", report.Unchanged),
+	)
+	return report, nil
+}
+
+// importRow validates a row with the rules of the create or update request
+// Returns the student to create or the updated student, the action and all problems found in the row.
+// teachers caches the checked teacher assignments over the rows, inDepartment checks the department of the
+// student's teacher before and after the row
+func (s *Service) importRow(ctx context.Context, row bulk.Row, existing map[string]*Student, teachers map[string]error, inDepartment func(teacherID *string) error) (*Student, string, []string) {
+	var teacherID *string
+	if id := row.Get("teacher_id"); id != "" {
+		teacherID = &id
+	}
+	checkTeacher := func(current *Student) error {
+		if teacherID == nil || (current != nil && current.TeacherID != nil && *current.TeacherID == *teacherID) {
+			return nil
+		}
+		err, checked := teachers[*teacherID]
+		if !checked {
+			err = validateTeacherAssignment(ctx, s.repo, nil, teacherID)
+			teachers[*teacherID] = err
+		}
+		return err
+	}
+
+	current := existing[validation.SanitizeEmail(row.Get("email"))]
+	if current == nil {
+		req := &CreateStudentRequest{
+			FirstName: row.Get("first_name"),
+			LastName:  row.Get("last_name"),
+			Email:     row.Get("email"),
+			TeacherID: row.Get("teacher_id"),
+		}
+		if err := s.validate.Struct(req); err != nil {
+			return nil, "", bulk.FieldErrors(err)
+		}
+		if err := checkTeacher(nil); err != nil {
+			return nil, "", bulk.FieldErrors(err)
+		}
+		student := req.ToStudent()
+		if err := inDepartment(student.TeacherID); err != nil {
+			return nil, "", bulk.FieldErrors(err)
+		}
+		return student, bulk.ActionCreate, nil
+	}
+
+	if err := inDepartment(current.TeacherID); err != nil {
+		return nil, "", bulk.FieldErrors(err)
+	}
+
+	req := &UpdateStudentRequest{
+		FirstName: row.Get("first_name"),
+		LastName:  row.Get("last_name"),
+		TeacherID: teacherID,
+	}
+	if err := s.validate.Struct(req); err != nil {
+		return nil, "", bulk.FieldErrors(err)
+	}
+	if err := checkTeacher(current); err != nil {
+		return nil, "", bulk.FieldErrors(err)
+	}
+
+	student := *current
+	student.Update(req)
+	if err := inDepartment(student.TeacherID); err != nil {
+		return nil, "", bulk.FieldErrors(err)
+	}
+	if student.FirstName == current.FirstName && student.LastName == current.LastName && sameTeacher(student.TeacherID, current.TeacherID) {
+		return current, bulk.ActionNone, nil
+	}
+	return &student, bulk.ActionUpdate, nil
+}
+
+func sameTeacher(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// Export returns the students the viewer may see matching the filter, for an export file
+// The filter is checked right away, the students are read in batches while iterating
+func (s *Service) Export(ctx context.Context, filter ListFilter, viewer access.Viewer) (iter.Seq2[*Student, error], error) {
+	scope, filter, err := listQuery(filter, viewer)
+	if err != nil {
+		return nil, err
+	}
+
+	return bulk.Records(ctx, filter.Sort, func(ctx context.Context, params pagination.Params) ([]*Student, pagination.PageInfo, error) {
+		return s.repo.ListPaginated(ctx, params, scope, filter)
+	}), nil
+}
+
+// WriteExport writes exported students to w as file of the format
+func WriteExport(w io.Writer, format bulk.Format, students iter.Seq2[*Student, error]) error {
+	writer, err := bulk.NewWriter(format, w, ExportColumns)
+	if err != nil {
+		return err
+	}
+
+	for student, err := range students {
+		if err != nil {
+			writer.Close()
+			return err
+		}
+		if err := writer.Write(student.UUID, student.FirstName, student.LastName, student.Email,
+			student.TeacherID, student.TeacherRemovedAt, student.CreatedAt, student.UpdatedAt); err != nil {
+			writer.Close()
+			return err
+		}
+	}
+	return writer.Close()
+}
@@ -0,0 +1,195 @@
+package student
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/access"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/bulk"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/reference"
+	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
+)
+
+// TestImport tests validation, upsert by email and dry runs of student imports
+func TestImport(t *testing.T) {
+	ctx := context.Background()
+	teacherUUID := "550e8400-e29b-41d4-a716-446655440900"
+	otherTeacher := "550e8400-e29b-41d4-a716-446655440901"
+
+	setup := func(t *testing.T) (*Service, *InMemoryRepository) {
+		repo := NewInMemoryRepository()
+		repo.AddTeacher(&reference.Teacher{UUID: teacherUUID, Department: "IT"})
+		repo.AddTeacher(&reference.Teacher{UUID: otherTeacher, Department: "IT"})
+		require.NoError(t, repo.Create(ctx, &Student{
+			UUID: "550e8400-e29b-41d4-a716-446655440910", FirstName: "Max", LastName: "Mustermann", Email: "max@example.com", TeacherID: &teacherUUID,
+		}))
+		require.NoError(t, repo.Create(ctx, &Student{
+			UUID: "550e8400-e29b-41d4-a716-446655440911", FirstName: "Erika", LastName: "Mustermann", Email: "erika@example.com",
+		}))
+		return NewService(repo), repo
+	}
+	read := func(t *testing.T, data string) []bulk.Row {
+		rows, err := ReadImport("students.csv", []byte(data), map[string]string{"nachname": "last_name"})
+		require.NoError(t, err)
+		return rows
+	}
+	roster := "first_name,Nachname,email,teacher_id\n" +
+		"Anna,Schmidt,Anna@Example.com," + teacherUUID + "\n" + // New
+		"Max,Mustermann,max@example.com,\n" + // Unchanged, empty teacher keeps the current
+		",Musterfrau,erika@example.com," + otherTeacher + "\n" // Updated, empty first name keeps the current
+
+	t.Run("dry run", func(t *testing.T) {
+		service, repo := setup(t)
+
+		report, err := service.Import(ctx, read(t, roster), true, fullAdmin)
+		require.NoError(t, err)
+		assert.Equal(t, 3, report.Valid)
+		assert.Equal(t, 0, report.Failed)
+		assert.Equal(t, []string{bulk.ActionCreate, bulk.ActionNone, bulk.ActionUpdate},
+			[]string{report.Rows[0].Action, report.Rows[1].Action, report.Rows[2].Action})
+		assert.Equal(t, "anna@example.com", report.Rows[0].Email)
+		assert.Equal(t, "550e8400-e29b-41d4-a716-446655440911", report.Rows[2].UUID)
+
+		students, _ := repo.List(ctx)
+		assert.Len(t, students, 2, "a dry run writes nothing")
+	})
+
+	t.Run("upserts by email", func(t *testing.T) {
+		service, repo := setup(t)
+
+		report, err := service.Import(ctx, read(t, roster), false, fullAdmin)
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Created)
+		assert.Equal(t, 1, report.Updated)
+		assert.Equal(t, 1, report.Unchanged)
+		assert.Equal(t, bulk.RowStatusCreated, report.Rows[0].Status)
+
+		existing, err := repo.GetByEmails(ctx, []string{"anna@example.com", "erika@example.com"})
+		require.NoError(t, err)
+		require.Len(t, existing, 2)
+		assert.Equal(t, report.Rows[0].UUID, existing["anna@example.com"].UUID)
+		erika := existing["erika@example.com"]
+		assert.Equal(t, "Erika", erika.FirstName)
+		assert.Equal(t, "Musterfrau", erika.LastName)
+		assert.Equal(t, otherTeacher, *erika.TeacherID)
+	})
+
+	t.Run("reports invalid rows and writes nothing", func(t *testing.T) {
+		service, repo := setup(t)
+		data := "first_name,last_name,email,teacher_id\n" +
+			"Anna,Schmidt,anna@example.com,\n" +
+			",Becker,tom@example.com,\n" +
+			"Tom,Becker,not-an-email,not-a-uuid\n" +
+			"Lena,Wolf,lena@example.com,550e8400-e29b-41d4-a716-446655440999\n" +
+			"Anna,Schmidt,ANNA@example.com,\n"
+
+		report, err := service.Import(ctx, read(t, data), false, fullAdmin)
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Valid)
+		assert.Equal(t, 4, report.Failed)
+		assert.Equal(t, bulk.RowStatusValid, report.Rows[0].Status)
+		assert.Equal(t, []string{"first_name is required"}, report.Rows[1].Errors)
+		assert.Equal(t, []string{"email must be a valid email", "teacher_id must be a UUID"}, report.Rows[2].Errors)
+		assert.Len(t, report.Rows[3].Errors, 1)
+		assert.Equal(t, []string{"email is already listed in row 2"}, report.Rows[4].Errors)
+
+		students, _ := repo.List(ctx)
+		assert.Len(t, students, 2)
+	})
+}
+
+// TestImport_DepartmentAdmin tests that department admins only import students of their department's teachers
+func TestImport_DepartmentAdmin(t *testing.T) {
+	ctx := context.Background()
+	itTeacher := "550e8400-e29b-41d4-a716-446655440901"
+	hrTeacher := "550e8400-e29b-41d4-a716-446655440902"
+	repo := NewInMemoryRepository()
+	repo.AddTeacher(&reference.Teacher{UUID: itTeacher, Department: "IT"})
+	repo.AddTeacher(&reference.Teacher{UUID: hrTeacher, Department: "HR"})
+	require.NoError(t, repo.Create(ctx, &Student{
+		UUID: "550e8400-e29b-41d4-a716-446655440912", FirstName: "Erika", LastName: "Mustermann", Email: "erika@example.com", TeacherID: &hrTeacher,
+	}))
+	service := NewService(repo)
+	viewer := access.Viewer{UserID: "admin-2", Role: crypto.RoleAdmin, AdminScope: crypto.AdminScopeDepartment, Department: "IT"}
+
+	rows, err := ReadImport("students.csv", []byte("first_name,last_name,email,teacher_id\n"+
+		"Anna,Schmidt,anna@example.com,"+itTeacher+"\n"+ // New in the department
+		"Max,Mustermann,max@example.com,"+hrTeacher+"\n"+ // New in another department
+		"Erika,Musterfrau,erika@example.com,"+itTeacher+"\n"), nil) // Moved from another department
+	require.NoError(t, err)
+
+	report, err := service.Import(ctx, rows, false, viewer)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Valid)
+	assert.Equal(t, 2, report.Failed)
+	assert.Empty(t, report.Rows[0].Errors)
+	assert.Equal(t, []string{"department admins can only access records of their department"}, report.Rows[1].Errors)
+	assert.Equal(t, []string{"department admins can only access records of their department"}, report.Rows[2].Errors)
+
+	students, _ := repo.List(ctx)
+	assert.Len(t, students, 1, "nothing is written if a row is rejected")
+}
+
+// TestExport tests that exports are scoped and filtered like lists and can be imported again
+func TestExport(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryRepository()
+	teacherUUID := "550e8400-e29b-41d4-a716-446655440920"
+	created := time.Date(2025, 9, 30, 12, 0, 0, 0, time.UTC)
+	for i, name := range []string{"Max", "Erika", "Anna"} {
+		student := &Student{
+			UUID:      "550e8400-e29b-41d4-a716-44665544093" + string(rune('0'+i)),
+			FirstName: name, LastName: "Mustermann", Email: strings.ToLower(name) + "@example.com",
+			CreatedAt: created.Add(time.Duration(i) * time.Hour), UpdatedAt: created,
+		}
+		if name != "Anna" {
+			student.TeacherID = &teacherUUID
+		}
+		require.NoError(t, repo.Create(ctx, student))
+	}
+	service := NewService(repo)
+
+	t.Run("csv", func(t *testing.T) {
+		students, err := service.Export(ctx, ListFilter{Search: "mustermann"}, access.Viewer{UserID: "admin-1", Role: crypto.RoleAdmin})
+		require.NoError(t, err)
+
+		var buf strings.Builder
+		require.NoError(t, WriteExport(&buf, bulk.FormatCSV, students))
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		require.Len(t, lines, 4)
+		assert.Equal(t, strings.Join(ExportColumns, ","), lines[0])
+		assert.Equal(t, "550e8400-e29b-41d4-a716-446655440932,Anna,Mustermann,anna@example.com,,,2025-09-30T14:00:00Z,2025-09-30T12:00:00Z", lines[1])
+
+		rows, err := ReadImport("students.csv", []byte(buf.String()), nil)
+		require.NoError(t, err)
+		report, err := service.Import(ctx, rows, true, fullAdmin)
+		require.NoError(t, err)
+		assert.Equal(t, 3, report.Valid)
+		for _, row := range report.Rows {
+			assert.Equal(t, bulk.ActionNone, row.Action, "an export imports unchanged")
+		}
+	})
+
+	t.Run("scoped to the viewer", func(t *testing.T) {
+		students, err := service.Export(ctx, ListFilter{}, access.Viewer{UserID: "user-1", Role: crypto.RoleTeacher, UserUUID: teacherUUID})
+		require.NoError(t, err)
+
+		var buf strings.Builder
+		require.NoError(t, WriteExport(&buf, bulk.FormatJSONL, students))
+		assert.Equal(t, 2, strings.Count(buf.String(), "\n"))
+		assert.NotContains(t, buf.String(), "anna@example.com")
+	})
+
+	t.Run("rejects invalid filters", func(t *testing.T) {
+		_, err := service.Export(ctx, ListFilter{TeacherID: "not-a-uuid"}, access.Viewer{UserID: "admin-1", Role: crypto.RoleAdmin})
+		assert.Error(t, err)
+
+		_, err = service.Export(ctx, ListFilter{}, access.Viewer{})
+		assert.Error(t, err)
+	})
+}
@@ -3,6 +3,8 @@ package student
 import (
 	"time"
 
+	"github.com/JustDoItBetter/FITS-backend/internal/common/pagination"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/patch"
 	"github.com/JustDoItBetter/FITS-backend/internal/common/validation"
 	"github.com/google/uuid"
 )
@@ -10,13 +12,29 @@ import (
 // Student represents a student entity
 // @Description Student information
 type Student struct {
-	UUID      string    `json:"uuid" example:"550e8400-e29b-41d4-a716-446655440000" validate:"required,uuid"`
-	FirstName string    `json:"first_name" example:"Max" validate:"required,min=1,max=100"`
-	LastName  string    `json:"last_name" example:"Mustermann" validate:"required,min=1,max=100"`
-	Email     string    `json:"email" example:"max@example.com" validate:"required,email"`
-	TeacherID *string   `json:"teacher_id,omitempty" example:"teacher-uuid-123"` // Optional - can be NULL
-	CreatedAt time.Time `json:"created_at" example:"2025-09-30T12:00:00Z"`
-	UpdatedAt time.Time `json:"updated_at" example:"2025-09-30T12:00:00Z"`
+	UUID             string     `json:"uuid" example:"550e8400-e29b-41d4-a716-446655440000" validate:"required,uuid"`
+	FirstName        string     `json:"first_name" example:"Max" validate:"required,min=1,max=100"`
+	LastName         string     `json:"last_name" example:"Mustermann" validate:"required,min=1,max=100"`
+	Email            string     `json:"email" example:"max@example.com" validate:"required,email"`
+	TeacherID        *string    `json:"teacher_id,omitempty" example:"teacher-uuid-123" validate:"omitempty,uuid"` // Optional - can be NULL
+	TeacherRemovedAt *time.Time `json:"teacher_removed_at,omitempty" example:"2025-09-30T12:00:00Z"`               // Set when the teacher was deleted
+	CreatedAt        time.Time  `json:"created_at" example:"2025-09-30T12:00:00Z"`
+	UpdatedAt        time.Time  `json:"updated_at" example:"2025-09-30T12:00:00Z"`
+	Version          int        `json:"version" example:"3"`                                 // Incremented by every change, sent as ETag
+	DeletedAt        *time.Time `json:"deleted_at,omitempty" example:"2025-10-01T12:00:00Z"` // Only set for students in the trash
+}
+
+// SortFields are the fields student lists can be sorted by
+var SortFields = []string{"first_name", "last_name", "email", "created_at", "updated_at"}
+
+// ListFilter narrows student lists, set from the query parameters of the list endpoint
+type ListFilter struct {
+	Search      string                 // Case-insensitive part of the full name or email
+	TeacherID   string                 // Students assigned to this teacher
+	Department  string                 // Students whose teacher is in this department, case-insensitive
+	CreatedFrom *time.Time             // Created at or after
+	CreatedTo   *time.Time             // Created at or before
+	Sort        []pagination.SortField // Empty for pagination.DefaultSort
 }
 
 // CreateStudentRequest represents the request to create a new student
@@ -37,6 +55,15 @@ type UpdateStudentRequest struct {
 	TeacherID *string `json:"teacher_id,omitempty" example:"teacher-uuid-456" validate:"omitempty,uuid"`
 }
 
+// PatchStudentRequest is a JSON merge patch (RFC 7396) of a student
+// @Description Request body for patching a student. Left out fields keep their value, null unassigns the teacher
+type PatchStudentRequest struct {
+	FirstName patch.Field[string] `json:"first_name" swaggertype:"string" example:"Moritz"`
+	LastName  patch.Field[string] `json:"last_name" swaggertype:"string" example:"Schmidt"`
+	Email     patch.Field[string] `json:"email" swaggertype:"string" example:"moritz@example.com"`
+	TeacherID patch.Field[string] `json:"teacher_id" swaggertype:"string" example:"teacher-uuid-456" extensions:"x-nullable"` // null unassigns the teacher
+}
+
 // ToStudent converts CreateStudentRequest to Student entity
 // Sanitizes input to prevent XSS and injection attacks
 // UUID is always generated server-side for security
@@ -74,6 +101,52 @@ func (s *Student) Update(req *UpdateStudentRequest) {
 	}
 	if req.TeacherID != nil {
 		s.TeacherID = req.TeacherID
+		if *req.TeacherID != "" {
+			s.TeacherRemovedAt = nil
+		}
 	}
 	s.UpdatedAt = time.Now()
 }
+
+// Patch applies a merge patch to the student and returns the columns that changed
+// Sanitizes input like Update. Names and email can't be cleared, null only unassigns the teacher
+func (s *Student) Patch(req *PatchStudentRequest) ([]string, error) {
+	var columns []string
+	fields := []struct {
+		column  string
+		value   patch.Field[string]
+		current *string
+		clean   func(string) string
+	}{
+		{"first_name", req.FirstName, &s.FirstName, validation.SanitizeName},
+		{"last_name", req.LastName, &s.LastName, validation.SanitizeName},
+		{"email", req.Email, &s.Email, validation.SanitizeEmail},
+	}
+	for _, field := range fields {
+		changed, err := patch.Set(field.column, field.value, field.current, field.clean)
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			columns = append(columns, field.column)
+		}
+	}
+
+	if req.TeacherID.IsNull() && s.TeacherID != nil {
+		s.TeacherID = nil
+		columns = append(columns, "teacher_id")
+	}
+	if teacherID, ok := req.TeacherID.Get(); ok && (s.TeacherID == nil || *s.TeacherID != teacherID) {
+		s.TeacherID = &teacherID
+		columns = append(columns, "teacher_id")
+		if s.TeacherRemovedAt != nil {
+			s.TeacherRemovedAt = nil
+			columns = append(columns, "teacher_removed_at")
+		}
+	}
+
+	if len(columns) > 0 {
+		s.UpdatedAt = time.Now()
+	}
+	return columns, nil
+}
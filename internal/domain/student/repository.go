@@ -2,10 +2,15 @@ package student
 
 import (
 	"context"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/JustDoItBetter/FITS-backend/internal/common/errors"
 	"github.com/JustDoItBetter/FITS-backend/internal/common/pagination"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/reference"
 	"gorm.io/gorm"
 )
 
@@ -13,21 +18,60 @@ import (
 type Repository interface {
 	Create(ctx context.Context, student *Student) error
 	GetByUUID(ctx context.Context, uuid string) (*Student, error)
+	// Update saves a changed student if nobody changed it since it was loaded and increments its version,
+	// a precondition failed error if the stored version differs from student.Version
 	Update(ctx context.Context, student *Student) error
-	Delete(ctx context.Context, uuid string) error
-	// ListPaginated returns paginated students with total count for metadata
-	ListPaginated(ctx context.Context, params pagination.Params) ([]*Student, int64, error)
+	// Patch saves only the given columns of a changed student, with the version check of Update
+	Patch(ctx context.Context, student *Student, columns []string) error
+	// Delete soft-deletes a student if it still has the given version, a precondition failed error otherwise
+	Delete(ctx context.Context, uuid string, version int) error
+	// ListDeleted returns a page of soft-deleted students, most recently deleted first, with total count
+	ListDeleted(ctx context.Context, params pagination.Params) ([]*Student, int64, error)
+	// Restore undeletes a soft-deleted student, a conflict if another student uses its email by now
+	Restore(ctx context.Context, uuid string) error
+	// PurgeDeleted permanently removes the students deleted before the given time and returns their number
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+	// ListPaginated returns a page of students within scope matching the filter with total count and cursors for metadata
+	ListPaginated(ctx context.Context, params pagination.Params, scope ListScope, filter ListFilter) ([]*Student, pagination.PageInfo, error)
 	// List retrieves all students (deprecated: use ListPaginated for better performance)
 	List(ctx context.Context) ([]*Student, error)
+	// GetByEmails resolves emails (lowercased) to students, used to match import rows
+	GetByEmails(ctx context.Context, emails []string) (map[string]*Student, error)
+	// GetActiveTeacher looks up the teacher a student is assigned to, nil if it doesn't exist or was deleted
+	GetActiveTeacher(ctx context.Context, uuid string) (*reference.Teacher, error)
 	// WithDB returns a new repository instance using the provided database connection
 	// This enables the repository to participate in transactions
 	WithDB(db *gorm.DB) Repository
 }
 
+// ListScope restricts a student list to the records a viewer may see
+// The zero value matches no student, so a missing scope fails closed
+type ListScope struct {
+	All        bool   // Every student, for admins
+	UUID       string // Only the student with this UUID
+	TeacherID  string // Only the students assigned to this teacher
+	Department string // Only the students whose teacher is in this department, for department admins
+}
+
+// matches reports whether the student is within the scope
+func (s ListScope) matches(student *Student) bool {
+	switch {
+	case s.All:
+		return true
+	case s.UUID != "":
+		return student.UUID == s.UUID
+	case s.TeacherID != "":
+		return student.TeacherID != nil && *student.TeacherID == s.TeacherID
+	}
+	return false
+}
+
 // InMemoryRepository is a simple in-memory implementation of Repository
 // This is temporary until we implement a real database
 type InMemoryRepository struct {
 	students map[string]*Student
+	deleted  map[string]*Student
+	teachers map[string]*reference.Teacher
 	mu       sync.RWMutex
 }
 
@@ -35,9 +79,19 @@ type InMemoryRepository struct {
 func NewInMemoryRepository() *InMemoryRepository {
 	return &InMemoryRepository{
 		students: make(map[string]*Student),
+		deleted:  make(map[string]*Student),
+		teachers: make(map[string]*reference.Teacher),
 	}
 }
 
+// AddTeacher makes a teacher available for assignment
+func (r *InMemoryRepository) AddTeacher(teacher *reference.Teacher) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.teachers[teacher.UUID] = teacher
+}
+
 // Create adds a new student to the repository
 func (r *InMemoryRepository) Create(ctx context.Context, student *Student) error {
 	r.mu.Lock()
@@ -47,6 +101,7 @@ func (r *InMemoryRepository) Create(ctx context.Context, student *Student) error
 		return errors.Conflict("student with this UUID already exists")
 	}
 
+	student.Version = 1
 	r.students[student.UUID] = student
 	return nil
 }
@@ -69,27 +124,105 @@ func (r *InMemoryRepository) Update(ctx context.Context, student *Student) error
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if _, exists := r.students[student.UUID]; !exists {
+	current, exists := r.students[student.UUID]
+	if !exists {
 		return errors.NotFound("student")
 	}
+	if current.Version != student.Version {
+		return errors.PreconditionFailed("student has been modified by another request")
+	}
 
+	student.Version++
 	r.students[student.UUID] = student
 	return nil
 }
 
-// Delete removes a student from the repository
-func (r *InMemoryRepository) Delete(ctx context.Context, uuid string) error {
+// Patch updates an existing student, the in-memory repository always stores the whole student
+func (r *InMemoryRepository) Patch(ctx context.Context, student *Student, columns []string) error {
+	return r.Update(ctx, student)
+}
+
+// Delete moves a student to the deleted students, like the soft delete of the GORM repository
+func (r *InMemoryRepository) Delete(ctx context.Context, uuid string, version int) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if _, exists := r.students[uuid]; !exists {
+	student, exists := r.students[uuid]
+	if !exists {
 		return errors.NotFound("student")
 	}
+	if student.Version != version {
+		return errors.PreconditionFailed("student has been modified by another request")
+	}
 
+	now := time.Now()
+	student.DeletedAt = &now
+	r.deleted[uuid] = student
 	delete(r.students, uuid)
 	return nil
 }
 
+// ListDeleted retrieves a page of deleted students, most recently deleted first
+func (r *InMemoryRepository) ListDeleted(ctx context.Context, params pagination.Params) ([]*Student, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	deleted := make([]*Student, 0, len(r.deleted))
+	for _, student := range r.deleted {
+		deleted = append(deleted, student)
+	}
+	sort.Slice(deleted, func(i, j int) bool {
+		if c := deleted[i].DeletedAt.Compare(*deleted[j].DeletedAt); c != 0 {
+			return c > 0
+		}
+		return deleted[i].UUID < deleted[j].UUID
+	})
+
+	totalCount := int64(len(deleted))
+	start := params.Offset()
+	if start >= len(deleted) {
+		return []*Student{}, totalCount, nil
+	}
+	return deleted[start:min(start+params.Limit, len(deleted))], totalCount, nil
+}
+
+// Restore moves a deleted student back to the students
+func (r *InMemoryRepository) Restore(ctx context.Context, uuid string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	student, exists := r.deleted[uuid]
+	if !exists {
+		return errors.NotFound("deleted student not found")
+	}
+	for _, other := range r.students {
+		if strings.EqualFold(other.Email, student.Email) {
+			return errors.Conflict("another student with this email exists")
+		}
+	}
+
+	student.DeletedAt = nil
+	student.Version++
+	r.students[uuid] = student
+	delete(r.deleted, uuid)
+	return nil
+}
+
+// PurgeDeleted removes the students deleted before the given time
+func (r *InMemoryRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var purged int64
+	for uuid, student := range r.deleted {
+		if student.DeletedAt.Before(before) {
+			delete(r.deleted, uuid)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
 // List retrieves all students (deprecated: use ListPaginated)
 func (r *InMemoryRepository) List(ctx context.Context) ([]*Student, error) {
 	r.mu.RLock()
@@ -103,33 +236,134 @@ func (r *InMemoryRepository) List(ctx context.Context) ([]*Student, error) {
 	return students, nil
 }
 
+// GetByEmails resolves emails (lowercased) to students
+func (r *InMemoryRepository) GetByEmails(ctx context.Context, emails []string) (map[string]*Student, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make(map[string]*Student)
+	for _, student := range r.students {
+		email := strings.ToLower(student.Email)
+		if slices.Contains(emails, email) {
+			result[email] = student
+		}
+	}
+	return result, nil
+}
+
 // ListPaginated retrieves students with pagination support
-// Returns slice of students, total count, and error
-func (r *InMemoryRepository) ListPaginated(ctx context.Context, params pagination.Params) ([]*Student, int64, error) {
+// Returns slice of students, total count and cursors, and error
+func (r *InMemoryRepository) ListPaginated(ctx context.Context, params pagination.Params, scope ListScope, filter ListFilter) ([]*Student, pagination.PageInfo, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	// Get all students first
+	// Get all students within scope matching the filter first
 	allStudents := make([]*Student, 0, len(r.students))
 	for _, student := range r.students {
-		allStudents = append(allStudents, student)
+		if r.inScope(scope, student) && r.matchesFilter(student, filter) {
+			allStudents = append(allStudents, student)
+		}
 	}
+	sort.Slice(allStudents, func(i, j int) bool {
+		a, b := allStudents[i], allStudents[j]
+		return pagination.Less(filter.Sort, func(field string) int {
+			return compareField(a, b, field)
+		}, strings.Compare(a.UUID, b.UUID))
+	})
 
 	totalCount := int64(len(allStudents))
 
+	if params.Keyset {
+		desc, _ := pagination.KeysetSort(filter.Sort)
+		page, info := pagination.KeysetSlice(allStudents, params, desc, cursorOf)
+		info.TotalCount = totalCount
+		return page, info, nil
+	}
+
 	// Apply pagination
+	info := pagination.PageInfo{TotalCount: totalCount}
 	start := params.Offset()
 	end := start + params.Limit
 
 	// Handle edge cases
 	if start >= len(allStudents) {
-		return []*Student{}, totalCount, nil
+		return []*Student{}, info, nil
 	}
 	if end > len(allStudents) {
 		end = len(allStudents)
 	}
 
-	return allStudents[start:end], totalCount, nil
+	return allStudents[start:end], info, nil
+}
+
+// inScope reports whether the student is within the scope
+// A Department scope needs the teachers added with AddTeacher, like the department filter
+func (r *InMemoryRepository) inScope(scope ListScope, student *Student) bool {
+	if scope.Department != "" {
+		return r.matchesFilter(student, ListFilter{Department: scope.Department})
+	}
+	return scope.matches(student)
+}
+
+// cursorOf returns the keyset pagination position of a student
+func cursorOf(student *Student) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: student.CreatedAt, ID: student.UUID}
+}
+
+// matchesFilter reports whether the student matches the filter, like the WHERE clause of the GORM repository
+// The department is the one of the teacher added with AddTeacher
+func (r *InMemoryRepository) matchesFilter(student *Student, filter ListFilter) bool {
+	if filter.Search != "" {
+		search := strings.ToLower(filter.Search)
+		fullName := strings.ToLower(student.FirstName + " " + student.LastName)
+		if !strings.Contains(fullName, search) && !strings.Contains(strings.ToLower(student.Email), search) {
+			return false
+		}
+	}
+	if filter.TeacherID != "" && (student.TeacherID == nil || *student.TeacherID != filter.TeacherID) {
+		return false
+	}
+	if filter.Department != "" {
+		if student.TeacherID == nil {
+			return false
+		}
+		teacher := r.teachers[*student.TeacherID]
+		if teacher == nil || !strings.EqualFold(teacher.Department, filter.Department) {
+			return false
+		}
+	}
+	if filter.CreatedFrom != nil && student.CreatedAt.Before(*filter.CreatedFrom) {
+		return false
+	}
+	if filter.CreatedTo != nil && student.CreatedAt.After(*filter.CreatedTo) {
+		return false
+	}
+	return true
+}
+
+// compareField compares two students by one of the SortFields
+func compareField(a, b *Student, field string) int {
+	switch field {
+	case "first_name":
+		return strings.Compare(a.FirstName, b.FirstName)
+	case "last_name":
+		return strings.Compare(a.LastName, b.LastName)
+	case "email":
+		return strings.Compare(a.Email, b.Email)
+	case "created_at":
+		return a.CreatedAt.Compare(b.CreatedAt)
+	case "updated_at":
+		return a.UpdatedAt.Compare(b.UpdatedAt)
+	}
+	return 0
+}
+
+// GetActiveTeacher retrieves a teacher added with AddTeacher
+func (r *InMemoryRepository) GetActiveTeacher(ctx context.Context, uuid string) (*reference.Teacher, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.teachers[uuid], nil
 }
 
 // WithDB returns the same repository instance (in-memory doesn't use database connections)
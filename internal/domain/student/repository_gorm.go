@@ -2,23 +2,28 @@ package student
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/JustDoItBetter/FITS-backend/internal/common/errors"
 	"github.com/JustDoItBetter/FITS-backend/internal/common/pagination"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/reference"
+	"github.com/JustDoItBetter/FITS-backend/pkg/database"
 	"gorm.io/gorm"
 )
 
 // StudentModel represents the GORM model for students table
 type StudentModel struct {
-	ID        string     `gorm:"column:id;type:uuid;primaryKey;default:uuid_generate_v4()"`
-	FirstName string     `gorm:"column:first_name;type:varchar(100);not null"`
-	LastName  string     `gorm:"column:last_name;type:varchar(100);not null"`
-	Email     string     `gorm:"column:email;type:varchar(255);uniqueIndex;not null"`
-	TeacherID *string    `gorm:"column:teacher_id;type:uuid"`
-	CreatedAt time.Time  `gorm:"column:created_at;autoCreateTime"`
-	UpdatedAt time.Time  `gorm:"column:updated_at;autoUpdateTime"`
-	DeletedAt *time.Time `gorm:"column:deleted_at;index"` // Soft delete support
+	ID               string         `gorm:"column:id;type:uuid;primaryKey;default:uuid_generate_v4()"`
+	FirstName        string         `gorm:"column:first_name;type:varchar(100);not null"`
+	LastName         string         `gorm:"column:last_name;type:varchar(100);not null"`
+	Email            string         `gorm:"column:email;type:varchar(255);not null"` // Unique among students that aren't deleted, see migration 021
+	TeacherID        *string        `gorm:"column:teacher_id;type:uuid"`
+	TeacherRemovedAt *time.Time     `gorm:"column:teacher_removed_at"`
+	CreatedAt        time.Time      `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt        time.Time      `gorm:"column:updated_at;autoUpdateTime"`
+	Version          int            `gorm:"column:version;not null;default:1"` // Optimistic locking, see Update
+	DeletedAt        gorm.DeletedAt `gorm:"column:deleted_at;index"`           // Soft delete support
 }
 
 // TableName specifies the table name for GORM
@@ -28,27 +33,37 @@ func (StudentModel) TableName() string {
 
 // ToStudent converts StudentModel to Student domain entity
 func (m *StudentModel) ToStudent() *Student {
+	var deletedAt *time.Time
+	if m.DeletedAt.Valid {
+		deletedAt = &m.DeletedAt.Time
+	}
+
 	return &Student{
-		UUID:      m.ID,
-		FirstName: m.FirstName,
-		LastName:  m.LastName,
-		Email:     m.Email,
-		TeacherID: m.TeacherID,
-		CreatedAt: m.CreatedAt,
-		UpdatedAt: m.UpdatedAt,
+		UUID:             m.ID,
+		FirstName:        m.FirstName,
+		LastName:         m.LastName,
+		Email:            m.Email,
+		TeacherID:        m.TeacherID,
+		TeacherRemovedAt: m.TeacherRemovedAt,
+		CreatedAt:        m.CreatedAt,
+		UpdatedAt:        m.UpdatedAt,
+		Version:          m.Version,
+		DeletedAt:        deletedAt,
 	}
 }
 
 // FromStudent converts Student domain entity to StudentModel
 func FromStudent(s *Student) *StudentModel {
 	return &StudentModel{
-		ID:        s.UUID,
-		FirstName: s.FirstName,
-		LastName:  s.LastName,
-		Email:     s.Email,
-		TeacherID: s.TeacherID,
-		CreatedAt: s.CreatedAt,
-		UpdatedAt: s.UpdatedAt,
+		ID:               s.UUID,
+		FirstName:        s.FirstName,
+		LastName:         s.LastName,
+		Email:            s.Email,
+		TeacherID:        s.TeacherID,
+		TeacherRemovedAt: s.TeacherRemovedAt,
+		CreatedAt:        s.CreatedAt,
+		UpdatedAt:        s.UpdatedAt,
+		Version:          s.Version,
 	}
 }
 
@@ -70,6 +85,7 @@ func (r *GormRepository) WithDB(db *gorm.DB) Repository {
 
 // Create adds a new student to the database
 func (r *GormRepository) Create(ctx context.Context, student *Student) error {
+	student.Version = 1 // Every record starts at version 1
 	model := FromStudent(student)
 
 	if err := r.db.WithContext(ctx).Create(model).Error; err != nil {
@@ -98,20 +114,46 @@ func (r *GormRepository) GetByUUID(ctx context.Context, uuid string) (*Student,
 	return model.ToStudent(), nil
 }
 
-// Update updates an existing student
+// Update updates an existing student if it still has the version it was loaded with
 func (r *GormRepository) Update(ctx context.Context, student *Student) error {
-	model := FromStudent(student)
+	return r.update(ctx, student, columnValues(FromStudent(student)))
+}
+
+// Patch writes only the given columns of a changed student, with the version check of Update
+func (r *GormRepository) Patch(ctx context.Context, student *Student, columns []string) error {
+	all := columnValues(FromStudent(student))
+	values := make(map[string]interface{}, len(columns))
+	for _, column := range columns {
+		value, ok := all[column]
+		if !ok {
+			return errors.Internal("student column " + column + " can't be patched")
+		}
+		values[column] = value
+	}
+	return r.update(ctx, student, values)
+}
+
+// columnValues returns the values of the columns that updates write
+func columnValues(model *StudentModel) map[string]interface{} {
+	return map[string]interface{}{
+		"first_name":         model.FirstName,
+		"last_name":          model.LastName,
+		"email":              model.Email,
+		"teacher_id":         model.TeacherID,
+		"teacher_removed_at": model.TeacherRemovedAt,
+	}
+}
+
+// update writes the values to the row of the student and increments its version
+// The version check and increment happen in the UPDATE itself, so concurrent writers can't overwrite each other
+func (r *GormRepository) update(ctx context.Context, student *Student, values map[string]interface{}) error {
+	values["updated_at"] = time.Now()
+	values["version"] = gorm.Expr("version + 1")
 
 	result := r.db.WithContext(ctx).
 		Model(&StudentModel{}).
-		Where("id = ?", student.UUID).
-		Updates(map[string]interface{}{
-			"first_name": model.FirstName,
-			"last_name":  model.LastName,
-			"email":      model.Email,
-			"teacher_id": model.TeacherID,
-			"updated_at": time.Now(),
-		})
+		Where("id = ? AND version = ?", student.UUID, student.Version).
+		Updates(values)
 
 	if result.Error != nil {
 		if errors.IsUniqueViolation(result.Error) {
@@ -121,16 +163,17 @@ func (r *GormRepository) Update(ctx context.Context, student *Student) error {
 	}
 
 	if result.RowsAffected == 0 {
-		return errors.NotFound("student not found")
+		return r.notUpdated(ctx, student.UUID)
 	}
 
+	student.Version++
 	return nil
 }
 
-// Delete removes a student from the database
-func (r *GormRepository) Delete(ctx context.Context, uuid string) error {
+// Delete soft-deletes a student if it still has the given version
+func (r *GormRepository) Delete(ctx context.Context, uuid string, version int) error {
 	result := r.db.WithContext(ctx).
-		Where("id = ?", uuid).
+		Where("id = ? AND version = ?", uuid, version).
 		Delete(&StudentModel{})
 
 	if result.Error != nil {
@@ -138,12 +181,93 @@ func (r *GormRepository) Delete(ctx context.Context, uuid string) error {
 	}
 
 	if result.RowsAffected == 0 {
-		return errors.NotFound("student not found")
+		return r.notUpdated(ctx, uuid)
 	}
 
 	return nil
 }
 
+// notUpdated explains why a versioned write matched no row:
+// the student was changed by another request, or it doesn't exist (anymore)
+func (r *GormRepository) notUpdated(ctx context.Context, uuid string) error {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&StudentModel{}).Where("id = ?", uuid).Count(&count).Error; err != nil {
+		return errors.Internal("failed to check student: " + err.Error())
+	}
+	if count > 0 {
+		return errors.PreconditionFailed("student has been modified by another request")
+	}
+	return errors.NotFound("student not found")
+}
+
+// ListDeleted retrieves a page of soft-deleted students, most recently deleted first
+func (r *GormRepository) ListDeleted(ctx context.Context, params pagination.Params) ([]*Student, int64, error) {
+	var models []StudentModel
+	var totalCount int64
+
+	if err := r.db.WithContext(ctx).Unscoped().Model(&StudentModel{}).Where("deleted_at IS NOT NULL").Count(&totalCount).Error; err != nil {
+		return nil, 0, errors.Internal("failed to count deleted students: " + err.Error())
+	}
+
+	if err := r.db.WithContext(ctx).Unscoped().
+		Where("deleted_at IS NOT NULL").
+		Offset(params.Offset()).Limit(params.Limit).
+		Order("deleted_at DESC, id").
+		Find(&models).Error; err != nil {
+		return nil, 0, errors.Internal("failed to list deleted students: " + err.Error())
+	}
+
+	students := make([]*Student, len(models))
+	for i, model := range models {
+		students[i] = model.ToStudent()
+	}
+
+	return students, totalCount, nil
+}
+
+// Restore undeletes a soft-deleted student
+// The email is only unique among students that aren't deleted, so it may be taken by now
+func (r *GormRepository) Restore(ctx context.Context, uuid string) error {
+	result := r.db.WithContext(ctx).
+		Unscoped().
+		Model(&StudentModel{}).
+		Where("id = ? AND deleted_at IS NOT NULL", uuid).
+		Updates(map[string]interface{}{
+			"deleted_at": nil,
+			"updated_at": time.Now(),
+			"version":    gorm.Expr("version + 1"),
+		})
+
+	if result.Error != nil {
+		if errors.IsUniqueViolation(result.Error) {
+			return errors.Conflict("another student with this email exists")
+		}
+		return errors.Internal("failed to restore student: " + result.Error.Error())
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.NotFound("deleted student not found")
+	}
+
+	return nil
+}
+
+// PurgeDeleted permanently removes the students deleted before the given time
+// Students with reports stay in the trash, as ON DELETE CASCADE would destroy the reports and their signatures
+func (r *GormRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Where("NOT EXISTS (SELECT 1 FROM reports WHERE reports.student_uuid = students.id)").
+		Delete(&StudentModel{})
+
+	if result.Error != nil {
+		return 0, errors.Internal("failed to purge deleted students: " + result.Error.Error())
+	}
+
+	return result.RowsAffected, nil
+}
+
 // List retrieves all students (deprecated: use ListPaginated for better performance)
 func (r *GormRepository) List(ctx context.Context) ([]*Student, error) {
 	var models []StudentModel
@@ -160,24 +284,51 @@ func (r *GormRepository) List(ctx context.Context) ([]*Student, error) {
 	return students, nil
 }
 
-// ListPaginated retrieves students with pagination using efficient OFFSET/LIMIT query
-// Performs two queries: COUNT for total, SELECT with LIMIT/OFFSET for data
-func (r *GormRepository) ListPaginated(ctx context.Context, params pagination.Params) ([]*Student, int64, error) {
+// GetByEmails resolves emails (lowercased) to students, ignoring deleted students
+func (r *GormRepository) GetByEmails(ctx context.Context, emails []string) (map[string]*Student, error) {
+	result := make(map[string]*Student)
+	if len(emails) == 0 {
+		return result, nil
+	}
+
 	var models []StudentModel
-	var totalCount int64
+	if err := r.db.WithContext(ctx).Where("LOWER(email) IN ?", emails).Find(&models).Error; err != nil {
+		return nil, errors.Internal("failed to look up students: " + err.Error())
+	}
+
+	for _, model := range models {
+		student := model.ToStudent()
+		result[strings.ToLower(student.Email)] = student
+	}
+	return result, nil
+}
+
+// ListPaginated retrieves students with pagination using efficient OFFSET/LIMIT or keyset queries
+// Performs two queries: COUNT for total, SELECT with LIMIT/OFFSET (or after the cursor) for data, both restricted to the scope and filter
+func (r *GormRepository) ListPaginated(ctx context.Context, params pagination.Params, scope ListScope, filter ListFilter) ([]*Student, pagination.PageInfo, error) {
+	var models []StudentModel
+	var info pagination.PageInfo
 
 	// Count total records first for pagination metadata
-	if err := r.db.WithContext(ctx).Model(&StudentModel{}).Count(&totalCount).Error; err != nil {
-		return nil, 0, errors.Internal("failed to count students: " + err.Error())
+	if err := filtered(scoped(r.db.WithContext(ctx).Model(&StudentModel{}), scope), filter).Count(&info.TotalCount).Error; err != nil {
+		return nil, info, errors.Internal("failed to count students: " + err.Error())
 	}
 
-	// Fetch paginated results with OFFSET and LIMIT
-	if err := r.db.WithContext(ctx).
-		Offset(params.Offset()).
-		Limit(params.Limit).
-		Order("created_at DESC"). // Most recent students first for better UX
-		Find(&models).Error; err != nil {
-		return nil, 0, errors.Internal("failed to list students: " + err.Error())
+	query := filtered(scoped(r.db.WithContext(ctx), scope), filter)
+	if params.Keyset {
+		// Continue after the cursor on (created_at, id), fetching one extra row to detect the next page
+		desc, _ := pagination.KeysetSort(filter.Sort)
+		if params.Cursor != nil {
+			condition, args := pagination.KeysetCondition(*params.Cursor, desc)
+			query = query.Where(condition, args...)
+		}
+		query = query.Limit(params.Limit + 1).Order(pagination.KeysetOrder(params, desc))
+	} else {
+		// Fetch paginated results with OFFSET and LIMIT, most recent students first unless sorted otherwise
+		query = query.Offset(params.Offset()).Limit(params.Limit).Order(pagination.OrderBy(filter.Sort, "id"))
+	}
+	if err := query.Find(&models).Error; err != nil {
+		return nil, info, errors.Internal("failed to list students: " + err.Error())
 	}
 
 	students := make([]*Student, len(models))
@@ -185,5 +336,57 @@ func (r *GormRepository) ListPaginated(ctx context.Context, params pagination.Pa
 		students[i] = model.ToStudent()
 	}
 
-	return students, totalCount, nil
+	if params.Keyset {
+		page, keyset := pagination.KeysetPage(students, params, cursorOf)
+		keyset.TotalCount = info.TotalCount
+		return page, keyset, nil
+	}
+	return students, info, nil
+}
+
+// scoped restricts a students query to the scope
+func scoped(db *gorm.DB, scope ListScope) *gorm.DB {
+	switch {
+	case scope.All:
+		return db
+	case scope.UUID != "":
+		return db.Where("id = ?", scope.UUID)
+	case scope.TeacherID != "":
+		return db.Where("teacher_id = ?", scope.TeacherID)
+	case scope.Department != "":
+		return db.Where("teacher_id IN (SELECT id FROM teachers WHERE LOWER(department) = LOWER(?) AND deleted_at IS NULL)", scope.Department)
+	}
+	return db.Where("1 = 0")
+}
+
+// filtered applies the filter of the list endpoint to a students query
+// Search and department use the expression indexes of migration 020
+func filtered(db *gorm.DB, filter ListFilter) *gorm.DB {
+	if filter.Search != "" {
+		pattern := database.ContainsPattern(filter.Search)
+		db = db.Where(`(LOWER(first_name || ' ' || last_name) LIKE ? ESCAPE '\' OR LOWER(email) LIKE ? ESCAPE '\')`, pattern, pattern)
+	}
+	if filter.TeacherID != "" {
+		db = db.Where("teacher_id = ?", filter.TeacherID)
+	}
+	if filter.Department != "" {
+		db = db.Where("teacher_id IN (SELECT id FROM teachers WHERE LOWER(department) = LOWER(?) AND deleted_at IS NULL)", filter.Department)
+	}
+	if filter.CreatedFrom != nil {
+		db = db.Where("created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		db = db.Where("created_at <= ?", *filter.CreatedTo)
+	}
+	return db
+}
+
+// GetActiveTeacher looks up the teacher a student is assigned to
+// Returns nil without error if the teacher does not exist or was deleted
+func (r *GormRepository) GetActiveTeacher(ctx context.Context, uuid string) (*reference.Teacher, error) {
+	teacher, err := reference.FindActiveTeacher(ctx, r.db, uuid)
+	if err != nil {
+		return nil, errors.Internal(err.Error())
+	}
+	return teacher, nil
 }
@@ -2,6 +2,7 @@ package student
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -9,18 +10,26 @@ import (
 	"github.com/stretchr/testify/require"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/conditional"
+	apperrors "github.com/JustDoItBetter/FITS-backend/internal/common/errors"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/pagination"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/reference"
+	"github.com/JustDoItBetter/FITS-backend/pkg/database"
 )
 
 // TestStudentModel is a simplified model for SQLite testing
 type TestStudentModel struct {
-	ID        string  `gorm:"column:id;primaryKey"`
-	FirstName string  `gorm:"column:first_name;type:varchar(100);not null"`
-	LastName  string  `gorm:"column:last_name;type:varchar(100);not null"`
-	Email     string  `gorm:"column:email;type:varchar(255);uniqueIndex;not null"`
-	TeacherID *string `gorm:"column:teacher_id;type:varchar(255)"`
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	DeletedAt gorm.DeletedAt `gorm:"index"`
+	ID               string  `gorm:"column:id;primaryKey"`
+	FirstName        string  `gorm:"column:first_name;type:varchar(100);not null"`
+	LastName         string  `gorm:"column:last_name;type:varchar(100);not null"`
+	Email            string  `gorm:"column:email;type:varchar(255);uniqueIndex:idx_students_email_active,where:deleted_at IS NULL;not null"`
+	TeacherID        *string `gorm:"column:teacher_id;type:varchar(255)"`
+	TeacherRemovedAt *time.Time
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	Version          int            `gorm:"column:version;not null;default:1"`
+	DeletedAt        gorm.DeletedAt `gorm:"index"`
 }
 
 func (TestStudentModel) TableName() string {
@@ -36,6 +45,10 @@ func setupTestDB(t *testing.T) *gorm.DB {
 	err = db.AutoMigrate(&TestStudentModel{})
 	require.NoError(t, err, "failed to migrate test database")
 
+	// Reports and signatures are only read by the purge, which must not delete records they reference
+	require.NoError(t, db.Exec(`CREATE TABLE reports (id TEXT PRIMARY KEY, student_uuid TEXT NOT NULL, teacher_uuid TEXT NOT NULL, status TEXT NOT NULL)`).Error)
+	require.NoError(t, db.Exec(`CREATE TABLE signatures (id TEXT PRIMARY KEY, report_id TEXT NOT NULL, teacher_uuid TEXT NOT NULL)`).Error)
+
 	return db
 }
 
@@ -202,6 +215,38 @@ func TestGormRepository_Update(t *testing.T) {
 	})
 }
 
+// TestGormRepository_Patch tests that Patch writes only the given columns
+func TestGormRepository_Patch(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository(db)
+	ctx := context.Background()
+
+	student := &Student{
+		UUID:      "550e8400-e29b-41d4-a716-446655440320",
+		FirstName: "John",
+		LastName:  "Doe",
+		Email:     "patched@test.com",
+	}
+	require.NoError(t, repo.Create(ctx, student))
+
+	// Another request changes the last name, the patch of the first name must not overwrite it
+	require.NoError(t, db.Table("students").Where("id = ?", student.UUID).Update("last_name", "Smith").Error)
+
+	student.FirstName = "Jane"
+	student.LastName = "Stale"
+	require.NoError(t, repo.Patch(ctx, student, []string{"first_name"}))
+	assert.Equal(t, 2, student.Version)
+
+	retrieved, err := repo.GetByUUID(ctx, student.UUID)
+	require.NoError(t, err)
+	assert.Equal(t, "Jane", retrieved.FirstName)
+	assert.Equal(t, "Smith", retrieved.LastName)
+	assert.Equal(t, 2, retrieved.Version)
+
+	err = repo.Patch(ctx, student, []string{"created_at"})
+	assertAppErrorCode(t, err, 500)
+}
+
 // TestGormRepository_Delete tests the Delete method
 func TestGormRepository_Delete(t *testing.T) {
 	db := setupTestDB(t)
@@ -222,7 +267,7 @@ func TestGormRepository_Delete(t *testing.T) {
 		require.NoError(t, err)
 
 		// Delete the student
-		err = repo.Delete(ctx, student.UUID)
+		err = repo.Delete(ctx, student.UUID, 1)
 		assert.NoError(t, err)
 
 		// Verify deletion
@@ -232,7 +277,7 @@ func TestGormRepository_Delete(t *testing.T) {
 	})
 
 	t.Run("delete nonexistent student", func(t *testing.T) {
-		err := repo.Delete(ctx, "nonexistent-uuid")
+		err := repo.Delete(ctx, "nonexistent-uuid", 1)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "not found")
 	})
@@ -296,6 +341,415 @@ func TestGormRepository_List(t *testing.T) {
 	})
 }
 
+// TestGormRepository_GetByEmails tests that import rows are matched case-insensitively, ignoring deleted students
+func TestGormRepository_GetByEmails(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository(db)
+	ctx := context.Background()
+
+	for i, email := range []string{"Max@Example.com", "erika@example.com", "deleted@example.com"} {
+		require.NoError(t, repo.Create(ctx, &Student{
+			UUID: "550e8400-e29b-41d4-a716-44665544097" + string(rune('0'+i)), FirstName: "First", LastName: "Last", Email: email, CreatedAt: time.Now(), UpdatedAt: time.Now(),
+		}))
+	}
+	require.NoError(t, repo.Delete(ctx, "550e8400-e29b-41d4-a716-446655440972", 1))
+
+	result, err := repo.GetByEmails(ctx, []string{"max@example.com", "deleted@example.com", "unknown@example.com"})
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	assert.Equal(t, "550e8400-e29b-41d4-a716-446655440970", result["max@example.com"].UUID)
+
+	result, err = repo.GetByEmails(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, result)
+}
+
+// TestGormRepository_ListPaginatedScope tests that the scope is part of the query and the count
+func TestGormRepository_ListPaginatedScope(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository(db)
+	ctx := context.Background()
+
+	teacherA := "550e8400-e29b-41d4-a716-446655440600"
+	teacherB := "550e8400-e29b-41d4-a716-446655440601"
+	for i, teacherID := range []*string{&teacherA, &teacherA, &teacherB, nil} {
+		require.NoError(t, repo.Create(ctx, &Student{
+			UUID:      fmt.Sprintf("550e8400-e29b-41d4-a716-44665544070%d", i),
+			FirstName: "Student",
+			LastName:  fmt.Sprintf("%d", i),
+			Email:     fmt.Sprintf("student%d@test.com", i),
+			TeacherID: teacherID,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}))
+	}
+
+	tests := []struct {
+		name  string
+		scope ListScope
+		want  int64
+	}{
+		{name: "all students", scope: ListScope{All: true}, want: 4},
+		{name: "students of a teacher", scope: ListScope{TeacherID: teacherA}, want: 2},
+		{name: "own record", scope: ListScope{UUID: "550e8400-e29b-41d4-a716-446655440703"}, want: 1},
+		{name: "zero scope", scope: ListScope{}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			students, info, err := repo.ListPaginated(ctx, pagination.Params{Page: 1, Limit: 20}, tt.scope, ListFilter{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, info.TotalCount)
+			assert.Len(t, students, int(tt.want))
+		})
+	}
+}
+
+// testTeacherModel is the part of the teachers table the department filter reads
+type testTeacherModel struct {
+	ID         string `gorm:"column:id;primaryKey"`
+	Email      string `gorm:"column:email"`
+	Department string `gorm:"column:department"`
+	DeletedAt  gorm.DeletedAt
+}
+
+func (testTeacherModel) TableName() string {
+	return "teachers"
+}
+
+// TestRepository_ListPaginatedFilter tests search, filters and sorting of both repositories
+func TestRepository_ListPaginatedFilter(t *testing.T) {
+	ctx := context.Background()
+	teacherIT := "550e8400-e29b-41d4-a716-446655440800"
+	teacherHR := "550e8400-e29b-41d4-a716-446655440801"
+	day := func(d int) time.Time { return time.Date(2025, 9, d, 12, 0, 0, 0, time.UTC) }
+	students := []*Student{
+		{UUID: "550e8400-e29b-41d4-a716-446655440810", FirstName: "Max", LastName: "Mustermann", Email: "max@school.de", TeacherID: &teacherIT, CreatedAt: day(1), UpdatedAt: day(1)},
+		{UUID: "550e8400-e29b-41d4-a716-446655440811", FirstName: "Erika", LastName: "Mustermann", Email: "erika@school.de", TeacherID: &teacherHR, CreatedAt: day(2), UpdatedAt: day(2)},
+		{UUID: "550e8400-e29b-41d4-a716-446655440812", FirstName: "Anna", LastName: "Schmidt", Email: "a.schmidt@example.com", TeacherID: &teacherIT, CreatedAt: day(3), UpdatedAt: day(3)},
+		{UUID: "550e8400-e29b-41d4-a716-446655440813", FirstName: "Tom", LastName: "Becker", Email: "tom_becker@school.de", CreatedAt: day(4), UpdatedAt: day(4)},
+	}
+
+	gormRepo := func(t *testing.T) Repository {
+		db := setupTestDB(t)
+		require.NoError(t, db.AutoMigrate(&testTeacherModel{}))
+		require.NoError(t, db.Create(&testTeacherModel{ID: teacherIT, Department: "IT"}).Error)
+		require.NoError(t, db.Create(&testTeacherModel{ID: teacherHR, Department: "HR"}).Error)
+		return NewGormRepository(db)
+	}
+	inMemoryRepo := func(t *testing.T) Repository {
+		repo := NewInMemoryRepository()
+		repo.AddTeacher(&reference.Teacher{UUID: teacherIT, Department: "IT"})
+		repo.AddTeacher(&reference.Teacher{UUID: teacherHR, Department: "HR"})
+		return repo
+	}
+
+	from, to := day(2), day(3)
+	tests := []struct {
+		name   string
+		filter ListFilter
+		want   []string // Last names in order
+	}{
+		{name: "newest first by default", filter: ListFilter{}, want: []string{"Becker", "Schmidt", "Mustermann", "Mustermann"}},
+		{name: "search full name", filter: ListFilter{Search: "max muster"}, want: []string{"Mustermann"}},
+		{name: "search email", filter: ListFilter{Search: "EXAMPLE.com"}, want: []string{"Schmidt"}},
+		{name: "search literal underscore", filter: ListFilter{Search: "_"}, want: []string{"Becker"}},
+		{name: "search literal percent", filter: ListFilter{Search: "%"}, want: []string{}},
+		{name: "teacher", filter: ListFilter{TeacherID: teacherIT}, want: []string{"Schmidt", "Mustermann"}},
+		{name: "department of the teacher", filter: ListFilter{Department: "hr"}, want: []string{"Mustermann"}},
+		{name: "created range", filter: ListFilter{CreatedFrom: &from, CreatedTo: &to}, want: []string{"Schmidt", "Mustermann"}},
+		{
+			name:   "multi-field sort",
+			filter: ListFilter{Sort: []pagination.SortField{{Field: "last_name"}, {Field: "first_name", Desc: true}}},
+			want:   []string{"Becker", "Mustermann", "Mustermann", "Schmidt"},
+		},
+	}
+
+	for name, newRepo := range map[string]func(*testing.T) Repository{"gorm": gormRepo, "in-memory": inMemoryRepo} {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+			for _, student := range students {
+				require.NoError(t, repo.Create(ctx, student))
+			}
+
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					result, info, err := repo.ListPaginated(ctx, pagination.Params{Page: 1, Limit: 20}, ListScope{All: true}, tt.filter)
+					require.NoError(t, err)
+					assert.Equal(t, int64(len(tt.want)), info.TotalCount)
+
+					lastNames := make([]string, len(result))
+					for i, student := range result {
+						lastNames[i] = student.LastName
+					}
+					assert.Equal(t, tt.want, lastNames)
+				})
+			}
+
+			t.Run("sort breaks ties", func(t *testing.T) {
+				sort := []pagination.SortField{{Field: "last_name"}, {Field: "first_name", Desc: true}}
+				result, _, err := repo.ListPaginated(ctx, pagination.Params{Page: 1, Limit: 20}, ListScope{All: true}, ListFilter{Sort: sort})
+				require.NoError(t, err)
+				assert.Equal(t, "Max", result[1].FirstName)
+				assert.Equal(t, "Erika", result[2].FirstName)
+			})
+		})
+	}
+}
+
+// TestRepository_Versions tests that writes are only applied to the version they were based on
+func TestRepository_Versions(t *testing.T) {
+	ctx := context.Background()
+	repos := map[string]func(*testing.T) Repository{
+		"gorm":      func(t *testing.T) Repository { return NewGormRepository(setupTestDB(t)) },
+		"in-memory": func(t *testing.T) Repository { return NewInMemoryRepository() },
+	}
+
+	for name, newRepo := range repos {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+			studentUUID := "550e8400-e29b-41d4-a716-446655440960"
+			require.NoError(t, repo.Create(ctx, &Student{
+				UUID:      studentUUID,
+				FirstName: "Versioned",
+				LastName:  "Student",
+				Email:     "versioned@school.de",
+			}))
+
+			loaded, err := repo.GetByUUID(ctx, studentUUID)
+			require.NoError(t, err)
+			assert.Equal(t, 1, loaded.Version)
+			stale := *loaded
+
+			loaded.FirstName = "Changed"
+			require.NoError(t, repo.Update(ctx, loaded))
+			assert.Equal(t, 2, loaded.Version)
+
+			// A write based on the old version is rejected
+			stale.FirstName = "Lost"
+			assertAppErrorCode(t, repo.Update(ctx, &stale), 412)
+			assertAppErrorCode(t, repo.Delete(ctx, studentUUID, 1), 412)
+
+			current, err := repo.GetByUUID(ctx, studentUUID)
+			require.NoError(t, err)
+			assert.Equal(t, "Changed", current.FirstName)
+			assert.Equal(t, 2, current.Version)
+
+			require.NoError(t, repo.Delete(ctx, studentUUID, 2))
+			assertAppErrorCode(t, repo.Delete(ctx, studentUUID, 2), 404)
+		})
+	}
+}
+
+// TestRepository_Trash tests listing, restoring and purging deleted students in both repositories
+func TestRepository_Trash(t *testing.T) {
+	ctx := context.Background()
+	repos := map[string]func(*testing.T) Repository{
+		"gorm":      func(t *testing.T) Repository { return NewGormRepository(setupTestDB(t)) },
+		"in-memory": func(t *testing.T) Repository { return NewInMemoryRepository() },
+	}
+
+	for name, newRepo := range repos {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+			for i, email := range []string{"first@school.de", "second@school.de", "kept@school.de"} {
+				require.NoError(t, repo.Create(ctx, &Student{
+					UUID:      fmt.Sprintf("550e8400-e29b-41d4-a716-44665544093%d", i),
+					FirstName: "Trash",
+					LastName:  fmt.Sprintf("Student%d", i),
+					Email:     email,
+					CreatedAt: time.Now(),
+					UpdatedAt: time.Now(),
+				}))
+			}
+			require.NoError(t, repo.Delete(ctx, "550e8400-e29b-41d4-a716-446655440930", 1))
+			time.Sleep(10 * time.Millisecond)
+			require.NoError(t, repo.Delete(ctx, "550e8400-e29b-41d4-a716-446655440931", 1))
+
+			// Most recently deleted first
+			deleted, total, err := repo.ListDeleted(ctx, pagination.Params{Page: 1, Limit: 20})
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), total)
+			require.Len(t, deleted, 2)
+			assert.Equal(t, "second@school.de", deleted[0].Email)
+			assert.Equal(t, "first@school.de", deleted[1].Email)
+			assert.NotNil(t, deleted[0].DeletedAt)
+
+			// The email of a deleted student can be used again, which blocks restoring
+			require.NoError(t, repo.Create(ctx, &Student{
+				UUID:      "550e8400-e29b-41d4-a716-446655440939",
+				FirstName: "New",
+				LastName:  "Student",
+				Email:     "first@school.de",
+			}))
+			err = repo.Restore(ctx, "550e8400-e29b-41d4-a716-446655440930")
+			var appErr *apperrors.AppError
+			require.ErrorAs(t, err, &appErr)
+			assert.Equal(t, 409, appErr.Code)
+
+			require.NoError(t, repo.Restore(ctx, "550e8400-e29b-41d4-a716-446655440931"))
+			restored, err := repo.GetByUUID(ctx, "550e8400-e29b-41d4-a716-446655440931")
+			require.NoError(t, err)
+			assert.Nil(t, restored.DeletedAt)
+
+			// Only deleted students can be restored
+			err = repo.Restore(ctx, "550e8400-e29b-41d4-a716-446655440932")
+			require.ErrorAs(t, err, &appErr)
+			assert.Equal(t, 404, appErr.Code)
+
+			purged, err := repo.PurgeDeleted(ctx, time.Now().Add(-time.Hour))
+			require.NoError(t, err)
+			assert.Equal(t, int64(0), purged)
+
+			purged, err = repo.PurgeDeleted(ctx, time.Now().Add(time.Second))
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), purged)
+
+			_, total, err = repo.ListDeleted(ctx, pagination.Params{Page: 1, Limit: 20})
+			require.NoError(t, err)
+			assert.Equal(t, int64(0), total)
+		})
+	}
+}
+
+// TestGormRepository_PurgeDeletedKeepsReports tests that students with reports are never purged
+func TestGormRepository_PurgeDeletedKeepsReports(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB(t)
+	repo := NewGormRepository(db)
+	withReport := "550e8400-e29b-41d4-a716-446655440950"
+	withoutReport := "550e8400-e29b-41d4-a716-446655440951"
+
+	for i, uuid := range []string{withReport, withoutReport} {
+		require.NoError(t, repo.Create(ctx, &Student{UUID: uuid, FirstName: "Max", LastName: "Mustermann", Email: fmt.Sprintf("max%d@school.de", i)}))
+		require.NoError(t, repo.Delete(ctx, uuid, 1))
+	}
+	require.NoError(t, db.Exec(`INSERT INTO reports (id, student_uuid, teacher_uuid, status) VALUES ('report-1', ?, 'teacher-1', 'signed')`, withReport).Error)
+
+	purged, err := repo.PurgeDeleted(ctx, time.Now().Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	deleted, total, err := repo.ListDeleted(ctx, pagination.Params{Page: 1, Limit: 20})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, withReport, deleted[0].UUID)
+}
+
+// TestService_Restore tests that a restored student loses a teacher deleted in the meantime
+func TestService_Restore(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB(t)
+	require.NoError(t, db.AutoMigrate(&testTeacherModel{}))
+	activeTeacher := "550e8400-e29b-41d4-a716-446655440940"
+	deletedTeacher := "550e8400-e29b-41d4-a716-446655440941"
+	require.NoError(t, db.Create(&testTeacherModel{ID: activeTeacher, Department: "IT"}).Error)
+	require.NoError(t, db.Create(&testTeacherModel{ID: deletedTeacher, Department: "IT"}).Error)
+
+	repo := NewGormRepository(db)
+	service := NewServiceWithTx(repo, database.NewTransactionManager(db))
+	for i, teacherID := range []string{activeTeacher, deletedTeacher} {
+		require.NoError(t, repo.Create(ctx, &Student{
+			UUID:      fmt.Sprintf("550e8400-e29b-41d4-a716-44665544095%d", i),
+			FirstName: "Restored",
+			LastName:  "Student",
+			Email:     fmt.Sprintf("restored%d@school.de", i),
+			TeacherID: &teacherID,
+		}))
+		require.NoError(t, service.Delete(ctx, fmt.Sprintf("550e8400-e29b-41d4-a716-44665544095%d", i), conditional.Any, fullAdmin))
+	}
+	require.NoError(t, db.Delete(&testTeacherModel{ID: deletedTeacher}).Error)
+
+	student, err := service.Restore(ctx, "550e8400-e29b-41d4-a716-446655440950")
+	require.NoError(t, err)
+	require.NotNil(t, student.TeacherID)
+	assert.Equal(t, activeTeacher, *student.TeacherID)
+	assert.Nil(t, student.TeacherRemovedAt)
+
+	student, err = service.Restore(ctx, "550e8400-e29b-41d4-a716-446655440951")
+	require.NoError(t, err)
+	assert.Nil(t, student.TeacherID)
+	assert.NotNil(t, student.TeacherRemovedAt)
+
+	stored, err := service.GetByUUID(ctx, student.UUID)
+	require.NoError(t, err)
+	assert.Nil(t, stored.TeacherID)
+}
+
+// TestRepository_ListPaginatedKeyset tests paging forward and back with cursors in both repositories
+func TestRepository_ListPaginatedKeyset(t *testing.T) {
+	ctx := context.Background()
+	day := func(d int) time.Time { return time.Date(2025, 9, d, 12, 0, 0, 0, time.UTC) }
+	// Two students share a timestamp, the id breaks the tie
+	students := []*Student{
+		{UUID: "550e8400-e29b-41d4-a716-446655440820", FirstName: "A", LastName: "One", Email: "one@school.de", CreatedAt: day(1), UpdatedAt: day(1)},
+		{UUID: "550e8400-e29b-41d4-a716-446655440821", FirstName: "B", LastName: "Two", Email: "two@school.de", CreatedAt: day(2), UpdatedAt: day(2)},
+		{UUID: "550e8400-e29b-41d4-a716-446655440822", FirstName: "C", LastName: "Three", Email: "three@school.de", CreatedAt: day(2), UpdatedAt: day(2)},
+		{UUID: "550e8400-e29b-41d4-a716-446655440823", FirstName: "D", LastName: "Four", Email: "four@school.de", CreatedAt: day(3), UpdatedAt: day(3)},
+		{UUID: "550e8400-e29b-41d4-a716-446655440824", FirstName: "E", LastName: "Five", Email: "five@school.de", CreatedAt: day(4), UpdatedAt: day(4)},
+	}
+	lastNames := func(result []*Student) []string {
+		names := make([]string, len(result))
+		for i, student := range result {
+			names[i] = student.LastName
+		}
+		return names
+	}
+
+	repos := map[string]func(*testing.T) Repository{
+		"gorm":      func(t *testing.T) Repository { return NewGormRepository(setupTestDB(t)) },
+		"in-memory": func(t *testing.T) Repository { return NewInMemoryRepository() },
+	}
+	for name, newRepo := range repos {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+			for _, student := range students {
+				require.NoError(t, repo.Create(ctx, student))
+			}
+			list := func(cursor *pagination.Cursor, sort []pagination.SortField) ([]string, pagination.PageInfo) {
+				params := pagination.Params{Limit: 2, Keyset: true, Cursor: cursor}
+				result, info, err := repo.ListPaginated(ctx, params, ListScope{All: true}, ListFilter{Sort: sort})
+				require.NoError(t, err)
+				return lastNames(result), info
+			}
+
+			page, info := list(nil, nil)
+			assert.Equal(t, []string{"Five", "Four"}, page)
+			assert.Equal(t, int64(5), info.TotalCount)
+			assert.Nil(t, info.Prev)
+			require.NotNil(t, info.Next)
+
+			// Records created meanwhile don't shift the following pages
+			require.NoError(t, repo.Create(ctx, &Student{
+				UUID: "550e8400-e29b-41d4-a716-446655440825", FirstName: "F", LastName: "Six", Email: "six@school.de", CreatedAt: day(5), UpdatedAt: day(5),
+			}))
+
+			page, info = list(info.Next, nil)
+			assert.Equal(t, []string{"Three", "Two"}, page)
+			require.NotNil(t, info.Prev)
+			require.NotNil(t, info.Next)
+			second := info
+
+			page, info = list(second.Next, nil)
+			assert.Equal(t, []string{"One"}, page)
+			assert.Nil(t, info.Next)
+
+			page, info = list(second.Prev, nil)
+			assert.Equal(t, []string{"Five", "Four"}, page)
+			require.NotNil(t, info.Prev, "the record created meanwhile comes before")
+			require.NotNil(t, info.Next)
+
+			page, _ = list(info.Next, nil)
+			assert.Equal(t, []string{"Three", "Two"}, page)
+
+			page, info = list(nil, []pagination.SortField{{Field: "created_at"}})
+			assert.Equal(t, []string{"One", "Two"}, page)
+			page, _ = list(info.Next, []pagination.SortField{{Field: "created_at"}})
+			assert.Equal(t, []string{"Three", "Four"}, page)
+		})
+	}
+}
+
 // TestGormRepository_WithTeacher tests student creation with teacher reference
 func TestGormRepository_WithTeacher(t *testing.T) {
 	db := setupTestDB(t)
@@ -350,3 +804,107 @@ func TestGormRepository_WithTeacher(t *testing.T) {
 		assert.Nil(t, retrieved.TeacherID)
 	})
 }
+
+// TestGormRepository_TeacherRemovedAt tests the flag of students whose teacher was deleted
+func TestGormRepository_TeacherRemovedAt(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository(db)
+	ctx := context.Background()
+
+	removed := time.Now()
+	student := &Student{
+		UUID:             "550e8400-e29b-41d4-a716-446655440700",
+		FirstName:        "Student",
+		LastName:         "Orphaned",
+		Email:            "orphaned@test.com",
+		TeacherRemovedAt: &removed,
+	}
+	require.NoError(t, repo.Create(ctx, student))
+
+	retrieved, err := repo.GetByUUID(ctx, student.UUID)
+	require.NoError(t, err)
+	assert.NotNil(t, retrieved.TeacherRemovedAt)
+
+	// Assigning a new teacher clears the flag
+	teacherID := "550e8400-e29b-41d4-a716-446655440003"
+	retrieved.Update(&UpdateStudentRequest{TeacherID: &teacherID})
+	require.NoError(t, repo.Update(ctx, retrieved))
+
+	retrieved, err = repo.GetByUUID(ctx, student.UUID)
+	require.NoError(t, err)
+	assert.Nil(t, retrieved.TeacherRemovedAt)
+	assert.Equal(t, teacherID, *retrieved.TeacherID)
+}
+
+// TestService_DeleteHooks tests that delete hooks run in the transaction deleting the student
+func TestService_DeleteHooks(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T) (*gorm.DB, *Service, string) {
+		db := setupTestDB(t)
+		repo := NewGormRepository(db)
+		student := &Student{
+			UUID:      "550e8400-e29b-41d4-a716-446655440800",
+			FirstName: "Hooked",
+			LastName:  "Student",
+			Email:     "hooked@test.com",
+		}
+		require.NoError(t, repo.Create(ctx, student))
+		return db, NewServiceWithTx(repo, database.NewTransactionManager(db)), student.UUID
+	}
+
+	t.Run("runs hooks and soft-deletes", func(t *testing.T) {
+		db, service, uuid := setup(t)
+		var hooked []string
+		service.OnDelete(
+			func(ctx context.Context, tx *gorm.DB, uuid string) error {
+				hooked = append(hooked, "first "+uuid)
+				return nil
+			},
+			func(ctx context.Context, tx *gorm.DB, uuid string) error {
+				hooked = append(hooked, "second "+uuid)
+				return nil
+			},
+		)
+
+		require.NoError(t, service.Delete(ctx, uuid, conditional.Any, fullAdmin))
+
+		assert.Equal(t, []string{"first " + uuid, "second " + uuid}, hooked)
+		_, err := service.GetByUUID(ctx, uuid)
+		assert.Error(t, err)
+
+		var count int64
+		require.NoError(t, db.Unscoped().Model(&TestStudentModel{}).Where("id = ?", uuid).Count(&count).Error)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("failing hook rolls back the deletion", func(t *testing.T) {
+		_, service, uuid := setup(t)
+		service.OnDelete(func(ctx context.Context, tx *gorm.DB, uuid string) error {
+			return apperrors.Internal("hook failed")
+		})
+
+		assert.Error(t, service.Delete(ctx, uuid, conditional.Any, fullAdmin))
+
+		_, err := service.GetByUUID(ctx, uuid)
+		assert.NoError(t, err)
+	})
+
+	t.Run("hooks require transaction support", func(t *testing.T) {
+		db, _, uuid := setup(t)
+		service := NewService(NewGormRepository(db))
+		service.OnDelete(func(ctx context.Context, tx *gorm.DB, uuid string) error { return nil })
+
+		assert.Error(t, service.Delete(ctx, uuid, conditional.Any, fullAdmin))
+
+		_, err := service.GetByUUID(ctx, uuid)
+		assert.NoError(t, err)
+	})
+}
+
+func assertAppErrorCode(t *testing.T, err error, code int) {
+	t.Helper()
+	appErr, ok := err.(*apperrors.AppError)
+	require.True(t, ok, "expected AppError, got %v", err)
+	assert.Equal(t, code, appErr.Code)
+}
@@ -2,9 +2,17 @@ package student
 
 import (
 	"context"
+	"strings"
+	"time"
 
+	"github.com/JustDoItBetter/FITS-backend/internal/common/access"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/conditional"
 	"github.com/JustDoItBetter/FITS-backend/internal/common/errors"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/lifecycle"
 	"github.com/JustDoItBetter/FITS-backend/internal/common/pagination"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/reference"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/validation"
+	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
 	"github.com/JustDoItBetter/FITS-backend/pkg/database"
 	"github.com/go-playground/validator/v10"
 	"gorm.io/gorm"
@@ -12,9 +20,10 @@ import (
 
 // Service handles business logic for students
 type Service struct {
-	repo     Repository
-	txMgr    *database.TransactionManager
-	validate *validator.Validate
+	repo        Repository
+	txMgr       *database.TransactionManager
+	validate    *validator.Validate
+	deleteHooks lifecycle.DeleteHooks
 }
 
 // NewService creates a new student service
@@ -35,7 +44,8 @@ func NewServiceWithTx(repo Repository, txMgr *database.TransactionManager) *Serv
 }
 
 // Create creates a new student
-func (s *Service) Create(ctx context.Context, req *CreateStudentRequest) (*Student, error) {
+// Department admins may only assign the student to a teacher of their department
+func (s *Service) Create(ctx context.Context, req *CreateStudentRequest, viewer access.Viewer) (*Student, error) {
 	// Validate request
 	if err := s.validate.Struct(req); err != nil {
 		return nil, errors.ValidationError(err.Error())
@@ -44,6 +54,14 @@ func (s *Service) Create(ctx context.Context, req *CreateStudentRequest) (*Stude
 	// Convert request to student entity
 	student := req.ToStudent()
 
+	// Reject unknown or deleted teachers instead of failing on the foreign key
+	if err := validateTeacherAssignment(ctx, s.repo, nil, student.TeacherID); err != nil {
+		return nil, err
+	}
+	if err := checkDepartment(ctx, s.repo, student.TeacherID, viewer); err != nil {
+		return nil, err
+	}
+
 	// Create student in repository
 	if err := s.repo.Create(ctx, student); err != nil {
 		return nil, err
@@ -57,9 +75,35 @@ func (s *Service) GetByUUID(ctx context.Context, uuid string) (*Student, error)
 	return s.repo.GetByUUID(ctx, uuid)
 }
 
-// Update updates an existing student
-// Uses transactions to prevent race conditions between read and write operations
-func (s *Service) Update(ctx context.Context, uuid string, req *UpdateStudentRequest) (*Student, error) {
+// GetForViewer retrieves a student the viewer may read
+// Students read their own record, teachers the records of their students, department admins the students
+// of their department's teachers and other admins every record
+func (s *Service) GetForViewer(ctx context.Context, uuid string, viewer access.Viewer) (*Student, error) {
+	student, err := s.repo.GetByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case viewer.IsDepartmentAdmin():
+		if err := checkDepartment(ctx, s.repo, student.TeacherID, viewer); err != nil {
+			return nil, err
+		}
+		return student, nil
+	case viewer.IsAdmin():
+		return student, nil
+	case viewer.Role == crypto.RoleStudent && viewer.Owns(student.UUID):
+		return student, nil
+	case viewer.Role == crypto.RoleTeacher && student.TeacherID != nil && viewer.Owns(*student.TeacherID):
+		return student, nil
+	}
+	return nil, access.Forbidden()
+}
+
+// Update updates an existing student if its version satisfies ifMatch
+// Uses transactions to prevent race conditions between read and write operations.
+// Department admins may only update students of their department and keep them there
+func (s *Service) Update(ctx context.Context, uuid string, ifMatch conditional.Precondition, req *UpdateStudentRequest, viewer access.Viewer) (*Student, error) {
 	// Validate request
 	if err := s.validate.Struct(req); err != nil {
 		return nil, errors.ValidationError(err.Error())
@@ -76,6 +120,21 @@ func (s *Service) Update(ctx context.Context, uuid string, req *UpdateStudentReq
 			if err != nil {
 				return nil, err
 			}
+			if err := checkDepartment(ctx, txRepo, student.TeacherID, viewer); err != nil {
+				return nil, err
+			}
+			if err := ifMatch.Check("student", student.Version); err != nil {
+				return nil, err
+			}
+
+			if err := validateTeacherAssignment(ctx, txRepo, student, req.TeacherID); err != nil {
+				return nil, err
+			}
+			if req.TeacherID != nil {
+				if err := checkDepartment(ctx, txRepo, req.TeacherID, viewer); err != nil {
+					return nil, err
+				}
+			}
 
 			// Update student fields
 			student.Update(req)
@@ -94,6 +153,21 @@ func (s *Service) Update(ctx context.Context, uuid string, req *UpdateStudentReq
 	if err != nil {
 		return nil, err
 	}
+	if err := checkDepartment(ctx, s.repo, student.TeacherID, viewer); err != nil {
+		return nil, err
+	}
+	if err := ifMatch.Check("student", student.Version); err != nil {
+		return nil, err
+	}
+
+	if err := validateTeacherAssignment(ctx, s.repo, student, req.TeacherID); err != nil {
+		return nil, err
+	}
+	if req.TeacherID != nil {
+		if err := checkDepartment(ctx, s.repo, req.TeacherID, viewer); err != nil {
+			return nil, err
+		}
+	}
 
 	student.Update(req)
 
@@ -104,9 +178,149 @@ func (s *Service) Update(ctx context.Context, uuid string, req *UpdateStudentReq
 	return student, nil
 }
 
-// Delete deletes a student by UUID
-func (s *Service) Delete(ctx context.Context, uuid string) error {
-	return s.repo.Delete(ctx, uuid)
+// Patch applies a JSON merge patch to a student if its version satisfies ifMatch
+// The merged student is validated as a whole, and only the changed columns are written
+func (s *Service) Patch(ctx context.Context, uuid string, ifMatch conditional.Precondition, req *PatchStudentRequest, viewer access.Viewer) (*Student, error) {
+	if s.txMgr == nil {
+		return s.patch(ctx, s.repo, uuid, ifMatch, req, viewer)
+	}
+	return database.WithTransactionValue(ctx, s.txMgr, func(tx *gorm.DB) (*Student, error) {
+		return s.patch(ctx, s.repo.WithDB(tx), uuid, ifMatch, req, viewer)
+	})
+}
+
+// patch merges the patch into a copy of the student, so a rejected patch leaves the loaded student untouched
+func (s *Service) patch(ctx context.Context, repo Repository, uuid string, ifMatch conditional.Precondition, req *PatchStudentRequest, viewer access.Viewer) (*Student, error) {
+	student, err := repo.GetByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkDepartment(ctx, repo, student.TeacherID, viewer); err != nil {
+		return nil, err
+	}
+	if err := ifMatch.Check("student", student.Version); err != nil {
+		return nil, err
+	}
+
+	merged := *student
+	columns, err := merged.Patch(req)
+	if err != nil {
+		return nil, err
+	}
+	if len(columns) == 0 {
+		return student, nil
+	}
+
+	if err := s.validate.Struct(&merged); err != nil {
+		return nil, errors.ValidationError(err.Error())
+	}
+	if merged.TeacherID != nil && !validation.IsValidUUID(*merged.TeacherID) {
+		return nil, errors.ValidationError("teacher_id must be a UUID or null")
+	}
+	if err := validateTeacherAssignment(ctx, repo, student, merged.TeacherID); err != nil {
+		return nil, err
+	}
+	if err := checkDepartment(ctx, repo, merged.TeacherID, viewer); err != nil {
+		return nil, err
+	}
+
+	if err := repo.Patch(ctx, &merged, columns); err != nil {
+		return nil, err
+	}
+	return &merged, nil
+}
+
+// OnDelete registers hooks that run in the transaction deleting a student
+// Used to keep records of other domains, like the student's account, consistent
+func (s *Service) OnDelete(hooks ...lifecycle.DeleteHook) {
+	s.deleteHooks = append(s.deleteHooks, hooks...)
+}
+
+// Delete soft-deletes a student by UUID together with the registered delete hooks
+// if its version satisfies ifMatch. A failing hook rolls back the deletion
+func (s *Service) Delete(ctx context.Context, uuid string, ifMatch conditional.Precondition, viewer access.Viewer) error {
+	if s.txMgr == nil {
+		if len(s.deleteHooks) > 0 {
+			return errors.Internal("delete hooks require transaction support")
+		}
+		return deleteStudent(ctx, s.repo, uuid, ifMatch, viewer)
+	}
+
+	return s.txMgr.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := deleteStudent(ctx, s.repo.WithDB(tx), uuid, ifMatch, viewer); err != nil {
+			return err
+		}
+		return s.deleteHooks.Run(ctx, tx, uuid)
+	})
+}
+
+// deleteStudent deletes the student if its version satisfies ifMatch
+// The repository deletes only the checked version, so a concurrent update fails the deletion
+func deleteStudent(ctx context.Context, repo Repository, uuid string, ifMatch conditional.Precondition, viewer access.Viewer) error {
+	student, err := repo.GetByUUID(ctx, uuid)
+	if err != nil {
+		return err
+	}
+	if err := checkDepartment(ctx, repo, student.TeacherID, viewer); err != nil {
+		return err
+	}
+	if err := ifMatch.Check("student", student.Version); err != nil {
+		return err
+	}
+	return repo.Delete(ctx, uuid, student.Version)
+}
+
+// ListDeleted retrieves a page of the students in the trash, most recently deleted first
+func (s *Service) ListDeleted(ctx context.Context, params pagination.Params) ([]*Student, int64, error) {
+	return s.repo.ListDeleted(ctx, params)
+}
+
+// Restore takes a student out of the trash
+// A teacher deleted in the meantime is removed from the student, which is flagged like the students of a deleted teacher.
+// The student's account stays disabled, admins enable it again if it should be used
+func (s *Service) Restore(ctx context.Context, uuid string) (*Student, error) {
+	if s.txMgr == nil {
+		return restore(ctx, s.repo, uuid)
+	}
+	return database.WithTransactionValue(ctx, s.txMgr, func(tx *gorm.DB) (*Student, error) {
+		return restore(ctx, s.repo.WithDB(tx), uuid)
+	})
+}
+
+// restore undeletes the student and unassigns its teacher if that has been deleted
+func restore(ctx context.Context, repo Repository, uuid string) (*Student, error) {
+	if err := repo.Restore(ctx, uuid); err != nil {
+		return nil, err
+	}
+
+	student, err := repo.GetByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	if student.TeacherID == nil {
+		return student, nil
+	}
+
+	teacher, err := repo.GetActiveTeacher(ctx, *student.TeacherID)
+	if err != nil {
+		return nil, err
+	}
+	if teacher == nil {
+		now := time.Now()
+		student.TeacherID = nil
+		student.TeacherRemovedAt = &now
+		student.UpdatedAt = now
+		if err := repo.Update(ctx, student); err != nil {
+			return nil, err
+		}
+	}
+	return student, nil
+}
+
+// PurgeDeleted permanently removes the students deleted before the given time
+// Used as lifecycle.PurgeFunc once the retention time of the trash has passed
+func (s *Service) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	return s.repo.PurgeDeleted(ctx, before)
 }
 
 // List retrieves all students (deprecated: use ListPaginated)
@@ -114,8 +328,85 @@ func (s *Service) List(ctx context.Context) ([]*Student, error) {
 	return s.repo.List(ctx)
 }
 
-// ListPaginated retrieves students with pagination
-// Returns students slice, total count, and error
-func (s *Service) ListPaginated(ctx context.Context, params pagination.Params) ([]*Student, int64, error) {
-	return s.repo.ListPaginated(ctx, params)
+// ListPaginated retrieves the students the viewer may see matching the filter with pagination
+// Admins see every student or those of their department's teachers, teachers their assigned students and students their own record
+// Keyset pagination only supports sorting by created_at
+// Returns students slice, total count and cursors, and error
+func (s *Service) ListPaginated(ctx context.Context, params pagination.Params, filter ListFilter, viewer access.Viewer) ([]*Student, pagination.PageInfo, error) {
+	scope, filter, err := listQuery(filter, viewer)
+	if err != nil {
+		return nil, pagination.PageInfo{}, err
+	}
+	if _, ok := pagination.KeysetSort(filter.Sort); params.Keyset && !ok {
+		return nil, pagination.PageInfo{}, errors.ValidationError("cursor pagination only supports sort=created_at or sort=-created_at")
+	}
+	return s.repo.ListPaginated(ctx, params, scope, filter)
+}
+
+// listQuery returns the scope of the viewer and the checked filter of a list or export
+func listQuery(filter ListFilter, viewer access.Viewer) (ListScope, ListFilter, error) {
+	scope, err := listScope(viewer)
+	if err != nil {
+		return ListScope{}, filter, err
+	}
+
+	filter.Search = strings.TrimSpace(filter.Search)
+	filter.Department = strings.TrimSpace(filter.Department)
+	if filter.TeacherID != "" && !validation.IsValidUUID(filter.TeacherID) {
+		return ListScope{}, filter, errors.ValidationError("teacher_id must be a UUID")
+	}
+	if filter.CreatedFrom != nil && filter.CreatedTo != nil && filter.CreatedFrom.After(*filter.CreatedTo) {
+		return ListScope{}, filter, errors.ValidationError("created_from must not be after created_to")
+	}
+	return scope, filter, nil
+}
+
+// listScope returns the students the viewer may list
+// An account without linked record or department gets the zero scope and sees no students
+func listScope(viewer access.Viewer) (ListScope, error) {
+	switch viewer.Role {
+	case crypto.RoleAdmin:
+		if viewer.IsDepartmentAdmin() {
+			return ListScope{Department: strings.TrimSpace(viewer.Department)}, nil
+		}
+		return ListScope{All: true}, nil
+	case crypto.RoleStudent:
+		return ListScope{UUID: viewer.UserUUID}, nil
+	case crypto.RoleTeacher:
+		return ListScope{TeacherID: viewer.UserUUID}, nil
+	}
+	return ListScope{}, errors.Unauthorized("authentication required")
+}
+
+// validateTeacherAssignment checks that a newly assigned teacher exists and is not deleted
+// An unchanged assignment of current is not checked again. Students carry no department,
+// so the department rules don't apply here
+func validateTeacherAssignment(ctx context.Context, repo Repository, current *Student, teacherID *string) error {
+	if teacherID == nil || *teacherID == "" {
+		return nil
+	}
+	if current != nil && current.TeacherID != nil && *current.TeacherID == *teacherID {
+		return nil
+	}
+	return reference.Rules{}.ValidateTeacher(ctx, repo, *teacherID, nil)
+}
+
+// checkDepartment checks that the viewer may manage a student assigned to teacherID
+// Department admins only manage the students of active teachers in their department, other viewers aren't limited here
+func checkDepartment(ctx context.Context, repo Repository, teacherID *string, viewer access.Viewer) error {
+	if !viewer.IsDepartmentAdmin() {
+		return nil
+	}
+	if teacherID == nil || *teacherID == "" {
+		return access.OutsideDepartment()
+	}
+
+	teacher, err := repo.GetActiveTeacher(ctx, *teacherID)
+	if err != nil {
+		return err
+	}
+	if teacher == nil || !viewer.InDepartment(teacher.Department) {
+		return access.OutsideDepartment()
+	}
+	return nil
 }
@@ -6,10 +6,16 @@ import (
 	"testing"
 	"time"
 
+	"github.com/JustDoItBetter/FITS-backend/internal/common/access"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/conditional"
 	apperrors "github.com/JustDoItBetter/FITS-backend/internal/common/errors"
 	"github.com/JustDoItBetter/FITS-backend/internal/common/pagination"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/patch"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/reference"
+	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
 )
 
@@ -36,11 +42,34 @@ func (m *MockRepository) Update(ctx context.Context, student *Student) error {
 	return args.Error(0)
 }
 
-func (m *MockRepository) Delete(ctx context.Context, uuid string) error {
+func (m *MockRepository) Patch(ctx context.Context, student *Student, columns []string) error {
+	args := m.Called(ctx, student, columns)
+	return args.Error(0)
+}
+
+func (m *MockRepository) Delete(ctx context.Context, uuid string, version int) error {
+	args := m.Called(ctx, uuid, version)
+	return args.Error(0)
+}
+
+func (m *MockRepository) ListDeleted(ctx context.Context, params pagination.Params) ([]*Student, int64, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*Student), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockRepository) Restore(ctx context.Context, uuid string) error {
 	args := m.Called(ctx, uuid)
 	return args.Error(0)
 }
 
+func (m *MockRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (m *MockRepository) List(ctx context.Context) ([]*Student, error) {
 	args := m.Called(ctx)
 	if args.Get(0) == nil {
@@ -49,12 +78,28 @@ func (m *MockRepository) List(ctx context.Context) ([]*Student, error) {
 	return args.Get(0).([]*Student), args.Error(1)
 }
 
-func (m *MockRepository) ListPaginated(ctx context.Context, params pagination.Params) ([]*Student, int64, error) {
-	args := m.Called(ctx, params)
+func (m *MockRepository) ListPaginated(ctx context.Context, params pagination.Params, scope ListScope, filter ListFilter) ([]*Student, pagination.PageInfo, error) {
+	args := m.Called(ctx, params, scope, filter)
 	if args.Get(0) == nil {
-		return nil, args.Get(1).(int64), args.Error(2)
+		return nil, args.Get(1).(pagination.PageInfo), args.Error(2)
 	}
-	return args.Get(0).([]*Student), args.Get(1).(int64), args.Error(2)
+	return args.Get(0).([]*Student), args.Get(1).(pagination.PageInfo), args.Error(2)
+}
+
+func (m *MockRepository) GetByEmails(ctx context.Context, emails []string) (map[string]*Student, error) {
+	args := m.Called(ctx, emails)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]*Student), args.Error(1)
+}
+
+func (m *MockRepository) GetActiveTeacher(ctx context.Context, uuid string) (*reference.Teacher, error) {
+	args := m.Called(ctx, uuid)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*reference.Teacher), args.Error(1)
 }
 
 func (m *MockRepository) WithDB(db *gorm.DB) Repository {
@@ -62,10 +107,14 @@ func (m *MockRepository) WithDB(db *gorm.DB) Repository {
 }
 
 // Helper function to create a valid student
+// fullAdmin is a viewer that may manage every student
+var fullAdmin = access.Viewer{UserID: "admin-1", Role: crypto.RoleAdmin, AdminScope: crypto.AdminScopeFull}
+
 func createValidStudent() *Student {
 	teacherID := "550e8400-e29b-41d4-a716-446655440001"
 	return &Student{
 		UUID:      "550e8400-e29b-41d4-a716-446655440000",
+		Version:   3,
 		FirstName: "Max",
 		LastName:  "Mustermann",
 		Email:     "max@example.com",
@@ -105,10 +154,25 @@ func TestCreate(t *testing.T) {
 				TeacherID: "550e8400-e29b-41d4-a716-446655440001",
 			},
 			setupMock: func(m *MockRepository) {
+				m.On("GetActiveTeacher", mock.Anything, "550e8400-e29b-41d4-a716-446655440001").
+					Return(&reference.Teacher{UUID: "550e8400-e29b-41d4-a716-446655440001"}, nil)
 				m.On("Create", mock.Anything, mock.Anything).Return(nil)
 			},
 			expectError: false,
 		},
+		{
+			name: "validation error - unknown or deleted teacher",
+			request: &CreateStudentRequest{
+				FirstName: "Lisa",
+				LastName:  "Schmidt",
+				Email:     "lisa@example.com",
+				TeacherID: "550e8400-e29b-41d4-a716-446655440009",
+			},
+			setupMock: func(m *MockRepository) {
+				m.On("GetActiveTeacher", mock.Anything, "550e8400-e29b-41d4-a716-446655440009").Return(nil, nil)
+			},
+			expectError: true,
+		},
 		{
 			name: "validation error - missing first name",
 			request: &CreateStudentRequest{
@@ -185,7 +249,7 @@ func TestCreate(t *testing.T) {
 			service := NewService(mockRepo)
 
 			// Execute
-			student, err := service.Create(context.Background(), tt.request)
+			student, err := service.Create(context.Background(), tt.request, fullAdmin)
 
 			// Assert
 			if tt.expectError {
@@ -269,6 +333,151 @@ func TestGetByUUID(t *testing.T) {
 	}
 }
 
+// TestGetForViewer tests which students a viewer may read
+func TestGetForViewer(t *testing.T) {
+	const studentUUID = "550e8400-e29b-41d4-a716-446655440000"
+	const teacherUUID = "550e8400-e29b-41d4-a716-446655440001"
+
+	tests := []struct {
+		name    string
+		viewer  access.Viewer
+		allowed bool
+	}{
+		{
+			name:    "admin reads any student",
+			viewer:  access.Viewer{UserID: "admin-1", Role: crypto.RoleAdmin, AdminScope: crypto.AdminScopeAuditor},
+			allowed: true,
+		},
+		{
+			name:    "student reads own record",
+			viewer:  access.Viewer{UserID: "user-1", Role: crypto.RoleStudent, UserUUID: studentUUID},
+			allowed: true,
+		},
+		{
+			name:   "student can't read another student",
+			viewer: access.Viewer{UserID: "user-2", Role: crypto.RoleStudent, UserUUID: "other-student"},
+		},
+		{
+			name:    "teacher reads assigned student",
+			viewer:  access.Viewer{UserID: "user-3", Role: crypto.RoleTeacher, UserUUID: teacherUUID},
+			allowed: true,
+		},
+		{
+			name:   "teacher can't read students of other teachers",
+			viewer: access.Viewer{UserID: "user-4", Role: crypto.RoleTeacher, UserUUID: "other-teacher"},
+		},
+		{
+			name:   "teacher id is not the account id",
+			viewer: access.Viewer{UserID: teacherUUID, Role: crypto.RoleTeacher},
+		},
+		{
+			name:    "department admin reads students of the department's teachers",
+			viewer:  access.Viewer{UserID: "admin-2", Role: crypto.RoleAdmin, AdminScope: crypto.AdminScopeDepartment, Department: "it"},
+			allowed: true,
+		},
+		{
+			name:   "department admin can't read students of other departments",
+			viewer: access.Viewer{UserID: "admin-3", Role: crypto.RoleAdmin, AdminScope: crypto.AdminScopeDepartment, Department: "HR"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			mockRepo.On("GetByUUID", mock.Anything, studentUUID).Return(createValidStudent(), nil)
+			mockRepo.On("GetActiveTeacher", mock.Anything, teacherUUID).
+				Return(&reference.Teacher{UUID: teacherUUID, Department: "IT"}, nil).Maybe()
+			service := NewService(mockRepo)
+
+			student, err := service.GetForViewer(context.Background(), studentUUID, tt.viewer)
+
+			if tt.allowed {
+				assert.NoError(t, err)
+				assert.Equal(t, studentUUID, student.UUID)
+			} else {
+				var appErr *apperrors.AppError
+				assert.ErrorAs(t, err, &appErr)
+				assert.Equal(t, 403, appErr.Code)
+				assert.Nil(t, student)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+// TestDepartmentAdmin tests that department admins only manage students of their department's teachers
+func TestDepartmentAdmin(t *testing.T) {
+	ctx := context.Background()
+	const (
+		itTeacher = "550e8400-e29b-41d4-a716-446655440901"
+		hrTeacher = "550e8400-e29b-41d4-a716-446655440902"
+		itStudent = "550e8400-e29b-41d4-a716-446655440911"
+		hrStudent = "550e8400-e29b-41d4-a716-446655440912"
+	)
+	viewer := access.Viewer{UserID: "admin-2", Role: crypto.RoleAdmin, AdminScope: crypto.AdminScopeDepartment, Department: "IT"}
+
+	setup := func(t *testing.T) (*Service, *InMemoryRepository) {
+		repo := NewInMemoryRepository()
+		repo.AddTeacher(&reference.Teacher{UUID: itTeacher, Department: "IT"})
+		repo.AddTeacher(&reference.Teacher{UUID: hrTeacher, Department: "HR"})
+		for uuid, teacherID := range map[string]string{itStudent: itTeacher, hrStudent: hrTeacher} {
+			require.NoError(t, repo.Create(ctx, &Student{
+				UUID: uuid, FirstName: "Max", LastName: "Mustermann", Email: uuid + "@example.com", TeacherID: &teacherID,
+			}))
+		}
+		return NewService(repo), repo
+	}
+	requireForbidden := func(t *testing.T, err error) {
+		var appErr *apperrors.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, 403, appErr.Code)
+	}
+
+	t.Run("lists only the department's students", func(t *testing.T) {
+		service, _ := setup(t)
+
+		students, info, err := service.ListPaginated(ctx, pagination.Params{Page: 1, Limit: 10}, ListFilter{}, viewer)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), info.TotalCount)
+		require.Len(t, students, 1)
+		assert.Equal(t, itStudent, students[0].UUID)
+
+		students, _, err = service.ListPaginated(ctx, pagination.Params{Page: 1, Limit: 10}, ListFilter{}, access.Viewer{Role: crypto.RoleAdmin, AdminScope: crypto.AdminScopeDepartment})
+		require.NoError(t, err)
+		assert.Empty(t, students, "a department admin without department sees no students")
+	})
+
+	t.Run("can't write students of other departments", func(t *testing.T) {
+		service, repo := setup(t)
+
+		_, err := service.Update(ctx, hrStudent, conditional.Any, &UpdateStudentRequest{FirstName: "Moritz"}, viewer)
+		requireForbidden(t, err)
+		_, err = service.Patch(ctx, hrStudent, conditional.Any, &PatchStudentRequest{FirstName: patch.Value("Moritz")}, viewer)
+		requireForbidden(t, err)
+		requireForbidden(t, service.Delete(ctx, hrStudent, conditional.Any, viewer))
+
+		student, err := repo.GetByUUID(ctx, hrStudent)
+		require.NoError(t, err)
+		assert.Equal(t, "Max", student.FirstName)
+	})
+
+	t.Run("can't move students out of the department", func(t *testing.T) {
+		service, _ := setup(t)
+		hr := hrTeacher
+
+		_, err := service.Update(ctx, itStudent, conditional.Any, &UpdateStudentRequest{TeacherID: &hr}, viewer)
+		requireForbidden(t, err)
+		_, err = service.Patch(ctx, itStudent, conditional.Any, &PatchStudentRequest{TeacherID: patch.Null[string]()}, viewer)
+		requireForbidden(t, err)
+		_, err = service.Create(ctx, &CreateStudentRequest{FirstName: "Anna", LastName: "Schmidt", Email: "anna@example.com", TeacherID: hrTeacher}, viewer)
+		requireForbidden(t, err)
+
+		student, err := service.Update(ctx, itStudent, conditional.Any, &UpdateStudentRequest{FirstName: "Moritz"}, viewer)
+		require.NoError(t, err)
+		assert.Equal(t, "Moritz", student.FirstName)
+	})
+}
+
 // TestUpdate tests the Update method
 func TestUpdate(t *testing.T) {
 	tests := []struct {
@@ -287,6 +496,21 @@ func TestUpdate(t *testing.T) {
 				Email:     "moritz@example.com",
 				TeacherID: stringPtr("550e8400-e29b-41d4-a716-446655440002"),
 			},
+			setupMock: func(m *MockRepository) {
+				student := createValidStudent()
+				m.On("GetByUUID", mock.Anything, "550e8400-e29b-41d4-a716-446655440000").Return(student, nil)
+				m.On("GetActiveTeacher", mock.Anything, "550e8400-e29b-41d4-a716-446655440002").
+					Return(&reference.Teacher{UUID: "550e8400-e29b-41d4-a716-446655440002"}, nil)
+				m.On("Update", mock.Anything, mock.Anything).Return(nil)
+			},
+			expectError: false,
+		},
+		{
+			name: "unchanged teacher is not checked again",
+			uuid: "550e8400-e29b-41d4-a716-446655440000",
+			request: &UpdateStudentRequest{
+				TeacherID: stringPtr("550e8400-e29b-41d4-a716-446655440001"),
+			},
 			setupMock: func(m *MockRepository) {
 				student := createValidStudent()
 				m.On("GetByUUID", mock.Anything, "550e8400-e29b-41d4-a716-446655440000").Return(student, nil)
@@ -294,6 +518,19 @@ func TestUpdate(t *testing.T) {
 			},
 			expectError: false,
 		},
+		{
+			name: "validation error - reassign to deleted teacher",
+			uuid: "550e8400-e29b-41d4-a716-446655440000",
+			request: &UpdateStudentRequest{
+				TeacherID: stringPtr("550e8400-e29b-41d4-a716-446655440009"),
+			},
+			setupMock: func(m *MockRepository) {
+				student := createValidStudent()
+				m.On("GetByUUID", mock.Anything, "550e8400-e29b-41d4-a716-446655440000").Return(student, nil)
+				m.On("GetActiveTeacher", mock.Anything, "550e8400-e29b-41d4-a716-446655440009").Return(nil, nil)
+			},
+			expectError: true,
+		},
 		{
 			name: "successful update - partial fields",
 			uuid: "550e8400-e29b-41d4-a716-446655440000",
@@ -361,7 +598,7 @@ func TestUpdate(t *testing.T) {
 			service := NewService(mockRepo)
 
 			// Execute
-			student, err := service.Update(context.Background(), tt.uuid, tt.request)
+			student, err := service.Update(context.Background(), tt.uuid, conditional.Any, tt.request, fullAdmin)
 
 			// Assert
 			if tt.expectError {
@@ -386,36 +623,195 @@ func TestUpdate(t *testing.T) {
 	}
 }
 
+// TestUpdate_IfMatch tests that updates based on another version of the student are rejected
+func TestUpdate_IfMatch(t *testing.T) {
+	ctx := context.Background()
+	request := &UpdateStudentRequest{FirstName: "Moritz"}
+
+	t.Run("matching version", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetByUUID", ctx, "550e8400-e29b-41d4-a716-446655440000").Return(createValidStudent(), nil)
+		mockRepo.On("Update", ctx, mock.Anything).Return(nil)
+
+		_, err := NewService(mockRepo).Update(ctx, "550e8400-e29b-41d4-a716-446655440000", conditional.Version(3), request, fullAdmin)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("stale version", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetByUUID", ctx, "550e8400-e29b-41d4-a716-446655440000").Return(createValidStudent(), nil)
+
+		_, err := NewService(mockRepo).Update(ctx, "550e8400-e29b-41d4-a716-446655440000", conditional.Version(2), request, fullAdmin)
+
+		assertAppErrorCode(t, err, 412)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+}
+
+// TestPatch tests applying merge patches to a student
+func TestPatch(t *testing.T) {
+	const studentUUID = "550e8400-e29b-41d4-a716-446655440000"
+	tests := []struct {
+		name        string
+		ifMatch     conditional.Precondition
+		request     *PatchStudentRequest
+		setupMock   func(*MockRepository)
+		wantColumns []string // nil if nothing is written
+		errorCode   int
+		check       func(*testing.T, *Student)
+	}{
+		{
+			name:        "null unassigns the teacher",
+			ifMatch:     conditional.Version(3),
+			request:     &PatchStudentRequest{TeacherID: patch.Null[string]()},
+			wantColumns: []string{"teacher_id"},
+			check: func(t *testing.T, student *Student) {
+				assert.Nil(t, student.TeacherID)
+				assert.Equal(t, "Max", student.FirstName)
+			},
+		},
+		{
+			name:    "new teacher is checked",
+			ifMatch: conditional.Any,
+			request: &PatchStudentRequest{
+				FirstName: patch.Value("Moritz"),
+				TeacherID: patch.Value("550e8400-e29b-41d4-a716-446655440002"),
+			},
+			setupMock: func(m *MockRepository) {
+				m.On("GetActiveTeacher", mock.Anything, "550e8400-e29b-41d4-a716-446655440002").
+					Return(&reference.Teacher{UUID: "550e8400-e29b-41d4-a716-446655440002"}, nil)
+			},
+			wantColumns: []string{"first_name", "teacher_id"},
+			check: func(t *testing.T, student *Student) {
+				assert.Equal(t, "Moritz", student.FirstName)
+				assert.Equal(t, "550e8400-e29b-41d4-a716-446655440002", *student.TeacherID)
+			},
+		},
+		{
+			name:    "unchanged values aren't written",
+			ifMatch: conditional.Any,
+			request: &PatchStudentRequest{
+				FirstName: patch.Value("Max"),
+				TeacherID: patch.Value("550e8400-e29b-41d4-a716-446655440001"),
+			},
+			check: func(t *testing.T, student *Student) {
+				assert.Equal(t, 3, student.Version)
+			},
+		},
+		{
+			name:      "null for a required field",
+			ifMatch:   conditional.Any,
+			request:   &PatchStudentRequest{Email: patch.Null[string]()},
+			errorCode: 422,
+		},
+		{
+			name:      "invalid merged email",
+			ifMatch:   conditional.Any,
+			request:   &PatchStudentRequest{Email: patch.Value("invalid-email")},
+			errorCode: 422,
+		},
+		{
+			name:      "empty teacher",
+			ifMatch:   conditional.Any,
+			request:   &PatchStudentRequest{TeacherID: patch.Value("")},
+			errorCode: 422,
+		},
+		{
+			name:    "deleted teacher",
+			ifMatch: conditional.Any,
+			request: &PatchStudentRequest{TeacherID: patch.Value("550e8400-e29b-41d4-a716-446655440009")},
+			setupMock: func(m *MockRepository) {
+				m.On("GetActiveTeacher", mock.Anything, "550e8400-e29b-41d4-a716-446655440009").Return(nil, nil)
+			},
+			errorCode: 422,
+		},
+		{
+			name:      "stale version",
+			ifMatch:   conditional.Version(2),
+			request:   &PatchStudentRequest{FirstName: patch.Value("Moritz")},
+			errorCode: 412,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			loaded := createValidStudent()
+			mockRepo.On("GetByUUID", mock.Anything, studentUUID).Return(loaded, nil)
+			if tt.setupMock != nil {
+				tt.setupMock(mockRepo)
+			}
+			if tt.wantColumns != nil {
+				mockRepo.On("Patch", mock.Anything, mock.Anything, tt.wantColumns).Return(nil)
+			}
+
+			student, err := NewService(mockRepo).Patch(context.Background(), studentUUID, tt.ifMatch, tt.request, fullAdmin)
+
+			if tt.errorCode != 0 {
+				assertAppErrorCode(t, err, tt.errorCode)
+				assert.Equal(t, createValidStudent().Email, loaded.Email, "a rejected patch leaves the student untouched")
+			} else {
+				require.NoError(t, err)
+				tt.check(t, student)
+			}
+			if tt.wantColumns == nil {
+				mockRepo.AssertNotCalled(t, "Patch", mock.Anything, mock.Anything, mock.Anything)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
 // TestDelete tests the Delete method
 func TestDelete(t *testing.T) {
 	tests := []struct {
 		name        string
 		uuid        string
+		ifMatch     conditional.Precondition
 		setupMock   func(*MockRepository)
 		expectError bool
+		errorCode   int
 	}{
 		{
-			name: "successful delete",
-			uuid: "550e8400-e29b-41d4-a716-446655440000",
+			name:    "successful delete",
+			uuid:    "550e8400-e29b-41d4-a716-446655440000",
+			ifMatch: conditional.Version(1),
 			setupMock: func(m *MockRepository) {
-				m.On("Delete", mock.Anything, "550e8400-e29b-41d4-a716-446655440000").Return(nil)
+				m.On("GetByUUID", mock.Anything, "550e8400-e29b-41d4-a716-446655440000").Return(&Student{UUID: "550e8400-e29b-41d4-a716-446655440000", Version: 1}, nil)
+				m.On("Delete", mock.Anything, "550e8400-e29b-41d4-a716-446655440000", 1).Return(nil)
 			},
 			expectError: false,
 		},
 		{
-			name: "student not found",
-			uuid: "nonexistent-uuid",
+			name:    "student not found",
+			uuid:    "nonexistent-uuid",
+			ifMatch: conditional.Any,
 			setupMock: func(m *MockRepository) {
-				m.On("Delete", mock.Anything, "nonexistent-uuid").
-					Return(apperrors.NotFound("student not found"))
+				m.On("GetByUUID", mock.Anything, "nonexistent-uuid").
+					Return(nil, apperrors.NotFound("student not found"))
 			},
 			expectError: true,
+			errorCode:   404,
 		},
 		{
-			name: "repository error",
-			uuid: "550e8400-e29b-41d4-a716-446655440000",
+			name:    "version mismatch",
+			uuid:    "550e8400-e29b-41d4-a716-446655440000",
+			ifMatch: conditional.Version(1),
+			setupMock: func(m *MockRepository) {
+				m.On("GetByUUID", mock.Anything, "550e8400-e29b-41d4-a716-446655440000").Return(&Student{UUID: "550e8400-e29b-41d4-a716-446655440000", Version: 2}, nil)
+			},
+			expectError: true,
+			errorCode:   412,
+		},
+		{
+			name:    "repository error",
+			uuid:    "550e8400-e29b-41d4-a716-446655440000",
+			ifMatch: conditional.Any,
 			setupMock: func(m *MockRepository) {
-				m.On("Delete", mock.Anything, "550e8400-e29b-41d4-a716-446655440000").
+				m.On("GetByUUID", mock.Anything, "550e8400-e29b-41d4-a716-446655440000").Return(&Student{UUID: "550e8400-e29b-41d4-a716-446655440000", Version: 3}, nil)
+				m.On("Delete", mock.Anything, "550e8400-e29b-41d4-a716-446655440000", 3).
 					Return(errors.New("database error"))
 			},
 			expectError: true,
@@ -430,11 +826,14 @@ func TestDelete(t *testing.T) {
 			service := NewService(mockRepo)
 
 			// Execute
-			err := service.Delete(context.Background(), tt.uuid)
+			err := service.Delete(context.Background(), tt.uuid, tt.ifMatch, fullAdmin)
 
 			// Assert
 			if tt.expectError {
 				assert.Error(t, err)
+				if tt.errorCode != 0 {
+					assertAppErrorCode(t, err, tt.errorCode)
+				}
 			} else {
 				assert.NoError(t, err)
 			}
@@ -514,6 +913,93 @@ func TestList(t *testing.T) {
 	}
 }
 
+// TestListPaginated tests that lists are scoped to the students the viewer may see
+func TestListPaginated(t *testing.T) {
+	params := pagination.Params{Page: 1, Limit: 20}
+
+	tests := []struct {
+		name      string
+		viewer    access.Viewer
+		wantScope ListScope
+	}{
+		{
+			name:      "admin lists every student",
+			viewer:    access.Viewer{UserID: "admin-1", Role: crypto.RoleAdmin, AdminScope: crypto.AdminScopeFull},
+			wantScope: ListScope{All: true},
+		},
+		{
+			name:      "teacher lists assigned students",
+			viewer:    access.Viewer{UserID: "user-1", Role: crypto.RoleTeacher, UserUUID: "teacher-1"},
+			wantScope: ListScope{TeacherID: "teacher-1"},
+		},
+		{
+			name:      "student lists own record",
+			viewer:    access.Viewer{UserID: "user-2", Role: crypto.RoleStudent, UserUUID: "student-1"},
+			wantScope: ListScope{UUID: "student-1"},
+		},
+		{
+			name:      "account without linked record lists nothing",
+			viewer:    access.Viewer{UserID: "user-3", Role: crypto.RoleTeacher},
+			wantScope: ListScope{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			mockRepo.On("ListPaginated", mock.Anything, params, tt.wantScope, ListFilter{}).Return([]*Student{}, pagination.PageInfo{}, nil)
+			service := NewService(mockRepo)
+
+			_, _, err := service.ListPaginated(context.Background(), params, ListFilter{}, tt.viewer)
+
+			assert.NoError(t, err)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+
+	t.Run("rejects invalid filters", func(t *testing.T) {
+		service := NewService(new(MockRepository))
+		viewer := access.Viewer{UserID: "admin-1", Role: crypto.RoleAdmin}
+		from := time.Date(2025, 9, 30, 0, 0, 0, 0, time.UTC)
+		to := from.AddDate(0, 0, -1)
+
+		for _, filter := range []ListFilter{
+			{TeacherID: "not-a-uuid"},
+			{CreatedFrom: &from, CreatedTo: &to},
+		} {
+			_, _, err := service.ListPaginated(context.Background(), params, filter, viewer)
+
+			var appErr *apperrors.AppError
+			assert.ErrorAs(t, err, &appErr)
+			assert.Equal(t, 422, appErr.Code)
+		}
+	})
+
+	t.Run("keyset pagination requires created_at sort", func(t *testing.T) {
+		service := NewService(new(MockRepository))
+		viewer := access.Viewer{UserID: "admin-1", Role: crypto.RoleAdmin}
+		filter := ListFilter{Sort: []pagination.SortField{{Field: "last_name"}}}
+
+		_, _, err := service.ListPaginated(context.Background(), pagination.Params{Limit: 20, Keyset: true}, filter, viewer)
+
+		var appErr *apperrors.AppError
+		assert.ErrorAs(t, err, &appErr)
+		assert.Equal(t, 422, appErr.Code)
+	})
+
+	t.Run("anonymous caller", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo)
+
+		_, _, err := service.ListPaginated(context.Background(), params, ListFilter{}, access.Viewer{})
+
+		var appErr *apperrors.AppError
+		assert.ErrorAs(t, err, &appErr)
+		assert.Equal(t, 401, appErr.Code)
+		mockRepo.AssertNotCalled(t, "ListPaginated", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
 // Helper function to create a string pointer
 func stringPtr(s string) *string {
 	return &s
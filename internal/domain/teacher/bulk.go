@@ -0,0 +1,188 @@
+package teacher
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"iter"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/access"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/bulk"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/pagination"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/validation"
+	"github.com/JustDoItBetter/FITS-backend/pkg/logger"
+)
+
+// ExportColumns are the columns of teacher exports
+var ExportColumns = []string{"uuid", "first_name", "last_name", "email", "department", "created_at", "updated_at"}
+
+// ImportFields are the columns read by teacher imports, other columns (e.g. of an export) are ignored
+var ImportFields = []string{"first_name", "last_name", "email", "department"}
+
+// ReadImport reads the rows of a teacher import file, renaming columns with mapping
+// CSV and XLSX files must have all ImportFields as columns
+func ReadImport(filename string, data []byte, mapping map[string]string) ([]bulk.Row, error) {
+	return bulk.Read(filename, data, bulk.Options{Fields: ImportFields, Required: ImportFields, Mapping: mapping})
+}
+
+// Import validates every row and upserts the teachers by email
+// Rows with a new email create a teacher, the others update the teacher, empty cells keep its values.
+// Nothing is written if any row is invalid or dryRun is set; otherwise all rows are written in one transaction.
+// Department admins may only import teachers of their department
+func (s *Service) Import(ctx context.Context, rows []bulk.Row, dryRun bool, viewer access.Viewer) (*bulk.Report, error) {
+	report := bulk.NewReport(rows, dryRun)
+
+	// Resolve existing teachers with one query instead of one per row
+	emails := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if email := validation.SanitizeEmail(row.Get("email")); email != "" {
+			emails = append(emails, email)
+		}
+	}
+	existing, err := s.repo.GetByEmails(ctx, emails)
+	if err != nil {
+		return nil, err
+	}
+
+	teachers := make([]*Teacher, len(rows))
+	seen := make(map[string]int)
+	for i, row := range rows {
+		report.Rows[i].Email = row.Get("email")
+
+		teacher, action, rowErrors := s.importRow(row, existing, viewer)
+		if email := validation.SanitizeEmail(row.Get("email")); email != "" {
+			report.Rows[i].Email = email
+			if line, ok := seen[email]; ok {
+				rowErrors = append(rowErrors, fmt.Sprintf("email is already listed in row %d", line))
+			} else {
+				seen[email] = row.Line
+			}
+		}
+
+		if len(rowErrors) > 0 {
+			report.Fail(i, rowErrors)
+			continue
+		}
+		teachers[i] = teacher
+		report.Accept(i, action, teacher.UUID)
+	}
+
+	if dryRun || report.Failed > 0 {
+		return report, nil
+	}
+
+	write := func(repo Repository) error {
+		for i, teacher := range teachers {
+			var err error
+			switch report.Rows[i].Action {
+			case bulk.ActionCreate:
+				err = repo.Create(ctx, teacher)
+			case bulk.ActionUpdate:
+				err = repo.Update(ctx, teacher)
+			}
+			if err != nil {
+				return bulk.RowError(rows[i].Line, err)
+			}
+		}
+		return nil
+	}
+	if s.txMgr != nil {
+		err = s.txMgr.WithTransaction(ctx, func(tx *gorm.DB) error {
+			return write(s.repo.WithDB(tx))
+		})
+	} else {
+		err = write(s.repo)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	report.Imported()
+	logger.Info("Teachers imported",
+		zap.Int("created", report.Created),
+		zap.Int("updated", report.Updated),
+		zap.Int("unchanged", report.Unchanged),
+	)
+	return report, nil
+}
+
+// importRow validates a row with the rules of the create or update request
+// Returns the teacher to create or the updated teacher, the action and all problems found in the row
+func (s *Service) importRow(row bulk.Row, existing map[string]*Teacher, viewer access.Viewer) (*Teacher, string, []string) {
+	current := existing[validation.SanitizeEmail(row.Get("email"))]
+	if current == nil {
+		req := &CreateTeacherRequest{
+			FirstName:  row.Get("first_name"),
+			LastName:   row.Get("last_name"),
+			Email:      row.Get("email"),
+			Department: row.Get("department"),
+		}
+		if err := s.validate.Struct(req); err != nil {
+			return nil, "", bulk.FieldErrors(err)
+		}
+		teacher := req.ToTeacher()
+		if err := checkDepartment(teacher.Department, viewer); err != nil {
+			return nil, "", bulk.FieldErrors(err)
+		}
+		return teacher, bulk.ActionCreate, nil
+	}
+	if err := checkDepartment(current.Department, viewer); err != nil {
+		return nil, "", bulk.FieldErrors(err)
+	}
+
+	req := &UpdateTeacherRequest{
+		FirstName:  row.Get("first_name"),
+		LastName:   row.Get("last_name"),
+		Department: row.Get("department"),
+	}
+	if err := s.validate.Struct(req); err != nil {
+		return nil, "", bulk.FieldErrors(err)
+	}
+
+	teacher := *current
+	teacher.Update(req)
+	if err := checkDepartment(teacher.Department, viewer); err != nil {
+		return nil, "", bulk.FieldErrors(err)
+	}
+	if teacher.FirstName == current.FirstName && teacher.LastName == current.LastName && teacher.Department == current.Department {
+		return current, bulk.ActionNone, nil
+	}
+	return &teacher, bulk.ActionUpdate, nil
+}
+
+// Export returns the teachers the viewer may see matching the filter, for an export file
+// The filter is checked right away, the teachers are read in batches while iterating
+func (s *Service) Export(ctx context.Context, filter ListFilter, viewer access.Viewer) (iter.Seq2[*Teacher, error], error) {
+	scope, filter, err := listQuery(filter, viewer)
+	if err != nil {
+		return nil, err
+	}
+
+	return bulk.Records(ctx, filter.Sort, func(ctx context.Context, params pagination.Params) ([]*Teacher, pagination.PageInfo, error) {
+		return s.repo.ListPaginated(ctx, params, scope, filter)
+	}), nil
+}
+
+// WriteExport writes exported teachers to w as file of the format
+func WriteExport(w io.Writer, format bulk.Format, teachers iter.Seq2[*Teacher, error]) error {
+	writer, err := bulk.NewWriter(format, w, ExportColumns)
+	if err != nil {
+		return err
+	}
+
+	for teacher, err := range teachers {
+		if err != nil {
+			writer.Close()
+			return err
+		}
+		if err := writer.Write(teacher.UUID, teacher.FirstName, teacher.LastName, teacher.Email,
+			teacher.Department, teacher.CreatedAt, teacher.UpdatedAt); err != nil {
+			writer.Close()
+			return err
+		}
+	}
+	return writer.Close()
+}
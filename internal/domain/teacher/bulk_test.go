@@ -0,0 +1,84 @@
+package teacher
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/access"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/bulk"
+	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
+)
+
+// TestImport tests validation and upsert by email of teacher imports
+func TestImport(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryRepository()
+	require.NoError(t, repo.Create(ctx, &Teacher{
+		UUID: "550e8400-e29b-41d4-a716-446655440950", FirstName: "Anna", LastName: "Schmidt", Email: "anna@example.com", Department: "IT",
+	}))
+	service := NewService(repo)
+
+	t.Run("requires all columns", func(t *testing.T) {
+		_, err := ReadImport("teachers.csv", []byte("first_name,last_name,email\nTom,Becker,tom@example.com\n"), nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("reports invalid rows", func(t *testing.T) {
+		rows, err := ReadImport("teachers.csv", []byte("first_name,last_name,email,department\nTom,Becker,tom@example.com,\n"), nil)
+		require.NoError(t, err)
+
+		report, err := service.Import(ctx, rows, false, fullAdmin)
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Failed)
+		assert.Equal(t, []string{"department is required"}, report.Rows[0].Errors)
+	})
+
+	t.Run("upserts by email", func(t *testing.T) {
+		data := `{"first_name": "Tom", "last_name": "Becker", "email": "tom@example.com", "department": "HR"}` + "\n" +
+			`{"email": "ANNA@example.com", "department": "Mathematics"}`
+		rows, err := ReadImport("teachers.jsonl", []byte(data), nil)
+		require.NoError(t, err)
+
+		report, err := service.Import(ctx, rows, false, fullAdmin)
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Created)
+		assert.Equal(t, 1, report.Updated)
+
+		anna, err := repo.GetByUUID(ctx, "550e8400-e29b-41d4-a716-446655440950")
+		require.NoError(t, err)
+		assert.Equal(t, "Schmidt", anna.LastName)
+		assert.Equal(t, "Mathematics", anna.Department)
+	})
+
+	t.Run("department admins import their department", func(t *testing.T) {
+		data := "first_name,last_name,email,department\n" +
+			"Lena,Vogel,lena@example.com,Mathematics\n" + // New in the department
+			"Jan,Wolf,jan@example.com,HR\n" + // New in another department
+			"Tom,Becker,tom@example.com,Mathematics\n" // Moved from another department
+		rows, err := ReadImport("teachers.csv", []byte(data), nil)
+		require.NoError(t, err)
+
+		viewer := access.Viewer{UserID: "admin-2", Role: crypto.RoleAdmin, AdminScope: crypto.AdminScopeDepartment, Department: "Mathematics"}
+		report, err := service.Import(ctx, rows, true, viewer)
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Valid)
+		assert.Empty(t, report.Rows[0].Errors)
+		assert.Equal(t, []string{"department admins can only access records of their department"}, report.Rows[1].Errors)
+		assert.Equal(t, []string{"department admins can only access records of their department"}, report.Rows[2].Errors)
+	})
+
+	t.Run("export", func(t *testing.T) {
+		teachers, err := service.Export(ctx, ListFilter{Department: "hr"}, access.Viewer{UserID: "admin-1", Role: crypto.RoleAdmin})
+		require.NoError(t, err)
+
+		var buf strings.Builder
+		require.NoError(t, WriteExport(&buf, bulk.FormatCSV, teachers))
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		require.Len(t, lines, 2)
+		assert.Contains(t, lines[1], ",Tom,Becker,tom@example.com,HR,")
+	})
+}
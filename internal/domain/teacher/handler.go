@@ -1,52 +1,103 @@
 package teacher
 
 import (
+	"bufio"
+	"context"
+	"io"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/access"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/bulk"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/conditional"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/errors"
 	"github.com/JustDoItBetter/FITS-backend/internal/common/pagination"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/patch"
 	"github.com/JustDoItBetter/FITS-backend/internal/common/response"
+	"github.com/JustDoItBetter/FITS-backend/pkg/logger"
 	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
 )
 
 type Handler struct {
 	service *Service
+	cursors *pagination.CursorCodec
 }
 
-func NewHandler(service *Service) *Handler {
-	return &Handler{service: service}
+func NewHandler(service *Service, cursors *pagination.CursorCodec) *Handler {
+	return &Handler{service: service, cursors: cursors}
 }
 
 // RegisterRoutes registers all teacher endpoints with their required middleware
 // This provides a single source of truth for routes and their security requirements
 func (h *Handler) RegisterRoutes(router fiber.Router, jwtMW JWTMiddleware, rbacMW RBACMiddleware) {
-	// POST /api/v1/teacher - Create teacher (Admin only)
+	// POST /api/v1/teacher - Create teacher (requires teacher:write)
 	router.Post("/",
 		jwtMW.RequireAuth(),
-		rbacMW.RequireAdmin(),
+		rbacMW.RequirePermission("teacher:write"),
 		h.Create,
 	)
 
-	// GET /api/v1/teacher/:uuid - Get teacher (optional auth for future filtering)
+	// POST /api/v1/teacher/import - Import teachers from a file (requires teacher:write)
+	router.Post("/import",
+		jwtMW.RequireAuth(),
+		rbacMW.RequirePermission("teacher:write"),
+		h.Import,
+	)
+
+	// GET /api/v1/teacher/export - Export the teachers visible to the caller (requires teacher:read)
+	// Registered before /:uuid, which would match it otherwise
+	router.Get("/export",
+		jwtMW.RequireAuth(),
+		rbacMW.RequirePermission("teacher:read"),
+		h.Export,
+	)
+
+	// GET /api/v1/teacher/trash - List deleted teachers (full admins only)
+	// Registered before /:uuid, which would match it otherwise
+	router.Get("/trash",
+		jwtMW.RequireAuth(),
+		rbacMW.RequireAdmin(),
+		h.ListDeleted,
+	)
+
+	// POST /api/v1/teacher/:uuid/restore - Restore a deleted teacher (full admins only)
+	router.Post("/:uuid/restore",
+		jwtMW.RequireAuth(),
+		rbacMW.RequireAdmin(),
+		h.Restore,
+	)
+
+	// GET /api/v1/teacher/:uuid - Get teacher (requires teacher:read, filtered by the read policy)
 	router.Get("/:uuid",
-		jwtMW.OptionalAuth(),
+		jwtMW.RequireAuth(),
+		rbacMW.RequirePermission("teacher:read"),
 		h.GetByUUID,
 	)
 
-	// PUT /api/v1/teacher/:uuid - Update teacher (Admin only)
+	// PUT /api/v1/teacher/:uuid - Update teacher (requires teacher:write)
 	router.Put("/:uuid",
 		jwtMW.RequireAuth(),
-		rbacMW.RequireAdmin(),
+		rbacMW.RequirePermission("teacher:write"),
 		h.Update,
 	)
 
-	// DELETE /api/v1/teacher/:uuid - Delete teacher (Admin only, soft delete)
+	// PATCH /api/v1/teacher/:uuid - Merge patch teacher (requires teacher:write)
+	router.Patch("/:uuid",
+		jwtMW.RequireAuth(),
+		rbacMW.RequirePermission("teacher:write"),
+		h.Patch,
+	)
+
+	// DELETE /api/v1/teacher/:uuid - Delete teacher (requires teacher:write, soft delete)
 	router.Delete("/:uuid",
 		jwtMW.RequireAuth(),
-		rbacMW.RequireAdmin(),
+		rbacMW.RequirePermission("teacher:write"),
 		h.Delete,
 	)
 
-	// GET /api/v1/teacher - List teachers (optional auth for future filtering)
+	// GET /api/v1/teacher - List teachers visible to the caller (requires teacher:read)
 	router.Get("/",
-		jwtMW.OptionalAuth(),
+		jwtMW.RequireAuth(),
+		rbacMW.RequirePermission("teacher:read"),
 		h.List,
 	)
 }
@@ -54,18 +105,19 @@ func (h *Handler) RegisterRoutes(router fiber.Router, jwtMW JWTMiddleware, rbacM
 // JWTMiddleware interface defines JWT authentication middleware requirements
 type JWTMiddleware interface {
 	RequireAuth() fiber.Handler
-	OptionalAuth() fiber.Handler
 }
 
 // RBACMiddleware interface defines role-based access control middleware
 type RBACMiddleware interface {
 	RequireAdmin() fiber.Handler
-	RequireRole(roles ...interface{}) fiber.Handler
+	// RequirePermission requires a permission granted to the role of the user, e.g. student:write
+	RequirePermission(permission string) fiber.Handler
+	RequireRole(roles ...string) fiber.Handler
 }
 
 // Create godoc
 // @Summary Create a new teacher
-// @Description Creates a new teacher record. Requires admin role. Email must be unique. Department is required.
+// @Description Creates a new teacher record. Requires the teacher:write permission. Email must be unique. Department is required.
 // @Tags Teachers
 // @Accept json
 // @Produce json
@@ -73,7 +125,7 @@ type RBACMiddleware interface {
 // @Success 201 {object} response.SuccessResponse{data=Teacher} "Teacher created successfully"
 // @Failure 400 {object} response.ErrorResponse "Invalid request body"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized - missing or invalid token"
-// @Failure 403 {object} response.ErrorResponse "Forbidden - requires admin role"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - requires the teacher:write permission, or the teacher is of another department"
 // @Failure 409 {object} response.ErrorResponse "Conflict - email already exists"
 // @Failure 422 {object} response.ErrorResponse "Validation error - invalid field values"
 // @Failure 500 {object} response.ErrorResponse "Internal server error"
@@ -85,88 +137,166 @@ func (h *Handler) Create(c *fiber.Ctx) error {
 		return response.Error(c, err)
 	}
 
-	teacher, err := h.service.Create(c.Context(), &req)
+	teacher, err := h.service.Create(c.Context(), &req, access.FromContext(c))
 	if err != nil {
 		return response.Error(c, err)
 	}
 
+	conditional.SetETag(c, teacher.Version)
 	return response.Created(c, teacher)
 }
 
 // GetByUUID godoc
 // @Summary Get teacher by UUID
-// @Description Retrieves detailed information about a specific teacher by their UUID. Public endpoint.
+// @Description Retrieves detailed information about a specific teacher by their UUID. Requires teacher:read permission.
+// @Description Teachers read their own record, students the record of their assigned teacher, department admins the teachers of their department and other admins every record.
 // @Tags Teachers
 // @Produce json
 // @Param uuid path string true "Teacher UUID" format(uuid) example(550e8400-e29b-41d4-a716-446655440010)
-// @Success 200 {object} response.SuccessResponse{data=Teacher} "Teacher found"
+// @Param If-None-Match header string false "ETag of a cached copy, answered with 304 if it is still current"
+// @Success 200 {object} response.SuccessResponse{data=Teacher} "Teacher found, the ETag header carries its version"
+// @Success 304 "The cached copy is current (no content)"
 // @Failure 400 {object} response.ErrorResponse "Invalid UUID format"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - missing or invalid token"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - the teacher isn't visible to the caller"
 // @Failure 404 {object} response.ErrorResponse "Teacher not found"
 // @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
 // @Router /api/v1/teacher/{uuid} [get]
 func (h *Handler) GetByUUID(c *fiber.Ctx) error {
 	uuid := c.Params("uuid")
 
-	teacher, err := h.service.GetByUUID(c.Context(), uuid)
+	teacher, err := h.service.GetForViewer(c.Context(), uuid, access.FromContext(c))
 	if err != nil {
 		return response.Error(c, err)
 	}
 
-	return response.Success(c, teacher)
+	return conditional.Respond(c, teacher.Version, func() error {
+		return response.Success(c, teacher)
+	})
 }
 
 // Update godoc
 // @Summary Update teacher information
-// @Description Updates an existing teacher's information. Requires admin role. Supports partial updates including department changes.
+// @Description Updates an existing teacher's information. Requires the teacher:write permission. Supports partial updates including department changes.
 // @Tags Teachers
 // @Accept json
 // @Produce json
 // @Param uuid path string true "Teacher UUID" format(uuid) example(550e8400-e29b-41d4-a716-446655440010)
+// @Param If-Match header string true "ETag of the teacher the change is based on, or * to overwrite any version"
 // @Param request body UpdateTeacherRequest true "Teacher update request (partial updates supported)"
-// @Success 200 {object} response.SuccessResponse{data=Teacher} "Teacher updated successfully"
+// @Success 200 {object} response.SuccessResponse{data=Teacher} "Teacher updated successfully, the ETag header carries the new version"
 // @Failure 400 {object} response.ErrorResponse "Invalid request body or UUID"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized - missing or invalid token"
-// @Failure 403 {object} response.ErrorResponse "Forbidden - requires admin role"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - requires the teacher:write permission, or the teacher is of another department"
 // @Failure 404 {object} response.ErrorResponse "Teacher not found"
 // @Failure 409 {object} response.ErrorResponse "Conflict - email already exists"
+// @Failure 412 {object} response.ErrorResponse "Precondition failed - the teacher was changed since If-Match was read"
 // @Failure 422 {object} response.ErrorResponse "Validation error - invalid field values"
+// @Failure 428 {object} response.ErrorResponse "Precondition required - missing If-Match header"
 // @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Security BearerAuth
 // @Router /api/v1/teacher/{uuid} [put]
 func (h *Handler) Update(c *fiber.Ctx) error {
 	uuid := c.Params("uuid")
 
+	ifMatch, err := conditional.IfMatch(c)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
 	var req UpdateTeacherRequest
 	if err := c.BodyParser(&req); err != nil {
 		return response.Error(c, err)
 	}
 
-	teacher, err := h.service.Update(c.Context(), uuid, &req)
+	teacher, err := h.service.Update(c.Context(), uuid, ifMatch, &req, access.FromContext(c))
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	conditional.SetETag(c, teacher.Version)
+	return response.Success(c, teacher)
+}
+
+// Patch godoc
+// @Summary Patch teacher information
+// @Description Changes the fields of an existing teacher given in a JSON merge patch (RFC 7396). Requires the teacher:write permission.
+// @Description Left out fields keep their value. No field can be cleared, null is rejected.
+// @Description The merged teacher is validated as a whole and only the changed fields are written.
+// @Tags Teachers
+// @Accept application/merge-patch+json
+// @Accept json
+// @Produce json
+// @Param uuid path string true "Teacher UUID" format(uuid) example(550e8400-e29b-41d4-a716-446655440010)
+// @Param If-Match header string true "ETag of the teacher the change is based on, or * to patch any version"
+// @Param request body PatchTeacherRequest true "Teacher merge patch"
+// @Success 200 {object} response.SuccessResponse{data=Teacher} "Teacher patched successfully, the ETag header carries the new version"
+// @Failure 400 {object} response.ErrorResponse "The body is no JSON object"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - missing or invalid token"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - requires the teacher:write permission, or the teacher is of another department"
+// @Failure 404 {object} response.ErrorResponse "Teacher not found"
+// @Failure 409 {object} response.ErrorResponse "Conflict - email already exists"
+// @Failure 412 {object} response.ErrorResponse "Precondition failed - the teacher was changed since If-Match was read"
+// @Failure 415 {object} response.ErrorResponse "Unsupported media type - send application/merge-patch+json"
+// @Failure 422 {object} response.ErrorResponse "Validation error - invalid merged teacher, null for a required field or a field that can't be patched"
+// @Failure 428 {object} response.ErrorResponse "Precondition required - missing If-Match header"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/v1/teacher/{uuid} [patch]
+func (h *Handler) Patch(c *fiber.Ctx) error {
+	uuid := c.Params("uuid")
+
+	ifMatch, err := conditional.IfMatch(c)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	var req PatchTeacherRequest
+	if err := patch.Decode(c, &req); err != nil {
+		return response.Error(c, err)
+	}
+
+	teacher, err := h.service.Patch(c.Context(), uuid, ifMatch, &req, access.FromContext(c))
 	if err != nil {
 		return response.Error(c, err)
 	}
 
+	conditional.SetETag(c, teacher.Version)
 	return response.Success(c, teacher)
 }
 
 // Delete godoc
 // @Summary Delete a teacher
-// @Description Permanently deletes a teacher from the system (soft delete). Requires admin role.
+// @Description Permanently deletes a teacher from the system (soft delete). Requires the teacher:write permission.
+// @Description The teacher's students are moved to the teacher given in reassign_to. Without reassign_to
+// @Description they are left without teacher and flagged with teacher_removed_at. The teacher's account is disabled.
 // @Tags Teachers
 // @Produce json
 // @Param uuid path string true "Teacher UUID" format(uuid) example(550e8400-e29b-41d4-a716-446655440010)
+// @Param reassign_to query string false "UUID of the teacher that takes over the students" format(uuid)
+// @Param If-Match header string true "ETag of the teacher to delete, or * to delete any version"
 // @Success 204 "Teacher deleted successfully (no content)"
 // @Failure 400 {object} response.ErrorResponse "Invalid UUID format"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized - missing or invalid token"
-// @Failure 403 {object} response.ErrorResponse "Forbidden - requires admin role"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - requires the teacher:write permission, or the teacher is of another department"
 // @Failure 404 {object} response.ErrorResponse "Teacher not found"
+// @Failure 412 {object} response.ErrorResponse "Precondition failed - the teacher was changed since If-Match was read"
+// @Failure 422 {object} response.ErrorResponse "reassign_to is invalid or not an active teacher"
+// @Failure 428 {object} response.ErrorResponse "Precondition required - missing If-Match header"
 // @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Security BearerAuth
 // @Router /api/v1/teacher/{uuid} [delete]
 func (h *Handler) Delete(c *fiber.Ctx) error {
 	uuid := c.Params("uuid")
 
-	if err := h.service.Delete(c.Context(), uuid); err != nil {
+	ifMatch, err := conditional.IfMatch(c)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	opts := DeleteOptions{ReassignTo: c.Query("reassign_to")}
+	if err := h.service.Delete(c.Context(), uuid, ifMatch, opts, access.FromContext(c)); err != nil {
 		return response.Error(c, err)
 	}
 
@@ -175,25 +305,227 @@ func (h *Handler) Delete(c *fiber.Ctx) error {
 
 // List godoc
 // @Summary List teachers with pagination
-// @Description Retrieves a paginated list of teachers. Supports page and limit query parameters.
+// @Description Retrieves a paginated list of teachers. Supports page and limit, search, filter and sort query parameters.
 // @Description Default: page=1, limit=20. Maximum limit is 100 to prevent performance issues.
+// @Description Pass cursor for keyset pagination, which stays fast on deep pages and is stable while records are added. It only supports sorting by created_at.
+// @Description The Link header (RFC 8288) points to the first, prev, next and (offset pagination) last pages.
+// @Description Requires teacher:read permission. Admins see every teacher or, for department admins, those of their department, teachers their own record and students their assigned teacher.
 // @Tags Teachers
 // @Produce json
 // @Param page query int false "Page number (default: 1)" minimum(1)
+// @Param cursor query string false "Keyset pagination: empty for the first page, then next_cursor or prev_cursor of the previous response. Overrides page"
 // @Param limit query int false "Items per page (default: 20, max: 100)" minimum(1) maximum(100)
+// @Param search query string false "Case-insensitive part of the full name or email"
+// @Param department query string false "Only teachers of this department"
+// @Param created_from query string false "Created on or after, date (2025-09-30) or RFC 3339 timestamp"
+// @Param created_to query string false "Created on or before, date (2025-09-30) or RFC 3339 timestamp"
+// @Param sort query string false "Up to 3 comma separated fields of first_name, last_name, email, department, created_at, updated_at, prefix - for descending (default: -created_at)" example(last_name,-created_at)
 // @Success 200 {object} pagination.Response{data=[]Teacher} "Paginated list of teachers with metadata"
+// @Header 200 {string} Link "Links to the neighbouring pages (RFC 8288)"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - missing or invalid token"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - requires teacher:read permission"
+// @Failure 422 {object} response.ErrorResponse "Validation error - invalid filter or sort field"
 // @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
 // @Router /api/v1/teacher [get]
 func (h *Handler) List(c *fiber.Ctx) error {
 	// Extract pagination parameters from query string
-	params := pagination.ExtractParams(c)
+	params, err := pagination.ExtractCursorParams(c, h.cursors)
+	if err != nil {
+		return response.Error(c, err)
+	}
+	filter, err := extractFilter(c)
+	if err != nil {
+		return response.Error(c, err)
+	}
 
-	teachers, totalCount, err := h.service.ListPaginated(c.Context(), params)
+	teachers, info, err := h.service.ListPaginated(c.Context(), params, filter, access.FromContext(c))
 	if err != nil {
 		return response.Error(c, err)
 	}
 
-	// Build paginated response with metadata
-	paginatedResp := pagination.NewResponse(teachers, params, totalCount)
+	// Build paginated response with metadata and links to the neighbouring pages
+	paginatedResp := pagination.NewPageResponse(teachers, params, info, h.cursors)
+	pagination.SetLinkHeader(c, paginatedResp)
 	return c.JSON(paginatedResp)
 }
+
+// ListDeleted godoc
+// @Summary List deleted teachers
+// @Description Retrieves a paginated list of the teachers in the trash, most recently deleted first. Requires a full admin.
+// @Description Deleted teachers can be restored until they are purged after the configured retention time.
+// @Tags Teachers
+// @Produce json
+// @Param page query int false "Page number (default: 1)" minimum(1)
+// @Param limit query int false "Items per page (default: 20, max: 100)" minimum(1) maximum(100)
+// @Success 200 {object} pagination.Response{data=[]Teacher} "Paginated list of deleted teachers"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - missing or invalid token"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - requires a full admin"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/v1/teacher/trash [get]
+func (h *Handler) ListDeleted(c *fiber.Ctx) error {
+	params := pagination.ExtractParams(c)
+
+	teachers, totalCount, err := h.service.ListDeleted(c.Context(), params)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return c.JSON(pagination.NewResponse(teachers, params, totalCount))
+}
+
+// Restore godoc
+// @Summary Restore a deleted teacher
+// @Description Takes a teacher out of the trash. Requires a full admin.
+// @Description Students that were reassigned or unassigned when the teacher was deleted keep their current teacher.
+// @Description The teacher's account stays disabled until an admin enables it.
+// @Tags Teachers
+// @Produce json
+// @Param uuid path string true "Teacher UUID" format(uuid) example(550e8400-e29b-41d4-a716-446655440010)
+// @Success 200 {object} response.SuccessResponse{data=Teacher} "Teacher restored"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - missing or invalid token"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - requires a full admin"
+// @Failure 404 {object} response.ErrorResponse "No deleted teacher with this UUID"
+// @Failure 409 {object} response.ErrorResponse "Conflict - another teacher uses the email"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/v1/teacher/{uuid}/restore [post]
+func (h *Handler) Restore(c *fiber.Ctx) error {
+	teacher, err := h.service.Restore(c.Context(), c.Params("uuid"))
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	conditional.SetETag(c, teacher.Version)
+	return response.Success(c, teacher)
+}
+
+// Import godoc
+// @Summary Import teachers
+// @Description Upload a CSV, XLSX or JSON Lines file with the columns first_name, last_name, email and department. Other columns, e.g. of an export, are ignored.
+// @Description Rows are matched by email: new emails create teachers, known emails update them and empty cells keep the current values.
+// @Description Every row is validated; nothing is written unless all rows are valid, then all rows are written in a single transaction. Requires the teacher:write permission.
+// @Tags Teachers
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Import file (.csv, .xlsx or .jsonl)"
+// @Param mapping formData string false "Column names of the file mapped to fields as JSON object" example({"Vorname": "first_name"})
+// @Param dry_run query bool false "Only validate the file, write nothing"
+// @Success 200 {object} response.SuccessResponse{data=bulk.Report} "Dry run, invalid rows or nothing to change"
+// @Success 201 {object} response.SuccessResponse{data=bulk.Report} "Teachers created or updated"
+// @Failure 400 {object} response.ErrorResponse "Missing, unreadable or empty file, or invalid mapping"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - missing or invalid token"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - requires the teacher:write permission"
+// @Failure 409 {object} response.ErrorResponse "A row conflicts with another teacher"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/v1/teacher/import [post]
+func (h *Handler) Import(c *fiber.Ctx) error {
+	file, err := c.FormFile("file")
+	if err != nil {
+		return response.Error(c, errors.BadRequest("import file is required"))
+	}
+	mapping, err := bulk.ParseMapping(c.FormValue("mapping"), ImportFields)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	// Read file data
+	fileData, err := file.Open()
+	if err != nil {
+		return response.Error(c, err)
+	}
+	defer fileData.Close()
+
+	data, err := io.ReadAll(fileData)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	rows, err := ReadImport(file.Filename, data, mapping)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	report, err := h.service.Import(c.Context(), rows, c.QueryBool("dry_run"), access.FromContext(c))
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	if report.Created+report.Updated == 0 {
+		return response.Success(c, report)
+	}
+	return response.Created(c, report)
+}
+
+// Export godoc
+// @Summary Export teachers
+// @Description Streams the teachers visible to the caller as CSV, XLSX or JSON Lines file, filtered and sorted like the list endpoint.
+// @Description Requires the teacher:read permission.
+// @Tags Teachers
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce application/jsonl
+// @Param format query string false "File format (default: csv)" Enums(csv, xlsx, jsonl)
+// @Param search query string false "Case-insensitive part of the full name or email"
+// @Param department query string false "Only teachers of this department, case-insensitive"
+// @Param created_from query string false "Created on or after, date (2025-09-30) or RFC 3339 timestamp"
+// @Param created_to query string false "Created on or before, date (2025-09-30) or RFC 3339 timestamp"
+// @Param sort query string false "Up to 3 comma separated fields of first_name, last_name, email, department, created_at, updated_at, prefix - for descending (default: -created_at)"
+// @Success 200 {file} file "Export file"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - missing or invalid token"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - requires teacher:read permission"
+// @Failure 422 {object} response.ErrorResponse "Validation error - invalid format, filter or sort field"
+// @Security BearerAuth
+// @Router /api/v1/teacher/export [get]
+func (h *Handler) Export(c *fiber.Ctx) error {
+	format, err := bulk.ParseFormat(c.Query("format"))
+	if err != nil {
+		return response.Error(c, err)
+	}
+	filter, err := extractFilter(c)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	// The body is streamed after the handler returned, so the export can't use the request context
+	teachers, err := h.service.Export(context.Background(), filter, access.FromContext(c))
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	c.Set(fiber.HeaderContentType, format.ContentType())
+	c.Set(fiber.HeaderContentDisposition, "attachment; filename=teachers."+string(format))
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		// The status is already sent, a failed export ends the file early
+		if err := WriteExport(w, format, teachers); err != nil {
+			logger.Error("Teacher export failed", zap.Error(err))
+		}
+	})
+	return nil
+}
+
+// extractFilter reads the search, filter and sort query parameters of the list endpoint
+func extractFilter(c *fiber.Ctx) (ListFilter, error) {
+	sort, err := pagination.ParseSort(c.Query("sort"), SortFields)
+	if err != nil {
+		return ListFilter{}, err
+	}
+	createdFrom, err := pagination.ParseDate("created_from", c.Query("created_from"), false)
+	if err != nil {
+		return ListFilter{}, err
+	}
+	createdTo, err := pagination.ParseDate("created_to", c.Query("created_to"), true)
+	if err != nil {
+		return ListFilter{}, err
+	}
+
+	return ListFilter{
+		Search:      c.Query("search"),
+		Department:  c.Query("department"),
+		CreatedFrom: createdFrom,
+		CreatedTo:   createdTo,
+		Sort:        sort,
+	}, nil
+}
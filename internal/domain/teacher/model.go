@@ -3,6 +3,8 @@ package teacher
 import (
 	"time"
 
+	"github.com/JustDoItBetter/FITS-backend/internal/common/pagination"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/patch"
 	"github.com/JustDoItBetter/FITS-backend/internal/common/validation"
 	"github.com/google/uuid"
 )
@@ -10,13 +12,27 @@ import (
 // Teacher represents a teacher entity
 // @Description Teacher information
 type Teacher struct {
-	UUID       string    `json:"uuid" example:"teacher-uuid-123" validate:"required,uuid"`
-	FirstName  string    `json:"first_name" example:"Anna" validate:"required,min=1,max=100"`
-	LastName   string    `json:"last_name" example:"Schmidt" validate:"required,min=1,max=100"`
-	Email      string    `json:"email" example:"anna@example.com" validate:"required,email"`
-	Department string    `json:"department" example:"Computer Science" validate:"required,min=1,max=100"`
-	CreatedAt  time.Time `json:"created_at" example:"2025-09-30T12:00:00Z"`
-	UpdatedAt  time.Time `json:"updated_at" example:"2025-09-30T12:00:00Z"`
+	UUID       string     `json:"uuid" example:"teacher-uuid-123" validate:"required,uuid"`
+	FirstName  string     `json:"first_name" example:"Anna" validate:"required,min=1,max=100"`
+	LastName   string     `json:"last_name" example:"Schmidt" validate:"required,min=1,max=100"`
+	Email      string     `json:"email" example:"anna@example.com" validate:"required,email"`
+	Department string     `json:"department" example:"Computer Science" validate:"required,min=1,max=100"`
+	CreatedAt  time.Time  `json:"created_at" example:"2025-09-30T12:00:00Z"`
+	UpdatedAt  time.Time  `json:"updated_at" example:"2025-09-30T12:00:00Z"`
+	Version    int        `json:"version" example:"3"`                                 // Incremented by every change, sent as ETag
+	DeletedAt  *time.Time `json:"deleted_at,omitempty" example:"2025-10-01T12:00:00Z"` // Only set for teachers in the trash
+}
+
+// SortFields are the fields teacher lists can be sorted by
+var SortFields = []string{"first_name", "last_name", "email", "department", "created_at", "updated_at"}
+
+// ListFilter narrows teacher lists, set from the query parameters of the list endpoint
+type ListFilter struct {
+	Search      string                 // Case-insensitive part of the full name or email
+	Department  string                 // Teachers of this department, case-insensitive
+	CreatedFrom *time.Time             // Created at or after
+	CreatedTo   *time.Time             // Created at or before
+	Sort        []pagination.SortField // Empty for pagination.DefaultSort
 }
 
 // CreateTeacherRequest represents the request to create a new teacher
@@ -37,6 +53,22 @@ type UpdateTeacherRequest struct {
 	Department string `json:"department,omitempty" example:"Mathematics" validate:"omitempty,min=1,max=100"`
 }
 
+// PatchTeacherRequest is a JSON merge patch (RFC 7396) of a teacher
+// @Description Request body for patching a teacher. Left out fields keep their value, no field can be cleared with null
+type PatchTeacherRequest struct {
+	FirstName  patch.Field[string] `json:"first_name" swaggertype:"string" example:"Maria"`
+	LastName   patch.Field[string] `json:"last_name" swaggertype:"string" example:"Müller"`
+	Email      patch.Field[string] `json:"email" swaggertype:"string" example:"maria@example.com"`
+	Department patch.Field[string] `json:"department" swaggertype:"string" example:"Mathematics"`
+}
+
+// DeleteOptions controls what happens to the students of a deleted teacher
+type DeleteOptions struct {
+	// ReassignTo is the UUID of the teacher that takes over the students
+	// If empty, the students are left without teacher and flagged with teacher_removed_at
+	ReassignTo string
+}
+
 // ToTeacher converts CreateTeacherRequest to Teacher entity
 // UUID is always generated server-side for security
 func (r *CreateTeacherRequest) ToTeacher() *Teacher {
@@ -69,3 +101,34 @@ func (t *Teacher) Update(req *UpdateTeacherRequest) {
 	}
 	t.UpdatedAt = time.Now()
 }
+
+// Patch applies a merge patch to the teacher and returns the columns that changed
+// Sanitizes input like Update. All fields are required, so null is rejected
+func (t *Teacher) Patch(req *PatchTeacherRequest) ([]string, error) {
+	var columns []string
+	fields := []struct {
+		column  string
+		value   patch.Field[string]
+		current *string
+		clean   func(string) string
+	}{
+		{"first_name", req.FirstName, &t.FirstName, validation.SanitizeName},
+		{"last_name", req.LastName, &t.LastName, validation.SanitizeName},
+		{"email", req.Email, &t.Email, validation.SanitizeEmail},
+		{"department", req.Department, &t.Department, validation.SanitizeName},
+	}
+	for _, field := range fields {
+		changed, err := patch.Set(field.column, field.value, field.current, field.clean)
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			columns = append(columns, field.column)
+		}
+	}
+
+	if len(columns) > 0 {
+		t.UpdatedAt = time.Now()
+	}
+	return columns, nil
+}
@@ -2,10 +2,15 @@ package teacher
 
 import (
 	"context"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/JustDoItBetter/FITS-backend/internal/common/errors"
 	"github.com/JustDoItBetter/FITS-backend/internal/common/pagination"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/reference"
 	"gorm.io/gorm"
 )
 
@@ -13,20 +18,51 @@ import (
 type Repository interface {
 	Create(ctx context.Context, teacher *Teacher) error
 	GetByUUID(ctx context.Context, uuid string) (*Teacher, error)
+	// Update saves a changed teacher if nobody changed it since it was loaded and increments its version,
+	// a precondition failed error if the stored version differs from teacher.Version
 	Update(ctx context.Context, teacher *Teacher) error
-	Delete(ctx context.Context, uuid string) error
-	// ListPaginated returns paginated teachers with total count for metadata
-	ListPaginated(ctx context.Context, params pagination.Params) ([]*Teacher, int64, error)
+	// Patch saves only the given columns of a changed teacher, with the version check of Update
+	Patch(ctx context.Context, teacher *Teacher, columns []string) error
+	// Delete soft-deletes a teacher if it still has the given version, a precondition failed error otherwise
+	Delete(ctx context.Context, uuid string, version int) error
+	// ListDeleted returns a page of soft-deleted teachers, most recently deleted first, with total count
+	ListDeleted(ctx context.Context, params pagination.Params) ([]*Teacher, int64, error)
+	// Restore undeletes a soft-deleted teacher, a conflict if another teacher uses its email by now
+	Restore(ctx context.Context, uuid string) error
+	// PurgeDeleted permanently removes the teachers deleted before the given time and returns their number
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+	// ListPaginated returns a page of teachers within scope matching the filter with total count and cursors for metadata
+	ListPaginated(ctx context.Context, params pagination.Params, scope ListScope, filter ListFilter) ([]*Teacher, pagination.PageInfo, error)
 	// List retrieves all teachers (deprecated: use ListPaginated for better performance)
 	List(ctx context.Context) ([]*Teacher, error)
+	// GetByEmails resolves emails (lowercased) to teachers, used to match import rows
+	GetByEmails(ctx context.Context, emails []string) (map[string]*Teacher, error)
+	// GetActiveTeacher looks up a teacher students can be reassigned to, nil if it doesn't exist or was deleted
+	GetActiveTeacher(ctx context.Context, uuid string) (*reference.Teacher, error)
+	// ReassignStudents moves the students of a teacher to another teacher and returns their number
+	ReassignStudents(ctx context.Context, fromUUID, toUUID string) (int64, error)
+	// UnassignStudents removes the teacher from its students, flags them and returns their number
+	UnassignStudents(ctx context.Context, teacherUUID string) (int64, error)
+	// IsAssignedTeacher reports whether the student is assigned to the teacher
+	IsAssignedTeacher(ctx context.Context, studentUUID, teacherUUID string) (bool, error)
 	// WithDB returns a new repository instance using the provided database connection
 	// This enables the repository to participate in transactions
 	WithDB(db *gorm.DB) Repository
 }
 
+// ListScope restricts a teacher list to the records a viewer may see
+// The zero value matches no teacher, so a missing scope fails closed
+type ListScope struct {
+	All        bool   // Every teacher, for admins
+	UUID       string // Only the teacher with this UUID
+	StudentID  string // Only the teacher the student is assigned to
+	Department string // Only the teachers of this department, for department admins
+}
+
 // InMemoryRepository is a simple in-memory implementation of Repository
 type InMemoryRepository struct {
 	teachers map[string]*Teacher
+	deleted  map[string]*Teacher
 	mu       sync.RWMutex
 }
 
@@ -34,6 +70,7 @@ type InMemoryRepository struct {
 func NewInMemoryRepository() *InMemoryRepository {
 	return &InMemoryRepository{
 		teachers: make(map[string]*Teacher),
+		deleted:  make(map[string]*Teacher),
 	}
 }
 
@@ -46,6 +83,7 @@ func (r *InMemoryRepository) Create(ctx context.Context, teacher *Teacher) error
 		return errors.Conflict("teacher with this UUID already exists")
 	}
 
+	teacher.Version = 1
 	r.teachers[teacher.UUID] = teacher
 	return nil
 }
@@ -68,27 +106,105 @@ func (r *InMemoryRepository) Update(ctx context.Context, teacher *Teacher) error
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if _, exists := r.teachers[teacher.UUID]; !exists {
+	current, exists := r.teachers[teacher.UUID]
+	if !exists {
 		return errors.NotFound("teacher")
 	}
+	if current.Version != teacher.Version {
+		return errors.PreconditionFailed("teacher has been modified by another request")
+	}
 
+	teacher.Version++
 	r.teachers[teacher.UUID] = teacher
 	return nil
 }
 
-// Delete removes a teacher from the repository
-func (r *InMemoryRepository) Delete(ctx context.Context, uuid string) error {
+// Patch updates an existing teacher, the in-memory repository always stores the whole teacher
+func (r *InMemoryRepository) Patch(ctx context.Context, teacher *Teacher, columns []string) error {
+	return r.Update(ctx, teacher)
+}
+
+// Delete moves a teacher to the deleted teachers, like the soft delete of the GORM repository
+func (r *InMemoryRepository) Delete(ctx context.Context, uuid string, version int) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if _, exists := r.teachers[uuid]; !exists {
+	teacher, exists := r.teachers[uuid]
+	if !exists {
 		return errors.NotFound("teacher")
 	}
+	if teacher.Version != version {
+		return errors.PreconditionFailed("teacher has been modified by another request")
+	}
 
+	now := time.Now()
+	teacher.DeletedAt = &now
+	r.deleted[uuid] = teacher
 	delete(r.teachers, uuid)
 	return nil
 }
 
+// ListDeleted retrieves a page of deleted teachers, most recently deleted first
+func (r *InMemoryRepository) ListDeleted(ctx context.Context, params pagination.Params) ([]*Teacher, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	deleted := make([]*Teacher, 0, len(r.deleted))
+	for _, teacher := range r.deleted {
+		deleted = append(deleted, teacher)
+	}
+	sort.Slice(deleted, func(i, j int) bool {
+		if c := deleted[i].DeletedAt.Compare(*deleted[j].DeletedAt); c != 0 {
+			return c > 0
+		}
+		return deleted[i].UUID < deleted[j].UUID
+	})
+
+	totalCount := int64(len(deleted))
+	start := params.Offset()
+	if start >= len(deleted) {
+		return []*Teacher{}, totalCount, nil
+	}
+	return deleted[start:min(start+params.Limit, len(deleted))], totalCount, nil
+}
+
+// Restore moves a deleted teacher back to the teachers
+func (r *InMemoryRepository) Restore(ctx context.Context, uuid string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	teacher, exists := r.deleted[uuid]
+	if !exists {
+		return errors.NotFound("deleted teacher not found")
+	}
+	for _, other := range r.teachers {
+		if strings.EqualFold(other.Email, teacher.Email) {
+			return errors.Conflict("another teacher with this email exists")
+		}
+	}
+
+	teacher.DeletedAt = nil
+	teacher.Version++
+	r.teachers[uuid] = teacher
+	delete(r.deleted, uuid)
+	return nil
+}
+
+// PurgeDeleted removes the teachers deleted before the given time
+func (r *InMemoryRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var purged int64
+	for uuid, teacher := range r.deleted {
+		if teacher.DeletedAt.Before(before) {
+			delete(r.deleted, uuid)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
 // List retrieves all teachers (deprecated: use ListPaginated)
 func (r *InMemoryRepository) List(ctx context.Context) ([]*Teacher, error) {
 	r.mu.RLock()
@@ -102,33 +218,139 @@ func (r *InMemoryRepository) List(ctx context.Context) ([]*Teacher, error) {
 	return teachers, nil
 }
 
+// GetByEmails resolves emails (lowercased) to teachers
+func (r *InMemoryRepository) GetByEmails(ctx context.Context, emails []string) (map[string]*Teacher, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make(map[string]*Teacher)
+	for _, teacher := range r.teachers {
+		email := strings.ToLower(teacher.Email)
+		if slices.Contains(emails, email) {
+			result[email] = teacher
+		}
+	}
+	return result, nil
+}
+
 // ListPaginated retrieves teachers with pagination support
-// Returns slice of teachers, total count, and error
-func (r *InMemoryRepository) ListPaginated(ctx context.Context, params pagination.Params) ([]*Teacher, int64, error) {
+// Returns slice of teachers, total count and cursors, and error
+// The in-memory repository doesn't know students, a StudentID scope matches no teacher
+func (r *InMemoryRepository) ListPaginated(ctx context.Context, params pagination.Params, scope ListScope, filter ListFilter) ([]*Teacher, pagination.PageInfo, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	// Get all teachers first
+	// Get all teachers within scope matching the filter first
 	allTeachers := make([]*Teacher, 0, len(r.teachers))
 	for _, teacher := range r.teachers {
-		allTeachers = append(allTeachers, teacher)
+		inScope := scope.All || (scope.UUID != "" && teacher.UUID == scope.UUID) ||
+			(scope.Department != "" && strings.EqualFold(teacher.Department, scope.Department))
+		if inScope && matchesFilter(teacher, filter) {
+			allTeachers = append(allTeachers, teacher)
+		}
 	}
+	sort.Slice(allTeachers, func(i, j int) bool {
+		a, b := allTeachers[i], allTeachers[j]
+		return pagination.Less(filter.Sort, func(field string) int {
+			return compareField(a, b, field)
+		}, strings.Compare(a.UUID, b.UUID))
+	})
 
 	totalCount := int64(len(allTeachers))
 
+	if params.Keyset {
+		desc, _ := pagination.KeysetSort(filter.Sort)
+		page, info := pagination.KeysetSlice(allTeachers, params, desc, cursorOf)
+		info.TotalCount = totalCount
+		return page, info, nil
+	}
+
 	// Apply pagination
+	info := pagination.PageInfo{TotalCount: totalCount}
 	start := params.Offset()
 	end := start + params.Limit
 
 	// Handle edge cases
 	if start >= len(allTeachers) {
-		return []*Teacher{}, totalCount, nil
+		return []*Teacher{}, info, nil
 	}
 	if end > len(allTeachers) {
 		end = len(allTeachers)
 	}
 
-	return allTeachers[start:end], totalCount, nil
+	return allTeachers[start:end], info, nil
+}
+
+// cursorOf returns the keyset pagination position of a teacher
+func cursorOf(teacher *Teacher) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: teacher.CreatedAt, ID: teacher.UUID}
+}
+
+// matchesFilter reports whether the teacher matches the filter, like the WHERE clause of the GORM repository
+func matchesFilter(teacher *Teacher, filter ListFilter) bool {
+	if filter.Search != "" {
+		search := strings.ToLower(filter.Search)
+		fullName := strings.ToLower(teacher.FirstName + " " + teacher.LastName)
+		if !strings.Contains(fullName, search) && !strings.Contains(strings.ToLower(teacher.Email), search) {
+			return false
+		}
+	}
+	if filter.Department != "" && !strings.EqualFold(teacher.Department, filter.Department) {
+		return false
+	}
+	if filter.CreatedFrom != nil && teacher.CreatedAt.Before(*filter.CreatedFrom) {
+		return false
+	}
+	if filter.CreatedTo != nil && teacher.CreatedAt.After(*filter.CreatedTo) {
+		return false
+	}
+	return true
+}
+
+// compareField compares two teachers by one of the SortFields
+func compareField(a, b *Teacher, field string) int {
+	switch field {
+	case "first_name":
+		return strings.Compare(a.FirstName, b.FirstName)
+	case "last_name":
+		return strings.Compare(a.LastName, b.LastName)
+	case "email":
+		return strings.Compare(a.Email, b.Email)
+	case "department":
+		return strings.Compare(a.Department, b.Department)
+	case "created_at":
+		return a.CreatedAt.Compare(b.CreatedAt)
+	case "updated_at":
+		return a.UpdatedAt.Compare(b.UpdatedAt)
+	}
+	return 0
+}
+
+// GetActiveTeacher retrieves a teacher of the repository
+func (r *InMemoryRepository) GetActiveTeacher(ctx context.Context, uuid string) (*reference.Teacher, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	teacher, exists := r.teachers[uuid]
+	if !exists {
+		return nil, nil
+	}
+	return &reference.Teacher{UUID: teacher.UUID, Email: teacher.Email, Department: teacher.Department}, nil
+}
+
+// ReassignStudents is a no-op, the in-memory repository doesn't know students
+func (r *InMemoryRepository) ReassignStudents(ctx context.Context, fromUUID, toUUID string) (int64, error) {
+	return 0, nil
+}
+
+// UnassignStudents is a no-op, the in-memory repository doesn't know students
+func (r *InMemoryRepository) UnassignStudents(ctx context.Context, teacherUUID string) (int64, error) {
+	return 0, nil
+}
+
+// IsAssignedTeacher always reports false, the in-memory repository doesn't know students
+func (r *InMemoryRepository) IsAssignedTeacher(ctx context.Context, studentUUID, teacherUUID string) (bool, error) {
+	return false, nil
 }
 
 // WithDB returns the same repository instance (in-memory doesn't use database connections)
@@ -2,23 +2,27 @@ package teacher
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/JustDoItBetter/FITS-backend/internal/common/errors"
 	"github.com/JustDoItBetter/FITS-backend/internal/common/pagination"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/reference"
+	"github.com/JustDoItBetter/FITS-backend/pkg/database"
 	"gorm.io/gorm"
 )
 
 // TeacherModel represents the GORM model for teachers table
 type TeacherModel struct {
-	ID         string     `gorm:"column:id;type:uuid;primaryKey;default:uuid_generate_v4()"`
-	FirstName  string     `gorm:"column:first_name;type:varchar(100);not null"`
-	LastName   string     `gorm:"column:last_name;type:varchar(100);not null"`
-	Email      string     `gorm:"column:email;type:varchar(255);uniqueIndex;not null"`
-	Department string     `gorm:"column:department;type:varchar(100);not null"`
-	CreatedAt  time.Time  `gorm:"column:created_at;autoCreateTime"`
-	UpdatedAt  time.Time  `gorm:"column:updated_at;autoUpdateTime"`
-	DeletedAt  *time.Time `gorm:"column:deleted_at;index"` // Soft delete support
+	ID         string         `gorm:"column:id;type:uuid;primaryKey;default:uuid_generate_v4()"`
+	FirstName  string         `gorm:"column:first_name;type:varchar(100);not null"`
+	LastName   string         `gorm:"column:last_name;type:varchar(100);not null"`
+	Email      string         `gorm:"column:email;type:varchar(255);not null"` // Unique among teachers that aren't deleted, see migration 021
+	Department string         `gorm:"column:department;type:varchar(100);not null"`
+	CreatedAt  time.Time      `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt  time.Time      `gorm:"column:updated_at;autoUpdateTime"`
+	Version    int            `gorm:"column:version;not null;default:1"` // Optimistic locking, see Update
+	DeletedAt  gorm.DeletedAt `gorm:"column:deleted_at;index"`           // Soft delete support
 }
 
 // TableName specifies the table name for GORM
@@ -28,6 +32,11 @@ func (TeacherModel) TableName() string {
 
 // ToTeacher converts TeacherModel to Teacher domain entity
 func (m *TeacherModel) ToTeacher() *Teacher {
+	var deletedAt *time.Time
+	if m.DeletedAt.Valid {
+		deletedAt = &m.DeletedAt.Time
+	}
+
 	return &Teacher{
 		UUID:       m.ID,
 		FirstName:  m.FirstName,
@@ -36,6 +45,8 @@ func (m *TeacherModel) ToTeacher() *Teacher {
 		Department: m.Department,
 		CreatedAt:  m.CreatedAt,
 		UpdatedAt:  m.UpdatedAt,
+		Version:    m.Version,
+		DeletedAt:  deletedAt,
 	}
 }
 
@@ -49,6 +60,7 @@ func FromTeacher(t *Teacher) *TeacherModel {
 		Department: t.Department,
 		CreatedAt:  t.CreatedAt,
 		UpdatedAt:  t.UpdatedAt,
+		Version:    t.Version,
 	}
 }
 
@@ -70,6 +82,7 @@ func (r *GormRepository) WithDB(db *gorm.DB) Repository {
 
 // Create adds a new teacher to the database
 func (r *GormRepository) Create(ctx context.Context, teacher *Teacher) error {
+	teacher.Version = 1 // Every record starts at version 1
 	model := FromTeacher(teacher)
 
 	if err := r.db.WithContext(ctx).Create(model).Error; err != nil {
@@ -98,20 +111,45 @@ func (r *GormRepository) GetByUUID(ctx context.Context, uuid string) (*Teacher,
 	return model.ToTeacher(), nil
 }
 
-// Update updates an existing teacher
+// Update updates an existing teacher if it still has the version it was loaded with
 func (r *GormRepository) Update(ctx context.Context, teacher *Teacher) error {
-	model := FromTeacher(teacher)
+	return r.update(ctx, teacher, columnValues(FromTeacher(teacher)))
+}
+
+// Patch writes only the given columns of a changed teacher, with the version check of Update
+func (r *GormRepository) Patch(ctx context.Context, teacher *Teacher, columns []string) error {
+	all := columnValues(FromTeacher(teacher))
+	values := make(map[string]interface{}, len(columns))
+	for _, column := range columns {
+		value, ok := all[column]
+		if !ok {
+			return errors.Internal("teacher column " + column + " can't be patched")
+		}
+		values[column] = value
+	}
+	return r.update(ctx, teacher, values)
+}
+
+// columnValues returns the values of the columns that updates write
+func columnValues(model *TeacherModel) map[string]interface{} {
+	return map[string]interface{}{
+		"first_name": model.FirstName,
+		"last_name":  model.LastName,
+		"email":      model.Email,
+		"department": model.Department,
+	}
+}
+
+// update writes the values to the row of the teacher and increments its version
+// The version check and increment happen in the UPDATE itself, so concurrent writers can't overwrite each other
+func (r *GormRepository) update(ctx context.Context, teacher *Teacher, values map[string]interface{}) error {
+	values["updated_at"] = time.Now()
+	values["version"] = gorm.Expr("version + 1")
 
 	result := r.db.WithContext(ctx).
 		Model(&TeacherModel{}).
-		Where("id = ?", teacher.UUID).
-		Updates(map[string]interface{}{
-			"first_name": model.FirstName,
-			"last_name":  model.LastName,
-			"email":      model.Email,
-			"department": model.Department,
-			"updated_at": time.Now(),
-		})
+		Where("id = ? AND version = ?", teacher.UUID, teacher.Version).
+		Updates(values)
 
 	if result.Error != nil {
 		if errors.IsUniqueViolation(result.Error) {
@@ -121,16 +159,17 @@ func (r *GormRepository) Update(ctx context.Context, teacher *Teacher) error {
 	}
 
 	if result.RowsAffected == 0 {
-		return errors.NotFound("teacher not found")
+		return r.notUpdated(ctx, teacher.UUID)
 	}
 
+	teacher.Version++
 	return nil
 }
 
-// Delete removes a teacher from the database
-func (r *GormRepository) Delete(ctx context.Context, uuid string) error {
+// Delete soft-deletes a teacher if it still has the given version
+func (r *GormRepository) Delete(ctx context.Context, uuid string, version int) error {
 	result := r.db.WithContext(ctx).
-		Where("id = ?", uuid).
+		Where("id = ? AND version = ?", uuid, version).
 		Delete(&TeacherModel{})
 
 	if result.Error != nil {
@@ -138,12 +177,95 @@ func (r *GormRepository) Delete(ctx context.Context, uuid string) error {
 	}
 
 	if result.RowsAffected == 0 {
-		return errors.NotFound("teacher not found")
+		return r.notUpdated(ctx, uuid)
+	}
+
+	return nil
+}
+
+// notUpdated explains why a versioned write matched no row:
+// the teacher was changed by another request, or it doesn't exist (anymore)
+func (r *GormRepository) notUpdated(ctx context.Context, uuid string) error {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&TeacherModel{}).Where("id = ?", uuid).Count(&count).Error; err != nil {
+		return errors.Internal("failed to check teacher: " + err.Error())
+	}
+	if count > 0 {
+		return errors.PreconditionFailed("teacher has been modified by another request")
+	}
+	return errors.NotFound("teacher not found")
+}
+
+// ListDeleted retrieves a page of soft-deleted teachers, most recently deleted first
+func (r *GormRepository) ListDeleted(ctx context.Context, params pagination.Params) ([]*Teacher, int64, error) {
+	var models []TeacherModel
+	var totalCount int64
+
+	if err := r.db.WithContext(ctx).Unscoped().Model(&TeacherModel{}).Where("deleted_at IS NOT NULL").Count(&totalCount).Error; err != nil {
+		return nil, 0, errors.Internal("failed to count deleted teachers: " + err.Error())
+	}
+
+	if err := r.db.WithContext(ctx).Unscoped().
+		Where("deleted_at IS NOT NULL").
+		Offset(params.Offset()).Limit(params.Limit).
+		Order("deleted_at DESC, id").
+		Find(&models).Error; err != nil {
+		return nil, 0, errors.Internal("failed to list deleted teachers: " + err.Error())
+	}
+
+	teachers := make([]*Teacher, len(models))
+	for i, model := range models {
+		teachers[i] = model.ToTeacher()
+	}
+
+	return teachers, totalCount, nil
+}
+
+// Restore undeletes a soft-deleted teacher
+// The email is only unique among teachers that aren't deleted, so it may be taken by now
+func (r *GormRepository) Restore(ctx context.Context, uuid string) error {
+	result := r.db.WithContext(ctx).
+		Unscoped().
+		Model(&TeacherModel{}).
+		Where("id = ? AND deleted_at IS NOT NULL", uuid).
+		Updates(map[string]interface{}{
+			"deleted_at": nil,
+			"updated_at": time.Now(),
+			"version":    gorm.Expr("version + 1"),
+		})
+
+	if result.Error != nil {
+		if errors.IsUniqueViolation(result.Error) {
+			return errors.Conflict("another teacher with this email exists")
+		}
+		return errors.Internal("failed to restore teacher: " + result.Error.Error())
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.NotFound("deleted teacher not found")
 	}
 
 	return nil
 }
 
+// PurgeDeleted permanently removes the teachers deleted before the given time
+// Students and invitations still referencing them lose the reference by ON DELETE SET NULL.
+// Teachers with reports or signatures stay in the trash, as ON DELETE CASCADE would destroy signed records
+func (r *GormRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Where("NOT EXISTS (SELECT 1 FROM reports WHERE reports.teacher_uuid = teachers.id)").
+		Where("NOT EXISTS (SELECT 1 FROM signatures WHERE signatures.teacher_uuid = teachers.id)").
+		Delete(&TeacherModel{})
+
+	if result.Error != nil {
+		return 0, errors.Internal("failed to purge deleted teachers: " + result.Error.Error())
+	}
+
+	return result.RowsAffected, nil
+}
+
 // List retrieves all teachers (deprecated: use ListPaginated for better performance)
 func (r *GormRepository) List(ctx context.Context) ([]*Teacher, error) {
 	var models []TeacherModel
@@ -160,24 +282,51 @@ func (r *GormRepository) List(ctx context.Context) ([]*Teacher, error) {
 	return teachers, nil
 }
 
-// ListPaginated retrieves teachers with pagination using efficient OFFSET/LIMIT query
-// Performs two queries: COUNT for total, SELECT with LIMIT/OFFSET for data
-func (r *GormRepository) ListPaginated(ctx context.Context, params pagination.Params) ([]*Teacher, int64, error) {
+// GetByEmails resolves emails (lowercased) to teachers, ignoring deleted teachers
+func (r *GormRepository) GetByEmails(ctx context.Context, emails []string) (map[string]*Teacher, error) {
+	result := make(map[string]*Teacher)
+	if len(emails) == 0 {
+		return result, nil
+	}
+
 	var models []TeacherModel
-	var totalCount int64
+	if err := r.db.WithContext(ctx).Where("LOWER(email) IN ?", emails).Find(&models).Error; err != nil {
+		return nil, errors.Internal("failed to look up teachers: " + err.Error())
+	}
+
+	for _, model := range models {
+		teacher := model.ToTeacher()
+		result[strings.ToLower(teacher.Email)] = teacher
+	}
+	return result, nil
+}
+
+// ListPaginated retrieves teachers with pagination using efficient OFFSET/LIMIT or keyset queries
+// Performs two queries: COUNT for total, SELECT with LIMIT/OFFSET (or after the cursor) for data, both restricted to the scope and filter
+func (r *GormRepository) ListPaginated(ctx context.Context, params pagination.Params, scope ListScope, filter ListFilter) ([]*Teacher, pagination.PageInfo, error) {
+	var models []TeacherModel
+	var info pagination.PageInfo
 
 	// Count total records first for pagination metadata
-	if err := r.db.WithContext(ctx).Model(&TeacherModel{}).Count(&totalCount).Error; err != nil {
-		return nil, 0, errors.Internal("failed to count teachers: " + err.Error())
+	if err := filtered(scoped(r.db.WithContext(ctx).Model(&TeacherModel{}), scope), filter).Count(&info.TotalCount).Error; err != nil {
+		return nil, info, errors.Internal("failed to count teachers: " + err.Error())
 	}
 
-	// Fetch paginated results with OFFSET and LIMIT
-	if err := r.db.WithContext(ctx).
-		Offset(params.Offset()).
-		Limit(params.Limit).
-		Order("created_at DESC"). // Most recent teachers first for better UX
-		Find(&models).Error; err != nil {
-		return nil, 0, errors.Internal("failed to list teachers: " + err.Error())
+	query := filtered(scoped(r.db.WithContext(ctx), scope), filter)
+	if params.Keyset {
+		// Continue after the cursor on (created_at, id), fetching one extra row to detect the next page
+		desc, _ := pagination.KeysetSort(filter.Sort)
+		if params.Cursor != nil {
+			condition, args := pagination.KeysetCondition(*params.Cursor, desc)
+			query = query.Where(condition, args...)
+		}
+		query = query.Limit(params.Limit + 1).Order(pagination.KeysetOrder(params, desc))
+	} else {
+		// Fetch paginated results with OFFSET and LIMIT, most recent teachers first unless sorted otherwise
+		query = query.Offset(params.Offset()).Limit(params.Limit).Order(pagination.OrderBy(filter.Sort, "id"))
+	}
+	if err := query.Find(&models).Error; err != nil {
+		return nil, info, errors.Internal("failed to list teachers: " + err.Error())
 	}
 
 	teachers := make([]*Teacher, len(models))
@@ -185,5 +334,108 @@ func (r *GormRepository) ListPaginated(ctx context.Context, params pagination.Pa
 		teachers[i] = model.ToTeacher()
 	}
 
-	return teachers, totalCount, nil
+	if params.Keyset {
+		page, keyset := pagination.KeysetPage(teachers, params, cursorOf)
+		keyset.TotalCount = info.TotalCount
+		return page, keyset, nil
+	}
+	return teachers, info, nil
+}
+
+// scoped restricts a teachers query to the scope
+func scoped(db *gorm.DB, scope ListScope) *gorm.DB {
+	switch {
+	case scope.All:
+		return db
+	case scope.UUID != "":
+		return db.Where("id = ?", scope.UUID)
+	case scope.StudentID != "":
+		return db.Where("id IN (SELECT teacher_id FROM students WHERE id = ? AND deleted_at IS NULL)", scope.StudentID)
+	case scope.Department != "":
+		return db.Where("LOWER(department) = LOWER(?)", scope.Department)
+	}
+	return db.Where("1 = 0")
+}
+
+// filtered applies the filter of the list endpoint to a teachers query
+// Search and department use the expression indexes of migration 020
+func filtered(db *gorm.DB, filter ListFilter) *gorm.DB {
+	if filter.Search != "" {
+		pattern := database.ContainsPattern(filter.Search)
+		db = db.Where(`(LOWER(first_name || ' ' || last_name) LIKE ? ESCAPE '\' OR LOWER(email) LIKE ? ESCAPE '\')`, pattern, pattern)
+	}
+	if filter.Department != "" {
+		db = db.Where("LOWER(department) = LOWER(?)", filter.Department)
+	}
+	if filter.CreatedFrom != nil {
+		db = db.Where("created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		db = db.Where("created_at <= ?", *filter.CreatedTo)
+	}
+	return db
+}
+
+// GetActiveTeacher looks up a teacher students can be reassigned to
+// Returns nil without error if the teacher does not exist or was deleted
+func (r *GormRepository) GetActiveTeacher(ctx context.Context, uuid string) (*reference.Teacher, error) {
+	teacher, err := reference.FindActiveTeacher(ctx, r.db, uuid)
+	if err != nil {
+		return nil, errors.Internal(err.Error())
+	}
+	return teacher, nil
+}
+
+// ReassignStudents moves the students of a teacher to another teacher
+// A flag left by an earlier teacher deletion is cleared, the students have a teacher again
+func (r *GormRepository) ReassignStudents(ctx context.Context, fromUUID, toUUID string) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Table("students").
+		Where("teacher_id = ? AND deleted_at IS NULL", fromUUID).
+		Updates(map[string]interface{}{
+			"teacher_id":         toUUID,
+			"teacher_removed_at": nil,
+			"updated_at":         time.Now(),
+			"version":            gorm.Expr("version + 1"),
+		})
+
+	if result.Error != nil {
+		return 0, errors.Internal("failed to reassign students: " + result.Error.Error())
+	}
+
+	return result.RowsAffected, nil
+}
+
+// IsAssignedTeacher reports whether the student exists, isn't deleted and is assigned to the teacher
+func (r *GormRepository) IsAssignedTeacher(ctx context.Context, studentUUID, teacherUUID string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Table("students").
+		Where("id = ? AND teacher_id = ? AND deleted_at IS NULL", studentUUID, teacherUUID).
+		Count(&count).Error
+	if err != nil {
+		return false, errors.Internal("failed to check assigned teacher: " + err.Error())
+	}
+	return count > 0, nil
+}
+
+// UnassignStudents removes the teacher from its students and flags them with teacher_removed_at
+// Soft deletion doesn't trigger the ON DELETE SET NULL of the foreign key, so this is done here
+func (r *GormRepository) UnassignStudents(ctx context.Context, teacherUUID string) (int64, error) {
+	now := time.Now()
+	result := r.db.WithContext(ctx).
+		Table("students").
+		Where("teacher_id = ? AND deleted_at IS NULL", teacherUUID).
+		Updates(map[string]interface{}{
+			"teacher_id":         nil,
+			"teacher_removed_at": now,
+			"updated_at":         now,
+			"version":            gorm.Expr("version + 1"),
+		})
+
+	if result.Error != nil {
+		return 0, errors.Internal("failed to unassign students: " + result.Error.Error())
+	}
+
+	return result.RowsAffected, nil
 }
@@ -2,6 +2,7 @@ package teacher
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -9,6 +10,11 @@ import (
 	"github.com/stretchr/testify/require"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/conditional"
+	apperrors "github.com/JustDoItBetter/FITS-backend/internal/common/errors"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/pagination"
+	"github.com/JustDoItBetter/FITS-backend/pkg/database"
 )
 
 // TestTeacherModel is a simplified model for SQLite testing
@@ -16,10 +22,11 @@ type TestTeacherModel struct {
 	ID         string `gorm:"column:id;primaryKey"`
 	FirstName  string `gorm:"column:first_name;type:varchar(100);not null"`
 	LastName   string `gorm:"column:last_name;type:varchar(100);not null"`
-	Email      string `gorm:"column:email;type:varchar(255);uniqueIndex;not null"`
+	Email      string `gorm:"column:email;type:varchar(255);uniqueIndex:idx_teachers_email_active,where:deleted_at IS NULL;not null"`
 	Department string `gorm:"column:department;type:varchar(100);not null"`
 	CreatedAt  time.Time
 	UpdatedAt  time.Time
+	Version    int            `gorm:"column:version;not null;default:1"`
 	DeletedAt  gorm.DeletedAt `gorm:"index"`
 }
 
@@ -27,6 +34,20 @@ func (TestTeacherModel) TableName() string {
 	return "teachers"
 }
 
+// testStudentModel is the part of the students table teachers update when they are deleted
+type testStudentModel struct {
+	ID               string  `gorm:"column:id;primaryKey"`
+	TeacherID        *string `gorm:"column:teacher_id"`
+	TeacherRemovedAt *time.Time
+	UpdatedAt        time.Time
+	Version          int `gorm:"column:version;not null;default:1"`
+	DeletedAt        gorm.DeletedAt
+}
+
+func (testStudentModel) TableName() string {
+	return "students"
+}
+
 // setupTestDB creates an in-memory SQLite database for testing
 func setupTestDB(t *testing.T) *gorm.DB {
 	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
@@ -36,6 +57,10 @@ func setupTestDB(t *testing.T) *gorm.DB {
 	err = db.AutoMigrate(&TestTeacherModel{})
 	require.NoError(t, err, "failed to migrate test database")
 
+	// Reports and signatures are only read by the purge, which must not delete records they reference
+	require.NoError(t, db.Exec(`CREATE TABLE reports (id TEXT PRIMARY KEY, student_uuid TEXT NOT NULL, teacher_uuid TEXT NOT NULL, status TEXT NOT NULL)`).Error)
+	require.NoError(t, db.Exec(`CREATE TABLE signatures (id TEXT PRIMARY KEY, report_id TEXT NOT NULL, teacher_uuid TEXT NOT NULL)`).Error)
+
 	return db
 }
 
@@ -213,6 +238,39 @@ func TestGormRepository_Update(t *testing.T) {
 	})
 }
 
+// TestGormRepository_Patch tests that Patch writes only the given columns
+func TestGormRepository_Patch(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository(db)
+	ctx := context.Background()
+
+	teacher := &Teacher{
+		UUID:       "550e8400-e29b-41d4-a716-446655440320",
+		FirstName:  "John",
+		LastName:   "Doe",
+		Email:      "patched@test.com",
+		Department: "Physics",
+	}
+	require.NoError(t, repo.Create(ctx, teacher))
+
+	// Another request changes the last name, the patch of the first name must not overwrite it
+	require.NoError(t, db.Table("teachers").Where("id = ?", teacher.UUID).Update("last_name", "Smith").Error)
+
+	teacher.FirstName = "Jane"
+	teacher.LastName = "Stale"
+	require.NoError(t, repo.Patch(ctx, teacher, []string{"first_name"}))
+	assert.Equal(t, 2, teacher.Version)
+
+	retrieved, err := repo.GetByUUID(ctx, teacher.UUID)
+	require.NoError(t, err)
+	assert.Equal(t, "Jane", retrieved.FirstName)
+	assert.Equal(t, "Smith", retrieved.LastName)
+	assert.Equal(t, 2, retrieved.Version)
+
+	err = repo.Patch(ctx, teacher, []string{"created_at"})
+	assertAppErrorCode(t, err, 500)
+}
+
 // TestGormRepository_Delete tests the Delete method
 func TestGormRepository_Delete(t *testing.T) {
 	db := setupTestDB(t)
@@ -234,17 +292,21 @@ func TestGormRepository_Delete(t *testing.T) {
 		require.NoError(t, err)
 
 		// Delete the teacher
-		err = repo.Delete(ctx, teacher.UUID)
+		err = repo.Delete(ctx, teacher.UUID, 1)
 		assert.NoError(t, err)
 
 		// Verify deletion
 		retrieved, err := repo.GetByUUID(ctx, teacher.UUID)
 		assert.Error(t, err)
 		assert.Nil(t, retrieved)
+		// The row is kept for references and audits
+		var count int64
+		require.NoError(t, db.Unscoped().Model(&TestTeacherModel{}).Where("id = ?", teacher.UUID).Count(&count).Error)
+		assert.Equal(t, int64(1), count)
 	})
 
 	t.Run("delete nonexistent teacher", func(t *testing.T) {
-		err := repo.Delete(ctx, "nonexistent-uuid")
+		err := repo.Delete(ctx, "nonexistent-uuid", 1)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "not found")
 	})
@@ -342,3 +404,416 @@ func TestGormRepository_DepartmentUpdate(t *testing.T) {
 		assert.Equal(t, "Data Science", retrieved.Department)
 	})
 }
+
+// TestService_DeleteStudents tests what happens to the students and hooks of a deleted teacher
+func TestService_DeleteStudents(t *testing.T) {
+	ctx := context.Background()
+	oldUUID := "550e8400-e29b-41d4-a716-446655440700"
+	newUUID := "550e8400-e29b-41d4-a716-446655440701"
+
+	setup := func(t *testing.T) (*gorm.DB, *Service) {
+		db := setupTestDB(t)
+		require.NoError(t, db.AutoMigrate(&testStudentModel{}))
+		repo := NewGormRepository(db)
+		for _, id := range []string{oldUUID, newUUID} {
+			require.NoError(t, repo.Create(ctx, &Teacher{
+				UUID: id, FirstName: "Anna", LastName: "Schmidt", Email: id + "@test.com", Department: "IT",
+			}))
+		}
+		removed := time.Now()
+		require.NoError(t, db.Create(&testStudentModel{ID: "student-1", TeacherID: &oldUUID, TeacherRemovedAt: &removed}).Error)
+		require.NoError(t, db.Create(&testStudentModel{ID: "student-2", TeacherID: &oldUUID}).Error)
+		require.NoError(t, db.Create(&testStudentModel{ID: "student-3", TeacherID: &newUUID}).Error)
+		return db, NewServiceWithTx(repo, database.NewTransactionManager(db))
+	}
+
+	students := func(t *testing.T, db *gorm.DB) map[string]testStudentModel {
+		var models []testStudentModel
+		require.NoError(t, db.Find(&models).Error)
+		byID := make(map[string]testStudentModel, len(models))
+		for _, m := range models {
+			byID[m.ID] = m
+		}
+		return byID
+	}
+
+	t.Run("unassigns and flags students", func(t *testing.T) {
+		db, service := setup(t)
+
+		require.NoError(t, service.Delete(ctx, oldUUID, conditional.Any, DeleteOptions{}, fullAdmin))
+
+		byID := students(t, db)
+		for _, id := range []string{"student-1", "student-2"} {
+			assert.Nil(t, byID[id].TeacherID)
+			assert.NotNil(t, byID[id].TeacherRemovedAt)
+		}
+		assert.Equal(t, newUUID, *byID["student-3"].TeacherID)
+		assert.Nil(t, byID["student-3"].TeacherRemovedAt)
+	})
+
+	t.Run("reassigns students", func(t *testing.T) {
+		db, service := setup(t)
+
+		require.NoError(t, service.Delete(ctx, oldUUID, conditional.Any, DeleteOptions{ReassignTo: newUUID}, fullAdmin))
+
+		for id, student := range students(t, db) {
+			assert.Equal(t, newUUID, *student.TeacherID, id)
+			assert.Nil(t, student.TeacherRemovedAt, id)
+		}
+	})
+
+	t.Run("runs hooks in the transaction", func(t *testing.T) {
+		db, service := setup(t)
+		var hooked string
+		service.OnDelete(func(ctx context.Context, tx *gorm.DB, uuid string) error {
+			hooked = uuid
+			return nil
+		})
+
+		require.NoError(t, service.Delete(ctx, oldUUID, conditional.Any, DeleteOptions{}, fullAdmin))
+		assert.Equal(t, oldUUID, hooked)
+
+		_, err := service.GetByUUID(ctx, oldUUID)
+		assert.Error(t, err)
+		assert.NotNil(t, students(t, db)["student-2"].TeacherRemovedAt)
+	})
+
+	t.Run("failing hook rolls back the deletion", func(t *testing.T) {
+		db, service := setup(t)
+		service.OnDelete(func(ctx context.Context, tx *gorm.DB, uuid string) error {
+			return apperrors.Internal("hook failed")
+		})
+
+		assert.Error(t, service.Delete(ctx, oldUUID, conditional.Any, DeleteOptions{}, fullAdmin))
+
+		_, err := service.GetByUUID(ctx, oldUUID)
+		assert.NoError(t, err)
+		assert.Equal(t, oldUUID, *students(t, db)["student-2"].TeacherID)
+	})
+
+	t.Run("rejects deleted target teacher", func(t *testing.T) {
+		_, service := setup(t)
+		require.NoError(t, service.Delete(ctx, newUUID, conditional.Any, DeleteOptions{}, fullAdmin))
+
+		err := service.Delete(ctx, oldUUID, conditional.Any, DeleteOptions{ReassignTo: newUUID}, fullAdmin)
+
+		assertValidationError(t, err)
+		_, err = service.GetByUUID(ctx, oldUUID)
+		assert.NoError(t, err)
+	})
+}
+
+// TestGormRepository_IsAssignedTeacher tests the assignment lookup of the teacher read policy
+func TestGormRepository_IsAssignedTeacher(t *testing.T) {
+	db := setupTestDB(t)
+	require.NoError(t, db.AutoMigrate(&testStudentModel{}))
+	repo := NewGormRepository(db)
+	ctx := context.Background()
+
+	teacherUUID := "550e8400-e29b-41d4-a716-446655440010"
+	require.NoError(t, db.Create(&testStudentModel{ID: "student-1", TeacherID: &teacherUUID}).Error)
+	require.NoError(t, db.Create(&testStudentModel{ID: "student-2"}).Error)
+	require.NoError(t, db.Create(&testStudentModel{ID: "student-3", TeacherID: &teacherUUID}).Error)
+	require.NoError(t, db.Delete(&testStudentModel{ID: "student-3"}).Error)
+
+	tests := []struct {
+		name      string
+		studentID string
+		want      bool
+	}{
+		{name: "assigned student", studentID: "student-1", want: true},
+		{name: "student without teacher", studentID: "student-2", want: false},
+		{name: "deleted student", studentID: "student-3", want: false},
+		{name: "unknown student", studentID: "student-4", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assigned, err := repo.IsAssignedTeacher(ctx, tt.studentID, teacherUUID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, assigned)
+		})
+	}
+}
+
+// TestGormRepository_GetByEmails tests that import rows are matched case-insensitively, ignoring deleted teachers
+func TestGormRepository_GetByEmails(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository(db)
+	ctx := context.Background()
+
+	for i, email := range []string{"Max@Example.com", "erika@example.com", "deleted@example.com"} {
+		require.NoError(t, repo.Create(ctx, &Teacher{
+			UUID: "550e8400-e29b-41d4-a716-44665544097" + string(rune('0'+i)), FirstName: "First", LastName: "Last", Email: email, Department: "IT", CreatedAt: time.Now(), UpdatedAt: time.Now(),
+		}))
+	}
+	require.NoError(t, repo.Delete(ctx, "550e8400-e29b-41d4-a716-446655440972", 1))
+
+	result, err := repo.GetByEmails(ctx, []string{"max@example.com", "deleted@example.com", "unknown@example.com"})
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	assert.Equal(t, "550e8400-e29b-41d4-a716-446655440970", result["max@example.com"].UUID)
+
+	result, err = repo.GetByEmails(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, result)
+}
+
+// TestGormRepository_ListPaginatedScope tests that the scope is part of the query and the count
+func TestGormRepository_ListPaginatedScope(t *testing.T) {
+	db := setupTestDB(t)
+	require.NoError(t, db.AutoMigrate(&testStudentModel{}))
+	repo := NewGormRepository(db)
+	ctx := context.Background()
+
+	teacherUUIDs := []string{
+		"550e8400-e29b-41d4-a716-446655440800",
+		"550e8400-e29b-41d4-a716-446655440801",
+		"550e8400-e29b-41d4-a716-446655440802",
+	}
+	for i, teacherUUID := range teacherUUIDs {
+		require.NoError(t, repo.Create(ctx, &Teacher{
+			UUID:       teacherUUID,
+			FirstName:  "Teacher",
+			LastName:   fmt.Sprintf("%d", i),
+			Email:      fmt.Sprintf("teacher%d@test.com", i),
+			Department: "Computer Science",
+			CreatedAt:  time.Now(),
+			UpdatedAt:  time.Now(),
+		}))
+	}
+	require.NoError(t, db.Create(&testStudentModel{ID: "student-1", TeacherID: &teacherUUIDs[1]}).Error)
+	require.NoError(t, db.Create(&testStudentModel{ID: "student-2", TeacherID: &teacherUUIDs[2]}).Error)
+	require.NoError(t, db.Delete(&testStudentModel{ID: "student-2"}).Error)
+
+	tests := []struct {
+		name  string
+		scope ListScope
+		want  []string
+	}{
+		{name: "all teachers", scope: ListScope{All: true}, want: teacherUUIDs},
+		{name: "own record", scope: ListScope{UUID: teacherUUIDs[0]}, want: teacherUUIDs[:1]},
+		{name: "teacher of a student", scope: ListScope{StudentID: "student-1"}, want: teacherUUIDs[1:2]},
+		{name: "teacher of a deleted student", scope: ListScope{StudentID: "student-2"}, want: nil},
+		{name: "zero scope", scope: ListScope{}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			teachers, info, err := repo.ListPaginated(ctx, pagination.Params{Page: 1, Limit: 20}, tt.scope, ListFilter{})
+			require.NoError(t, err)
+			assert.Equal(t, int64(len(tt.want)), info.TotalCount)
+
+			uuids := make([]string, len(teachers))
+			for i, teacher := range teachers {
+				uuids[i] = teacher.UUID
+			}
+			assert.ElementsMatch(t, tt.want, uuids)
+		})
+	}
+}
+
+// TestRepository_ListPaginatedFilter tests search, filters and sorting of both repositories
+func TestRepository_ListPaginatedFilter(t *testing.T) {
+	ctx := context.Background()
+	day := func(d int) time.Time { return time.Date(2025, 9, d, 12, 0, 0, 0, time.UTC) }
+	teachers := []*Teacher{
+		{UUID: "550e8400-e29b-41d4-a716-446655440900", FirstName: "Anna", LastName: "Schmidt", Email: "anna@school.de", Department: "IT", CreatedAt: day(1), UpdatedAt: day(1)},
+		{UUID: "550e8400-e29b-41d4-a716-446655440901", FirstName: "Peter", LastName: "Meyer", Email: "p.meyer@example.com", Department: "HR", CreatedAt: day(2), UpdatedAt: day(2)},
+		{UUID: "550e8400-e29b-41d4-a716-446655440902", FirstName: "Jonas", LastName: "Schmidt", Email: "jonas_k@school.de", Department: "IT", CreatedAt: day(3), UpdatedAt: day(3)},
+	}
+
+	from := day(2)
+	tests := []struct {
+		name   string
+		filter ListFilter
+		want   []string // First names in order
+	}{
+		{name: "newest first by default", filter: ListFilter{}, want: []string{"Jonas", "Peter", "Anna"}},
+		{name: "search full name", filter: ListFilter{Search: "anna schm"}, want: []string{"Anna"}},
+		{name: "search email", filter: ListFilter{Search: "example"}, want: []string{"Peter"}},
+		{name: "search literal underscore", filter: ListFilter{Search: "_"}, want: []string{"Jonas"}},
+		{name: "search literal percent", filter: ListFilter{Search: "%"}, want: []string{}},
+		{name: "department", filter: ListFilter{Department: "it"}, want: []string{"Jonas", "Anna"}},
+		{name: "created from", filter: ListFilter{CreatedFrom: &from}, want: []string{"Jonas", "Peter"}},
+		{
+			name:   "multi-field sort",
+			filter: ListFilter{Sort: []pagination.SortField{{Field: "department", Desc: true}, {Field: "first_name"}}},
+			want:   []string{"Anna", "Jonas", "Peter"},
+		},
+	}
+
+	repos := map[string]func(*testing.T) Repository{
+		"gorm":      func(t *testing.T) Repository { return NewGormRepository(setupTestDB(t)) },
+		"in-memory": func(t *testing.T) Repository { return NewInMemoryRepository() },
+	}
+	for name, newRepo := range repos {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+			for _, teacher := range teachers {
+				require.NoError(t, repo.Create(ctx, teacher))
+			}
+
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					result, info, err := repo.ListPaginated(ctx, pagination.Params{Page: 1, Limit: 20}, ListScope{All: true}, tt.filter)
+					require.NoError(t, err)
+					assert.Equal(t, int64(len(tt.want)), info.TotalCount)
+
+					firstNames := make([]string, len(result))
+					for i, teacher := range result {
+						firstNames[i] = teacher.FirstName
+					}
+					assert.Equal(t, tt.want, firstNames)
+				})
+			}
+		})
+	}
+}
+
+// TestRepository_Versions tests that writes are only applied to the version they were based on
+func TestRepository_Versions(t *testing.T) {
+	ctx := context.Background()
+	repos := map[string]func(*testing.T) Repository{
+		"gorm":      func(t *testing.T) Repository { return NewGormRepository(setupTestDB(t)) },
+		"in-memory": func(t *testing.T) Repository { return NewInMemoryRepository() },
+	}
+
+	for name, newRepo := range repos {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+			teacherUUID := "550e8400-e29b-41d4-a716-446655440960"
+			require.NoError(t, repo.Create(ctx, &Teacher{
+				UUID:       teacherUUID,
+				FirstName:  "Versioned",
+				LastName:   "Teacher",
+				Email:      "versioned@school.de",
+				Department: "Physics",
+			}))
+
+			loaded, err := repo.GetByUUID(ctx, teacherUUID)
+			require.NoError(t, err)
+			assert.Equal(t, 1, loaded.Version)
+			stale := *loaded
+
+			loaded.FirstName = "Changed"
+			require.NoError(t, repo.Update(ctx, loaded))
+			assert.Equal(t, 2, loaded.Version)
+
+			// A write based on the old version is rejected
+			stale.FirstName = "Lost"
+			assertAppErrorCode(t, repo.Update(ctx, &stale), 412)
+			assertAppErrorCode(t, repo.Delete(ctx, teacherUUID, 1), 412)
+
+			current, err := repo.GetByUUID(ctx, teacherUUID)
+			require.NoError(t, err)
+			assert.Equal(t, "Changed", current.FirstName)
+			assert.Equal(t, 2, current.Version)
+
+			require.NoError(t, repo.Delete(ctx, teacherUUID, 2))
+			assertAppErrorCode(t, repo.Delete(ctx, teacherUUID, 2), 404)
+		})
+	}
+}
+
+// TestRepository_Trash tests listing, restoring and purging deleted teachers in both repositories
+func TestRepository_Trash(t *testing.T) {
+	ctx := context.Background()
+	repos := map[string]func(*testing.T) Repository{
+		"gorm":      func(t *testing.T) Repository { return NewGormRepository(setupTestDB(t)) },
+		"in-memory": func(t *testing.T) Repository { return NewInMemoryRepository() },
+	}
+
+	for name, newRepo := range repos {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+			for i, email := range []string{"first@school.de", "second@school.de"} {
+				require.NoError(t, repo.Create(ctx, &Teacher{
+					UUID:       fmt.Sprintf("550e8400-e29b-41d4-a716-44665544093%d", i),
+					FirstName:  "Trash",
+					LastName:   fmt.Sprintf("Teacher%d", i),
+					Email:      email,
+					Department: "IT",
+					CreatedAt:  time.Now(),
+					UpdatedAt:  time.Now(),
+				}))
+				require.NoError(t, repo.Delete(ctx, fmt.Sprintf("550e8400-e29b-41d4-a716-44665544093%d", i), 1))
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			// Most recently deleted first
+			deleted, total, err := repo.ListDeleted(ctx, pagination.Params{Page: 1, Limit: 20})
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), total)
+			require.Len(t, deleted, 2)
+			assert.Equal(t, "second@school.de", deleted[0].Email)
+			assert.NotNil(t, deleted[0].DeletedAt)
+
+			// The email of a deleted teacher can be used again, which blocks restoring
+			require.NoError(t, repo.Create(ctx, &Teacher{
+				UUID:       "550e8400-e29b-41d4-a716-446655440939",
+				FirstName:  "New",
+				LastName:   "Teacher",
+				Email:      "first@school.de",
+				Department: "IT",
+			}))
+			err = repo.Restore(ctx, "550e8400-e29b-41d4-a716-446655440930")
+			var appErr *apperrors.AppError
+			require.ErrorAs(t, err, &appErr)
+			assert.Equal(t, 409, appErr.Code)
+
+			require.NoError(t, repo.Restore(ctx, "550e8400-e29b-41d4-a716-446655440931"))
+			restored, err := repo.GetByUUID(ctx, "550e8400-e29b-41d4-a716-446655440931")
+			require.NoError(t, err)
+			assert.Nil(t, restored.DeletedAt)
+
+			err = repo.Restore(ctx, "550e8400-e29b-41d4-a716-446655440931")
+			require.ErrorAs(t, err, &appErr)
+			assert.Equal(t, 404, appErr.Code)
+
+			purged, err := repo.PurgeDeleted(ctx, time.Now().Add(time.Second))
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), purged)
+
+			_, total, err = repo.ListDeleted(ctx, pagination.Params{Page: 1, Limit: 20})
+			require.NoError(t, err)
+			assert.Equal(t, int64(0), total)
+		})
+	}
+}
+
+// TestGormRepository_PurgeDeletedKeepsSignedRecords tests that teachers with reports or signatures are never purged
+func TestGormRepository_PurgeDeletedKeepsSignedRecords(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB(t)
+	repo := NewGormRepository(db)
+	withReport := "550e8400-e29b-41d4-a716-446655440950"
+	withSignature := "550e8400-e29b-41d4-a716-446655440951"
+	unreferenced := "550e8400-e29b-41d4-a716-446655440952"
+
+	for i, uuid := range []string{withReport, withSignature, unreferenced} {
+		require.NoError(t, repo.Create(ctx, &Teacher{UUID: uuid, FirstName: "Anna", LastName: "Schmidt", Email: fmt.Sprintf("anna%d@school.de", i), Department: "IT"}))
+		require.NoError(t, repo.Delete(ctx, uuid, 1))
+	}
+	require.NoError(t, db.Exec(`INSERT INTO reports (id, student_uuid, teacher_uuid, status) VALUES ('report-1', 'student-1', ?, 'pending')`, withReport).Error)
+	require.NoError(t, db.Exec(`INSERT INTO signatures (id, report_id, teacher_uuid) VALUES ('signature-1', 'report-2', ?)`, withSignature).Error)
+
+	purged, err := repo.PurgeDeleted(ctx, time.Now().Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	deleted, _, err := repo.ListDeleted(ctx, pagination.Params{Page: 1, Limit: 20})
+	require.NoError(t, err)
+	uuids := make([]string, len(deleted))
+	for i, teacher := range deleted {
+		uuids[i] = teacher.UUID
+	}
+	assert.ElementsMatch(t, []string{withReport, withSignature}, uuids)
+}
+
+func assertAppErrorCode(t *testing.T, err error, code int) {
+	t.Helper()
+	appErr, ok := err.(*apperrors.AppError)
+	require.True(t, ok, "expected AppError, got %v", err)
+	assert.Equal(t, code, appErr.Code)
+}
@@ -2,19 +2,30 @@ package teacher
 
 import (
 	"context"
+	"strings"
+	"time"
 
+	"github.com/JustDoItBetter/FITS-backend/internal/common/access"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/conditional"
 	"github.com/JustDoItBetter/FITS-backend/internal/common/errors"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/lifecycle"
 	"github.com/JustDoItBetter/FITS-backend/internal/common/pagination"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/validation"
+	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
 	"github.com/JustDoItBetter/FITS-backend/pkg/database"
+	"github.com/JustDoItBetter/FITS-backend/pkg/logger"
 	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
 // Service handles business logic for teachers
 type Service struct {
-	repo     Repository
-	txMgr    *database.TransactionManager
-	validate *validator.Validate
+	repo        Repository
+	txMgr       *database.TransactionManager
+	validate    *validator.Validate
+	deleteHooks lifecycle.DeleteHooks
 }
 
 // NewService creates a new teacher service
@@ -35,7 +46,8 @@ func NewServiceWithTx(repo Repository, txMgr *database.TransactionManager) *Serv
 }
 
 // Create creates a new teacher
-func (s *Service) Create(ctx context.Context, req *CreateTeacherRequest) (*Teacher, error) {
+// Department admins may only create teachers of their department
+func (s *Service) Create(ctx context.Context, req *CreateTeacherRequest, viewer access.Viewer) (*Teacher, error) {
 	// Validate request
 	if err := s.validate.Struct(req); err != nil {
 		return nil, errors.ValidationError(err.Error())
@@ -43,6 +55,9 @@ func (s *Service) Create(ctx context.Context, req *CreateTeacherRequest) (*Teach
 
 	// Convert request to teacher entity
 	teacher := req.ToTeacher()
+	if err := checkDepartment(teacher.Department, viewer); err != nil {
+		return nil, err
+	}
 
 	// Create teacher in repository
 	if err := s.repo.Create(ctx, teacher); err != nil {
@@ -57,9 +72,41 @@ func (s *Service) GetByUUID(ctx context.Context, uuid string) (*Teacher, error)
 	return s.repo.GetByUUID(ctx, uuid)
 }
 
-// Update updates an existing teacher
-// Uses transactions to prevent race conditions between read and write operations
-func (s *Service) Update(ctx context.Context, uuid string, req *UpdateTeacherRequest) (*Teacher, error) {
+// GetForViewer retrieves a teacher the viewer may read
+// Teachers read their own record, students the record of their teacher, department admins the teachers
+// of their department and other admins every record
+func (s *Service) GetForViewer(ctx context.Context, uuid string, viewer access.Viewer) (*Teacher, error) {
+	teacher, err := s.repo.GetByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case viewer.IsDepartmentAdmin():
+		if err := checkDepartment(teacher.Department, viewer); err != nil {
+			return nil, err
+		}
+		return teacher, nil
+	case viewer.IsAdmin():
+		return teacher, nil
+	case viewer.Role == crypto.RoleTeacher && viewer.Owns(teacher.UUID):
+		return teacher, nil
+	case viewer.Role == crypto.RoleStudent && viewer.UserUUID != "":
+		assigned, err := s.repo.IsAssignedTeacher(ctx, viewer.UserUUID, teacher.UUID)
+		if err != nil {
+			return nil, err
+		}
+		if assigned {
+			return teacher, nil
+		}
+	}
+	return nil, access.Forbidden()
+}
+
+// Update updates an existing teacher if its version satisfies ifMatch
+// Uses transactions to prevent race conditions between read and write operations.
+// Department admins may only update teachers of their department and keep them there
+func (s *Service) Update(ctx context.Context, uuid string, ifMatch conditional.Precondition, req *UpdateTeacherRequest, viewer access.Viewer) (*Teacher, error) {
 	// Validate request
 	if err := s.validate.Struct(req); err != nil {
 		return nil, errors.ValidationError(err.Error())
@@ -76,6 +123,12 @@ func (s *Service) Update(ctx context.Context, uuid string, req *UpdateTeacherReq
 			if err != nil {
 				return nil, err
 			}
+			if err := checkUpdateDepartment(teacher, req, viewer); err != nil {
+				return nil, err
+			}
+			if err := ifMatch.Check("teacher", teacher.Version); err != nil {
+				return nil, err
+			}
 
 			// Update teacher fields
 			teacher.Update(req)
@@ -94,6 +147,12 @@ func (s *Service) Update(ctx context.Context, uuid string, req *UpdateTeacherReq
 	if err != nil {
 		return nil, err
 	}
+	if err := checkUpdateDepartment(teacher, req, viewer); err != nil {
+		return nil, err
+	}
+	if err := ifMatch.Check("teacher", teacher.Version); err != nil {
+		return nil, err
+	}
 
 	teacher.Update(req)
 
@@ -104,9 +163,160 @@ func (s *Service) Update(ctx context.Context, uuid string, req *UpdateTeacherReq
 	return teacher, nil
 }
 
-// Delete deletes a teacher by UUID
-func (s *Service) Delete(ctx context.Context, uuid string) error {
-	return s.repo.Delete(ctx, uuid)
+// Patch applies a JSON merge patch to a teacher if its version satisfies ifMatch
+// The merged teacher is validated as a whole, and only the changed columns are written
+func (s *Service) Patch(ctx context.Context, uuid string, ifMatch conditional.Precondition, req *PatchTeacherRequest, viewer access.Viewer) (*Teacher, error) {
+	if s.txMgr == nil {
+		return s.patch(ctx, s.repo, uuid, ifMatch, req, viewer)
+	}
+	return database.WithTransactionValue(ctx, s.txMgr, func(tx *gorm.DB) (*Teacher, error) {
+		return s.patch(ctx, s.repo.WithDB(tx), uuid, ifMatch, req, viewer)
+	})
+}
+
+// patch merges the patch into a copy of the teacher, so a rejected patch leaves the loaded teacher untouched
+func (s *Service) patch(ctx context.Context, repo Repository, uuid string, ifMatch conditional.Precondition, req *PatchTeacherRequest, viewer access.Viewer) (*Teacher, error) {
+	teacher, err := repo.GetByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkDepartment(teacher.Department, viewer); err != nil {
+		return nil, err
+	}
+	if err := ifMatch.Check("teacher", teacher.Version); err != nil {
+		return nil, err
+	}
+
+	merged := *teacher
+	columns, err := merged.Patch(req)
+	if err != nil {
+		return nil, err
+	}
+	if len(columns) == 0 {
+		return teacher, nil
+	}
+
+	if err := s.validate.Struct(&merged); err != nil {
+		return nil, errors.ValidationError(err.Error())
+	}
+	if err := checkDepartment(merged.Department, viewer); err != nil {
+		return nil, err
+	}
+
+	if err := repo.Patch(ctx, &merged, columns); err != nil {
+		return nil, err
+	}
+	return &merged, nil
+}
+
+// OnDelete registers hooks that run in the transaction deleting a teacher
+// Used to keep records of other domains, like the teacher's account, consistent
+func (s *Service) OnDelete(hooks ...lifecycle.DeleteHook) {
+	s.deleteHooks = append(s.deleteHooks, hooks...)
+}
+
+// Delete soft-deletes a teacher by UUID
+// The students of the teacher are reassigned to opts.ReassignTo or flagged as without teacher,
+// then the registered delete hooks run. Everything happens in one transaction.
+// The teacher is only deleted if its version satisfies ifMatch. Department admins may only delete
+// teachers of their department and reassign the students within it
+func (s *Service) Delete(ctx context.Context, teacherUUID string, ifMatch conditional.Precondition, opts DeleteOptions, viewer access.Viewer) error {
+	if opts.ReassignTo != "" {
+		if _, err := uuid.Parse(opts.ReassignTo); err != nil {
+			return errors.ValidationError("reassign_to must be a valid UUID")
+		}
+		if opts.ReassignTo == teacherUUID {
+			return errors.ValidationError("students can't be reassigned to the deleted teacher")
+		}
+	}
+
+	if s.txMgr == nil {
+		if len(s.deleteHooks) > 0 {
+			return errors.Internal("delete hooks require transaction support")
+		}
+		_, err := s.deleteTeacher(ctx, s.repo, teacherUUID, ifMatch, opts, viewer)
+		return err
+	}
+
+	var students int64
+	err := s.txMgr.WithTransaction(ctx, func(tx *gorm.DB) error {
+		var err error
+		students, err = s.deleteTeacher(ctx, s.repo.WithDB(tx), teacherUUID, ifMatch, opts, viewer)
+		if err != nil {
+			return err
+		}
+		return s.deleteHooks.Run(ctx, tx, teacherUUID)
+	})
+	if err != nil {
+		return err
+	}
+
+	if students > 0 {
+		logger.Info("Students of deleted teacher updated",
+			zap.String("teacher_uuid", teacherUUID),
+			zap.String("reassigned_to", opts.ReassignTo),
+			zap.Int64("students", students),
+		)
+	}
+	return nil
+}
+
+// deleteTeacher deletes the teacher and reassigns or unassigns its students
+// Returns the number of students that were updated
+func (s *Service) deleteTeacher(ctx context.Context, repo Repository, teacherUUID string, ifMatch conditional.Precondition, opts DeleteOptions, viewer access.Viewer) (int64, error) {
+	teacher, err := repo.GetByUUID(ctx, teacherUUID)
+	if err != nil {
+		return 0, err
+	}
+	if err := checkDepartment(teacher.Department, viewer); err != nil {
+		return 0, err
+	}
+	if err := ifMatch.Check("teacher", teacher.Version); err != nil {
+		return 0, err
+	}
+
+	if opts.ReassignTo != "" {
+		// Locks the new teacher against concurrent deletion until the transaction ends
+		target, err := repo.GetActiveTeacher(ctx, opts.ReassignTo)
+		if err != nil {
+			return 0, err
+		}
+		if target == nil {
+			return 0, errors.ValidationError("reassign_to teacher does not exist or has been deleted")
+		}
+		if err := checkDepartment(target.Department, viewer); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := repo.Delete(ctx, teacherUUID, teacher.Version); err != nil {
+		return 0, err
+	}
+
+	if opts.ReassignTo != "" {
+		return repo.ReassignStudents(ctx, teacherUUID, opts.ReassignTo)
+	}
+	return repo.UnassignStudents(ctx, teacherUUID)
+}
+
+// ListDeleted retrieves a page of the teachers in the trash, most recently deleted first
+func (s *Service) ListDeleted(ctx context.Context, params pagination.Params) ([]*Teacher, int64, error) {
+	return s.repo.ListDeleted(ctx, params)
+}
+
+// Restore takes a teacher out of the trash
+// The students moved away by the deletion are not assigned back, and the teacher's account stays disabled
+func (s *Service) Restore(ctx context.Context, teacherUUID string) (*Teacher, error) {
+	if err := s.repo.Restore(ctx, teacherUUID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByUUID(ctx, teacherUUID)
+}
+
+// PurgeDeleted permanently removes the teachers deleted before the given time
+// Used as lifecycle.PurgeFunc once the retention time of the trash has passed
+func (s *Service) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	return s.repo.PurgeDeleted(ctx, before)
 }
 
 // List retrieves all teachers (deprecated: use ListPaginated)
@@ -114,8 +324,70 @@ func (s *Service) List(ctx context.Context) ([]*Teacher, error) {
 	return s.repo.List(ctx)
 }
 
-// ListPaginated retrieves teachers with pagination
-// Returns teachers slice, total count, and error
-func (s *Service) ListPaginated(ctx context.Context, params pagination.Params) ([]*Teacher, int64, error) {
-	return s.repo.ListPaginated(ctx, params)
+// ListPaginated retrieves the teachers the viewer may see matching the filter with pagination
+// Admins see every teacher or those of their department, teachers their own record and students their assigned teacher
+// Keyset pagination only supports sorting by created_at
+// Returns teachers slice, total count and cursors, and error
+func (s *Service) ListPaginated(ctx context.Context, params pagination.Params, filter ListFilter, viewer access.Viewer) ([]*Teacher, pagination.PageInfo, error) {
+	scope, filter, err := listQuery(filter, viewer)
+	if err != nil {
+		return nil, pagination.PageInfo{}, err
+	}
+	if _, ok := pagination.KeysetSort(filter.Sort); params.Keyset && !ok {
+		return nil, pagination.PageInfo{}, errors.ValidationError("cursor pagination only supports sort=created_at or sort=-created_at")
+	}
+	return s.repo.ListPaginated(ctx, params, scope, filter)
+}
+
+// listQuery returns the scope of the viewer and the checked filter of a list or export
+func listQuery(filter ListFilter, viewer access.Viewer) (ListScope, ListFilter, error) {
+	scope, err := listScope(viewer)
+	if err != nil {
+		return ListScope{}, filter, err
+	}
+
+	filter.Search = strings.TrimSpace(filter.Search)
+	filter.Department = strings.TrimSpace(filter.Department)
+	if filter.CreatedFrom != nil && filter.CreatedTo != nil && filter.CreatedFrom.After(*filter.CreatedTo) {
+		return ListScope{}, filter, errors.ValidationError("created_from must not be after created_to")
+	}
+	return scope, filter, nil
+}
+
+// listScope returns the teachers the viewer may list
+// An account without linked record or department gets the zero scope and sees no teachers
+func listScope(viewer access.Viewer) (ListScope, error) {
+	switch viewer.Role {
+	case crypto.RoleAdmin:
+		if viewer.IsDepartmentAdmin() {
+			return ListScope{Department: strings.TrimSpace(viewer.Department)}, nil
+		}
+		return ListScope{All: true}, nil
+	case crypto.RoleTeacher:
+		return ListScope{UUID: viewer.UserUUID}, nil
+	case crypto.RoleStudent:
+		return ListScope{StudentID: viewer.UserUUID}, nil
+	}
+	return ListScope{}, errors.Unauthorized("authentication required")
+}
+
+// checkDepartment checks that the viewer may manage a teacher of the department
+// Department admins only manage the teachers of their department, other viewers aren't limited here
+func checkDepartment(department string, viewer access.Viewer) error {
+	if viewer.IsDepartmentAdmin() && !viewer.InDepartment(department) {
+		return access.OutsideDepartment()
+	}
+	return nil
+}
+
+// checkUpdateDepartment checks the department of the teacher before and after the update
+// Runs before the update is applied, so a rejected update leaves the loaded teacher untouched
+func checkUpdateDepartment(teacher *Teacher, req *UpdateTeacherRequest, viewer access.Viewer) error {
+	if err := checkDepartment(teacher.Department, viewer); err != nil {
+		return err
+	}
+	if req.Department != "" {
+		return checkDepartment(validation.SanitizeName(req.Department), viewer)
+	}
+	return nil
 }
@@ -6,10 +6,16 @@ import (
 	"testing"
 	"time"
 
+	"github.com/JustDoItBetter/FITS-backend/internal/common/access"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/conditional"
 	apperrors "github.com/JustDoItBetter/FITS-backend/internal/common/errors"
 	"github.com/JustDoItBetter/FITS-backend/internal/common/pagination"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/patch"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/reference"
+	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
 )
 
@@ -36,11 +42,34 @@ func (m *MockRepository) Update(ctx context.Context, teacher *Teacher) error {
 	return args.Error(0)
 }
 
-func (m *MockRepository) Delete(ctx context.Context, uuid string) error {
+func (m *MockRepository) Patch(ctx context.Context, teacher *Teacher, columns []string) error {
+	args := m.Called(ctx, teacher, columns)
+	return args.Error(0)
+}
+
+func (m *MockRepository) Delete(ctx context.Context, uuid string, version int) error {
+	args := m.Called(ctx, uuid, version)
+	return args.Error(0)
+}
+
+func (m *MockRepository) ListDeleted(ctx context.Context, params pagination.Params) ([]*Teacher, int64, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*Teacher), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockRepository) Restore(ctx context.Context, uuid string) error {
 	args := m.Called(ctx, uuid)
 	return args.Error(0)
 }
 
+func (m *MockRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (m *MockRepository) List(ctx context.Context) ([]*Teacher, error) {
 	args := m.Called(ctx)
 	if args.Get(0) == nil {
@@ -49,12 +78,43 @@ func (m *MockRepository) List(ctx context.Context) ([]*Teacher, error) {
 	return args.Get(0).([]*Teacher), args.Error(1)
 }
 
-func (m *MockRepository) ListPaginated(ctx context.Context, params pagination.Params) ([]*Teacher, int64, error) {
-	args := m.Called(ctx, params)
+func (m *MockRepository) ListPaginated(ctx context.Context, params pagination.Params, scope ListScope, filter ListFilter) ([]*Teacher, pagination.PageInfo, error) {
+	args := m.Called(ctx, params, scope, filter)
 	if args.Get(0) == nil {
-		return nil, args.Get(1).(int64), args.Error(2)
+		return nil, args.Get(1).(pagination.PageInfo), args.Error(2)
 	}
-	return args.Get(0).([]*Teacher), args.Get(1).(int64), args.Error(2)
+	return args.Get(0).([]*Teacher), args.Get(1).(pagination.PageInfo), args.Error(2)
+}
+
+func (m *MockRepository) GetByEmails(ctx context.Context, emails []string) (map[string]*Teacher, error) {
+	args := m.Called(ctx, emails)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]*Teacher), args.Error(1)
+}
+
+func (m *MockRepository) GetActiveTeacher(ctx context.Context, uuid string) (*reference.Teacher, error) {
+	args := m.Called(ctx, uuid)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*reference.Teacher), args.Error(1)
+}
+
+func (m *MockRepository) ReassignStudents(ctx context.Context, fromUUID, toUUID string) (int64, error) {
+	args := m.Called(ctx, fromUUID, toUUID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRepository) UnassignStudents(ctx context.Context, teacherUUID string) (int64, error) {
+	args := m.Called(ctx, teacherUUID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRepository) IsAssignedTeacher(ctx context.Context, studentUUID, teacherUUID string) (bool, error) {
+	args := m.Called(ctx, studentUUID, teacherUUID)
+	return args.Bool(0), args.Error(1)
 }
 
 func (m *MockRepository) WithDB(db *gorm.DB) Repository {
@@ -62,9 +122,13 @@ func (m *MockRepository) WithDB(db *gorm.DB) Repository {
 }
 
 // Helper function to create a valid teacher
+// fullAdmin is a viewer that may manage every teacher
+var fullAdmin = access.Viewer{UserID: "admin-1", Role: crypto.RoleAdmin, AdminScope: crypto.AdminScopeFull}
+
 func createValidTeacher() *Teacher {
 	return &Teacher{
 		UUID:       "550e8400-e29b-41d4-a716-446655440010",
+		Version:    3,
 		FirstName:  "Anna",
 		LastName:   "Schmidt",
 		Email:      "anna@example.com",
@@ -213,7 +277,7 @@ func TestCreate(t *testing.T) {
 			service := NewService(mockRepo)
 
 			// Execute
-			teacher, err := service.Create(context.Background(), tt.request)
+			teacher, err := service.Create(context.Background(), tt.request, fullAdmin)
 
 			// Assert
 			if tt.expectError {
@@ -298,6 +362,157 @@ func TestGetByUUID(t *testing.T) {
 	}
 }
 
+// TestGetForViewer tests which teachers a viewer may read
+func TestGetForViewer(t *testing.T) {
+	const teacherUUID = "550e8400-e29b-41d4-a716-446655440010"
+	const studentUUID = "550e8400-e29b-41d4-a716-446655440001"
+
+	tests := []struct {
+		name       string
+		viewer     access.Viewer
+		setupMock  func(*MockRepository)
+		wantStatus int
+	}{
+		{
+			name:   "admin reads any teacher",
+			viewer: access.Viewer{UserID: "admin-1", Role: crypto.RoleAdmin, AdminScope: crypto.AdminScopeFull},
+		},
+		{
+			name:   "teacher reads own record",
+			viewer: access.Viewer{UserID: "user-1", Role: crypto.RoleTeacher, UserUUID: teacherUUID},
+		},
+		{
+			name:       "teacher can't read another teacher",
+			viewer:     access.Viewer{UserID: "user-2", Role: crypto.RoleTeacher, UserUUID: "other-teacher"},
+			wantStatus: 403,
+		},
+		{
+			name:   "student reads assigned teacher",
+			viewer: access.Viewer{UserID: "user-3", Role: crypto.RoleStudent, UserUUID: studentUUID},
+			setupMock: func(m *MockRepository) {
+				m.On("IsAssignedTeacher", mock.Anything, studentUUID, teacherUUID).Return(true, nil)
+			},
+		},
+		{
+			name:   "student can't read other teachers",
+			viewer: access.Viewer{UserID: "user-3", Role: crypto.RoleStudent, UserUUID: studentUUID},
+			setupMock: func(m *MockRepository) {
+				m.On("IsAssignedTeacher", mock.Anything, studentUUID, teacherUUID).Return(false, nil)
+			},
+			wantStatus: 403,
+		},
+		{
+			name:       "account without linked record",
+			viewer:     access.Viewer{UserID: "user-4", Role: crypto.RoleStudent},
+			wantStatus: 403,
+		},
+		{
+			name:   "department admin reads teachers of the department",
+			viewer: access.Viewer{UserID: "admin-2", Role: crypto.RoleAdmin, AdminScope: crypto.AdminScopeDepartment, Department: "computer science"},
+		},
+		{
+			name:       "department admin can't read teachers of other departments",
+			viewer:     access.Viewer{UserID: "admin-3", Role: crypto.RoleAdmin, AdminScope: crypto.AdminScopeDepartment, Department: "HR"},
+			wantStatus: 403,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			mockRepo.On("GetByUUID", mock.Anything, teacherUUID).Return(createValidTeacher(), nil)
+			if tt.setupMock != nil {
+				tt.setupMock(mockRepo)
+			}
+			service := NewService(mockRepo)
+
+			teacher, err := service.GetForViewer(context.Background(), teacherUUID, tt.viewer)
+
+			if tt.wantStatus != 0 {
+				var appErr *apperrors.AppError
+				assert.ErrorAs(t, err, &appErr)
+				assert.Equal(t, tt.wantStatus, appErr.Code)
+				assert.Nil(t, teacher)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, teacherUUID, teacher.UUID)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+// TestDepartmentAdmin tests that department admins only manage teachers of their department
+func TestDepartmentAdmin(t *testing.T) {
+	ctx := context.Background()
+	const (
+		itTeacher = "550e8400-e29b-41d4-a716-446655440901"
+		hrTeacher = "550e8400-e29b-41d4-a716-446655440902"
+	)
+	viewer := access.Viewer{UserID: "admin-2", Role: crypto.RoleAdmin, AdminScope: crypto.AdminScopeDepartment, Department: "IT"}
+
+	setup := func(t *testing.T) (*Service, *InMemoryRepository) {
+		repo := NewInMemoryRepository()
+		for uuid, department := range map[string]string{itTeacher: "IT", hrTeacher: "HR"} {
+			require.NoError(t, repo.Create(ctx, &Teacher{
+				UUID: uuid, FirstName: "Anna", LastName: "Schmidt", Email: uuid + "@example.com", Department: department,
+			}))
+		}
+		return NewService(repo), repo
+	}
+	requireForbidden := func(t *testing.T, err error) {
+		var appErr *apperrors.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, 403, appErr.Code)
+	}
+
+	t.Run("lists only the department's teachers", func(t *testing.T) {
+		service, _ := setup(t)
+
+		teachers, info, err := service.ListPaginated(ctx, pagination.Params{Page: 1, Limit: 10}, ListFilter{}, viewer)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), info.TotalCount)
+		require.Len(t, teachers, 1)
+		assert.Equal(t, itTeacher, teachers[0].UUID)
+
+		teachers, _, err = service.ListPaginated(ctx, pagination.Params{Page: 1, Limit: 10}, ListFilter{}, access.Viewer{Role: crypto.RoleAdmin, AdminScope: crypto.AdminScopeDepartment})
+		require.NoError(t, err)
+		assert.Empty(t, teachers, "a department admin without department sees no teachers")
+	})
+
+	t.Run("can't write teachers of other departments", func(t *testing.T) {
+		service, repo := setup(t)
+
+		_, err := service.Update(ctx, hrTeacher, conditional.Any, &UpdateTeacherRequest{FirstName: "Tom"}, viewer)
+		requireForbidden(t, err)
+		_, err = service.Patch(ctx, hrTeacher, conditional.Any, &PatchTeacherRequest{FirstName: patch.Value("Tom")}, viewer)
+		requireForbidden(t, err)
+		requireForbidden(t, service.Delete(ctx, hrTeacher, conditional.Any, DeleteOptions{}, viewer))
+		requireForbidden(t, service.Delete(ctx, itTeacher, conditional.Any, DeleteOptions{ReassignTo: hrTeacher}, viewer))
+
+		teacher, err := repo.GetByUUID(ctx, hrTeacher)
+		require.NoError(t, err)
+		assert.Equal(t, "Anna", teacher.FirstName)
+		_, err = repo.GetByUUID(ctx, itTeacher)
+		assert.NoError(t, err, "a rejected reassignment keeps the teacher")
+	})
+
+	t.Run("can't move teachers out of the department", func(t *testing.T) {
+		service, _ := setup(t)
+
+		_, err := service.Update(ctx, itTeacher, conditional.Any, &UpdateTeacherRequest{Department: "HR"}, viewer)
+		requireForbidden(t, err)
+		_, err = service.Patch(ctx, itTeacher, conditional.Any, &PatchTeacherRequest{Department: patch.Value("HR")}, viewer)
+		requireForbidden(t, err)
+		_, err = service.Create(ctx, &CreateTeacherRequest{FirstName: "Tom", LastName: "Becker", Email: "tom@example.com", Department: "HR"}, viewer)
+		requireForbidden(t, err)
+
+		teacher, err := service.Update(ctx, itTeacher, conditional.Any, &UpdateTeacherRequest{FirstName: "Tom", Department: "it"}, viewer)
+		require.NoError(t, err)
+		assert.Equal(t, "Tom", teacher.FirstName)
+	})
+}
+
 // TestUpdate tests the Update method
 func TestUpdate(t *testing.T) {
 	tests := []struct {
@@ -399,7 +614,7 @@ func TestUpdate(t *testing.T) {
 			service := NewService(mockRepo)
 
 			// Execute
-			teacher, err := service.Update(context.Background(), tt.uuid, tt.request)
+			teacher, err := service.Update(context.Background(), tt.uuid, conditional.Any, tt.request, fullAdmin)
 
 			// Assert
 			if tt.expectError {
@@ -427,36 +642,136 @@ func TestUpdate(t *testing.T) {
 	}
 }
 
+// TestUpdate_IfMatch tests that updates based on another version of the teacher are rejected
+func TestUpdate_IfMatch(t *testing.T) {
+	ctx := context.Background()
+	request := &UpdateTeacherRequest{FirstName: "Moritz"}
+
+	t.Run("matching version", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetByUUID", ctx, "550e8400-e29b-41d4-a716-446655440010").Return(createValidTeacher(), nil)
+		mockRepo.On("Update", ctx, mock.Anything).Return(nil)
+
+		_, err := NewService(mockRepo).Update(ctx, "550e8400-e29b-41d4-a716-446655440010", conditional.Version(3), request, fullAdmin)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("stale version", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetByUUID", ctx, "550e8400-e29b-41d4-a716-446655440010").Return(createValidTeacher(), nil)
+
+		_, err := NewService(mockRepo).Update(ctx, "550e8400-e29b-41d4-a716-446655440010", conditional.Version(2), request, fullAdmin)
+
+		assertAppErrorCode(t, err, 412)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+}
+
+// TestPatch tests applying merge patches to a teacher
+func TestPatch(t *testing.T) {
+	const teacherUUID = "550e8400-e29b-41d4-a716-446655440010"
+	tests := []struct {
+		name        string
+		request     *PatchTeacherRequest
+		wantColumns []string // nil if nothing is written
+		errorCode   int
+	}{
+		{
+			name:        "changed fields only",
+			request:     &PatchTeacherRequest{FirstName: patch.Value("Anna"), Department: patch.Value("Mathematics")},
+			wantColumns: []string{"department"},
+		},
+		{
+			name:    "unchanged values aren't written",
+			request: &PatchTeacherRequest{Email: patch.Value("anna@example.com")},
+		},
+		{
+			name:      "null for a required field",
+			request:   &PatchTeacherRequest{Department: patch.Null[string]()},
+			errorCode: 422,
+		},
+		{
+			name:      "invalid merged email",
+			request:   &PatchTeacherRequest{Email: patch.Value("invalid-email")},
+			errorCode: 422,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			mockRepo.On("GetByUUID", mock.Anything, teacherUUID).Return(createValidTeacher(), nil)
+			if tt.wantColumns != nil {
+				mockRepo.On("Patch", mock.Anything, mock.Anything, tt.wantColumns).Return(nil)
+			}
+
+			teacher, err := NewService(mockRepo).Patch(context.Background(), teacherUUID, conditional.Version(3), tt.request, fullAdmin)
+
+			if tt.errorCode != 0 {
+				assertAppErrorCode(t, err, tt.errorCode)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, teacher)
+			}
+			if tt.wantColumns == nil {
+				mockRepo.AssertNotCalled(t, "Patch", mock.Anything, mock.Anything, mock.Anything)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
 // TestDelete tests the Delete method
 func TestDelete(t *testing.T) {
 	tests := []struct {
 		name        string
 		uuid        string
+		ifMatch     conditional.Precondition
 		setupMock   func(*MockRepository)
 		expectError bool
+		errorCode   int
 	}{
 		{
-			name: "successful delete",
-			uuid: "550e8400-e29b-41d4-a716-446655440010",
+			name:    "successful delete",
+			uuid:    "550e8400-e29b-41d4-a716-446655440010",
+			ifMatch: conditional.Version(1),
 			setupMock: func(m *MockRepository) {
-				m.On("Delete", mock.Anything, "550e8400-e29b-41d4-a716-446655440010").Return(nil)
+				m.On("GetByUUID", mock.Anything, "550e8400-e29b-41d4-a716-446655440010").Return(&Teacher{UUID: "550e8400-e29b-41d4-a716-446655440010", Version: 1}, nil)
+				m.On("Delete", mock.Anything, "550e8400-e29b-41d4-a716-446655440010", 1).Return(nil)
+				m.On("UnassignStudents", mock.Anything, "550e8400-e29b-41d4-a716-446655440010").Return(int64(2), nil)
 			},
 			expectError: false,
 		},
 		{
-			name: "teacher not found",
-			uuid: "nonexistent-uuid",
+			name:    "teacher not found",
+			uuid:    "nonexistent-uuid",
+			ifMatch: conditional.Any,
 			setupMock: func(m *MockRepository) {
-				m.On("Delete", mock.Anything, "nonexistent-uuid").
-					Return(apperrors.NotFound("teacher not found"))
+				m.On("GetByUUID", mock.Anything, "nonexistent-uuid").
+					Return(nil, apperrors.NotFound("teacher not found"))
 			},
 			expectError: true,
+			errorCode:   404,
 		},
 		{
-			name: "repository error",
-			uuid: "550e8400-e29b-41d4-a716-446655440010",
+			name:    "version mismatch",
+			uuid:    "550e8400-e29b-41d4-a716-446655440010",
+			ifMatch: conditional.Version(1),
+			setupMock: func(m *MockRepository) {
+				m.On("GetByUUID", mock.Anything, "550e8400-e29b-41d4-a716-446655440010").Return(&Teacher{UUID: "550e8400-e29b-41d4-a716-446655440010", Version: 2}, nil)
+			},
+			expectError: true,
+			errorCode:   412,
+		},
+		{
+			name:    "repository error",
+			uuid:    "550e8400-e29b-41d4-a716-446655440010",
+			ifMatch: conditional.Any,
 			setupMock: func(m *MockRepository) {
-				m.On("Delete", mock.Anything, "550e8400-e29b-41d4-a716-446655440010").
+				m.On("GetByUUID", mock.Anything, "550e8400-e29b-41d4-a716-446655440010").Return(&Teacher{UUID: "550e8400-e29b-41d4-a716-446655440010", Version: 3}, nil)
+				m.On("Delete", mock.Anything, "550e8400-e29b-41d4-a716-446655440010", 3).
 					Return(errors.New("database error"))
 			},
 			expectError: true,
@@ -471,11 +786,14 @@ func TestDelete(t *testing.T) {
 			service := NewService(mockRepo)
 
 			// Execute
-			err := service.Delete(context.Background(), tt.uuid)
+			err := service.Delete(context.Background(), tt.uuid, tt.ifMatch, DeleteOptions{}, fullAdmin)
 
 			// Assert
 			if tt.expectError {
 				assert.Error(t, err)
+				if tt.errorCode != 0 {
+					assertAppErrorCode(t, err, tt.errorCode)
+				}
 			} else {
 				assert.NoError(t, err)
 			}
@@ -485,6 +803,69 @@ func TestDelete(t *testing.T) {
 	}
 }
 
+// TestDelete_ReassignTo tests moving the students of a deleted teacher to another teacher
+func TestDelete_ReassignTo(t *testing.T) {
+	ctx := context.Background()
+	teacherUUID := "550e8400-e29b-41d4-a716-446655440010"
+	targetUUID := "550e8400-e29b-41d4-a716-446655440011"
+
+	t.Run("reassigns students", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetByUUID", ctx, teacherUUID).Return(&Teacher{UUID: teacherUUID, Version: 1}, nil)
+		mockRepo.On("GetActiveTeacher", ctx, targetUUID).Return(&reference.Teacher{UUID: targetUUID}, nil)
+		mockRepo.On("Delete", ctx, teacherUUID, 1).Return(nil)
+		mockRepo.On("ReassignStudents", ctx, teacherUUID, targetUUID).Return(int64(3), nil)
+
+		err := NewService(mockRepo).Delete(ctx, teacherUUID, conditional.Any, DeleteOptions{ReassignTo: targetUUID}, fullAdmin)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "UnassignStudents", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects deleted target teacher", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetByUUID", ctx, teacherUUID).Return(&Teacher{UUID: teacherUUID, Version: 1}, nil)
+		mockRepo.On("GetActiveTeacher", ctx, targetUUID).Return(nil, nil)
+
+		err := NewService(mockRepo).Delete(ctx, teacherUUID, conditional.Any, DeleteOptions{ReassignTo: targetUUID}, fullAdmin)
+
+		assertValidationError(t, err)
+		mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects invalid target", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo)
+
+		assertValidationError(t, service.Delete(ctx, teacherUUID, conditional.Any, DeleteOptions{ReassignTo: "not-a-uuid"}, fullAdmin))
+		assertValidationError(t, service.Delete(ctx, teacherUUID, conditional.Any, DeleteOptions{ReassignTo: teacherUUID}, fullAdmin))
+		mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// TestDelete_HooksRequireTransaction tests that hooks are never run outside a transaction
+func TestDelete_HooksRequireTransaction(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+	service.OnDelete(func(ctx context.Context, tx *gorm.DB, uuid string) error { return nil })
+
+	err := service.Delete(context.Background(), "550e8400-e29b-41d4-a716-446655440010", conditional.Any, DeleteOptions{}, fullAdmin)
+
+	var appErr *apperrors.AppError
+	assert.True(t, errors.As(err, &appErr))
+	assert.Equal(t, 500, appErr.Code)
+	mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func assertValidationError(t *testing.T, err error) {
+	t.Helper()
+	var appErr *apperrors.AppError
+	if assert.True(t, errors.As(err, &appErr)) {
+		assert.Equal(t, 422, appErr.Code)
+	}
+}
+
 // TestList tests the List method
 func TestList(t *testing.T) {
 	tests := []struct {
@@ -555,3 +936,53 @@ func TestList(t *testing.T) {
 		})
 	}
 }
+
+// TestListPaginated tests that lists are scoped to the teachers the viewer may see
+func TestListPaginated(t *testing.T) {
+	params := pagination.Params{Page: 1, Limit: 20}
+
+	tests := []struct {
+		name      string
+		viewer    access.Viewer
+		wantScope ListScope
+	}{
+		{
+			name:      "admin lists every teacher",
+			viewer:    access.Viewer{UserID: "admin-1", Role: crypto.RoleAdmin, AdminScope: crypto.AdminScopeAuditor},
+			wantScope: ListScope{All: true},
+		},
+		{
+			name:      "teacher lists own record",
+			viewer:    access.Viewer{UserID: "user-1", Role: crypto.RoleTeacher, UserUUID: "teacher-1"},
+			wantScope: ListScope{UUID: "teacher-1"},
+		},
+		{
+			name:      "student lists assigned teacher",
+			viewer:    access.Viewer{UserID: "user-2", Role: crypto.RoleStudent, UserUUID: "student-1"},
+			wantScope: ListScope{StudentID: "student-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			mockRepo.On("ListPaginated", mock.Anything, params, tt.wantScope, ListFilter{}).Return([]*Teacher{}, pagination.PageInfo{}, nil)
+			service := NewService(mockRepo)
+
+			_, _, err := service.ListPaginated(context.Background(), params, ListFilter{}, tt.viewer)
+
+			assert.NoError(t, err)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+
+	t.Run("anonymous caller", func(t *testing.T) {
+		service := NewService(new(MockRepository))
+
+		_, _, err := service.ListPaginated(context.Background(), params, ListFilter{}, access.Viewer{})
+
+		var appErr *apperrors.AppError
+		assert.ErrorAs(t, err, &appErr)
+		assert.Equal(t, 401, appErr.Code)
+	})
+}
@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"github.com/JustDoItBetter/FITS-backend/internal/common/errors"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/response"
 	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
 	"github.com/gofiber/fiber/v2"
 )
@@ -9,6 +11,7 @@ import (
 // This allows handlers to register routes with their security requirements in one place
 type MiddlewareAdapter struct {
 	jwtMiddleware *JWTMiddleware
+	permissions   PermissionResolver
 }
 
 // NewMiddlewareAdapter creates a new middleware adapter
@@ -18,6 +21,12 @@ func NewMiddlewareAdapter(jwtMiddleware *JWTMiddleware) *MiddlewareAdapter {
 	}
 }
 
+// WithPermissions sets the resolver RequirePermission looks up the permissions of roles with
+func (m *MiddlewareAdapter) WithPermissions(resolver PermissionResolver) *MiddlewareAdapter {
+	m.permissions = resolver
+	return m
+}
+
 // RequireAuth returns the JWT authentication middleware
 func (m *MiddlewareAdapter) RequireAuth() fiber.Handler {
 	return m.jwtMiddleware.RequireAuth()
@@ -33,20 +42,23 @@ func (m *MiddlewareAdapter) RequireAdmin() fiber.Handler {
 	return RequireAdmin()
 }
 
+// RequirePermission returns middleware that requires a permission, e.g. student:write
+// Without permission resolver every request is rejected
+func (m *MiddlewareAdapter) RequirePermission(permission string) fiber.Handler {
+	if m.permissions == nil {
+		return func(c *fiber.Ctx) error {
+			return response.Error(c, errors.Internal("permission checks are not configured"))
+		}
+	}
+	return RequirePermission(m.permissions, crypto.Permission(permission))
+}
+
 // RequireRole returns middleware that requires one of the specified roles
-func (m *MiddlewareAdapter) RequireRole(roles ...interface{}) fiber.Handler {
-	// Convert interface{} to crypto.Role
+// Prefer RequirePermission, which follows the permissions stored for each role
+func (m *MiddlewareAdapter) RequireRole(roles ...string) fiber.Handler {
 	cryptoRoles := make([]crypto.Role, len(roles))
 	for i, role := range roles {
-		switch v := role.(type) {
-		case crypto.Role:
-			cryptoRoles[i] = v
-		case string:
-			cryptoRoles[i] = crypto.Role(v)
-		default:
-			// Fallback to admin if type is unexpected
-			cryptoRoles[i] = crypto.RoleAdmin
-		}
+		cryptoRoles[i] = crypto.Role(role)
 	}
 	return RequireRole(cryptoRoles...)
 }
@@ -1,51 +1,60 @@
 package middleware
 
 import (
-	"strings"
+	"context"
 
+	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
 	"github.com/gofiber/fiber/v2"
 )
 
-func SecretAuth(expectedSecret string) fiber.Handler {
-	return func(c *fiber.Ctx) error {
-		authHeader := c.Get("Authorization")
-		if authHeader == "" {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "missing authorization header",
-			})
-		}
-
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "invalid authorization format, expected: Bearer <token>",
-			})
-		}
-
-		token := parts[1]
-
-		if token != expectedSecret {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "invalid secret",
-			})
-		}
-
-		return c.Next()
-	}
+// APIKeyValidator resolves service account API keys
+type APIKeyValidator interface {
+	// ValidateAPIKey returns the service account of a valid, unexpired and unrevoked key
+	// and records its use from ipAddress
+	ValidateAPIKey(ctx context.Context, key, ipAddress string) (*crypto.APIKeyPrincipal, error)
 }
 
-func MetricsAuth(secret string) fiber.Handler {
-	return SecretAuth(secret)
+// AccountValidator checks that the account behind a token may still use it
+type AccountValidator interface {
+	// ValidateAccount returns an error if the account was deleted or disabled
+	// or no longer has the role or admin scope the token was issued for
+	ValidateAccount(ctx context.Context, claims *crypto.Claims) error
 }
 
-func RegistrationAuth(secret string) fiber.Handler {
-	return SecretAuth(secret)
+// WithAccountCheck makes RequireAuth and OptionalAuth look up the account of every token,
+// so disabling an account or changing its role or admin scope takes effect before its tokens expire
+func (m *JWTMiddleware) WithAccountCheck(validator AccountValidator) *JWTMiddleware {
+	m.accounts = validator
+	return m
 }
 
-func DeletionAuth(secret string) fiber.Handler {
-	return SecretAuth(secret)
+// WithAPIKeys makes RequireAuth and OptionalAuth also accept service account API keys
+func (m *JWTMiddleware) WithAPIKeys(validator APIKeyValidator) *JWTMiddleware {
+	m.apiKeys = validator
+	return m
 }
 
-func UpdateAuth(secret string) fiber.Handler {
-	return SecretAuth(secret)
+// authenticateAPIKey validates a bearer token formatted as an API key and stores the service account in the context
+// Service accounts have no user ID, handlers see them as role "service" with the granted scopes
+func (m *JWTMiddleware) authenticateAPIKey(c *fiber.Ctx, key string) error {
+	principal, err := m.apiKeys.ValidateAPIKey(c.Context(), key, c.IP())
+	if err != nil {
+		return err
+	}
+
+	c.Locals("role", crypto.RoleService)
+	c.Locals("token_type", crypto.TokenTypeAPIKey)
+	c.Locals("api_key_id", principal.KeyID)
+	c.Locals("scopes", principal.Scopes)
+
+	return nil
+}
+
+// isAPIKey reports whether token should be validated as an API key
+func (m *JWTMiddleware) isAPIKey(token string) bool {
+	if m.apiKeys == nil {
+		return false
+	}
+	_, ok := crypto.ParseAPIKey(token)
+	return ok
 }
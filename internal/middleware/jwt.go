@@ -3,6 +3,7 @@ package middleware
 import (
 	"strings"
 
+	"github.com/JustDoItBetter/FITS-backend/internal/common/response"
 	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
 	"github.com/gofiber/fiber/v2"
 )
@@ -10,6 +11,8 @@ import (
 // JWTMiddleware creates a middleware that validates JWT tokens
 type JWTMiddleware struct {
 	jwtService *crypto.JWTService
+	apiKeys    APIKeyValidator  // nil unless API keys are accepted (see WithAPIKeys)
+	accounts   AccountValidator // nil unless accounts are checked per request (see WithAccountCheck)
 }
 
 // NewJWTMiddleware creates a new JWT middleware
@@ -20,7 +23,19 @@ func NewJWTMiddleware(jwtService *crypto.JWTService) *JWTMiddleware {
 }
 
 // RequireAuth is a middleware that requires valid JWT authentication
+// or a service account API key if enabled with WithAPIKeys
 func (m *JWTMiddleware) RequireAuth() fiber.Handler {
+	return m.requireToken(crypto.TokenTypeAccess, crypto.TokenTypeAdmin, crypto.TokenTypeAPIKey)
+}
+
+// RequireAuthOrMFA is like RequireAuth but also accepts the partial MFA token issued at login
+// Only use it for the 2FA enrollment endpoints, so users of roles that require 2FA can enroll before their first full login
+func (m *JWTMiddleware) RequireAuthOrMFA() fiber.Handler {
+	return m.requireToken(crypto.TokenTypeAccess, crypto.TokenTypeAdmin, crypto.TokenTypeMFA)
+}
+
+// requireToken builds an authentication middleware that accepts the given token types
+func (m *JWTMiddleware) requireToken(allowed ...crypto.TokenType) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		// Get Authorization header
 		authHeader := c.Get("Authorization")
@@ -42,6 +57,17 @@ func (m *JWTMiddleware) RequireAuth() fiber.Handler {
 
 		token := parts[1]
 
+		// API keys are a separate principal type, not a JWT
+		if isAllowedTokenType(crypto.TokenTypeAPIKey, allowed) && m.isAPIKey(token) {
+			if err := m.authenticateAPIKey(c, token); err != nil {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					"success": false,
+					"error":   "invalid or expired API key",
+				})
+			}
+			return c.Next()
+		}
+
 		// Validate token
 		claims, err := m.jwtService.ValidateToken(token)
 		if err != nil {
@@ -52,18 +78,31 @@ func (m *JWTMiddleware) RequireAuth() fiber.Handler {
 			})
 		}
 
-		// Check token type (should be access or admin token)
-		if claims.TokenType != crypto.TokenTypeAccess && claims.TokenType != crypto.TokenTypeAdmin {
+		// API keys are checked above, a JWT can't claim to be one
+		if claims.TokenType == crypto.TokenTypeAPIKey {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"success": false,
+				"error":   "invalid token type",
+			})
+		}
+
+		// Check token type
+		if !isAllowedTokenType(claims.TokenType, allowed) {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 				"success": false,
 				"error":   "invalid token type",
 			})
 		}
 
+		// Tokens of disabled or deleted accounts and tokens with an outdated role or admin scope are rejected
+		if m.accounts != nil {
+			if err := m.accounts.ValidateAccount(c.Context(), claims); err != nil {
+				return response.Error(c, err)
+			}
+		}
+
 		// Store claims in context for later use
-		c.Locals("user_id", claims.UserID)
-		c.Locals("role", claims.Role)
-		c.Locals("token_type", claims.TokenType)
+		setClaimsLocals(c, claims)
 
 		return c.Next()
 	}
@@ -86,17 +125,57 @@ func (m *JWTMiddleware) OptionalAuth() fiber.Handler {
 
 		token := parts[1]
 
+		if m.isAPIKey(token) {
+			_ = m.authenticateAPIKey(c, token) // Invalid key, but don't fail
+			return c.Next()
+		}
+
 		// Validate token
 		claims, err := m.jwtService.ValidateToken(token)
 		if err != nil {
 			return c.Next() // Invalid token, but don't fail
 		}
 
+		// Refresh and MFA tokens don't grant access to resources
+		if claims.TokenType != crypto.TokenTypeAccess && claims.TokenType != crypto.TokenTypeAdmin {
+			return c.Next()
+		}
+
+		if m.accounts != nil && m.accounts.ValidateAccount(c.Context(), claims) != nil {
+			return c.Next() // Account disabled or changed, but don't fail
+		}
+
 		// Store claims in context
-		c.Locals("user_id", claims.UserID)
-		c.Locals("role", claims.Role)
-		c.Locals("token_type", claims.TokenType)
+		setClaimsLocals(c, claims)
 
 		return c.Next()
 	}
 }
+
+// setClaimsLocals stores the claims of a valid token in the context
+// Admins also get their effective scope, so RequireAdmin can limit scoped admins
+func setClaimsLocals(c *fiber.Ctx, claims *crypto.Claims) {
+	c.Locals("user_id", claims.UserID)
+	c.Locals("role", claims.Role)
+	c.Locals("token_type", claims.TokenType)
+	c.Locals("session_id", claims.SessionID)
+	// Linked student or teacher record, ownership is checked against it
+	if claims.UserUUID != "" {
+		c.Locals("user_uuid", claims.UserUUID)
+	}
+
+	if scope := claims.EffectiveAdminScope(); scope != "" {
+		c.Locals("admin_scope", scope)
+		c.Locals("admin_department", claims.Department)
+	}
+}
+
+// isAllowedTokenType reports whether tokenType is one of the allowed types
+func isAllowedTokenType(tokenType crypto.TokenType, allowed []crypto.TokenType) bool {
+	for _, t := range allowed {
+		if tokenType == t {
+			return true
+		}
+	}
+	return false
+}
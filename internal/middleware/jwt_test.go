@@ -1,11 +1,14 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	apperrors "github.com/JustDoItBetter/FITS-backend/internal/common/errors"
 	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
@@ -278,6 +281,260 @@ func TestJWTMiddleware_OptionalAuth(t *testing.T) {
 	})
 }
 
+func TestJWTMiddleware_MFAToken(t *testing.T) {
+	jwtService := crypto.NewJWTService("test-secret")
+	middleware := NewJWTMiddleware(jwtService)
+
+	mfaToken, err := jwtService.GenerateToken("user-123", crypto.RoleTeacher, crypto.TokenTypeMFA, 5*time.Minute)
+	require.NoError(t, err)
+
+	t.Run("RequireAuth rejects MFA token", func(t *testing.T) {
+		app := setupTestApp()
+		app.Get("/protected", middleware.RequireAuth(), func(c *fiber.Ctx) error {
+			return c.SendString("success")
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+		req.Header.Set("Authorization", "Bearer "+mfaToken)
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("RequireAuthOrMFA accepts MFA token", func(t *testing.T) {
+		app := setupTestApp()
+
+		var tokenType interface{}
+		app.Get("/enroll", middleware.RequireAuthOrMFA(), func(c *fiber.Ctx) error {
+			tokenType = c.Locals("token_type")
+			return c.SendString("success")
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/enroll", nil)
+		req.Header.Set("Authorization", "Bearer "+mfaToken)
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, crypto.TokenTypeMFA, tokenType)
+	})
+
+	t.Run("OptionalAuth ignores MFA token", func(t *testing.T) {
+		app := setupTestApp()
+
+		var hasUserID bool
+		app.Get("/optional", middleware.OptionalAuth(), func(c *fiber.Ctx) error {
+			hasUserID = c.Locals("user_id") != nil
+			return c.SendString("success")
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/optional", nil)
+		req.Header.Set("Authorization", "Bearer "+mfaToken)
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.False(t, hasUserID)
+	})
+}
+
+// stubAPIKeys accepts the API keys it was created with
+type stubAPIKeys map[string]*crypto.APIKeyPrincipal
+
+func (s stubAPIKeys) ValidateAPIKey(ctx context.Context, key, ipAddress string) (*crypto.APIKeyPrincipal, error) {
+	if principal, ok := s[key]; ok {
+		return principal, nil
+	}
+	return nil, errors.New("invalid API key")
+}
+
+func TestJWTMiddleware_APIKey(t *testing.T) {
+	jwtService := crypto.NewJWTService("test-secret")
+	apiKey, _, err := crypto.GenerateAPIKey()
+	require.NoError(t, err)
+	principal := &crypto.APIKeyPrincipal{KeyID: "key-1", Name: "Prometheus", Scopes: []crypto.Scope{crypto.ScopeMetricsRead}}
+	middleware := NewJWTMiddleware(jwtService).WithAPIKeys(stubAPIKeys{apiKey: principal})
+
+	request := func(t *testing.T, handler fiber.Handler, token string) (int, map[string]interface{}) {
+		t.Helper()
+
+		locals := make(map[string]interface{})
+		app := setupTestApp()
+		app.Get("/protected", handler, func(c *fiber.Ctx) error {
+			for _, key := range []string{"user_id", "role", "token_type", "api_key_id", "scopes"} {
+				if value := c.Locals(key); value != nil {
+					locals[key] = value
+				}
+			}
+			return c.SendString("success")
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode, locals
+	}
+
+	t.Run("RequireAuth accepts API key as service account", func(t *testing.T) {
+		status, locals := request(t, middleware.RequireAuth(), apiKey)
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, crypto.RoleService, locals["role"])
+		assert.Equal(t, crypto.TokenTypeAPIKey, locals["token_type"])
+		assert.Equal(t, "key-1", locals["api_key_id"])
+		assert.Equal(t, principal.Scopes, locals["scopes"])
+		assert.NotContains(t, locals, "user_id")
+	})
+
+	t.Run("RequireAuth rejects unknown API key", func(t *testing.T) {
+		status, _ := request(t, middleware.RequireAuth(), apiKey+"x")
+
+		assert.Equal(t, http.StatusUnauthorized, status)
+	})
+
+	t.Run("RequireAuthOrMFA rejects API key", func(t *testing.T) {
+		status, _ := request(t, middleware.RequireAuthOrMFA(), apiKey)
+
+		assert.Equal(t, http.StatusUnauthorized, status)
+	})
+
+	t.Run("API keys are rejected unless enabled", func(t *testing.T) {
+		status, _ := request(t, NewJWTMiddleware(jwtService).RequireAuth(), apiKey)
+
+		assert.Equal(t, http.StatusUnauthorized, status)
+	})
+
+	t.Run("JWT can't claim to be an API key", func(t *testing.T) {
+		token, err := jwtService.GenerateToken("user-123", crypto.RoleAdmin, crypto.TokenTypeAPIKey, time.Hour)
+		require.NoError(t, err)
+
+		status, _ := request(t, middleware.RequireAuth(), token)
+
+		assert.Equal(t, http.StatusUnauthorized, status)
+	})
+
+	t.Run("OptionalAuth accepts API key", func(t *testing.T) {
+		status, locals := request(t, middleware.OptionalAuth(), apiKey)
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, crypto.RoleService, locals["role"])
+	})
+}
+
+// stubAccounts knows the current role of the accounts it was created with
+type stubAccounts map[string]crypto.Role
+
+func (s stubAccounts) ValidateAccount(ctx context.Context, claims *crypto.Claims) error {
+	current, ok := s[claims.UserID]
+	if !ok || current != claims.Role {
+		return apperrors.Unauthorized("account is disabled")
+	}
+	return nil
+}
+
+func TestJWTMiddleware_AccountCheck(t *testing.T) {
+	jwtService := crypto.NewJWTService("test-secret")
+	middleware := NewJWTMiddleware(jwtService).WithAccountCheck(stubAccounts{"user-123": crypto.RoleTeacher})
+
+	request := func(t *testing.T, handler fiber.Handler, userID string, role crypto.Role) (int, interface{}) {
+		t.Helper()
+
+		token, err := jwtService.GenerateToken(userID, role, crypto.TokenTypeAccess, time.Hour)
+		require.NoError(t, err)
+
+		var locals interface{}
+		app := setupTestApp()
+		app.Get("/protected", handler, func(c *fiber.Ctx) error {
+			locals = c.Locals("user_id")
+			return c.SendString("success")
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode, locals
+	}
+
+	t.Run("RequireAuth accepts active account", func(t *testing.T) {
+		status, userID := request(t, middleware.RequireAuth(), "user-123", crypto.RoleTeacher)
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "user-123", userID)
+	})
+
+	t.Run("RequireAuth rejects outdated role", func(t *testing.T) {
+		status, _ := request(t, middleware.RequireAuth(), "user-123", crypto.RoleAdmin)
+
+		assert.Equal(t, http.StatusUnauthorized, status)
+	})
+
+	t.Run("RequireAuth rejects disabled account", func(t *testing.T) {
+		status, _ := request(t, middleware.RequireAuth(), "user-456", crypto.RoleStudent)
+
+		assert.Equal(t, http.StatusUnauthorized, status)
+	})
+
+	t.Run("OptionalAuth ignores disabled account", func(t *testing.T) {
+		status, userID := request(t, middleware.OptionalAuth(), "user-456", crypto.RoleStudent)
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.Nil(t, userID)
+	})
+}
+
+func TestJWTMiddleware_AdminScope(t *testing.T) {
+	jwtService := crypto.NewJWTService("test-secret")
+	middleware := NewJWTMiddleware(jwtService)
+
+	request := func(t *testing.T, token string) (interface{}, interface{}) {
+		t.Helper()
+
+		var scope, department interface{}
+		app := setupTestApp()
+		app.Get("/protected", middleware.RequireAuth(), func(c *fiber.Ctx) error {
+			scope = c.Locals("admin_scope")
+			department = c.Locals("admin_department")
+			return c.SendString("success")
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		return scope, department
+	}
+
+	t.Run("scoped admin", func(t *testing.T) {
+		token, err := jwtService.GenerateUserSessionToken("admin-1", crypto.RoleAdmin,
+			crypto.AccountClaims{AdminClaims: crypto.AdminClaims{AdminScope: crypto.AdminScopeDepartment, Department: "IT"}}, crypto.TokenTypeAccess, "", time.Hour)
+		require.NoError(t, err)
+
+		scope, department := request(t, token)
+		assert.Equal(t, crypto.AdminScopeDepartment, scope)
+		assert.Equal(t, "IT", department)
+	})
+
+	t.Run("admin token without scope is a full admin", func(t *testing.T) {
+		token, err := jwtService.GenerateToken("admin-1", crypto.RoleAdmin, crypto.TokenTypeAdmin, time.Hour)
+		require.NoError(t, err)
+
+		scope, _ := request(t, token)
+		assert.Equal(t, crypto.AdminScopeFull, scope)
+	})
+
+	t.Run("other roles have no admin scope", func(t *testing.T) {
+		token, err := jwtService.GenerateToken("user-1", crypto.RoleTeacher, crypto.TokenTypeAccess, time.Hour)
+		require.NoError(t, err)
+
+		scope, _ := request(t, token)
+		assert.Nil(t, scope)
+	})
+}
+
 // Note: extractToken is a private function, tested indirectly through RequireAuth and OptionalAuth
 
 func TestJWTMiddleware_MultipleRoles(t *testing.T) {
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/JustDoItBetter/FITS-backend/internal/common/errors"
+	"github.com/JustDoItBetter/FITS-backend/internal/common/response"
+	"github.com/JustDoItBetter/FITS-backend/pkg/crypto"
+	"github.com/gofiber/fiber/v2"
+)
+
+// PermissionResolver looks up the permissions granted to a role
+// Implemented by the role service, which stores them in the database
+type PermissionResolver interface {
+	HasPermission(ctx context.Context, role crypto.Role, permission crypto.Permission) (bool, error)
+}
+
+// RequirePermission creates a middleware that checks if the role of the user was granted a permission
+// Must be used after RequireAuth middleware to ensure role context is set
+// Admins are additionally limited by their admin scope, see crypto.AdminScope.Grants
+// API keys have no role permissions and are rejected, service account endpoints use RequireScope
+func RequirePermission(resolver PermissionResolver, permission crypto.Permission) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userRole, ok := c.Locals("role").(crypto.Role)
+		if !ok {
+			return response.Error(c, errors.Unauthorized("authentication required"))
+		}
+
+		if userRole == crypto.RoleAdmin {
+			if scope, _ := c.Locals("admin_scope").(crypto.AdminScope); !scope.Grants(permission) {
+				return response.Error(c, errors.NewAppError(
+					fiber.StatusForbidden,
+					"Forbidden",
+					"your admin scope doesn't grant the permission: "+string(permission),
+				))
+			}
+		}
+
+		granted, err := resolver.HasPermission(c.Context(), userRole, permission)
+		if err != nil {
+			return response.Error(c, err)
+		}
+		if !granted {
+			return response.Error(c, errors.NewAppError(
+				fiber.StatusForbidden,
+				"Forbidden",
+				"this action requires the permission: "+string(permission),
+			))
+		}
+
+		return c.Next()
+	}
+}
@@ -25,6 +25,10 @@ func RequireRole(allowedRoles ...crypto.Role) fiber.Handler {
 		// Check if user has one of the allowed roles
 		for _, allowedRole := range allowedRoles {
 			if userRole == allowedRole {
+				// Auditors keep their admin role, but only read
+				if userRole == crypto.RoleAdmin && !adminScopeAllows(c, nil) {
+					return response.Error(c, errAdminScope(nil))
+				}
 				return c.Next()
 			}
 		}
@@ -39,39 +43,90 @@ func RequireRole(allowedRoles ...crypto.Role) fiber.Handler {
 }
 
 // RequireAdmin is a convenience middleware that requires admin role
-func RequireAdmin() fiber.Handler {
-	return RequireRole(crypto.RoleAdmin)
+// Full admins are always allowed and auditors may read (GET and HEAD),
+// other scoped admins need one of the given scopes
+func RequireAdmin(scopes ...crypto.AdminScope) fiber.Handler {
+	requireRole := RequireRole(crypto.RoleAdmin)
+
+	return func(c *fiber.Ctx) error {
+		if userRole, _ := c.Locals("role").(crypto.Role); userRole != crypto.RoleAdmin {
+			return requireRole(c)
+		}
+
+		if !adminScopeAllows(c, scopes) {
+			return response.Error(c, errAdminScope(scopes))
+		}
+		return c.Next()
+	}
+}
+
+// RequireScopeOrAdmin creates a middleware for admin endpoints that service accounts may call
+// Service accounts (API keys) need the scope, users must be admins allowed by RequireAdmin(adminScopes...)
+func RequireScopeOrAdmin(scope crypto.Scope, adminScopes ...crypto.AdminScope) fiber.Handler {
+	requireScope := RequireScope(scope)
+	requireAdmin := RequireAdmin(adminScopes...)
+
+	return func(c *fiber.Ctx) error {
+		if tokenType, _ := c.Locals("token_type").(crypto.TokenType); tokenType == crypto.TokenTypeAPIKey {
+			return requireScope(c)
+		}
+		return requireAdmin(c)
+	}
 }
 
 // RequireTeacher is a convenience middleware that requires teacher role
 // (Admin can also access teacher endpoints)
+//
+// Deprecated: use RequirePermission, which follows the permissions stored for each role
 func RequireTeacher() fiber.Handler {
 	return RequireRole(crypto.RoleAdmin, crypto.RoleTeacher)
 }
 
 // RequireStudent is a convenience middleware that requires student role
 // (Teachers and admins can also access student endpoints for supervision)
+//
+// Deprecated: use RequirePermission, which follows the permissions stored for each role
 func RequireStudent() fiber.Handler {
 	return RequireRole(crypto.RoleAdmin, crypto.RoleTeacher, crypto.RoleStudent)
 }
 
+// RequireScope creates a middleware for endpoints that service accounts may call
+// Service accounts (API keys) need the scope, users need one of the allowed roles
+// Must be used after RequireAuth middleware to ensure role context is set
+func RequireScope(scope crypto.Scope, allowedRoles ...crypto.Role) fiber.Handler {
+	requireRole := RequireRole(allowedRoles...)
+
+	return func(c *fiber.Ctx) error {
+		if tokenType, _ := c.Locals("token_type").(crypto.TokenType); tokenType != crypto.TokenTypeAPIKey {
+			return requireRole(c)
+		}
+
+		scopes, _ := c.Locals("scopes").([]crypto.Scope)
+		for _, granted := range scopes {
+			if granted == scope {
+				return c.Next()
+			}
+		}
+
+		return response.Error(c, errors.NewAppError(
+			fiber.StatusForbidden,
+			"Forbidden",
+			"this API key lacks the scope: "+string(scope),
+		))
+	}
+}
+
 // RequireOwnership creates a middleware that checks if the user owns the resource
 // Must be used after RequireAuth middleware to ensure user context exists
 // The resource UUID should be in the route parameter specified by paramName
+// and refer to a student or teacher record, which is compared with the record linked to the account
 // Admins bypass ownership checks to enable administrative access
 func RequireOwnership(paramName string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		userIDRaw := c.Locals("user_id")
-		if userIDRaw == nil {
+		if _, ok := c.Locals("user_id").(string); !ok {
 			return response.Error(c, errors.Unauthorized("authentication required"))
 		}
 
-		// Safe type assertion prevents runtime panic if context has wrong type
-		userID, ok := userIDRaw.(string)
-		if !ok {
-			return response.Error(c, errors.Internal("invalid user ID type in context"))
-		}
-
 		roleRaw := c.Locals("role")
 		if roleRaw == nil {
 			return response.Error(c, errors.Unauthorized("authentication required"))
@@ -83,8 +138,8 @@ func RequireOwnership(paramName string) fiber.Handler {
 			return response.Error(c, errors.Internal("invalid role type in context"))
 		}
 
-		// Admins bypass ownership checks for administrative operations
-		if userRole == crypto.RoleAdmin {
+		// Admins bypass ownership checks for administrative operations, auditors only to read
+		if userRole == crypto.RoleAdmin && adminScopeAllows(c, nil) {
 			return c.Next()
 		}
 
@@ -93,9 +148,9 @@ func RequireOwnership(paramName string) fiber.Handler {
 			return response.Error(c, errors.BadRequest("resource UUID not provided"))
 		}
 
-		// Simple ownership check: user_uuid from JWT must match resource UUID
-		// NOTE: For complex resources, query database to verify user_uuid field
-		if userID != resourceUUID {
+		// The token carries the record linked to the account (user_uuid), users.id never matches a record
+		userUUID, _ := c.Locals("user_uuid").(string)
+		if userUUID == "" || userUUID != resourceUUID {
 			return response.Error(c, errors.NewAppError(
 				fiber.StatusForbidden,
 				"Forbidden",
@@ -107,6 +162,39 @@ func RequireOwnership(paramName string) fiber.Handler {
 	}
 }
 
+// adminScopeAllows reports whether the admin of the request may continue
+// Full admins may do everything, auditors may only read, other scoped admins need one of the scopes
+// A missing scope counts as full admin like a token without scope, see crypto.EffectiveAdminScope
+func adminScopeAllows(c *fiber.Ctx, scopes []crypto.AdminScope) bool {
+	scope, _ := c.Locals("admin_scope").(crypto.AdminScope)
+	switch scope {
+	case "", crypto.AdminScopeFull:
+		return true
+	case crypto.AdminScopeAuditor:
+		return c.Method() == fiber.MethodGet || c.Method() == fiber.MethodHead
+	}
+
+	for _, allowed := range scopes {
+		if scope == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// errAdminScope explains which admins may perform an action
+func errAdminScope(scopes []crypto.AdminScope) error {
+	allowed := string(crypto.AdminScopeFull)
+	for _, scope := range scopes {
+		allowed += ", " + string(scope)
+	}
+	return errors.NewAppError(
+		fiber.StatusForbidden,
+		"Forbidden",
+		"this action requires one of the following admin scopes: "+allowed,
+	)
+}
+
 // Helper function to convert roles to string
 func rolesToString(roles []crypto.Role) string {
 	if len(roles) == 0 {
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -260,16 +261,17 @@ func TestRequireOwnership(t *testing.T) {
 	t.Run("allows user accessing own resource", func(t *testing.T) {
 		app := setupTestApp()
 
-		userID := "user-123"
+		studentUUID := "student-123"
 		app.Get("/user/:id", func(c *fiber.Ctx) error {
-			c.Locals("user_id", userID)
+			c.Locals("user_id", "user-123")
+			c.Locals("user_uuid", studentUUID)
 			c.Locals("role", crypto.RoleStudent)
 			return c.Next()
 		}, RequireOwnership("id"), func(c *fiber.Ctx) error {
 			return c.SendString("success")
 		})
 
-		req := httptest.NewRequest(http.MethodGet, "/user/"+userID, nil)
+		req := httptest.NewRequest(http.MethodGet, "/user/"+studentUUID, nil)
 		resp, err := app.Test(req)
 
 		require.NoError(t, err)
@@ -281,6 +283,7 @@ func TestRequireOwnership(t *testing.T) {
 
 		app.Get("/user/:id", func(c *fiber.Ctx) error {
 			c.Locals("user_id", "user-123")
+			c.Locals("user_uuid", "student-123")
 			c.Locals("role", crypto.RoleStudent)
 			return c.Next()
 		}, RequireOwnership("id"), func(c *fiber.Ctx) error {
@@ -294,6 +297,24 @@ func TestRequireOwnership(t *testing.T) {
 		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
 	})
 
+	t.Run("account ID is not the linked record", func(t *testing.T) {
+		app := setupTestApp()
+
+		app.Get("/user/:id", func(c *fiber.Ctx) error {
+			c.Locals("user_id", "user-123")
+			c.Locals("role", crypto.RoleStudent)
+			return c.Next()
+		}, RequireOwnership("id"), func(c *fiber.Ctx) error {
+			return c.SendString("success")
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/user/user-123", nil)
+		resp, err := app.Test(req)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
 	t.Run("allows admin accessing any resource", func(t *testing.T) {
 		app := setupTestApp()
 
@@ -327,66 +348,213 @@ func TestRequireOwnership(t *testing.T) {
 	})
 }
 
-func TestSecretAuth(t *testing.T) {
-	expectedSecret := "super-secret-key"
-
-	t.Run("allows request with correct secret", func(t *testing.T) {
+func TestRequireScope(t *testing.T) {
+	newApp := func(role crypto.Role, tokenType crypto.TokenType, scopes []crypto.Scope) *fiber.App {
 		app := setupTestApp()
-
-		app.Get("/metrics", SecretAuth(expectedSecret), func(c *fiber.Ctx) error {
+		app.Get("/metrics", func(c *fiber.Ctx) error {
+			c.Locals("role", role)
+			c.Locals("token_type", tokenType)
+			if scopes != nil {
+				c.Locals("scopes", scopes)
+			}
+			return c.Next()
+		}, RequireScope(crypto.ScopeMetricsRead, crypto.RoleAdmin), func(c *fiber.Ctx) error {
 			return c.SendString("metrics data")
 		})
+		return app
+	}
 
-		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
-		req.Header.Set("Authorization", "Bearer "+expectedSecret)
-		resp, err := app.Test(req)
+	tests := []struct {
+		name       string
+		role       crypto.Role
+		tokenType  crypto.TokenType
+		scopes     []crypto.Scope
+		wantStatus int
+	}{
+		{"API key with scope", crypto.RoleService, crypto.TokenTypeAPIKey, []crypto.Scope{crypto.ScopeMetricsRead}, http.StatusOK},
+		{"API key without scope", crypto.RoleService, crypto.TokenTypeAPIKey, []crypto.Scope{crypto.ScopeInvitationsWrite}, http.StatusForbidden},
+		{"admin user", crypto.RoleAdmin, crypto.TokenTypeAccess, nil, http.StatusOK},
+		{"user without role", crypto.RoleTeacher, crypto.TokenTypeAccess, nil, http.StatusForbidden},
+		{"service role without API key", crypto.RoleService, crypto.TokenTypeAccess, []crypto.Scope{crypto.ScopeMetricsRead}, http.StatusForbidden},
+	}
 
-		require.NoError(t, err)
-		assert.Equal(t, http.StatusOK, resp.StatusCode)
-	})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newApp(tt.role, tt.tokenType, tt.scopes)
 
-	t.Run("rejects request with wrong secret", func(t *testing.T) {
-		app := setupTestApp()
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/metrics", nil))
 
-		app.Get("/metrics", SecretAuth(expectedSecret), func(c *fiber.Ctx) error {
-			return c.SendString("metrics data")
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
 		})
+	}
+}
 
-		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
-		req.Header.Set("Authorization", "Bearer wrong-secret")
-		resp, err := app.Test(req)
+func TestRequireAdmin_Scopes(t *testing.T) {
+	newApp := func(scope crypto.AdminScope) *fiber.App {
+		app := setupTestApp()
+		setLocals := func(c *fiber.Ctx) error {
+			c.Locals("role", crypto.RoleAdmin)
+			c.Locals("admin_scope", scope)
+			return c.Next()
+		}
+		ok := func(c *fiber.Ctx) error { return c.SendString("success") }
+		app.Get("/users", setLocals, RequireAdmin(crypto.AdminScopeSecurity), ok)
+		app.Post("/users", setLocals, RequireAdmin(crypto.AdminScopeSecurity), ok)
+		app.Delete("/users", setLocals, RequireAdmin(), ok)
+		app.Post("/students", setLocals, RequireRole(crypto.RoleAdmin, crypto.RoleTeacher), ok)
+		return app
+	}
 
-		require.NoError(t, err)
-		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
-	})
+	tests := []struct {
+		name       string
+		scope      crypto.AdminScope
+		method     string
+		path       string
+		wantStatus int
+	}{
+		{"full admin", crypto.AdminScopeFull, http.MethodDelete, "/users", http.StatusOK},
+		{"admin token without scope", "", http.MethodDelete, "/users", http.StatusOK},
+		{"security admin with scope", crypto.AdminScopeSecurity, http.MethodPost, "/users", http.StatusOK},
+		{"security admin on full admin endpoint", crypto.AdminScopeSecurity, http.MethodDelete, "/users", http.StatusForbidden},
+		{"department admin without scope", crypto.AdminScopeDepartment, http.MethodGet, "/users", http.StatusForbidden},
+		{"auditor reads", crypto.AdminScopeAuditor, http.MethodGet, "/users", http.StatusOK},
+		{"auditor writes", crypto.AdminScopeAuditor, http.MethodPost, "/users", http.StatusForbidden},
+		{"auditor writes through RequireRole", crypto.AdminScopeAuditor, http.MethodPost, "/students", http.StatusForbidden},
+		{"scoped admin through RequireRole", crypto.AdminScopeSecurity, http.MethodPost, "/students", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newApp(tt.scope)
+
+			resp, err := app.Test(httptest.NewRequest(tt.method, tt.path, nil))
 
-	t.Run("rejects request without secret", func(t *testing.T) {
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+		})
+	}
+}
+
+func TestRequireScopeOrAdmin(t *testing.T) {
+	newApp := func(role crypto.Role, tokenType crypto.TokenType, scope crypto.AdminScope, scopes []crypto.Scope) *fiber.App {
 		app := setupTestApp()
+		app.Post("/invite", func(c *fiber.Ctx) error {
+			c.Locals("role", role)
+			c.Locals("token_type", tokenType)
+			c.Locals("admin_scope", scope)
+			if scopes != nil {
+				c.Locals("scopes", scopes)
+			}
+			return c.Next()
+		}, RequireScopeOrAdmin(crypto.ScopeInvitationsWrite, crypto.AdminScopeDepartment), func(c *fiber.Ctx) error {
+			return c.SendString("success")
+		})
+		return app
+	}
 
-		app.Get("/metrics", SecretAuth(expectedSecret), func(c *fiber.Ctx) error {
-			return c.SendString("metrics data")
+	tests := []struct {
+		name       string
+		role       crypto.Role
+		tokenType  crypto.TokenType
+		scope      crypto.AdminScope
+		scopes     []crypto.Scope
+		wantStatus int
+	}{
+		{"API key with scope", crypto.RoleService, crypto.TokenTypeAPIKey, "", []crypto.Scope{crypto.ScopeInvitationsWrite}, http.StatusOK},
+		{"API key without scope", crypto.RoleService, crypto.TokenTypeAPIKey, "", []crypto.Scope{crypto.ScopeMetricsRead}, http.StatusForbidden},
+		{"department admin", crypto.RoleAdmin, crypto.TokenTypeAccess, crypto.AdminScopeDepartment, nil, http.StatusOK},
+		{"security admin", crypto.RoleAdmin, crypto.TokenTypeAccess, crypto.AdminScopeSecurity, nil, http.StatusForbidden},
+		{"teacher", crypto.RoleTeacher, crypto.TokenTypeAccess, "", nil, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newApp(tt.role, tt.tokenType, tt.scope, tt.scopes)
+
+			resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/invite", nil))
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
 		})
+	}
+}
 
-		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
-		resp, err := app.Test(req)
+// stubPermissions grants the listed permissions per role
+type stubPermissions map[crypto.Role][]crypto.Permission
 
-		require.NoError(t, err)
-		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
-	})
+func (s stubPermissions) HasPermission(ctx context.Context, role crypto.Role, permission crypto.Permission) (bool, error) {
+	if role == crypto.RoleAdmin {
+		return true, nil
+	}
+	for _, p := range s[role] {
+		if p == permission {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestRequirePermission(t *testing.T) {
+	resolver := stubPermissions{crypto.RoleTeacher: {crypto.PermissionStudentRead, crypto.PermissionReportSign}}
 
-	t.Run("handles empty secret configuration", func(t *testing.T) {
+	newApp := func(role crypto.Role, scope crypto.AdminScope, permission crypto.Permission) *fiber.App {
 		app := setupTestApp()
+		app.Post("/resource", func(c *fiber.Ctx) error {
+			if role != "" {
+				c.Locals("role", role)
+			}
+			c.Locals("admin_scope", scope)
+			return c.Next()
+		}, RequirePermission(resolver, permission), func(c *fiber.Ctx) error {
+			return c.SendString("success")
+		})
+		return app
+	}
 
-		app.Get("/metrics", SecretAuth(""), func(c *fiber.Ctx) error {
-			return c.SendString("metrics data")
+	tests := []struct {
+		name       string
+		role       crypto.Role
+		scope      crypto.AdminScope
+		permission crypto.Permission
+		wantStatus int
+	}{
+		{"granted permission", crypto.RoleTeacher, "", crypto.PermissionReportSign, http.StatusOK},
+		{"missing permission", crypto.RoleTeacher, "", crypto.PermissionStudentWrite, http.StatusForbidden},
+		{"role without permissions", crypto.RoleStudent, "", crypto.PermissionStudentRead, http.StatusForbidden},
+		{"API key", crypto.RoleService, "", crypto.PermissionStudentRead, http.StatusForbidden},
+		{"unauthenticated", "", "", crypto.PermissionStudentRead, http.StatusUnauthorized},
+		{"full admin", crypto.RoleAdmin, crypto.AdminScopeFull, crypto.PermissionReportSign, http.StatusOK},
+		{"department admin within scope", crypto.RoleAdmin, crypto.AdminScopeDepartment, crypto.PermissionStudentWrite, http.StatusOK},
+		{"department admin outside scope", crypto.RoleAdmin, crypto.AdminScopeDepartment, crypto.PermissionReportSign, http.StatusForbidden},
+		{"auditor writes", crypto.RoleAdmin, crypto.AdminScopeAuditor, crypto.PermissionStudentWrite, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newApp(tt.role, tt.scope, tt.permission)
+
+			resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/resource", nil))
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
 		})
+	}
 
-		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
-		resp, err := app.Test(req)
+	t.Run("adapter without resolver rejects", func(t *testing.T) {
+		app := setupTestApp()
+		adapter := NewMiddlewareAdapter(NewJWTMiddleware(crypto.NewJWTService("test-secret")))
+		app.Post("/resource", func(c *fiber.Ctx) error {
+			c.Locals("role", crypto.RoleAdmin)
+			return c.Next()
+		}, adapter.RequirePermission("student:write"), func(c *fiber.Ctx) error {
+			return c.SendString("success")
+		})
+
+		resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/resource", nil))
 
 		require.NoError(t, err)
-		// Should still reject if no secret provided
-		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
 	})
 }
 
@@ -414,16 +582,17 @@ func TestRBACIntegration(t *testing.T) {
 	t.Run("chained middleware - auth + ownership", func(t *testing.T) {
 		app := setupTestApp()
 
-		userID := "user-123"
+		studentUUID := "student-123"
 		app.Get("/user/:id/profile", func(c *fiber.Ctx) error {
-			c.Locals("user_id", userID)
+			c.Locals("user_id", "user-123")
+			c.Locals("user_uuid", studentUUID)
 			c.Locals("role", crypto.RoleStudent)
 			return c.Next()
 		}, RequireOwnership("id"), func(c *fiber.Ctx) error {
 			return c.JSON(fiber.Map{"profile": "data"})
 		})
 
-		req := httptest.NewRequest(http.MethodGet, "/user/"+userID+"/profile", nil)
+		req := httptest.NewRequest(http.MethodGet, "/user/"+studentUUID+"/profile", nil)
 		resp, err := app.Test(req)
 
 		require.NoError(t, err)
@@ -453,16 +622,17 @@ func BenchmarkRequireRole(b *testing.B) {
 func BenchmarkRequireOwnership(b *testing.B) {
 	app := setupTestApp()
 
-	userID := "user-123"
+	studentUUID := "student-123"
 	app.Get("/user/:id", func(c *fiber.Ctx) error {
-		c.Locals("user_id", userID)
+		c.Locals("user_id", "user-123")
+		c.Locals("user_uuid", studentUUID)
 		c.Locals("role", crypto.RoleStudent)
 		return c.Next()
 	}, RequireOwnership("id"), func(c *fiber.Ctx) error {
 		return c.SendString("success")
 	})
 
-	req := httptest.NewRequest(http.MethodGet, "/user/"+userID, nil)
+	req := httptest.NewRequest(http.MethodGet, "/user/"+studentUUID, nil)
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -0,0 +1,60 @@
+package crypto
+
+// AdminScope limits what an admin may do
+// Scoped admins let several people administer the system without all of them having full access
+type AdminScope string
+
+const (
+	// AdminScopeFull grants everything, including managing other admins
+	AdminScopeFull AdminScope = "full"
+	// AdminScopeDepartment manages students, teachers and invitations of one department
+	AdminScopeDepartment AdminScope = "department"
+	// AdminScopeAuditor may read everything admins can read, but change nothing
+	AdminScopeAuditor AdminScope = "auditor"
+	// AdminScopeSecurity manages accounts, sessions, lockouts and API keys
+	AdminScopeSecurity AdminScope = "security"
+)
+
+// AdminScopes lists all scopes an admin can have
+var AdminScopes = []AdminScope{AdminScopeFull, AdminScopeDepartment, AdminScopeAuditor, AdminScopeSecurity}
+
+// IsValidAdminScope reports whether scope can be given to an admin
+func IsValidAdminScope(scope AdminScope) bool {
+	for _, s := range AdminScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// EffectiveAdminScope returns the scope an account or token of the given role acts with
+// Admins without scope are full admins: the bootstrap token and tokens issued before scopes existed
+// Other roles have no admin scope
+func EffectiveAdminScope(role Role, scope AdminScope) AdminScope {
+	if role != RoleAdmin {
+		return ""
+	}
+	if scope == "" {
+		return AdminScopeFull
+	}
+	return scope
+}
+
+// Grants reports whether an admin with the scope may use a permission of the admin role
+// Department admins manage students and teachers, auditors only read and security admins
+// manage accounts, which is not covered by permissions
+func (s AdminScope) Grants(permission Permission) bool {
+	switch s {
+	case "", AdminScopeFull:
+		return true
+	case AdminScopeAuditor:
+		return permission.IsRead()
+	case AdminScopeDepartment:
+		switch permission {
+		case PermissionStudentRead, PermissionStudentWrite, PermissionTeacherRead, PermissionTeacherWrite:
+			return true
+		}
+	}
+	return false
+}
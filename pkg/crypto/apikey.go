@@ -0,0 +1,94 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// APIKeyPrefix starts every API key, so keys can be told apart from JWTs and found by secret scanners
+const APIKeyPrefix = "fits_"
+
+const (
+	apiKeyIDBytes     = 6  // Lookup ID, stored in plain text
+	apiKeySecretBytes = 32 // Secret part, only its hash is stored
+)
+
+// Scope is a permission granted to an API key
+type Scope string
+
+const (
+	ScopeMetricsRead      Scope = "metrics:read"      // Scrape /metrics
+	ScopeInvitationsWrite Scope = "invitations:write" // Create invitations, e.g. from a school information system
+)
+
+// Scopes lists all scopes that can be granted
+var Scopes = []Scope{ScopeMetricsRead, ScopeInvitationsWrite}
+
+// IsValidScope reports whether scope can be granted to an API key
+func IsValidScope(scope Scope) bool {
+	for _, s := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKeyPrincipal is the service account an API key authenticates as
+type APIKeyPrincipal struct {
+	KeyID  string // ID of the API key record
+	Name   string
+	Scopes []Scope
+}
+
+// HasScope reports whether the API key was granted scope
+func (p *APIKeyPrincipal) HasScope(scope Scope) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateAPIKey generates a new API key of the form fits_<lookup id>_<secret>
+// The lookup ID finds the stored record, the whole key is verified against its hash (see HashString)
+func GenerateAPIKey() (key, lookupID string, err error) {
+	id := make([]byte, apiKeyIDBytes)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+
+	secret, err := GenerateSecureToken(apiKeySecretBytes)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+
+	lookupID = hex.EncodeToString(id)
+	return APIKeyPrefix + lookupID + "_" + secret, lookupID, nil
+}
+
+// ParseAPIKey returns the lookup ID of an API key
+// ok is false if the value is not formatted like an API key
+func ParseAPIKey(key string) (lookupID string, ok bool) {
+	if !strings.HasPrefix(key, APIKeyPrefix) {
+		return "", false
+	}
+
+	lookupID, secret, found := strings.Cut(strings.TrimPrefix(key, APIKeyPrefix), "_")
+	if !found || len(lookupID) != 2*apiKeyIDBytes || secret == "" {
+		return "", false
+	}
+	if _, err := hex.DecodeString(lookupID); err != nil {
+		return "", false
+	}
+	return lookupID, true
+}
+
+// VerifyAPIKey compares an API key with a stored hash in constant time
+func VerifyAPIKey(key, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashString(key)), []byte(hash)) == 1
+}
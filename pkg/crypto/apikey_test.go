@@ -0,0 +1,66 @@
+package crypto
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateAPIKey(t *testing.T) {
+	key, lookupID, err := GenerateAPIKey()
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(key, APIKeyPrefix+lookupID+"_"))
+	assert.Len(t, lookupID, 12)
+
+	parsed, ok := ParseAPIKey(key)
+	assert.True(t, ok)
+	assert.Equal(t, lookupID, parsed)
+
+	other, _, err := GenerateAPIKey()
+	require.NoError(t, err)
+	assert.NotEqual(t, key, other)
+}
+
+func TestParseAPIKey(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		ok   bool
+	}{
+		{"valid", "fits_0123456789ab_c2VjcmV0", true},
+		{"secret may contain underscores", "fits_0123456789ab_c2Vj_cmV0", true},
+		{"JWT", "eyJhbGciOiJIUzI1NiJ9.e30.sig", false},
+		{"missing secret", "fits_0123456789ab_", false},
+		{"short lookup ID", "fits_0123_c2VjcmV0", false},
+		{"lookup ID not hex", "fits_0123456789xy_c2VjcmV0", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, ok := ParseAPIKey(tt.key)
+			assert.Equal(t, tt.ok, ok)
+		})
+	}
+}
+
+func TestVerifyAPIKey(t *testing.T) {
+	key, _, err := GenerateAPIKey()
+	require.NoError(t, err)
+	hash := HashString(key)
+
+	assert.True(t, VerifyAPIKey(key, hash))
+	assert.False(t, VerifyAPIKey(key+"x", hash))
+	assert.False(t, VerifyAPIKey(key, ""))
+}
+
+func TestAPIKeyPrincipal_HasScope(t *testing.T) {
+	principal := &APIKeyPrincipal{Scopes: []Scope{ScopeMetricsRead}}
+
+	assert.True(t, principal.HasScope(ScopeMetricsRead))
+	assert.False(t, principal.HasScope(ScopeInvitationsWrite))
+	assert.True(t, IsValidScope(ScopeInvitationsWrite))
+	assert.False(t, IsValidScope("students:delete"))
+}
@@ -0,0 +1,80 @@
+package crypto
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fastArgon2Params keep tests quick, they are far too weak for production
+var fastArgon2Params = Argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1}
+
+func TestArgon2idHasher(t *testing.T) {
+	hasher := NewArgon2idHasher(fastArgon2Params)
+
+	t.Run("encodes parameters in hash", func(t *testing.T) {
+		hash, err := hasher.Hash("TestPassword123")
+
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"))
+		assert.NoError(t, hasher.Verify("TestPassword123", hash))
+		assert.ErrorIs(t, hasher.Verify("TestPassword124", hash), ErrPasswordMismatch)
+	})
+
+	t.Run("hash verifies with other configured parameters", func(t *testing.T) {
+		hash, err := hasher.Hash("TestPassword123")
+		require.NoError(t, err)
+
+		// Parameters are read from the hash, not from the verifying hasher
+		assert.NoError(t, VerifyPassword("TestPassword123", hash))
+	})
+
+	t.Run("needs rehash when parameters change", func(t *testing.T) {
+		hash, err := hasher.Hash("TestPassword123")
+		require.NoError(t, err)
+
+		assert.False(t, hasher.NeedsRehash(hash))
+		assert.True(t, NewArgon2idHasher(Argon2Params{Memory: 2048, Iterations: 1, Parallelism: 1}).NeedsRehash(hash))
+		assert.True(t, NewArgon2idHasher(Argon2Params{Memory: 1024, Iterations: 2, Parallelism: 1}).NeedsRehash(hash))
+		assert.True(t, NewBcryptHasher(BcryptCost).NeedsRehash(hash))
+	})
+
+	t.Run("bcrypt hash needs rehash", func(t *testing.T) {
+		assert.True(t, hasher.NeedsRehash("$2a$12$abcdefghijklmnopqrstuu5Ck0D7YB0lS7bE5mGJQ1n0lVdC0q5Ri"))
+	})
+
+	t.Run("tampered hash does not verify", func(t *testing.T) {
+		hash, err := hasher.Hash("TestPassword123")
+		require.NoError(t, err)
+
+		tampered := strings.Replace(hash, "t=1", "t=2", 1)
+		assert.Error(t, VerifyPassword("TestPassword123", tampered))
+	})
+
+	t.Run("rejects malformed hashes", func(t *testing.T) {
+		malformed := []string{
+			"$argon2id$",
+			"$argon2id$v=19$m=1024,t=1,p=1$salt",
+			"$argon2id$v=18$m=1024,t=1,p=1$c2FsdHNhbHQ$a2V5a2V5",
+			"$argon2id$v=19$m=1024,t=0,p=1$c2FsdHNhbHQ$a2V5a2V5",
+			"$argon2id$v=19$m=1024,t=1,p=1$!!!$a2V5a2V5",
+			"$argon2i$v=19$m=1024,t=1,p=1$c2FsdHNhbHQ$a2V5a2V5",
+		}
+		for _, hash := range malformed {
+			assert.Error(t, VerifyPassword("TestPassword123", hash), hash)
+		}
+	})
+}
+
+func TestSetPasswordHasher(t *testing.T) {
+	SetPasswordHasher(NewBcryptHasher(4))
+	defer SetPasswordHasher(NewArgon2idHasher(DefaultArgon2Params))
+
+	hash, err := HashPassword("TestPassword123")
+
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$2a$04$"))
+	assert.False(t, PasswordNeedsRehash(hash))
+}
@@ -16,6 +16,11 @@ const (
 	TokenTypeRefresh    TokenType = "refresh"
 	TokenTypeInvitation TokenType = "invitation"
 	TokenTypeAdmin      TokenType = "admin"
+	// TokenTypeMFA is a short-lived partial token issued after the password check
+	// It can only be exchanged for access/refresh tokens once the second factor is verified
+	TokenTypeMFA TokenType = "mfa"
+	// TokenTypeAPIKey marks requests authenticated with a service account API key instead of a JWT
+	TokenTypeAPIKey TokenType = "api_key"
 )
 
 // Role represents a user role
@@ -25,6 +30,8 @@ const (
 	RoleAdmin   Role = "admin"
 	RoleTeacher Role = "teacher"
 	RoleStudent Role = "student"
+	// RoleService is the role of service accounts, API keys never act as a user
+	RoleService Role = "service"
 )
 
 // Claims represents JWT claims
@@ -32,9 +39,30 @@ type Claims struct {
 	UserID    string    `json:"sub"`
 	Role      Role      `json:"role"`
 	TokenType TokenType `json:"type"`
+	SessionID string    `json:"sid,omitempty"` // Refresh token (session) the token belongs to
+	AccountClaims
 	jwt.RegisteredClaims
 }
 
+// AccountClaims describe the account a token was issued for beyond its ID and role
+type AccountClaims struct {
+	// UserUUID is the student or teacher record linked to the account, empty for admins
+	// Ownership is checked against it, as routes refer to records and not to accounts
+	UserUUID string `json:"uid,omitempty"`
+	AdminClaims
+}
+
+// AdminClaims limit what an admin may do, they are empty for other roles
+type AdminClaims struct {
+	AdminScope AdminScope `json:"adm,omitempty"`
+	Department string     `json:"dep,omitempty"` // Department of a department admin
+}
+
+// EffectiveAdminScope returns the scope the token acts with, see EffectiveAdminScope
+func (c *Claims) EffectiveAdminScope() AdminScope {
+	return EffectiveAdminScope(c.Role, c.AdminScope)
+}
+
 // JWTService handles JWT token operations
 type JWTService struct {
 	secret []byte
@@ -49,12 +77,21 @@ func NewJWTService(secret string) *JWTService {
 
 // GenerateToken generates a new JWT token
 func (s *JWTService) GenerateToken(userID string, role Role, tokenType TokenType, expiry time.Duration) (string, error) {
+	return s.GenerateUserSessionToken(userID, role, AccountClaims{}, tokenType, "", expiry)
+}
+
+// GenerateUserSessionToken generates a new JWT token bound to a login session
+// The session ID lets access tokens identify the session they were issued for,
+// the account claims carry the linked record and the scope of an admin
+func (s *JWTService) GenerateUserSessionToken(userID string, role Role, account AccountClaims, tokenType TokenType, sessionID string, expiry time.Duration) (string, error) {
 	now := time.Now()
 
 	claims := &Claims{
-		UserID:    userID,
-		Role:      role,
-		TokenType: tokenType,
+		UserID:        userID,
+		Role:          role,
+		TokenType:     tokenType,
+		SessionID:     sessionID,
+		AccountClaims: account,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ID:        uuid.New().String(),
 			IssuedAt:  jwt.NewNumericDate(now),
@@ -99,6 +99,24 @@ func TestGenerateToken(t *testing.T) {
 		require.NoError(t, err2)
 		assert.NotEqual(t, token1, token2, "tokens should be different due to different JTI and timestamps")
 	})
+
+	t.Run("session token carries session ID", func(t *testing.T) {
+		token, err := service.GenerateUserSessionToken("user-1", RoleStudent, AccountClaims{}, TokenTypeAccess, "session-1", time.Hour)
+		require.NoError(t, err)
+
+		claims, err := service.ValidateToken(token)
+		require.NoError(t, err)
+		assert.Equal(t, "session-1", claims.SessionID)
+	})
+
+	t.Run("plain token has no session ID", func(t *testing.T) {
+		token, err := service.GenerateToken("user-1", RoleStudent, TokenTypeAccess, time.Hour)
+		require.NoError(t, err)
+
+		claims, err := service.ValidateToken(token)
+		require.NoError(t, err)
+		assert.Empty(t, claims.SessionID)
+	})
 }
 
 func TestValidateToken(t *testing.T) {
@@ -254,6 +272,7 @@ func TestTokenTypes(t *testing.T) {
 		{"refresh token", TokenTypeRefresh},
 		{"invitation token", TokenTypeInvitation},
 		{"admin token", TokenTypeAdmin},
+		{"mfa token", TokenTypeMFA},
 	}
 
 	for _, tc := range testCases {
@@ -331,6 +350,63 @@ func TestClaims(t *testing.T) {
 	})
 }
 
+func TestAdminClaims(t *testing.T) {
+	service := NewJWTService("test-secret-key")
+
+	t.Run("round trip", func(t *testing.T) {
+		admin := AdminClaims{AdminScope: AdminScopeDepartment, Department: "IT"}
+		token, err := service.GenerateUserSessionToken("admin-1", RoleAdmin, AccountClaims{AdminClaims: admin}, TokenTypeAccess, "session-1", time.Hour)
+		require.NoError(t, err)
+
+		claims, err := service.ValidateToken(token)
+		require.NoError(t, err)
+		assert.Equal(t, admin, claims.AdminClaims)
+		assert.Equal(t, AdminScopeDepartment, claims.EffectiveAdminScope())
+		assert.Equal(t, "session-1", claims.SessionID)
+	})
+
+	t.Run("admin tokens without scope are full admins", func(t *testing.T) {
+		token, err := service.GenerateToken("admin-1", RoleAdmin, TokenTypeAdmin, time.Hour)
+		require.NoError(t, err)
+
+		claims, err := service.ValidateToken(token)
+		require.NoError(t, err)
+		assert.Equal(t, AdminScopeFull, claims.EffectiveAdminScope())
+	})
+
+	t.Run("other roles have no admin scope", func(t *testing.T) {
+		assert.Equal(t, AdminScope(""), EffectiveAdminScope(RoleTeacher, AdminScopeFull))
+		assert.True(t, IsValidAdminScope(AdminScopeAuditor))
+		assert.False(t, IsValidAdminScope("owner"))
+	})
+}
+
+func TestAccountClaims(t *testing.T) {
+	service := NewJWTService("test-secret-key")
+
+	token, err := service.GenerateUserSessionToken("user-1", RoleStudent, AccountClaims{UserUUID: "student-1"}, TokenTypeAccess, "session-1", time.Hour)
+	require.NoError(t, err)
+
+	claims, err := service.ValidateToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", claims.UserID)
+	assert.Equal(t, "student-1", claims.UserUUID)
+	assert.Empty(t, claims.EffectiveAdminScope())
+}
+
+func TestAdminScope_Grants(t *testing.T) {
+	assert.True(t, AdminScopeFull.Grants(PermissionReportSign))
+	assert.True(t, AdminScope("").Grants(PermissionStudentWrite))
+	assert.True(t, AdminScopeDepartment.Grants(PermissionTeacherWrite))
+	assert.False(t, AdminScopeDepartment.Grants(PermissionReportSign))
+	assert.True(t, AdminScopeAuditor.Grants(PermissionReportRead))
+	assert.False(t, AdminScopeAuditor.Grants(PermissionStudentWrite))
+	assert.False(t, AdminScopeSecurity.Grants(PermissionStudentRead))
+
+	assert.True(t, IsValidPermission(PermissionReportUpload))
+	assert.False(t, IsValidPermission("report:delete"))
+}
+
 // Benchmark tests
 func BenchmarkGenerateToken(b *testing.B) {
 	service := NewJWTService("test-secret-key")
@@ -1,7 +1,10 @@
 package crypto
 
 import (
+	"errors"
 	"fmt"
+	"strings"
+	"sync"
 
 	"golang.org/x/crypto/bcrypt"
 )
@@ -12,21 +15,52 @@ const (
 	BcryptCost = 12
 )
 
-// HashPassword hashes a password using bcrypt
+// ErrPasswordMismatch is returned when a password does not match its hash
+var ErrPasswordMismatch = errors.New("password does not match")
+
+// PasswordHasher creates and verifies password hashes
+// Hashes are self-describing (algorithm and parameters are encoded in the hash),
+// so any hash can be verified regardless of the hasher that is currently configured
+type PasswordHasher interface {
+	// Hash hashes a password with a random salt
+	Hash(password string) (string, error)
+	// Verify checks a password against a hash created by this algorithm
+	Verify(password, hash string) error
+	// NeedsRehash reports whether the hash uses another algorithm or other parameters
+	NeedsRehash(hash string) bool
+}
+
+var (
+	hasherMu      sync.RWMutex
+	defaultHasher PasswordHasher = NewArgon2idHasher(DefaultArgon2Params)
+)
+
+// SetPasswordHasher replaces the hasher used for new password hashes
+// Called once at startup with the configured algorithm and parameters
+func SetPasswordHasher(hasher PasswordHasher) {
+	hasherMu.Lock()
+	defer hasherMu.Unlock()
+	defaultHasher = hasher
+}
+
+// currentHasher returns the hasher used for new password hashes
+func currentHasher() PasswordHasher {
+	hasherMu.RLock()
+	defer hasherMu.RUnlock()
+	return defaultHasher
+}
+
+// HashPassword hashes a password using the configured hasher (argon2id by default)
 func HashPassword(password string) (string, error) {
 	if password == "" {
 		return "", fmt.Errorf("password cannot be empty")
 	}
 
-	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), BcryptCost)
-	if err != nil {
-		return "", fmt.Errorf("failed to hash password: %w", err)
-	}
-
-	return string(hashedBytes), nil
+	return currentHasher().Hash(password)
 }
 
-// VerifyPassword verifies a password against a bcrypt hash
+// VerifyPassword verifies a password against an argon2id or bcrypt hash
+// The algorithm is detected from the hash prefix
 func VerifyPassword(password, hash string) error {
 	if password == "" {
 		return fmt.Errorf("password cannot be empty")
@@ -36,10 +70,72 @@ func VerifyPassword(password, hash string) error {
 		return fmt.Errorf("hash cannot be empty")
 	}
 
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	var err error
+	switch {
+	case isArgon2idHash(hash):
+		err = verifyArgon2id(password, hash)
+	case isBcryptHash(hash):
+		err = verifyBcrypt(password, hash)
+	default:
+		err = fmt.Errorf("unsupported password hash format")
+	}
 	if err != nil {
 		return fmt.Errorf("password verification failed: %w", err)
 	}
 
 	return nil
 }
+
+// PasswordNeedsRehash reports whether a hash should be replaced with one from the configured hasher
+// Used after a successful login to migrate legacy hashes without a forced password reset
+func PasswordNeedsRehash(hash string) bool {
+	return currentHasher().NeedsRehash(hash)
+}
+
+// BcryptHasher hashes passwords with bcrypt
+// Kept for verifying legacy hashes; bcrypt only uses the first 72 bytes of a password
+type BcryptHasher struct {
+	cost int
+}
+
+// NewBcryptHasher creates a bcrypt hasher with the given cost factor
+func NewBcryptHasher(cost int) *BcryptHasher {
+	return &BcryptHasher{cost: cost}
+}
+
+// Hash hashes a password using bcrypt
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	return string(hashedBytes), nil
+}
+
+// Verify checks a password against a bcrypt hash
+func (h *BcryptHasher) Verify(password, hash string) error {
+	return verifyBcrypt(password, hash)
+}
+
+// NeedsRehash reports whether the hash is not bcrypt or uses a different cost
+func (h *BcryptHasher) NeedsRehash(hash string) bool {
+	if !isBcryptHash(hash) {
+		return true
+	}
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != h.cost
+}
+
+// isBcryptHash checks for the $2a$, $2b$ and $2y$ bcrypt prefixes
+func isBcryptHash(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+func verifyBcrypt(password, hash string) error {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return ErrPasswordMismatch
+	}
+	return err
+}
@@ -28,7 +28,7 @@ func TestHashPassword(t *testing.T) {
 
 		require.NoError(t, err1)
 		require.NoError(t, err2)
-		assert.NotEqual(t, hash1, hash2, "bcrypt should use different salts")
+		assert.NotEqual(t, hash1, hash2, "hashes should use different salts")
 	})
 
 	t.Run("rejects empty password", func(t *testing.T) {
@@ -39,38 +39,63 @@ func TestHashPassword(t *testing.T) {
 		assert.Empty(t, hash)
 	})
 
-	t.Run("rejects password exceeding 72 bytes", func(t *testing.T) {
-		// Bcrypt has a hard limit of 72 bytes
-		password := strings.Repeat("a", 100)
+	t.Run("rejects password exceeding max length", func(t *testing.T) {
+		password := strings.Repeat("a", MaxPasswordBytes+1)
 
 		hash, err := HashPassword(password)
 
 		require.Error(t, err)
-		assert.Contains(t, err.Error(), "password length exceeds 72 bytes")
+		assert.Contains(t, err.Error(), "password length exceeds 1024 bytes")
 		assert.Empty(t, hash)
 	})
 
-	t.Run("hash starts with bcrypt prefix", func(t *testing.T) {
+	t.Run("hash uses argon2id by default", func(t *testing.T) {
 		password := "TestPassword123"
 
 		hash, err := HashPassword(password)
 
 		require.NoError(t, err)
-		// Bcrypt hashes start with $2a$, $2b$, or $2y$
-		assert.True(t, strings.HasPrefix(hash, "$2"), "bcrypt hash should start with $2")
+		assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=65536,t=3,p=2$"), "hash should encode algorithm and parameters")
+		assert.False(t, PasswordNeedsRehash(hash))
 	})
+}
 
-	t.Run("hash contains cost factor", func(t *testing.T) {
-		password := "TestPassword123"
+func TestBcryptHasher(t *testing.T) {
+	hasher := NewBcryptHasher(BcryptCost)
 
-		hash, err := HashPassword(password)
+	t.Run("rejects password exceeding 72 bytes", func(t *testing.T) {
+		// Bcrypt has a hard limit of 72 bytes
+		password := strings.Repeat("a", 100)
+
+		hash, err := hasher.Hash(password)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "password length exceeds 72 bytes")
+		assert.Empty(t, hash)
+	})
+
+	t.Run("hash contains cost factor", func(t *testing.T) {
+		hash, err := hasher.Hash("TestPassword123")
 
 		require.NoError(t, err)
+		// Bcrypt hashes start with $2a$, $2b$, or $2y$
+		assert.True(t, strings.HasPrefix(hash, "$2"), "bcrypt hash should start with $2")
 		// Extract cost from hash and verify it matches BcryptCost (12)
 		cost, err := bcrypt.Cost([]byte(hash))
 		require.NoError(t, err)
 		assert.Equal(t, BcryptCost, cost)
 	})
+
+	t.Run("legacy bcrypt hashes still verify and need rehash", func(t *testing.T) {
+		hash, err := hasher.Hash("LegacyPassword123")
+		require.NoError(t, err)
+
+		assert.NoError(t, VerifyPassword("LegacyPassword123", hash))
+		assert.ErrorIs(t, VerifyPassword("WrongPassword123", hash), ErrPasswordMismatch)
+		assert.True(t, PasswordNeedsRehash(hash), "bcrypt hash should be migrated to argon2id")
+		assert.False(t, hasher.NeedsRehash(hash))
+		assert.True(t, NewBcryptHasher(BcryptCost+1).NeedsRehash(hash))
+	})
 }
 
 func TestVerifyPassword(t *testing.T) {
@@ -94,7 +119,7 @@ func TestVerifyPassword(t *testing.T) {
 
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "password verification failed")
-		assert.ErrorIs(t, err, bcrypt.ErrMismatchedHashAndPassword)
+		assert.ErrorIs(t, err, ErrPasswordMismatch)
 	})
 
 	t.Run("rejects empty password in verification", func(t *testing.T) {
@@ -265,12 +290,12 @@ func TestPasswordHashSecurity(t *testing.T) {
 			if i == 0 {
 				hashLength = len(hash)
 			} else {
-				assert.Equal(t, hashLength, len(hash), "all bcrypt hashes should have same length")
+				assert.Equal(t, hashLength, len(hash), "all argon2id hashes should have same length")
 			}
 		}
 
-		// Bcrypt hashes are always 60 characters
-		assert.Equal(t, 60, hashLength)
+		// Default parameters, 16-byte salt and 32-byte key encode to 97 characters
+		assert.Equal(t, 97, hashLength)
 	})
 }
 
@@ -326,8 +351,8 @@ func TestPasswordExamples(t *testing.T) {
 	}
 
 	invalidExamples := map[string]string{
-		"":                       "password cannot be empty",         // Empty password
-		strings.Repeat("a", 100): "password length exceeds 72 bytes", // Too long
+		"":                                      "password cannot be empty",           // Empty password
+		strings.Repeat("a", MaxPasswordBytes+1): "password length exceeds 1024 bytes", // Too long
 	}
 
 	// Test valid passwords
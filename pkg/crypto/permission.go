@@ -0,0 +1,43 @@
+package crypto
+
+import "strings"
+
+// Permission is a named action a role can be granted, e.g. student:write
+// Which roles have which permissions is stored in the database, see the role domain
+type Permission string
+
+const (
+	PermissionStudentRead  Permission = "student:read"  // Read student records
+	PermissionStudentWrite Permission = "student:write" // Create, update and delete student records
+	PermissionTeacherRead  Permission = "teacher:read"  // Read teacher records
+	PermissionTeacherWrite Permission = "teacher:write" // Create, update and delete teacher records
+	PermissionReportUpload Permission = "report:upload" // Upload reports for signing
+	PermissionReportRead   Permission = "report:read"   // Download pending sign requests
+	PermissionReportSign   Permission = "report:sign"   // Upload signed reports
+)
+
+// Permissions lists all permissions that can be granted to a role
+var Permissions = []Permission{
+	PermissionStudentRead,
+	PermissionStudentWrite,
+	PermissionTeacherRead,
+	PermissionTeacherWrite,
+	PermissionReportUpload,
+	PermissionReportRead,
+	PermissionReportSign,
+}
+
+// IsValidPermission reports whether permission can be granted to a role
+func IsValidPermission(permission Permission) bool {
+	for _, p := range Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRead reports whether the permission only reads data
+func (p Permission) IsRead() bool {
+	return strings.HasSuffix(string(p), ":read")
+}
@@ -0,0 +1,17 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+)
+
+// GenerateSecureToken generates a random URL-safe token with the given number of random bytes
+// Use it for opaque single-use tokens that are stored hashed (see HashString)
+func GenerateSecureToken(size int) (string, error) {
+	raw := make([]byte, size)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
@@ -0,0 +1,113 @@
+package crypto
+
+import (
+	"encoding/base32"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfc6238Secret is the SHA1 test key from RFC 6238 Appendix B ("12345678901234567890")
+var rfc6238Secret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestGenerateTOTPCode(t *testing.T) {
+	// RFC 6238 Appendix B test vectors (8 digits), truncated to our 6 digits
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		code, err := GenerateTOTPCode(rfc6238Secret, time.Unix(tt.unix, 0))
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, code, "unix time %d", tt.unix)
+	}
+
+	t.Run("rejects invalid secret", func(t *testing.T) {
+		_, err := GenerateTOTPCode("not base32!", time.Now())
+		assert.Error(t, err)
+	})
+}
+
+func TestValidateTOTPCode(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	require.NoError(t, err)
+	now := time.Unix(1700000000, 0)
+
+	t.Run("accepts current code", func(t *testing.T) {
+		code, _ := GenerateTOTPCode(secret, now)
+
+		step, ok := ValidateTOTPCode(secret, code, now)
+
+		assert.True(t, ok)
+		assert.Equal(t, TOTPStep(now), step)
+	})
+
+	t.Run("accepts code from previous step within skew", func(t *testing.T) {
+		code, _ := GenerateTOTPCode(secret, now.Add(-TOTPPeriod*time.Second))
+
+		step, ok := ValidateTOTPCode(secret, code, now)
+
+		assert.True(t, ok)
+		assert.Equal(t, TOTPStep(now)-1, step)
+	})
+
+	t.Run("rejects code outside skew", func(t *testing.T) {
+		code, _ := GenerateTOTPCode(secret, now.Add(-5*TOTPPeriod*time.Second))
+
+		_, ok := ValidateTOTPCode(secret, code, now)
+
+		assert.False(t, ok)
+	})
+
+	t.Run("rejects malformed code", func(t *testing.T) {
+		_, ok := ValidateTOTPCode(secret, "12345", now)
+		assert.False(t, ok)
+
+		_, ok = ValidateTOTPCode(secret, "", now)
+		assert.False(t, ok)
+	})
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri := TOTPProvisioningURI("FITS", "anna.schmidt", "JBSWY3DPEHPK3PXP")
+
+	parsed, err := url.Parse(uri)
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth", parsed.Scheme)
+	assert.Equal(t, "totp", parsed.Host)
+	assert.Equal(t, "/FITS:anna.schmidt", parsed.Path)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", parsed.Query().Get("secret"))
+	assert.Equal(t, "FITS", parsed.Query().Get("issuer"))
+	assert.Equal(t, "6", parsed.Query().Get("digits"))
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(10)
+	require.NoError(t, err)
+	assert.Len(t, codes, 10)
+
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		assert.Len(t, code, 11)
+		assert.Equal(t, "-", code[5:6])
+		assert.False(t, seen[code], "recovery codes should be unique")
+		seen[code] = true
+	}
+}
+
+func TestNormalizeRecoveryCode(t *testing.T) {
+	assert.Equal(t, "abcde12345", NormalizeRecoveryCode(" ABCDE-12345 "))
+	assert.Equal(t, "abcde12345", NormalizeRecoveryCode("abcde 12345"))
+	assert.Equal(t, NormalizeRecoveryCode("abcde-12345"), NormalizeRecoveryCode(strings.ToUpper("abcde12345")))
+}
@@ -177,6 +177,16 @@ func (db *DB) VerifySchema(ctx context.Context) error {
 		"reports",
 		"signatures",
 		"teacher_keys",
+		"login_attempts",
+		"security_events",
+		"mfa_recovery_codes",
+		"webauthn_credentials",
+		"webauthn_sessions",
+		"password_reset_tokens",
+		"mail_outbox",
+		"oidc_identities",
+		"oidc_login_states",
+		"api_keys",
 		"schema_migrations",
 	}
 
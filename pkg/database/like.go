@@ -0,0 +1,12 @@
+package database
+
+import "strings"
+
+// likeEscaper escapes the wildcards of LIKE patterns, used with ESCAPE '\'
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// ContainsPattern returns the lowercase LIKE pattern matching values that contain search literally
+// Queries must compare with LIKE ? ESCAPE '\' so that %, _ and \ in the search aren't wildcards
+func ContainsPattern(search string) string {
+	return "%" + likeEscaper.Replace(strings.ToLower(search)) + "%"
+}
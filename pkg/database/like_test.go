@@ -0,0 +1,25 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContainsPattern(t *testing.T) {
+	tests := []struct {
+		search string
+		want   string
+	}{
+		{search: "Max", want: "%max%"},
+		{search: "max_1", want: `%max\_1%`},
+		{search: "100%", want: `%100\%%`},
+		{search: `a\b`, want: `%a\\b%`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.search, func(t *testing.T) {
+			assert.Equal(t, tt.want, ContainsPattern(tt.search))
+		})
+	}
+}
@@ -150,6 +150,111 @@ func getAllMigrations() []MigrationDefinition {
 			Name:    "add_teacher_to_invitations_and_make_required",
 			Up:      migration004AddTeacherToInvitations,
 		},
+		{
+			Version: "005",
+			Name:    "add_login_protection",
+			Up:      migration005AddLoginProtection,
+		},
+		{
+			Version: "006",
+			Name:    "add_two_factor_authentication",
+			Up:      migration006AddTwoFactorAuthentication,
+		},
+		{
+			Version: "007",
+			Name:    "add_webauthn_credentials",
+			Up:      migration007AddWebAuthnCredentials,
+		},
+		{
+			Version: "008",
+			Name:    "add_password_reset_tokens",
+			Up:      migration008AddPasswordResetTokens,
+		},
+		{
+			Version: "009",
+			Name:    "add_session_device_info",
+			Up:      migration009AddSessionDeviceInfo,
+		},
+		{
+			Version: "010",
+			Name:    "add_invitation_audit_columns",
+			Up:      migration010AddInvitationAuditColumns,
+		},
+		{
+			Version: "011",
+			Name:    "add_mail_outbox",
+			Up:      migration011AddMailOutbox,
+		},
+		{
+			Version: "012",
+			Name:    "add_oidc_identities",
+			Up:      migration012AddOIDCIdentities,
+		},
+		{
+			Version: "013",
+			Name:    "add_api_keys",
+			Up:      migration013AddAPIKeys,
+		},
+		{
+			Version: "014",
+			Name:    "add_user_administration",
+			Up:      migration014AddUserAdministration,
+		},
+		{
+			Version: "015",
+			Name:    "add_student_teacher_removed_at",
+			Up:      migration015AddStudentTeacherRemovedAt,
+		},
+		{
+			Version: "016",
+			Name:    "add_admin_scopes",
+			Up:      migration016AddAdminScopes,
+		},
+		{
+			Version: "017",
+			Name:    "add_roles_and_permissions",
+			Up:      migration017AddRolesAndPermissions,
+		},
+		{
+			Version: "018",
+			Name:    "grant_students_read_own_record",
+			Up:      migration018GrantStudentsReadOwnRecord,
+		},
+		{
+			Version: "019",
+			Name:    "add_user_profile",
+			Up:      migration019AddUserProfile,
+		},
+		{
+			Version: "020",
+			Name:    "add_list_search_indexes",
+			Up:      migration020AddListSearchIndexes,
+		},
+		{
+			Version: "021",
+			Name:    "partial_unique_email_indexes",
+			Up:      migration021PartialUniqueEmailIndexes,
+		},
+		{
+			Version: "022",
+			Name:    "add_record_versions",
+			Up:      migration022AddRecordVersions,
+		},
+		{
+			Version: "023",
+			Name:    "add_login_attempts_pruning_index",
+			Up:      migration023AddLoginAttemptsPruningIndex,
+		},
+		{
+			Version: "024",
+			Name:    "scrub_mail_outbox",
+			Up:      migration024ScrubMailOutbox,
+		},
+		{
+			Version: "025",
+			Name:    "add_open_invitation_email_index",
+			Up:      migration025AddOpenInvitationEmailIndex,
+		},
 		// Add future migrations here
 	}
 }
@@ -417,3 +522,526 @@ func migration004AddTeacherToInvitations(db *gorm.DB) error {
 
 	return nil
 }
+
+// migration005AddLoginProtection adds brute-force protection for the login endpoint
+// Failed attempts are tracked per username and IP, lockouts are recorded in a security log
+func migration005AddLoginProtection(db *gorm.DB) error {
+	// Add locked_until column to users table
+	if err := db.Exec(`
+		ALTER TABLE users
+		ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP WITH TIME ZONE
+	`).Error; err != nil {
+		return fmt.Errorf("failed to add locked_until to users: %w", err)
+	}
+
+	// Create login_attempts table (failed attempts only)
+	if err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS login_attempts (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			username VARCHAR(100) NOT NULL,
+			ip_address VARCHAR(45) NOT NULL,
+			attempted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`).Error; err != nil {
+		return fmt.Errorf("failed to create login_attempts table: %w", err)
+	}
+
+	// Create indexes for login_attempts (lookups are always time-bounded)
+	db.Exec(`CREATE INDEX IF NOT EXISTS idx_login_attempts_username ON login_attempts(username, attempted_at)`)
+	db.Exec(`CREATE INDEX IF NOT EXISTS idx_login_attempts_ip_address ON login_attempts(ip_address, attempted_at)`)
+
+	// Create security_events table
+	if err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS security_events (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			event_type VARCHAR(50) NOT NULL,
+			user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+			username VARCHAR(100),
+			ip_address VARCHAR(45),
+			actor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+			details TEXT,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+		)
+	`).Error; err != nil {
+		return fmt.Errorf("failed to create security_events table: %w", err)
+	}
+
+	// Create indexes for security_events
+	db.Exec(`CREATE INDEX IF NOT EXISTS idx_security_events_event_type ON security_events(event_type)`)
+	db.Exec(`CREATE INDEX IF NOT EXISTS idx_security_events_user_id ON security_events(user_id)`)
+	db.Exec(`CREATE INDEX IF NOT EXISTS idx_security_events_created_at ON security_events(created_at)`)
+
+	return nil
+}
+
+// migration006AddTwoFactorAuthentication adds TOTP two-factor authentication
+// The secret lives on the users row, recovery codes are stored hashed in their own table
+func migration006AddTwoFactorAuthentication(db *gorm.DB) error {
+	// Add MFA columns to users table
+	if err := db.Exec(`
+		ALTER TABLE users
+		ADD COLUMN IF NOT EXISTS mfa_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+		ADD COLUMN IF NOT EXISTS totp_secret VARCHAR(64),
+		ADD COLUMN IF NOT EXISTS totp_last_step BIGINT
+	`).Error; err != nil {
+		return fmt.Errorf("failed to add MFA columns to users: %w", err)
+	}
+
+	// Create mfa_recovery_codes table
+	if err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			code_hash VARCHAR(64) NOT NULL,
+			used_at TIMESTAMP WITH TIME ZONE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+		)
+	`).Error; err != nil {
+		return fmt.Errorf("failed to create mfa_recovery_codes table: %w", err)
+	}
+
+	// Create indexes for mfa_recovery_codes
+	db.Exec(`CREATE INDEX IF NOT EXISTS idx_mfa_recovery_codes_user_id ON mfa_recovery_codes(user_id)`)
+
+	return nil
+}
+
+// migration007AddWebAuthnCredentials creates the tables for passkey login
+func migration007AddWebAuthnCredentials(db *gorm.DB) error {
+	// Create webauthn_credentials table
+	if err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS webauthn_credentials (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			credential_id VARCHAR(1400) UNIQUE NOT NULL,
+			name VARCHAR(100) NOT NULL,
+			data TEXT NOT NULL,
+			sign_count BIGINT NOT NULL DEFAULT 0,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+			last_used_at TIMESTAMP WITH TIME ZONE
+		)
+	`).Error; err != nil {
+		return fmt.Errorf("failed to create webauthn_credentials table: %w", err)
+	}
+
+	// Create webauthn_sessions table (challenges between begin and finish of a ceremony)
+	if err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS webauthn_sessions (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+			ceremony VARCHAR(20) NOT NULL,
+			data TEXT NOT NULL,
+			expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+		)
+	`).Error; err != nil {
+		return fmt.Errorf("failed to create webauthn_sessions table: %w", err)
+	}
+
+	// Create indexes
+	db.Exec(`CREATE INDEX IF NOT EXISTS idx_webauthn_credentials_user_id ON webauthn_credentials(user_id)`)
+	db.Exec(`CREATE INDEX IF NOT EXISTS idx_webauthn_sessions_expires_at ON webauthn_sessions(expires_at)`)
+
+	return nil
+}
+
+// migration008AddPasswordResetTokens creates the table for admin-initiated password resets
+func migration008AddPasswordResetTokens(db *gorm.DB) error {
+	if err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS password_reset_tokens (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			token_hash VARCHAR(64) UNIQUE NOT NULL,
+			created_by UUID,
+			expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			used_at TIMESTAMP WITH TIME ZONE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+		)
+	`).Error; err != nil {
+		return fmt.Errorf("failed to create password_reset_tokens table: %w", err)
+	}
+
+	db.Exec(`CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_user_id ON password_reset_tokens(user_id)`)
+
+	return nil
+}
+
+// migration009AddSessionDeviceInfo records the device of each refresh token so sessions can be listed and revoked individually
+func migration009AddSessionDeviceInfo(db *gorm.DB) error {
+	if err := db.Exec(`
+		ALTER TABLE refresh_tokens
+		ADD COLUMN IF NOT EXISTS user_agent VARCHAR(512) NOT NULL DEFAULT '',
+		ADD COLUMN IF NOT EXISTS ip_address VARCHAR(45) NOT NULL DEFAULT '',
+		ADD COLUMN IF NOT EXISTS last_used_at TIMESTAMP WITH TIME ZONE
+	`).Error; err != nil {
+		return fmt.Errorf("failed to add device columns to refresh_tokens: %w", err)
+	}
+
+	return nil
+}
+
+// migration010AddInvitationAuditColumns tracks who created, revoked and resent invitations
+func migration010AddInvitationAuditColumns(db *gorm.DB) error {
+	if err := db.Exec(`
+		ALTER TABLE invitations
+		ADD COLUMN IF NOT EXISTS created_by UUID,
+		ADD COLUMN IF NOT EXISTS used_at TIMESTAMP WITH TIME ZONE,
+		ADD COLUMN IF NOT EXISTS revoked_at TIMESTAMP WITH TIME ZONE,
+		ADD COLUMN IF NOT EXISTS revoked_by UUID,
+		ADD COLUMN IF NOT EXISTS resent_at TIMESTAMP WITH TIME ZONE,
+		ADD COLUMN IF NOT EXISTS resend_count INTEGER NOT NULL DEFAULT 0
+	`).Error; err != nil {
+		return fmt.Errorf("failed to add audit columns to invitations: %w", err)
+	}
+
+	// Duplicate check and email filter compare case-insensitively
+	db.Exec(`CREATE INDEX IF NOT EXISTS idx_invitations_email_lower ON invitations(LOWER(email))`)
+
+	return nil
+}
+
+// migration011AddMailOutbox creates the outbox for emails, delivered by the background sender
+func migration011AddMailOutbox(db *gorm.DB) error {
+	if err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS mail_outbox (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			template VARCHAR(50) NOT NULL,
+			recipient VARCHAR(255) NOT NULL,
+			sender VARCHAR(255) NOT NULL,
+			subject VARCHAR(255) NOT NULL,
+			text_body TEXT NOT NULL,
+			html_body TEXT NOT NULL DEFAULT '',
+			status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'failed')),
+			attempts INTEGER NOT NULL DEFAULT 0,
+			next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			last_error TEXT,
+			sent_at TIMESTAMP WITH TIME ZONE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+		)
+	`).Error; err != nil {
+		return fmt.Errorf("failed to create mail_outbox table: %w", err)
+	}
+
+	// The sender only looks at pending messages
+	db.Exec(`CREATE INDEX IF NOT EXISTS idx_mail_outbox_due ON mail_outbox(next_attempt_at) WHERE status = 'pending'`)
+
+	return nil
+}
+
+// migration012AddOIDCIdentities adds login through an external OpenID Connect provider
+// Identities are linked to users automatically by invitation email or by an admin
+func migration012AddOIDCIdentities(db *gorm.DB) error {
+	if err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS oidc_identities (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			issuer VARCHAR(255) NOT NULL,
+			subject VARCHAR(255) NOT NULL,
+			user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+			status VARCHAR(20) NOT NULL CHECK (status IN ('pending', 'linked')),
+			email VARCHAR(255) NOT NULL DEFAULT '',
+			name VARCHAR(255) NOT NULL DEFAULT '',
+			linked_by UUID REFERENCES users(id) ON DELETE SET NULL,
+			linked_at TIMESTAMP WITH TIME ZONE,
+			last_login_at TIMESTAMP WITH TIME ZONE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (issuer, subject),
+			CHECK (status = 'pending' OR user_id IS NOT NULL)
+		)
+	`).Error; err != nil {
+		return fmt.Errorf("failed to create oidc_identities table: %w", err)
+	}
+
+	db.Exec(`CREATE INDEX IF NOT EXISTS idx_oidc_identities_user_id ON oidc_identities(user_id)`)
+	db.Exec(`CREATE INDEX IF NOT EXISTS idx_oidc_identities_status ON oidc_identities(status)`)
+
+	// State, nonce and PKCE verifier of logins between the redirect to the provider and the callback
+	if err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS oidc_login_states (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			state_hash VARCHAR(64) UNIQUE NOT NULL,
+			nonce VARCHAR(255) NOT NULL,
+			code_verifier VARCHAR(255) NOT NULL,
+			expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+		)
+	`).Error; err != nil {
+		return fmt.Errorf("failed to create oidc_login_states table: %w", err)
+	}
+
+	db.Exec(`CREATE INDEX IF NOT EXISTS idx_oidc_login_states_expires_at ON oidc_login_states(expires_at)`)
+
+	return nil
+}
+
+// migration013AddAPIKeys adds API keys for service accounts, replacing the static shared secrets
+// Only the SHA-256 hash of a key is stored, the lookup ID finds the record
+func migration013AddAPIKeys(db *gorm.DB) error {
+	if err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS api_keys (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			name VARCHAR(100) NOT NULL,
+			lookup_id VARCHAR(32) UNIQUE NOT NULL,
+			key_hash VARCHAR(64) NOT NULL,
+			scopes TEXT NOT NULL DEFAULT '[]',
+			created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+			expires_at TIMESTAMP WITH TIME ZONE,
+			last_used_at TIMESTAMP WITH TIME ZONE,
+			last_used_ip VARCHAR(45) NOT NULL DEFAULT '',
+			revoked_at TIMESTAMP WITH TIME ZONE,
+			revoked_by UUID REFERENCES users(id) ON DELETE SET NULL,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+		)
+	`).Error; err != nil {
+		return fmt.Errorf("failed to create api_keys table: %w", err)
+	}
+
+	return nil
+}
+
+// migration014AddUserAdministration lets admins disable accounts
+// and makes sure a student or teacher record belongs to at most one account
+func migration014AddUserAdministration(db *gorm.DB) error {
+	if err := db.Exec(`
+		ALTER TABLE users
+		ADD COLUMN IF NOT EXISTS disabled_at TIMESTAMP WITH TIME ZONE,
+		ADD COLUMN IF NOT EXISTS disabled_by UUID REFERENCES users(id) ON DELETE SET NULL
+	`).Error; err != nil {
+		return fmt.Errorf("failed to add disabled columns to users: %w", err)
+	}
+
+	// Fails if existing accounts share a record, these have to be fixed by hand before upgrading
+	if err := db.Exec(`
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_users_user_uuid_unique ON users(user_uuid) WHERE user_uuid IS NOT NULL
+	`).Error; err != nil {
+		return fmt.Errorf("failed to create unique index on users.user_uuid: %w", err)
+	}
+
+	return nil
+}
+
+// migration015AddStudentTeacherRemovedAt flags students whose teacher was deleted
+// so admins can find them and assign a new teacher
+func migration015AddStudentTeacherRemovedAt(db *gorm.DB) error {
+	if err := db.Exec(`
+		ALTER TABLE students
+		ADD COLUMN IF NOT EXISTS teacher_removed_at TIMESTAMP WITH TIME ZONE
+	`).Error; err != nil {
+		return fmt.Errorf("failed to add teacher_removed_at to students: %w", err)
+	}
+
+	db.Exec(`CREATE INDEX IF NOT EXISTS idx_students_teacher_removed_at ON students(teacher_removed_at) WHERE teacher_removed_at IS NOT NULL`)
+
+	return nil
+}
+
+// migration016AddAdminScopes adds scoped admins and admin invitations
+// Existing admins keep full access
+func migration016AddAdminScopes(db *gorm.DB) error {
+	if err := db.Exec(`
+		ALTER TABLE users
+		ADD COLUMN IF NOT EXISTS admin_scope VARCHAR(20) NOT NULL DEFAULT '',
+		ADD COLUMN IF NOT EXISTS admin_department VARCHAR(100) NOT NULL DEFAULT ''
+	`).Error; err != nil {
+		return fmt.Errorf("failed to add admin scope to users: %w", err)
+	}
+
+	if err := db.Exec(`UPDATE users SET admin_scope = 'full' WHERE role = 'admin' AND admin_scope = ''`).Error; err != nil {
+		return fmt.Errorf("failed to set scope of existing admins: %w", err)
+	}
+
+	if err := db.Exec(`
+		ALTER TABLE invitations
+		ADD COLUMN IF NOT EXISTS admin_scope VARCHAR(20) NOT NULL DEFAULT ''
+	`).Error; err != nil {
+		return fmt.Errorf("failed to add admin scope to invitations: %w", err)
+	}
+
+	return nil
+}
+
+// migration017AddRolesAndPermissions stores the permissions of each role
+// The roles are seeded with the permissions they had while checks were hard-coded;
+// the admin role has all permissions without rows, admins are limited by their admin scope
+func migration017AddRolesAndPermissions(db *gorm.DB) error {
+	if err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS roles (
+			name VARCHAR(20) PRIMARY KEY,
+			description VARCHAR(255) NOT NULL DEFAULT '',
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+		)
+	`).Error; err != nil {
+		return fmt.Errorf("failed to create roles table: %w", err)
+	}
+
+	if err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS role_permissions (
+			role VARCHAR(20) NOT NULL REFERENCES roles(name) ON DELETE CASCADE,
+			permission VARCHAR(50) NOT NULL,
+			PRIMARY KEY (role, permission)
+		)
+	`).Error; err != nil {
+		return fmt.Errorf("failed to create role_permissions table: %w", err)
+	}
+
+	if err := db.Exec(`
+		INSERT INTO roles (name, description) VALUES
+			('admin', 'Manages the system, limited by the admin scope'),
+			('teacher', 'Supervises students and signs their reports'),
+			('student', 'Uploads reports for signing')
+		ON CONFLICT (name) DO NOTHING
+	`).Error; err != nil {
+		return fmt.Errorf("failed to seed roles: %w", err)
+	}
+
+	if err := db.Exec(`
+		INSERT INTO role_permissions (role, permission) VALUES
+			('teacher', 'student:read'),
+			('teacher', 'teacher:read'),
+			('teacher', 'report:read'),
+			('teacher', 'report:sign'),
+			('student', 'teacher:read'),
+			('student', 'report:upload'),
+			('student', 'report:read')
+		ON CONFLICT (role, permission) DO NOTHING
+	`).Error; err != nil {
+		return fmt.Errorf("failed to seed role permissions: %w", err)
+	}
+
+	return nil
+}
+
+// migration018GrantStudentsReadOwnRecord lets students read student records
+// The student read policy limits them to their own record
+func migration018GrantStudentsReadOwnRecord(db *gorm.DB) error {
+	if err := db.Exec(`
+		INSERT INTO role_permissions (role, permission) VALUES ('student', 'student:read')
+		ON CONFLICT (role, permission) DO NOTHING
+	`).Error; err != nil {
+		return fmt.Errorf("failed to grant student:read to students: %w", err)
+	}
+
+	return nil
+}
+
+// migration019AddUserProfile adds the profile fields users edit themselves
+// Optional notification emails are enabled until a user opts out
+func migration019AddUserProfile(db *gorm.DB) error {
+	if err := db.Exec(`
+		ALTER TABLE users
+			ADD COLUMN IF NOT EXISTS display_name VARCHAR(100) NOT NULL DEFAULT '',
+			ADD COLUMN IF NOT EXISTS notify_sign_requests BOOLEAN NOT NULL DEFAULT TRUE,
+			ADD COLUMN IF NOT EXISTS notify_reminders BOOLEAN NOT NULL DEFAULT TRUE
+	`).Error; err != nil {
+		return fmt.Errorf("failed to add profile to users: %w", err)
+	}
+
+	return nil
+}
+
+// migration020AddListSearchIndexes supports search, filters and sorting of the student and teacher lists
+// Searches match parts of names and emails with LIKE '%...%', which trigram indexes can serve;
+// the indexed expressions must stay identical to the ones of the repositories
+func migration020AddListSearchIndexes(db *gorm.DB) error {
+	if err := db.Exec(`CREATE EXTENSION IF NOT EXISTS pg_trgm`).Error; err != nil {
+		return fmt.Errorf("failed to create pg_trgm extension: %w", err)
+	}
+
+	indexes := []string{
+		`CREATE INDEX IF NOT EXISTS idx_students_full_name_trgm ON students USING gin (LOWER(first_name || ' ' || last_name) gin_trgm_ops)`,
+		`CREATE INDEX IF NOT EXISTS idx_students_email_trgm ON students USING gin (LOWER(email) gin_trgm_ops)`,
+		`CREATE INDEX IF NOT EXISTS idx_students_created_at ON students(created_at, id)`,
+		`CREATE INDEX IF NOT EXISTS idx_students_last_name ON students(last_name, first_name)`,
+		`CREATE INDEX IF NOT EXISTS idx_teachers_full_name_trgm ON teachers USING gin (LOWER(first_name || ' ' || last_name) gin_trgm_ops)`,
+		`CREATE INDEX IF NOT EXISTS idx_teachers_email_trgm ON teachers USING gin (LOWER(email) gin_trgm_ops)`,
+		`CREATE INDEX IF NOT EXISTS idx_teachers_department ON teachers(LOWER(department))`,
+		`CREATE INDEX IF NOT EXISTS idx_teachers_created_at ON teachers(created_at, id)`,
+		`CREATE INDEX IF NOT EXISTS idx_teachers_last_name ON teachers(last_name, first_name)`,
+	}
+	for _, index := range indexes {
+		if err := db.Exec(index).Error; err != nil {
+			return fmt.Errorf("failed to create list index: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// migration021PartialUniqueEmailIndexes makes emails unique among students and teachers that aren't deleted
+// A deleted record no longer blocks creating a new one with its email; restoring it fails while the email is taken
+func migration021PartialUniqueEmailIndexes(db *gorm.DB) error {
+	statements := []string{
+		`ALTER TABLE students DROP CONSTRAINT IF EXISTS students_email_key`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_students_email_active ON students(email) WHERE deleted_at IS NULL`,
+		`ALTER TABLE teachers DROP CONSTRAINT IF EXISTS teachers_email_key`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_teachers_email_active ON teachers(email) WHERE deleted_at IS NULL`,
+	}
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return fmt.Errorf("failed to replace email unique constraints: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// migration022AddRecordVersions adds the version students and teachers are updated with optimistically
+// The version is sent as ETag and checked against If-Match, so concurrent writes can't overwrite each other
+func migration022AddRecordVersions(db *gorm.DB) error {
+	for _, table := range []string{"students", "teachers"} {
+		statement := fmt.Sprintf(`ALTER TABLE %s ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1`, table)
+		if err := db.Exec(statement).Error; err != nil {
+			return fmt.Errorf("failed to add version to %s: %w", table, err)
+		}
+	}
+
+	return nil
+}
+
+// migration023AddLoginAttemptsPruningIndex indexes login attempts by time,
+// attempts are pruned by age whenever a new failure is recorded
+func migration023AddLoginAttemptsPruningIndex(db *gorm.DB) error {
+	if err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_login_attempts_attempted_at ON login_attempts(attempted_at)`).Error; err != nil {
+		return fmt.Errorf("failed to create login attempts pruning index: %w", err)
+	}
+
+	return nil
+}
+
+// migration024ScrubMailOutbox clears the bodies of delivered and given up emails,
+// which contain one-time links and tokens, and indexes sent emails for the retention purge
+func migration024ScrubMailOutbox(db *gorm.DB) error {
+	if err := db.Exec(`UPDATE mail_outbox SET text_body = '', html_body = '' WHERE status IN ('sent', 'failed')`).Error; err != nil {
+		return fmt.Errorf("failed to scrub mail_outbox: %w", err)
+	}
+
+	if err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_mail_outbox_sent ON mail_outbox(sent_at) WHERE status = 'sent'`).Error; err != nil {
+		return fmt.Errorf("failed to create mail outbox retention index: %w", err)
+	}
+
+	return nil
+}
+
+// migration025AddOpenInvitationEmailIndex allows only one open invitation per email
+// Older duplicates, left by concurrent requests before this index existed, are revoked first
+func migration025AddOpenInvitationEmailIndex(db *gorm.DB) error {
+	if err := db.Exec(`
+		UPDATE invitations SET revoked_at = NOW()
+		WHERE used = false AND revoked_at IS NULL AND id NOT IN (
+			SELECT DISTINCT ON (LOWER(email)) id FROM invitations
+			WHERE used = false AND revoked_at IS NULL
+			ORDER BY LOWER(email), created_at DESC
+		)
+	`).Error; err != nil {
+		return fmt.Errorf("failed to revoke duplicate open invitations: %w", err)
+	}
+
+	if err := db.Exec(`
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_invitations_open_email
+		ON invitations(LOWER(email)) WHERE used = false AND revoked_at IS NULL
+	`).Error; err != nil {
+		return fmt.Errorf("failed to create open invitation email index: %w", err)
+	}
+
+	return nil
+}
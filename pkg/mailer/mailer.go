@@ -0,0 +1,57 @@
+package mailer
+
+import (
+	"fmt"
+	"net/mail"
+	"time"
+)
+
+// Mailer composes outbox messages from the localized templates
+// Emails are not sent directly: the caller stores the message in the same transaction
+// as the change that triggers it, and a Worker delivers it afterwards
+type Mailer struct {
+	renderer *Renderer
+	from     string
+}
+
+// New creates a mailer sending as from with templates in defaultLocale unless another locale is requested
+func New(from, defaultLocale string) (*Mailer, error) {
+	if _, err := mail.ParseAddress(from); err != nil {
+		return nil, fmt.Errorf("invalid sender address '%s': %w", from, err)
+	}
+
+	renderer, err := NewRenderer(defaultLocale)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Mailer{renderer: renderer, from: from}, nil
+}
+
+// HasLocale reports whether templates exist for the locale
+func (m *Mailer) HasLocale(locale string) bool {
+	return m.renderer.HasLocale(locale)
+}
+
+// Compose renders a template for a recipient into an outbox message that is due immediately
+func (m *Mailer) Compose(to, template, locale string, data any) (*OutboxMessage, error) {
+	if _, err := mail.ParseAddress(to); err != nil {
+		return nil, fmt.Errorf("invalid recipient address: %w", err)
+	}
+
+	content, err := m.renderer.Render(template, locale, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OutboxMessage{
+		Template:      template,
+		Recipient:     to,
+		Sender:        m.from,
+		Subject:       content.Subject,
+		TextBody:      content.Text,
+		HTMLBody:      content.HTML,
+		Status:        StatusPending,
+		NextAttemptAt: time.Now(),
+	}, nil
+}
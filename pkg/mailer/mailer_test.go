@@ -0,0 +1,180 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testInvitation = InvitationData{
+	FirstName: "Max",
+	LastName:  "Mustermann",
+	Role:      "student",
+	Link:      "https://fits.example.com/invite.html?token=abc&x=<y>",
+	ExpiresAt: time.Date(2025, 10, 25, 12, 0, 0, 0, time.UTC),
+}
+
+func TestRenderer(t *testing.T) {
+	renderer, err := NewRenderer("en")
+	require.NoError(t, err)
+
+	t.Run("renders all templates in all locales", func(t *testing.T) {
+		templates := map[string]any{
+			TemplateInvitation:    testInvitation,
+			TemplatePasswordReset: PasswordResetData{Username: "max.mustermann", Link: "https://fits.example.com/reset-password.html?token=abc"},
+		}
+		for _, locale := range renderer.Locales() {
+			for name, data := range templates {
+				content, err := renderer.Render(name, locale, data)
+				require.NoError(t, err, "%s/%s", locale, name)
+				assert.NotEmpty(t, content.Subject)
+				assert.NotContains(t, content.Subject, "\n")
+				assert.Contains(t, content.Text, "https://fits.example.com")
+				assert.Contains(t, content.HTML, "https://fits.example.com")
+			}
+		}
+	})
+
+	t.Run("selects locale by language tag", func(t *testing.T) {
+		content, err := renderer.Render(TemplateInvitation, "de-DE", testInvitation)
+
+		require.NoError(t, err)
+		assert.Equal(t, "Ihre Einladung zu FITS", content.Subject)
+		assert.Contains(t, content.Text, "25.10.2025 12:00 UTC")
+	})
+
+	t.Run("falls back to default locale", func(t *testing.T) {
+		content, err := renderer.Render(TemplateInvitation, "fr", testInvitation)
+
+		require.NoError(t, err)
+		assert.Equal(t, "Your invitation to FITS", content.Subject)
+		assert.Contains(t, content.Text, "October 25, 2025 12:00 UTC")
+	})
+
+	t.Run("escapes HTML but not text", func(t *testing.T) {
+		content, err := renderer.Render(TemplateInvitation, "en", testInvitation)
+
+		require.NoError(t, err)
+		assert.Contains(t, content.Text, testInvitation.Link)
+		assert.NotContains(t, content.HTML, "<y>")
+	})
+
+	t.Run("rejects unknown default locale", func(t *testing.T) {
+		_, err := NewRenderer("fr")
+
+		assert.Error(t, err)
+	})
+}
+
+func TestMailer_Compose(t *testing.T) {
+	m, err := New("FITS <noreply@fits.example.com>", "de")
+	require.NoError(t, err)
+
+	msg, err := m.Compose("max@example.com", TemplateInvitation, "", testInvitation)
+
+	require.NoError(t, err)
+	assert.Equal(t, TemplateInvitation, msg.Template)
+	assert.Equal(t, "max@example.com", msg.Recipient)
+	assert.Equal(t, "FITS <noreply@fits.example.com>", msg.Sender)
+	assert.Equal(t, "Ihre Einladung zu FITS", msg.Subject)
+	assert.Equal(t, StatusPending, msg.Status)
+	assert.WithinDuration(t, time.Now(), msg.NextAttemptAt, time.Minute)
+
+	_, err = m.Compose("not an address", TemplateInvitation, "", testInvitation)
+	assert.Error(t, err)
+
+	_, err = New("not an address", "de")
+	assert.Error(t, err)
+}
+
+func TestMessage_Bytes(t *testing.T) {
+	msg := &Message{
+		From:    "FITS <noreply@fits.example.com>",
+		To:      "max@example.com",
+		Subject: "Ihr FITS-Passwort zurücksetzen",
+		Text:    "Hallo Max,\nbitte öffnen Sie den Link.",
+		HTML:    "<p>Hallo Max,</p>",
+	}
+
+	data, err := msg.Bytes(time.Date(2025, 10, 25, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(data))
+	require.NoError(t, err)
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, msg.Subject, subject)
+	assert.Equal(t, "<max@example.com>", parsed.Header.Get("To"))
+	assert.True(t, strings.HasSuffix(parsed.Header.Get("Message-ID"), "@fits.example.com>"))
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	var parts []string
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		body, err := io.ReadAll(part)
+		require.NoError(t, err)
+		parts = append(parts, string(body))
+	}
+	require.Len(t, parts, 2)
+	assert.Equal(t, "Hallo Max,\r\nbitte öffnen Sie den Link.", parts[0])
+	assert.Equal(t, "<p>Hallo Max,</p>", parts[1])
+}
+
+func TestLogSender_Fields(t *testing.T) {
+	msg := &Message{
+		To:       "max@example.com",
+		Subject:  "Reset your password",
+		Text:     "https://fits.example.com/reset?token=secret",
+		HTML:     "<a href=\"https://fits.example.com/reset?token=secret\">Reset</a>",
+		Template: TemplatePasswordReset,
+	}
+
+	keys := func(sender *LogSender) []string {
+		var keys []string
+		for _, field := range sender.fields(msg) {
+			keys = append(keys, field.Key)
+		}
+		return keys
+	}
+
+	assert.Equal(t, []string{"to", "subject", "template"}, keys(NewLogSender(false)))
+	assert.Equal(t, []string{"to", "subject", "template", "text"}, keys(NewLogSender(true)))
+}
+
+func TestFileSender(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	sender, err := NewFileSender(dir)
+	require.NoError(t, err)
+
+	err = sender.Send(context.Background(), &Message{
+		From:    "FITS <noreply@fits.example.com>",
+		To:      "max@example.com",
+		Subject: "Test",
+		Text:    "Hello",
+	})
+	require.NoError(t, err)
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.True(t, strings.HasSuffix(files[0].Name(), "_max@example.com.eml"))
+}
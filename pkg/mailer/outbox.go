@@ -0,0 +1,144 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Outbox message statuses
+const (
+	StatusPending = "pending" // Waiting for the first or next delivery attempt
+	StatusSent    = "sent"
+	StatusFailed  = "failed" // Given up after the maximum number of attempts
+)
+
+// OutboxMessage is an email waiting for delivery
+// Messages are written in the same transaction as the change that triggers them,
+// so an email is only sent if that change was committed
+type OutboxMessage struct {
+	ID            string    `gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	Template      string    `gorm:"not null"`
+	Recipient     string    `gorm:"not null"`
+	Sender        string    `gorm:"not null"`
+	Subject       string    `gorm:"not null"`
+	TextBody      string    `gorm:"not null"`
+	HTMLBody      string    `gorm:"column:html_body"`
+	Status        string    `gorm:"not null;default:pending"`
+	Attempts      int       `gorm:"not null;default:0"`
+	NextAttemptAt time.Time `gorm:"not null"`
+	LastError     *string
+	SentAt        *time.Time
+	CreatedAt     time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+}
+
+// TableName specifies the table name for OutboxMessage
+func (OutboxMessage) TableName() string {
+	return "mail_outbox"
+}
+
+// Message converts the outbox entry to a message for a Sender
+func (m *OutboxMessage) Message() *Message {
+	return &Message{
+		From:    m.Sender,
+		To:      m.Recipient,
+		Subject: m.Subject,
+		Text:    m.TextBody,
+		HTML:    m.HTMLBody,
+
+		Template: m.Template,
+	}
+}
+
+// OutboxStore persists outbox messages for the background sender
+type OutboxStore interface {
+	// ClaimDue locks up to limit due messages for lease and counts the attempt
+	ClaimDue(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]OutboxMessage, error)
+	MarkSent(ctx context.Context, id string, sentAt time.Time) error
+	// MarkRetry records a failed attempt; a nil nextAttemptAt gives the message up
+	MarkRetry(ctx context.Context, id, lastError string, nextAttemptAt *time.Time) error
+	// DeleteSent removes messages sent before the given time and returns their number
+	DeleteSent(ctx context.Context, before time.Time) (int64, error)
+}
+
+// GormOutbox implements OutboxStore using GORM
+type GormOutbox struct {
+	db *gorm.DB
+}
+
+// NewGormOutbox creates a new GORM outbox store
+func NewGormOutbox(db *gorm.DB) *GormOutbox {
+	return &GormOutbox{db: db}
+}
+
+// ClaimDue moves the next attempt of due messages into the future before sending them
+// SKIP LOCKED lets several server instances share the outbox without sending a message twice,
+// and a crashed sender only delays the message until the lease expires
+func (o *GormOutbox) ClaimDue(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]OutboxMessage, error) {
+	var messages []OutboxMessage
+	if err := o.db.WithContext(ctx).Raw(`
+		UPDATE mail_outbox SET attempts = attempts + 1, next_attempt_at = ?
+		WHERE id IN (
+			SELECT id FROM mail_outbox
+			WHERE status = ? AND next_attempt_at <= ?
+			ORDER BY next_attempt_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *
+	`, now.Add(lease), StatusPending, now, limit).Scan(&messages).Error; err != nil {
+		return nil, fmt.Errorf("failed to claim outbox messages: %w", err)
+	}
+	return messages, nil
+}
+
+// MarkSent records the successful delivery of a message
+// The bodies are cleared, they contain one-time links and tokens that must not outlive the delivery
+func (o *GormOutbox) MarkSent(ctx context.Context, id string, sentAt time.Time) error {
+	if err := o.db.WithContext(ctx).Model(&OutboxMessage{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":     StatusSent,
+			"sent_at":    sentAt,
+			"last_error": nil,
+			"text_body":  "",
+			"html_body":  "",
+		}).Error; err != nil {
+		return fmt.Errorf("failed to mark outbox message as sent: %w", err)
+	}
+	return nil
+}
+
+// MarkRetry records a failed delivery and schedules the next attempt or gives up
+// Given up messages are never sent, so their bodies are cleared like those of sent messages
+func (o *GormOutbox) MarkRetry(ctx context.Context, id, lastError string, nextAttemptAt *time.Time) error {
+	updates := map[string]interface{}{"last_error": lastError}
+	if nextAttemptAt != nil {
+		updates["next_attempt_at"] = *nextAttemptAt
+	} else {
+		updates["status"] = StatusFailed
+		updates["text_body"] = ""
+		updates["html_body"] = ""
+	}
+
+	if err := o.db.WithContext(ctx).Model(&OutboxMessage{}).
+		Where("id = ?", id).
+		Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to record outbox delivery failure: %w", err)
+	}
+	return nil
+}
+
+// DeleteSent removes messages sent before the given time
+// Failed messages are kept for diagnosis, their bodies are already cleared
+func (o *GormOutbox) DeleteSent(ctx context.Context, before time.Time) (int64, error) {
+	result := o.db.WithContext(ctx).
+		Where("status = ? AND sent_at < ?", StatusSent, before).
+		Delete(&OutboxMessage{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete sent outbox messages: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
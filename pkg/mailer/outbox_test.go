@@ -0,0 +1,79 @@
+package mailer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// setupOutboxTestDB creates the mail_outbox table in an in-memory SQLite database
+func setupOutboxTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err, "failed to create test database")
+
+	require.NoError(t, db.Exec(`CREATE TABLE mail_outbox (id TEXT PRIMARY KEY, template TEXT NOT NULL, recipient TEXT NOT NULL,
+		sender TEXT NOT NULL, subject TEXT NOT NULL, text_body TEXT NOT NULL, html_body TEXT NOT NULL DEFAULT '',
+		status TEXT NOT NULL DEFAULT 'pending', attempts INTEGER NOT NULL DEFAULT 0, next_attempt_at DATETIME NOT NULL,
+		last_error TEXT, sent_at DATETIME, created_at DATETIME DEFAULT CURRENT_TIMESTAMP)`).Error)
+	return db
+}
+
+func TestGormOutbox_ScrubsBodies(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	db := setupOutboxTestDB(t)
+	outbox := NewGormOutbox(db)
+
+	for _, id := range []string{"sent", "retry", "failed"} {
+		msg := newTestOutboxMessage(id, now)
+		require.NoError(t, db.Create(&msg).Error)
+	}
+
+	require.NoError(t, outbox.MarkSent(ctx, "sent", now))
+	next := now.Add(time.Minute)
+	require.NoError(t, outbox.MarkRetry(ctx, "retry", "connection refused", &next))
+	require.NoError(t, outbox.MarkRetry(ctx, "failed", "mailbox unavailable", nil))
+
+	load := func(id string) OutboxMessage {
+		var msg OutboxMessage
+		require.NoError(t, db.First(&msg, "id = ?", id).Error)
+		return msg
+	}
+
+	for _, id := range []string{"sent", "failed"} {
+		msg := load(id)
+		assert.Empty(t, msg.TextBody, id)
+		assert.Empty(t, msg.HTMLBody, id)
+	}
+
+	// Messages that are retried still need their content
+	assert.NotEmpty(t, load("retry").TextBody)
+}
+
+func TestGormOutbox_DeleteSent(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	db := setupOutboxTestDB(t)
+	outbox := NewGormOutbox(db)
+
+	for _, id := range []string{"old", "recent", "failed"} {
+		msg := newTestOutboxMessage(id, now)
+		require.NoError(t, db.Create(&msg).Error)
+	}
+	require.NoError(t, outbox.MarkSent(ctx, "old", now.Add(-48*time.Hour)))
+	require.NoError(t, outbox.MarkSent(ctx, "recent", now.Add(-time.Hour)))
+	require.NoError(t, outbox.MarkRetry(ctx, "failed", "mailbox unavailable", nil))
+
+	deleted, err := outbox.DeleteSent(ctx, now.Add(-24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	var remaining []string
+	require.NoError(t, db.Model(&OutboxMessage{}).Order("id").Pluck("id", &remaining).Error)
+	assert.Equal(t, []string{"failed", "recent"}, remaining)
+}
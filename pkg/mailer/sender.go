@@ -0,0 +1,80 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/JustDoItBetter/FITS-backend/pkg/logger"
+)
+
+// Sender delivers a single message
+// Errors are treated as temporary; the outbox retries the message later
+type Sender interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// LogSender writes messages to the application log instead of sending them
+// Intended for development; bodies contain reset and invitation links and are only logged on request
+type LogSender struct {
+	logBodies bool
+}
+
+// NewLogSender creates a sender that only logs messages, with their bodies if logBodies is set
+func NewLogSender(logBodies bool) *LogSender {
+	return &LogSender{logBodies: logBodies}
+}
+
+// Send logs the message
+func (s *LogSender) Send(ctx context.Context, msg *Message) error {
+	logger.Info("Email (log backend)", s.fields(msg)...)
+	return nil
+}
+
+// fields returns the logged parts of msg
+func (s *LogSender) fields(msg *Message) []zap.Field {
+	fields := []zap.Field{
+		zap.String("to", msg.To),
+		zap.String("subject", msg.Subject),
+		zap.String("template", msg.Template),
+	}
+	if s.logBodies {
+		fields = append(fields, zap.String("text", msg.Text))
+	}
+	return fields
+}
+
+// FileSender stores every message as .eml file in a directory
+// The files can be opened with any mail client, useful for development and tests
+type FileSender struct {
+	dir string
+}
+
+// NewFileSender creates a sender that writes messages into dir, creating it if needed
+func NewFileSender(dir string) (*FileSender, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create mail directory: %w", err)
+	}
+	return &FileSender{dir: dir}, nil
+}
+
+// Send writes the message to <timestamp>_<recipient>.eml
+func (s *FileSender) Send(ctx context.Context, msg *Message) error {
+	now := time.Now()
+	data, err := msg.Bytes(now)
+	if err != nil {
+		return err
+	}
+
+	recipient := strings.NewReplacer("/", "_", "\\", "_", "<", "", ">", "", " ", "").Replace(msg.To)
+	name := fmt.Sprintf("%s_%s.eml", now.Format("20060102T150405.000000000"), recipient)
+	if err := os.WriteFile(filepath.Join(s.dir, name), data, 0o640); err != nil {
+		return fmt.Errorf("failed to write mail file: %w", err)
+	}
+	return nil
+}
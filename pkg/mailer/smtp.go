@@ -0,0 +1,137 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTP transport security modes
+const (
+	TLSModeStartTLS = "starttls" // Plain connection upgraded with STARTTLS, which is required (port 587)
+	TLSModeImplicit = "tls"      // TLS from the first byte (port 465)
+	TLSModeNone     = "none"     // Unencrypted, only for local SMTP stand-ins like MailHog
+)
+
+// smtpTimeout bounds connecting and the whole SMTP conversation of one message
+const smtpTimeout = 30 * time.Second
+
+// SMTPConfig contains the connection settings of an SMTP server
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string // Empty disables authentication
+	Password string
+	TLSMode  string // One of the TLSMode constants
+}
+
+// SMTPSender delivers messages through an SMTP server
+// A new connection is used per message, the outbox sends at a low rate
+type SMTPSender struct {
+	cfg SMTPConfig
+}
+
+// NewSMTPSender creates an SMTP sender
+func NewSMTPSender(cfg SMTPConfig) (*SMTPSender, error) {
+	switch cfg.TLSMode {
+	case TLSModeStartTLS, TLSModeImplicit, TLSModeNone:
+	default:
+		return nil, fmt.Errorf("unsupported SMTP TLS mode '%s'", cfg.TLSMode)
+	}
+	if cfg.Host == "" || cfg.Port == 0 {
+		return nil, fmt.Errorf("SMTP host and port must be set")
+	}
+	return &SMTPSender{cfg: cfg}, nil
+}
+
+// Send delivers a message to its recipient
+func (s *SMTPSender) Send(ctx context.Context, msg *Message) error {
+	from, err := msg.EnvelopeFrom()
+	if err != nil {
+		return err
+	}
+	to, err := msg.EnvelopeTo()
+	if err != nil {
+		return err
+	}
+	data, err := msg.Bytes(time.Now())
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, smtpTimeout)
+	defer cancel()
+
+	conn, err := s.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		return fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer client.Close()
+
+	if s.cfg.TLSMode == TLSModeStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("SMTP server does not support STARTTLS")
+		}
+		if err := client.StartTLS(&tls.Config{ServerName: s.cfg.Host}); err != nil {
+			return fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+
+	if s.cfg.Username != "" {
+		// PlainAuth refuses to send credentials over unencrypted connections to remote hosts
+		if err := client.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)); err != nil {
+			return fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+
+	if err := client.Mail(from); err != nil {
+		return fmt.Errorf("SMTP MAIL FROM failed: %w", err)
+	}
+	if err := client.Rcpt(to); err != nil {
+		return fmt.Errorf("SMTP RCPT TO failed: %w", err)
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("SMTP DATA failed: %w", err)
+	}
+	if _, err := writer.Write(data); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("SMTP server rejected message: %w", err)
+	}
+
+	return client.Quit()
+}
+
+func (s *SMTPSender) dial(ctx context.Context) (net.Conn, error) {
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	dialer := &net.Dialer{}
+
+	var conn net.Conn
+	var err error
+	if s.cfg.TLSMode == TLSModeImplicit {
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: s.cfg.Host}}
+		conn, err = tlsDialer.DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	return conn, nil
+}
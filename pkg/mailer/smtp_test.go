@@ -0,0 +1,160 @@
+package mailer
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSMTPServer is a minimal SMTP stand-in that accepts one message per connection
+type fakeSMTPServer struct {
+	listener net.Listener
+	commands chan []string // Commands of each session
+	data     chan string   // Message data of each session
+}
+
+func newFakeSMTPServer(t *testing.T, extensions ...string) *fakeSMTPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	s := &fakeSMTPServer{listener: listener, commands: make(chan []string, 1), data: make(chan string, 1)}
+	go s.serve(extensions)
+	return s
+}
+
+func (s *fakeSMTPServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeSMTPServer) serve(extensions []string) {
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	var commands []string
+	defer func() { s.commands <- commands }()
+
+	reply("220 localhost ESMTP fake")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		commands = append(commands, line)
+
+		switch verb {
+		case "EHLO":
+			reply("250-localhost")
+			for _, ext := range extensions {
+				reply("250-" + ext)
+			}
+			reply("250 8BITMIME")
+		case "AUTH":
+			reply("235 authenticated")
+		case "DATA":
+			reply("354 end data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				dataLine, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(dataLine)
+			}
+			s.data <- data.String()
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
+
+func TestSMTPSender(t *testing.T) {
+	msg := &Message{
+		From:    "FITS <noreply@fits.example.com>",
+		To:      "Max Mustermann <max@example.com>",
+		Subject: "Your invitation to FITS",
+		Text:    "Hello Max",
+		HTML:    "<p>Hello Max</p>",
+	}
+
+	t.Run("delivers message", func(t *testing.T) {
+		server := newFakeSMTPServer(t)
+		sender, err := NewSMTPSender(SMTPConfig{Host: "127.0.0.1", Port: server.port(), TLSMode: TLSModeNone})
+		require.NoError(t, err)
+
+		require.NoError(t, sender.Send(context.Background(), msg))
+
+		commands := <-server.commands
+		assert.Contains(t, commands, "MAIL FROM:<noreply@fits.example.com> BODY=8BITMIME")
+		assert.Contains(t, commands, "RCPT TO:<max@example.com>")
+		data := <-server.data
+		assert.Contains(t, data, "Subject: Your invitation to FITS")
+		assert.Contains(t, data, "Hello Max")
+	})
+
+	t.Run("requires STARTTLS", func(t *testing.T) {
+		server := newFakeSMTPServer(t)
+		sender, err := NewSMTPSender(SMTPConfig{Host: "127.0.0.1", Port: server.port(), TLSMode: TLSModeStartTLS})
+		require.NoError(t, err)
+
+		err = sender.Send(context.Background(), msg)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "STARTTLS")
+	})
+
+	t.Run("authenticates", func(t *testing.T) {
+		server := newFakeSMTPServer(t, "AUTH PLAIN")
+		// PlainAuth only sends credentials without TLS to localhost
+		sender, err := NewSMTPSender(SMTPConfig{Host: "localhost", Port: server.port(), TLSMode: TLSModeNone, Username: "fits", Password: "secret"})
+		require.NoError(t, err)
+
+		require.NoError(t, sender.Send(context.Background(), msg))
+
+		commands := <-server.commands
+		assert.Contains(t, commands, "AUTH PLAIN AGZpdHMAc2VjcmV0")
+	})
+
+	t.Run("reports connection errors", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		port := listener.Addr().(*net.TCPAddr).Port
+		listener.Close()
+
+		sender, err := NewSMTPSender(SMTPConfig{Host: "127.0.0.1", Port: port, TLSMode: TLSModeNone})
+		require.NoError(t, err)
+
+		err = sender.Send(context.Background(), msg)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to connect")
+	})
+
+	t.Run("rejects invalid configuration", func(t *testing.T) {
+		_, err := NewSMTPSender(SMTPConfig{Host: "localhost", Port: 25, TLSMode: "ssl"})
+		assert.Error(t, err)
+
+		_, err = NewSMTPSender(SMTPConfig{Port: 25, TLSMode: TLSModeNone})
+		assert.Error(t, err)
+	})
+}
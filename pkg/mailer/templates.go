@@ -0,0 +1,142 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+// Template names, each exists as <name>.subject.tmpl, <name>.txt.tmpl and <name>.html.tmpl per locale
+const (
+	TemplateInvitation    = "invitation"
+	TemplatePasswordReset = "password_reset"
+)
+
+// InvitationData is the data of the invitation template
+type InvitationData struct {
+	FirstName string
+	LastName  string
+	Role      string // "student" or "teacher"
+	Link      string
+	ExpiresAt time.Time
+}
+
+// PasswordResetData is the data of the password reset template
+type PasswordResetData struct {
+	FirstName string
+	LastName  string
+	Username  string
+	Link      string
+	ExpiresAt time.Time
+}
+
+// Content is the rendered subject and bodies of a message
+type Content struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// localeTemplates holds the parsed templates of one locale
+type localeTemplates struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+// Renderer renders the embedded email templates in the available locales
+type Renderer struct {
+	defaultLocale string
+	locales       map[string]*localeTemplates
+}
+
+// NewRenderer parses all embedded templates
+// Messages in locales without templates fall back to defaultLocale
+func NewRenderer(defaultLocale string) (*Renderer, error) {
+	r := &Renderer{locales: make(map[string]*localeTemplates)}
+
+	entries, err := fs.ReadDir(templateFS, "templates")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read email templates: %w", err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		locale := entry.Name()
+		dir := "templates/" + locale
+
+		text, err := texttemplate.ParseFS(templateFS, dir+"/*.subject.tmpl", dir+"/*.txt.tmpl")
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s text templates: %w", locale, err)
+		}
+		html, err := htmltemplate.ParseFS(templateFS, dir+"/*.html.tmpl")
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s HTML templates: %w", locale, err)
+		}
+		r.locales[locale] = &localeTemplates{text: text, html: html}
+	}
+
+	if _, ok := r.locales[defaultLocale]; !ok {
+		return nil, fmt.Errorf("unsupported default locale '%s', available: %s", defaultLocale, strings.Join(r.Locales(), ", "))
+	}
+	r.defaultLocale = defaultLocale
+
+	return r, nil
+}
+
+// Locales returns the available locales
+func (r *Renderer) Locales() []string {
+	locales := make([]string, 0, len(r.locales))
+	for locale := range r.locales {
+		locales = append(locales, locale)
+	}
+	return locales
+}
+
+// HasLocale reports whether templates exist for the locale (e.g. "de" or "de-DE")
+func (r *Renderer) HasLocale(locale string) bool {
+	_, ok := r.locales[baseLanguage(locale)]
+	return ok
+}
+
+// Render renders a template in the given locale, falling back to the default locale
+func (r *Renderer) Render(name, locale string, data any) (*Content, error) {
+	templates, ok := r.locales[baseLanguage(locale)]
+	if !ok {
+		templates = r.locales[r.defaultLocale]
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := templates.text.ExecuteTemplate(&subject, name+".subject.tmpl", data); err != nil {
+		return nil, fmt.Errorf("failed to render %s subject: %w", name, err)
+	}
+	if err := templates.text.ExecuteTemplate(&text, name+".txt.tmpl", data); err != nil {
+		return nil, fmt.Errorf("failed to render %s text: %w", name, err)
+	}
+	if err := templates.html.ExecuteTemplate(&html, name+".html.tmpl", data); err != nil {
+		return nil, fmt.Errorf("failed to render %s HTML: %w", name, err)
+	}
+
+	return &Content{
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
+
+// baseLanguage reduces a language tag like "de-DE" to "de"
+func baseLanguage(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if i := strings.IndexAny(locale, "-_"); i >= 0 {
+		locale = locale[:i]
+	}
+	return locale
+}
@@ -0,0 +1,171 @@
+package mailer
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/JustDoItBetter/FITS-backend/pkg/logger"
+)
+
+// Retry backoff between delivery attempts, doubled per failed attempt
+const (
+	retryBaseDelay = 30 * time.Second
+	retryMaxDelay  = 2 * time.Hour
+)
+
+// sentPurgeInterval is how often sent messages past the retention are deleted
+const sentPurgeInterval = time.Hour
+
+// WorkerConfig controls the background sender
+type WorkerConfig struct {
+	PollInterval time.Duration // How often the outbox is checked for due messages
+	BatchSize    int           // Messages claimed per poll
+	MaxAttempts  int           // Attempts before a message is marked as failed
+	Retention    time.Duration // How long sent messages are kept before they are deleted
+}
+
+// Worker delivers outbox messages in the background and retries failed deliveries
+type Worker struct {
+	store  OutboxStore
+	sender Sender
+	cfg    WorkerConfig
+
+	lastPurge time.Time
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// NewWorker creates a background sender, call Start to begin delivering
+func NewWorker(store OutboxStore, sender Sender, cfg WorkerConfig) *Worker {
+	return &Worker{
+		store:  store,
+		sender: sender,
+		cfg:    cfg,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// Start polls the outbox until Stop is called
+func (w *Worker) Start() {
+	go func() {
+		defer close(w.done)
+
+		ticker := time.NewTicker(w.cfg.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			now := time.Now()
+			w.processDue(context.Background(), now)
+			if now.Sub(w.lastPurge) >= sentPurgeInterval {
+				w.purgeSent(context.Background(), now)
+				w.lastPurge = now
+			}
+
+			select {
+			case <-w.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop waits for the current batch to finish and stops polling
+func (w *Worker) Stop() {
+	w.once.Do(func() {
+		close(w.stop)
+	})
+	<-w.done
+}
+
+// processDue sends all due messages of one batch and returns how many were sent
+func (w *Worker) processDue(ctx context.Context, now time.Time) int {
+	// The lease must outlast sending the whole batch, otherwise another instance could claim it again
+	lease := time.Duration(w.cfg.BatchSize)*smtpTimeout + time.Minute
+
+	messages, err := w.store.ClaimDue(ctx, now, w.cfg.BatchSize, lease)
+	if err != nil {
+		logger.Error("Failed to read mail outbox", zap.Error(err))
+		return 0
+	}
+
+	sent := 0
+	for i := range messages {
+		if w.deliver(ctx, &messages[i]) {
+			sent++
+		}
+	}
+	return sent
+}
+
+// purgeSent deletes the messages sent longer ago than the retention and returns their number
+func (w *Worker) purgeSent(ctx context.Context, now time.Time) int64 {
+	deleted, err := w.store.DeleteSent(ctx, now.Add(-w.cfg.Retention))
+	if err != nil {
+		logger.Error("Failed to purge sent emails", zap.Error(err))
+		return 0
+	}
+	if deleted > 0 {
+		logger.Info("Purged sent emails", zap.Int64("count", deleted))
+	}
+	return deleted
+}
+
+// deliver sends one claimed message and records the outcome
+func (w *Worker) deliver(ctx context.Context, msg *OutboxMessage) bool {
+	sendErr := w.sender.Send(ctx, msg.Message())
+	now := time.Now()
+
+	if sendErr == nil {
+		if err := w.store.MarkSent(ctx, msg.ID, now); err != nil {
+			logger.Error("Failed to mark email as sent", zap.String("message_id", msg.ID), zap.Error(err))
+		}
+		logger.Info("Email sent",
+			zap.String("message_id", msg.ID),
+			zap.String("template", msg.Template),
+		)
+		return true
+	}
+
+	// Attempts was already incremented when the message was claimed
+	var nextAttemptAt *time.Time
+	if msg.Attempts < w.cfg.MaxAttempts {
+		next := now.Add(retryDelay(msg.Attempts))
+		nextAttemptAt = &next
+	}
+
+	if err := w.store.MarkRetry(ctx, msg.ID, sendErr.Error(), nextAttemptAt); err != nil {
+		logger.Error("Failed to record email delivery failure", zap.String("message_id", msg.ID), zap.Error(err))
+	}
+
+	fields := []zap.Field{
+		zap.String("message_id", msg.ID),
+		zap.String("template", msg.Template),
+		zap.Int("attempt", msg.Attempts),
+		zap.Error(sendErr),
+	}
+	if nextAttemptAt == nil {
+		logger.Error("Email delivery failed permanently", fields...)
+	} else {
+		logger.Warn("Email delivery failed, will retry", append(fields, zap.Time("next_attempt_at", *nextAttemptAt))...)
+	}
+	return false
+}
+
+// retryDelay returns the backoff after the given number of failed attempts
+func retryDelay(attempts int) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= retryMaxDelay {
+			return retryMaxDelay
+		}
+	}
+	return delay
+}
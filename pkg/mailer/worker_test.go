@@ -0,0 +1,222 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryOutbox is an in-memory OutboxStore for worker tests
+type memoryOutbox struct {
+	mu       sync.Mutex
+	messages map[string]*OutboxMessage
+}
+
+func newMemoryOutbox(messages ...OutboxMessage) *memoryOutbox {
+	o := &memoryOutbox{messages: make(map[string]*OutboxMessage)}
+	for i := range messages {
+		o.messages[messages[i].ID] = &messages[i]
+	}
+	return o
+}
+
+func (o *memoryOutbox) ClaimDue(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]OutboxMessage, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var claimed []OutboxMessage
+	for _, msg := range o.messages {
+		if len(claimed) == limit {
+			break
+		}
+		if msg.Status == StatusPending && !msg.NextAttemptAt.After(now) {
+			msg.Attempts++
+			msg.NextAttemptAt = now.Add(lease)
+			claimed = append(claimed, *msg)
+		}
+	}
+	return claimed, nil
+}
+
+func (o *memoryOutbox) MarkSent(ctx context.Context, id string, sentAt time.Time) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.messages[id].Status = StatusSent
+	o.messages[id].SentAt = &sentAt
+	o.messages[id].TextBody = ""
+	o.messages[id].HTMLBody = ""
+	return nil
+}
+
+func (o *memoryOutbox) MarkRetry(ctx context.Context, id, lastError string, nextAttemptAt *time.Time) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	msg := o.messages[id]
+	msg.LastError = &lastError
+	if nextAttemptAt != nil {
+		msg.NextAttemptAt = *nextAttemptAt
+	} else {
+		msg.Status = StatusFailed
+		msg.TextBody = ""
+		msg.HTMLBody = ""
+	}
+	return nil
+}
+
+func (o *memoryOutbox) DeleteSent(ctx context.Context, before time.Time) (int64, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var deleted int64
+	for id, msg := range o.messages {
+		if msg.Status == StatusSent && msg.SentAt.Before(before) {
+			delete(o.messages, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (o *memoryOutbox) get(id string) OutboxMessage {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return *o.messages[id]
+}
+
+// stubSender records sent messages and fails while err is set
+type stubSender struct {
+	mu   sync.Mutex
+	sent []*Message
+	err  error
+}
+
+func (s *stubSender) Send(ctx context.Context, msg *Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+func (s *stubSender) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.sent)
+}
+
+func newTestOutboxMessage(id string, nextAttemptAt time.Time) OutboxMessage {
+	return OutboxMessage{
+		ID:            id,
+		Template:      TemplateInvitation,
+		Recipient:     "max@example.com",
+		Sender:        "FITS <noreply@fits.example.com>",
+		Subject:       "Your invitation to FITS",
+		TextBody:      "Hello Max",
+		Status:        StatusPending,
+		NextAttemptAt: nextAttemptAt,
+	}
+}
+
+var testWorkerConfig = WorkerConfig{PollInterval: 10 * time.Millisecond, BatchSize: 10, MaxAttempts: 3, Retention: 24 * time.Hour}
+
+func TestWorker_ProcessDue(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	t.Run("sends due messages only", func(t *testing.T) {
+		store := newMemoryOutbox(newTestOutboxMessage("due", now), newTestOutboxMessage("later", now.Add(time.Hour)))
+		sender := &stubSender{}
+		worker := NewWorker(store, sender, testWorkerConfig)
+
+		assert.Equal(t, 1, worker.processDue(ctx, now))
+
+		require.Len(t, sender.sent, 1)
+		assert.Equal(t, "max@example.com", sender.sent[0].To)
+		assert.Equal(t, StatusSent, store.get("due").Status)
+		assert.Equal(t, StatusPending, store.get("later").Status)
+	})
+
+	t.Run("schedules retry with backoff", func(t *testing.T) {
+		store := newMemoryOutbox(newTestOutboxMessage("msg", now))
+		sender := &stubSender{err: errors.New("connection refused")}
+		worker := NewWorker(store, sender, testWorkerConfig)
+
+		assert.Equal(t, 0, worker.processDue(ctx, now))
+
+		msg := store.get("msg")
+		assert.Equal(t, StatusPending, msg.Status)
+		assert.Equal(t, 1, msg.Attempts)
+		require.NotNil(t, msg.LastError)
+		assert.Equal(t, "connection refused", *msg.LastError)
+		assert.WithinDuration(t, time.Now().Add(retryBaseDelay), msg.NextAttemptAt, time.Second)
+
+		// Not due again before the backoff has passed
+		assert.Equal(t, 0, worker.processDue(ctx, now.Add(time.Second)))
+		assert.Equal(t, 1, store.get("msg").Attempts)
+
+		// Delivered on a later attempt
+		sender.err = nil
+		assert.Equal(t, 1, worker.processDue(ctx, msg.NextAttemptAt))
+		assert.Equal(t, StatusSent, store.get("msg").Status)
+		assert.Equal(t, 2, store.get("msg").Attempts)
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		store := newMemoryOutbox(newTestOutboxMessage("msg", now))
+		worker := NewWorker(store, &stubSender{err: errors.New("mailbox unavailable")}, testWorkerConfig)
+
+		at := now
+		for i := 0; i < testWorkerConfig.MaxAttempts; i++ {
+			worker.processDue(ctx, at)
+			at = store.get("msg").NextAttemptAt
+		}
+
+		msg := store.get("msg")
+		assert.Equal(t, StatusFailed, msg.Status)
+		assert.Equal(t, testWorkerConfig.MaxAttempts, msg.Attempts)
+	})
+}
+
+func TestWorker_PurgeSent(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	store := newMemoryOutbox(newTestOutboxMessage("old", now.Add(-48*time.Hour)), newTestOutboxMessage("recent", now))
+	worker := NewWorker(store, &stubSender{}, testWorkerConfig)
+
+	require.NoError(t, store.MarkSent(ctx, "old", now.Add(-48*time.Hour)))
+	require.NoError(t, store.MarkSent(ctx, "recent", now.Add(-time.Hour)))
+
+	assert.Equal(t, int64(1), worker.purgeSent(ctx, now))
+	assert.Equal(t, int64(0), worker.purgeSent(ctx, now))
+	assert.Equal(t, StatusSent, store.get("recent").Status)
+}
+
+func TestWorker_StartStop(t *testing.T) {
+	store := newMemoryOutbox(newTestOutboxMessage("msg", time.Now()))
+	sender := &stubSender{}
+	worker := NewWorker(store, sender, testWorkerConfig)
+
+	worker.Start()
+	assert.Eventually(t, func() bool { return sender.count() == 1 }, time.Second, 5*time.Millisecond)
+	worker.Stop()
+	worker.Stop() // Stopping twice is safe
+
+	assert.Equal(t, StatusSent, store.get("msg").Status)
+}
+
+func TestRetryDelay(t *testing.T) {
+	assert.Equal(t, retryBaseDelay, retryDelay(1))
+	assert.Equal(t, 2*retryBaseDelay, retryDelay(2))
+	assert.Equal(t, 8*retryBaseDelay, retryDelay(4))
+	assert.Equal(t, retryMaxDelay, retryDelay(20))
+}